@@ -117,6 +117,8 @@ type bgpTask struct {
 	listenPort    uint16
 
 	bgpStarted bool
+	// cancel stops the watchers of the running GoBGP server.
+	cancel context.CancelFunc
 
 	yclient *ygnmi.Client
 
@@ -131,34 +133,45 @@ type bgpTask struct {
 
 // newBgpTask creates a new bgpTask.
 func newBgpTask(targetName, zapiURL string, listenPort uint16) *bgpTask {
-	appliedState := &oc.Root{}
-	// appliedBGP is the SoT for BGP applied configuration. It is maintained locally by the task.
-	appliedBGP := appliedState.GetOrCreateNetworkInstance(fakedevice.DefaultNetworkInstance).GetOrCreateProtocol(oc.PolicyTypes_INSTALL_PROTOCOL_TYPE_BGP, fakedevice.BGPRoutingProtocol).GetOrCreateBgp()
-	appliedRoutingPolicy := appliedState.GetOrCreateRoutingPolicy()
-
-	return &bgpTask{
+	t := &bgpTask{
 		targetName: targetName,
 		zapiURL:    zapiURL,
 		listenPort: listenPort,
-
-		commAttrTracker: newOCRIBAttrIndices[string](),
-		attrSetTracker:  newOCRIBAttrIndices[ribAttrSet](),
-
-		appliedState:         appliedState,
-		appliedBGP:           appliedBGP,
-		appliedRoutingPolicy: appliedRoutingPolicy,
 	}
+	t.resetAppliedState()
+	return t
+}
+
+// resetAppliedState discards the applied configuration, so that the intended
+// configuration is applied from scratch to the next GoBGP server.
+func (t *bgpTask) resetAppliedState() {
+	t.appliedState = &oc.Root{}
+	// appliedBGP is the SoT for BGP applied configuration. It is maintained locally by the task.
+	t.appliedBGP = t.appliedState.GetOrCreateNetworkInstance(fakedevice.DefaultNetworkInstance).GetOrCreateProtocol(oc.PolicyTypes_INSTALL_PROTOCOL_TYPE_BGP, fakedevice.BGPRoutingProtocol).GetOrCreateBgp()
+	t.appliedRoutingPolicy = t.appliedState.GetOrCreateRoutingPolicy()
+	t.commAttrTracker = newOCRIBAttrIndices[string]()
+	t.attrSetTracker = newOCRIBAttrIndices[ribAttrSet]()
+	t.bgpStarted = false
 }
 
-// stop stops the GoBGP server.
+// stop stops the GoBGP server, tearing down all the BGP sessions. The task
+// can be started again afterwards.
 func (t *bgpTask) stop(context.Context) error {
+	if t.cancel != nil {
+		t.cancel()
+	}
 	t.bgpServer.Stop()
+
+	t.appliedStateMu.Lock()
+	defer t.appliedStateMu.Unlock()
+	t.resetAppliedState()
 	return nil
 }
 
 // start starts a GoBGP server.
 func (t *bgpTask) start(ctx context.Context, yclient *ygnmi.Client) error {
 	t.yclient = yclient
+	ctx, t.cancel = context.WithCancel(ctx)
 
 	b := &ocpath.Batch{}
 	b.AddPaths(
@@ -358,9 +371,15 @@ func (t *bgpTask) createNewGoBGPServer(ctx context.Context) error {
 
 	go func() {
 		tick := time.NewTicker(5 * time.Second)
-		for range tick.C {
-			if err := t.updateRIBs(ctx); err != nil {
-				log.Warning("Error while updating BGP RIB data: %v", err)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				if err := t.updateRIBs(ctx); err != nil {
+					log.Warning("Error while updating BGP RIB data: %v", err)
+				}
 			}
 		}
 	}()
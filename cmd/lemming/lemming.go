@@ -22,7 +22,6 @@ import (
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
-	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/openconfig/lemming"
@@ -40,6 +39,10 @@ var (
 	target         = pflag.String("target", "fakedut", "name of the fake target")
 	tlsKeyFile     = pflag.String("tls_key_file", "", "Controls whether to enable TLS for gNXI services. If unspecified, insecure credentials are used.")
 	tlsCertFile    = pflag.String("tls_cert_file", "", "Controls whether to enable TLS for gNXI services. If unspecified, insecure credentials are used.")
+	tlsClientCA    = pflag.String("tls_client_ca_file", "", "If set with the TLS cert and key, clients must present a certificate issued by a CA of this PEM file.")
+	certPrincipals = pflag.StringToString("tls_cert_principals", nil, "Principals of the client certificate identities (SPIFFE ID, DNS SAN or common name), as identity=principal. If unspecified, the identities are the principals.")
+	gnsiStateDir   = pflag.String("gnsi_state_dir", "", "If set, directory where the finalized gNSI policies and credentials are persisted across restarts.")
+	anonymous      = pflag.Bool("allow_anonymous", true, "Controls whether to let through the calls presenting neither a username and password nor a client certificate.")
 	zapiAddr       = pflag.String("zapi_addr", "unix:/var/run/zserv.api", "Custom ZAPI address: use unix:/tmp/zserv.api for a temp.")
 	dplane         = pflag.Bool("enable_dataplane", false, "Controls whether to enable dataplane")
 	gcpTraceExport = pflag.Bool("gcp_trace_export", false, "If true, export OTEL traces to GCP")
@@ -62,18 +65,29 @@ func main() {
 	}
 	defer cancel(context.Background())
 
-	creds := insecure.NewCredentials()
+	credsOpt := lemming.WithTransportCreds(insecure.NewCredentials())
 	if *tlsCertFile != "" && *tlsKeyFile != "" {
 		var err error
-		creds, err = credentials.NewServerTLSFromFile(*tlsCertFile, *tlsKeyFile)
+		credsOpt, err = lemming.WithTLSCredsFromFile(*tlsCertFile, *tlsKeyFile)
 		if err != nil {
 			log.Exitf("failed to create tls credentials: %v", err)
 		}
 	}
+	opts := []lemming.Option{credsOpt, lemming.WithGNSIStateDir(*gnsiStateDir), lemming.WithAnonymousClients(*anonymous)}
+	if *tlsClientCA != "" {
+		var principals map[string]string
+		if len(*certPrincipals) > 0 {
+			principals = *certPrincipals
+		}
+		clientOpt, err := lemming.WithClientCertAuth(*tlsClientCA, principals)
+		if err != nil {
+			log.Exitf("failed to load client CAs: %v", err)
+		}
+		opts = append(opts, clientOpt)
+	}
 
-	f, err := lemming.New(*target, *zapiAddr,
+	f, err := lemming.New(*target, *zapiAddr, append(opts,
 		lemming.WithConfigFile(*configFile),
-		lemming.WithTransportCreds(creds),
 		lemming.WithGRIBIAddr(*gribiAddr),
 		lemming.WithGNMIAddr(*gnmiAddr),
 		lemming.WithBGPPort(uint16(*bgpPort)),
@@ -82,7 +96,7 @@ func main() {
 		lemming.WithFaultInjection(*faultEnable),
 		lemming.WithP4RTAddr(*p4rtAddr),
 		lemming.WithDataplaneOpts(dplaneopts.WithSkipIPValidation()),
-	)
+	)...)
 	if err != nil {
 		log.Exitf("Failed to start lemming: %v", err)
 	}
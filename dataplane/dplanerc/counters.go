@@ -0,0 +1,334 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package dplanerc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openconfig/ygnmi/ygnmi"
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/lemming/gnmi/gnmiclient"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+
+	log "github.com/golang/glog"
+
+	saipb "github.com/openconfig/lemming/dataplane/proto/sai"
+)
+
+// aftCounterInterval is how often the counters of the AFT entries are
+// published.
+const aftCounterInterval = 5 * time.Second
+
+type aftEntryType int
+
+const (
+	aftPrefix aftEntryType = iota
+	aftNextHop
+	aftLabel
+)
+
+// aftEntry is an AFT entry whose forwarded packets are counted by a flow
+// counter.
+type aftEntry struct {
+	typ    aftEntryType
+	ni     string
+	prefix string // The prefix of IPv4 and IPv6 entries
+	id     uint64 // The index of next hops, or the label of label entries
+}
+
+// aftCounter is the flow counter of an AFT entry.
+type aftCounter struct {
+	oid uint64
+	// refs is the number of next hops sharing the flow counter of a
+	// gRIBI next hop.
+	refs int
+	// The counts when the counters were last cleared.
+	clearedPackets uint64
+	clearedOctets  uint64
+}
+
+// entryCounter returns the flow counter of a prefix or label entry, creating
+// it if needed.
+func (rec *Reconciler) entryCounter(ctx context.Context, entry aftEntry) (uint64, error) {
+	rec.countersMu.Lock()
+	defer rec.countersMu.Unlock()
+	c, err := rec.counterLocked(ctx, entry)
+	if err != nil {
+		return 0, err
+	}
+	return c.oid, nil
+}
+
+// removeCounter removes the flow counter of a prefix or label entry.
+func (rec *Reconciler) removeCounter(ctx context.Context, entry aftEntry) {
+	rec.countersMu.Lock()
+	defer rec.countersMu.Unlock()
+	rec.removeCounterLocked(ctx, entry)
+}
+
+// acquireNextHopCounter returns the flow counter shared by the next hops of a
+// gRIBI next hop, creating it if needed. The counter must be released by
+// releaseNextHopCounter, once bound to the next hop by bindNextHopCounter.
+func (rec *Reconciler) acquireNextHopCounter(ctx context.Context, entry aftEntry) (uint64, error) {
+	rec.countersMu.Lock()
+	defer rec.countersMu.Unlock()
+	c, err := rec.counterLocked(ctx, entry)
+	if err != nil {
+		return 0, err
+	}
+	c.refs++
+	return c.oid, nil
+}
+
+// bindNextHopCounter records that a next hop uses the flow counter of a
+// gRIBI next hop.
+func (rec *Reconciler) bindNextHopCounter(hopID uint64, entry aftEntry) {
+	rec.countersMu.Lock()
+	defer rec.countersMu.Unlock()
+	rec.nextHopCounters[hopID] = entry
+}
+
+// releaseCounter releases a flow counter acquired by acquireNextHopCounter
+// that is not bound to a next hop.
+func (rec *Reconciler) releaseCounter(ctx context.Context, entry aftEntry) {
+	rec.countersMu.Lock()
+	defer rec.countersMu.Unlock()
+	rec.releaseCounterLocked(ctx, entry)
+}
+
+// releaseNextHopCounter releases the flow counter used by a next hop.
+func (rec *Reconciler) releaseNextHopCounter(ctx context.Context, hopID uint64) {
+	rec.countersMu.Lock()
+	defer rec.countersMu.Unlock()
+	entry, ok := rec.nextHopCounters[hopID]
+	if !ok {
+		return
+	}
+	delete(rec.nextHopCounters, hopID)
+	rec.releaseCounterLocked(ctx, entry)
+}
+
+// releaseCounterLocked releases a reference to the flow counter of a gRIBI
+// next hop, removing it once it is no longer referenced.
+//
+// NOTE: rec.countersMu must be held.
+func (rec *Reconciler) releaseCounterLocked(ctx context.Context, entry aftEntry) {
+	c, ok := rec.aftCounters[entry]
+	if !ok {
+		return
+	}
+	if c.refs--; c.refs == 0 {
+		rec.removeCounterLocked(ctx, entry)
+	}
+}
+
+// counterLocked returns the flow counter of an AFT entry, creating it if
+// needed.
+//
+// NOTE: rec.countersMu must be held.
+func (rec *Reconciler) counterLocked(ctx context.Context, entry aftEntry) (*aftCounter, error) {
+	if c, ok := rec.aftCounters[entry]; ok {
+		return c, nil
+	}
+	resp, err := rec.counterClient.CreateCounter(ctx, &saipb.CreateCounterRequest{
+		Switch: rec.switchID,
+		Type:   saipb.CounterType_COUNTER_TYPE_REGULAR.Enum(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create counter: %v", err)
+	}
+	c := &aftCounter{oid: resp.Oid}
+	rec.aftCounters[entry] = c
+	return c, nil
+}
+
+// removeCounterLocked removes the flow counter of an AFT entry.
+//
+// NOTE: rec.countersMu must be held.
+func (rec *Reconciler) removeCounterLocked(ctx context.Context, entry aftEntry) {
+	c, ok := rec.aftCounters[entry]
+	if !ok {
+		return
+	}
+	if _, err := rec.counterClient.RemoveCounter(ctx, &saipb.RemoveCounterRequest{Oid: c.oid}); err != nil {
+		log.Warningf("failed to delete counter: %v", err)
+	}
+	delete(rec.aftCounters, entry)
+}
+
+// counterStats returns the packets and octets counted by a flow counter.
+func (rec *Reconciler) counterStats(ctx context.Context, oid uint64) (uint64, uint64, error) {
+	stats, err := rec.counterClient.GetCounterStats(ctx, &saipb.GetCounterStatsRequest{
+		Oid:        oid,
+		CounterIds: []saipb.CounterStat{saipb.CounterStat_COUNTER_STAT_PACKETS, saipb.CounterStat_COUNTER_STAT_BYTES},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return stats.GetValues()[0], stats.GetValues()[1], nil
+}
+
+// ClearLabelCounters zeroes the counters of the MPLS label entry in the
+// network instance.
+func (rec *Reconciler) ClearLabelCounters(ctx context.Context, ni string, label uint32) error {
+	entry := aftEntry{typ: aftLabel, ni: ni, id: uint64(label)}
+	rec.countersMu.Lock()
+	c, ok := rec.aftCounters[entry]
+	rec.countersMu.Unlock()
+	if !ok {
+		return status.Errorf(codes.NotFound, "label %d is not programmed in network instance %q", label, ni)
+	}
+	packets, octets, err := rec.counterStats(ctx, c.oid)
+	if err != nil {
+		return fmt.Errorf("failed to get label counters: %v", err)
+	}
+	rec.countersMu.Lock()
+	defer rec.countersMu.Unlock()
+	c.clearedPackets, c.clearedOctets = packets, octets
+	return nil
+}
+
+// startAFTCounterUpdates periodically publishes the counters of the AFT
+// entries programmed in the dataplane.
+func (rec *Reconciler) startAFTCounterUpdates(ctx context.Context) {
+	tick := time.NewTicker(aftCounterInterval)
+	rec.closers = append(rec.closers, tick.Stop)
+	go func() {
+		for range tick.C {
+			if err := rec.publishAFTCounters(ctx); err != nil {
+				log.Warningf("failed to publish AFT counters: %v", err)
+			}
+		}
+	}()
+}
+
+// publishAFTCounters publishes the counters of the AFT entries. Only the
+// entries that are present are updated, so that the counters of an entry
+// deleted before its flow counter do not recreate it.
+func (rec *Reconciler) publishAFTCounters(ctx context.Context) error {
+	if rec.c == nil {
+		return nil
+	}
+	type counts struct {
+		oid, clearedPackets, clearedOctets uint64
+	}
+	rec.countersMu.Lock()
+	entries := map[aftEntry]counts{}
+	for entry, c := range rec.aftCounters {
+		entries[entry] = counts{oid: c.oid, clearedPackets: c.clearedPackets, clearedOctets: c.clearedOctets}
+	}
+	rec.countersMu.Unlock()
+
+	nis := map[string]bool{}
+	for entry := range entries {
+		nis[entry.ni] = true
+	}
+	present := map[aftEntry]bool{}
+	for ni := range nis {
+		afts := ocpath.Root().NetworkInstance(ni).Afts()
+		v4, err := ygnmi.LookupAll(ctx, rec.c, afts.Ipv4EntryAny().State())
+		if err != nil {
+			return err
+		}
+		for _, v := range v4 {
+			if e, ok := v.Val(); ok {
+				present[aftEntry{typ: aftPrefix, ni: ni, prefix: e.GetPrefix()}] = true
+			}
+		}
+		v6, err := ygnmi.LookupAll(ctx, rec.c, afts.Ipv6EntryAny().State())
+		if err != nil {
+			return err
+		}
+		for _, v := range v6 {
+			if e, ok := v.Val(); ok {
+				present[aftEntry{typ: aftPrefix, ni: ni, prefix: e.GetPrefix()}] = true
+			}
+		}
+		nhs, err := ygnmi.LookupAll(ctx, rec.c, afts.NextHopAny().State())
+		if err != nil {
+			return err
+		}
+		for _, v := range nhs {
+			if e, ok := v.Val(); ok {
+				present[aftEntry{typ: aftNextHop, ni: ni, id: e.GetIndex()}] = true
+			}
+		}
+		labels, err := ygnmi.LookupAll(ctx, rec.c, afts.LabelEntryAny().State())
+		if err != nil {
+			return err
+		}
+		for _, v := range labels {
+			if e, ok := v.Val(); ok {
+				if l, ok := e.GetLabel().(oc.UnionUint32); ok {
+					present[aftEntry{typ: aftLabel, ni: ni, id: uint64(l)}] = true
+				}
+			}
+		}
+	}
+
+	sb := &ygnmi.SetBatch{}
+	updated := false
+	for entry, c := range entries {
+		if !present[entry] {
+			continue
+		}
+		packets, octets, err := rec.counterStats(ctx, c.oid)
+		if err != nil {
+			log.Warningf("failed to get counters of AFT entry %+v: %v", entry, err)
+			continue
+		}
+		packets, octets = packets-c.clearedPackets, octets-c.clearedOctets
+		afts := ocpath.Root().NetworkInstance(entry.ni).Afts()
+		switch entry.typ {
+		case aftPrefix:
+			if strings.Contains(entry.prefix, ":") {
+				gnmiclient.BatchUpdate(sb, afts.Ipv6Entry(entry.prefix).State(), &oc.NetworkInstance_Afts_Ipv6Entry{
+					Prefix:   ygot.String(entry.prefix),
+					Counters: &oc.NetworkInstance_Afts_Ipv6Entry_Counters{PacketsForwarded: ygot.Uint64(packets), OctetsForwarded: ygot.Uint64(octets)},
+				})
+			} else {
+				gnmiclient.BatchUpdate(sb, afts.Ipv4Entry(entry.prefix).State(), &oc.NetworkInstance_Afts_Ipv4Entry{
+					Prefix:   ygot.String(entry.prefix),
+					Counters: &oc.NetworkInstance_Afts_Ipv4Entry_Counters{PacketsForwarded: ygot.Uint64(packets), OctetsForwarded: ygot.Uint64(octets)},
+				})
+			}
+		case aftNextHop:
+			gnmiclient.BatchUpdate(sb, afts.NextHop(entry.id).State(), &oc.NetworkInstance_Afts_NextHop{
+				Index:    ygot.Uint64(entry.id),
+				Counters: &oc.NetworkInstance_Afts_NextHop_Counters{PacketsForwarded: ygot.Uint64(packets), OctetsForwarded: ygot.Uint64(octets)},
+			})
+		case aftLabel:
+			gnmiclient.BatchUpdate(sb, afts.LabelEntry(oc.UnionUint32(entry.id)).State(), &oc.NetworkInstance_Afts_LabelEntry{
+				Label:    oc.UnionUint32(entry.id),
+				Counters: &oc.NetworkInstance_Afts_LabelEntry_Counters{PacketsForwarded: ygot.Uint64(packets), OctetsForwarded: ygot.Uint64(octets)},
+			})
+		}
+		updated = true
+	}
+	if !updated {
+		return nil
+	}
+	_, err := sb.Set(ctx, rec.c)
+	return err
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -88,9 +89,10 @@ type ocRoute struct {
 }
 
 type routeData struct {
-	nh    uint64 // OID of the NextHop
-	isNHG bool
-	nhg   map[uint64]map[uint64]uint64 // NHG_ID/NH_ID -> Member_ID
+	nh         uint64 // OID of the NextHop
+	isNHG      bool
+	nhg        map[uint64]map[uint64]uint64 // NHG_ID/NH_ID -> Member_ID
+	protection *protection                  // Set if the NHG is a protection group
 }
 
 type routeMap map[ocRoute]*routeData
@@ -120,6 +122,8 @@ type Reconciler struct {
 	nextHopGroupClient saipb.NextHopGroupClient
 	lagClient          saipb.LagClient
 	vrClient           saipb.VirtualRouterClient
+	mplsClient         saipb.MplsClient
+	counterClient      saipb.CounterClient
 	stateMu            sync.RWMutex
 	lldp               protocolHanlder
 	// state keeps track of the applied state of the device's interfaces so that we do not issue duplicate configuration commands to the device's interfaces.
@@ -131,6 +135,20 @@ type Reconciler struct {
 	cpuPortID       uint64
 	contextID       string
 	niDetail        map[string]*netInst
+	// labelsMu guards ocLabelData, which is also accessed outside of the
+	// labels watch.
+	labelsMu    sync.Mutex
+	ocLabelData map[ocLabel]*labelData
+	// protectionMu guards protections and operDown, which are also accessed
+	// by dataplane port events.
+	protectionMu sync.Mutex
+	protections  map[uint64]*protection
+	operDown     map[string]bool
+	// countersMu guards aftCounters and nextHopCounters, which are also
+	// accessed by the AFT counters poller.
+	countersMu      sync.Mutex
+	aftCounters     map[aftEntry]*aftCounter
+	nextHopCounters map[uint64]aftEntry // Next hop OID -> gRIBI next hop
 }
 
 type netInst struct {
@@ -177,8 +195,15 @@ func New(conn grpc.ClientConnInterface, switchID, cpuPortID uint64, contextID st
 		fwdClient:          fwdpb.NewForwardingClient(conn),
 		lagClient:          saipb.NewLagClient(conn),
 		vrClient:           saipb.NewVirtualRouterClient(conn),
+		mplsClient:         saipb.NewMplsClient(conn),
+		counterClient:      saipb.NewCounterClient(conn),
 		lldp:               lldp.New(),
 		niDetail:           map[string]*netInst{},
+		ocLabelData:        map[ocLabel]*labelData{},
+		protections:        map[uint64]*protection{},
+		operDown:           map[string]bool{},
+		aftCounters:        map[aftEntry]*aftCounter{},
+		nextHopCounters:    map[uint64]aftEntry{},
 	}
 	return r
 }
@@ -313,6 +338,69 @@ func (ni *Reconciler) Stop(context.Context) error {
 	return nil
 }
 
+// ProbeSource returns the ID of a port whose router interface is in the
+// network instance networkInstance, along with a local address on that
+// interface to source probes from. If intf is set, only that interface is
+// considered; if src is set, it must be one of the interface's addresses.
+// Locally originated probes are injected at the input of the returned port so
+// that they are routed in the network instance's VRF.
+func (ni *Reconciler) ProbeSource(networkInstance, intf string, src net.IP, isV4 bool) (uint64, net.IP, error) {
+	ni.stateMu.RLock()
+	defer ni.stateMu.RUnlock()
+
+	var refs []ocInterface
+	for ref, data := range ni.ocInterfaceData {
+		if data.networkInstance != networkInstance || data.isAggregate || (intf != "" && ref.name != intf) {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].name != refs[j].name {
+			return refs[i].name < refs[j].name
+		}
+		return refs[i].subintf < refs[j].subintf
+	})
+	for _, ref := range refs {
+		sub := ni.state[ref.name].GetSubinterface(ref.subintf)
+		var addrs []string
+		if isV4 {
+			for addr := range sub.GetIpv4().Address {
+				addrs = append(addrs, addr)
+			}
+		} else {
+			for addr := range sub.GetIpv6().Address {
+				addrs = append(addrs, addr)
+			}
+		}
+		sort.Strings(addrs)
+		for _, addr := range addrs {
+			ip := net.ParseIP(addr)
+			if ip == nil || ip.IsLinkLocalUnicast() || (src != nil && !src.Equal(ip)) {
+				continue
+			}
+			return ni.ocInterfaceData[ref].portID, ip, nil
+		}
+	}
+	if src != nil {
+		return 0, nil, fmt.Errorf("address %v is not assigned to any interface in network instance %q", src, networkInstance)
+	}
+	return 0, nil, fmt.Errorf("no interface with an address of the requested family in network instance %q", networkInstance)
+}
+
+// Port returns the ID of the port of the interface intf, along with the
+// port's numeric ID in the forwarding context.
+func (ni *Reconciler) Port(intf string) (uint64, uint64, error) {
+	ni.stateMu.RLock()
+	defer ni.stateMu.RUnlock()
+
+	data, ok := ni.ocInterfaceData[ocInterface{name: intf}]
+	if !ok || data.isAggregate {
+		return 0, 0, fmt.Errorf("interface %q has no port in the dataplane", intf)
+	}
+	return data.portID, data.portNID, nil
+}
+
 // startCounterUpdates starts a goroutine for updating counters for configured
 // interfaces.
 func (ni *Reconciler) startCounterUpdates(ctx context.Context) {
@@ -904,6 +992,7 @@ func (rec *Reconciler) reconcileNI(config *oc.NetworkInstance) {
 					log.Warningf("failed to create rif %s/%d %v", intfRef.name, intfRef.subintf, err)
 				}
 				data.rifID = rifResp.Oid
+				data.networkInstance = config.GetName()
 			}
 		}
 	}
@@ -940,6 +1029,7 @@ func (ni *Reconciler) handleDataplaneEvent(ctx context.Context, resp *saipb.Port
 		if _, err := sb.Set(ctx, ni.c); err != nil {
 			log.Warningf("failed to set link status: %v", err)
 		}
+		ni.setOperDown(ctx, intf.name, operStatus == oc.Interface_OperStatus_DOWN)
 	}
 }
 
@@ -0,0 +1,207 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dplanerc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/openconfig/ygnmi/schemaless"
+	"github.com/openconfig/ygnmi/ygnmi"
+	statuspb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/openconfig/lemming/gnmi"
+	"github.com/openconfig/lemming/gnmi/fakedevice"
+
+	log "github.com/golang/glog"
+
+	saipb "github.com/openconfig/lemming/dataplane/proto/sai"
+	dpb "github.com/openconfig/lemming/proto/dataplane"
+)
+
+// LabelQuery returns a ygnmi query for an MPLS label entry with the given
+// label and vrf.
+func LabelQuery(ni string, label uint32) ygnmi.ConfigQuery[*dpb.LabelRoute] {
+	q, err := schemaless.NewConfig[*dpb.LabelRoute](fmt.Sprintf("/dataplane/labels/label[label=%d][vrf=%s]", label, ni), gnmi.InternalOrigin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return q
+}
+
+// MustLabelWildcardQuery returns a wildcard query for all MPLS label entries.
+func MustLabelWildcardQuery() ygnmi.WildcardQuery[*dpb.LabelRoute] {
+	q, err := schemaless.NewWildcard[*dpb.LabelRoute]("/dataplane/labels/label[label=*][vrf=*]", gnmi.InternalOrigin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return q
+}
+
+// LabelStatusQuery returns a ygnmi query for the outcome of programming the
+// MPLS label entry with the given label and vrf. The status details contain
+// the programmed label entry.
+func LabelStatusQuery(ni string, label uint32) ygnmi.ConfigQuery[*statuspb.Status] {
+	q, err := schemaless.NewConfig[*statuspb.Status](fmt.Sprintf("/dataplane/label-statuses/label[label=%d][vrf=%s]", label, ni), gnmi.InternalOrigin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return q
+}
+
+// ocLabel identifies an MPLS label entry.
+type ocLabel struct {
+	ni    string
+	label uint32
+}
+
+// labelData is a programmed MPLS label entry.
+type labelData struct {
+	route *dpb.LabelRoute
+	hops  *routeData
+}
+
+// StartLabel starts programming the MPLS label entries as SAI inseg entries.
+func (rec *Reconciler) StartLabel(ctx context.Context, client *ygnmi.Client) error {
+	ctx, cancelFn := context.WithCancel(ctx)
+	w := ygnmi.WatchAll(ctx, client, MustLabelWildcardQuery(), func(v *ygnmi.Value[*dpb.LabelRoute]) error {
+		route, present := v.Val()
+		labelStr, ni := v.Path.Elem[2].Key["label"], v.Path.Elem[2].Key["vrf"]
+		label, err := strconv.ParseUint(labelStr, 10, 32)
+		if err != nil {
+			log.Warningf("invalid label %q: %v", labelStr, err)
+			return ygnmi.Continue
+		}
+		key := ocLabel{ni: ni, label: uint32(label)}
+		statusQuery := LabelStatusQuery(ni, key.label)
+
+		rec.labelsMu.Lock()
+		defer rec.labelsMu.Unlock()
+		if !present {
+			rec.removeLabel(ctx, key)
+			if _, err := ygnmi.Delete(ctx, client, statusQuery); err != nil {
+				log.Warningf("failed to delete label status: %v", err)
+			}
+			return ygnmi.Continue
+		}
+		detail, err := anypb.New(route)
+		if err != nil {
+			log.Warningf("failed to create label status: %v", err)
+			return ygnmi.Continue
+		}
+		st := &statuspb.Status{Code: int32(codes.OK), Details: []*anypb.Any{detail}}
+		if err := rec.programLabel(ctx, key, route); err != nil {
+			log.Warningf("failed to program label %d: %v", key.label, err)
+			st.Code, st.Message = int32(codes.Internal), err.Error()
+		}
+		if _, err := ygnmi.Replace(ctx, client, statusQuery, st, ygnmi.WithSetFallbackEncoding()); err != nil {
+			log.Warningf("failed to publish label status: %v", err)
+		}
+		return ygnmi.Continue
+	})
+	go func() {
+		if _, err := w.Await(); err != nil {
+			log.Warningf("labels watch err: %v", err)
+		}
+	}()
+	rec.closers = append(rec.closers, cancelFn)
+	return nil
+}
+
+// HasLabel returns whether the MPLS label entry is programmed in the network
+// instance.
+func (rec *Reconciler) HasLabel(ni string, label uint32) bool {
+	rec.labelsMu.Lock()
+	defer rec.labelsMu.Unlock()
+	_, ok := rec.ocLabelData[ocLabel{ni: ni, label: label}]
+	return ok
+}
+
+// ReprogramLabel removes the MPLS label entry from the dataplane and programs
+// it again.
+func (rec *Reconciler) ReprogramLabel(ctx context.Context, ni string, label uint32) error {
+	rec.labelsMu.Lock()
+	defer rec.labelsMu.Unlock()
+	key := ocLabel{ni: ni, label: label}
+	data, ok := rec.ocLabelData[key]
+	if !ok {
+		return status.Errorf(codes.NotFound, "label %d is not programmed in network instance %q", label, ni)
+	}
+	rec.removeLabel(ctx, key)
+	return rec.programLabel(ctx, key, data.route)
+}
+
+func (rec *Reconciler) insegEntry(label uint32) *saipb.InsegEntry {
+	return &saipb.InsegEntry{
+		SwitchId: rec.switchID,
+		Label:    label,
+	}
+}
+
+// removeLabel removes an MPLS label entry and its next hops from the
+// dataplane.
+func (rec *Reconciler) removeLabel(ctx context.Context, key ocLabel) {
+	data, ok := rec.ocLabelData[key]
+	if !ok {
+		return
+	}
+	log.Infof("removing label: %d", key.label)
+	if _, err := rec.mplsClient.RemoveInsegEntry(ctx, &saipb.RemoveInsegEntryRequest{Entry: rec.insegEntry(key.label)}); err != nil {
+		log.Warningf("failed to delete label: %v", err)
+	}
+	rec.removeCounter(ctx, aftEntry{typ: aftLabel, ni: key.ni, id: uint64(key.label)})
+	rec.removeNextHops(ctx, data.hops)
+	delete(rec.ocLabelData, key)
+}
+
+// programLabel creates an MPLS label entry and its next hops in the
+// dataplane, replacing the programmed entry with the same label.
+func (rec *Reconciler) programLabel(ctx context.Context, key ocLabel, route *dpb.LabelRoute) error {
+	rec.removeLabel(ctx, key)
+	// SAI inseg entries are not scoped to a virtual router.
+	if key.ni != fakedevice.DefaultNetworkInstance {
+		return fmt.Errorf("MPLS labels are only supported in network instance %q", fakedevice.DefaultNetworkInstance)
+	}
+	hopID, rd, err := rec.createNextHops(ctx, route.GetNextHops())
+	if err != nil {
+		return err
+	}
+	counter := aftEntry{typ: aftLabel, ni: key.ni, id: uint64(key.label)}
+	counterID, err := rec.entryCounter(ctx, counter)
+	if err != nil {
+		rec.removeNextHops(ctx, rd)
+		return err
+	}
+	req := &saipb.CreateInsegEntryRequest{
+		Entry:        rec.insegEntry(key.label),
+		NumOfPop:     proto.Uint32(route.GetPopLabels()),
+		PacketAction: saipb.PacketAction_PACKET_ACTION_FORWARD.Enum(),
+		NextHopId:    proto.Uint64(hopID),
+		CounterId:    proto.Uint64(counterID),
+	}
+	if _, err := rec.mplsClient.CreateInsegEntry(ctx, req); err != nil {
+		rec.removeNextHops(ctx, rd)
+		rec.removeCounter(ctx, counter)
+		return fmt.Errorf("failed to create label: %v", err)
+	}
+	rec.ocLabelData[key] = &labelData{route: route, hops: rd}
+	log.Infof("created inseg entry: %v", req)
+	return nil
+}
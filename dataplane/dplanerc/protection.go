@@ -0,0 +1,157 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package dplanerc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/lemming/gnmi/gnmiclient"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+
+	log "github.com/golang/glog"
+
+	saipb "github.com/openconfig/lemming/dataplane/proto/sai"
+	dpb "github.com/openconfig/lemming/proto/dataplane"
+)
+
+// protection is a SAI protection group that switches over to its standby
+// members, the backup next hops, when the interfaces of all of its primary
+// next hops are oper-down.
+type protection struct {
+	group        uint64   // OID of the protection group
+	primaries    []string // Interfaces of the primary next hops
+	backupActive bool
+	// The gRIBI next-hop group, if any, whose backup-active state is
+	// reported.
+	aftGroup uint64
+	aftNI    string
+}
+
+// createProtectionGroup creates a protection group of the next hops and the
+// backup next hops, returning its OID.
+func (rec *Reconciler) createProtectionGroup(ctx context.Context, hops *dpb.NextHopList) (uint64, *routeData, error) {
+	group, err := rec.nextHopGroupClient.CreateNextHopGroup(ctx, &saipb.CreateNextHopGroupRequest{
+		Switch: rec.switchID,
+		Type:   saipb.NextHopGroupType_NEXT_HOP_GROUP_TYPE_PROTECTION.Enum(),
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create protection group: %v", err)
+	}
+	hopID := group.Oid
+	rd := &routeData{isNHG: true, nhg: map[uint64]map[uint64]uint64{hopID: {}}}
+	p := &protection{group: hopID, aftGroup: hops.GetGroupId(), aftNI: hops.GetGroupNetworkInstance()}
+	addMembers := func(nhs []*dpb.NextHop, weights []uint64, role saipb.NextHopGroupMemberConfiguredRole) error {
+		for i, nh := range nhs {
+			hID, err := rec.createNextHop(ctx, nh)
+			if err != nil {
+				return fmt.Errorf("failed to create next hop: %v", err)
+			}
+			resp, err := rec.nextHopGroupClient.CreateNextHopGroupMember(ctx, &saipb.CreateNextHopGroupMemberRequest{
+				Switch:         rec.switchID,
+				NextHopGroupId: &group.Oid,
+				NextHopId:      &hID,
+				Weight:         proto.Uint32(uint32(weights[i])),
+				ConfiguredRole: role.Enum(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create next group member: %v", err)
+			}
+			rd.nhg[hopID][hID] = resp.Oid
+		}
+		return nil
+	}
+	if err := addMembers(hops.GetHops(), hops.GetWeights(), saipb.NextHopGroupMemberConfiguredRole_NEXT_HOP_GROUP_MEMBER_CONFIGURED_ROLE_PRIMARY); err != nil {
+		return 0, nil, err
+	}
+	if err := addMembers(hops.GetBackupHops(), hops.GetBackupWeights(), saipb.NextHopGroupMemberConfiguredRole_NEXT_HOP_GROUP_MEMBER_CONFIGURED_ROLE_STANDBY); err != nil {
+		return 0, nil, err
+	}
+	for _, nh := range hops.GetHops() {
+		p.primaries = append(p.primaries, nh.GetInterface().GetInterface())
+	}
+	rd.protection = p
+
+	rec.protectionMu.Lock()
+	defer rec.protectionMu.Unlock()
+	rec.protections[hopID] = p
+	if err := rec.updateProtection(ctx, p, true); err != nil {
+		return 0, nil, err
+	}
+	return hopID, rd, nil
+}
+
+// removeProtectionGroup stops tracking the state of a protection group
+// before it is removed.
+func (rec *Reconciler) removeProtectionGroup(p *protection) {
+	rec.protectionMu.Lock()
+	defer rec.protectionMu.Unlock()
+	delete(rec.protections, p.group)
+}
+
+// setOperDown records the oper status of an interface, switching over the
+// protection groups whose primary next hops egress through it.
+func (rec *Reconciler) setOperDown(ctx context.Context, intf string, down bool) {
+	rec.protectionMu.Lock()
+	defer rec.protectionMu.Unlock()
+	rec.operDown[intf] = down
+	for _, p := range rec.protections {
+		if !slices.Contains(p.primaries, intf) {
+			continue
+		}
+		if err := rec.updateProtection(ctx, p, false); err != nil {
+			log.Warningf("failed to update protection group %d: %v", p.group, err)
+		}
+	}
+}
+
+// updateProtection switches a protection group over to its backup next hops
+// if the interfaces of all of its primary next hops are down, and back
+// otherwise. The active group is reported if it changed, or if force is set.
+//
+// NOTE: rec.protectionMu must be held.
+func (rec *Reconciler) updateProtection(ctx context.Context, p *protection, force bool) error {
+	allDown := !slices.ContainsFunc(p.primaries, func(intf string) bool {
+		return !rec.operDown[intf]
+	})
+	if allDown == p.backupActive && !force {
+		return nil
+	}
+	if allDown != p.backupActive {
+		if _, err := rec.nextHopGroupClient.SetNextHopGroupAttribute(ctx, &saipb.SetNextHopGroupAttributeRequest{
+			Oid:           p.group,
+			SetSwitchover: proto.Bool(allDown),
+		}); err != nil {
+			return fmt.Errorf("failed to switch over protection group: %v", err)
+		}
+		log.Infof("protection group %d backup active: %v", p.group, allDown)
+		p.backupActive = allDown
+	}
+	if p.aftGroup == 0 || rec.c == nil {
+		return nil
+	}
+	nhg := &oc.NetworkInstance_Afts_NextHopGroup{Id: ygot.Uint64(p.aftGroup), BackupActive: ygot.Bool(p.backupActive)}
+	if _, err := gnmiclient.Update(ctx, rec.c, ocpath.Root().NetworkInstance(p.aftNI).Afts().NextHopGroup(p.aftGroup).State(), nhg); err != nil {
+		log.Warningf("failed to publish backup-active state: %v", err)
+	}
+	return nil
+}
@@ -20,18 +20,23 @@ import (
 	"fmt"
 	"net"
 	"net/netip"
+	"slices"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"github.com/openconfig/ygnmi/schemaless"
 	"github.com/openconfig/ygnmi/ygnmi"
+	statuspb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/openconfig/lemming/gnmi"
 	"github.com/openconfig/lemming/gnmi/fakedevice"
 
 	log "github.com/golang/glog"
 
+	"github.com/openconfig/lemming/dataplane/forwarding/fwdconfig"
 	saipb "github.com/openconfig/lemming/dataplane/proto/sai"
 	"github.com/openconfig/lemming/dataplane/saiserver"
 	dpb "github.com/openconfig/lemming/proto/dataplane"
@@ -57,135 +62,43 @@ func MustWildcardQuery() ygnmi.WildcardQuery[*dpb.Route] {
 	return q
 }
 
+// RouteStatusQuery returns a ygnmi query for the outcome of programming the
+// route with the given prefix and vrf. The status details contain the
+// programmed route.
+func RouteStatusQuery(ni string, prefix string) ygnmi.ConfigQuery[*statuspb.Status] {
+	q, err := schemaless.NewConfig[*statuspb.Status](fmt.Sprintf("/dataplane/route-statuses/route[prefix=%s][vrf=%s]", prefix, ni), gnmi.InternalOrigin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return q
+}
+
 func (rec *Reconciler) StartRoute(ctx context.Context, client *ygnmi.Client) error {
 	ctx, cancelFn := context.WithCancel(ctx)
 	w := ygnmi.WatchAll(ctx, client, MustWildcardQuery(), func(v *ygnmi.Value[*dpb.Route]) error {
 		route, present := v.Val()
-		prefixStr := v.Path.Elem[2].Key["prefix"]
-		prefix, err := netip.ParsePrefix(prefixStr)
-		if err != nil {
-			log.Warningf("failed to parse cidr: %v", err)
-			return ygnmi.Continue
-		}
-		ipBytes := prefix.Masked().Addr().AsSlice()
-		mask := net.CIDRMask(prefix.Bits(), len(ipBytes)*8)
-
-		niName := fakedevice.DefaultNetworkInstance
-		if route.GetPrefix().GetNetworkInstance() != "" {
-			niName = route.GetPrefix().GetNetworkInstance()
-		}
-		if _, ok := rec.niDetail[niName]; !ok {
-			log.Warningf("got route update for unknown vrf %q", niName)
-			return ygnmi.Continue
-		}
-
-		entry := &saipb.RouteEntry{
-			SwitchId: rec.switchID,
-			VrId:     rec.niDetail[niName].vrOID,
-			Destination: &saipb.IpPrefix{
-				Addr: ipBytes,
-				Mask: mask,
-			},
-		}
-
+		prefixStr, ni := v.Path.Elem[2].Key["prefix"], v.Path.Elem[2].Key["vrf"]
+		statusQuery := RouteStatusQuery(ni, prefixStr)
 		if !present {
-			// Remove NextHop or NextHopGroup.
-			if routeData := rec.ocRouteData.findRoute(prefixStr, entry.GetVrId()); routeData != nil {
-				if routeData.isNHG {
-					log.Infof("removing next hop group")
-					for nhgID, nhs := range routeData.nhg {
-						for nhID, memberID := range nhs {
-							if err := rec.removeNextHopGroupMember(ctx, memberID); err != nil {
-								log.Warningf("failed to delete next hop group member: %v", err)
-							}
-							if err := rec.removeNextHop(ctx, nhID); err != nil {
-								log.Warningf("failed to delete next hop: %v", err)
-							}
-						}
-						if err := rec.removeNextHopGroup(ctx, nhgID); err != nil {
-							log.Warningf("failed to delete next hop group: %v", err)
-						}
-					}
-				} else {
-					log.Infof("removing next hop.")
-					if err := rec.removeNextHop(ctx, routeData.nh); err != nil {
-						log.Warningf("failed to delete next hop: %v", err)
-					}
-				}
-			}
-
-			log.Infof("removing route: %v", prefix)
-			_, err = rec.routeClient.RemoveRouteEntry(ctx, &saipb.RemoveRouteEntryRequest{
-				Entry: entry,
-			})
-			if err != nil {
-				log.Warningf("failed to delete route: %v", err)
+			rec.removeRoute(ctx, ni, prefixStr)
+			if _, err := ygnmi.Delete(ctx, client, statusQuery); err != nil {
+				log.Warningf("failed to delete route status: %v", err)
 			}
 			return ygnmi.Continue
 		}
-		rReq := saipb.CreateRouteEntryRequest{
-			Entry:        entry,
-			PacketAction: saipb.PacketAction_PACKET_ACTION_FORWARD.Enum(),
-		}
-
-		if route.GetInterface() != nil { // If next hop is a interface.
-			// TODO: Add support for subinterfaces.
-			data := rec.ocInterfaceData[ocInterface{name: route.GetInterface().GetInterface(), subintf: route.GetInterface().GetSubinterface()}]
-			rReq.NextHopId = &data.rifID
-
-			if _, err := rec.routeClient.CreateRouteEntry(ctx, &rReq); err != nil {
-				log.Warningf("failed to create route: %v", err)
-			}
-			log.Infof("added connected route: %v", &rReq)
+		detail, err := anypb.New(route)
+		if err != nil {
+			log.Warningf("failed to create route status: %v", err)
 			return ygnmi.Continue
 		}
-		var hopID uint64
-		routeKey := ocRoute{prefix: prefixStr, vrf: entry.GetVrId()}
-		if len(route.GetNextHops().GetHops()) == 1 {
-			hopID, err = rec.createNextHop(ctx, route.GetNextHops().Hops[0])
-			if err != nil {
-				log.Warningf("failed to create next hop: %v", err)
-				return ygnmi.Continue
-			}
-			rec.ocRouteData[routeKey] = &routeData{nh: hopID}
-		} else {
-			group, err := rec.nextHopGroupClient.CreateNextHopGroup(ctx, &saipb.CreateNextHopGroupRequest{
-				Switch: rec.switchID,
-				Type:   saipb.NextHopGroupType_NEXT_HOP_GROUP_TYPE_DYNAMIC_UNORDERED_ECMP.Enum(),
-			})
-			hopID = group.Oid
-			if err != nil {
-				log.Warningf("failed to create next hop group: %v", err)
-				return ygnmi.Continue
-			}
-			rd := &routeData{isNHG: true, nhg: map[uint64]map[uint64]uint64{hopID: {}}}
-			for i, nh := range route.GetNextHops().GetHops() {
-				hID, err := rec.createNextHop(ctx, nh)
-				if err != nil {
-					log.Warningf("failed to create next hop: %v", err)
-					return ygnmi.Continue
-				}
-				resp, err := rec.nextHopGroupClient.CreateNextHopGroupMember(ctx, &saipb.CreateNextHopGroupMemberRequest{
-					Switch:         rec.switchID,
-					NextHopGroupId: &group.Oid,
-					NextHopId:      &hID,
-					Weight:         proto.Uint32(uint32(route.GetNextHops().Weights[i])),
-				})
-				if err != nil {
-					log.Warningf("failed to create next group member: %v", err)
-					return ygnmi.Continue
-				}
-				rd.nhg[hopID][hID] = resp.Oid
-			}
-			rec.ocRouteData[routeKey] = rd
+		st := &statuspb.Status{Code: int32(codes.OK), Details: []*anypb.Any{detail}}
+		if err := rec.programRoute(ctx, prefixStr, route); err != nil {
+			log.Warningf("failed to program route %s: %v", prefixStr, err)
+			st.Code, st.Message = int32(codes.Internal), err.Error()
 		}
-		rReq.NextHopId = proto.Uint64(hopID)
-		if _, err := rec.routeClient.CreateRouteEntry(ctx, &rReq); err != nil {
-			log.Warningf("failed to create route: %v", err)
-			return ygnmi.Continue
+		if _, err := ygnmi.Replace(ctx, client, statusQuery, st, ygnmi.WithSetFallbackEncoding()); err != nil {
+			log.Warningf("failed to publish route status: %v", err)
 		}
-		log.Infof("created route entry: %v", &rReq)
-
 		return ygnmi.Continue
 	})
 	go func() {
@@ -195,14 +108,228 @@ func (rec *Reconciler) StartRoute(ctx context.Context, client *ygnmi.Client) err
 		}
 	}()
 	rec.closers = append(rec.closers, cancelFn)
+	rec.startAFTCounterUpdates(ctx)
+	return nil
+}
+
+// routeNI returns the network instance of a route.
+func routeNI(route *dpb.Route) string {
+	if ni := route.GetPrefix().GetNetworkInstance(); ni != "" {
+		return ni
+	}
+	return fakedevice.DefaultNetworkInstance
+}
+
+// routeEntry returns the SAI route entry of a route.
+func (rec *Reconciler) routeEntry(prefixStr string, route *dpb.Route) (*saipb.RouteEntry, error) {
+	prefix, err := netip.ParsePrefix(prefixStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cidr: %v", err)
+	}
+	ipBytes := prefix.Masked().Addr().AsSlice()
+	mask := net.CIDRMask(prefix.Bits(), len(ipBytes)*8)
+
+	niName := routeNI(route)
+	if _, ok := rec.niDetail[niName]; !ok {
+		return nil, fmt.Errorf("unknown vrf %q", niName)
+	}
+
+	return &saipb.RouteEntry{
+		SwitchId: rec.switchID,
+		VrId:     rec.niDetail[niName].vrOID,
+		Destination: &saipb.IpPrefix{
+			Addr: ipBytes,
+			Mask: mask,
+		},
+	}, nil
+}
+
+// removeRoute removes a route and its next hops from the dataplane.
+func (rec *Reconciler) removeRoute(ctx context.Context, ni, prefixStr string) {
+	entry, err := rec.routeEntry(prefixStr, &dpb.Route{Prefix: &dpb.RoutePrefix{NetworkInstance: ni}})
+	if err != nil {
+		log.Warningf("cannot remove route: %v", err)
+		return
+	}
+	// Remove NextHop or NextHopGroup.
+	if routeData := rec.ocRouteData.findRoute(prefixStr, entry.GetVrId()); routeData != nil {
+		rec.removeNextHops(ctx, routeData)
+	}
+
+	log.Infof("removing route: %v", prefixStr)
+	_, err = rec.routeClient.RemoveRouteEntry(ctx, &saipb.RemoveRouteEntryRequest{
+		Entry: entry,
+	})
+	if err != nil {
+		log.Warningf("failed to delete route: %v", err)
+	}
+	rec.removeCounter(ctx, aftEntry{typ: aftPrefix, ni: ni, prefix: prefixStr})
+}
+
+// programRoute creates a route and its next hops in the dataplane.
+func (rec *Reconciler) programRoute(ctx context.Context, prefixStr string, route *dpb.Route) error {
+	entry, err := rec.routeEntry(prefixStr, route)
+	if err != nil {
+		return err
+	}
+	counterID, err := rec.entryCounter(ctx, aftEntry{typ: aftPrefix, ni: routeNI(route), prefix: prefixStr})
+	if err != nil {
+		return err
+	}
+	rReq := saipb.CreateRouteEntryRequest{
+		Entry:        entry,
+		PacketAction: saipb.PacketAction_PACKET_ACTION_FORWARD.Enum(),
+		CounterId:    proto.Uint64(counterID),
+	}
+
+	if route.GetInterface() != nil { // If next hop is a interface.
+		// TODO: Add support for subinterfaces.
+		data := rec.ocInterfaceData[ocInterface{name: route.GetInterface().GetInterface(), subintf: route.GetInterface().GetSubinterface()}]
+		rReq.NextHopId = &data.rifID
+
+		if _, err := rec.routeClient.CreateRouteEntry(ctx, &rReq); err != nil {
+			return fmt.Errorf("failed to create route: %v", err)
+		}
+		log.Infof("added connected route: %v", &rReq)
+		return nil
+	}
+	if route.GetDecapLookup() != nil {
+		return rec.programDecapRoute(ctx, entry, route.GetDecapLookup(), counterID)
+	}
+	hopID, rd, err := rec.createNextHops(ctx, route.GetNextHops())
+	if err != nil {
+		return err
+	}
+	rec.ocRouteData[ocRoute{prefix: prefixStr, vrf: entry.GetVrId()}] = rd
+	rReq.NextHopId = proto.Uint64(hopID)
+	if _, err := rec.routeClient.CreateRouteEntry(ctx, &rReq); err != nil {
+		return fmt.Errorf("failed to create route: %v", err)
+	}
+	log.Infof("created route entry: %v", &rReq)
 	return nil
 }
 
+// programDecapRoute creates a route that decapsulates packets and looks them
+// up again in another network instance, counting them with the flow counter.
+// The route is removed like any other route.
+func (rec *Reconciler) programDecapRoute(ctx context.Context, entry *saipb.RouteEntry, decap *dpb.DecapLookup, counterID uint64) error {
+	lookupNI, ok := rec.niDetail[decap.GetNetworkInstance()]
+	if !ok {
+		return fmt.Errorf("unknown vrf %q", decap.GetNetworkInstance())
+	}
+	var headerID fwdpb.PacketHeaderId
+	switch decap.GetHeader() {
+	case routingpb.HeaderType_HEADER_TYPE_IP4:
+		headerID = fwdpb.PacketHeaderId_PACKET_HEADER_ID_IP4
+	case routingpb.HeaderType_HEADER_TYPE_IP6:
+		headerID = fwdpb.PacketHeaderId_PACKET_HEADER_ID_IP6
+	default:
+		return fmt.Errorf("unsupported decap header: %v", decap.GetHeader())
+	}
+	fib := saiserver.FIBV6Table
+	if len(entry.GetDestination().GetAddr()) == 4 {
+		fib = saiserver.FIBV4Table
+	}
+	// TODO: Ideally, this would use the SAI tunnel termination table, but
+	// its entries are matched before the VRF of packets is known.
+	req := fwdconfig.TableEntryAddRequest(rec.contextID, fib).AppendEntry(
+		fwdconfig.EntryDesc(fwdconfig.PrefixEntry(
+			fwdconfig.PacketFieldMaskedBytes(fwdpb.PacketFieldNum_PACKET_FIELD_NUM_PACKET_VRF).WithUint64(entry.GetVrId()),
+			fwdconfig.PacketFieldMaskedBytes(fwdpb.PacketFieldNum_PACKET_FIELD_NUM_IP_ADDR_DST).WithBytes(entry.GetDestination().GetAddr(), entry.GetDestination().GetMask()),
+		)),
+		fwdconfig.FlowCounterAction(fmt.Sprint(counterID)),
+		fwdconfig.DecapAction(headerID),
+		fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_SET, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_PACKET_VRF).WithUint64Value(lookupNI.vrOID),
+		fwdconfig.LookupAction(saiserver.FIBSelectorTable),
+	).Build()
+	if _, err := rec.fwdClient.TableEntryAdd(ctx, req); err != nil {
+		return fmt.Errorf("failed to create decap route: %v", err)
+	}
+	log.Infof("created decap route: %v", req)
+	return nil
+}
+
+// createNextHops creates a next hop, or a next hop group if there are several
+// next hops or backup next hops, returning its OID.
+func (rec *Reconciler) createNextHops(ctx context.Context, hops *dpb.NextHopList) (uint64, *routeData, error) {
+	if len(hops.GetBackupHops()) > 0 {
+		return rec.createProtectionGroup(ctx, hops)
+	}
+	if len(hops.GetHops()) == 1 {
+		hopID, err := rec.createNextHop(ctx, hops.GetHops()[0])
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create next hop: %v", err)
+		}
+		return hopID, &routeData{nh: hopID}, nil
+	}
+	group, err := rec.nextHopGroupClient.CreateNextHopGroup(ctx, &saipb.CreateNextHopGroupRequest{
+		Switch: rec.switchID,
+		Type:   saipb.NextHopGroupType_NEXT_HOP_GROUP_TYPE_DYNAMIC_UNORDERED_ECMP.Enum(),
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create next hop group: %v", err)
+	}
+	hopID := group.Oid
+	rd := &routeData{isNHG: true, nhg: map[uint64]map[uint64]uint64{hopID: {}}}
+	for i, nh := range hops.GetHops() {
+		hID, err := rec.createNextHop(ctx, nh)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create next hop: %v", err)
+		}
+		resp, err := rec.nextHopGroupClient.CreateNextHopGroupMember(ctx, &saipb.CreateNextHopGroupMemberRequest{
+			Switch:         rec.switchID,
+			NextHopGroupId: &group.Oid,
+			NextHopId:      &hID,
+			Weight:         proto.Uint32(uint32(hops.Weights[i])),
+		})
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create next group member: %v", err)
+		}
+		rd.nhg[hopID][hID] = resp.Oid
+	}
+	return hopID, rd, nil
+}
+
+// removeNextHops removes the next hop or next hop group created by
+// createNextHops.
+func (rec *Reconciler) removeNextHops(ctx context.Context, routeData *routeData) {
+	if routeData.protection != nil {
+		rec.removeProtectionGroup(routeData.protection)
+	}
+	if routeData.isNHG {
+		log.Infof("removing next hop group")
+		for nhgID, nhs := range routeData.nhg {
+			for nhID, memberID := range nhs {
+				if err := rec.removeNextHopGroupMember(ctx, memberID); err != nil {
+					log.Warningf("failed to delete next hop group member: %v", err)
+				}
+				if err := rec.removeNextHop(ctx, nhID); err != nil {
+					log.Warningf("failed to delete next hop: %v", err)
+				}
+			}
+			if err := rec.removeNextHopGroup(ctx, nhgID); err != nil {
+				log.Warningf("failed to delete next hop group: %v", err)
+			}
+		}
+		return
+	}
+	log.Infof("removing next hop.")
+	if err := rec.removeNextHop(ctx, routeData.nh); err != nil {
+		log.Warningf("failed to delete next hop: %v", err)
+	}
+}
+
 func (ni *Reconciler) createNextHop(ctx context.Context, hop *dpb.NextHop) (uint64, error) {
 	ip, err := netip.ParseAddr(hop.GetNextHopIp())
 	if err != nil {
 		return 0, err
 	}
+	encapActs, native := encapActions(hop.GetHeaders().GetHeaders())
+	if !native && slices.ContainsFunc(hop.GetHeaders().GetHeaders(), func(hdr *routingpb.Header) bool {
+		return hdr.GetType() == routingpb.HeaderType_HEADER_TYPE_IP4 || hdr.GetType() == routingpb.HeaderType_HEADER_TYPE_IP6
+	}) {
+		return 0, fmt.Errorf("unsupported encap headers: %v", hop.GetHeaders())
+	}
 	data := ni.ocInterfaceData[ocInterface{name: hop.GetInterface().GetInterface(), subintf: hop.GetInterface().GetSubinterface()}]
 	hopReq := saipb.CreateNextHopRequest{
 		Switch:            ni.switchID,
@@ -210,10 +337,25 @@ func (ni *Reconciler) createNextHop(ctx context.Context, hop *dpb.NextHop) (uint
 		Ip:                ip.AsSlice(),
 		RouterInterfaceId: proto.Uint64(data.rifID),
 	}
+	// The next hops of a gRIBI next hop share its counter.
+	counter := aftEntry{typ: aftNextHop, ni: hop.GetAftNetworkInstance(), id: hop.GetAftIndex()}
+	if hop.GetAftIndex() != 0 {
+		counterID, err := ni.acquireNextHopCounter(ctx, counter)
+		if err != nil {
+			return 0, err
+		}
+		hopReq.CounterId = proto.Uint64(counterID)
+	}
 	resp, err := ni.nextHopClient.CreateNextHop(ctx, &hopReq)
 	if err != nil {
+		if hopReq.CounterId != nil {
+			ni.releaseCounter(ctx, counter)
+		}
 		return 0, err
 	}
+	if hopReq.CounterId != nil {
+		ni.bindNextHopCounter(resp.Oid, counter)
+	}
 	log.Infof("created next hop: %v", &hopReq)
 	if hop.GetGue() != nil {
 		acts, err := gueActions(hop.GetGue())
@@ -240,6 +382,27 @@ func (ni *Reconciler) createNextHop(ctx context.Context, hop *dpb.NextHop) (uint
 		}
 		log.Infof("created gue actions: %v", actReq)
 	}
+	if encapActs != nil {
+		actReq := &fwdpb.TableEntryAddRequest{
+			ContextId: &fwdpb.ContextId{Id: ni.contextID},
+			TableId:   &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: saiserver.NHActionTable}},
+			EntryDesc: &fwdpb.EntryDesc{Entry: &fwdpb.EntryDesc_Exact{
+				Exact: &fwdpb.ExactEntryDesc{
+					Fields: []*fwdpb.PacketFieldBytes{{
+						FieldId: &fwdpb.PacketFieldId{Field: &fwdpb.PacketField{FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_NEXT_HOP_ID}},
+						Bytes:   binary.BigEndian.AppendUint64(nil, resp.Oid),
+					}},
+				},
+			}},
+			Actions: encapActs,
+		}
+		if _, err := ni.fwdClient.TableEntryAdd(ctx, actReq); err != nil {
+			return 0, err
+		}
+		log.Infof("created encap actions: %v", actReq)
+		return resp.Oid, nil
+	}
+
 	// TODO: refactor this into a seperate func
 	if len(hop.GetHeaders().GetHeaders()) > 0 {
 		layer := []gopacket.SerializableLayer{}
@@ -304,6 +467,67 @@ func (ni *Reconciler) createNextHop(ctx context.Context, hop *dpb.NextHop) (uint
 	return resp.Oid, nil
 }
 
+// encapTTL is the TTL of the MPLS labels and IP headers pushed by next hops,
+// unless the header has its own.
+const encapTTL = 64
+
+// encapActions returns the actions encapsulating packets in headers, and
+// whether all of the headers can be added natively. The headers are added as
+// packet headers, instead of prepended bytes, so that the fields depending on
+// the payload, such as the bottom of stack bit and the IP protocol and length,
+// are kept up to date. Lucius places MPLS labels outside of IP headers, so
+// IP headers can only be added inside of MPLS headers.
+func encapActions(headers []*routingpb.Header) ([]*fwdpb.ActionDesc, bool) {
+	if len(headers) == 0 {
+		return nil, false
+	}
+	var acts []*fwdpb.ActionDesc
+	var hasMPLS bool
+	// The first header is the innermost, and the first label of a header
+	// is its top label.
+	for _, hdr := range headers {
+		switch hdr.GetType() {
+		case routingpb.HeaderType_HEADER_TYPE_MPLS:
+			hasMPLS = true
+			for i := len(hdr.GetLabels()) - 1; i >= 0; i-- {
+				acts = append(acts,
+					fwdconfig.Action(fwdconfig.EncapAction(fwdpb.PacketHeaderId_PACKET_HEADER_ID_MPLS)).Build(),
+					fwdconfig.Action(fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_SET, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_MPLS_LABEL).WithValue(binary.BigEndian.AppendUint32(nil, hdr.GetLabels()[i]))).Build(),
+					fwdconfig.Action(fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_SET, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_MPLS_TTL).WithValue([]byte{encapTTL})).Build(),
+				)
+			}
+		case routingpb.HeaderType_HEADER_TYPE_IP4, routingpb.HeaderType_HEADER_TYPE_IP6:
+			if hasMPLS {
+				return nil, false
+			}
+			dst, err := netip.ParseAddr(hdr.GetDstIp())
+			if err != nil {
+				return nil, false
+			}
+			headerID := fwdpb.PacketHeaderId_PACKET_HEADER_ID_IP4
+			if hdr.GetType() == routingpb.HeaderType_HEADER_TYPE_IP6 {
+				headerID = fwdpb.PacketHeaderId_PACKET_HEADER_ID_IP6
+			}
+			ttl := hdr.GetIpTtl()
+			if ttl == 0 {
+				ttl = encapTTL
+			}
+			acts = append(acts,
+				fwdconfig.Action(fwdconfig.EncapAction(headerID)).Build(),
+				fwdconfig.Action(fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_SET, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_IP_ADDR_DST).WithValue(dst.AsSlice())).Build(),
+				fwdconfig.Action(fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_SET, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_IP_HOP).WithValue([]byte{byte(ttl)})).Build(),
+			)
+			// The source address is optional.
+			if src, err := netip.ParseAddr(hdr.GetSrcIp()); err == nil {
+				acts = append(acts, fwdconfig.Action(fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_SET, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_IP_ADDR_SRC).WithValue(src.AsSlice())).Build())
+			}
+		default:
+			return nil, false
+		}
+	}
+	return acts, true
+}
+
 func (ni *Reconciler) removeNextHop(ctx context.Context, oid uint64) error {
 	hopReq := saipb.RemoveNextHopRequest{
 		Oid: oid,
@@ -311,6 +535,7 @@ func (ni *Reconciler) removeNextHop(ctx context.Context, oid uint64) error {
 	if _, err := ni.nextHopClient.RemoveNextHop(ctx, &hopReq); err != nil {
 		return err
 	}
+	ni.releaseNextHopCounter(ctx, oid)
 	return nil
 }
 
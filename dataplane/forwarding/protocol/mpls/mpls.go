@@ -185,13 +185,24 @@ func parseMPLS(f *frame.Frame, desc *protocol.Desc) (protocol.Handler, fwdpb.Pac
 			break
 		}
 	}
-	// If the last label is explicit null, then use that to parse the next header, otherwise treat the rest of the packet as opaque.
+	// If the last label is explicit null, then use that to parse the next header.
+	// Otherwise, the next header is IP if its version nibble is 4 or 6, as other
+	// MPLS implementations assume, and the rest of the packet is treated as opaque if not.
 	next := fwdpb.PacketHeaderId_PACKET_HEADER_ID_OPAQUE
 	switch label.Value() {
 	case ipv4ExplicitNull:
 		next = fwdpb.PacketHeaderId_PACKET_HEADER_ID_IP4
 	case ipv6ExplicitNull:
 		next = fwdpb.PacketHeaderId_PACKET_HEADER_ID_IP6
+	default:
+		if version, err := f.Peek(0, 1); err == nil {
+			switch version.BitField(4, 4).Value() {
+			case 4:
+				next = fwdpb.PacketHeaderId_PACKET_HEADER_ID_IP4
+			case 6:
+				next = fwdpb.PacketHeaderId_PACKET_HEADER_ID_IP6
+			}
+		}
 	}
 
 	return m, next, nil
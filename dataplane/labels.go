@@ -0,0 +1,59 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataplane
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// labelProgrammer programs the MPLS label entries in the dataplane.
+type labelProgrammer interface {
+	HasLabel(ni string, label uint32) bool
+	ReprogramLabel(ctx context.Context, ni string, label uint32) error
+	ClearLabelCounters(ctx context.Context, ni string, label uint32) error
+}
+
+// programmedLabels returns the label programmer of the started dataplane, or
+// a NotFound error if the label is not programmed.
+func (d *Dataplane) programmedLabels(ni string, label uint32) (labelProgrammer, error) {
+	local := d.local.Load()
+	if local == nil || local.labels == nil || !local.labels.HasLabel(ni, label) {
+		return nil, status.Errorf(codes.NotFound, "label %d is not programmed in network instance %q", label, ni)
+	}
+	return local.labels, nil
+}
+
+// ClearLabelCounters zeroes the counters of the MPLS label entry in the
+// network instance.
+func (d *Dataplane) ClearLabelCounters(ctx context.Context, ni string, label uint32) error {
+	lp, err := d.programmedLabels(ni, label)
+	if err != nil {
+		return err
+	}
+	return lp.ClearLabelCounters(ctx, ni, label)
+}
+
+// ReprogramLabel removes the MPLS label entry from the network instance and
+// programs it again.
+func (d *Dataplane) ReprogramLabel(ctx context.Context, ni string, label uint32) error {
+	lp, err := d.programmedLabels(ni, label)
+	if err != nil {
+		return err
+	}
+	return lp.ReprogramLabel(ctx, ni, label)
+}
@@ -0,0 +1,258 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataplane
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+
+	"github.com/openconfig/lemming/dataplane/forwarding/fwdconfig"
+	"github.com/openconfig/lemming/dataplane/protocol/linkqual"
+	"github.com/openconfig/lemming/dataplane/saiserver"
+
+	fwdpb "github.com/openconfig/lemming/proto/forwarding"
+)
+
+const (
+	// linkTestTick is the interval at which test frames are sent.
+	linkTestTick = 10 * time.Millisecond
+	// linkTestDrain is how long test frames are still counted after the
+	// last one was sent.
+	linkTestDrain = 100 * time.Millisecond
+)
+
+// linkTest is a link qualification endpoint on a port of the dataplane.
+type linkTest struct {
+	d      *Dataplane
+	portID uint64
+	// entry is the trap table entry added for the test.
+	entry *fwdpb.EntryDesc
+	// session counts the frames of generators and injectors in counter.
+	counter *linkqual.Counter
+	session *linkqual.Session
+	// The port counters when a reflector was started.
+	rx, tx uint64
+
+	cancel   func()
+	done     chan struct{}
+	stopOnce sync.Once
+	stopErr  error
+
+	mu                      sync.Mutex
+	sent, received, errored uint64
+	stopped                 bool
+}
+
+// StartGenerator transmits test frames of size bytes out of the port of the
+// interface intf at rate frames per second, and counts the frames that are
+// looped back to the port by its peer.
+func (d *Dataplane) StartGenerator(intf string, rate uint64, size int) (linkqual.Endpoint, error) {
+	return d.startLinkTest(intf, fwdpb.PortAction_PORT_ACTION_OUTPUT, rate, 0, size)
+}
+
+// StartInjector injects count test frames of size bytes, evenly spread over
+// duration, at the input of the port of the interface intf, as if the port
+// was in loopback, and counts the frames that are punted back.
+func (d *Dataplane) StartInjector(intf string, count uint64, size int, duration time.Duration) (linkqual.Endpoint, error) {
+	rate := uint64(float64(count) / duration.Seconds())
+	return d.startLinkTest(intf, fwdpb.PortAction_PORT_ACTION_INPUT, max(rate, 1), count, size)
+}
+
+// StartReflector puts the port of the interface intf in loopback: all the
+// frames received on the port are transmitted back out of it. ASIC and PMD
+// loopbacks are the same in the dataplane.
+func (d *Dataplane) StartReflector(intf string) (linkqual.Endpoint, error) {
+	local := d.local.Load()
+	if local == nil || local.probeSrc == nil {
+		return nil, fmt.Errorf("dataplane is not started")
+	}
+	portID, portNID, err := local.probeSrc.Port(intf)
+	if err != nil {
+		return nil, err
+	}
+	t := &linkTest{
+		d:      d,
+		portID: portID,
+		entry: fwdconfig.EntryDesc(fwdconfig.FlowEntry(
+			fwdconfig.PacketFieldMaskedBytes(fwdpb.PacketFieldNum_PACKET_FIELD_NUM_PACKET_PORT_INPUT).WithUint64(portNID))).Build(),
+	}
+	if t.rx, t.tx, err = t.portCounters(); err != nil {
+		return nil, err
+	}
+	if err := t.addEntry(fwdconfig.TransmitAction(fmt.Sprint(portID)).WithImmediate(true)); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// startLinkTest sends test frames in the port of the interface intf in the
+// direction dir, at rate frames per second until count frames are sent or
+// the test is stopped. The test frames received on the port are punted to the
+// CPU port to be counted.
+func (d *Dataplane) startLinkTest(intf string, dir fwdpb.PortAction, rate, count uint64, size int) (*linkTest, error) {
+	local := d.local.Load()
+	if local == nil || local.probeSrc == nil {
+		return nil, fmt.Errorf("dataplane is not started")
+	}
+	portID, portNID, err := local.probeSrc.Port(intf)
+	if err != nil {
+		return nil, err
+	}
+	t := &linkTest{
+		d:      d,
+		portID: portID,
+		entry: fwdconfig.EntryDesc(fwdconfig.FlowEntry(
+			fwdconfig.PacketFieldMaskedBytes(fwdpb.PacketFieldNum_PACKET_FIELD_NUM_PACKET_PORT_INPUT).WithUint64(portNID),
+			fwdconfig.PacketFieldMaskedBytes(fwdpb.PacketFieldNum_PACKET_FIELD_NUM_ETHER_TYPE).
+				WithBytes(binary.BigEndian.AppendUint16(nil, uint16(linkqual.EtherType)), []byte{0xFF, 0xFF}))).Build(),
+		counter: local.linkCounter,
+		session: local.linkCounter.NewSession(portID),
+		done:    make(chan struct{}),
+	}
+	if err := t.addEntry(fwdconfig.TransmitAction(fmt.Sprint(local.cpuPortID)).WithImmediate(true)); err != nil {
+		local.linkCounter.RemoveSession(t.session)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	go func() {
+		defer close(t.done)
+		tick := time.NewTicker(linkTestTick)
+		defer tick.Stop()
+		start := time.Now()
+		var sent uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-tick.C:
+				want := uint64(now.Sub(start).Seconds() * float64(rate))
+				if count > 0 {
+					want = min(want, count)
+				}
+				for ; sent < want; sent++ {
+					if err := d.injectLinkTest(portID, t.session.Frame(size), dir); err != nil {
+						log.Warningf("failed to send test frame on %s: %v", intf, err)
+						return
+					}
+					t.session.AddSent(1)
+				}
+				if count > 0 && sent == count {
+					return
+				}
+			}
+		}
+	}()
+	return t, nil
+}
+
+// addEntry adds the test's entry with the action to the trap table.
+func (t *linkTest) addEntry(act fwdconfig.ActionDescBuilder) error {
+	req := &fwdpb.TableEntryAddRequest{
+		ContextId: &fwdpb.ContextId{Id: t.d.saiserv.ID()},
+		TableId:   &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: saiserver.TrapTable}},
+		Entries: []*fwdpb.TableEntryAddRequest_Entry{{
+			EntryDesc: t.entry,
+			Actions:   []*fwdpb.ActionDesc{fwdconfig.Action(act).Build()},
+		}},
+	}
+	_, err := t.d.saiserv.TableEntryAdd(context.Background(), req)
+	return err
+}
+
+// portCounters returns the number of frames received and transmitted by the
+// test's port.
+func (t *linkTest) portCounters() (uint64, uint64, error) {
+	resp, err := t.d.saiserv.ObjectCounters(context.Background(), &fwdpb.ObjectCountersRequest{
+		ContextId: &fwdpb.ContextId{Id: t.d.saiserv.ID()},
+		ObjectId:  &fwdpb.ObjectId{Id: fmt.Sprint(t.portID)},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	var rx, tx uint64
+	for _, c := range resp.GetCounters() {
+		switch c.GetId() {
+		case fwdpb.CounterId_COUNTER_ID_RX_PACKETS:
+			rx = c.GetValue()
+		case fwdpb.CounterId_COUNTER_ID_TX_PACKETS:
+			tx = c.GetValue()
+		}
+	}
+	return rx, tx, nil
+}
+
+// Stats returns the number of test frames sent, received and received with
+// errors. For reflectors, these are the frames looped back by the port. The
+// statistics are frozen once the test is stopped.
+func (t *linkTest) Stats() (uint64, uint64, uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.stopped {
+		t.updateStatsLocked()
+	}
+	return t.sent, t.received, t.errored
+}
+
+func (t *linkTest) updateStatsLocked() {
+	if t.session != nil {
+		t.sent, t.received, t.errored = t.session.Stats()
+		return
+	}
+	rx, tx, err := t.portCounters()
+	if err != nil {
+		log.Warningf("failed to get counters of port %d: %v", t.portID, err)
+		return
+	}
+	t.sent, t.received = tx-t.tx, rx-t.rx
+}
+
+// Stop stops sending test frames and removes the test's trap table entry,
+// once the frames still in flight have been counted.
+func (t *linkTest) Stop() error {
+	t.stopOnce.Do(func() {
+		if t.cancel != nil {
+			t.cancel()
+			<-t.done
+			time.Sleep(linkTestDrain)
+		}
+		t.mu.Lock()
+		t.updateStatsLocked()
+		t.stopped = true
+		t.mu.Unlock()
+
+		_, t.stopErr = t.d.saiserv.TableEntryRemove(context.Background(), &fwdpb.TableEntryRemoveRequest{
+			ContextId: &fwdpb.ContextId{Id: t.d.saiserv.ID()},
+			TableId:   &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: saiserver.TrapTable}},
+			Entries:   []*fwdpb.EntryDesc{t.entry},
+		})
+		if t.session != nil {
+			t.counter.RemoveSession(t.session)
+		}
+	})
+	return t.stopErr
+}
+
+// injectLinkTest sends the test frame in the port in the direction dir.
+func (d *Dataplane) injectLinkTest(portID uint64, frame []byte, dir fwdpb.PortAction) error {
+	return d.saiserv.InjectPacket(&fwdpb.ContextId{Id: d.saiserv.ID()}, &fwdpb.PortId{ObjectId: &fwdpb.ObjectId{Id: fmt.Sprint(portID)}},
+		fwdpb.PacketHeaderId_PACKET_HEADER_ID_ETHERNET, frame, nil, false, dir)
+}
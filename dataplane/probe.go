@@ -0,0 +1,58 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataplane
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/openconfig/lemming/dataplane/protocol/icmp"
+
+	fwdpb "github.com/openconfig/lemming/proto/forwarding"
+)
+
+// probeSourcer finds where locally originated probes and test frames enter
+// the dataplane.
+type probeSourcer interface {
+	ProbeSource(networkInstance, intf string, src net.IP, isV4 bool) (uint64, net.IP, error)
+	Port(intf string) (uint64, uint64, error)
+}
+
+// Probe sends an ICMP echo request originated in the network instance ni and
+// waits for the ICMP message sent in response to it. If intf is set, the probe
+// is sourced from that interface. If req.Src is unset, an address of the
+// source interface is used.
+func (d *Dataplane) Probe(ctx context.Context, ni, intf string, req *icmp.Request) (*icmp.Reply, error) {
+	local := d.local.Load()
+	if local == nil || local.probeSrc == nil {
+		return nil, fmt.Errorf("dataplane is not started")
+	}
+	portID, src, err := local.probeSrc.ProbeSource(ni, intf, req.Src, req.Dst.To4() != nil)
+	if err != nil {
+		return nil, err
+	}
+	probe := *req
+	probe.PortID = portID
+	probe.Src = src
+	return local.prober.Probe(ctx, &probe)
+}
+
+// injectProbe injects the frame at the input of the port so that it is
+// routed by the forwarding pipeline.
+func (d *Dataplane) injectProbe(portID uint64, frame []byte) error {
+	return d.saiserv.InjectPacket(&fwdpb.ContextId{Id: d.saiserv.ID()}, &fwdpb.PortId{ObjectId: &fwdpb.ObjectId{Id: fmt.Sprint(portID)}},
+		fwdpb.PacketHeaderId_PACKET_HEADER_ID_ETHERNET, frame, nil, false, fwdpb.PortAction_PORT_ACTION_INPUT)
+}
@@ -0,0 +1,296 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package icmp originates ICMP echo probes through the dataplane and
+// collects the ICMP messages sent back in response to them.
+package icmp
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+
+	"github.com/openconfig/lemming/dataplane/proto/packetio"
+)
+
+// Injector injects an Ethernet frame into the dataplane at the input of
+// the port with the given ID.
+type Injector func(portID uint64, frame []byte) error
+
+// Request describes a single ICMP echo request.
+type Request struct {
+	PortID        uint64 // ID of the port at which the probe enters the dataplane.
+	Src           net.IP // Source address of the probe.
+	Dst           net.IP // Destination address of the probe.
+	TTL           uint8  // TTL (or hop limit) of the probe when it leaves the device.
+	Size          int    // Number of payload bytes.
+	DoNotFragment bool   // Set the DF bit (IPv4 only).
+}
+
+// ReplyType is the kind of ICMP message received in response to a probe.
+type ReplyType int
+
+const (
+	// EchoReply is an echo reply from the probed destination.
+	EchoReply ReplyType = iota
+	// TimeExceeded is sent by the router at which the probe's TTL expired.
+	TimeExceeded
+	// Unreachable is sent by a router that cannot deliver the probe.
+	Unreachable
+)
+
+// Reply is the ICMP message received in response to a probe.
+type Reply struct {
+	Type  ReplyType
+	From  net.IP        // Source address of the reply.
+	Code  uint8         // ICMP code of the reply.
+	TTL   uint8         // TTL (or hop limit) of the reply.
+	Bytes int           // Number of ICMP payload bytes in the reply.
+	RTT   time.Duration // Time between injecting the probe and receiving the reply.
+}
+
+// Prober sends ICMP echo requests and matches the replies punted to the CPU
+// port against the outstanding requests. It implements protocol.Handler.
+type Prober struct {
+	inject  Injector
+	id      uint16 // ICMP identifier used by all probes.
+	mu      sync.Mutex
+	seq     uint16
+	pending map[uint16]*pending // keyed by sequence number.
+}
+
+type pending struct {
+	sent    time.Time
+	replyCh chan *Reply
+}
+
+// New returns a new prober that uses inject to send its probes.
+func New(inject Injector) *Prober {
+	return &Prober{
+		inject:  inject,
+		id:      uint16(rand.Uint32()),
+		pending: map[uint16]*pending{},
+	}
+}
+
+// Probe sends an echo request and waits for the ICMP message sent in
+// response to it. It returns the context's error if no reply arrives before
+// the context is done.
+func (p *Prober) Probe(ctx context.Context, req *Request) (*Reply, error) {
+	p.mu.Lock()
+	p.seq++
+	seq := p.seq
+	p.mu.Unlock()
+
+	frame, err := EchoFrame(req, p.id, seq)
+	if err != nil {
+		return nil, err
+	}
+	// The probe is published with its send time, as replies are matched
+	// against it as soon as it is pending.
+	pd := &pending{replyCh: make(chan *Reply, 1)}
+	p.mu.Lock()
+	pd.sent = time.Now()
+	p.pending[seq] = pd
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, seq)
+		p.mu.Unlock()
+	}()
+
+	if err := p.inject(req.PortID, frame); err != nil {
+		return nil, fmt.Errorf("failed to inject probe: %v", err)
+	}
+	select {
+	case r := <-pd.replyCh:
+		return r, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Matched returns true if the packet is a reply to an outstanding probe.
+func (p *Prober) Matched(po *packetio.PacketOut) bool {
+	id, seq, _, ok := parseReply(po.GetPacket().GetFrame())
+	if !ok || id != p.id {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok = p.pending[seq]
+	return ok
+}
+
+// Process delivers the reply to the waiting probe.
+func (p *Prober) Process(po *packetio.PacketOut) error {
+	id, seq, r, ok := parseReply(po.GetPacket().GetFrame())
+	if !ok || id != p.id {
+		return fmt.Errorf("packet is not an ICMP reply")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pd, ok := p.pending[seq]
+	if !ok {
+		return fmt.Errorf("no outstanding probe with sequence %d", seq)
+	}
+	r.RTT = time.Since(pd.sent)
+	select {
+	case pd.replyCh <- r:
+	default: // Duplicate reply.
+	}
+	return nil
+}
+
+// EchoFrame returns the Ethernet frame of an echo request with the given
+// identifier and sequence number.
+//
+// The probe is injected at the input of a port and routed like transit
+// traffic, which decrements its TTL once, so the TTL in the frame is one
+// more than the requested TTL.
+func EchoFrame(req *Request, id, seq uint16) ([]byte, error) {
+	if req.Dst == nil || req.Src == nil {
+		return nil, fmt.Errorf("probe source and destination must be set")
+	}
+	isV4 := req.Dst.To4() != nil
+	if isV4 != (req.Src.To4() != nil) {
+		return nil, fmt.Errorf("probe source %v and destination %v are of different address families", req.Src, req.Dst)
+	}
+	ttl := req.TTL
+	if ttl < 255 {
+		ttl++
+	}
+	payload := gopacket.Payload(make([]byte, req.Size))
+	eth := &layers.Ethernet{
+		SrcMAC: net.HardwareAddr{0, 0, 0, 0, 0, 0},
+		DstMAC: net.HardwareAddr{0, 0, 0, 0, 0, 0},
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if isV4 {
+		eth.EthernetType = layers.EthernetTypeIPv4
+		ip := &layers.IPv4{
+			Version:  4,
+			TTL:      ttl,
+			Protocol: layers.IPProtocolICMPv4,
+			SrcIP:    req.Src.To4(),
+			DstIP:    req.Dst.To4(),
+		}
+		if req.DoNotFragment {
+			ip.Flags = layers.IPv4DontFragment
+		}
+		icmp := &layers.ICMPv4{
+			TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoRequest, 0),
+			Id:       id,
+			Seq:      seq,
+		}
+		if err := gopacket.SerializeLayers(buf, opts, eth, ip, icmp, payload); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	eth.EthernetType = layers.EthernetTypeIPv6
+	ip := &layers.IPv6{
+		Version:    6,
+		HopLimit:   ttl,
+		NextHeader: layers.IPProtocolICMPv6,
+		SrcIP:      req.Src.To16(),
+		DstIP:      req.Dst.To16(),
+	}
+	icmp := &layers.ICMPv6{
+		TypeCode: layers.CreateICMPv6TypeCode(layers.ICMPv6TypeEchoRequest, 0),
+	}
+	if err := icmp.SetNetworkLayerForChecksum(ip); err != nil {
+		return nil, err
+	}
+	echo := &layers.ICMPv6Echo{Identifier: id, SeqNumber: seq}
+	if err := gopacket.SerializeLayers(buf, opts, eth, ip, icmp, echo, payload); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseReply parses an Ethernet frame carrying an ICMP message sent in
+// response to an echo request. It returns the identifier and sequence number
+// of the request and the parsed reply.
+func parseReply(frame []byte) (uint16, uint16, *Reply, bool) {
+	pkt := gopacket.NewPacket(frame, layers.LayerTypeEthernet, gopacket.Default)
+	if ip4, ok := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4); ok {
+		icmp, ok := pkt.Layer(layers.LayerTypeICMPv4).(*layers.ICMPv4)
+		if !ok {
+			return 0, 0, nil, false
+		}
+		r := &Reply{From: ip4.SrcIP, TTL: ip4.TTL, Code: icmp.TypeCode.Code(), Bytes: len(icmp.Payload)}
+		switch icmp.TypeCode.Type() {
+		case layers.ICMPv4TypeEchoReply:
+			r.Type = EchoReply
+			return icmp.Id, icmp.Seq, r, true
+		case layers.ICMPv4TypeTimeExceeded:
+			r.Type = TimeExceeded
+		case layers.ICMPv4TypeDestinationUnreachable:
+			r.Type = Unreachable
+		default:
+			return 0, 0, nil, false
+		}
+		// The message quotes the IP header and the first 8 bytes of the probe.
+		quoted := gopacket.NewPacket(icmp.Payload, layers.LayerTypeIPv4, gopacket.Default)
+		orig, ok := quoted.Layer(layers.LayerTypeICMPv4).(*layers.ICMPv4)
+		if !ok || orig.TypeCode.Type() != layers.ICMPv4TypeEchoRequest {
+			return 0, 0, nil, false
+		}
+		return orig.Id, orig.Seq, r, true
+	}
+	ip6, ok := pkt.Layer(layers.LayerTypeIPv6).(*layers.IPv6)
+	if !ok {
+		return 0, 0, nil, false
+	}
+	icmp, ok := pkt.Layer(layers.LayerTypeICMPv6).(*layers.ICMPv6)
+	if !ok {
+		return 0, 0, nil, false
+	}
+	// The message body starts with 4 bytes (identifier and sequence number
+	// for echo replies, unused otherwise) followed by the payload.
+	if len(icmp.Payload) < 4 {
+		return 0, 0, nil, false
+	}
+	r := &Reply{From: ip6.SrcIP, TTL: ip6.HopLimit, Code: icmp.TypeCode.Code(), Bytes: len(icmp.Payload) - 4}
+	switch icmp.TypeCode.Type() {
+	case layers.ICMPv6TypeEchoReply:
+		echo, ok := pkt.Layer(layers.LayerTypeICMPv6Echo).(*layers.ICMPv6Echo)
+		if !ok {
+			return 0, 0, nil, false
+		}
+		r.Type = EchoReply
+		return echo.Identifier, echo.SeqNumber, r, true
+	case layers.ICMPv6TypeTimeExceeded:
+		r.Type = TimeExceeded
+	case layers.ICMPv6TypeDestinationUnreachable:
+		r.Type = Unreachable
+	default:
+		return 0, 0, nil, false
+	}
+	quoted := gopacket.NewPacket(icmp.Payload[4:], layers.LayerTypeIPv6, gopacket.Default)
+	orig, ok := quoted.Layer(layers.LayerTypeICMPv6Echo).(*layers.ICMPv6Echo)
+	if !ok {
+		return 0, 0, nil, false
+	}
+	return orig.Identifier, orig.SeqNumber, r, true
+}
@@ -0,0 +1,213 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icmp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/openconfig/gnmi/errdiff"
+
+	pktiopb "github.com/openconfig/lemming/dataplane/proto/packetio"
+)
+
+// respond builds the frame a router at addr sends in response to the probe
+// in frame.
+func respond(t *testing.T, frame []byte, addr net.IP, typ ReplyType) []byte {
+	t.Helper()
+	pkt := gopacket.NewPacket(frame, layers.LayerTypeEthernet, gopacket.Default)
+	eth := &layers.Ethernet{SrcMAC: net.HardwareAddr{0, 0, 0, 0, 0, 1}, DstMAC: net.HardwareAddr{0, 0, 0, 0, 0, 2}}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if probe, ok := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4); ok {
+		eth.EthernetType = layers.EthernetTypeIPv4
+		ip := &layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolICMPv4, SrcIP: addr, DstIP: probe.SrcIP}
+		echo := pkt.Layer(layers.LayerTypeICMPv4).(*layers.ICMPv4)
+		var err error
+		switch typ {
+		case EchoReply:
+			icmp := &layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoReply, 0), Id: echo.Id, Seq: echo.Seq}
+			err = gopacket.SerializeLayers(buf, opts, eth, ip, icmp, gopacket.Payload(echo.Payload))
+		case TimeExceeded:
+			icmp := &layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeTimeExceeded, layers.ICMPv4CodeTTLExceeded)}
+			err = gopacket.SerializeLayers(buf, opts, eth, ip, icmp, gopacket.Payload(append(probe.Contents, echo.Contents...)))
+		}
+		if err != nil {
+			t.Fatalf("failed to build reply: %v", err)
+		}
+		return buf.Bytes()
+	}
+	probe := pkt.Layer(layers.LayerTypeIPv6).(*layers.IPv6)
+	eth.EthernetType = layers.EthernetTypeIPv6
+	ip := &layers.IPv6{Version: 6, HopLimit: 64, NextHeader: layers.IPProtocolICMPv6, SrcIP: addr, DstIP: probe.SrcIP}
+	var icmp *layers.ICMPv6
+	var body []gopacket.SerializableLayer
+	switch typ {
+	case EchoReply:
+		echo := pkt.Layer(layers.LayerTypeICMPv6Echo).(*layers.ICMPv6Echo)
+		icmp = &layers.ICMPv6{TypeCode: layers.CreateICMPv6TypeCode(layers.ICMPv6TypeEchoReply, 0)}
+		payload := pkt.Layer(layers.LayerTypeICMPv6).LayerPayload()[4:]
+		body = []gopacket.SerializableLayer{&layers.ICMPv6Echo{Identifier: echo.Identifier, SeqNumber: echo.SeqNumber}, gopacket.Payload(payload)}
+	case TimeExceeded:
+		icmp = &layers.ICMPv6{TypeCode: layers.CreateICMPv6TypeCode(layers.ICMPv6TypeTimeExceeded, layers.ICMPv6CodeHopLimitExceeded)}
+		body = []gopacket.SerializableLayer{gopacket.Payload(append([]byte{0, 0, 0, 0}, frame[14:]...))}
+	}
+	icmp.SetNetworkLayerForChecksum(ip)
+	if err := gopacket.SerializeLayers(buf, opts, append([]gopacket.SerializableLayer{eth, ip, icmp}, body...)...); err != nil {
+		t.Fatalf("failed to build reply: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestEchoFrame(t *testing.T) {
+	tests := []struct {
+		desc    string
+		req     *Request
+		wantTTL uint8
+		wantDF  bool
+		wantErr string
+	}{{
+		desc:    "ipv4",
+		req:     &Request{Src: net.ParseIP("10.0.0.1"), Dst: net.ParseIP("10.0.1.1"), TTL: 3, Size: 56, DoNotFragment: true},
+		wantTTL: 4,
+		wantDF:  true,
+	}, {
+		desc:    "ipv6",
+		req:     &Request{Src: net.ParseIP("2001::1"), Dst: net.ParseIP("2001:1::1"), TTL: 64, Size: 56},
+		wantTTL: 65,
+	}, {
+		desc:    "mismatched families",
+		req:     &Request{Src: net.ParseIP("10.0.0.1"), Dst: net.ParseIP("2001:1::1"), TTL: 1},
+		wantErr: "different address families",
+	}, {
+		desc:    "missing source",
+		req:     &Request{Dst: net.ParseIP("10.0.1.1"), TTL: 1},
+		wantErr: "must be set",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			frame, err := EchoFrame(tt.req, 1, 2)
+			if d := errdiff.Substring(err, tt.wantErr); d != "" {
+				t.Fatalf("EchoFrame() unexpected error: %s", d)
+			}
+			if err != nil {
+				return
+			}
+			pkt := gopacket.NewPacket(frame, layers.LayerTypeEthernet, gopacket.Default)
+			var gotTTL uint8
+			var gotDF bool
+			var gotPayload []byte
+			if ip, ok := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4); ok {
+				gotTTL, gotDF = ip.TTL, ip.Flags&layers.IPv4DontFragment != 0
+				gotPayload = pkt.Layer(layers.LayerTypeICMPv4).LayerPayload()
+			} else {
+				gotTTL = pkt.Layer(layers.LayerTypeIPv6).(*layers.IPv6).HopLimit
+				gotPayload = pkt.Layer(layers.LayerTypeICMPv6).LayerPayload()[4:]
+			}
+			if gotTTL != tt.wantTTL {
+				t.Errorf("EchoFrame() got TTL %d, want %d", gotTTL, tt.wantTTL)
+			}
+			if gotDF != tt.wantDF {
+				t.Errorf("EchoFrame() got DF %v, want %v", gotDF, tt.wantDF)
+			}
+			if len(gotPayload) != tt.req.Size {
+				t.Errorf("EchoFrame() got payload of %d bytes, want %d", len(gotPayload), tt.req.Size)
+			}
+		})
+	}
+}
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		desc      string
+		req       *Request
+		replyFrom net.IP
+		replyType ReplyType
+		want      *Reply
+	}{{
+		desc:      "ipv4 echo reply",
+		req:       &Request{PortID: 10, Src: net.ParseIP("10.0.0.1"), Dst: net.ParseIP("10.0.1.1"), TTL: 64, Size: 8},
+		replyFrom: net.ParseIP("10.0.1.1"),
+		replyType: EchoReply,
+		want:      &Reply{Type: EchoReply, From: net.ParseIP("10.0.1.1").To4(), TTL: 64, Bytes: 8},
+	}, {
+		desc:      "ipv4 time exceeded",
+		req:       &Request{PortID: 10, Src: net.ParseIP("10.0.0.1"), Dst: net.ParseIP("10.0.1.1"), TTL: 1, Size: 8},
+		replyFrom: net.ParseIP("10.0.0.2"),
+		replyType: TimeExceeded,
+		want:      &Reply{Type: TimeExceeded, From: net.ParseIP("10.0.0.2").To4(), TTL: 64, Bytes: 28},
+	}, {
+		desc:      "ipv6 echo reply",
+		req:       &Request{PortID: 10, Src: net.ParseIP("2001::1"), Dst: net.ParseIP("2001:1::1"), TTL: 64, Size: 8},
+		replyFrom: net.ParseIP("2001:1::1"),
+		replyType: EchoReply,
+		want:      &Reply{Type: EchoReply, From: net.ParseIP("2001:1::1"), TTL: 64, Bytes: 8},
+	}, {
+		desc:      "ipv6 time exceeded",
+		req:       &Request{PortID: 10, Src: net.ParseIP("2001::1"), Dst: net.ParseIP("2001:1::1"), TTL: 1, Size: 8},
+		replyFrom: net.ParseIP("2001::2"),
+		replyType: TimeExceeded,
+		want:      &Reply{Type: TimeExceeded, From: net.ParseIP("2001::2"), TTL: 64, Bytes: 56},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var p *Prober
+			p = New(func(portID uint64, frame []byte) error {
+				if portID != tt.req.PortID {
+					t.Errorf("probe injected at port %d, want %d", portID, tt.req.PortID)
+				}
+				po := &pktiopb.PacketOut{Packet: &pktiopb.Packet{Frame: respond(t, frame, tt.replyFrom, tt.replyType)}}
+				go func() {
+					if !p.Matched(po) {
+						t.Errorf("Matched() = false, want true")
+						return
+					}
+					if err := p.Process(po); err != nil {
+						t.Errorf("Process() unexpected error: %v", err)
+					}
+				}()
+				return nil
+			})
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			got, err := p.Probe(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("Probe() unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(Reply{}, "RTT")); d != "" {
+				t.Errorf("Probe() unexpected diff (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestProbeTimeout(t *testing.T) {
+	p := New(func(uint64, []byte) error { return nil })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.Probe(ctx, &Request{Src: net.ParseIP("10.0.0.1"), Dst: net.ParseIP("10.0.1.1"), TTL: 1}); err == nil {
+		t.Fatalf("Probe() got no error, want timeout")
+	}
+	unrelated := &pktiopb.PacketOut{Packet: &pktiopb.Packet{Frame: []byte("hello")}}
+	if p.Matched(unrelated) {
+		t.Errorf("Matched() = true for a non-ICMP frame, want false")
+	}
+}
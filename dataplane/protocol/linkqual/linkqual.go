@@ -0,0 +1,184 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package linkqual builds the test frames of packet link qualifications and
+// counts the ones that are looped back to the device.
+package linkqual
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/gopacket/layers"
+
+	"github.com/openconfig/lemming/dataplane/proto/packetio"
+)
+
+// EtherType is the EtherType of test frames, one reserved for local
+// experiments by IEEE 802.
+const EtherType = layers.EthernetType(0x88b5)
+
+const (
+	ethLen = 14
+	// The header following the Ethernet header holds the magic, the session
+	// ID, the sequence number and a checksum of the rest of the frame.
+	headerLen = ethLen + 4 + 8 + 8 + 4
+	// minFrameLen is the minimum length of an Ethernet frame, without FCS.
+	minFrameLen = 60
+)
+
+var (
+	magic = []byte("LMLQ")
+	// The destination and source MAC addresses of test frames. Reflectors
+	// loop back all the frames, so the addresses are not checked.
+	dstMAC = net.HardwareAddr{0x02, 0x00, 0x00, 0x4c, 0x51, 0x01}
+	srcMAC = net.HardwareAddr{0x02, 0x00, 0x00, 0x4c, 0x51, 0x00}
+)
+
+// Endpoint is a link qualification endpoint that sends or reflects real test
+// packets.
+type Endpoint interface {
+	// Stats returns the number of test packets sent, received and
+	// received with errors since the endpoint was started, up to when it
+	// was stopped.
+	Stats() (sent, received, errored uint64)
+	// Stop stops the endpoint and restores the forwarding of the interface.
+	Stop() error
+}
+
+// Session is the set of test frames sent by a link qualification endpoint.
+type Session struct {
+	id       uint64
+	portID   uint64
+	seq      atomic.Uint64
+	sent     atomic.Uint64
+	received atomic.Uint64
+	errors   atomic.Uint64
+}
+
+// Frame returns the next test frame of the session. Its length is size, or
+// the minimum length of a test frame if size is smaller.
+func (s *Session) Frame(size int) []byte {
+	size = max(size, headerLen, minFrameLen)
+	seq := s.seq.Add(1)
+	frame := make([]byte, size)
+	copy(frame, dstMAC)
+	copy(frame[6:], srcMAC)
+	binary.BigEndian.PutUint16(frame[12:], uint16(EtherType))
+	copy(frame[ethLen:], magic)
+	binary.BigEndian.PutUint64(frame[ethLen+4:], s.id)
+	binary.BigEndian.PutUint64(frame[ethLen+12:], seq)
+	for i := headerLen; i < size; i++ {
+		frame[i] = byte(seq) + byte(i)
+	}
+	binary.BigEndian.PutUint32(frame[headerLen-4:], crc32.ChecksumIEEE(frame[headerLen:]))
+	return frame
+}
+
+// AddSent records that n frames of the session were sent.
+func (s *Session) AddSent(n uint64) {
+	s.sent.Add(n)
+}
+
+// Stats returns the number of frames of the session that were sent, that
+// were received back and that were received back corrupted.
+func (s *Session) Stats() (sent, received, errored uint64) {
+	return s.sent.Load(), s.received.Load(), s.errors.Load()
+}
+
+// parse returns the session ID of the test frame and whether its payload is
+// intact.
+func parse(frame []byte) (uint64, bool, bool) {
+	if len(frame) < headerLen || binary.BigEndian.Uint16(frame[12:]) != uint16(EtherType) || !bytes.Equal(frame[ethLen:ethLen+4], magic) {
+		return 0, false, false
+	}
+	id := binary.BigEndian.Uint64(frame[ethLen+4:])
+	intact := binary.BigEndian.Uint32(frame[headerLen-4:]) == crc32.ChecksumIEEE(frame[headerLen:])
+	return id, intact, true
+}
+
+// Counter counts the test frames punted to the CPU port against the
+// sessions they belong to. It implements protocol.Handler.
+type Counter struct {
+	mu       sync.Mutex
+	sessions map[uint64]*Session
+}
+
+// NewCounter returns a new counter without any sessions.
+func NewCounter() *Counter {
+	return &Counter{
+		sessions: map[uint64]*Session{},
+	}
+}
+
+// NewSession returns a new session whose frames are sent and received on
+// the port with the given ID.
+func (c *Counter) NewSession(portID uint64) *Session {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := &Session{portID: portID}
+	for {
+		s.id = rand.Uint64()
+		if _, ok := c.sessions[s.id]; !ok {
+			break
+		}
+	}
+	c.sessions[s.id] = s
+	return s
+}
+
+// RemoveSession stops counting the frames of the session.
+func (c *Counter) RemoveSession(s *Session) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sessions, s.id)
+}
+
+// Matched returns true if the packet is a test frame of a session, received
+// on the session's port.
+func (c *Counter) Matched(po *packetio.PacketOut) bool {
+	id, _, ok := parse(po.GetPacket().GetFrame())
+	if !ok {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.sessions[id]
+	return ok && s.portID == po.GetPacket().GetInputPort()
+}
+
+// Process counts the test frame against its session.
+func (c *Counter) Process(po *packetio.PacketOut) error {
+	id, intact, ok := parse(po.GetPacket().GetFrame())
+	if !ok {
+		return fmt.Errorf("packet is not a test frame")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.sessions[id]
+	if !ok {
+		return fmt.Errorf("no session with ID %d", id)
+	}
+	s.received.Add(1)
+	if !intact {
+		s.errors.Add(1)
+	}
+	return nil
+}
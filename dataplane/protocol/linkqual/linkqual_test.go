@@ -0,0 +1,118 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linkqual
+
+import (
+	"testing"
+
+	pktiopb "github.com/openconfig/lemming/dataplane/proto/packetio"
+)
+
+func packetOut(frame []byte, port uint64) *pktiopb.PacketOut {
+	return &pktiopb.PacketOut{
+		Packet: &pktiopb.Packet{
+			Frame:     frame,
+			InputPort: port,
+		},
+	}
+}
+
+func TestFrame(t *testing.T) {
+	c := NewCounter()
+	s := c.NewSession(1)
+
+	tests := []struct {
+		desc    string
+		size    int
+		wantLen int
+	}{{
+		desc:    "minimum length",
+		size:    10,
+		wantLen: minFrameLen,
+	}, {
+		desc:    "requested length",
+		size:    1500,
+		wantLen: 1500,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			frame := s.Frame(tt.size)
+			if len(frame) != tt.wantLen {
+				t.Errorf("Frame(%d) got length %d, want %d", tt.size, len(frame), tt.wantLen)
+			}
+			id, intact, ok := parse(frame)
+			if !ok || !intact || id != s.id {
+				t.Errorf("parse() got (%d, %v, %v), want (%d, true, true)", id, intact, ok, s.id)
+			}
+		})
+	}
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounter()
+	s := c.NewSession(1)
+	other := c.NewSession(2)
+
+	corrupted := s.Frame(100)
+	corrupted[len(corrupted)-1]++
+	notTest := s.Frame(100)
+	notTest[12] = 0x08
+
+	tests := []struct {
+		desc        string
+		po          *pktiopb.PacketOut
+		wantMatched bool
+	}{{
+		desc:        "test frame",
+		po:          packetOut(s.Frame(100), 1),
+		wantMatched: true,
+	}, {
+		desc:        "corrupted test frame",
+		po:          packetOut(corrupted, 1),
+		wantMatched: true,
+	}, {
+		desc: "other port",
+		po:   packetOut(s.Frame(100), 2),
+	}, {
+		desc: "not a test frame",
+		po:   packetOut(notTest, 1),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := c.Matched(tt.po); got != tt.wantMatched {
+				t.Fatalf("Matched() got %v, want %v", got, tt.wantMatched)
+			}
+			if !tt.wantMatched {
+				return
+			}
+			if err := c.Process(tt.po); err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+		})
+	}
+
+	s.AddSent(2)
+	if sent, received, errored := s.Stats(); sent != 2 || received != 2 || errored != 1 {
+		t.Errorf("Stats() got (%d, %d, %d), want (2, 2, 1)", sent, received, errored)
+	}
+	if _, received, _ := other.Stats(); received != 0 {
+		t.Errorf("Stats() of other session got %d received, want 0", received)
+	}
+
+	c.RemoveSession(s)
+	if c.Matched(packetOut(s.Frame(100), 1)) {
+		t.Errorf("Matched() got true after RemoveSession, want false")
+	}
+}
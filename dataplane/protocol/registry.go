@@ -43,6 +43,7 @@ type Registry struct {
 	bypassQ *queue.Queue                           // the queue as a buffer for packet peeking/filtering.
 	psc     pktiopb.PacketIO_CPUPacketStreamClient // the original packet stream client.
 	doneCh  chan struct{}
+	stop    sync.Once
 	mu      sync.Mutex
 	reg     map[string]Handler // map the protocol name to its handler.
 }
@@ -112,9 +113,10 @@ func (r *Registry) Start() {
 	}()
 }
 
-// Stop stops the registry.
+// Stop stops the registry. It does not block, and can be called after the
+// packet stream has ended.
 func (r *Registry) Stop() {
-	r.doneCh <- struct{}{}
+	r.stop.Do(func() { close(r.doneCh) })
 }
 
 // Recv returns the packet that is not processed by any protocol.
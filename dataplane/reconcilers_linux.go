@@ -24,11 +24,12 @@ import (
 	"github.com/openconfig/lemming/gnmi/reconciler"
 )
 
-func getReconcilers(conn grpc.ClientConnInterface, switchID uint64, cpuPortID uint64, contextID string) []reconciler.Reconciler {
+func getReconcilers(conn grpc.ClientConnInterface, switchID uint64, cpuPortID uint64, contextID string) ([]reconciler.Reconciler, probeSourcer, labelProgrammer) {
 	r := dplanerc.New(conn, switchID, cpuPortID, contextID)
 
 	return []reconciler.Reconciler{
 		reconciler.NewBuilder("inferface").WithStart(r.StartInterface).Build(),
 		reconciler.NewBuilder("routes").WithStart(r.StartRoute).WithStop(r.Stop).Build(),
-	}
+		reconciler.NewBuilder("labels").WithStart(r.StartLabel).Build(),
+	}, r, r
 }
@@ -0,0 +1,155 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saiserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/openconfig/lemming/dataplane/forwarding/infra/fwdcontext"
+	"github.com/openconfig/lemming/dataplane/forwarding/infra/fwdobject"
+	saipb "github.com/openconfig/lemming/dataplane/proto/sai"
+	"github.com/openconfig/lemming/dataplane/saiserver/attrmgr"
+	fwdpb "github.com/openconfig/lemming/proto/forwarding"
+)
+
+func TestCreateCounter(t *testing.T) {
+	tests := []struct {
+		desc    string
+		req     *saipb.CreateCounterRequest
+		wantErr string
+		want    *fwdpb.FlowCounterCreateRequest
+	}{{
+		desc: "success",
+		req:  &saipb.CreateCounterRequest{Type: saipb.CounterType_COUNTER_TYPE_REGULAR.Enum()},
+		want: &fwdpb.FlowCounterCreateRequest{
+			ContextId: &fwdpb.ContextId{Id: "foo"},
+			Id:        &fwdpb.FlowCounterId{ObjectId: &fwdpb.ObjectId{Id: "1"}},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			dplane := &fakeSwitchDataplane{}
+			c, _, stopFn := newTestCounter(t, dplane)
+			defer stopFn()
+			_, gotErr := c.CreateCounter(context.TODO(), tt.req)
+			if diff := errdiff.Check(gotErr, tt.wantErr); diff != "" {
+				t.Fatalf("CreateCounter() unexpected err: %s", diff)
+			}
+			if gotErr != nil {
+				return
+			}
+			if d := cmp.Diff(dplane.gotFlowCounterCreateReqs[0], tt.want, protocmp.Transform()); d != "" {
+				t.Errorf("CreateCounter() failed: diff(-got,+want)\n:%s", d)
+			}
+		})
+	}
+}
+
+func TestRemoveCounter(t *testing.T) {
+	tests := []struct {
+		desc    string
+		req     *saipb.RemoveCounterRequest
+		wantErr string
+		want    *fwdpb.ObjectDeleteRequest
+	}{{
+		desc:    "not found",
+		req:     &saipb.RemoveCounterRequest{Oid: 2},
+		wantErr: "not found",
+	}, {
+		desc: "success",
+		req:  &saipb.RemoveCounterRequest{Oid: 1},
+		want: &fwdpb.ObjectDeleteRequest{
+			ContextId: &fwdpb.ContextId{Id: "foo"},
+			ObjectId:  &fwdpb.ObjectId{Id: "1"},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			dplane := &fakeSwitchDataplane{
+				ctx: fwdcontext.New("foo", "foo"),
+			}
+			dplane.ctx.Objects.Insert(&fwdobject.Base{}, &fwdpb.ObjectId{Id: "1"})
+			c, a, stopFn := newTestCounter(t, dplane)
+			a.mgr.StoreAttributes(1, &saipb.CreateCounterRequest{Type: saipb.CounterType_COUNTER_TYPE_REGULAR.Enum()})
+			defer stopFn()
+			_, gotErr := c.RemoveCounter(context.TODO(), tt.req)
+			if diff := errdiff.Check(gotErr, tt.wantErr); diff != "" {
+				t.Fatalf("RemoveCounter() unexpected err: %s", diff)
+			}
+			if gotErr != nil {
+				return
+			}
+			if d := cmp.Diff(dplane.gotObjectDeleteReqs[0], tt.want, protocmp.Transform()); d != "" {
+				t.Errorf("RemoveCounter() failed: diff(-got,+want)\n:%s", d)
+			}
+		})
+	}
+}
+
+func TestGetCounterStats(t *testing.T) {
+	tests := []struct {
+		desc    string
+		req     *saipb.GetCounterStatsRequest
+		wantErr string
+		want    *saipb.GetCounterStatsResponse
+	}{{
+		desc: "success",
+		req: &saipb.GetCounterStatsRequest{
+			Oid:        1,
+			CounterIds: []saipb.CounterStat{saipb.CounterStat_COUNTER_STAT_BYTES, saipb.CounterStat_COUNTER_STAT_PACKETS},
+		},
+		want: &saipb.GetCounterStatsResponse{
+			Values: []uint64{100, 2},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			dplane := &fakeSwitchDataplane{
+				flowQueryReplies: []*fwdpb.FlowCounterQueryReply{{
+					Counters: []*fwdpb.FlowCounter{{
+						Packets: 2,
+						Octets:  100,
+					}},
+				}},
+			}
+			c, _, stopFn := newTestCounter(t, dplane)
+			defer stopFn()
+			got, gotErr := c.GetCounterStats(context.TODO(), tt.req)
+			if diff := errdiff.Check(gotErr, tt.wantErr); diff != "" {
+				t.Fatalf("GetCounterStats() unexpected err: %s", diff)
+			}
+			if gotErr != nil {
+				return
+			}
+			if d := cmp.Diff(got, tt.want, protocmp.Transform()); d != "" {
+				t.Errorf("GetCounterStats() failed: diff(-got,+want)\n:%s", d)
+			}
+		})
+	}
+}
+
+func newTestCounter(t testing.TB, api switchDataplaneAPI) (saipb.CounterClient, *counter, func()) {
+	var c *counter
+	conn, _, stopFn := newTestServer(t, func(mgr *attrmgr.AttrMgr, srv *grpc.Server) {
+		c = newCounter(mgr, api, srv)
+	})
+	return saipb.NewCounterClient(conn), c, stopFn
+}
@@ -254,13 +254,13 @@ var (
 
 const (
 	bgpPort        = 179
-	trapTableID    = "trap-table"
+	TrapTable      = "trap-table"
 	wildcardPortID = 0
 )
 
 func (hostif *hostif) CreateHostifTrap(ctx context.Context, req *saipb.CreateHostifTrapRequest) (*saipb.CreateHostifTrapResponse, error) {
 	id := hostif.mgr.NextID()
-	fwdReq := fwdconfig.TableEntryAddRequest(hostif.dataplane.ID(), trapTableID)
+	fwdReq := fwdconfig.TableEntryAddRequest(hostif.dataplane.ID(), TrapTable)
 
 	swReq := &saipb.GetSwitchAttributeRequest{
 		Oid:      req.GetSwitch(),
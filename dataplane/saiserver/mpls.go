@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saiserver
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/lemming/dataplane/forwarding/fwdconfig"
+	"github.com/openconfig/lemming/dataplane/saiserver/attrmgr"
+
+	saipb "github.com/openconfig/lemming/dataplane/proto/sai"
+	fwdpb "github.com/openconfig/lemming/proto/forwarding"
+)
+
+type mpls struct {
+	saipb.UnimplementedMplsServer
+	mgr       *attrmgr.AttrMgr
+	dataplane switchDataplaneAPI
+}
+
+func newMPLS(mgr *attrmgr.AttrMgr, dataplane switchDataplaneAPI, s *grpc.Server) *mpls {
+	m := &mpls{
+		mgr:       mgr,
+		dataplane: dataplane,
+	}
+	saipb.RegisterMplsServer(s, m)
+	return m
+}
+
+func insegEntryDesc(entry *saipb.InsegEntry) *fwdconfig.EntryDescBuilder {
+	return fwdconfig.EntryDesc(fwdconfig.ExactEntry(
+		fwdconfig.PacketFieldBytes(fwdpb.PacketFieldNum_PACKET_FIELD_NUM_MPLS_LABEL).WithBytes(binary.BigEndian.AppendUint32(nil, entry.GetLabel())),
+	))
+}
+
+// CreateInsegEntry creates an entry in the MPLS table that pops the top labels
+// of the packets with the label and forwards them to the next hop.
+func (m *mpls) CreateInsegEntry(ctx context.Context, req *saipb.CreateInsegEntryRequest) (*saipb.CreateInsegEntryResponse, error) {
+	actions := counterAction(req.CounterId)
+	switch req.GetPacketAction() {
+	case saipb.PacketAction_PACKET_ACTION_DROP, saipb.PacketAction_PACKET_ACTION_TRAP, saipb.PacketAction_PACKET_ACTION_DENY:
+		actions = append(actions, fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_BIT_WRITE, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_PACKET_ACTION).WithBitOp(1, 0).WithValue([]byte{0}))
+	default:
+		for i := uint32(0); i < req.GetNumOfPop(); i++ {
+			actions = append(actions, fwdconfig.DecapAction(fwdpb.PacketHeaderId_PACKET_HEADER_ID_MPLS))
+		}
+		actions = append(actions, fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_BIT_WRITE, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_PACKET_ACTION).WithBitOp(1, 0).WithValue([]byte{1}))
+		switch nextType := m.mgr.GetType(fmt.Sprint(req.GetNextHopId())); nextType {
+		case saipb.ObjectType_OBJECT_TYPE_NEXT_HOP:
+			actions = append(actions,
+				fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_SET, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_NEXT_HOP_ID).WithUint64Value(req.GetNextHopId()),
+				fwdconfig.LookupAction(NHTable),
+			)
+		case saipb.ObjectType_OBJECT_TYPE_NEXT_HOP_GROUP:
+			actions = append(actions,
+				fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_SET, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_NEXT_HOP_GROUP_ID).WithUint64Value(req.GetNextHopId()),
+				fwdconfig.LookupAction(NHGTable),
+			)
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown next hop type: %v", nextType)
+		}
+	}
+	entry := fwdconfig.TableEntryAddRequest(m.dataplane.ID(), MPLSTable).AppendEntry(insegEntryDesc(req.GetEntry()), actions...).Build()
+	if _, err := m.dataplane.TableEntryAdd(ctx, entry); err != nil {
+		return nil, err
+	}
+	return &saipb.CreateInsegEntryResponse{}, nil
+}
+
+func (m *mpls) RemoveInsegEntry(ctx context.Context, req *saipb.RemoveInsegEntryRequest) (*saipb.RemoveInsegEntryResponse, error) {
+	_, err := m.dataplane.TableEntryRemove(ctx, &fwdpb.TableEntryRemoveRequest{
+		ContextId: &fwdpb.ContextId{Id: m.dataplane.ID()},
+		TableId:   &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: MPLSTable}},
+		EntryDesc: insegEntryDesc(req.GetEntry()).Build(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &saipb.RemoveInsegEntryResponse{}, nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package saiserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/openconfig/lemming/dataplane/saiserver/attrmgr"
+
+	saipb "github.com/openconfig/lemming/dataplane/proto/sai"
+	fwdpb "github.com/openconfig/lemming/proto/forwarding"
+)
+
+func TestCreateInsegEntry(t *testing.T) {
+	labelEntry := &fwdpb.EntryDesc{
+		Entry: &fwdpb.EntryDesc_Exact{
+			Exact: &fwdpb.ExactEntryDesc{
+				Fields: []*fwdpb.PacketFieldBytes{{
+					FieldId: &fwdpb.PacketFieldId{
+						Field: &fwdpb.PacketField{FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_MPLS_LABEL},
+					},
+					Bytes: []byte{0x00, 0x00, 0x00, 0x64},
+				}},
+			},
+		},
+	}
+	packetAction := func(v byte) *fwdpb.ActionDesc {
+		return &fwdpb.ActionDesc{
+			ActionType: fwdpb.ActionType_ACTION_TYPE_UPDATE,
+			Action: &fwdpb.ActionDesc_Update{
+				Update: &fwdpb.UpdateActionDesc{
+					FieldId:  &fwdpb.PacketFieldId{Field: &fwdpb.PacketField{FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_PACKET_ACTION}},
+					Field:    &fwdpb.PacketFieldId{Field: &fwdpb.PacketField{}},
+					Type:     fwdpb.UpdateType_UPDATE_TYPE_BIT_WRITE,
+					Value:    []byte{v},
+					BitCount: 1,
+				},
+			},
+		}
+	}
+	popAction := &fwdpb.ActionDesc{
+		ActionType: fwdpb.ActionType_ACTION_TYPE_DECAP,
+		Action: &fwdpb.ActionDesc_Decap{
+			Decap: &fwdpb.DecapActionDesc{
+				HeaderId: fwdpb.PacketHeaderId_PACKET_HEADER_ID_MPLS,
+			},
+		},
+	}
+	tests := []struct {
+		desc    string
+		req     *saipb.CreateInsegEntryRequest
+		types   map[string]saipb.ObjectType
+		wantReq *fwdpb.TableEntryAddRequest
+		wantErr string
+	}{{
+		desc: "unknown next hop",
+		req: &saipb.CreateInsegEntryRequest{
+			Entry:     &saipb.InsegEntry{SwitchId: 1, Label: 100},
+			NextHopId: proto.Uint64(200),
+		},
+		wantErr: "InvalidArgument",
+	}, {
+		desc: "drop action",
+		req: &saipb.CreateInsegEntryRequest{
+			Entry:        &saipb.InsegEntry{SwitchId: 1, Label: 100},
+			PacketAction: saipb.PacketAction_PACKET_ACTION_DROP.Enum(),
+		},
+		wantReq: &fwdpb.TableEntryAddRequest{
+			ContextId: &fwdpb.ContextId{Id: "foo"},
+			TableId:   &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: MPLSTable}},
+			Entries: []*fwdpb.TableEntryAddRequest_Entry{{
+				EntryDesc: labelEntry,
+				Actions:   []*fwdpb.ActionDesc{packetAction(0)},
+			}},
+		},
+	}, {
+		desc:  "pop and forward to next hop group",
+		types: map[string]saipb.ObjectType{"200": saipb.ObjectType_OBJECT_TYPE_NEXT_HOP_GROUP},
+		req: &saipb.CreateInsegEntryRequest{
+			Entry:     &saipb.InsegEntry{SwitchId: 1, Label: 100},
+			NumOfPop:  proto.Uint32(2),
+			NextHopId: proto.Uint64(200),
+		},
+		wantReq: &fwdpb.TableEntryAddRequest{
+			ContextId: &fwdpb.ContextId{Id: "foo"},
+			TableId:   &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: MPLSTable}},
+			Entries: []*fwdpb.TableEntryAddRequest_Entry{{
+				EntryDesc: labelEntry,
+				Actions: []*fwdpb.ActionDesc{popAction, popAction, packetAction(1), {
+					ActionType: fwdpb.ActionType_ACTION_TYPE_UPDATE,
+					Action: &fwdpb.ActionDesc_Update{
+						Update: &fwdpb.UpdateActionDesc{
+							FieldId: &fwdpb.PacketFieldId{Field: &fwdpb.PacketField{FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_NEXT_HOP_GROUP_ID}},
+							Field:   &fwdpb.PacketFieldId{Field: &fwdpb.PacketField{}},
+							Type:    fwdpb.UpdateType_UPDATE_TYPE_SET,
+							Value:   []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc8},
+						},
+					},
+				}, {
+					ActionType: fwdpb.ActionType_ACTION_TYPE_LOOKUP,
+					Action: &fwdpb.ActionDesc_Lookup{
+						Lookup: &fwdpb.LookupActionDesc{
+							TableId: &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: NHGTable}},
+						},
+					},
+				}},
+			}},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			dplane := &fakeSwitchDataplane{}
+			c, mgr, stopFn := newTestMPLS(t, dplane)
+			defer stopFn()
+			for k, v := range tt.types {
+				mgr.SetType(k, v)
+			}
+			_, gotErr := c.CreateInsegEntry(context.TODO(), tt.req)
+			if diff := errdiff.Check(gotErr, tt.wantErr); diff != "" {
+				t.Fatalf("CreateInsegEntry() unexpected err: %s", diff)
+			}
+			if gotErr != nil {
+				return
+			}
+			if d := cmp.Diff(dplane.gotEntryAddReqs[0], tt.wantReq, protocmp.Transform()); d != "" {
+				t.Errorf("CreateInsegEntry() failed: diff(-got,+want)\n:%s", d)
+			}
+		})
+	}
+}
+
+func TestRemoveInsegEntry(t *testing.T) {
+	dplane := &fakeSwitchDataplane{}
+	c, _, stopFn := newTestMPLS(t, dplane)
+	defer stopFn()
+	if _, err := c.CreateInsegEntry(context.TODO(), &saipb.CreateInsegEntryRequest{
+		Entry:        &saipb.InsegEntry{SwitchId: 1, Label: 100},
+		PacketAction: saipb.PacketAction_PACKET_ACTION_DROP.Enum(),
+	}); err != nil {
+		t.Fatalf("CreateInsegEntry() unexpected err: %v", err)
+	}
+	if _, err := c.RemoveInsegEntry(context.TODO(), &saipb.RemoveInsegEntryRequest{Entry: &saipb.InsegEntry{SwitchId: 1, Label: 100}}); err != nil {
+		t.Fatalf("RemoveInsegEntry() unexpected err: %v", err)
+	}
+	want := []*fwdpb.TableEntryRemoveRequest{{
+		ContextId: &fwdpb.ContextId{Id: "foo"},
+		TableId:   &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: MPLSTable}},
+		EntryDesc: &fwdpb.EntryDesc{
+			Entry: &fwdpb.EntryDesc_Exact{
+				Exact: &fwdpb.ExactEntryDesc{
+					Fields: []*fwdpb.PacketFieldBytes{{
+						FieldId: &fwdpb.PacketFieldId{
+							Field: &fwdpb.PacketField{FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_MPLS_LABEL},
+						},
+						Bytes: []byte{0x00, 0x00, 0x00, 0x64},
+					}},
+				},
+			},
+		},
+	}}
+	if d := cmp.Diff(dplane.gotEntryRemoveReqs, want, protocmp.Transform()); d != "" {
+		t.Errorf("RemoveInsegEntry() failed: diff(-got,+want)\n:%s", d)
+	}
+}
+
+func newTestMPLS(t testing.TB, api switchDataplaneAPI) (saipb.MplsClient, *attrmgr.AttrMgr, func()) {
+	conn, mgr, stopFn := newTestServer(t, func(mgr *attrmgr.AttrMgr, srv *grpc.Server) {
+		newMPLS(mgr, api, srv)
+	})
+	return saipb.NewMplsClient(conn), mgr, stopFn
+}
@@ -110,6 +110,7 @@ func (n *neighbor) CreateNeighborEntries(ctx context.Context, re *saipb.CreateNe
 type groupMember struct {
 	nextHop uint64 // ID of the next hop
 	weight  uint32
+	standby bool // Whether the member is a backup in a protection group
 }
 
 type nextHopGroup struct {
@@ -118,14 +119,18 @@ type nextHopGroup struct {
 	dataplane switchDataplaneAPI
 	groups    map[uint64]map[uint64]*groupMember // groups is map of next hop groups to a map of next hops
 	groupIsV4 map[uint64]bool                    // map from group id to IP protocol version
+	// switchover is a map from protection group id to whether the group
+	// forwards to its standby members instead of its primary members.
+	switchover map[uint64]bool
 }
 
 func newNextHopGroup(mgr *attrmgr.AttrMgr, dataplane switchDataplaneAPI, s *grpc.Server) *nextHopGroup {
 	n := &nextHopGroup{
-		mgr:       mgr,
-		dataplane: dataplane,
-		groups:    map[uint64]map[uint64]*groupMember{},
-		groupIsV4: map[uint64]bool{},
+		mgr:        mgr,
+		dataplane:  dataplane,
+		groups:     map[uint64]map[uint64]*groupMember{},
+		groupIsV4:  map[uint64]bool{},
+		switchover: map[uint64]bool{},
 	}
 	saipb.RegisterNextHopGroupServer(s, n)
 	return n
@@ -141,6 +146,12 @@ func (nhg *nextHopGroup) CreateNextHopGroup(ctx context.Context, req *saipb.Crea
 		return &saipb.CreateNextHopGroupResponse{
 			Oid: id,
 		}, nil
+	case saipb.NextHopGroupType_NEXT_HOP_GROUP_TYPE_PROTECTION:
+		nhg.groups[id] = map[uint64]*groupMember{}
+		nhg.switchover[id] = req.GetSetSwitchover()
+		return &saipb.CreateNextHopGroupResponse{
+			Oid: id,
+		}, nil
 	case saipb.NextHopGroupType_NEXT_HOP_GROUP_TYPE_ECMP_WITH_MEMBERS:
 		nhg.groups[id] = map[uint64]*groupMember{}
 
@@ -203,8 +214,18 @@ func (nhg *nextHopGroup) updateNextHopGroupMember(ctx context.Context, nhgid, mi
 	} else {
 		delete(group, mid)
 	}
+	return nhg.programGroup(ctx, nhgid)
+}
+
+// programGroup programs the next hop group into the dataplane. Protection
+// groups only forward to either their primary or standby members.
+func (nhg *nextHopGroup) programGroup(ctx context.Context, nhgid uint64) error {
+	switchover, isProtection := nhg.switchover[nhgid]
 	var actLists []*fwdpb.ActionList
-	for _, member := range group {
+	for _, member := range nhg.groups[nhgid] {
+		if isProtection && member.standby != switchover {
+			continue
+		}
 		action := fwdconfig.Action(fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_SET, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_NEXT_HOP_ID).WithUint64Value(member.nextHop))
 		actLists = append(actLists, &fwdpb.ActionList{
 			Weight:  uint64(member.weight),
@@ -291,6 +312,7 @@ func (nhg *nextHopGroup) RemoveNextHopGroup(_ context.Context, req *saipb.Remove
 		return nil, status.Errorf(codes.FailedPrecondition, "group %d does not exist", oid)
 	}
 	delete(nhg.groups, oid)
+	delete(nhg.switchover, oid)
 
 	entry := fwdconfig.EntryDesc(fwdconfig.ExactEntry(
 		fwdconfig.PacketFieldBytes(fwdpb.PacketFieldNum_PACKET_FIELD_NUM_NEXT_HOP_GROUP_ID).WithUint64(oid))).Build()
@@ -306,6 +328,22 @@ func (nhg *nextHopGroup) RemoveNextHopGroup(_ context.Context, req *saipb.Remove
 	return &saipb.RemoveNextHopGroupResponse{}, nil
 }
 
+// SetNextHopGroupAttribute sets the attributes of a next hop group. Setting
+// the switchover of a protection group switches its forwarding between its
+// primary and standby members.
+func (nhg *nextHopGroup) SetNextHopGroupAttribute(ctx context.Context, req *saipb.SetNextHopGroupAttributeRequest) (*saipb.SetNextHopGroupAttributeResponse, error) {
+	if req.SetSwitchover != nil {
+		if _, ok := nhg.switchover[req.GetOid()]; !ok {
+			return nil, status.Errorf(codes.FailedPrecondition, "group %d is not a protection group", req.GetOid())
+		}
+		nhg.switchover[req.GetOid()] = req.GetSetSwitchover()
+		if err := nhg.programGroup(ctx, req.GetOid()); err != nil {
+			return nil, err
+		}
+	}
+	return &saipb.SetNextHopGroupAttributeResponse{}, nil
+}
+
 // CreateNextHopGroupMember adds a next hop to a next hop group.
 func (nhg *nextHopGroup) CreateNextHopGroupMember(ctx context.Context, req *saipb.CreateNextHopGroupMemberRequest) (*saipb.CreateNextHopGroupMemberResponse, error) {
 	nhgid := req.GetNextHopGroupId()
@@ -313,6 +351,7 @@ func (nhg *nextHopGroup) CreateNextHopGroupMember(ctx context.Context, req *saip
 	m := &groupMember{
 		nextHop: req.GetNextHopId(),
 		weight:  req.GetWeight(),
+		standby: req.GetConfiguredRole() == saipb.NextHopGroupMemberConfiguredRole_NEXT_HOP_GROUP_MEMBER_CONFIGURED_ROLE_STANDBY,
 	}
 	if err := nhg.updateNextHopGroupMember(ctx, nhgid, mid, m); err != nil {
 		return nil, err
@@ -440,8 +479,9 @@ func (nh *nextHop) CreateNextHop(ctx context.Context, req *saipb.CreateNextHopRe
 
 	nhReq := fwdconfig.TableEntryAddRequest(nh.dataplane.ID(), NHTable).AppendEntry(
 		fwdconfig.EntryDesc(fwdconfig.ExactEntry(fwdconfig.PacketFieldBytes(fwdpb.PacketFieldNum_PACKET_FIELD_NUM_NEXT_HOP_ID).WithUint64(id))),
+		counterAction(req.CounterId)...,
 	).Build()
-	nhReq.Entries[0].Actions = actions
+	nhReq.Entries[0].Actions = append(nhReq.Entries[0].Actions, actions...)
 
 	if _, err := nh.dataplane.TableEntryAdd(ctx, nhReq); err != nil {
 		return nil, err
@@ -534,7 +574,7 @@ func (r *route) CreateRouteEntry(ctx context.Context, req *saipb.CreateRouteEntr
 	}
 	nextType := r.mgr.GetType(fmt.Sprint(req.GetNextHopId()))
 
-	actions := []fwdconfig.ActionDescBuilder{}
+	actions := counterAction(req.CounterId)
 
 	// If the packet action is drop, then next hop is optional.
 	if forward {
@@ -567,7 +607,7 @@ func (r *route) CreateRouteEntry(ctx context.Context, req *saipb.CreateRouteEntr
 				return nil, err
 			}
 			if req.GetNextHopId() == *resp.Attr.CpuPort {
-				_, err := r.dataplane.TableEntryAdd(ctx, fwdconfig.TableEntryAddRequest(r.dataplane.ID(), trapTableID).
+				_, err := r.dataplane.TableEntryAdd(ctx, fwdconfig.TableEntryAddRequest(r.dataplane.ID(), TrapTable).
 					AppendEntry(
 						fwdconfig.EntryDesc(fwdconfig.FlowEntry(
 							fwdconfig.PacketFieldMaskedBytes(fwdpb.PacketFieldNum_PACKET_FIELD_NUM_IP_ADDR_DST).WithBytes(
@@ -90,6 +90,14 @@ func TestCreateNextHopGroup(t *testing.T) {
 		wantAttr: &saipb.NextHopGroupAttribute{
 			Type: saipb.NextHopGroupType_NEXT_HOP_GROUP_TYPE_DYNAMIC_UNORDERED_ECMP.Enum(),
 		},
+	}, {
+		desc: "protection",
+		req: &saipb.CreateNextHopGroupRequest{
+			Type: saipb.NextHopGroupType_NEXT_HOP_GROUP_TYPE_PROTECTION.Enum(),
+		},
+		wantAttr: &saipb.NextHopGroupAttribute{
+			Type: saipb.NextHopGroupType_NEXT_HOP_GROUP_TYPE_PROTECTION.Enum(),
+		},
 	}, {
 		desc: "ecmp with members",
 		req: &saipb.CreateNextHopGroupRequest{
@@ -420,6 +428,93 @@ func TestRemoveNextHopGroupMember(t *testing.T) {
 	}
 }
 
+func TestSetNextHopGroupAttribute(t *testing.T) {
+	tests := []struct {
+		desc        string
+		groupType   saipb.NextHopGroupType
+		switchover  bool
+		wantNextHop uint64
+		wantErr     string
+	}{{
+		desc:        "primary",
+		groupType:   saipb.NextHopGroupType_NEXT_HOP_GROUP_TYPE_PROTECTION,
+		wantNextHop: 3,
+	}, {
+		desc:        "switchover",
+		groupType:   saipb.NextHopGroupType_NEXT_HOP_GROUP_TYPE_PROTECTION,
+		switchover:  true,
+		wantNextHop: 4,
+	}, {
+		desc:       "not protection",
+		groupType:  saipb.NextHopGroupType_NEXT_HOP_GROUP_TYPE_DYNAMIC_UNORDERED_ECMP,
+		switchover: true,
+		wantErr:    "not a protection group",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			dplane := &fakeSwitchDataplane{}
+			c, mgr, stopFn := newTestNextHopGroup(t, dplane)
+			defer stopFn()
+			mgr.StoreAttributes(switchID, &saipb.SwitchAttribute{
+				EcmpHashIpv4: proto.Uint64(10),
+				EcmpHashIpv6: proto.Uint64(10),
+			})
+			mgr.StoreAttributes(10, &saipb.HashAttribute{
+				NativeHashFieldList: []saipb.NativeHashField{saipb.NativeHashField_NATIVE_HASH_FIELD_DST_IP},
+			})
+			mgr.StoreAttributes(3, &saipb.NextHopAttribute{Ip: []byte{127, 0, 0, 1}})
+			mgr.StoreAttributes(4, &saipb.NextHopAttribute{Ip: []byte{127, 0, 0, 2}})
+
+			ctx := context.Background()
+			group, err := c.CreateNextHopGroup(ctx, &saipb.CreateNextHopGroupRequest{Type: tt.groupType.Enum()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for nh, role := range map[uint64]saipb.NextHopGroupMemberConfiguredRole{
+				3: saipb.NextHopGroupMemberConfiguredRole_NEXT_HOP_GROUP_MEMBER_CONFIGURED_ROLE_PRIMARY,
+				4: saipb.NextHopGroupMemberConfiguredRole_NEXT_HOP_GROUP_MEMBER_CONFIGURED_ROLE_STANDBY,
+			} {
+				if _, err := c.CreateNextHopGroupMember(ctx, &saipb.CreateNextHopGroupMemberRequest{
+					NextHopGroupId: proto.Uint64(group.GetOid()),
+					NextHopId:      proto.Uint64(nh),
+					Weight:         proto.Uint32(1),
+					ConfiguredRole: role.Enum(),
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, gotErr := c.SetNextHopGroupAttribute(ctx, &saipb.SetNextHopGroupAttributeRequest{
+				Oid:           group.GetOid(),
+				SetSwitchover: proto.Bool(tt.switchover),
+			})
+			if diff := errdiff.Check(gotErr, tt.wantErr); diff != "" {
+				t.Fatalf("SetNextHopGroupAttribute() unexpected err: %s", diff)
+			}
+			if gotErr != nil {
+				return
+			}
+			got := dplane.gotEntryAddReqs[len(dplane.gotEntryAddReqs)-1].GetEntries()[0].GetActions()[0].GetSelect().GetActionLists()
+			want := []*fwdpb.ActionList{{
+				Weight: 1,
+				Actions: []*fwdpb.ActionDesc{{
+					ActionType: fwdpb.ActionType_ACTION_TYPE_UPDATE,
+					Action: &fwdpb.ActionDesc_Update{
+						Update: &fwdpb.UpdateActionDesc{
+							FieldId: &fwdpb.PacketFieldId{Field: &fwdpb.PacketField{FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_NEXT_HOP_ID}},
+							Type:    fwdpb.UpdateType_UPDATE_TYPE_SET,
+							Field:   &fwdpb.PacketFieldId{Field: &fwdpb.PacketField{}},
+							Value:   binary.BigEndian.AppendUint64(nil, tt.wantNextHop),
+						},
+					},
+				}},
+			}}
+			if d := cmp.Diff(got, want, protocmp.Transform()); d != "" {
+				t.Errorf("SetNextHopGroupAttribute() failed: diff(-got,+want)\n:%s", d)
+			}
+		})
+	}
+}
+
 func TestCreateNextHop(t *testing.T) {
 	tests := []struct {
 		desc     string
@@ -492,6 +587,76 @@ func TestCreateNextHop(t *testing.T) {
 				},
 			}},
 		},
+	}, {
+		desc: "counted ip next hop",
+		req: &saipb.CreateNextHopRequest{
+			Type:              saipb.NextHopType_NEXT_HOP_TYPE_IP.Enum(),
+			RouterInterfaceId: proto.Uint64(10),
+			Ip:                []byte{127, 0, 0, 1},
+			CounterId:         proto.Uint64(20),
+		},
+		wantAttr: &saipb.NextHopAttribute{
+			Type:              saipb.NextHopType_NEXT_HOP_TYPE_IP.Enum(),
+			RouterInterfaceId: proto.Uint64(10),
+			Ip:                []byte{127, 0, 0, 1},
+			CounterId:         proto.Uint64(20),
+		},
+		wantReq: &fwdpb.TableEntryAddRequest{
+			ContextId: &fwdpb.ContextId{Id: "foo"},
+			TableId:   &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: NHTable}},
+			Entries: []*fwdpb.TableEntryAddRequest_Entry{{
+				Actions: []*fwdpb.ActionDesc{{
+					ActionType: fwdpb.ActionType_ACTION_TYPE_FLOW_COUNTER,
+					Action: &fwdpb.ActionDesc_Flow{
+						Flow: &fwdpb.FlowCounterActionDesc{
+							CounterId: &fwdpb.FlowCounterId{ObjectId: &fwdpb.ObjectId{Id: "20"}},
+						},
+					},
+				}, {
+					ActionType: fwdpb.ActionType_ACTION_TYPE_UPDATE,
+					Action: &fwdpb.ActionDesc_Update{
+						Update: &fwdpb.UpdateActionDesc{
+							Type: fwdpb.UpdateType_UPDATE_TYPE_SET,
+							FieldId: &fwdpb.PacketFieldId{
+								Field: &fwdpb.PacketField{
+									FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_OUTPUT_IFACE,
+								},
+							},
+							Field: &fwdpb.PacketFieldId{Field: &fwdpb.PacketField{}},
+							Value: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a},
+						},
+					},
+				}, {
+					ActionType: fwdpb.ActionType_ACTION_TYPE_UPDATE,
+					Action: &fwdpb.ActionDesc_Update{
+						Update: &fwdpb.UpdateActionDesc{
+							Type: fwdpb.UpdateType_UPDATE_TYPE_SET,
+							FieldId: &fwdpb.PacketFieldId{
+								Field: &fwdpb.PacketField{
+									FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_NEXT_HOP_IP,
+								},
+							},
+							Field: &fwdpb.PacketFieldId{Field: &fwdpb.PacketField{}},
+							Value: []byte{0x7f, 0x00, 0x00, 0x01},
+						},
+					},
+				}},
+				EntryDesc: &fwdpb.EntryDesc{
+					Entry: &fwdpb.EntryDesc_Exact{
+						Exact: &fwdpb.ExactEntryDesc{
+							Fields: []*fwdpb.PacketFieldBytes{{
+								Bytes: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+								FieldId: &fwdpb.PacketFieldId{
+									Field: &fwdpb.PacketField{
+										FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_NEXT_HOP_ID,
+									},
+								},
+							}},
+						},
+					},
+				},
+			}},
+		},
 	}, {
 		desc: "success tunnel next hop",
 		req: &saipb.CreateNextHopRequest{
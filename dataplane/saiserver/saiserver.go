@@ -35,10 +35,6 @@ type bfd struct {
 	saipb.UnimplementedBfdServer
 }
 
-type counter struct {
-	saipb.UnimplementedCounterServer
-}
-
 type debugCounter struct {
 	saipb.UnimplementedDebugCounterServer
 }
@@ -75,10 +71,6 @@ type mirror struct {
 	saipb.UnimplementedMirrorServer
 }
 
-type mpls struct {
-	saipb.UnimplementedMplsServer
-}
-
 type nat struct {
 	saipb.UnimplementedNatServer
 }
@@ -118,7 +110,6 @@ type Server struct {
 	mgr          *attrmgr.AttrMgr
 	initialized  bool
 	bfd          *bfd
-	counter      *counter
 	debugCounter *debugCounter
 	dtel         *dtel
 	fdb          *fdb
@@ -128,7 +119,6 @@ type Server struct {
 	macsec       *macsec
 	mcastFdb     *mcastFdb
 	mirror       *mirror
-	mpls         *mpls
 	nat          *nat
 	samplePacket *samplePacket
 	srv6         *srv6
@@ -150,8 +140,6 @@ func (s *Server) ObjectTypeQuery(ctx context.Context, req *saipb.ObjectTypeQuery
 func (s *Server) Initialize(ctx context.Context, _ *saipb.InitializeRequest) (*saipb.InitializeResponse, error) {
 	if s.initialized {
 		slog.InfoContext(ctx, "dataplane already intialized, reseting")
-		s.mgr.Reset()
-		s.saiSwitch.Reset()
 		if err := s.Reset(ctx); err != nil {
 			return nil, err
 		}
@@ -161,7 +149,10 @@ func (s *Server) Initialize(ctx context.Context, _ *saipb.InitializeRequest) (*s
 	return &saipb.InitializeResponse{}, nil
 }
 
+// Reset removes all the SAI objects and recreates the forwarding context.
 func (s *Server) Reset(ctx context.Context) error {
+	s.mgr.Reset()
+	s.saiSwitch.Reset()
 	_, err := s.forwardingContext.ContextDelete(ctx, &fwdpb.ContextDeleteRequest{
 		ContextId: &fwdpb.ContextId{Id: s.forwardingContext.id},
 	})
@@ -196,7 +187,6 @@ func New(ctx context.Context, mgr *attrmgr.AttrMgr, s *grpc.Server, opts *dplane
 		mgr:               mgr,
 		forwardingContext: fwdCtx,
 		bfd:               &bfd{},
-		counter:           &counter{},
 		debugCounter:      &debugCounter{},
 		dtel:              &dtel{},
 		fdb:               &fdb{},
@@ -206,7 +196,6 @@ func New(ctx context.Context, mgr *attrmgr.AttrMgr, s *grpc.Server, opts *dplane
 		macsec:            &macsec{},
 		mcastFdb:          &mcastFdb{},
 		mirror:            &mirror{},
-		mpls:              &mpls{},
 		nat:               &nat{},
 		samplePacket:      &samplePacket{},
 		srv6:              &srv6{},
@@ -218,7 +207,6 @@ func New(ctx context.Context, mgr *attrmgr.AttrMgr, s *grpc.Server, opts *dplane
 	fwdpb.RegisterInfoServer(s, fwdCtx)
 	saipb.RegisterEntrypointServer(s, srv)
 	saipb.RegisterBfdServer(s, srv.bfd)
-	saipb.RegisterCounterServer(s, srv.counter)
 	saipb.RegisterDebugCounterServer(s, srv.debugCounter)
 	saipb.RegisterDtelServer(s, srv.dtel)
 	saipb.RegisterFdbServer(s, srv.fdb)
@@ -228,7 +216,6 @@ func New(ctx context.Context, mgr *attrmgr.AttrMgr, s *grpc.Server, opts *dplane
 	saipb.RegisterMacsecServer(s, srv.macsec)
 	saipb.RegisterMcastFdbServer(s, srv.mcastFdb)
 	saipb.RegisterMirrorServer(s, srv.mirror)
-	saipb.RegisterMplsServer(s, srv.mpls)
 	saipb.RegisterNatServer(s, srv.nat)
 	saipb.RegisterSamplepacketServer(s, srv.samplePacket)
 	saipb.RegisterSrv6Server(s, srv.srv6)
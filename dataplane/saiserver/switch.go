@@ -41,6 +41,7 @@ type saiSwitch struct {
 	opts            *dplaneopts.Options
 	acl             *acl
 	buffer          *buffer
+	counter         *counter
 	port            *port
 	vlan            *vlan
 	stp             *stp
@@ -56,6 +57,7 @@ type saiSwitch struct {
 	nextHop         *nextHop
 	policer         *policer
 	route           *route
+	mpls            *mpls
 	lag             *lag
 	tunnel          *tunnel
 	queue           *queue
@@ -172,6 +174,7 @@ const (
 	IngressVRFTable       = "ingress-vrf"
 	FIBV4Table            = "fib-v4"
 	FIBV6Table            = "fib-v6"
+	MPLSTable             = "mpls"
 	SRCMACTable           = "port-mac"
 	FIBSelectorTable      = "fib-selector"
 	NeighborTable         = "neighbor"
@@ -215,6 +218,7 @@ func newSwitch(mgr *attrmgr.AttrMgr, engine switchDataplaneAPI, s *grpc.Server,
 		opts:            opts,
 		acl:             newACL(mgr, dplane, s),
 		policer:         newPolicer(mgr, dplane, s),
+		counter:         newCounter(mgr, dplane, s),
 		port:            port,
 		vlan:            vlan,
 		stp:             &stp{},
@@ -229,6 +233,7 @@ func newSwitch(mgr *attrmgr.AttrMgr, engine switchDataplaneAPI, s *grpc.Server,
 		nextHopGroup:    newNextHopGroup(mgr, engine, s),
 		nextHop:         newNextHop(mgr, engine, s),
 		route:           newRoute(mgr, engine, s),
+		mpls:            newMPLS(mgr, engine, s),
 		routerInterface: newRouterInterface(mgr, engine, s),
 		lag:             newLAG(mgr, engine, s),
 		tunnel:          newTunnel(mgr, engine, s),
@@ -322,6 +327,26 @@ func (sw *saiSwitch) CreateSwitch(ctx context.Context, _ *saipb.CreateSwitchRequ
 	if _, err := sw.dataplane.TableCreate(ctx, v6FIB); err != nil {
 		return nil, err
 	}
+	mplsFIB := &fwdpb.TableCreateRequest{
+		ContextId: &fwdpb.ContextId{Id: sw.dataplane.ID()},
+		Desc: &fwdpb.TableDesc{
+			TableType: fwdpb.TableType_TABLE_TYPE_EXACT,
+			TableId:   &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: MPLSTable}},
+			Actions:   []*fwdpb.ActionDesc{fwdconfig.Action(fwdconfig.UpdateAction(fwdpb.UpdateType_UPDATE_TYPE_BIT_WRITE, fwdpb.PacketFieldNum_PACKET_FIELD_NUM_PACKET_ACTION).WithBitOp(1, 0).WithValue([]byte{0})).Build()},
+			Table: &fwdpb.TableDesc_Exact{
+				Exact: &fwdpb.ExactTableDesc{
+					FieldIds: []*fwdpb.PacketFieldId{{
+						Field: &fwdpb.PacketField{
+							FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_MPLS_LABEL,
+						},
+					}},
+				},
+			},
+		},
+	}
+	if _, err := sw.dataplane.TableCreate(ctx, mplsFIB); err != nil {
+		return nil, err
+	}
 	portMAC := &fwdpb.TableCreateRequest{
 		ContextId: &fwdpb.ContextId{Id: sw.dataplane.ID()},
 		Desc: &fwdpb.TableDesc{
@@ -536,7 +561,7 @@ func (sw *saiSwitch) CreateSwitch(ctx context.Context, _ *saipb.CreateSwitchRequ
 		Desc: &fwdpb.TableDesc{
 			TableType: fwdpb.TableType_TABLE_TYPE_FLOW,
 			// Actions:   []*fwdpb.ActionDesc{fwdconfig.Action(fwdconfig.LookupAction(portToHostifTable)).Build()},
-			TableId: &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: trapTableID}},
+			TableId: &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: TrapTable}},
 			Table: &fwdpb.TableDesc_Flow{
 				Flow: &fwdpb.FlowTableDesc{
 					BankCount: 1,
@@ -551,7 +576,7 @@ func (sw *saiSwitch) CreateSwitch(ctx context.Context, _ *saipb.CreateSwitchRequ
 	_, err = sw.dataplane.TableEntryAdd(ctx, fwdconfig.TableEntryAddRequest(sw.dataplane.ID(), PreIngressActionTable).
 		AppendEntry(
 			fwdconfig.EntryDesc(fwdconfig.ActionEntry("trap", fwdpb.ActionEntryDesc_INSERT_METHOD_APPEND)),
-			fwdconfig.LookupAction(trapTableID)).
+			fwdconfig.LookupAction(TrapTable)).
 		Build(),
 	)
 	if err != nil {
@@ -1118,13 +1143,7 @@ func (sw *saiSwitch) Reset() {
 
 // createFIBSelector creates a table that controls which forwarding table is used.
 func (sw *saiSwitch) createFIBSelector(ctx context.Context) error {
-	fieldID := &fwdpb.PacketFieldId{
-		Field: &fwdpb.PacketField{
-			FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_IP_VERSION,
-		},
-	}
-
-	ipVersion := &fwdpb.TableCreateRequest{
+	ethType := &fwdpb.TableCreateRequest{
 		ContextId: &fwdpb.ContextId{Id: sw.dataplane.ID()},
 		Desc: &fwdpb.TableDesc{
 			TableType: fwdpb.TableType_TABLE_TYPE_EXACT,
@@ -1132,65 +1151,34 @@ func (sw *saiSwitch) createFIBSelector(ctx context.Context) error {
 			Actions:   []*fwdpb.ActionDesc{{ActionType: fwdpb.ActionType_ACTION_TYPE_CONTINUE}},
 			Table: &fwdpb.TableDesc_Exact{
 				Exact: &fwdpb.ExactTableDesc{
-					FieldIds: []*fwdpb.PacketFieldId{fieldID},
+					FieldIds: []*fwdpb.PacketFieldId{{
+						Field: &fwdpb.PacketField{
+							FieldNum: fwdpb.PacketFieldNum_PACKET_FIELD_NUM_ETHER_TYPE,
+						},
+					}},
 				},
 			},
 		},
 	}
-	if _, err := sw.dataplane.TableCreate(ctx, ipVersion); err != nil {
+	if _, err := sw.dataplane.TableCreate(ctx, ethType); err != nil {
 		return err
 	}
-	v4Acts := []*fwdpb.ActionDesc{{
-		ActionType: fwdpb.ActionType_ACTION_TYPE_LOOKUP,
-		Action: &fwdpb.ActionDesc_Lookup{
-			Lookup: &fwdpb.LookupActionDesc{
-				TableId: &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: FIBV4Table}},
-			},
-		},
-	}}
-	v6Acts := []*fwdpb.ActionDesc{{
-		ActionType: fwdpb.ActionType_ACTION_TYPE_LOOKUP,
-		Action: &fwdpb.ActionDesc_Lookup{
-			Lookup: &fwdpb.LookupActionDesc{
-				TableId: &fwdpb.TableId{ObjectId: &fwdpb.ObjectId{Id: FIBV6Table}},
-			},
-		},
-	}}
-
-	entries := &fwdpb.TableEntryAddRequest{
-		ContextId: &fwdpb.ContextId{Id: sw.dataplane.ID()},
-		TableId: &fwdpb.TableId{
-			ObjectId: &fwdpb.ObjectId{
-				Id: FIBSelectorTable,
-			},
-		},
-		Entries: []*fwdpb.TableEntryAddRequest_Entry{{
-			EntryDesc: &fwdpb.EntryDesc{
-				Entry: &fwdpb.EntryDesc_Exact{
-					Exact: &fwdpb.ExactEntryDesc{
-						Fields: []*fwdpb.PacketFieldBytes{{
-							Bytes:   []byte{0x4},
-							FieldId: fieldID,
-						}},
-					},
-				},
-			},
-			Actions: v4Acts,
-		}, {
-			EntryDesc: &fwdpb.EntryDesc{
-				Entry: &fwdpb.EntryDesc_Exact{
-					Exact: &fwdpb.ExactEntryDesc{
-						Fields: []*fwdpb.PacketFieldBytes{{
-							Bytes:   []byte{0x6},
-							FieldId: fieldID,
-						}},
-					},
-				},
-			},
-			Actions: v6Acts,
-		}},
+	// MPLS packets are forwarded by their top label, even if the IP payload is parsed.
+	req := fwdconfig.TableEntryAddRequest(sw.dataplane.ID(), FIBSelectorTable)
+	for _, fib := range []struct {
+		ethType []byte
+		table   string
+	}{
+		{[]byte{0x08, 0x00}, FIBV4Table},
+		{[]byte{0x86, 0xDD}, FIBV6Table},
+		{[]byte{0x88, 0x47}, MPLSTable},
+	} {
+		req.AppendEntry(
+			fwdconfig.EntryDesc(fwdconfig.ExactEntry(fwdconfig.PacketFieldBytes(fwdpb.PacketFieldNum_PACKET_FIELD_NUM_ETHER_TYPE).WithBytes(fib.ethType))),
+			fwdconfig.LookupAction(fib.table),
+		)
 	}
-	if _, err := sw.dataplane.TableEntryAdd(ctx, entries); err != nil {
+	if _, err := sw.dataplane.TableEntryAdd(ctx, req.Build()); err != nil {
 		return err
 	}
 	return nil
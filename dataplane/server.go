@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sync/atomic"
 
 	"github.com/openconfig/ygnmi/ygnmi"
 	"google.golang.org/grpc"
@@ -29,6 +30,8 @@ import (
 	"github.com/openconfig/lemming/dataplane/dplaneopts"
 	_ "github.com/openconfig/lemming/dataplane/kernel/tap"
 	"github.com/openconfig/lemming/dataplane/protocol"
+	"github.com/openconfig/lemming/dataplane/protocol/icmp"
+	"github.com/openconfig/lemming/dataplane/protocol/linkqual"
 	"github.com/openconfig/lemming/dataplane/saiserver"
 	"github.com/openconfig/lemming/dataplane/saiserver/attrmgr"
 	"github.com/openconfig/lemming/dataplane/standalone/pkthandler/pktiohandler"
@@ -44,12 +47,24 @@ import (
 // Dataplane is an implementation of Dataplane HAL API.
 type Dataplane struct {
 	saiserv     *saiserver.Server
-	srv         *grpc.Server
 	lis         net.Listener
 	reconcilers []reconciler.Reconciler
 	opt         *dplaneopts.Options
 	cancelFn    func()
 	pr          *protocol.Registry
+	// local is the state of the started dataplane used by the probes and
+	// link tests, which can run concurrently with Start and Stop.
+	local atomic.Pointer[localPackets]
+}
+
+// localPackets sends and receives the packets originated by the device of a
+// started dataplane.
+type localPackets struct {
+	prober      *icmp.Prober
+	probeSrc    probeSourcer
+	labels      labelProgrammer
+	linkCounter *linkqual.Counter
+	cpuPortID   uint64
 }
 
 // New create a new dataplane instance.
@@ -86,7 +101,7 @@ func (d *Dataplane) ID() string {
 
 // Start starts the HAL gRPC server and packet forwarding engine.
 func (d *Dataplane) Start(ctx context.Context, c gpb.GNMIClient, target string) error {
-	if d.srv != nil {
+	if d.cancelFn != nil {
 		return fmt.Errorf("dataplane already started")
 	}
 
@@ -182,12 +197,26 @@ func (d *Dataplane) Start(ctx context.Context, c gpb.GNMIClient, target string)
 	if err != nil {
 		return err
 	}
+	local := &localPackets{
+		prober:      icmp.New(d.injectProbe),
+		linkCounter: linkqual.NewCounter(),
+		cpuPortID:   swAttrs.GetAttr().GetCpuPort(),
+	}
+	if err := d.pr.Register("icmp", local.prober); err != nil {
+		return err
+	}
+	if err := d.pr.Register("linkqual", local.linkCounter); err != nil {
+		return err
+	}
 	d.pr.Start()
 	go h.ManagePorts(portCtl)
 	go h.StreamPackets(d.pr)
 
 	if d.opt.Reconcilation {
-		d.reconcilers = append(d.reconcilers, getReconcilers(conn, swResp.Oid, *swAttrs.GetAttr().CpuPort, "lucius")...)
+		recs, ps, lp := getReconcilers(conn, swResp.Oid, *swAttrs.GetAttr().CpuPort, "lucius")
+		d.reconcilers = recs
+		local.probeSrc = ps
+		local.labels = lp
 
 		for _, rec := range d.reconcilers {
 			if err := rec.Start(ctx, c, target); err != nil {
@@ -195,6 +224,7 @@ func (d *Dataplane) Start(ctx context.Context, c gpb.GNMIClient, target string)
 			}
 		}
 	}
+	d.local.Store(local)
 
 	return nil
 }
@@ -212,18 +242,31 @@ func (d *Dataplane) SaiServer() *saiserver.Server {
 	return d.saiserv
 }
 
-// Stop gracefully stops the server.
+// Stop stops the packet forwarding engine and the reconcilers. The HAL gRPC
+// server keeps running, so the dataplane can be started again.
 func (d *Dataplane) Stop(ctx context.Context) error {
+	if d.cancelFn == nil {
+		return nil
+	}
+	d.local.Store(nil)
 	d.cancelFn()
+	d.cancelFn = nil
+	d.pr.Stop()
 	for _, rec := range d.reconcilers {
 		if err := rec.Stop(ctx); err != nil {
 			return fmt.Errorf("failed to stop handler %q: %v", rec.ID(), err)
 		}
 	}
-	d.srv.GracefulStop()
+	d.reconcilers = nil
 	return nil
 }
 
+// Reset removes all the SAI objects and forwarding state, as if the switch
+// had been reinitialized. The dataplane should be stopped first.
+func (d *Dataplane) Reset(ctx context.Context) error {
+	return d.saiserv.Reset(ctx)
+}
+
 // Validate is a noop to implement to the reconciler interface.
 func (d *Dataplane) Validate(*oc.Root) error {
 	return nil
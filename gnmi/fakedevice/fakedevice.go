@@ -16,6 +16,7 @@ package fakedevice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand/v2"
@@ -29,6 +30,7 @@ import (
 	plqpb "github.com/openconfig/gnoi/packet_link_qualification"
 	spb "github.com/openconfig/gnoi/system"
 
+	"github.com/openconfig/lemming/dataplane/protocol/linkqual"
 	"github.com/openconfig/lemming/gnmi"
 	"github.com/openconfig/lemming/gnmi/gnmiclient"
 	"github.com/openconfig/lemming/gnmi/oc"
@@ -44,9 +46,20 @@ const (
 	BGPRoutingProtocol     = "BGP"
 )
 
-// Reboot updates the system boot time to the provided Unix time.
-func Reboot(ctx context.Context, c *ygnmi.Client, rebootTime int64) error {
-	_, err := gnmiclient.Replace(gnmi.AddTimestampMetadata(ctx, rebootTime), c, ocpath.Root().System().BootTime().State(), uint64(rebootTime))
+// defaultSoftwareVersion is the software version reported until a package is
+// activated.
+const defaultSoftwareVersion = "current"
+
+// Reboot updates the system boot time to the provided Unix time, along with
+// the last reboot time and reason of the chassis.
+func Reboot(ctx context.Context, c *ygnmi.Client, rebootTime int64, cfg *configpb.Config) error {
+	batch := &ygnmi.SetBatch{}
+	gnmiclient.BatchReplace(batch, ocpath.Root().System().BootTime().State(), uint64(rebootTime))
+	if chassisName := cfg.GetComponents().GetChassisName(); chassisName != "" {
+		gnmiclient.BatchReplace(batch, ocpath.Root().Component(chassisName).LastRebootTime().State(), uint64(rebootTime))
+		gnmiclient.BatchReplace(batch, ocpath.Root().Component(chassisName).LastRebootReason().State(), oc.PlatformTypes_COMPONENT_REBOOT_REASON_REBOOT_USER_INITIATED)
+	}
+	_, err := batch.Set(gnmi.AddTimestampMetadata(ctx, rebootTime), c)
 	return err
 }
 
@@ -119,6 +132,90 @@ func SwitchoverSupervisor(ctx context.Context, c *ygnmi.Client, targetSupervisor
 	return nil
 }
 
+// SetSoftwareVersion updates the software version reported by the chassis
+// and its supervisors, after a package of that version is activated.
+func SetSoftwareVersion(ctx context.Context, c *ygnmi.Client, version string, cfg *configpb.Config) error {
+	components := cfg.GetComponents()
+	batch := &ygnmi.SetBatch{}
+	for _, name := range []string{components.GetChassisName(), components.GetSupervisor1Name(), components.GetSupervisor2Name()} {
+		if name != "" {
+			gnmiclient.BatchReplace(batch, ocpath.Root().Component(name).SoftwareVersion().State(), version)
+		}
+	}
+	_, err := batch.Set(gnmi.AddTimestampMetadata(ctx, time.Now().UnixNano()), c)
+	return err
+}
+
+// softwareVersion returns the software version reported by the component, so
+// that an activated version is kept across reboots.
+func softwareVersion(ctx context.Context, c *ygnmi.Client, componentName string) string {
+	v, err := ygnmi.Lookup(ctx, c, ocpath.Root().Component(componentName).SoftwareVersion().State())
+	if err != nil {
+		return defaultSoftwareVersion
+	}
+	if version, ok := v.Val(); ok {
+		return version
+	}
+	return defaultSoftwareVersion
+}
+
+// ClearLabelEntryCounters zeroes the counters of the network instance's AFT
+// label entries at clearTime. Labels without an entry are skipped.
+func ClearLabelEntryCounters(ctx context.Context, c *ygnmi.Client, ni string, labels []uint32, clearTime int64) error {
+	batch := &ygnmi.SetBatch{}
+	cleared := false
+	for _, label := range labels {
+		path := ocpath.Root().NetworkInstance(ni).Afts().LabelEntry(oc.UnionUint32(label)).State()
+		entry, err := ygnmi.Get(ctx, c, path)
+		if err != nil {
+			if errors.Is(err, ygnmi.ErrNotPresent) {
+				continue
+			}
+			return err
+		}
+		cleared = true
+		entry.Counters = zeroLabelEntryCounters()
+		gnmiclient.BatchReplace(batch, path, entry)
+	}
+	if !cleared {
+		return nil
+	}
+	_, err := batch.Set(gnmi.AddTimestampMetadata(ctx, clearTime), c)
+	return err
+}
+
+// ResignalLabelEntries tears down the network instance's AFT label entries
+// and programs them again at resignalTime with zeroed counters. Labels
+// without an entry are skipped.
+func ResignalLabelEntries(ctx context.Context, c *ygnmi.Client, ni string, labels []uint32, resignalTime int64) error {
+	timestampedCtx := gnmi.AddTimestampMetadata(ctx, resignalTime)
+	for _, label := range labels {
+		path := ocpath.Root().NetworkInstance(ni).Afts().LabelEntry(oc.UnionUint32(label)).State()
+		entry, err := ygnmi.Get(ctx, c, path)
+		if err != nil {
+			if errors.Is(err, ygnmi.ErrNotPresent) {
+				continue
+			}
+			return err
+		}
+		if _, err := gnmiclient.Delete(timestampedCtx, c, path); err != nil {
+			return fmt.Errorf("failed to tear down label entry %d: %v", label, err)
+		}
+		entry.Counters = zeroLabelEntryCounters()
+		if _, err := gnmiclient.Replace(timestampedCtx, c, path, entry); err != nil {
+			return fmt.Errorf("failed to program label entry %d: %v", label, err)
+		}
+	}
+	return nil
+}
+
+func zeroLabelEntryCounters() *oc.NetworkInstance_Afts_LabelEntry_Counters {
+	return &oc.NetworkInstance_Afts_LabelEntry_Counters{
+		OctetsForwarded:  ygot.Uint64(0),
+		PacketsForwarded: ygot.Uint64(0),
+	}
+}
+
 // KillProcess simulates process termination and restart functionality
 func KillProcess(ctx context.Context, c *ygnmi.Client, pid uint32, processName string, signal spb.KillProcessRequest_Signal, restart bool, cfg *configpb.Config) error {
 	log.Infof("KillProcess called with pid=%d, name=%s, signal=%v, restart=%v", pid, processName, signal, restart)
@@ -190,25 +287,41 @@ func KillProcess(ctx context.Context, c *ygnmi.Client, pid uint32, processName s
 	return nil
 }
 
-// PingPacketResult represents the result of a single ping packet
-type PingPacketResult struct {
-	Sequence int32
-	RTT      time.Duration
-	Bytes    uint32
-	TTL      int32
-	Success  bool
+// StartContainerProcess adds a process running the container instance to the
+// system process list, and returns its PID.
+func StartContainerProcess(ctx context.Context, c *ygnmi.Client, instance string) (uint32, error) {
+	pid, err := generateNewPID(ctx, c, 0)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UnixNano()
+	process := &oc.System_Process{
+		Name:      ygot.String(instance),
+		Pid:       ygot.Uint64(uint64(pid)),
+		StartTime: ygot.Uint64(uint64(now)),
+	}
+	if _, err := gnmiclient.Replace(gnmi.AddTimestampMetadata(ctx, now), c, ocpath.Root().System().Process(uint64(pid)).State(), process); err != nil {
+		return 0, fmt.Errorf("failed to start process of container %s: %v", instance, err)
+	}
+	log.Infof("Started container %s with PID: %d", instance, pid)
+	return pid, nil
 }
 
-// PingSimulation simulates ping operation with configurable network conditions
-func PingSimulation(ctx context.Context, destination string, count int32, interval time.Duration, wait time.Duration, size uint32, responseChan chan<- *PingPacketResult, cfg *configpb.Config) error {
-	log.Infof("Starting ping simulation to %s with count=%d, interval=%v, wait=%v",
-		destination, count, interval, wait)
-
-	baseLatencyMs := int64(10)
-	ttl := int32(64)
+// StopContainerProcess removes the process of a container from the system
+// process list.
+func StopContainerProcess(ctx context.Context, c *ygnmi.Client, pid uint32) error {
+	if _, err := gnmiclient.Delete(gnmi.AddTimestampMetadata(ctx, time.Now().UnixNano()), c, ocpath.Root().System().Process(uint64(pid)).State()); err != nil {
+		return fmt.Errorf("failed to stop container process (PID: %d): %v", pid, err)
+	}
+	log.Infof("Stopped container process (PID: %d)", pid)
+	return nil
+}
 
-	var jitterMs int64
-	var packetLossRate float64
+// networkSimParams returns the base latency, latency jitter, packet loss rate
+// and TTL to simulate, using the network simulation config where set.
+func networkSimParams(cfg *configpb.Config) (baseLatencyMs, jitterMs int64, packetLossRate float64, ttl int32) {
+	baseLatencyMs = 10
+	ttl = 64
 
 	// Use config values if available
 	if cfg != nil && cfg.GetNetworkSimulation() != nil {
@@ -221,6 +334,24 @@ func PingSimulation(ctx context.Context, destination string, count int32, interv
 			ttl = cfg.GetNetworkSimulation().GetDefaultTtl()
 		}
 	}
+	return baseLatencyMs, jitterMs, packetLossRate, ttl
+}
+
+// PingPacketResult represents the result of a single ping packet
+type PingPacketResult struct {
+	Sequence int32
+	RTT      time.Duration
+	Bytes    uint32
+	TTL      int32
+	Success  bool
+}
+
+// PingSimulation simulates ping operation with configurable network conditions
+func PingSimulation(ctx context.Context, destination string, count int32, interval time.Duration, wait time.Duration, size uint32, responseChan chan<- *PingPacketResult, cfg *configpb.Config) error {
+	log.Infof("Starting ping simulation to %s with count=%d, interval=%v, wait=%v",
+		destination, count, interval, wait)
+
+	baseLatencyMs, jitterMs, packetLossRate, ttl := networkSimParams(cfg)
 
 	// Create random generator for this simulation
 	rng := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), uint64(time.Now().UnixNano()>>32))) //nolint:gosec // Using math/rand for network simulation
@@ -327,6 +458,63 @@ func PingSimulation(ctx context.Context, destination string, count int32, interv
 	return nil
 }
 
+// TracerouteProbeResult represents the result of a single traceroute probe
+type TracerouteProbeResult struct {
+	Hop     int32
+	Address string
+	RTT     time.Duration
+	Success bool
+}
+
+// TracerouteSimulation simulates a traceroute along path, which holds the
+// addresses of the hops to the destination ordered from the nearest one.
+// Each hop answers after the configured base latency for every hop traversed
+// plus jitter; probes are lost at the configured packet loss rate.
+func TracerouteSimulation(ctx context.Context, path []string, initialTTL, maxTTL int32, probes int, wait time.Duration, responseChan chan<- *TracerouteProbeResult, cfg *configpb.Config) error {
+	log.Infof("Starting traceroute simulation along %v with initial ttl=%d, max ttl=%d, wait=%v",
+		path, initialTTL, maxTTL, wait)
+
+	baseLatencyMs, jitterMs, packetLossRate, _ := networkSimParams(cfg)
+
+	// Create random generator for this simulation
+	rng := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), uint64(time.Now().UnixNano()>>32))) //nolint:gosec // Using math/rand for network simulation
+
+	lastHop := min(maxTTL, int32(len(path)))
+	for hop := initialTTL; hop <= lastHop; hop++ {
+		for i := 0; i < probes; i++ {
+			result := &TracerouteProbeResult{Hop: hop}
+
+			jitterOffset := rng.Int64N(jitterMs*2+1) - jitterMs
+			networkLatency := time.Duration(max(baseLatencyMs*int64(hop)+jitterOffset, 1)) * time.Millisecond
+
+			delay := networkLatency
+			if packetLossRate > 0 && rng.Float64() < packetLossRate || networkLatency > wait {
+				delay = wait
+			} else {
+				result.Address = path[hop-1]
+				result.RTT = networkLatency
+				result.Success = true
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+
+			select {
+			case responseChan <- result:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	log.Infof("Traceroute simulation along %v completed", path)
+	return nil
+}
+
 // NewBootTimeTask initializes boot-related paths.
 func NewBootTimeTask(cfg *configpb.Config) *reconciler.BuiltReconciler {
 	chassisName := cfg.GetComponents().GetChassisName()
@@ -334,14 +522,14 @@ func NewBootTimeTask(cfg *configpb.Config) *reconciler.BuiltReconciler {
 	rec := reconciler.NewBuilder("boot time").
 		WithStart(func(ctx context.Context, c *ygnmi.Client) error {
 			now := time.Now().UnixNano()
-			if err := Reboot(ctx, c, now); err != nil {
+			if _, err := gnmiclient.Replace(gnmi.AddTimestampMetadata(ctx, now), c, ocpath.Root().System().BootTime().State(), uint64(now)); err != nil {
 				return err
 			}
 			if _, err := gnmiclient.Replace(gnmi.AddTimestampMetadata(ctx, now), c, ocpath.Root().Component(chassisName).State(), &oc.Component{
 				Name:            ygot.String(chassisName),
 				Type:            oc.PlatformTypes_OPENCONFIG_HARDWARE_COMPONENT_CHASSIS,
 				OperStatus:      oc.PlatformTypes_COMPONENT_OPER_STATUS_ACTIVE,
-				SoftwareVersion: ygot.String("current"),
+				SoftwareVersion: ygot.String(softwareVersion(ctx, c, chassisName)),
 			}); err != nil {
 				return err
 			}
@@ -382,7 +570,7 @@ func NewChassisComponentsTask(cfg *configpb.Config) *reconciler.BuiltReconciler
 					OperStatus:         oc.PlatformTypes_COMPONENT_OPER_STATUS_ACTIVE,
 					RedundantRole:      redundantRole,
 					Parent:             ygot.String(chassisName),
-					SoftwareVersion:    ygot.String("current"),
+					SoftwareVersion:    ygot.String(softwareVersion(ctx, c, componentName)),
 					LastRebootTime:     ygot.Uint64(uint64(now)),
 					LastRebootReason:   oc.PlatformTypes_COMPONENT_REBOOT_REASON_UNSET,
 					SwitchoverReady:    ygot.Bool(true),
@@ -404,7 +592,7 @@ func NewChassisComponentsTask(cfg *configpb.Config) *reconciler.BuiltReconciler
 					Type:             oc.PlatformTypes_OPENCONFIG_HARDWARE_COMPONENT_LINECARD,
 					OperStatus:       oc.PlatformTypes_COMPONENT_OPER_STATUS_ACTIVE,
 					Parent:           ygot.String(chassisName),
-					SoftwareVersion:  ygot.String("current"),
+					SoftwareVersion:  ygot.String(defaultSoftwareVersion),
 					LastRebootTime:   ygot.Uint64(uint64(now)),
 					LastRebootReason: oc.PlatformTypes_COMPONENT_REBOOT_REASON_UNSET,
 				}
@@ -420,7 +608,7 @@ func NewChassisComponentsTask(cfg *configpb.Config) *reconciler.BuiltReconciler
 					Type:             oc.PlatformTypes_OPENCONFIG_HARDWARE_COMPONENT_FABRIC,
 					OperStatus:       oc.PlatformTypes_COMPONENT_OPER_STATUS_ACTIVE,
 					Parent:           ygot.String(chassisName),
-					SoftwareVersion:  ygot.String("current"),
+					SoftwareVersion:  ygot.String(defaultSoftwareVersion),
 					LastRebootTime:   ygot.Uint64(uint64(now)),
 					LastRebootReason: oc.PlatformTypes_COMPONENT_REBOOT_REASON_UNSET,
 				}
@@ -439,13 +627,52 @@ func NewChassisComponentsTask(cfg *configpb.Config) *reconciler.BuiltReconciler
 	return rec
 }
 
-// NewCurrentTimeTask initializes boot-related paths.
-func NewCurrentTimeTask() *reconciler.BuiltReconciler {
+// Clock is the device clock. It is skewed from the host clock by a fixed
+// offset, and by a drift growing with the time since the clock was created.
+type Clock struct {
+	start    time.Time
+	offset   time.Duration
+	driftPPM float64
+}
+
+// NewClock returns a device clock skewed as configured by cfg.
+func NewClock(cfg *configpb.ClockConfig) *Clock {
+	return &Clock{
+		start:    time.Now(),
+		offset:   time.Duration(cfg.GetOffsetMs()) * time.Millisecond,
+		driftPPM: cfg.GetDriftPpm(),
+	}
+}
+
+// Now returns the current time of the device clock.
+func (c *Clock) Now() time.Time {
+	now := time.Now()
+	return now.Add(c.skew(now))
+}
+
+// Offset returns the current difference between the device clock and the
+// host clock.
+func (c *Clock) Offset() time.Duration {
+	return c.skew(time.Now())
+}
+
+func (c *Clock) skew(now time.Time) time.Duration {
+	if c == nil {
+		return 0
+	}
+	drift := time.Duration(float64(now.Sub(c.start)) * c.driftPPM / 1e6)
+	return c.offset + drift
+}
+
+// NewCurrentTimeTask periodically publishes the time of the device clock.
+func NewCurrentTimeTask(clock *Clock) *reconciler.BuiltReconciler {
+	var cancel context.CancelFunc
 	rec := reconciler.NewBuilder("current time").
 		WithStart(func(ctx context.Context, c *ygnmi.Client) error { // TODO: consider WithPeriodic if this a common pattern.
+			ctx, cancel = context.WithCancel(ctx)
 			tick := time.NewTicker(time.Second)
 			periodic := func() error {
-				_, err := gnmiclient.Replace(ctx, c, ocpath.Root().System().CurrentDatetime().State(), time.Now().Format(time.RFC3339))
+				_, err := gnmiclient.Replace(ctx, c, ocpath.Root().System().CurrentDatetime().State(), clock.Now().Format(time.RFC3339))
 				return err
 			}
 
@@ -453,19 +680,124 @@ func NewCurrentTimeTask() *reconciler.BuiltReconciler {
 				return err
 			}
 			go func() {
-				for range tick.C {
-					if err := periodic(); err != nil {
-						log.Errorf("currentDateTimeTask error: %v", err)
+				defer tick.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-tick.C:
+						if err := periodic(); err != nil {
+							log.Errorf("currentDateTimeTask error: %v", err)
+							return
+						}
+					}
+				}
+			}()
+			return nil
+		}).
+		WithStop(func(context.Context) error {
+			if cancel != nil {
+				cancel()
+			}
+			return nil
+		}).Build()
+
+	return rec
+}
+
+// NewNTPTask reconciles the NTP config into state. The configured servers
+// are reported as having the host time, with the configured stratum and poll
+// interval, so their offset is the opposite of the skew of the device clock.
+func NewNTPTask(cfg *configpb.Config, clock *Clock) *reconciler.BuiltReconciler {
+	var cancel context.CancelFunc
+	rec := reconciler.NewBuilder("ntp").
+		WithStart(func(ctx context.Context, c *ygnmi.Client) error {
+			ctx, cancel = context.WithCancel(ctx)
+			var (
+				// mu serializes the updates from the config watch and the
+				// offset refreshes.
+				mu     sync.Mutex
+				config *oc.System_Ntp
+			)
+			publish := func() {
+				mu.Lock()
+				defer mu.Unlock()
+				if err := publishNTPState(ctx, c, config, cfg.GetClock(), clock); err != nil && ctx.Err() == nil {
+					log.Warningf("unable to update NTP state: %v", err)
+				}
+			}
+
+			w := ygnmi.Watch(ctx, c, ocpath.Root().System().Ntp().Config(), func(v *ygnmi.Value[*oc.System_Ntp]) error {
+				ntp, _ := v.Val()
+				mu.Lock()
+				config = ntp
+				mu.Unlock()
+				publish()
+				return ygnmi.Continue
+			})
+			go func() {
+				if _, err := w.Await(); err != nil && ctx.Err() == nil {
+					log.Errorf("NTP watch error: %v", err)
+				}
+			}()
+			// Refresh the offsets, which change as the device clock drifts.
+			go func() {
+				tick := time.NewTicker(time.Second)
+				defer tick.Stop()
+				for {
+					select {
+					case <-ctx.Done():
 						return
+					case <-tick.C:
+						publish()
 					}
 				}
 			}()
 			return nil
+		}).
+		WithStop(func(context.Context) error {
+			if cancel != nil {
+				cancel()
+			}
+			return nil
 		}).Build()
 
 	return rec
 }
 
+// publishNTPState replaces the NTP state with the state derived from the
+// NTP config, or deletes it if there is no config.
+func publishNTPState(ctx context.Context, c *ygnmi.Client, config *oc.System_Ntp, clockCfg *configpb.ClockConfig, clock *Clock) error {
+	if config == nil {
+		_, err := gnmiclient.Delete(ctx, c, ocpath.Root().System().Ntp().State())
+		return err
+	}
+	state := &oc.System_Ntp{
+		Enabled:       ygot.Bool(config.GetEnabled()),
+		EnableNtpAuth: config.EnableNtpAuth,
+	}
+	offset := -clock.Offset().Nanoseconds()
+	for addr, server := range config.Server {
+		s := state.GetOrCreateServer(addr)
+		s.Port = server.Port
+		s.Version = server.Version
+		s.AssociationType = server.AssociationType
+		s.Iburst = server.Iburst
+		s.Prefer = server.Prefer
+		s.NetworkInstance = server.NetworkInstance
+		s.SourceAddress = server.SourceAddress
+		s.KeyId = server.KeyId
+		// Servers are only polled while NTP is enabled.
+		if config.GetEnabled() {
+			s.Stratum = ygot.Uint8(uint8(clockCfg.GetNtpStratum()))
+			s.PollInterval = ygot.Uint32(clockCfg.GetNtpPollIntervalSecs())
+			s.Offset = ygot.Int64(offset)
+		}
+	}
+	_, err := gnmiclient.Replace(gnmi.AddTimestampMetadata(ctx, time.Now().UnixNano()), c, ocpath.Root().System().Ntp().State(), state)
+	return err
+}
+
 // NewSystemBaseTask handles some of the logic for the base systems feature
 // profile using ygnmi as the client.
 func NewSystemBaseTask() *reconciler.BuiltReconciler {
@@ -574,7 +906,7 @@ func NewProcessMonitoringTask(cfg *configpb.Config) *reconciler.BuiltReconciler
 // generateNewPID generates a new unique PID for restarted processes
 func generateNewPID(ctx context.Context, c *ygnmi.Client, excludePID uint32) (uint32, error) {
 	processes, err := ygnmi.GetAll(ctx, c, ocpath.Root().System().ProcessAny().State())
-	if err != nil {
+	if err != nil && !errors.Is(err, ygnmi.ErrNotPresent) {
 		return 0, fmt.Errorf("failed to get existing processes: %v", err)
 	}
 
@@ -657,7 +989,10 @@ type LinkQualificationResult struct {
 }
 
 // RunPacketLinkQualification performs a complete packet-based link qualification simulation.
-func RunPacketLinkQualification(ctx context.Context, c *ygnmi.Client, config *plqpb.QualificationConfiguration, updateCallback func(*LinkQualificationResult), cfg *configpb.Config) error {
+// If start is set, it is called when the qualification starts running to
+// create the endpoint that sends or reflects real packets, and the packet
+// statistics are the endpoint's. Otherwise, they are simulated.
+func RunPacketLinkQualification(ctx context.Context, c *ygnmi.Client, config *plqpb.QualificationConfiguration, updateCallback func(*LinkQualificationResult), cfg *configpb.Config, start func() (linkqual.Endpoint, error)) error {
 	qualID := config.GetId()
 	interfaceName := config.GetInterfaceName()
 	interfacePath := ocpath.Root().Interface(interfaceName)
@@ -692,7 +1027,7 @@ func RunPacketLinkQualification(ctx context.Context, c *ygnmi.Client, config *pl
 	}()
 
 	// Execute the qualification state machine
-	if err := executeQualificationStateMachine(ctx, c, interfaceName, result, packetConfig, timing, updateCallback, cfg); err != nil {
+	if err := executeQualificationStateMachine(ctx, c, interfaceName, result, packetConfig, timing, updateCallback, cfg, start); err != nil {
 		result.mu.Lock()
 		result.State = plqpb.QualificationState_QUALIFICATION_STATE_ERROR
 		result.EndTime = time.Now()
@@ -737,6 +1072,14 @@ func extractPacketConfiguration(config *plqpb.QualificationConfiguration, cfg *c
 		}
 	}
 
+	// A packet injector loops back the packets it sends
+	if packetInj := config.GetPacketInjector(); packetInj != nil {
+		pc.IsGenerator = true
+		if packetInj.GetPacketSize() > 0 {
+			pc.PacketSize = uint64(packetInj.GetPacketSize())
+		}
+	}
+
 	// Check for reflector configuration
 	if config.GetAsicLoopback() != nil || config.GetPmdLoopback() != nil {
 		pc.IsReflector = true
@@ -790,7 +1133,7 @@ func extractQualificationTiming(config *plqpb.QualificationConfiguration, cfg *c
 // pre-sync delay (optional), SETUP (initialize test), RUNNING (execute packet generation and measurement),
 // post-sync delay (optional), and TEARDOWN (cleanup resources).
 // It manages state transitions, context cancellation, and result updates throughout the qualification process.
-func executeQualificationStateMachine(ctx context.Context, c *ygnmi.Client, interfaceName string, result *LinkQualificationResult, packetConfig *PacketConfiguration, timing *QualificationTiming, updateCallback func(*LinkQualificationResult), cfg *configpb.Config) error {
+func executeQualificationStateMachine(ctx context.Context, c *ygnmi.Client, interfaceName string, result *LinkQualificationResult, packetConfig *PacketConfiguration, timing *QualificationTiming, updateCallback func(*LinkQualificationResult), cfg *configpb.Config, start func() (linkqual.Endpoint, error)) error {
 	// Helper function to check context and send updates
 	checkContextAndUpdate := func(state plqpb.QualificationState) error {
 		if ctx.Err() != nil {
@@ -825,7 +1168,7 @@ func executeQualificationStateMachine(ctx context.Context, c *ygnmi.Client, inte
 	if err := checkContextAndUpdate(plqpb.QualificationState_QUALIFICATION_STATE_RUNNING); err != nil {
 		return err
 	}
-	if err := executeRunningPhase(ctx, result, packetConfig, timing, updateCallback, cfg); err != nil {
+	if err := executeRunningPhase(ctx, result, packetConfig, timing, updateCallback, cfg, start); err != nil {
 		return fmt.Errorf("running phase failed: %w", err)
 	}
 
@@ -886,10 +1229,27 @@ func executeSetupPhase(ctx context.Context, c *ygnmi.Client, interfaceName strin
 	return nil
 }
 
-// executeRunningPhase handles the RUNNING state with packet simulation.
-func executeRunningPhase(ctx context.Context, result *LinkQualificationResult, packetConfig *PacketConfiguration, timing *QualificationTiming, updateCallback func(*LinkQualificationResult), cfg *configpb.Config) error {
+// executeRunningPhase handles the RUNNING state with packet simulation, or
+// with the packet endpoint created by start if it is set.
+func executeRunningPhase(ctx context.Context, result *LinkQualificationResult, packetConfig *PacketConfiguration, timing *QualificationTiming, updateCallback func(*LinkQualificationResult), cfg *configpb.Config, start func() (linkqual.Endpoint, error)) error {
 	log.Infof("Entering RUNNING phase for %v", timing.TestDuration)
 
+	var endpoint linkqual.Endpoint
+	if start != nil {
+		var err error
+		if endpoint, err = start(); err != nil {
+			return fmt.Errorf("failed to start packet endpoint: %w", err)
+		}
+		defer func() {
+			if endpoint == nil {
+				return
+			}
+			if err := endpoint.Stop(); err != nil {
+				log.Errorf("Failed to stop packet endpoint: %v", err)
+			}
+		}()
+	}
+
 	// Start packet simulation
 	startTime := time.Now()
 	sampleInterval := time.Duration(cfg.GetLinkQualification().GetMinSampleIntervalMs()) * time.Millisecond
@@ -904,15 +1264,32 @@ func executeRunningPhase(ctx context.Context, result *LinkQualificationResult, p
 		case now := <-updateTicker.C:
 			elapsed := now.Sub(startTime)
 
+			// Check if test duration completed
+			completed := now.After(testEndTime) || now.Equal(testEndTime)
+
 			// Update packet statistics based on endpoint type
-			updatePacketStatistics(result, packetConfig, elapsed, cfg)
+			if endpoint != nil {
+				if completed {
+					// Stop the endpoint so that the final statistics
+					// include the packets still in flight.
+					err := endpoint.Stop()
+					updateEndpointStatistics(result, packetConfig, endpoint)
+					endpoint = nil
+					if err != nil {
+						return fmt.Errorf("failed to stop packet endpoint: %w", err)
+					}
+				} else {
+					updateEndpointStatistics(result, packetConfig, endpoint)
+				}
+			} else {
+				updatePacketStatistics(result, packetConfig, elapsed, cfg)
+			}
 
 			if updateCallback != nil {
 				updateCallback(result)
 			}
 
-			// Check if test duration completed
-			if now.After(testEndTime) || now.Equal(testEndTime) {
+			if completed {
 				log.Infof("RUNNING phase completed after %v", elapsed)
 				return nil
 			}
@@ -973,6 +1350,26 @@ func updatePacketStatistics(result *LinkQualificationResult, packetConfig *Packe
 	result.mu.Unlock()
 }
 
+// updateEndpointStatistics copies the statistics of a packet endpoint to the
+// result. Generators count the packets that were not looped back as dropped,
+// and reflectors the packets they failed to send back.
+func updateEndpointStatistics(result *LinkQualificationResult, packetConfig *PacketConfiguration, endpoint linkqual.Endpoint) {
+	sent, received, errored := endpoint.Stats()
+
+	result.mu.Lock()
+	defer result.mu.Unlock()
+	result.PacketsSent = sent
+	result.PacketsReceived = received
+	result.PacketsError = errored
+	result.PacketsDropped = 0
+	switch {
+	case packetConfig.IsGenerator && sent > received:
+		result.PacketsDropped = sent - received
+	case packetConfig.IsReflector && received > sent:
+		result.PacketsDropped = received - sent
+	}
+}
+
 // restoreInterfaceOperStatus restores interface to original operational state.
 func restoreInterfaceOperStatus(ctx context.Context, c *ygnmi.Client, interfaceName string, originalStatus oc.E_Interface_OperStatus) error {
 	timestampedCtx := gnmi.AddTimestampMetadata(ctx, time.Now().UnixNano())
@@ -982,3 +1379,106 @@ func restoreInterfaceOperStatus(ctx context.Context, c *ygnmi.Client, interfaceN
 	log.Infof("Restored interface operational status to %v", originalStatus)
 	return nil
 }
+
+// bertSampleInterval is the interval at which simulated BERT results are
+// updated.
+const bertSampleInterval = time.Second
+
+// BERTResult represents the results of a simulated BERT on an interface.
+type BERTResult struct {
+	PeerLockEstablished bool
+	PeerLockLost        bool
+	ErrorCountPerMinute []uint32
+	TotalErrors         uint64
+}
+
+// RunBERT simulates a BERT on an interface for the given duration. The
+// interface is in the TESTING state while the BERT runs. Bit errors are
+// generated according to the error profile, and a copy of the results is
+// passed to updateCallback every second.
+func RunBERT(ctx context.Context, c *ygnmi.Client, interfaceName string, duration time.Duration, profile *configpb.BertErrorProfile, updateCallback func(*BERTResult)) error {
+	interfacePath := ocpath.Root().Interface(interfaceName)
+	originalInterface, err := ygnmi.Get(ctx, c, interfacePath.State())
+	if err != nil {
+		return fmt.Errorf("failed to get interface %s: %w", interfaceName, err)
+	}
+	timestampedCtx := gnmi.AddTimestampMetadata(ctx, time.Now().UnixNano())
+	if _, err := gnmiclient.Replace(timestampedCtx, c, interfacePath.OperStatus().State(), oc.Interface_OperStatus_TESTING); err != nil {
+		return fmt.Errorf("failed to set interface to TESTING state: %w", err)
+	}
+	defer func() {
+		if err := restoreInterfaceOperStatus(context.Background(), c, interfaceName, originalInterface.GetOperStatus()); err != nil {
+			log.Errorf("Failed to restore interface %s status: %v", interfaceName, err)
+		}
+	}()
+
+	result := &BERTResult{}
+	update := func() {
+		snapshot := *result
+		snapshot.ErrorCountPerMinute = append([]uint32(nil), result.ErrorCountPerMinute...)
+		updateCallback(&snapshot)
+	}
+	if profile.GetPeerLockFailure() {
+		log.Infof("BERT on %s failed to establish peer lock", interfaceName)
+		update()
+		return nil
+	}
+	result.PeerLockEstablished = true
+	update()
+
+	rng := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), uint64(time.Now().UnixNano()>>32))) //nolint:gosec // Using math/rand for BERT simulation
+	lockLostAfter := time.Duration(profile.GetPeerLockLostAfterSecs()) * time.Second
+	ticker := time.NewTicker(bertSampleInterval)
+	defer ticker.Stop()
+	startTime := time.Now()
+	lastSample := startTime
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			elapsed := min(now.Sub(startTime), duration)
+			if lockLostAfter > 0 && elapsed >= lockLostAfter {
+				log.Infof("BERT on %s lost peer lock after %v", interfaceName, elapsed)
+				result.PeerLockLost = true
+				update()
+				return nil
+			}
+
+			mean := profile.GetErrorsPerMinute() * now.Sub(lastSample).Minutes()
+			lastSample = now
+			n := poisson(rng, mean)
+			minute := int(elapsed / time.Minute)
+			for len(result.ErrorCountPerMinute) <= minute {
+				result.ErrorCountPerMinute = append(result.ErrorCountPerMinute, 0)
+			}
+			result.ErrorCountPerMinute[minute] += uint32(n)
+			result.TotalErrors += n
+			update()
+
+			if elapsed >= duration {
+				log.Infof("BERT on %s completed with %d errors", interfaceName, result.TotalErrors)
+				return nil
+			}
+		}
+	}
+}
+
+// poisson returns a random number of events from a Poisson distribution with
+// the given mean.
+func poisson(rng *rand.Rand, mean float64) uint64 {
+	if mean <= 0 {
+		return 0
+	}
+	if mean > 100 {
+		// Normal approximation for large means.
+		return uint64(max(math.Round(mean+rng.NormFloat64()*math.Sqrt(mean)), 0))
+	}
+	l := math.Exp(-mean)
+	var k uint64
+	for p := rng.Float64(); p > l; p *= rng.Float64() {
+		k++
+	}
+	return k
+}
@@ -644,7 +644,11 @@ func (s *Server) Capabilities(context.Context, *gpb.CapabilityRequest) (*gpb.Cap
 	return nil, status.Errorf(codes.Unimplemented, "Reference Implementation Unimplemented")
 }
 
-func (s *Server) Get(context.Context, *gpb.GetRequest) (*gpb.GetResponse, error) {
+func (s *Server) Get(ctx context.Context, _ *gpb.GetRequest) (*gpb.GetResponse, error) {
+	user, auth, err := s.readUser(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if len(s.GetResponses) == 0 {
 		return nil, status.Errorf(codes.Unimplemented, "Reference Implementation Unimplemented")
 	}
@@ -654,12 +658,75 @@ func (s *Server) Get(context.Context, *gpb.GetRequest) (*gpb.GetResponse, error)
 	case error:
 		return nil, v
 	case *gpb.GetResponse:
-		return v, nil
+		if !auth {
+			return v, nil
+		}
+		authResp := &gpb.GetResponse{Extension: v.GetExtension()}
+		for _, n := range v.GetNotification() {
+			authN, err := authorizedNotification(n, s.pathAuth, user)
+			if err != nil {
+				return nil, err
+			}
+			if authN != nil {
+				authResp.Notification = append(authResp.Notification, authN)
+			}
+		}
+		return authResp, nil
 	default:
 		return nil, status.Errorf(codes.DataLoss, "Unknown message type: %T", resp)
 	}
 }
 
+// readUser returns the user reading from the server, and whether its reads
+// must be authorized. Reads are authorized once a path authorization policy
+// is installed, except for the reconcilers which have no peer address.
+func (s *Server) readUser(ctx context.Context) (string, bool, error) {
+	p, ok := peer.FromContext(ctx)
+	if s.pathAuth == nil || !s.pathAuth.IsInitialized() || !ok || p.Addr == nil { // Addr is nil for calls from the reconcilers.
+		return "", false, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx) // Metadata exists even if not explicitly set by client.
+	// TODO: Authentication, for now just looking at the username field.
+	user := md[usernameKey]
+	if len(user) != 1 || user[0] == "" {
+		return "", false, status.Errorf(codes.Unauthenticated, "no username set in metadata %v", user)
+	}
+	return user[0], true, nil
+}
+
+// authorizedNotification returns a copy of the notification without the
+// updates and deletes the user is not allowed to read, or nil if none is left.
+// The input notification is not modified since it may be stored in the cache.
+func authorizedNotification(n *gpb.Notification, auth PathAuth, user string) (*gpb.Notification, error) {
+	authN := &gpb.Notification{
+		Prefix:    n.GetPrefix(),
+		Timestamp: n.GetTimestamp(),
+		Atomic:    n.GetAtomic(),
+	}
+	for _, del := range n.GetDelete() {
+		p, err := util.JoinPaths(n.GetPrefix(), del)
+		if err != nil {
+			return nil, err
+		}
+		if auth.CheckPermit(p, user, false) {
+			authN.Delete = append(authN.Delete, del)
+		}
+	}
+	for _, upd := range n.GetUpdate() {
+		p, err := util.JoinPaths(n.GetPrefix(), upd.GetPath())
+		if err != nil {
+			return nil, err
+		}
+		if auth.CheckPermit(p, user, false) {
+			authN.Update = append(authN.Update, upd)
+		}
+	}
+	if len(authN.Update) == 0 && len(authN.Delete) == 0 {
+		return nil, nil
+	}
+	return authN, nil
+}
+
 // PathAuth is an interface for checking authorization for gNMI paths.
 type PathAuth interface {
 	// CheckPermit returns if the user is allowed to read from or write from in the input path.
@@ -753,62 +820,28 @@ func (s *subscribeWithAuth) Send(resp *gpb.SubscribeResponse) error {
 	if resp.GetSyncResponse() {
 		return s.GNMI_SubscribeServer.Send(resp)
 	}
-	// Create a copy of the resp so that we don't modify the notification stored in the cache.
-	authResp := &gpb.SubscribeResponse{
-		Response: &gpb.SubscribeResponse_Update{
-			Update: &gpb.Notification{
-				Prefix:    resp.GetUpdate().GetPrefix(),
-				Timestamp: resp.GetUpdate().GetTimestamp(),
-				Atomic:    resp.GetUpdate().GetAtomic(),
-			},
-		},
-	}
-	respUpd := resp.Response.(*gpb.SubscribeResponse_Update).Update
-	authUpd := authResp.Response.(*gpb.SubscribeResponse_Update).Update
-
-	for _, del := range respUpd.Delete {
-		p, err := util.JoinPaths(authUpd.GetPrefix(), del)
-		if err != nil {
-			return err
-		}
-		if s.auth.CheckPermit(p, s.user, false) {
-			authUpd.Delete = append(authUpd.Delete, del)
-		}
-	}
-
-	for _, upd := range respUpd.Update {
-		p, err := util.JoinPaths(authUpd.GetPrefix(), upd.GetPath())
-		if err != nil {
-			return err
-		}
-		if s.auth.CheckPermit(p, s.user, false) {
-			authUpd.Update = append(authUpd.Update, upd)
-		}
-	}
-	if len(authUpd.Update) == 0 && len(authUpd.Delete) == 0 {
-		return nil
+	authUpd, err := authorizedNotification(resp.GetUpdate(), s.auth, s.user)
+	if err != nil || authUpd == nil {
+		return err
 	}
-
-	return s.GNMI_SubscribeServer.Send(authResp)
+	return s.GNMI_SubscribeServer.Send(&gpb.SubscribeResponse{
+		Response: &gpb.SubscribeResponse_Update{Update: authUpd},
+	})
 }
 
 // Subscribe wraps the internal subscribe with optional authorization.
 func (s *Server) Subscribe(srv gpb.GNMI_SubscribeServer) error {
-	p, ok := peer.FromContext(srv.Context())
-
-	if s.pathAuth == nil || !s.pathAuth.IsInitialized() || !ok || p.Addr == nil { // Addr is nil for calls from the reconcilers.
-		return s.Server.Subscribe(srv)
+	user, auth, err := s.readUser(srv.Context())
+	if err != nil {
+		return err
 	}
-	md, _ := metadata.FromIncomingContext(srv.Context()) // Metadata exists even if not explicitly set by client.
-	// TODO: Authentication, for now just looking at the username field.
-	user := md["username"]
-	if len(user) != 1 || user[0] == "" {
-		return status.Errorf(codes.Unauthenticated, "no username set in metadata %v", user)
+	if !auth {
+		return s.Server.Subscribe(srv)
 	}
 	sa := &subscribeWithAuth{
 		GNMI_SubscribeServer: srv,
 		auth:                 s.pathAuth,
-		user:                 user[0],
+		user:                 user,
 	}
 
 	return s.Server.Subscribe(sa)
@@ -37,6 +37,7 @@ import (
 	"google.golang.org/grpc/credentials/local"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/testing/protocmp"
 
 	"github.com/openconfig/lemming/gnmi/gnmiclient"
 	"github.com/openconfig/lemming/gnmi/oc"
@@ -1072,10 +1073,12 @@ func TestSTREAM(t *testing.T) {
 
 type testAuth struct {
 	allow bool
+	// deny is a path denied even if allow is set.
+	deny string
 }
 
-func (t testAuth) CheckPermit(*gpb.Path, string, bool) bool {
-	return t.allow
+func (t testAuth) CheckPermit(p *gpb.Path, _ string, _ bool) bool {
+	return t.allow && (t.deny == "" || mustPathToString(p) != t.deny)
 }
 
 func (t testAuth) IsInitialized() bool {
@@ -1150,3 +1153,75 @@ func TestSubscribeWithAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestGetWithAuth(t *testing.T) {
+	notif := &gpb.Notification{
+		Timestamp: 1,
+		Update: []*gpb.Update{{
+			Path: mustPath("/system/state/hostname"),
+			Val:  mustTypedValue("test"),
+		}, {
+			Path: mustPath("/system/state/domain-name"),
+			Val:  mustTypedValue("example.com"),
+		}},
+	}
+	tests := []struct {
+		desc    string
+		auth    testAuth
+		user    string
+		want    *gpb.GetResponse
+		wantErr string
+	}{{
+		desc: "allowed",
+		auth: testAuth{allow: true},
+		user: "test",
+		want: &gpb.GetResponse{Notification: []*gpb.Notification{notif}},
+	}, {
+		desc: "denied leaf pruned",
+		auth: testAuth{allow: true, deny: "/system/state/domain-name"},
+		user: "test",
+		want: &gpb.GetResponse{Notification: []*gpb.Notification{{
+			Timestamp: 1,
+			Update:    notif.Update[:1],
+		}}},
+	}, {
+		desc: "denied",
+		auth: testAuth{allow: false},
+		user: "test",
+		want: &gpb.GetResponse{},
+	}, {
+		desc:    "error no user",
+		auth:    testAuth{allow: true},
+		wantErr: "no username set in metadata",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			gnmiServer, err := newServer(context.Background(), targetName, false)
+			if err != nil {
+				t.Fatalf("cannot create server, got err: %v", err)
+			}
+			defer gnmiServer.c.Stop()
+			gnmiServer.pathAuth = tt.auth
+			gnmiServer.GetResponses = []interface{}{&gpb.GetResponse{Notification: []*gpb.Notification{notif}}}
+			addr, err := startServer(gnmiServer)
+			if err != nil {
+				t.Fatalf("cannot start server, got err: %v", err)
+			}
+			conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(local.NewCredentials()))
+			if err != nil {
+				t.Fatalf("cannot dial gNMI server, %v", err)
+			}
+			ctx := metadata.NewOutgoingContext(context.Background(), metadata.New(map[string]string{"username": tt.user}))
+			got, err := gpb.NewGNMIClient(conn).Get(ctx, &gpb.GetRequest{})
+			if d := errdiff.Check(err, tt.wantErr); d != "" {
+				t.Errorf("Get() unexpected err: %s", d)
+			}
+			if err != nil {
+				return
+			}
+			if d := cmp.Diff(tt.want, got, protocmp.Transform()); d != "" {
+				t.Errorf("Get() unexpected diff (-want +got):\n%s", d)
+			}
+		})
+	}
+}
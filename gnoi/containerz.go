@@ -0,0 +1,649 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/openconfig/lemming/gnmi/fakedevice"
+
+	czpb "github.com/openconfig/gnoi/containerz"
+)
+
+const (
+	// imageDir is the directory of the simulated file system where the
+	// deployed container images are stored.
+	imageDir = "/containerz/images"
+	// defaultImageTag is the tag of images deployed or started without one.
+	defaultImageTag = "latest"
+	// containerLogInterval is the interval between the synthetic log lines
+	// streamed for a followed running container.
+	containerLogInterval = time.Second
+)
+
+// containerz emulates the gNOI Containerz service. Deployed images are stored
+// in the simulated file system and running containers are fake processes in
+// the system process list.
+type containerz struct {
+	czpb.UnimplementedContainerzServer
+
+	c     *ygnmi.Client
+	files *file
+
+	// mu protects the containers and volumes maps
+	mu sync.Mutex
+	// Container instances by name
+	containers map[string]*container
+	// Volumes by name
+	volumes map[string]*volume
+}
+
+// container is an instance of a deployed image.
+type container struct {
+	id      string
+	name    string
+	image   string
+	tag     string
+	cmd     string
+	ports   []*czpb.StartContainerRequest_Port
+	volumes []*czpb.Volume
+	pid     uint32
+	running bool
+	started time.Time
+	// logs are the lifecycle events of the container.
+	logs []string
+	// stopped is closed when the running container stops.
+	stopped chan struct{}
+}
+
+// volume is a simulated persistent volume that can be attached to containers.
+type volume struct {
+	name    string
+	created time.Time
+	driver  czpb.Driver
+	options map[string]string
+	labels  map[string]string
+}
+
+func newContainerz(c *ygnmi.Client, files *file) *containerz {
+	return &containerz{
+		c:          c,
+		files:      files,
+		containers: make(map[string]*container),
+		volumes:    make(map[string]*volume),
+	}
+}
+
+// imagePath returns the path of the image in the simulated file system.
+func imagePath(name, tag string) string {
+	if tag == "" {
+		tag = defaultImageTag
+	}
+	return filepath.Join(imageDir, name, tag)
+}
+
+// validateImageRef checks that the image name and tag are relative paths that
+// stay inside imageDir, so clients cannot overwrite or remove other files of
+// the simulated file system.
+func validateImageRef(name, tag string) error {
+	if name == "" {
+		return status.Error(codes.InvalidArgument, "image name is required")
+	}
+	if strings.HasPrefix(name, "/") || slices.Contains(strings.Split(name, "/"), "..") {
+		return status.Errorf(codes.InvalidArgument, "invalid image name %q", name)
+	}
+	if strings.Contains(tag, "/") || tag == ".." || tag == "." {
+		return status.Errorf(codes.InvalidArgument, "invalid image tag %q", tag)
+	}
+	return nil
+}
+
+// hasImage returns whether the image was deployed.
+func (cz *containerz) hasImage(name, tag string) bool {
+	_, ok := cz.files.GetFileInfo(imagePath(name, tag))
+	return ok
+}
+
+// Deploy stores the transferred container image in the simulated file
+// system.
+func (cz *containerz) Deploy(stream czpb.Containerz_DeployServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	transfer := req.GetImageTransfer()
+	if transfer == nil {
+		return status.Error(codes.InvalidArgument, "first message must be an image transfer")
+	}
+	if err := validateImageRef(transfer.GetName(), transfer.GetTag()); err != nil {
+		return err
+	}
+	if transfer.GetRemoteDownload() != nil {
+		return status.Error(codes.Unimplemented, "remote download of images is not implemented in simulation")
+	}
+	if err := cz.files.validateFileSize(int(transfer.GetImageSize())); err != nil {
+		return err
+	}
+	tag := transfer.GetTag()
+	if tag == "" {
+		tag = defaultImageTag
+	}
+	log.Infof("Containerz Deploy: receiving image %s:%s", transfer.GetName(), tag)
+
+	if err := stream.Send(&czpb.DeployResponse{
+		Response: &czpb.DeployResponse_ImageTransferReady{
+			ImageTransferReady: &czpb.ImageTransferReady{ChunkSize: maxChunkSize},
+		},
+	}); err != nil {
+		return err
+	}
+
+	var content []byte
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return status.Error(codes.InvalidArgument, "stream closed before the end of the image transfer")
+		}
+		if err != nil {
+			return err
+		}
+		switch r := req.GetRequest().(type) {
+		case *czpb.DeployRequest_Content:
+			if len(r.Content) > maxChunkSize {
+				return status.Errorf(codes.InvalidArgument, "chunk size %d exceeds maximum %d", len(r.Content), maxChunkSize)
+			}
+			content = append(content, r.Content...)
+			if err := cz.files.validateFileSize(len(content)); err != nil {
+				return err
+			}
+			if err := stream.Send(&czpb.DeployResponse{
+				Response: &czpb.DeployResponse_ImageTransferProgress{
+					ImageTransferProgress: &czpb.ImageTransferProgress{BytesReceived: uint64(len(content))},
+				},
+			}); err != nil {
+				return err
+			}
+		case *czpb.DeployRequest_ImageTransferEnd:
+			if size := transfer.GetImageSize(); size != 0 && size != uint64(len(content)) {
+				return status.Errorf(codes.InvalidArgument, "received %d bytes of image, want %d", len(content), size)
+			}
+			if err := cz.files.write(imagePath(transfer.GetName(), tag), content, 0o644); err != nil {
+				return status.Errorf(codes.Internal, "failed to store image %s:%s: %v", transfer.GetName(), tag, err)
+			}
+			log.Infof("Containerz Deploy: stored image %s:%s (%d bytes)", transfer.GetName(), tag, len(content))
+			return stream.Send(&czpb.DeployResponse{
+				Response: &czpb.DeployResponse_ImageTransferSuccess{
+					ImageTransferSuccess: &czpb.ImageTransferSuccess{
+						Name:      transfer.GetName(),
+						Tag:       tag,
+						ImageSize: uint64(len(content)),
+					},
+				},
+			})
+		default:
+			return status.Error(codes.InvalidArgument, "unexpected message in image transfer")
+		}
+	}
+}
+
+// RemoveContainer removes the image and the containers instantiated from it.
+func (cz *containerz) RemoveContainer(ctx context.Context, req *czpb.RemoveContainerRequest) (*czpb.RemoveContainerResponse, error) {
+	if err := validateImageRef(req.GetName(), req.GetTag()); err != nil {
+		return nil, err
+	}
+	tag := req.GetTag()
+	if tag == "" {
+		tag = defaultImageTag
+	}
+
+	cz.mu.Lock()
+	defer cz.mu.Unlock()
+	if !cz.hasImage(req.GetName(), tag) {
+		return &czpb.RemoveContainerResponse{
+			Code:   czpb.RemoveContainerResponse_NOT_FOUND,
+			Detail: fmt.Sprintf("image %s:%s not found", req.GetName(), tag),
+		}, nil
+	}
+
+	var instances []*container
+	for _, ctr := range cz.containers {
+		if ctr.image != req.GetName() || ctr.tag != tag {
+			continue
+		}
+		if ctr.running && !req.GetForce() {
+			return &czpb.RemoveContainerResponse{
+				Code:   czpb.RemoveContainerResponse_RUNNING,
+				Detail: fmt.Sprintf("container %s is running image %s:%s", ctr.name, req.GetName(), tag),
+			}, nil
+		}
+		instances = append(instances, ctr)
+	}
+	for _, ctr := range instances {
+		if ctr.running {
+			if err := cz.stop(ctx, ctr, true); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to stop container %s: %v", ctr.name, err)
+			}
+		}
+		delete(cz.containers, ctr.name)
+	}
+	if _, err := cz.files.remove(imagePath(req.GetName(), tag)); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to remove image %s:%s: %v", req.GetName(), tag, err)
+	}
+	log.Infof("Containerz RemoveContainer: removed image %s:%s and %d containers", req.GetName(), tag, len(instances))
+
+	return &czpb.RemoveContainerResponse{Code: czpb.RemoveContainerResponse_SUCCESS}, nil
+}
+
+// ListContainer streams the containers matching the request, ordered by name.
+func (cz *containerz) ListContainer(req *czpb.ListContainerRequest, stream czpb.Containerz_ListContainerServer) error {
+	filters := map[string][]string{}
+	for _, f := range req.GetFilter() {
+		switch f.GetKey() {
+		case "name", "image":
+			filters[f.GetKey()] = append(filters[f.GetKey()], f.GetValue()...)
+		default:
+			return status.Errorf(codes.InvalidArgument, "unsupported filter key %q", f.GetKey())
+		}
+	}
+	matches := func(key, value string) bool {
+		values, ok := filters[key]
+		return !ok || slices.Contains(values, value)
+	}
+
+	cz.mu.Lock()
+	var resps []*czpb.ListContainerResponse
+	for _, ctr := range cz.containers {
+		if (!ctr.running && !req.GetAll()) || !matches("name", ctr.name) || !matches("image", ctr.image) {
+			continue
+		}
+		st := czpb.ListContainerResponse_STOPPED
+		if ctr.running {
+			st = czpb.ListContainerResponse_RUNNING
+		}
+		resps = append(resps, &czpb.ListContainerResponse{
+			Id:        ctr.id,
+			Name:      ctr.name,
+			ImageName: ctr.image,
+			Status:    st,
+		})
+	}
+	cz.mu.Unlock()
+
+	sort.Slice(resps, func(i, j int) bool { return resps[i].GetName() < resps[j].GetName() })
+	if limit := int(req.GetLimit()); limit > 0 && len(resps) > limit {
+		resps = resps[:limit]
+	}
+	for _, resp := range resps {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartContainer starts an instance of a deployed image as a new process.
+func (cz *containerz) StartContainer(ctx context.Context, req *czpb.StartContainerRequest) (*czpb.StartContainerResponse, error) {
+	if err := validateImageRef(req.GetImageName(), req.GetTag()); err != nil {
+		return nil, err
+	}
+	tag := req.GetTag()
+	if tag == "" {
+		tag = defaultImageTag
+	}
+	startError := func(code czpb.StartError_Code, format string, args ...any) *czpb.StartContainerResponse {
+		return &czpb.StartContainerResponse{
+			Response: &czpb.StartContainerResponse_StartError{
+				StartError: &czpb.StartError{ErrorCode: code, Details: fmt.Sprintf(format, args...)},
+			},
+		}
+	}
+
+	cz.mu.Lock()
+	defer cz.mu.Unlock()
+	if !cz.hasImage(req.GetImageName(), tag) {
+		return startError(czpb.StartError_NOT_FOUND, "image %s:%s not found", req.GetImageName(), tag), nil
+	}
+
+	name := req.GetInstanceName()
+	if name == "" {
+		base := filepath.Base(req.GetImageName())
+		for i := 1; ; i++ {
+			name = fmt.Sprintf("%s-%d", base, i)
+			if _, ok := cz.containers[name]; !ok {
+				break
+			}
+		}
+	}
+	if _, ok := cz.containers[name]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "container %s already exists", name)
+	}
+
+	for _, port := range req.GetPorts() {
+		for _, ctr := range cz.containers {
+			if ctr.running && ctr.usesPort(externalPort(port)) {
+				return startError(czpb.StartError_PORT_USED, "port %d is used by container %s", externalPort(port), ctr.name), nil
+			}
+		}
+	}
+	for _, v := range req.GetVolumes() {
+		if _, ok := cz.volumes[v.GetName()]; !ok {
+			return startError(czpb.StartError_NOT_FOUND, "volume %s not found", v.GetName()), nil
+		}
+	}
+
+	ctr := &container{
+		id:      fmt.Sprintf("%012x", rand.Uint64()&0xffffffffffff),
+		name:    name,
+		image:   req.GetImageName(),
+		tag:     tag,
+		cmd:     req.GetCmd(),
+		ports:   req.GetPorts(),
+		volumes: req.GetVolumes(),
+	}
+	if err := cz.start(ctx, ctr); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to start container %s: %v", name, err)
+	}
+	cz.containers[name] = ctr
+	log.Infof("Containerz StartContainer: started container %s from image %s:%s", name, ctr.image, tag)
+
+	return &czpb.StartContainerResponse{
+		Response: &czpb.StartContainerResponse_StartOk{
+			StartOk: &czpb.StartOK{InstanceName: name},
+		},
+	}, nil
+}
+
+// StopContainer stops a running container, and optionally restarts it.
+func (cz *containerz) StopContainer(ctx context.Context, req *czpb.StopContainerRequest) (*czpb.StopContainerResponse, error) {
+	cz.mu.Lock()
+	defer cz.mu.Unlock()
+	ctr, ok := cz.containers[req.GetInstanceName()]
+	if !ok {
+		return &czpb.StopContainerResponse{
+			Code:    czpb.StopContainerResponse_NOT_FOUND,
+			Details: fmt.Sprintf("container %s not found", req.GetInstanceName()),
+		}, nil
+	}
+	if !ctr.running {
+		return nil, status.Errorf(codes.FailedPrecondition, "container %s is not running", ctr.name)
+	}
+
+	if err := cz.stop(ctx, ctr, req.GetForce()); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to stop container %s: %v", ctr.name, err)
+	}
+	if req.GetRestart() {
+		if err := cz.start(ctx, ctr); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to restart container %s: %v", ctr.name, err)
+		}
+	}
+	log.Infof("Containerz StopContainer: stopped container %s (restart: %v)", ctr.name, req.GetRestart())
+
+	return &czpb.StopContainerResponse{Code: czpb.StopContainerResponse_SUCCESS}, nil
+}
+
+// Log streams the lifecycle events of the container followed, if requested
+// and the container is running, by synthetic log lines until it stops.
+func (cz *containerz) Log(req *czpb.LogRequest, stream czpb.Containerz_LogServer) error {
+	cz.mu.Lock()
+	ctr, ok := cz.containers[req.GetInstanceName()]
+	if !ok {
+		cz.mu.Unlock()
+		return status.Errorf(codes.NotFound, "container %s not found", req.GetInstanceName())
+	}
+	lines := slices.Clone(ctr.logs)
+	running, stopped := ctr.running, ctr.stopped
+	cz.mu.Unlock()
+
+	send := func(lines []string) error {
+		for _, line := range lines {
+			if err := stream.Send(&czpb.LogResponse{Msg: line}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := send(lines); err != nil {
+		return err
+	}
+	if !req.GetFollow() || !running {
+		return nil
+	}
+
+	ticker := time.NewTicker(containerLogInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-stopped:
+			cz.mu.Lock()
+			rest := slices.Clone(ctr.logs[len(lines):])
+			cz.mu.Unlock()
+			return send(rest)
+		case now := <-ticker.C:
+			if err := send([]string{logLine(now, "%s: running for %v", ctr.name, now.Sub(ctr.started).Round(time.Second))}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// CreateVolume creates a simulated volume.
+func (cz *containerz) CreateVolume(ctx context.Context, req *czpb.CreateVolumeRequest) (*czpb.CreateVolumeResponse, error) {
+	cz.mu.Lock()
+	defer cz.mu.Unlock()
+
+	name := req.GetName()
+	if name == "" {
+		for {
+			name = fmt.Sprintf("%016x", rand.Uint64())
+			if _, ok := cz.volumes[name]; !ok {
+				break
+			}
+		}
+	}
+	if _, ok := cz.volumes[name]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "volume %s already exists", name)
+	}
+
+	driver := req.GetDriver()
+	if driver == czpb.Driver_DS_UNSPECIFIED {
+		driver = czpb.Driver_DS_LOCAL
+	}
+	options := map[string]string{}
+	if opts := req.GetLocalMountOptions(); opts != nil {
+		options["type"] = "none"
+		if len(opts.GetOptions()) > 0 {
+			options["options"] = strings.Join(opts.GetOptions(), ",")
+		}
+		if opts.GetMountpoint() != "" {
+			options["mountpoint"] = opts.GetMountpoint()
+		}
+	}
+	cz.volumes[name] = &volume{
+		name:    name,
+		created: time.Now(),
+		driver:  driver,
+		options: options,
+		labels:  req.GetLabels(),
+	}
+	log.Infof("Containerz CreateVolume: created volume %s", name)
+
+	return &czpb.CreateVolumeResponse{Name: name}, nil
+}
+
+// RemoveVolume removes a volume, unless it is used by a running container and
+// the removal is not forced.
+func (cz *containerz) RemoveVolume(ctx context.Context, req *czpb.RemoveVolumeRequest) (*czpb.RemoveVolumeResponse, error) {
+	cz.mu.Lock()
+	defer cz.mu.Unlock()
+	if _, ok := cz.volumes[req.GetName()]; !ok {
+		return &czpb.RemoveVolumeResponse{}, nil
+	}
+	if !req.GetForce() {
+		for _, ctr := range cz.containers {
+			if ctr.running && ctr.usesVolume(req.GetName()) {
+				return nil, status.Errorf(codes.FailedPrecondition, "volume %s is used by container %s", req.GetName(), ctr.name)
+			}
+		}
+	}
+	delete(cz.volumes, req.GetName())
+	log.Infof("Containerz RemoveVolume: removed volume %s", req.GetName())
+
+	return &czpb.RemoveVolumeResponse{}, nil
+}
+
+// ListVolume streams the volumes matching the request, ordered by name.
+func (cz *containerz) ListVolume(req *czpb.ListVolumeRequest, stream czpb.Containerz_ListVolumeServer) error {
+	var names, labels []string
+	for _, f := range req.GetFilter() {
+		switch f.GetKey() {
+		case "name":
+			names = append(names, f.GetValue()...)
+		case "label":
+			labels = append(labels, f.GetValue()...)
+		default:
+			return status.Errorf(codes.InvalidArgument, "unsupported filter key %q", f.GetKey())
+		}
+	}
+
+	cz.mu.Lock()
+	var resps []*czpb.ListVolumeResponse
+	for _, v := range cz.volumes {
+		if (len(names) > 0 && !slices.Contains(names, v.name)) || !v.hasLabels(labels) {
+			continue
+		}
+		resps = append(resps, &czpb.ListVolumeResponse{
+			Name:    v.name,
+			Created: timestamppb.New(v.created),
+			Driver:  strings.ToLower(strings.TrimPrefix(v.driver.String(), "DS_")),
+			Options: v.options,
+			Labels:  v.labels,
+		})
+	}
+	cz.mu.Unlock()
+
+	sort.Slice(resps, func(i, j int) bool { return resps[i].GetName() < resps[j].GetName() })
+	for _, resp := range resps {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start runs the container as a new process. cz.mu must be held.
+func (cz *containerz) start(ctx context.Context, ctr *container) error {
+	pid, err := fakedevice.StartContainerProcess(ctx, cz.c, ctr.name)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	ctr.pid = pid
+	ctr.running = true
+	ctr.started = now
+	ctr.stopped = make(chan struct{})
+	ctr.logs = append(ctr.logs, logLine(now, "Starting container %s from image %s:%s", ctr.name, ctr.image, ctr.tag))
+	if ctr.cmd != "" {
+		ctr.logs = append(ctr.logs, logLine(now, "Running command %q", ctr.cmd))
+	}
+	for _, port := range ctr.ports {
+		ctr.logs = append(ctr.logs, logLine(now, "Exposing port %d on port %d", port.GetInternal(), externalPort(port)))
+	}
+	for _, v := range ctr.volumes {
+		ctr.logs = append(ctr.logs, logLine(now, "Mounted volume %s at %s (read-only: %v)", v.GetName(), v.GetMountPoint(), v.GetReadOnly()))
+	}
+	ctr.logs = append(ctr.logs, logLine(now, "Container %s started with PID %d", ctr.name, pid))
+	return nil
+}
+
+// stop terminates the process of the running container. cz.mu must be held.
+func (cz *containerz) stop(ctx context.Context, ctr *container, force bool) error {
+	if err := fakedevice.StopContainerProcess(ctx, cz.c, ctr.pid); err != nil {
+		return err
+	}
+	now := time.Now()
+	if force {
+		ctr.logs = append(ctr.logs, logLine(now, "Container %s killed", ctr.name))
+	} else {
+		ctr.logs = append(ctr.logs, logLine(now, "Container %s stopped", ctr.name))
+	}
+	ctr.running = false
+	close(ctr.stopped)
+	return nil
+}
+
+// usesPort returns whether the container exposes the external port.
+func (ctr *container) usesPort(port uint32) bool {
+	for _, p := range ctr.ports {
+		if externalPort(p) == port {
+			return true
+		}
+	}
+	return false
+}
+
+// usesVolume returns whether the volume is attached to the container.
+func (ctr *container) usesVolume(name string) bool {
+	for _, v := range ctr.volumes {
+		if v.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLabels returns whether the volume has all the labels, given as key or
+// key=value.
+func (v *volume) hasLabels(labels []string) bool {
+	for _, label := range labels {
+		key, value, hasValue := strings.Cut(label, "=")
+		got, ok := v.labels[key]
+		if !ok || (hasValue && got != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// externalPort returns the port exposed outside the container, which
+// defaults to the internal one.
+func externalPort(port *czpb.StartContainerRequest_Port) uint32 {
+	if port.GetExternal() != 0 {
+		return port.GetExternal()
+	}
+	return port.GetInternal()
+}
+
+// logLine formats a container log line.
+func logLine(t time.Time, format string, args ...any) string {
+	return t.Format(time.RFC3339Nano) + " " + fmt.Sprintf(format, args...)
+}
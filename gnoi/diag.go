@@ -0,0 +1,395 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/lemming/gnmi/fakedevice"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+	configpb "github.com/openconfig/lemming/proto/config"
+
+	diagpb "github.com/openconfig/gnoi/diag"
+	pb "github.com/openconfig/gnoi/types"
+)
+
+type diag struct {
+	diagpb.UnimplementedDiagServer
+
+	c      *ygnmi.Client
+	config *configpb.Config
+
+	// Protect the BERT state tracking
+	mu sync.Mutex
+	// Last BERT run on each port
+	// interface -> state
+	ports map[string]*bertState
+}
+
+// bertState represents the state of a BERT on a single port.
+type bertState struct {
+	opID          string
+	intf          *pb.Path
+	poly          diagpb.PrbsPolynomial
+	startTime     time.Time
+	lastResultGet time.Time
+
+	// Control for stopping the BERT
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// Protect the results updated by the simulation
+	mu      sync.Mutex
+	running bool
+	failed  bool
+	result  *fakedevice.BERTResult
+}
+
+func newDiag(c *ygnmi.Client, config *configpb.Config) *diag {
+	return &diag{
+		c:      c,
+		config: config,
+		ports:  make(map[string]*bertState),
+	}
+}
+
+// StartBERT starts BERT on the requested ports. Either BERT is started on
+// all the valid ports, or, if any of them is already in BERT, on none.
+func (d *diag) StartBERT(ctx context.Context, req *diagpb.StartBERTRequest) (*diagpb.StartBERTResponse, error) {
+	log.Infof("Received StartBERT request: %v", req)
+
+	opID := req.GetBertOperationId()
+	if opID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "bert operation id is required")
+	}
+	if len(req.GetPerPortRequests()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "no ports specified")
+	}
+
+	// Read the interfaces before taking the lock, so that a slow gNMI
+	// server does not block the other Diag RPCs.
+	intfs := make(map[string]*oc.Interface)
+	for _, port := range req.GetPerPortRequests() {
+		name, ok := bertInterfaceName(port.GetInterface())
+		if !ok {
+			continue
+		}
+		if _, ok := intfs[name]; ok {
+			continue
+		}
+		getCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		intf, err := ygnmi.Get(getCtx, d.c, ocpath.Root().Interface(name).State())
+		cancel()
+		if err != nil {
+			intf = nil
+		}
+		intfs[name] = intf
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	inUse := false
+	for _, state := range d.ports {
+		if state.opID == opID {
+			inUse = true
+			break
+		}
+	}
+	resp := &diagpb.StartBERTResponse{BertOperationId: opID}
+	requested := make(map[string]bool)
+	var toStart []*diagpb.StartBERTRequest_PerPortRequest
+	busy := false
+	for _, port := range req.GetPerPortRequests() {
+		st := d.validateStart(port, intfs, inUse, requested)
+		resp.PerPortResponses = append(resp.PerPortResponses, &diagpb.StartBERTResponse_PerPortResponse{
+			Interface: port.GetInterface(),
+			Status:    st,
+		})
+		switch st {
+		case diagpb.BertStatus_BERT_STATUS_OK:
+			toStart = append(toStart, port)
+		case diagpb.BertStatus_BERT_STATUS_PORT_ALREADY_IN_BERT:
+			busy = true
+		}
+	}
+	if busy {
+		return nil, status.Errorf(codes.FailedPrecondition, "BERT is already in progress on one or more of the requested ports: %v", resp.GetPerPortResponses())
+	}
+	if len(toStart) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "BERT is not supported on any of the requested ports: %v", resp.GetPerPortResponses())
+	}
+
+	for _, port := range toStart {
+		name, _ := bertInterfaceName(port.GetInterface())
+		duration := time.Duration(port.GetTestDurationInSecs()) * time.Second
+		bertCtx, cancel := context.WithCancel(context.Background())
+		state := &bertState{
+			opID:      opID,
+			intf:      port.GetInterface(),
+			poly:      port.GetPrbsPolynomial(),
+			startTime: time.Now(),
+			cancel:    cancel,
+			done:      make(chan struct{}),
+			running:   true,
+		}
+		d.ports[name] = state
+		go d.runBERT(bertCtx, name, duration, state)
+	}
+
+	log.Infof("Started BERT %s on %d ports", opID, len(toStart))
+	return resp, nil
+}
+
+// validateStart returns the status of starting BERT on the port. intfs holds
+// the state of the requested interfaces, nil for the ones that do not exist,
+// and inUse whether the operation ID is used by a BERT on any port.
+func (d *diag) validateStart(port *diagpb.StartBERTRequest_PerPortRequest, intfs map[string]*oc.Interface, inUse bool, requested map[string]bool) diagpb.BertStatus {
+	name, ok := bertInterfaceName(port.GetInterface())
+	if !ok {
+		return diagpb.BertStatus_BERT_STATUS_NON_EXISTENT_PORT
+	}
+	intf := intfs[name]
+	if intf == nil {
+		return diagpb.BertStatus_BERT_STATUS_NON_EXISTENT_PORT
+	}
+
+	// A port being tested by another operation (e.g. link qualification)
+	// is also busy.
+	if requested[name] || intf.GetOperStatus() == oc.Interface_OperStatus_TESTING {
+		return diagpb.BertStatus_BERT_STATUS_PORT_ALREADY_IN_BERT
+	}
+	requested[name] = true
+	if state, ok := d.ports[name]; ok {
+		state.mu.Lock()
+		running := state.running
+		state.mu.Unlock()
+		if running {
+			return diagpb.BertStatus_BERT_STATUS_PORT_ALREADY_IN_BERT
+		}
+	}
+	if inUse {
+		return diagpb.BertStatus_BERT_STATUS_OPERATION_ID_IN_USE
+	}
+
+	if _, ok := diagpb.PrbsPolynomial_name[int32(port.GetPrbsPolynomial())]; !ok || port.GetPrbsPolynomial() == diagpb.PrbsPolynomial_PRBS_POLYNOMIAL_UNKNOWN {
+		return diagpb.BertStatus_BERT_STATUS_UNSUPPORTED_PRBS_POLYNOMIAL
+	}
+	if port.GetTestDurationInSecs() < d.config.GetDiag().GetMinBertDurationSecs() || port.GetTestDurationInSecs() == 0 {
+		return diagpb.BertStatus_BERT_STATUS_TEST_DURATION_TOO_SHORT
+	}
+	if maxSecs := d.config.GetDiag().GetMaxBertDurationSecs(); maxSecs > 0 && port.GetTestDurationInSecs() > maxSecs {
+		return diagpb.BertStatus_BERT_STATUS_TEST_DURATION_TOO_LONG
+	}
+	return diagpb.BertStatus_BERT_STATUS_OK
+}
+
+// runBERT runs the BERT simulation on the port until it completes or is
+// stopped.
+func (d *diag) runBERT(ctx context.Context, name string, duration time.Duration, state *bertState) {
+	defer close(state.done)
+
+	updateCallback := func(result *fakedevice.BERTResult) {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		state.result = result
+	}
+	err := fakedevice.RunBERT(ctx, d.c, name, duration, d.errorProfile(name), updateCallback)
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.running = false
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Errorf("BERT %s on %s failed: %v", state.opID, name, err)
+		state.failed = true
+		return
+	}
+	log.Infof("BERT %s on %s finished", state.opID, name)
+}
+
+// errorProfile returns the simulated error profile of the interface.
+func (d *diag) errorProfile(name string) *configpb.BertErrorProfile {
+	for _, profile := range d.config.GetDiag().GetErrorProfile() {
+		if profile.GetInterface() == name {
+			return profile
+		}
+	}
+	return d.config.GetDiag().GetDefaultErrorProfile()
+}
+
+// StopBERT stops BERT on the requested ports. Stopping a port on which the
+// BERT operation has already completed is not an error.
+func (d *diag) StopBERT(ctx context.Context, req *diagpb.StopBERTRequest) (*diagpb.StopBERTResponse, error) {
+	log.Infof("Received StopBERT request: %v", req)
+
+	opID := req.GetBertOperationId()
+	if opID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "bert operation id is required")
+	}
+	if len(req.GetPerPortRequests()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "no ports specified")
+	}
+
+	d.mu.Lock()
+	resp := &diagpb.StopBERTResponse{BertOperationId: opID}
+	var stopped []*bertState
+	for _, port := range req.GetPerPortRequests() {
+		state, st := d.lookup(opID, port.GetInterface())
+		if st == diagpb.BertStatus_BERT_STATUS_OK {
+			state.cancel()
+			stopped = append(stopped, state)
+		}
+		resp.PerPortResponses = append(resp.PerPortResponses, &diagpb.StopBERTResponse_PerPortResponse{
+			Interface: port.GetInterface(),
+			Status:    st,
+		})
+	}
+	d.mu.Unlock()
+
+	// Wait for the simulations to exit so that the ports are no longer in
+	// the TESTING state when the RPC returns. The lock is not held, as
+	// restoring the oper status goes through gNMI.
+	for _, state := range stopped {
+		select {
+		case <-state.done:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	if len(stopped) == 0 {
+		return nil, status.Errorf(codes.NotFound, "BERT operation %s not found on any of the requested ports: %v", opID, resp.GetPerPortResponses())
+	}
+	return resp, nil
+}
+
+// GetBERTResult returns the results of BERT on the requested ports.
+func (d *diag) GetBERTResult(ctx context.Context, req *diagpb.GetBERTResultRequest) (*diagpb.GetBERTResultResponse, error) {
+	log.Infof("Received GetBERTResult request: %v", req)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	resp := &diagpb.GetBERTResultResponse{}
+	now := time.Now()
+
+	if req.GetResultFromAllPorts() {
+		var names []string
+		for name := range d.ports {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			resp.PerPortResponses = append(resp.PerPortResponses, d.ports[name].perPortResult(d.ports[name].intf, now))
+		}
+		return resp, nil
+	}
+
+	opID := req.GetBertOperationId()
+	if opID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "bert operation id is required")
+	}
+	if len(req.GetPerPortRequests()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "no ports specified")
+	}
+	found := false
+	for _, port := range req.GetPerPortRequests() {
+		state, st := d.lookup(opID, port.GetInterface())
+		if st != diagpb.BertStatus_BERT_STATUS_OK {
+			resp.PerPortResponses = append(resp.PerPortResponses, &diagpb.GetBERTResultResponse_PerPortResponse{
+				Interface: port.GetInterface(),
+				Status:    st,
+			})
+			continue
+		}
+		found = true
+		resp.PerPortResponses = append(resp.PerPortResponses, state.perPortResult(port.GetInterface(), now))
+	}
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "BERT operation %s not found on any of the requested ports: %v", opID, resp.GetPerPortResponses())
+	}
+	return resp, nil
+}
+
+// lookup returns the BERT state of the port if it belongs to the operation.
+func (d *diag) lookup(opID string, intf *pb.Path) (*bertState, diagpb.BertStatus) {
+	name, ok := bertInterfaceName(intf)
+	if !ok {
+		return nil, diagpb.BertStatus_BERT_STATUS_NON_EXISTENT_PORT
+	}
+	state, ok := d.ports[name]
+	if !ok {
+		return nil, diagpb.BertStatus_BERT_STATUS_PORT_NOT_RUNNING_BERT
+	}
+	if state.opID != opID {
+		return nil, diagpb.BertStatus_BERT_STATUS_OPERATION_ID_NOT_FOUND
+	}
+	return state, diagpb.BertStatus_BERT_STATUS_OK
+}
+
+// perPortResult returns the result of the BERT and records the time at which
+// it was read.
+func (s *bertState) perPortResult(intf *pb.Path, now time.Time) *diagpb.GetBERTResultResponse_PerPortResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastResultGet = now
+	result := &diagpb.GetBERTResultResponse_PerPortResponse{
+		Interface:                  intf,
+		Status:                     diagpb.BertStatus_BERT_STATUS_OK,
+		BertOperationId:            s.opID,
+		PrbsPolynomial:             s.poly,
+		LastBertStartTimestamp:     uint64(s.startTime.UnixNano()),
+		LastBertGetResultTimestamp: uint64(now.UnixNano()),
+	}
+	if s.result != nil {
+		result.PeerLockEstablished = s.result.PeerLockEstablished
+		result.PeerLockLost = s.result.PeerLockLost
+		result.ErrorCountPerMinute = s.result.ErrorCountPerMinute
+		result.TotalErrors = s.result.TotalErrors
+	}
+	switch {
+	case s.failed:
+		result.Status = diagpb.BertStatus_BERT_STATUS_INTERNAL_ERROR
+	case s.result != nil && !s.result.PeerLockEstablished:
+		result.Status = diagpb.BertStatus_BERT_STATUS_PEER_LOCK_FAILURE
+	case s.result != nil && s.result.PeerLockLost:
+		result.Status = diagpb.BertStatus_BERT_STATUS_PEER_LOCK_LOST
+	}
+	return result
+}
+
+// bertInterfaceName returns the name of the interface from a path of the
+// form /interfaces/interface[name=<name>].
+func bertInterfaceName(p *pb.Path) (string, bool) {
+	elems := p.GetElem()
+	if len(elems) == 0 {
+		return "", false
+	}
+	last := elems[len(elems)-1]
+	if last.GetName() != "interface" || last.GetKey()["name"] == "" {
+		return "", false
+	}
+	return last.GetKey()["name"], true
+}
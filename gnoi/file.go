@@ -19,9 +19,13 @@ import (
 	"crypto/md5" //nolint:gosec // MD5 required
 	"crypto/sha256"
 	"crypto/sha512"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	goos "os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +35,9 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	configpb "github.com/openconfig/lemming/proto/config"
+
+	cpb "github.com/openconfig/gnoi/common"
 	fpb "github.com/openconfig/gnoi/file"
 )
 
@@ -40,6 +47,9 @@ const (
 	// 64KB chunks
 	maxChunkSize = 64 * 1024
 	defaultUmask = 022
+	// dirPermissions are the permissions of the directories of the
+	// simulated file system.
+	dirPermissions = 0o755
 )
 
 // file implements the gNOI file service.
@@ -47,6 +57,14 @@ type file struct {
 	fpb.UnimplementedFileServer
 	mu    sync.RWMutex
 	files map[string]*fileInfo
+
+	// root is the directory backing the file system, empty if the files
+	// are only kept in memory.
+	root string
+	// remoteTransfer is set if TransferToRemote uploads the files, instead
+	// of simulating the transfer.
+	remoteTransfer  bool
+	transferTimeout time.Duration
 }
 
 // fileInfo represents a file in the simulated file system.
@@ -65,6 +83,52 @@ func newFile() *file {
 	}
 }
 
+// newFileFromConfig creates a file service configured by cfg. If cfg has a
+// root directory, the files already stored in it are loaded.
+func newFileFromConfig(cfg *configpb.FileConfig) (*file, error) {
+	f := newFile()
+	f.remoteTransfer = cfg.GetRemoteTransfer()
+	f.transferTimeout = time.Duration(cfg.GetTransferTimeoutMs()) * time.Millisecond
+	if cfg.GetRootDir() == "" {
+		return f, nil
+	}
+	f.root = filepath.Clean(cfg.GetRootDir())
+	if err := goos.MkdirAll(f.root, dirPermissions); err != nil {
+		return nil, fmt.Errorf("cannot create file root directory: %v", err)
+	}
+	err := filepath.WalkDir(f.root, func(diskPath string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := goos.ReadFile(diskPath)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(f.root, diskPath)
+		if err != nil {
+			return err
+		}
+		filePath := "/" + filepath.ToSlash(rel)
+		f.files[filePath] = &fileInfo{
+			path:        filePath,
+			content:     content,
+			permissions: uint32(info.Mode().Perm()),
+			created:     info.ModTime(),
+			modified:    info.ModTime(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot load files from %s: %v", f.root, err)
+	}
+	log.Infof("File: loaded %d files from %s", len(f.files), f.root)
+	return f, nil
+}
+
 // Put implements the gNOI file service Put RPC.
 func (f *file) Put(stream fpb.File_PutServer) error {
 	var (
@@ -142,17 +206,9 @@ func (f *file) Put(stream fpb.File_PutServer) error {
 		return status.Error(codes.InvalidArgument, "hash verification failed")
 	}
 
-	// Store the file
-	now := time.Now()
-	f.mu.Lock()
-	f.files[filePath] = &fileInfo{
-		path:        filePath,
-		content:     content,
-		permissions: permissions,
-		created:     now,
-		modified:    now,
+	if err := f.write(filePath, content, permissions); err != nil {
+		return status.Errorf(codes.Internal, "failed to store file: %v", err)
 	}
-	f.mu.Unlock()
 
 	log.Infof("File Put: successfully stored file %s (%d bytes)", filePath, len(content))
 	return stream.SendAndClose(&fpb.PutResponse{})
@@ -220,22 +276,49 @@ func (f *file) Stat(ctx context.Context, req *fpb.StatRequest) (*fpb.StatRespons
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
+	fileStat := func(file *fileInfo) *fpb.StatInfo {
+		return &fpb.StatInfo{
+			Path:         file.path,
+			LastModified: uint64(file.modified.UnixNano()),
+			Permissions:  file.permissions,
+			Size:         uint64(len(file.content)),
+			Umask:        defaultUmask,
+		}
+	}
+	if file, ok := f.files[path]; ok {
+		return &fpb.StatResponse{Stats: []*fpb.StatInfo{fileStat(file)}}, nil
+	}
+
+	// Otherwise, path is a directory: list its files, and its
+	// subdirectories, which were last modified with their latest file.
+	prefix := strings.TrimSuffix(path, "/") + "/"
 	var stats []*fpb.StatInfo
+	subdirs := make(map[string]time.Time)
 	for filePath, file := range f.files {
-		// Support both exact directory match and recursive listing
-		fileDir := filepath.Dir(filePath)
-		if fileDir == path || (strings.HasPrefix(filePath, path+"/") && path != "/") || (path == "/" && strings.HasPrefix(filePath, "/")) {
-			stats = append(stats, &fpb.StatInfo{
-				Path:         filePath,
-				LastModified: uint64(file.modified.UnixNano()),
-				Permissions:  file.permissions,
-				Size:         uint64(len(file.content)),
-				Umask:        defaultUmask,
-			})
+		rest, ok := strings.CutPrefix(filePath, prefix)
+		if !ok {
+			continue
 		}
+		if dir, _, ok := strings.Cut(rest, "/"); ok {
+			dirPath := prefix + dir
+			if file.modified.After(subdirs[dirPath]) {
+				subdirs[dirPath] = file.modified
+			}
+			continue
+		}
+		stats = append(stats, fileStat(file))
+	}
+	for dirPath, modified := range subdirs {
+		stats = append(stats, &fpb.StatInfo{
+			Path:         dirPath,
+			LastModified: uint64(modified.UnixNano()),
+			Permissions:  dirPermissions,
+			Umask:        defaultUmask,
+		})
 	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].GetPath() < stats[j].GetPath() })
 
-	log.Infof("File Stat: found %d files in %s", len(stats), path)
+	log.Infof("File Stat: found %d files and directories in %s", len(stats), path)
 	return &fpb.StatResponse{Stats: stats}, nil
 }
 
@@ -246,14 +329,13 @@ func (f *file) Remove(ctx context.Context, req *fpb.RemoveRequest) (*fpb.RemoveR
 		return nil, err
 	}
 
-	f.mu.Lock()
-	defer f.mu.Unlock()
-
-	if _, exists := f.files[filePath]; !exists {
+	exists, err := f.remove(filePath)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to remove file %s: %v", filePath, err)
+	}
+	if !exists {
 		return nil, status.Errorf(codes.NotFound, "file %s not found", filePath)
 	}
-
-	delete(f.files, filePath)
 	log.Infof("File Remove: deleted file %s", filePath)
 
 	return &fpb.RemoveResponse{}, nil
@@ -301,7 +383,20 @@ func (f *file) TransferToRemote(ctx context.Context, req *fpb.TransferToRemoteRe
 	log.Infof("File TransferToRemote: transferring %s to %s via protocol %v",
 		localPath, remoteDownload.GetPath(), remoteDownload.GetProtocol())
 
-	// Simulate the transfer by computing hash of the file content
+	if f.remoteTransfer {
+		target, err := parseRemoteDownload(remoteDownload)
+		if err != nil {
+			return nil, err
+		}
+		transferCtx, cancel := context.WithTimeout(ctx, f.transferTimeout)
+		defer cancel()
+		if err := target.upload(transferCtx, file.content, file.permissions); err != nil {
+			log.Errorf("File TransferToRemote: failed to transfer %s: %v", localPath, err)
+			return nil, status.Errorf(codes.Unavailable, "failed to transfer %s to %s: %v", localPath, remoteDownload.GetPath(), err)
+		}
+	}
+
+	// The hash is of the local content, as the remote file is not read back.
 	hash, err := f.computeHash(file.content, types.HashType_MD5)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to compute hash: %v", err)
@@ -318,6 +413,26 @@ func (f *file) TransferToRemote(ctx context.Context, req *fpb.TransferToRemoteRe
 	}, nil
 }
 
+// fetch downloads the remote file described by rd. Unlike uploads, downloads
+// cannot be simulated, so they fail unless remote transfers are enabled.
+func (f *file) fetch(ctx context.Context, rd *cpb.RemoteDownload) ([]byte, error) {
+	if !f.remoteTransfer {
+		return nil, status.Error(codes.FailedPrecondition, "remote transfers are disabled")
+	}
+	target, err := parseRemoteDownload(rd)
+	if err != nil {
+		return nil, err
+	}
+	transferCtx, cancel := context.WithTimeout(ctx, f.transferTimeout)
+	defer cancel()
+	content, err := target.download(transferCtx)
+	if err != nil {
+		log.Errorf("File: failed to download %s: %v", rd.GetPath(), err)
+		return nil, status.Errorf(codes.Unavailable, "failed to download %s: %v", rd.GetPath(), err)
+	}
+	return content, nil
+}
+
 // validatePath validates and normalizes a file path.
 func (f *file) validatePath(path string) (string, error) {
 	if path == "" {
@@ -378,6 +493,60 @@ func (f *file) compareHashes(hash1, hash2 []byte) bool {
 	return true
 }
 
+// write stores content at filePath in the simulated file system, replacing
+// any existing file. If the file system is backed by a directory, the file is
+// also written to it, always readable and writable by its owner so that it
+// can be loaded again.
+func (f *file) write(filePath string, content []byte, permissions uint32) error {
+	now := time.Now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.root != "" {
+		diskPath := filepath.Join(f.root, filePath)
+		if err := goos.MkdirAll(filepath.Dir(diskPath), dirPermissions); err != nil {
+			return err
+		}
+		mode := fs.FileMode(permissions&0o777 | 0o600)
+		if err := goos.WriteFile(diskPath, content, mode); err != nil {
+			return err
+		}
+		// WriteFile only sets the mode of new files.
+		if err := goos.Chmod(diskPath, mode); err != nil {
+			return err
+		}
+	}
+	created := now
+	if existing, ok := f.files[filePath]; ok {
+		created = existing.created
+	}
+	f.files[filePath] = &fileInfo{
+		path:        filePath,
+		content:     content,
+		permissions: permissions,
+		created:     created,
+		modified:    now,
+	}
+	return nil
+}
+
+// remove deletes filePath from the simulated file system, and its backing
+// directory if any, and returns whether it existed.
+func (f *file) remove(filePath string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, exists := f.files[filePath]
+	if !exists {
+		return false, nil
+	}
+	if f.root != "" {
+		if err := goos.Remove(filepath.Join(f.root, filePath)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return true, err
+		}
+	}
+	delete(f.files, filePath)
+	return true, nil
+}
+
 // GetFileInfo returns information about a specific file for testing.
 func (f *file) GetFileInfo(filePath string) (*fileInfo, bool) {
 	f.mu.RLock()
@@ -16,7 +16,10 @@ package gnoi
 
 import (
 	"context"
+	"errors"
+	"io"
 	"math"
+	"net"
 	"sync"
 	"time"
 
@@ -26,6 +29,8 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/openconfig/lemming/dataplane/protocol/icmp"
+	"github.com/openconfig/lemming/dataplane/protocol/linkqual"
 	"github.com/openconfig/lemming/gnmi/fakedevice"
 	"github.com/openconfig/lemming/gnmi/oc"
 	"github.com/openconfig/lemming/gnmi/oc/ocpath"
@@ -34,6 +39,7 @@ import (
 	gpb "github.com/openconfig/gnmi/proto/gnmi"
 	bpb "github.com/openconfig/gnoi/bgp"
 	cmpb "github.com/openconfig/gnoi/cert"
+	czpb "github.com/openconfig/gnoi/containerz"
 	diagpb "github.com/openconfig/gnoi/diag"
 	frpb "github.com/openconfig/gnoi/factory_reset"
 	fpb "github.com/openconfig/gnoi/file"
@@ -57,6 +63,12 @@ const (
 	defaultPingInterval = 1000000000
 	defaultPingWait     = 2000000000
 	defaultPingSize     = 56
+
+	// Traceroute default values
+	defaultTracerouteMaxTTL = 30
+	defaultTracerouteWait   = 2000000000
+	tracerouteProbesPerHop  = 3
+	tracerouteProbeSize     = 32
 )
 
 type bgp struct {
@@ -67,10 +79,6 @@ type cert struct {
 	cmpb.UnimplementedCertificateManagementServer
 }
 
-type diag struct {
-	diagpb.UnimplementedDiagServer
-}
-
 type factoryReset struct {
 	frpb.UnimplementedFactoryResetServer
 }
@@ -83,10 +91,6 @@ type layer2 struct {
 	lpb.UnimplementedLayer2Server
 }
 
-type mpls struct {
-	mpb.UnimplementedMPLSServer
-}
-
 type os struct {
 	ospb.UnimplementedOSServer
 }
@@ -102,21 +106,19 @@ type system struct {
 	config *configpb.Config
 
 	// rebootMu has the following roles:
-	// * ensures that writes to hasPendingReboot are free from race
-	//   conditions
+	// * ensures that writes to pendingReboot and rebootHistory are free
+	//   from race conditions
 	// * ensures consistency between reboot operations and the current
-	//   state of hasPendingReboot (i.e. prevent TOCCTOU race conditions).
-	rebootMu         sync.Mutex
-	hasPendingReboot bool
-	// These channels ensure that cancellation is a blocking operation to
-	// avoid future reboots from conflicting with cancelled pending
-	// reboots.
-	cancelReboot       chan struct{}
-	cancelRebootFinish chan struct{}
-	// componentRebootsMu protects the componentReboots map
-	// Map to track pending component reboots by component name
-	componentRebootsMu sync.Mutex
-	componentReboots   map[string]chan struct{}
+	//   state of pendingReboot (i.e. prevent TOCCTOU race conditions).
+	rebootMu      sync.Mutex
+	pendingReboot *pendingReboot
+	rebootHistory rebootHistory
+	// componentRebootsMu protects the componentReboots and
+	// componentRebootHistory maps
+	// Maps to track pending and past component reboots by component name
+	componentRebootsMu     sync.Mutex
+	componentReboots       map[string]*pendingReboot
+	componentRebootHistory map[string]*rebootHistory
 	// switchoverMu protects switchover operations and ensures
 	// only one switchover can be in progress at a time
 	switchoverMu         sync.Mutex
@@ -124,20 +126,94 @@ type system struct {
 	// processMu protects process operations and ensures
 	// only one process operation can be in progress at a time
 	processMu sync.Mutex
+	// rib resolves destinations for simulated diagnostics, if set.
+	rib RIB
+	// prober sends diagnostic probes through the dataplane, if set.
+	prober Prober
+	// rebooter resets the device state on reboot, if set.
+	rebooter Rebooter
+	// files stores the packages set on the system.
+	files *file
+	// clock is the device clock.
+	clock *fakedevice.Clock
 }
 
 func newSystem(c *ygnmi.Client, config *configpb.Config) *system {
 	return &system{
-		c:                  c,
-		config:             config,
-		cancelReboot:       make(chan struct{}, 1),
-		cancelRebootFinish: make(chan struct{}),
-		componentReboots:   make(map[string]chan struct{}),
+		c:                      c,
+		config:                 config,
+		clock:                  fakedevice.NewClock(config.GetClock()),
+		componentReboots:       make(map[string]*pendingReboot),
+		componentRebootHistory: make(map[string]*rebootHistory),
+	}
+}
+
+// pendingReboot is a reboot waiting for its delay to expire.
+type pendingReboot struct {
+	when   time.Time
+	method spb.RebootMethod
+	reason string
+	// cancel is closed when the reboot is cancelled.
+	cancel chan struct{}
+}
+
+func newPendingReboot(r *spb.RebootRequest) *pendingReboot {
+	return &pendingReboot{
+		when:   time.Now().Add(time.Duration(r.GetDelay()) * time.Nanosecond),
+		method: r.GetMethod(),
+		reason: r.GetMessage(),
+		cancel: make(chan struct{}),
 	}
 }
 
-func (*system) Time(context.Context, *spb.TimeRequest) (*spb.TimeResponse, error) {
-	return &spb.TimeResponse{Time: uint64(time.Now().UnixNano())}, nil
+// rebootHistory records the reboots performed on the system or on a
+// component.
+type rebootHistory struct {
+	count  uint32
+	when   time.Time
+	method spb.RebootMethod
+	reason string
+	status *spb.RebootStatus
+}
+
+// record records a reboot performed at when, which failed if err is set.
+func (h *rebootHistory) record(when time.Time, method spb.RebootMethod, reason string, err error) {
+	h.count++
+	h.when = when
+	h.method = method
+	h.reason = reason
+	h.status = &spb.RebootStatus{Status: spb.RebootStatus_STATUS_SUCCESS}
+	if err != nil {
+		h.status = &spb.RebootStatus{Status: spb.RebootStatus_STATUS_FAILURE, Message: err.Error()}
+	}
+}
+
+// rebootStatus returns the status of the pending reboot p if set, or else
+// of the last reboot recorded in h.
+func rebootStatus(p *pendingReboot, h *rebootHistory) *spb.RebootStatusResponse {
+	resp := &spb.RebootStatusResponse{}
+	if h != nil {
+		resp.Count = h.count
+		resp.Method = h.method
+		resp.Reason = h.reason
+		resp.Status = h.status
+		if !h.when.IsZero() {
+			resp.When = uint64(h.when.UnixNano())
+		}
+	}
+	if p != nil {
+		resp.Active = true
+		resp.Wait = uint64(max(time.Until(p.when), 0))
+		resp.When = uint64(p.when.UnixNano())
+		resp.Method = p.method
+		resp.Reason = p.reason
+	}
+	return resp
+}
+
+// Time returns the time of the device clock.
+func (s *system) Time(context.Context, *spb.TimeRequest) (*spb.TimeResponse, error) {
+	return &spb.TimeResponse{Time: uint64(s.clock.Now().UnixNano())}, nil
 }
 
 func (s *system) Reboot(ctx context.Context, r *spb.RebootRequest) (*spb.RebootResponse, error) {
@@ -164,7 +240,7 @@ func (s *system) Reboot(ctx context.Context, r *spb.RebootRequest) (*spb.RebootR
 func (s *system) handleComponentReboot(ctx context.Context, r *spb.RebootRequest) (*spb.RebootResponse, error) {
 	// Check if there's a system-wide reboot pending, which would block all component reboots
 	s.rebootMu.Lock()
-	systemRebootPending := s.hasPendingReboot
+	systemRebootPending := s.pendingReboot != nil
 	s.rebootMu.Unlock()
 
 	if systemRebootPending {
@@ -194,58 +270,47 @@ func (s *system) handleComponentReboot(ctx context.Context, r *spb.RebootRequest
 			return nil, status.Errorf(codes.NotFound, "component %q not found: %v", componentName, err)
 		}
 
-		delay := r.GetDelay()
-		if delay == 0 {
-			s.componentRebootsMu.Lock()
-			if _, exists := s.componentReboots[componentName]; exists {
-				s.componentRebootsMu.Unlock()
-				return nil, status.Errorf(codes.AlreadyExists, "reboot already pending for component %q", componentName)
-			}
+		s.componentRebootsMu.Lock()
+		if _, exists := s.componentReboots[componentName]; exists {
+			s.componentRebootsMu.Unlock()
+			return nil, status.Errorf(codes.AlreadyExists, "reboot already pending for component %q", componentName)
+		}
+		if r.GetDelay() == 0 {
 			s.componentRebootsMu.Unlock()
 			// Immediate reboot
-			if err := fakedevice.RebootComponent(context.Background(), s.c, componentName, time.Now().UnixNano(), s.config); err != nil {
+			if err := s.rebootComponent(context.Background(), componentName, r.GetMethod(), r.GetMessage()); err != nil {
 				return nil, status.Errorf(codes.Internal, "failed to reboot component %q: %v", componentName, err)
 			}
 			log.Infof("Component %q immediate reboot completed", componentName)
 			continue
 		}
-		// Check if there's already a pending reboot for this component
-		s.componentRebootsMu.Lock()
-		if _, exists := s.componentReboots[componentName]; exists {
-			s.componentRebootsMu.Unlock()
-			return nil, status.Errorf(codes.AlreadyExists, "reboot already pending for component %q", componentName)
-		}
-
-		// Create a cancellation channel for this component
-		cancelCh := make(chan struct{}, 1)
-		rebootCtx, cancel := context.WithCancel(context.Background())
-		s.componentReboots[componentName] = cancelCh
+		p := newPendingReboot(r)
+		s.componentReboots[componentName] = p
 		s.componentRebootsMu.Unlock()
 
-		// Cleanup function for consistent cleanup
-		cleanup := func() {
-			cancel()
-			s.componentRebootsMu.Lock()
-			delete(s.componentReboots, componentName)
-			s.componentRebootsMu.Unlock()
-		}
-
 		// Handle delayed reboot
 		go func(compName string) {
-			defer cleanup()
+			timer := time.NewTimer(time.Until(p.when))
+			defer timer.Stop()
 			select {
-			case <-cancelCh:
+			case <-p.cancel:
 				log.Infof("delayed component reboot for %q cancelled", compName)
-			case <-rebootCtx.Done():
-				log.Infof("delayed component reboot for %q cancelled due to context", compName)
-			case <-time.After(time.Duration(delay) * time.Nanosecond):
-				now := time.Now().UnixNano()
-				if err := fakedevice.RebootComponent(rebootCtx, s.c, compName, now, s.config); err != nil {
-					log.Errorf("delayed component reboot for %q failed: %v", compName, err)
-					return
-				}
-				log.Infof("Component %q delayed reboot completed", compName)
+				return
+			case <-timer.C:
+			}
+			s.componentRebootsMu.Lock()
+			if s.componentReboots[compName] != p {
+				// The reboot was cancelled as the delay expired.
+				s.componentRebootsMu.Unlock()
+				return
 			}
+			delete(s.componentReboots, compName)
+			s.componentRebootsMu.Unlock()
+			if err := s.rebootComponent(context.Background(), compName, p.method, p.reason); err != nil {
+				log.Errorf("delayed component reboot for %q failed: %v", compName, err)
+				return
+			}
+			log.Infof("Component %q delayed reboot completed", compName)
 		}(componentName)
 
 		log.Infof("scheduled component reboot for %q with delay %v", componentName, r.GetDelay())
@@ -254,94 +319,168 @@ func (s *system) handleComponentReboot(ctx context.Context, r *spb.RebootRequest
 	return &spb.RebootResponse{}, nil
 }
 
+// rebootComponent reboots the component and records the reboot in its
+// history.
+func (s *system) rebootComponent(ctx context.Context, componentName string, method spb.RebootMethod, reason string) error {
+	now := time.Now()
+	err := fakedevice.RebootComponent(ctx, s.c, componentName, now.UnixNano(), s.config)
+
+	s.componentRebootsMu.Lock()
+	defer s.componentRebootsMu.Unlock()
+	h, ok := s.componentRebootHistory[componentName]
+	if !ok {
+		h = &rebootHistory{}
+		s.componentRebootHistory[componentName] = h
+	}
+	h.record(now, method, reason, err)
+	return err
+}
+
 // handleSystemReboot processes a reboot request for chassis
 func (s *system) handleSystemReboot(ctx context.Context, r *spb.RebootRequest) error {
 	s.rebootMu.Lock()
 	defer s.rebootMu.Unlock()
-	if s.hasPendingReboot {
+	if s.pendingReboot != nil {
 		return status.Errorf(codes.AlreadyExists, "reboot already pending")
 	}
 
-	delay := r.GetDelay()
-	if delay == 0 {
-		now := time.Now().UnixNano()
-		if err := fakedevice.Reboot(ctx, s.c, now); err != nil {
+	if r.GetDelay() == 0 {
+		reset, err := s.reboot(ctx, r.GetMethod(), r.GetMessage())
+		if err != nil {
 			return status.Error(codes.Internal, err.Error())
 		}
+		if reset != nil {
+			// The reset takes the gRPC services down, so it waits for
+			// the RPC to complete.
+			go func() {
+				<-ctx.Done()
+				reset()
+			}()
+		}
 		return nil
 	}
 
-	s.hasPendingReboot = true
+	p := newPendingReboot(r)
+	s.pendingReboot = p
 	go func() { // wait the delay time for reboot
+		timer := time.NewTimer(time.Until(p.when))
+		defer timer.Stop()
 		select {
-		case <-s.cancelReboot:
+		case <-p.cancel:
 			log.Infof("delayed reboot cancelled")
-			s.cancelRebootFinish <- struct{}{}
-		case <-time.After(time.Duration(delay) * time.Nanosecond):
-			now := time.Now().UnixNano()
-			if err := fakedevice.Reboot(ctx, s.c, now); err != nil {
-				log.Errorf("delayed reboot failed: %v", err)
-			}
-			s.rebootMu.Lock()
-			defer s.rebootMu.Unlock()
-			s.hasPendingReboot = false
+			return
+		case <-timer.C:
+		}
+		s.rebootMu.Lock()
+		if s.pendingReboot != p {
+			// The reboot was cancelled as the delay expired.
+			s.rebootMu.Unlock()
+			return
+		}
+		s.pendingReboot = nil
+		reset, err := s.reboot(context.Background(), p.method, p.reason)
+		s.rebootMu.Unlock()
+		if err != nil {
+			log.Errorf("delayed reboot failed: %v", err)
+			return
+		}
+		if reset != nil {
+			reset()
 		}
 	}()
 	return nil
 }
 
+// reboot updates the boot time of the device and records the reboot in the
+// history. If the configuration asks for the device state to be reset, it
+// returns the function resetting it, nil otherwise. The reset takes the gRPC
+// services down, so the caller runs it once no RPC depends on them, and
+// without s.rebootMu held. s.rebootMu must be held.
+func (s *system) reboot(ctx context.Context, method spb.RebootMethod, reason string) (func(), error) {
+	now := time.Now()
+	err := fakedevice.Reboot(ctx, s.c, now.UnixNano(), s.config)
+	s.rebootHistory.record(now, method, reason, err)
+	if err != nil {
+		return nil, err
+	}
+	if s.rebooter == nil || !s.config.GetReboot().GetResetState() {
+		return nil, nil
+	}
+	return func() {
+		if err := s.rebooter.Reboot(context.Background()); err != nil {
+			log.Errorf("failed to reset device state on reboot: %v", err)
+		}
+	}, nil
+}
+
+// CancelReboot cancels the pending reboots of the requested subcomponents,
+// or of the system and all the components if none is requested.
 func (s *system) CancelReboot(ctx context.Context, c *spb.CancelRebootRequest) (*spb.CancelRebootResponse, error) {
 	log.Infof("Received cancel reboot request %v", c)
 
-	// Check if there are any component reboots to cancel
+	var components []string
+	for _, subcompPath := range c.GetSubcomponents() {
+		componentName, err := extractComponentNameFromPath(subcompPath)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, componentName)
+	}
+
 	s.componentRebootsMu.Lock()
-	componentRebootsPending := len(s.componentReboots)
-	for component, cancelCh := range s.componentReboots {
-		select {
-		case cancelCh <- struct{}{}:
-			log.Infof("Sent cancellation signal for component %q reboot", component)
-		default:
-			// Channel already has a message or is closed
-			log.Infof("Component %q reboot already completed or in progress, couldn't cancel", component)
+	if len(components) == 0 {
+		for component := range s.componentReboots {
+			components = append(components, component)
+		}
+	}
+	for _, component := range components {
+		if p, ok := s.componentReboots[component]; ok {
+			close(p.cancel)
+			delete(s.componentReboots, component)
+			log.Infof("Cancelled pending reboot of component %q", component)
 		}
-		delete(s.componentReboots, component)
 	}
 	s.componentRebootsMu.Unlock()
 
-	// Check for system-wide reboot to cancel
-	s.rebootMu.Lock()
-	hasPendingReboot := s.hasPendingReboot
-	s.rebootMu.Unlock()
-
-	if !hasPendingReboot && componentRebootsPending == 0 {
-		// No reboots of any kind to cancel
+	if len(c.GetSubcomponents()) > 0 {
 		return &spb.CancelRebootResponse{}, nil
 	}
 
-	if hasPendingReboot {
-		s.cancelReboot <- struct{}{} // signal cancellation
-		for {
-			select {
-			case <-s.cancelRebootFinish:
-				s.rebootMu.Lock()
-				defer s.rebootMu.Unlock()
-				s.hasPendingReboot = false
-				return &spb.CancelRebootResponse{}, nil
-			case <-time.After(time.Second): // It's possible for reboot to happen after cancellation signal -- use polling to check that.
-				s.rebootMu.Lock()
-				if !s.hasPendingReboot {
-					s.rebootMu.Unlock()
-					<-s.cancelReboot // clean-up cancellation signal that's not needed since reboot actually happened.
-					return &spb.CancelRebootResponse{}, nil
-				}
-				s.rebootMu.Unlock()
-			}
-		}
+	s.rebootMu.Lock()
+	defer s.rebootMu.Unlock()
+	if s.pendingReboot != nil {
+		close(s.pendingReboot.cancel)
+		s.pendingReboot = nil
+		log.Infof("Cancelled pending system reboot")
 	}
-
 	return &spb.CancelRebootResponse{}, nil
 }
 
+// RebootStatus returns the status of the pending or last reboot of the
+// system, or of the requested subcomponent.
+func (s *system) RebootStatus(ctx context.Context, r *spb.RebootStatusRequest) (*spb.RebootStatusResponse, error) {
+	switch len(r.GetSubcomponents()) {
+	case 0:
+		s.rebootMu.Lock()
+		defer s.rebootMu.Unlock()
+		return rebootStatus(s.pendingReboot, &s.rebootHistory), nil
+	case 1:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "reboot status of a single subcomponent can be requested, got %d", len(r.GetSubcomponents()))
+	}
+
+	componentName, err := extractComponentNameFromPath(r.GetSubcomponents()[0])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ygnmi.Get(ctx, s.c, ocpath.Root().Component(componentName).State()); err != nil {
+		return nil, status.Errorf(codes.NotFound, "component %q not found: %v", componentName, err)
+	}
+	s.componentRebootsMu.Lock()
+	defer s.componentRebootsMu.Unlock()
+	return rebootStatus(s.componentReboots[componentName], s.componentRebootHistory[componentName]), nil
+}
+
 // SwitchControlProcessor performs supervisor switchover from the current active supervisor to the specified target supervisor
 func (s *system) SwitchControlProcessor(ctx context.Context, r *spb.SwitchControlProcessorRequest) (*spb.SwitchControlProcessorResponse, error) {
 	log.Infof("Received SwitchControlProcessor request: %v", r)
@@ -365,7 +504,7 @@ func (s *system) SwitchControlProcessor(ctx context.Context, r *spb.SwitchContro
 
 	// Check if there are any pending reboot operations (system or component level)
 	s.rebootMu.Lock()
-	systemRebootPending := s.hasPendingReboot
+	systemRebootPending := s.pendingReboot != nil
 	s.rebootMu.Unlock()
 
 	s.componentRebootsMu.Lock()
@@ -477,7 +616,83 @@ func (s *system) KillProcess(ctx context.Context, r *spb.KillProcessRequest) (*s
 	return &spb.KillProcessResponse{}, nil
 }
 
-// Ping simulates ICMP ping operations with configurable network conditions
+// SetPackage stores a package, streamed by the client or downloaded from a
+// remote server, in the file system once its hash is verified. Activating
+// the package updates the software version reported by the device.
+func (s *system) SetPackage(stream spb.System_SetPackageServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to receive request: %v", err)
+	}
+	pkg := req.GetPackage()
+	if pkg == nil {
+		return status.Error(codes.InvalidArgument, "first message must be a Package")
+	}
+	filePath, err := s.files.validatePath(pkg.GetFilename())
+	if err != nil {
+		return err
+	}
+	if pkg.GetActivate() && pkg.GetVersion() == "" {
+		return status.Errorf(codes.InvalidArgument, "a version is required to activate package %s", filePath)
+	}
+	log.Infof("SetPackage: receiving package %s version %q", filePath, pkg.GetVersion())
+
+	var content []byte
+	var hash *pb.HashType
+	for hash == nil {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return status.Error(codes.InvalidArgument, "no Hash message received")
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to receive request: %v", err)
+		}
+		switch r := req.GetRequest().(type) {
+		case *spb.SetPackageRequest_Contents:
+			if pkg.GetRemoteDownload() != nil {
+				return status.Error(codes.InvalidArgument, "contents cannot be sent for a remote download")
+			}
+			if err := s.files.validateFileSize(len(content) + len(r.Contents)); err != nil {
+				return err
+			}
+			content = append(content, r.Contents...)
+		case *spb.SetPackageRequest_Hash:
+			hash = r.Hash
+		default:
+			return status.Error(codes.InvalidArgument, "unexpected message after the Package")
+		}
+	}
+
+	if rd := pkg.GetRemoteDownload(); rd != nil {
+		if content, err = s.files.fetch(stream.Context(), rd); err != nil {
+			return err
+		}
+	}
+	got, err := s.files.computeHash(content, hash.GetMethod())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !s.files.compareHashes(got, hash.GetHash()) {
+		log.Errorf("SetPackage: hash mismatch for %s", filePath)
+		return status.Errorf(codes.InvalidArgument, "hash verification failed for package %s", filePath)
+	}
+	if err := s.files.write(filePath, content, 0o644); err != nil {
+		return status.Errorf(codes.Internal, "failed to store package %s: %v", filePath, err)
+	}
+
+	if pkg.GetActivate() {
+		if err := fakedevice.SetSoftwareVersion(stream.Context(), s.c, pkg.GetVersion(), s.config); err != nil {
+			return status.Errorf(codes.Internal, "failed to activate package %s: %v", filePath, err)
+		}
+		log.Infof("SetPackage: activated version %q", pkg.GetVersion())
+	}
+	log.Infof("SetPackage: stored package %s (%d bytes)", filePath, len(content))
+	return stream.SendAndClose(&spb.SetPackageResponse{})
+}
+
+// Ping pings a destination. With a dataplane, ICMP echo requests are sent
+// through it in the requested network instance. Otherwise, the replies are
+// simulated with the configured network conditions.
 func (s *system) Ping(r *spb.PingRequest, stream spb.System_PingServer) error {
 	log.Infof("Received ping request: %v", r)
 
@@ -520,7 +735,14 @@ func (s *system) Ping(r *spb.PingRequest, stream spb.System_PingServer) error {
 		return status.Errorf(codes.InvalidArgument, "packet size must be between 8 and 65507 bytes, got %d", size)
 	}
 
-	// TODO: Add support for do_not_fragment, do_not_resolve, l3protocol, network_instance parameters
+	var src, dst net.IP
+	var srcIntf string
+	if s.prober != nil {
+		var err error
+		if src, srcIntf, dst, err = pingAddresses(ctx, r); err != nil {
+			return err
+		}
+	}
 
 	// Calculate appropriate buffer size based on interval and count
 	bufferSize := 100
@@ -535,8 +757,14 @@ func (s *system) Ping(r *spb.PingRequest, stream spb.System_PingServer) error {
 
 	go func() {
 		defer close(responseChan)
-		if err := fakedevice.PingSimulation(ctx, destination, count, time.Duration(interval), time.Duration(wait), uint32(size), responseChan, s.config); err != nil {
-			log.Errorf("Ping simulation error: %v", err)
+		var err error
+		if s.prober != nil {
+			err = s.dataplanePing(ctx, r, src, srcIntf, dst, count, time.Duration(interval), time.Duration(wait), int(size), responseChan)
+		} else {
+			err = fakedevice.PingSimulation(ctx, destination, count, time.Duration(interval), time.Duration(wait), uint32(size), responseChan, s.config)
+		}
+		if err != nil {
+			log.Errorf("Ping error: %v", err)
 			select {
 			case errorChan <- err:
 			default:
@@ -559,7 +787,7 @@ func (s *system) Ping(r *spb.PingRequest, stream spb.System_PingServer) error {
 			return ctx.Err()
 		case err := <-errorChan:
 			if err != nil {
-				return status.Errorf(codes.Internal, "ping simulation failed: %v", err)
+				return status.Errorf(codes.Internal, "ping failed: %v", err)
 			}
 		case result, ok := <-responseChan:
 			if !ok {
@@ -567,7 +795,7 @@ func (s *system) Ping(r *spb.PingRequest, stream spb.System_PingServer) error {
 				select {
 				case err := <-errorChan:
 					if err != nil {
-						return status.Errorf(codes.Internal, "ping simulation failed: %v", err)
+						return status.Errorf(codes.Internal, "ping failed: %v", err)
 					}
 				default:
 				}
@@ -641,6 +869,331 @@ func (s *system) Ping(r *spb.PingRequest, stream spb.System_PingServer) error {
 	}
 }
 
+// probeNetworkInstance returns the network instance that probes of a request
+// for ni are originated in, the default one if ni is unset.
+func probeNetworkInstance(ni string) string {
+	if ni == "" {
+		return fakedevice.DefaultNetworkInstance
+	}
+	return ni
+}
+
+// pingAddresses returns the source and destination of a ping sent through
+// the dataplane, resolving the destination unless do_not_resolve is set. The
+// source is either an address or the name of the interface to send from.
+func pingAddresses(ctx context.Context, r *spb.PingRequest) (net.IP, string, net.IP, error) {
+	network := "ip"
+	switch r.GetL3Protocol() {
+	case pb.L3Protocol_IPV4:
+		network = "ip4"
+	case pb.L3Protocol_IPV6:
+		network = "ip6"
+	}
+
+	dst := net.ParseIP(r.GetDestination())
+	if dst == nil {
+		if r.GetDoNotResolve() {
+			return nil, "", nil, status.Errorf(codes.InvalidArgument, "destination %q is not an IP address and do_not_resolve is set", r.GetDestination())
+		}
+		addrs, err := net.DefaultResolver.LookupIP(ctx, network, r.GetDestination())
+		if err != nil || len(addrs) == 0 {
+			return nil, "", nil, status.Errorf(codes.NotFound, "failed to resolve %q: %v", r.GetDestination(), err)
+		}
+		dst = addrs[0]
+	}
+	isV4 := dst.To4() != nil
+	if (network == "ip4" && !isV4) || (network == "ip6" && isV4) {
+		return nil, "", nil, status.Errorf(codes.InvalidArgument, "destination %s does not match l3protocol %v", r.GetDestination(), r.GetL3Protocol())
+	}
+
+	if r.GetSource() == "" {
+		return nil, "", dst, nil
+	}
+	src := net.ParseIP(r.GetSource())
+	if src == nil {
+		return nil, r.GetSource(), dst, nil
+	}
+	if (src.To4() != nil) != isV4 {
+		return nil, "", nil, status.Errorf(codes.InvalidArgument, "source %s and destination %s are of different address families", r.GetSource(), dst)
+	}
+	return src, "", dst, nil
+}
+
+// dataplanePing sends echo requests through the dataplane at the given
+// interval and reports the replies. Probes that are not answered within wait
+// are reported as lost.
+func (s *system) dataplanePing(ctx context.Context, r *spb.PingRequest, src net.IP, srcIntf string, dst net.IP, count int32, interval, wait time.Duration, size int, responseChan chan<- *fakedevice.PingPacketResult) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	maxPackets := count
+	if maxPackets == -1 {
+		maxPackets = math.MaxInt32
+	}
+	for seq := int32(1); seq <= maxPackets; seq++ {
+		probeCtx, cancel := context.WithTimeout(ctx, wait)
+		reply, err := s.prober.Probe(probeCtx, probeNetworkInstance(r.GetNetworkInstance()), srcIntf, &icmp.Request{
+			Src:           src,
+			Dst:           dst,
+			TTL:           math.MaxUint8,
+			Size:          size,
+			DoNotFragment: r.GetDoNotFragment(),
+		})
+		cancel()
+		result := &fakedevice.PingPacketResult{Sequence: seq}
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, context.DeadlineExceeded):
+			log.Infof("Ping to %s: seq=%d TIMEOUT", dst, seq)
+		case err != nil:
+			return err
+		case reply.Type == icmp.EchoReply:
+			result.RTT = reply.RTT
+			result.Bytes = uint32(reply.Bytes)
+			result.TTL = int32(reply.TTL)
+			result.Success = true
+		default:
+			log.Infof("Ping to %s: seq=%d unreachable from %s, code %d", dst, seq, reply.From, reply.Code)
+		}
+		select {
+		case responseChan <- result:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if seq == maxPackets {
+			break
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Traceroute traces the path to a destination. With a dataplane, TTL-limited
+// ICMP echo requests are sent through it and the ICMP replies are collected.
+// Otherwise, the hops are synthesized from the RIB with simulated latency.
+func (s *system) Traceroute(r *spb.TracerouteRequest, stream spb.System_TracerouteServer) error {
+	log.Infof("Received traceroute request: %v", r)
+
+	if r.GetDestination() == "" {
+		return status.Errorf(codes.InvalidArgument, "destination address is required")
+	}
+	dst := net.ParseIP(r.GetDestination())
+	if dst == nil {
+		return status.Errorf(codes.InvalidArgument, "destination must be an IP address, got %q", r.GetDestination())
+	}
+	isV4 := dst.To4() != nil
+
+	var src net.IP
+	if r.GetSource() != "" {
+		if src = net.ParseIP(r.GetSource()); src == nil {
+			return status.Errorf(codes.InvalidArgument, "source must be an IP address, got %q", r.GetSource())
+		}
+		if (src.To4() != nil) != isV4 {
+			return status.Errorf(codes.InvalidArgument, "source %s and destination %s are of different address families", r.GetSource(), r.GetDestination())
+		}
+	}
+
+	switch r.GetL3Protocol() {
+	case pb.L3Protocol_IPV4:
+		if !isV4 {
+			return status.Errorf(codes.InvalidArgument, "destination %s is not an IPv4 address", r.GetDestination())
+		}
+	case pb.L3Protocol_IPV6:
+		if isV4 {
+			return status.Errorf(codes.InvalidArgument, "destination %s is not an IPv6 address", r.GetDestination())
+		}
+	}
+	if r.GetL4Protocol() != spb.TracerouteRequest_ICMP {
+		return status.Errorf(codes.Unimplemented, "only ICMP probes are supported, got %v", r.GetL4Protocol())
+	}
+
+	// The TTLs are validated before any conversion, so that out of range
+	// values do not wrap.
+	if ttl := r.GetInitialTtl(); ttl > math.MaxUint8 {
+		return status.Errorf(codes.InvalidArgument, "initial ttl must be between 1 and 255, got %d", ttl)
+	}
+	if ttl := r.GetMaxTtl(); ttl < 0 || ttl > math.MaxUint8 {
+		return status.Errorf(codes.InvalidArgument, "max ttl must be between 1 and 255, got %d", ttl)
+	}
+	initialTTL := int32(r.GetInitialTtl())
+	if initialTTL == 0 {
+		initialTTL = 1
+	}
+	maxTTL := r.GetMaxTtl()
+	if maxTTL == 0 {
+		maxTTL = defaultTracerouteMaxTTL
+	}
+	if initialTTL > maxTTL {
+		return status.Errorf(codes.InvalidArgument, "initial ttl %d is greater than max ttl %d", initialTTL, maxTTL)
+	}
+
+	wait := r.GetWait()
+	if wait == 0 {
+		wait = defaultTracerouteWait
+	} else if wait < 0 {
+		return status.Errorf(codes.InvalidArgument, "wait must be >= 0, got %d", wait)
+	}
+
+	headerSize := 28 // IPv4 + ICMP header
+	if !isV4 {
+		headerSize = 48 // IPv6 + ICMPv6 header
+	}
+	if err := stream.Send(&spb.TracerouteResponse{
+		DestinationName:    r.GetDestination(),
+		DestinationAddress: dst.String(),
+		Hops:               maxTTL,
+		PacketSize:         int32(headerSize + tracerouteProbeSize),
+	}); err != nil {
+		return err
+	}
+
+	if s.prober != nil {
+		return s.dataplaneTraceroute(r, stream, src, dst, initialTTL, maxTTL, time.Duration(wait))
+	}
+	return s.simulatedTraceroute(r, stream, dst, initialTTL, maxTTL, time.Duration(wait))
+}
+
+// dataplaneTraceroute sends TTL-limited probes through the dataplane and
+// streams the ICMP replies, stopping once the destination has answered.
+func (s *system) dataplaneTraceroute(r *spb.TracerouteRequest, stream spb.System_TracerouteServer, src, dst net.IP, initialTTL, maxTTL int32, wait time.Duration) error {
+	ctx := stream.Context()
+	for hop := initialTTL; hop <= maxTTL; hop++ {
+		done := false
+		for i := 0; i < tracerouteProbesPerHop; i++ {
+			probeCtx, cancel := context.WithTimeout(ctx, wait)
+			reply, err := s.prober.Probe(probeCtx, probeNetworkInstance(r.GetNetworkInstance()), "", &icmp.Request{
+				Src:           src,
+				Dst:           dst,
+				TTL:           uint8(hop),
+				Size:          tracerouteProbeSize,
+				DoNotFragment: r.GetDoNotFragment(),
+			})
+			cancel()
+			resp := &spb.TracerouteResponse{Hop: hop}
+			switch {
+			case ctx.Err() != nil:
+				return ctx.Err()
+			case errors.Is(err, context.DeadlineExceeded):
+				resp.State = spb.TracerouteResponse_NONE
+			case err != nil:
+				return status.Errorf(codes.Internal, "failed to send probe: %v", err)
+			default:
+				resp.Address = reply.From.String()
+				resp.Rtt = reply.RTT.Nanoseconds()
+				resp.State, resp.IcmpCode = tracerouteState(reply, dst.To4() != nil)
+				done = done || reply.Type != icmp.TimeExceeded
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+		if done {
+			return nil
+		}
+	}
+	return nil
+}
+
+// tracerouteState returns the state of a traceroute hop for the ICMP reply.
+func tracerouteState(reply *icmp.Reply, isV4 bool) (spb.TracerouteResponse_State, int32) {
+	if reply.Type != icmp.Unreachable {
+		return spb.TracerouteResponse_DEFAULT, 0
+	}
+	code := int32(reply.Code)
+	if isV4 {
+		switch reply.Code {
+		case 0:
+			return spb.TracerouteResponse_NETWORK_UNREACHABLE, code
+		case 1:
+			return spb.TracerouteResponse_HOST_UNREACHABLE, code
+		case 2:
+			return spb.TracerouteResponse_PROTOCOL_UNREACHABLE, code
+		case 4:
+			return spb.TracerouteResponse_FRAGMENTATION_NEEDED, code
+		case 5:
+			return spb.TracerouteResponse_SOURCE_ROUTE_FAILED, code
+		case 9, 10, 13:
+			return spb.TracerouteResponse_PROHIBITED, code
+		case 14:
+			return spb.TracerouteResponse_PRECEDENCE_VIOLATION, code
+		case 15:
+			return spb.TracerouteResponse_PRECEDENCE_CUTOFF, code
+		}
+		return spb.TracerouteResponse_ICMP, code
+	}
+	switch reply.Code {
+	case 0:
+		return spb.TracerouteResponse_NETWORK_UNREACHABLE, code
+	case 1:
+		return spb.TracerouteResponse_PROHIBITED, code
+	case 3:
+		return spb.TracerouteResponse_HOST_UNREACHABLE, code
+	}
+	return spb.TracerouteResponse_ICMP, code
+}
+
+// simulatedTraceroute synthesizes the hops to the destination from the
+// next-hops traversed when resolving it in the RIB.
+func (s *system) simulatedTraceroute(r *spb.TracerouteRequest, stream spb.System_TracerouteServer, dst net.IP, initialTTL, maxTTL int32, wait time.Duration) error {
+	ctx := stream.Context()
+
+	var path []string
+	if s.rib != nil {
+		chain, found, err := s.rib.NexthopChain(r.GetNetworkInstance(), dst.String())
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to resolve %s: %v", dst, err)
+		}
+		if !found {
+			return stream.Send(&spb.TracerouteResponse{
+				Hop:   initialTTL,
+				State: spb.TracerouteResponse_NETWORK_UNREACHABLE,
+			})
+		}
+		path = chain
+	}
+	if len(path) == 0 || !net.ParseIP(path[len(path)-1]).Equal(dst) {
+		path = append(path, dst.String())
+	}
+
+	responseChan := make(chan *fakedevice.TracerouteProbeResult, tracerouteProbesPerHop)
+	errorChan := make(chan error, 1)
+	go func() {
+		defer close(responseChan)
+		if err := fakedevice.TracerouteSimulation(ctx, path, initialTTL, maxTTL, tracerouteProbesPerHop, wait, responseChan, s.config); err != nil {
+			log.Errorf("Traceroute simulation error: %v", err)
+			errorChan <- err
+		}
+		close(errorChan)
+	}()
+
+	for result := range responseChan {
+		resp := &spb.TracerouteResponse{
+			Hop:   result.Hop,
+			State: spb.TracerouteResponse_NONE,
+		}
+		if result.Success {
+			resp.Address = result.Address
+			resp.Rtt = result.RTT.Nanoseconds()
+			resp.State = spb.TracerouteResponse_DEFAULT
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	if err := <-errorChan; err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return status.Errorf(codes.Internal, "traceroute simulation failed: %v", err)
+	}
+	return nil
+}
+
 // resolvePIDAndName resolves either PID or name to a validated PID and process name that exists in the system
 func (s *system) resolvePIDAndName(ctx context.Context, pid uint32, name string) (uint32, string, error) {
 	var targetPID uint32
@@ -746,10 +1299,118 @@ type wavelengthRouter struct {
 	wrpb.UnimplementedWavelengthRouterServer
 }
 
+// RIB resolves destinations against the device's routing table.
+type RIB interface {
+	// NexthopChain returns the addresses of the next-hops traversed to
+	// reach address in the network instance, ordered from the one nearest
+	// to the device, and whether the address is routable.
+	NexthopChain(niName, address string) ([]string, bool, error)
+}
+
+// Prober sends ICMP probes through the device's forwarding plane.
+type Prober interface {
+	// Probe sends an echo request originated in the network instance,
+	// optionally sourced from the interface intf, and waits for the ICMP
+	// message sent in response to it.
+	Probe(ctx context.Context, ni, intf string, req *icmp.Request) (*icmp.Reply, error)
+}
+
+// Rebooter resets the protocol and dataplane state of the device.
+type Rebooter interface {
+	// Reboot takes down the device services, clears their state and
+	// brings them back up with the startup configuration.
+	Reboot(ctx context.Context) error
+}
+
+// LinkTester sends and reflects link qualification test packets through the
+// device's forwarding plane.
+type LinkTester interface {
+	// StartGenerator sends test packets of size bytes out of the interface
+	// intf at rate packets per second, and counts the ones looped back.
+	StartGenerator(intf string, rate uint64, size int) (linkqual.Endpoint, error)
+	// StartInjector injects count test packets of size bytes into the
+	// interface intf over duration, and counts the ones looped back.
+	StartInjector(intf string, count uint64, size int, duration time.Duration) (linkqual.Endpoint, error)
+	// StartReflector sends all the packets received on the interface intf
+	// back out of it.
+	StartReflector(intf string) (linkqual.Endpoint, error)
+}
+
+// LabelDataplane forwards MPLS label entries through the device's forwarding
+// plane. Methods return a NotFound error for labels that are not programmed.
+type LabelDataplane interface {
+	// ClearLabelCounters zeroes the counters of the label entry in the
+	// network instance.
+	ClearLabelCounters(ctx context.Context, ni string, label uint32) error
+	// ReprogramLabel removes the label entry from the network instance and
+	// programs it again.
+	ReprogramLabel(ctx context.Context, ni string, label uint32) error
+}
+
+// Option configures the gNOI server.
+type Option func(*opts)
+
+type opts struct {
+	rib        RIB
+	prober     Prober
+	rebooter   Rebooter
+	linkTester LinkTester
+	labels     LabelDataplane
+	clock      *fakedevice.Clock
+}
+
+// WithRIB sets the RIB used to synthesize the hops of simulated diagnostics.
+func WithRIB(rib RIB) Option {
+	return func(o *opts) {
+		o.rib = rib
+	}
+}
+
+// WithProber sets the dataplane used to send real diagnostic probes. Without
+// it, diagnostics are simulated.
+func WithProber(p Prober) Option {
+	return func(o *opts) {
+		o.prober = p
+	}
+}
+
+// WithRebooter sets the device used to reset the protocol and dataplane state
+// when the configuration enables a realistic reboot.
+func WithRebooter(r Rebooter) Option {
+	return func(o *opts) {
+		o.rebooter = r
+	}
+}
+
+// WithLinkTester sets the dataplane used to send real link qualification test
+// packets. Without it, link qualifications are simulated.
+func WithLinkTester(t LinkTester) Option {
+	return func(o *opts) {
+		o.linkTester = t
+	}
+}
+
+// WithLabelDataplane sets the dataplane whose label entries are cleared by the
+// MPLS service. Without it, only the label entry state is cleared.
+func WithLabelDataplane(l LabelDataplane) Option {
+	return func(o *opts) {
+		o.labels = l
+	}
+}
+
+// WithClock sets the device clock returned by the System Time RPC. Without
+// it, the clock is created from the configuration.
+func WithClock(c *fakedevice.Clock) Option {
+	return func(o *opts) {
+		o.clock = c
+	}
+}
+
 type Server struct {
 	s                       *grpc.Server
 	bgpServer               *bgp
 	certServer              *cert
+	containerzServer        *containerz
 	diagServer              *diag
 	fileServer              *file
 	resetServer             *factoryReset
@@ -763,30 +1424,52 @@ type Server struct {
 	wavelengthRouterServer  *wavelengthRouter
 }
 
-func New(s *grpc.Server, gClient gpb.GNMIClient, target string, config *configpb.Config) (*Server, error) {
+func New(s *grpc.Server, gClient gpb.GNMIClient, target string, config *configpb.Config, opt ...Option) (*Server, error) {
 	yclient, err := ygnmi.NewClient(gClient, ygnmi.WithTarget(target), ygnmi.WithRequestLogLevel(2))
 	if err != nil {
 		return nil, err
 	}
+	o := &opts{}
+	for _, fn := range opt {
+		fn(o)
+	}
+	fileServer, err := newFileFromConfig(config.GetFile())
+	if err != nil {
+		return nil, err
+	}
+	systemServer := newSystem(yclient, config)
+	systemServer.rib = o.rib
+	systemServer.prober = o.prober
+	systemServer.rebooter = o.rebooter
+	systemServer.files = fileServer
+	if o.clock != nil {
+		systemServer.clock = o.clock
+	}
+	linkQualificationServer := newLinkQualification(yclient, config)
+	linkQualificationServer.tester = o.linkTester
+	mplsServer := newMPLS(yclient)
+	mplsServer.labels = o.labels
 
 	srv := &Server{
 		s:                       s,
 		bgpServer:               &bgp{},
 		certServer:              &cert{},
-		diagServer:              &diag{},
-		fileServer:              newFile(),
+		containerzServer:        newContainerz(yclient, fileServer),
+		diagServer:              newDiag(yclient, config),
+		fileServer:              fileServer,
 		resetServer:             &factoryReset{},
 		healthzServer:           &healthz{},
 		layer2Server:            &layer2{},
-		mplsServer:              &mpls{},
+		mplsServer:              mplsServer,
 		osServer:                &os{},
 		otdrServer:              &otdr{},
-		linkQualificationServer: newLinkQualification(yclient, config),
-		systemServer:            newSystem(yclient, config),
+		linkQualificationServer: linkQualificationServer,
+		systemServer:            systemServer,
 		wavelengthRouterServer:  &wavelengthRouter{},
 	}
 	bpb.RegisterBGPServer(s, srv.bgpServer)
 	cmpb.RegisterCertificateManagementServer(s, srv.certServer)
+	czpb.RegisterContainerzServer(s, srv.containerzServer)
 	diagpb.RegisterDiagServer(s, srv.diagServer)
 	fpb.RegisterFileServer(s, srv.fileServer)
 	frpb.RegisterFactoryResetServer(s, srv.resetServer)
@@ -1,34 +1,56 @@
 package gnoi
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"crypto/md5"
+	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"math"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	goos "os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/openconfig/gnmi/errdiff"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/ygnmi/ygnmi"
 	"github.com/openconfig/ygot/ygot"
+	"golang.org/x/crypto/ssh"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	cpb "github.com/openconfig/gnoi/common"
+	czpb "github.com/openconfig/gnoi/containerz"
+	diagpb "github.com/openconfig/gnoi/diag"
 	fpb "github.com/openconfig/gnoi/file"
+	mpb "github.com/openconfig/gnoi/mpls"
 	plqpb "github.com/openconfig/gnoi/packet_link_qualification"
 	spb "github.com/openconfig/gnoi/system"
 	pb "github.com/openconfig/gnoi/types"
 	configpb "github.com/openconfig/lemming/proto/config"
 
+	"github.com/openconfig/lemming/dataplane/protocol/icmp"
+	"github.com/openconfig/lemming/dataplane/protocol/linkqual"
 	"github.com/openconfig/lemming/gnmi"
 	"github.com/openconfig/lemming/gnmi/fakedevice"
 	"github.com/openconfig/lemming/gnmi/gnmiclient"
@@ -99,6 +121,62 @@ func TestReboot(t *testing.T) {
 		}
 	})
 
+	t.Run("reset-state", func(t *testing.T) {
+		resetConfig := loadDefaultConfig(t)
+		resetConfig.Reboot = &configpb.RebootConfig{ResetState: true}
+		rs := newSystem(c, resetConfig)
+		rebooted := make(chan struct{})
+		rs.rebooter = rebooterFunc(func(context.Context) error {
+			close(rebooted)
+			return nil
+		})
+
+		rpcCtx, cancel := context.WithCancel(ctx)
+		if _, err := rs.Reboot(rpcCtx, &spb.RebootRequest{}); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-rebooted:
+			t.Fatalf("device state reset before the RPC completed")
+		case <-time.After(50 * time.Millisecond):
+		}
+		cancel()
+		select {
+		case <-rebooted:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("device state not reset after reboot")
+		}
+	})
+
+	t.Run("reset-state-delayed", func(t *testing.T) {
+		resetConfig := loadDefaultConfig(t)
+		resetConfig.Reboot = &configpb.RebootConfig{ResetState: true}
+		rs := newSystem(c, resetConfig)
+		rebooted := make(chan struct{})
+		rs.rebooter = rebooterFunc(func(context.Context) error {
+			close(rebooted)
+			return nil
+		})
+
+		// The RPC context is never done, as the delayed reset does not
+		// depend on it.
+		if _, err := rs.Reboot(ctx, &spb.RebootRequest{Delay: uint64(100 * time.Millisecond)}); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-rebooted:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("device state not reset after delayed reboot")
+		}
+		st, err := rs.RebootStatus(ctx, &spb.RebootStatusRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.GetActive() || st.GetCount() != 1 {
+			t.Errorf("RebootStatus after delayed reboot got %v, want 1 completed reboot", st)
+		}
+	})
+
 	t.Run("one-second-delay", func(t *testing.T) {
 		prevTime, err := ygnmi.Get(context.Background(), c, ocpath.Root().System().BootTime().State())
 		if err != nil {
@@ -163,6 +241,52 @@ func TestReboot(t *testing.T) {
 		}
 	})
 
+	t.Run("reboot-status", func(t *testing.T) {
+		before, err := s.RebootStatus(ctx, &spb.RebootStatusRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if before.GetActive() {
+			t.Fatalf("RebootStatus() got active reboot, want none")
+		}
+
+		const delay = 120e9
+		if _, err := s.Reboot(ctx, &spb.RebootRequest{Delay: delay, Message: "maintenance", Method: spb.RebootMethod_WARM}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.RebootStatus(ctx, &spb.RebootStatusRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.GetActive() || got.GetWait() == 0 || got.GetWait() > delay || got.GetReason() != "maintenance" || got.GetMethod() != spb.RebootMethod_WARM || got.GetCount() != before.GetCount() {
+			t.Errorf("RebootStatus() got %v, want active reboot in at most %v for maintenance", got, time.Duration(delay))
+		}
+		if _, err := s.CancelReboot(ctx, &spb.CancelRebootRequest{}); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := s.RebootStatus(ctx, &spb.RebootStatusRequest{}); err != nil || got.GetActive() {
+			t.Errorf("RebootStatus() after cancel got (%v, %v), want inactive", got, err)
+		}
+
+		if _, err := s.Reboot(ctx, &spb.RebootRequest{Message: "upgrade"}); err != nil {
+			t.Fatal(err)
+		}
+		got, err = s.RebootStatus(ctx, &spb.RebootStatusRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.GetActive() || got.GetReason() != "upgrade" || got.GetCount() != before.GetCount()+1 || got.GetStatus().GetStatus() != spb.RebootStatus_STATUS_SUCCESS {
+			t.Errorf("RebootStatus() got %v, want successful reboot #%d for upgrade", got, before.GetCount()+1)
+		}
+		chassis, err := ygnmi.Get(ctx, c, ocpath.Root().Component(lemmingConfig.GetComponents().GetChassisName()).State())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chassis.GetLastRebootTime() != got.GetWhen() || chassis.GetLastRebootReason() != oc.PlatformTypes_COMPONENT_REBOOT_REASON_REBOOT_USER_INITIATED {
+			t.Errorf("chassis got last reboot (%d, %v), want (%d, %v)", chassis.GetLastRebootTime(), chassis.GetLastRebootReason(), got.GetWhen(), oc.PlatformTypes_COMPONENT_REBOOT_REASON_REBOOT_USER_INITIATED)
+		}
+	})
+
 	t.Run("reboot-while-pending", func(t *testing.T) {
 		const delay = 120e9
 		if _, err := s.Reboot(ctx, &spb.RebootRequest{Delay: delay}); err != nil {
@@ -391,6 +515,57 @@ func TestComponentReboot(t *testing.T) {
 				s.componentRebootsMu.Unlock()
 			},
 		},
+		"cancel-selected-component-reboot": {
+			fn: func(t *testing.T, s *system, ctx context.Context) {
+				path := func(name string) *pb.Path {
+					return &pb.Path{
+						Elem: []*pb.PathElem{
+							{Name: "components"},
+							{Name: "component", Key: map[string]string{"name": name}},
+						},
+					}
+				}
+				req := &spb.RebootRequest{
+					Method:        spb.RebootMethod_COLD,
+					Delay:         120e9,
+					Message:       "replace optics",
+					Subcomponents: []*pb.Path{path("Linecard0"), path("Fabric0")},
+				}
+				if _, err := s.Reboot(ctx, req); err != nil {
+					t.Fatalf("Delayed component reboot failed: %v", err)
+				}
+				if _, err := s.CancelReboot(ctx, &spb.CancelRebootRequest{Subcomponents: []*pb.Path{path("Linecard0")}}); err != nil {
+					t.Fatalf("Failed to cancel reboot: %v", err)
+				}
+
+				linecard, err := s.RebootStatus(ctx, &spb.RebootStatusRequest{Subcomponents: []*pb.Path{path("Linecard0")}})
+				if err != nil {
+					t.Fatalf("RebootStatus failed: %v", err)
+				}
+				if linecard.GetActive() {
+					t.Errorf("RebootStatus(Linecard0) got active reboot after cancel")
+				}
+				fabric, err := s.RebootStatus(ctx, &spb.RebootStatusRequest{Subcomponents: []*pb.Path{path("Fabric0")}})
+				if err != nil {
+					t.Fatalf("RebootStatus failed: %v", err)
+				}
+				if !fabric.GetActive() || fabric.GetReason() != "replace optics" {
+					t.Errorf("RebootStatus(Fabric0) got %v, want active reboot for replace optics", fabric)
+				}
+				if _, err := s.RebootStatus(ctx, &spb.RebootStatusRequest{Subcomponents: []*pb.Path{path("Linecard0"), path("Fabric0")}}); status.Code(err) != codes.InvalidArgument {
+					t.Errorf("RebootStatus of two components got %v, want InvalidArgument", err)
+				}
+
+				if _, err := s.CancelReboot(ctx, &spb.CancelRebootRequest{}); err != nil {
+					t.Fatalf("Failed to cancel reboot: %v", err)
+				}
+				s.componentRebootsMu.Lock()
+				if len(s.componentReboots) != 0 {
+					t.Errorf("Expected no pending reboots, found %d", len(s.componentReboots))
+				}
+				s.componentRebootsMu.Unlock()
+			},
+		},
 		"multiple-component-reboot": {
 			fn: func(t *testing.T, s *system, ctx context.Context) {
 				req := &spb.RebootRequest{
@@ -1246,6 +1421,232 @@ func (m *mockPingServer) Context() context.Context {
 }
 
 // TestPing tests the Ping RPC functionality with comprehensive scenarios
+func TestSetPackage(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	gnmiServer, err := gnmi.New(grpcServer, "local", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ygnmi.NewClient(gnmiServer.LocalClient(), ygnmi.WithTarget("local"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	lemmingConfig := loadDefaultConfig(t)
+	ctx := context.Background()
+	bootTask := fakedevice.NewBootTimeTask(lemmingConfig)
+	if err := bootTask.Start(ctx, gnmiServer.LocalClient(), "local"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := newFileFromConfig(&configpb.FileConfig{RemoteTransfer: true, TransferTimeoutMs: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newSystem(c, lemmingConfig)
+	s.files = files
+	spb.RegisterSystemServer(grpcServer, s)
+	// The legacy system has remote transfers disabled.
+	legacyServer := grpc.NewServer()
+	legacy := newSystem(c, lemmingConfig)
+	legacy.files = newFile()
+	spb.RegisterSystemServer(legacyServer, legacy)
+	dial := func(srv *grpc.Server) spb.SystemClient {
+		lis, err := net.Listen("tcp", "localhost:0")
+		if err != nil {
+			t.Fatalf("Failed to start listener: %v", err)
+		}
+		go srv.Serve(lis)
+		t.Cleanup(srv.Stop)
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatalf("cannot dial system server: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return spb.NewSystemClient(conn)
+	}
+	client := dial(grpcServer)
+	legacyClient := dial(legacyServer)
+
+	image := bytes.Repeat([]byte("package"), 20000)
+	sum := sha256.Sum256(image)
+	imageHash := &pb.HashType{Method: pb.HashType_SHA256, Hash: sum[:]}
+	httpSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/images/os.pkg" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(image)
+	}))
+	defer httpSrv.Close()
+	sshSrv := startTransferServer(t, "admin", "secret")
+	sshSrv.files["/images/os.pkg"] = image
+	creds := &pb.Credentials{Username: "admin", Password: &pb.Credentials_Cleartext{Cleartext: "secret"}}
+
+	pkgReq := func(p *spb.Package) *spb.SetPackageRequest {
+		return &spb.SetPackageRequest{Request: &spb.SetPackageRequest_Package{Package: p}}
+	}
+	contentsReqs := func(content []byte) []*spb.SetPackageRequest {
+		var reqs []*spb.SetPackageRequest
+		for i := 0; i < len(content); i += maxChunkSize {
+			reqs = append(reqs, &spb.SetPackageRequest{Request: &spb.SetPackageRequest_Contents{Contents: content[i:min(i+maxChunkSize, len(content))]}})
+		}
+		return reqs
+	}
+	hashReq := func(h *pb.HashType) *spb.SetPackageRequest {
+		return &spb.SetPackageRequest{Request: &spb.SetPackageRequest_Hash{Hash: h}}
+	}
+	join := func(reqs ...any) []*spb.SetPackageRequest {
+		var all []*spb.SetPackageRequest
+		for _, r := range reqs {
+			switch r := r.(type) {
+			case *spb.SetPackageRequest:
+				all = append(all, r)
+			case []*spb.SetPackageRequest:
+				all = append(all, r...)
+			}
+		}
+		return all
+	}
+
+	tests := []struct {
+		desc        string
+		legacy      bool
+		reqs        []*spb.SetPackageRequest
+		wantCode    codes.Code
+		wantFile    string
+		wantVersion string
+	}{{
+		desc:     "stream",
+		reqs:     join(pkgReq(&spb.Package{Filename: "/images/stream.pkg"}), contentsReqs(image), hashReq(imageHash)),
+		wantFile: "/images/stream.pkg",
+	}, {
+		desc:        "stream-activate",
+		reqs:        join(pkgReq(&spb.Package{Filename: "/images/v2.pkg", Version: "v2", Activate: true}), contentsReqs(image), hashReq(imageHash)),
+		wantFile:    "/images/v2.pkg",
+		wantVersion: "v2",
+	}, {
+		desc: "http-download",
+		reqs: join(pkgReq(&spb.Package{
+			Filename:       "/images/http.pkg",
+			RemoteDownload: &cpb.RemoteDownload{Path: httpSrv.URL + "/images/os.pkg", Protocol: cpb.RemoteDownload_HTTP},
+		}), hashReq(imageHash)),
+		wantFile: "/images/http.pkg",
+	}, {
+		desc: "scp-download-activate",
+		reqs: join(pkgReq(&spb.Package{
+			Filename:       "/images/v3.pkg",
+			Version:        "v3",
+			Activate:       true,
+			RemoteDownload: &cpb.RemoteDownload{Path: "scp://" + sshSrv.addr + "/images/os.pkg", Protocol: cpb.RemoteDownload_SCP, Credentials: creds},
+		}), hashReq(imageHash)),
+		wantFile:    "/images/v3.pkg",
+		wantVersion: "v3",
+	}, {
+		desc: "sftp-download",
+		reqs: join(pkgReq(&spb.Package{
+			Filename:       "/images/sftp.pkg",
+			RemoteDownload: &cpb.RemoteDownload{Path: "sftp://" + sshSrv.addr + "/images/os.pkg", Protocol: cpb.RemoteDownload_SFTP, Credentials: creds},
+		}), hashReq(imageHash)),
+		wantFile: "/images/sftp.pkg",
+	}, {
+		desc: "remote-file-missing",
+		reqs: join(pkgReq(&spb.Package{
+			Filename:       "/images/missing.pkg",
+			RemoteDownload: &cpb.RemoteDownload{Path: "sftp://" + sshSrv.addr + "/images/missing.pkg", Protocol: cpb.RemoteDownload_SFTP, Credentials: creds},
+		}), hashReq(imageHash)),
+		wantCode: codes.Unavailable,
+	}, {
+		desc:   "remote-transfers-disabled",
+		legacy: true,
+		reqs: join(pkgReq(&spb.Package{
+			Filename:       "/images/http.pkg",
+			RemoteDownload: &cpb.RemoteDownload{Path: httpSrv.URL + "/images/os.pkg", Protocol: cpb.RemoteDownload_HTTP},
+		}), hashReq(imageHash)),
+		wantCode: codes.FailedPrecondition,
+	}, {
+		desc: "contents-with-remote-download",
+		reqs: join(pkgReq(&spb.Package{
+			Filename:       "/images/http.pkg",
+			RemoteDownload: &cpb.RemoteDownload{Path: httpSrv.URL + "/images/os.pkg", Protocol: cpb.RemoteDownload_HTTP},
+		}), contentsReqs(image), hashReq(imageHash)),
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "hash-mismatch",
+		reqs:     join(pkgReq(&spb.Package{Filename: "/images/bad.pkg"}), contentsReqs(image[1:]), hashReq(imageHash)),
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "no-hash",
+		reqs:     join(pkgReq(&spb.Package{Filename: "/images/bad.pkg"}), contentsReqs(image)),
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "no-package",
+		reqs:     join(contentsReqs(image), hashReq(imageHash)),
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "relative-filename",
+		reqs:     join(pkgReq(&spb.Package{Filename: "images/bad.pkg"}), contentsReqs(image), hashReq(imageHash)),
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "activate-without-version",
+		reqs:     join(pkgReq(&spb.Package{Filename: "/images/bad.pkg", Activate: true}), contentsReqs(image), hashReq(imageHash)),
+		wantCode: codes.InvalidArgument,
+	}}
+	chassisName := lemmingConfig.GetComponents().GetChassisName()
+	supervisorName := lemmingConfig.GetComponents().GetSupervisor1Name()
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cl := client
+			if tt.legacy {
+				cl = legacyClient
+			}
+			stream, err := cl.SetPackage(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, req := range tt.reqs {
+				if err := stream.Send(req); err != nil {
+					break
+				}
+			}
+			_, err = stream.CloseAndRecv()
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("SetPackage() got code %v, want %v: %v", got, tt.wantCode, err)
+			}
+			if tt.wantFile != "" {
+				info, ok := files.GetFileInfo(tt.wantFile)
+				if !ok || !bytes.Equal(info.content, image) {
+					t.Errorf("SetPackage() did not store the package in %s", tt.wantFile)
+				}
+			}
+			if _, ok := files.GetFileInfo("/images/bad.pkg"); ok {
+				t.Errorf("SetPackage() stored an invalid package")
+			}
+			if tt.wantVersion != "" {
+				for _, name := range []string{chassisName, supervisorName} {
+					got, err := ygnmi.Get(ctx, c, ocpath.Root().Component(name).SoftwareVersion().State())
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got != tt.wantVersion {
+						t.Errorf("component %s got software version %q, want %q", name, got, tt.wantVersion)
+					}
+				}
+			}
+		})
+	}
+
+	// The activated version is kept when the device boots again.
+	if err := bootTask.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := bootTask.Start(ctx, gnmiServer.LocalClient(), "local"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ygnmi.Get(ctx, c, ocpath.Root().Component(chassisName).SoftwareVersion().State()); err != nil || got != "v3" {
+		t.Errorf("chassis software version after boot got %q, %v, want %q", got, err, "v3")
+	}
+}
+
 func TestPing(t *testing.T) {
 	lemmingConfig := loadDefaultConfig(t)
 
@@ -1613,74 +2014,417 @@ func TestPing(t *testing.T) {
 	}
 }
 
-func TestLinkQualification(t *testing.T) {
-	grpcServer := grpc.NewServer()
-	gnmiServer, err := gnmi.New(grpcServer, "local", nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-	lis, err := net.Listen("tcp", "localhost:0")
-	if err != nil {
-		t.Fatalf("Failed to start listener: %v", err)
-	}
-	go func() {
-		grpcServer.Serve(lis)
-	}()
+type rebooterFunc func(ctx context.Context) error
 
-	client := gnmiServer.LocalClient()
-	c, err := ygnmi.NewClient(client, ygnmi.WithTarget("local"))
-	if err != nil {
-		t.Fatalf("cannot create ygnmi client: %v", err)
-	}
+func (f rebooterFunc) Reboot(ctx context.Context) error {
+	return f(ctx)
+}
+
+type proberFunc func(ctx context.Context, ni, intf string, req *icmp.Request) (*icmp.Reply, error)
+
+func (f proberFunc) Probe(ctx context.Context, ni, intf string, req *icmp.Request) (*icmp.Reply, error) {
+	return f(ctx, ni, intf, req)
+}
 
+func TestPingDataplane(t *testing.T) {
 	lemmingConfig := loadDefaultConfig(t)
-	linkQualServer := newLinkQualification(c, lemmingConfig)
-	ctx := context.Background()
 
-	// Create test interfaces in the system for testing
-	setupTestInterfaces(t, c)
+	type probe struct {
+		ni, intf string
+		src, dst string
+		size     int
+		df       bool
+	}
 
-	// Helper function to clean up all active qualifications
-	cleanupQualifications := func() {
-		// Get all active qualifications
-		listResp, err := linkQualServer.List(ctx, &plqpb.ListRequest{})
-		if err != nil {
-			t.Logf("Warning: List failed during cleanup: %v", err)
-			return
-		}
+	tests := []struct {
+		desc        string
+		req         *spb.PingRequest
+		replies     []*icmp.Reply // nil entries are lost probes.
+		probeErr    error
+		wantCode    codes.Code
+		wantProbe   *probe
+		wantBytes   []int32
+		wantSummary *spb.PingResponse
+	}{{
+		desc:     "hostname with do_not_resolve",
+		req:      &spb.PingRequest{Destination: "example.com", DoNotResolve: true},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "l3 protocol mismatch",
+		req:      &spb.PingRequest{Destination: "2001::1", L3Protocol: pb.L3Protocol_IPV4},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "source family mismatch",
+		req:      &spb.PingRequest{Destination: "10.0.0.1", Source: "2001::1"},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc: "echo replies",
+		req:  &spb.PingRequest{Destination: "10.0.1.1", Source: "10.0.0.1", Count: 2, Interval: 1000000, Size: 100, DoNotFragment: true, NetworkInstance: "vrf1"},
+		replies: []*icmp.Reply{
+			{Type: icmp.EchoReply, From: net.ParseIP("10.0.1.1"), TTL: 63, Bytes: 100, RTT: time.Millisecond},
+			{Type: icmp.EchoReply, From: net.ParseIP("10.0.1.1"), TTL: 63, Bytes: 100, RTT: 3 * time.Millisecond},
+		},
+		wantProbe:   &probe{ni: "vrf1", src: "10.0.0.1", dst: "10.0.1.1", size: 100, df: true},
+		wantBytes:   []int32{100, 100},
+		wantSummary: &spb.PingResponse{Source: "10.0.1.1", Sent: 2, Received: 2, MinTime: 1000000, AvgTime: 2000000, MaxTime: 3000000, StdDev: 1414214},
+	}, {
+		desc: "source interface and lost probe",
+		req:  &spb.PingRequest{Destination: "2001::1", Source: "eth0", Count: 3, Interval: 1000000, Wait: 10000000},
+		replies: []*icmp.Reply{
+			{Type: icmp.EchoReply, From: net.ParseIP("2001::1"), TTL: 64, Bytes: 56, RTT: time.Millisecond},
+			nil,
+			{Type: icmp.EchoReply, From: net.ParseIP("2001::1"), TTL: 64, Bytes: 56, RTT: time.Millisecond},
+		},
+		wantProbe:   &probe{ni: fakedevice.DefaultNetworkInstance, intf: "eth0", dst: "2001::1", size: 56},
+		wantBytes:   []int32{56, 0, 56},
+		wantSummary: &spb.PingResponse{Source: "2001::1", Sent: 3, Received: 2, MinTime: 1000000, AvgTime: 1000000, MaxTime: 1000000},
+	}, {
+		desc: "destination unreachable",
+		req:  &spb.PingRequest{Destination: "10.0.1.1", Count: 1},
+		replies: []*icmp.Reply{
+			{Type: icmp.Unreachable, From: net.ParseIP("10.0.0.2"), Code: 1},
+		},
+		wantProbe:   &probe{ni: fakedevice.DefaultNetworkInstance, dst: "10.0.1.1", size: 56},
+		wantBytes:   []int32{0},
+		wantSummary: &spb.PingResponse{Source: "10.0.1.1", Sent: 1},
+	}, {
+		desc:     "probe error",
+		req:      &spb.PingRequest{Destination: "10.0.1.1", Count: 1},
+		probeErr: fmt.Errorf("no interface with an address of the requested family"),
+		wantCode: codes.Internal,
+	}}
 
-		if len(listResp.Results) > 0 {
-			// Collect all IDs
-			var ids []string
-			for _, result := range listResp.Results {
-				ids = append(ids, result.Id)
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got *probe
+			var n int
+			s := newSystem(nil, lemmingConfig)
+			s.prober = proberFunc(func(ctx context.Context, ni, intf string, req *icmp.Request) (*icmp.Reply, error) {
+				if tt.probeErr != nil {
+					return nil, tt.probeErr
+				}
+				got = &probe{ni: ni, intf: intf, dst: req.Dst.String(), size: req.Size, df: req.DoNotFragment}
+				if req.Src != nil {
+					got.src = req.Src.String()
+				}
+				reply := tt.replies[n]
+				n++
+				if reply == nil {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+				return reply, nil
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			stream := &mockPingServer{ctx: ctx}
+			err := s.Ping(tt.req, stream)
+			if gotCode := status.Code(err); gotCode != tt.wantCode {
+				t.Fatalf("Ping() got code %v, want %v: %v", gotCode, tt.wantCode, err)
 			}
-
-			// Delete all qualifications
-			deleteResp, err := linkQualServer.Delete(ctx, &plqpb.DeleteRequest{Ids: ids})
 			if err != nil {
-				t.Logf("Warning: Delete failed during cleanup: %v", err)
-			} else {
-				for id, status := range deleteResp.Results {
-					if status.Code != int32(codes.OK) {
-						t.Logf("Warning: Failed to delete qualification %s: %s", id, status.Message)
-					}
-				}
+				return
 			}
+			if diff := cmp.Diff(tt.wantProbe, got, cmp.AllowUnexported(probe{})); diff != "" {
+				t.Errorf("Ping() unexpected probe (-want +got):\n%s", diff)
+			}
+			if len(stream.responses) != len(tt.wantBytes)+1 {
+				t.Fatalf("Ping() got %d responses, want %d", len(stream.responses), len(tt.wantBytes)+1)
+			}
+			var gotBytes []int32
+			for _, r := range stream.responses[:len(tt.wantBytes)] {
+				gotBytes = append(gotBytes, r.GetBytes())
+			}
+			if diff := cmp.Diff(tt.wantBytes, gotBytes); diff != "" {
+				t.Errorf("Ping() unexpected bytes (-want +got):\n%s", diff)
+			}
+			summary := stream.responses[len(stream.responses)-1]
+			if diff := cmp.Diff(tt.wantSummary, summary, protocmp.Transform(), protocmp.IgnoreFields(&spb.PingResponse{}, "time")); diff != "" {
+				t.Errorf("Ping() unexpected summary (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
 
-			// Wait a moment for cleanup to complete
-			time.Sleep(100 * time.Millisecond)
+type mockTracerouteServer struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*spb.TracerouteResponse
+}
+
+func (m *mockTracerouteServer) Send(response *spb.TracerouteResponse) error {
+	m.responses = append(m.responses, response)
+	return nil
+}
+
+func (m *mockTracerouteServer) Context() context.Context {
+	return m.ctx
+}
+
+type fakeRIB struct {
+	chain []string
+	found bool
+}
+
+func (r *fakeRIB) NexthopChain(string, string) ([]string, bool, error) {
+	return r.chain, r.found, nil
+}
+
+// fakeProber answers probes as the routers along path would. Hops in drop
+// never answer, and the last hop answers with reply.
+type fakeProber struct {
+	path  []string
+	drop  map[int]bool
+	reply *icmp.Reply
+}
+
+func (p *fakeProber) Probe(ctx context.Context, _, _ string, req *icmp.Request) (*icmp.Reply, error) {
+	hop := int(req.TTL)
+	if p.drop[hop] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if hop < len(p.path) {
+		return &icmp.Reply{Type: icmp.TimeExceeded, From: net.ParseIP(p.path[hop-1]), RTT: time.Duration(hop) * time.Millisecond}, nil
+	}
+	r := *p.reply
+	r.From = net.ParseIP(p.path[len(p.path)-1])
+	r.RTT = time.Duration(hop) * time.Millisecond
+	return &r, nil
+}
+
+func TestTraceroute(t *testing.T) {
+	lemmingConfig := loadDefaultConfig(t)
+
+	hops := func(responses []*spb.TracerouteResponse) []string {
+		var got []string
+		for _, r := range responses {
+			got = append(got, fmt.Sprintf("%d %s %v", r.GetHop(), r.GetAddress(), r.GetState()))
 		}
+		return got
 	}
 
 	tests := []struct {
-		name string
-		test func(t *testing.T, linkQualServer *linkQualification, ctx context.Context)
-	}{
-		{"capabilities", testCapabilities},
-		{"validation-tests", testValidation},
-		{"single-interface-qualification", testSingleInterfaceQualification},
-		{"multi-port-qualification", testMultiPortQualification},
+		desc      string
+		req       *spb.TracerouteRequest
+		rib       RIB
+		prober    Prober
+		wantCode  codes.Code
+		wantFirst *spb.TracerouteResponse
+		wantHops  []string
+	}{{
+		desc:     "missing destination",
+		req:      &spb.TracerouteRequest{},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "destination is not an address",
+		req:      &spb.TracerouteRequest{Destination: "example.com"},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "l3 protocol mismatch",
+		req:      &spb.TracerouteRequest{Destination: "2001::1", L3Protocol: pb.L3Protocol_IPV4},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "source family mismatch",
+		req:      &spb.TracerouteRequest{Destination: "8.8.8.8", Source: "2001::1"},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "initial ttl greater than max ttl",
+		req:      &spb.TracerouteRequest{Destination: "8.8.8.8", InitialTtl: 10, MaxTtl: 5},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "initial ttl out of range",
+		req:      &spb.TracerouteRequest{Destination: "8.8.8.8", InitialTtl: math.MaxUint32},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "max ttl out of range",
+		req:      &spb.TracerouteRequest{Destination: "8.8.8.8", MaxTtl: 256},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "udp probes",
+		req:      &spb.TracerouteRequest{Destination: "8.8.8.8", L4Protocol: spb.TracerouteRequest_UDP},
+		wantCode: codes.Unimplemented,
+	}, {
+		desc: "simulated without rib",
+		req:  &spb.TracerouteRequest{Destination: "8.8.8.8"},
+		wantFirst: &spb.TracerouteResponse{
+			DestinationName:    "8.8.8.8",
+			DestinationAddress: "8.8.8.8",
+			Hops:               30,
+			PacketSize:         60,
+		},
+		wantHops: []string{"1 8.8.8.8 DEFAULT", "1 8.8.8.8 DEFAULT", "1 8.8.8.8 DEFAULT"},
+	}, {
+		desc: "simulated recursive route",
+		req:  &spb.TracerouteRequest{Destination: "8.8.8.8", MaxTtl: 10},
+		rib:  &fakeRIB{chain: []string{"192.0.2.1", "10.0.0.1"}, found: true},
+		wantFirst: &spb.TracerouteResponse{
+			DestinationName:    "8.8.8.8",
+			DestinationAddress: "8.8.8.8",
+			Hops:               10,
+			PacketSize:         60,
+		},
+		wantHops: []string{
+			"1 192.0.2.1 DEFAULT", "1 192.0.2.1 DEFAULT", "1 192.0.2.1 DEFAULT",
+			"2 10.0.0.1 DEFAULT", "2 10.0.0.1 DEFAULT", "2 10.0.0.1 DEFAULT",
+			"3 8.8.8.8 DEFAULT", "3 8.8.8.8 DEFAULT", "3 8.8.8.8 DEFAULT",
+		},
+	}, {
+		desc: "simulated with initial and max ttl",
+		req:  &spb.TracerouteRequest{Destination: "2001:db8::1", InitialTtl: 2, MaxTtl: 2},
+		rib:  &fakeRIB{chain: []string{"2001::1", "2001::2"}, found: true},
+		wantFirst: &spb.TracerouteResponse{
+			DestinationName:    "2001:db8::1",
+			DestinationAddress: "2001:db8::1",
+			Hops:               2,
+			PacketSize:         80,
+		},
+		wantHops: []string{"2 2001::2 DEFAULT", "2 2001::2 DEFAULT", "2 2001::2 DEFAULT"},
+	}, {
+		desc: "simulated unroutable destination",
+		req:  &spb.TracerouteRequest{Destination: "8.8.8.8"},
+		rib:  &fakeRIB{},
+		wantFirst: &spb.TracerouteResponse{
+			DestinationName:    "8.8.8.8",
+			DestinationAddress: "8.8.8.8",
+			Hops:               30,
+			PacketSize:         60,
+		},
+		wantHops: []string{"1  NETWORK_UNREACHABLE"},
+	}, {
+		desc:   "dataplane with lost probes",
+		req:    &spb.TracerouteRequest{Destination: "8.8.8.8", Wait: 10000000},
+		prober: &fakeProber{path: []string{"192.0.2.1", "10.0.0.1", "8.8.8.8"}, drop: map[int]bool{2: true}, reply: &icmp.Reply{Type: icmp.EchoReply}},
+		wantFirst: &spb.TracerouteResponse{
+			DestinationName:    "8.8.8.8",
+			DestinationAddress: "8.8.8.8",
+			Hops:               30,
+			PacketSize:         60,
+		},
+		wantHops: []string{
+			"1 192.0.2.1 DEFAULT", "1 192.0.2.1 DEFAULT", "1 192.0.2.1 DEFAULT",
+			"2  NONE", "2  NONE", "2  NONE",
+			"3 8.8.8.8 DEFAULT", "3 8.8.8.8 DEFAULT", "3 8.8.8.8 DEFAULT",
+		},
+	}, {
+		desc:   "dataplane host unreachable",
+		req:    &spb.TracerouteRequest{Destination: "8.8.8.8"},
+		prober: &fakeProber{path: []string{"192.0.2.1", "10.0.0.1"}, reply: &icmp.Reply{Type: icmp.Unreachable, Code: 1}},
+		wantFirst: &spb.TracerouteResponse{
+			DestinationName:    "8.8.8.8",
+			DestinationAddress: "8.8.8.8",
+			Hops:               30,
+			PacketSize:         60,
+		},
+		wantHops: []string{
+			"1 192.0.2.1 DEFAULT", "1 192.0.2.1 DEFAULT", "1 192.0.2.1 DEFAULT",
+			"2 10.0.0.1 HOST_UNREACHABLE", "2 10.0.0.1 HOST_UNREACHABLE", "2 10.0.0.1 HOST_UNREACHABLE",
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s := newSystem(nil, lemmingConfig)
+			s.rib = tt.rib
+			s.prober = tt.prober
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			stream := &mockTracerouteServer{ctx: ctx}
+			err := s.Traceroute(tt.req, stream)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("Traceroute() got code %v, want %v: %v", got, tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(stream.responses) == 0 {
+				t.Fatalf("Traceroute() sent no responses")
+			}
+			if diff := cmp.Diff(tt.wantFirst, stream.responses[0], protocmp.Transform()); diff != "" {
+				t.Errorf("Traceroute() unexpected first response (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantHops, hops(stream.responses[1:])); diff != "" {
+				t.Errorf("Traceroute() unexpected hops (-want +got):\n%s", diff)
+			}
+			for _, r := range stream.responses[1:] {
+				if r.GetState() == spb.TracerouteResponse_DEFAULT && r.GetRtt() <= 0 {
+					t.Errorf("Traceroute() got non-positive RTT for hop %d", r.GetHop())
+				}
+			}
+		})
+	}
+}
+
+func TestLinkQualification(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	gnmiServer, err := gnmi.New(grpcServer, "local", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	go func() {
+		grpcServer.Serve(lis)
+	}()
+
+	client := gnmiServer.LocalClient()
+	c, err := ygnmi.NewClient(client, ygnmi.WithTarget("local"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+
+	lemmingConfig := loadDefaultConfig(t)
+	linkQualServer := newLinkQualification(c, lemmingConfig)
+	ctx := context.Background()
+
+	// Create test interfaces in the system for testing
+	setupTestInterfaces(t, c)
+
+	// Helper function to clean up all active qualifications
+	cleanupQualifications := func() {
+		// Get all active qualifications
+		listResp, err := linkQualServer.List(ctx, &plqpb.ListRequest{})
+		if err != nil {
+			t.Logf("Warning: List failed during cleanup: %v", err)
+			return
+		}
+
+		if len(listResp.Results) > 0 {
+			// Collect all IDs
+			var ids []string
+			for _, result := range listResp.Results {
+				ids = append(ids, result.Id)
+			}
+
+			// Delete all qualifications
+			deleteResp, err := linkQualServer.Delete(ctx, &plqpb.DeleteRequest{Ids: ids})
+			if err != nil {
+				t.Logf("Warning: Delete failed during cleanup: %v", err)
+			} else {
+				for id, status := range deleteResp.Results {
+					if status.Code != int32(codes.OK) {
+						t.Logf("Warning: Failed to delete qualification %s: %s", id, status.Message)
+					}
+				}
+			}
+
+			// Wait a moment for cleanup to complete
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	tests := []struct {
+		name string
+		test func(t *testing.T, linkQualServer *linkQualification, ctx context.Context)
+	}{
+		{"capabilities", testCapabilities},
+		{"validation-tests", testValidation},
+		{"single-interface-qualification", testSingleInterfaceQualification},
+		{"multi-port-qualification", testMultiPortQualification},
 		{"concurrent-qualifications", testConcurrentQualifications},
 		{"state-machine-validation", testStateMachineValidation},
 		{"packet-statistics-validation", testPacketStatisticsValidation},
@@ -1689,6 +2433,7 @@ func TestLinkQualification(t *testing.T) {
 		{"error-handling", testErrorHandling},
 		{"configuration-variations", testConfigurationVariations},
 		{"packet-injector-endpoint", testPacketInjectorEndpoint},
+		{"packet-injector-dataplane", testPacketInjectorDataplane},
 		{"ntp-timing-configuration", testNTPTimingConfiguration},
 		{"generator-reflector-coordination", testGeneratorReflectorCoordination},
 	}
@@ -1710,6 +2455,383 @@ func TestLinkQualification(t *testing.T) {
 	cleanupQualifications()
 }
 
+// fakeLabelDataplane records the label entries cleared by the MPLS service.
+type fakeLabelDataplane struct {
+	cleared      []string
+	reprogrammed []string
+}
+
+func (f *fakeLabelDataplane) ClearLabelCounters(_ context.Context, ni string, label uint32) error {
+	f.cleared = append(f.cleared, fmt.Sprintf("%s/%d", ni, label))
+	return nil
+}
+
+func (f *fakeLabelDataplane) ReprogramLabel(_ context.Context, ni string, label uint32) error {
+	if label == 300 {
+		return status.Errorf(codes.NotFound, "label %d not programmed", label)
+	}
+	f.reprogrammed = append(f.reprogrammed, fmt.Sprintf("%s/%d", ni, label))
+	return nil
+}
+
+func TestMPLS(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	gnmiServer, err := gnmi.New(grpcServer, "local", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+	c, err := ygnmi.NewClient(gnmiServer.LocalClient(), ygnmi.WithTarget("local"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	// The local client writes state, so configure the LSPs over gRPC.
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial gNMI server: %v", err)
+	}
+	defer conn.Close()
+	configClient, err := ygnmi.NewClient(gpb.NewGNMIClient(conn), ygnmi.WithTarget("local"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	ctx := context.Background()
+
+	ni := fakedevice.DefaultNetworkInstance
+	lsps := map[string]*oc.NetworkInstance_Mpls_Lsps_StaticLsp{
+		"lsp1": {
+			Name:    ygot.String("lsp1"),
+			Transit: &oc.NetworkInstance_Mpls_Lsps_StaticLsp_Transit{IncomingLabel: oc.UnionUint32(100)},
+			Egress:  &oc.NetworkInstance_Mpls_Lsps_StaticLsp_Egress{IncomingLabel: oc.UnionUint32(200)},
+		},
+		"lsp2": {
+			Name:    ygot.String("lsp2"),
+			Transit: &oc.NetworkInstance_Mpls_Lsps_StaticLsp_Transit{IncomingLabel: oc.UnionUint32(300)},
+		},
+	}
+	for name, lsp := range lsps {
+		if _, err := ygnmi.Replace(ctx, configClient, ocpath.Root().NetworkInstance(ni).Mpls().Lsps().StaticLsp(name).Config(), lsp); err != nil {
+			t.Fatalf("failed to configure LSP %q: %v", name, err)
+		}
+	}
+	entryPath := func(label uint32) ygnmi.SingletonQuery[*oc.NetworkInstance_Afts_LabelEntry] {
+		return ocpath.Root().NetworkInstance(ni).Afts().LabelEntry(oc.UnionUint32(label)).State()
+	}
+	programEntries := func(t *testing.T) {
+		t.Helper()
+		for _, label := range []uint32{100, 200, 300} {
+			entry := &oc.NetworkInstance_Afts_LabelEntry{
+				Label:        oc.UnionUint32(label),
+				NextHopGroup: ygot.Uint64(1),
+				Counters: &oc.NetworkInstance_Afts_LabelEntry_Counters{
+					OctetsForwarded:  ygot.Uint64(1000),
+					PacketsForwarded: ygot.Uint64(10),
+				},
+			}
+			if _, err := gnmiclient.Replace(ctx, c, entryPath(label), entry); err != nil {
+				t.Fatalf("failed to program label entry %d: %v", label, err)
+			}
+		}
+	}
+	// checkCounters verifies whether the counters of the label entries were
+	// cleared no earlier than start.
+	checkCounters := func(t *testing.T, start time.Time, wantCleared map[uint32]bool) {
+		t.Helper()
+		for label, cleared := range wantCleared {
+			v, err := ygnmi.Lookup(ctx, c, entryPath(label))
+			if err != nil {
+				t.Fatalf("failed to read label entry %d: %v", label, err)
+			}
+			entry, ok := v.Val()
+			if !ok {
+				t.Fatalf("label entry %d not present", label)
+			}
+			if got := entry.GetCounters().GetPacketsForwarded() == 0 && entry.GetCounters().GetOctetsForwarded() == 0; got != cleared {
+				t.Errorf("label entry %d counters cleared: got %v, want %v", label, got, cleared)
+			}
+			if cleared && v.Timestamp.Before(start) {
+				t.Errorf("label entry %d cleared at %v, want no earlier than %v", label, v.Timestamp, start)
+			}
+			if entry.GetNextHopGroup() != 1 {
+				t.Errorf("label entry %d next-hop-group: got %d, want 1", label, entry.GetNextHopGroup())
+			}
+		}
+	}
+
+	t.Run("ClearLSPCounters", func(t *testing.T) {
+		tests := []struct {
+			desc        string
+			req         *mpb.ClearLSPCountersRequest
+			wantCode    codes.Code
+			wantCleared map[uint32]bool
+			wantLabels  []string
+		}{{
+			desc:        "lsp",
+			req:         &mpb.ClearLSPCountersRequest{Name: "lsp1"},
+			wantCleared: map[uint32]bool{100: true, 200: true, 300: false},
+			wantLabels:  []string{"DEFAULT/100", "DEFAULT/200"},
+		}, {
+			desc:     "unknown lsp",
+			req:      &mpb.ClearLSPCountersRequest{Name: "lsp3"},
+			wantCode: codes.NotFound,
+		}, {
+			desc:     "no name",
+			req:      &mpb.ClearLSPCountersRequest{},
+			wantCode: codes.InvalidArgument,
+		}}
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				programEntries(t)
+				dp := &fakeLabelDataplane{}
+				m := newMPLS(c)
+				m.labels = dp
+				start := time.Now()
+				_, err := m.ClearLSPCounters(ctx, tt.req)
+				if got := status.Code(err); got != tt.wantCode {
+					t.Fatalf("ClearLSPCounters() got code %v, want %v: %v", got, tt.wantCode, err)
+				}
+				if diff := cmp.Diff(tt.wantLabels, dp.cleared); diff != "" {
+					t.Errorf("ClearLSPCounters() cleared labels diff (-want +got):\n%s", diff)
+				}
+				checkCounters(t, start, tt.wantCleared)
+			})
+		}
+	})
+
+	t.Run("ClearLSP", func(t *testing.T) {
+		tests := []struct {
+			desc        string
+			req         *mpb.ClearLSPRequest
+			wantCode    codes.Code
+			wantCleared map[uint32]bool
+			wantLabels  []string
+		}{{
+			desc:        "lsp",
+			req:         &mpb.ClearLSPRequest{Name: "lsp1", Mode: mpb.ClearLSPRequest_AGGRESSIVE},
+			wantCleared: map[uint32]bool{100: true, 200: true, 300: false},
+			wantLabels:  []string{"DEFAULT/100", "DEFAULT/200"},
+		}, {
+			desc:        "reset all lsps",
+			req:         &mpb.ClearLSPRequest{Mode: mpb.ClearLSPRequest_RESET},
+			wantCleared: map[uint32]bool{100: true, 200: true, 300: true},
+			wantLabels:  []string{"DEFAULT/100", "DEFAULT/200"},
+		}, {
+			desc:     "unknown lsp",
+			req:      &mpb.ClearLSPRequest{Name: "lsp3"},
+			wantCode: codes.NotFound,
+		}, {
+			desc:     "no name",
+			req:      &mpb.ClearLSPRequest{},
+			wantCode: codes.InvalidArgument,
+		}, {
+			desc:     "auto-bandwidth",
+			req:      &mpb.ClearLSPRequest{Name: "lsp1", Mode: mpb.ClearLSPRequest_AUTOBW_AGGRESSIVE},
+			wantCode: codes.Unimplemented,
+		}}
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				programEntries(t)
+				dp := &fakeLabelDataplane{}
+				m := newMPLS(c)
+				m.labels = dp
+				start := time.Now()
+				_, err := m.ClearLSP(ctx, tt.req)
+				if got := status.Code(err); got != tt.wantCode {
+					t.Fatalf("ClearLSP() got code %v, want %v: %v", got, tt.wantCode, err)
+				}
+				if diff := cmp.Diff(tt.wantLabels, dp.reprogrammed); diff != "" {
+					t.Errorf("ClearLSP() reprogrammed labels diff (-want +got):\n%s", diff)
+				}
+				checkCounters(t, start, tt.wantCleared)
+			})
+		}
+	})
+}
+
+func TestDiag(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	gnmiServer, err := gnmi.New(grpcServer, "local", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	go func() {
+		grpcServer.Serve(lis)
+	}()
+	defer grpcServer.GracefulStop()
+
+	c, err := ygnmi.NewClient(gnmiServer.LocalClient(), ygnmi.WithTarget("local"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	setupTestInterfaces(t, c)
+
+	lemmingConfig := loadDefaultConfig(t)
+	lemmingConfig.Diag = &configpb.DiagConfig{
+		MinBertDurationSecs: 1,
+		MaxBertDurationSecs: 60,
+		DefaultErrorProfile: &configpb.BertErrorProfile{},
+		ErrorProfile: []*configpb.BertErrorProfile{
+			{Interface: "eth1", ErrorsPerMinute: 6000},
+			{Interface: "eth2", PeerLockFailure: true},
+		},
+	}
+	d := newDiag(c, lemmingConfig)
+	ctx := context.Background()
+
+	intfPath := func(name string) *pb.Path {
+		return &pb.Path{Elem: []*pb.PathElem{{Name: "interfaces"}, {Name: "interface", Key: map[string]string{"name": name}}}}
+	}
+	startReq := func(opID string, secs uint32, names ...string) *diagpb.StartBERTRequest {
+		req := &diagpb.StartBERTRequest{BertOperationId: opID}
+		for _, name := range names {
+			req.PerPortRequests = append(req.PerPortRequests, &diagpb.StartBERTRequest_PerPortRequest{
+				Interface:          intfPath(name),
+				PrbsPolynomial:     diagpb.PrbsPolynomial_PRBS_POLYNOMIAL_PRBS31,
+				TestDurationInSecs: secs,
+			})
+		}
+		return req
+	}
+	awaitOperStatus := func(t *testing.T, name string, want oc.E_Interface_OperStatus) {
+		t.Helper()
+		awaitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if _, err := ygnmi.Await(awaitCtx, c, ocpath.Root().Interface(name).OperStatus().State(), want); err != nil {
+			t.Fatalf("interface %s did not reach oper-status %v: %v", name, want, err)
+		}
+	}
+	statuses := func(responses []*diagpb.StartBERTResponse_PerPortResponse) []diagpb.BertStatus {
+		var got []diagpb.BertStatus
+		for _, r := range responses {
+			got = append(got, r.GetStatus())
+		}
+		return got
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		for _, req := range []*diagpb.StartBERTRequest{
+			{PerPortRequests: startReq("", 1, "eth0").GetPerPortRequests()},
+			{BertOperationId: "op"},
+		} {
+			if _, err := d.StartBERT(ctx, req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("StartBERT(%v) got error %v, want InvalidArgument", req, err)
+			}
+		}
+		req := startReq("op", 1, "eth0", "eth9")
+		req.PerPortRequests[0].PrbsPolynomial = diagpb.PrbsPolynomial_PRBS_POLYNOMIAL_UNKNOWN
+		req.PerPortRequests = append(req.PerPortRequests, startReq("op", 61, "eth1").GetPerPortRequests()...)
+		if _, err := d.StartBERT(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("StartBERT() with no valid ports got error %v, want InvalidArgument", err)
+		}
+	})
+
+	t.Run("start and stop", func(t *testing.T) {
+		resp, err := d.StartBERT(ctx, startReq("op1", 30, "eth0", "eth9"))
+		if err != nil {
+			t.Fatalf("StartBERT() unexpected error: %v", err)
+		}
+		want := []diagpb.BertStatus{diagpb.BertStatus_BERT_STATUS_OK, diagpb.BertStatus_BERT_STATUS_NON_EXISTENT_PORT}
+		if diff := cmp.Diff(want, statuses(resp.GetPerPortResponses())); diff != "" {
+			t.Errorf("StartBERT() unexpected statuses (-want +got):\n%s", diff)
+		}
+		awaitOperStatus(t, "eth0", oc.Interface_OperStatus_TESTING)
+
+		if _, err := d.StartBERT(ctx, startReq("op2", 1, "eth0")); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("StartBERT() on busy port got error %v, want FailedPrecondition", err)
+		}
+		if _, err := d.StartBERT(ctx, startReq("op1", 1, "eth5")); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("StartBERT() reusing running operation id on another port got error %v, want InvalidArgument", err)
+		}
+
+		result, err := d.GetBERTResult(ctx, &diagpb.GetBERTResultRequest{
+			BertOperationId: "op1",
+			PerPortRequests: []*diagpb.GetBERTResultRequest_PerPortRequest{{Interface: intfPath("eth0")}},
+		})
+		if err != nil {
+			t.Fatalf("GetBERTResult() unexpected error: %v", err)
+		}
+		got := result.GetPerPortResponses()[0]
+		if got.GetStatus() != diagpb.BertStatus_BERT_STATUS_OK || got.GetBertOperationId() != "op1" || got.GetPrbsPolynomial() != diagpb.PrbsPolynomial_PRBS_POLYNOMIAL_PRBS31 {
+			t.Errorf("GetBERTResult() got %v, want OK result for op1", got)
+		}
+		if got.GetLastBertGetResultTimestamp() < got.GetLastBertStartTimestamp() {
+			t.Errorf("GetBERTResult() got result timestamp %d before start timestamp %d", got.GetLastBertGetResultTimestamp(), got.GetLastBertStartTimestamp())
+		}
+
+		stopReq := &diagpb.StopBERTRequest{
+			BertOperationId: "op2",
+			PerPortRequests: []*diagpb.StopBERTRequest_PerPortRequest{{Interface: intfPath("eth0")}},
+		}
+		if _, err := d.StopBERT(ctx, stopReq); status.Code(err) != codes.NotFound {
+			t.Errorf("StopBERT() with wrong operation id got error %v, want NotFound", err)
+		}
+		stopReq.BertOperationId = "op1"
+		if _, err := d.StopBERT(ctx, stopReq); err != nil {
+			t.Fatalf("StopBERT() unexpected error: %v", err)
+		}
+		awaitOperStatus(t, "eth0", oc.Interface_OperStatus_UP)
+		// Stopping a stopped BERT is not an error.
+		if _, err := d.StopBERT(ctx, stopReq); err != nil {
+			t.Errorf("StopBERT() on stopped BERT unexpected error: %v", err)
+		}
+
+		if _, err := d.StartBERT(ctx, startReq("op1", 1, "eth0")); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("StartBERT() reusing operation id got error %v, want InvalidArgument", err)
+		}
+	})
+
+	t.Run("error profiles", func(t *testing.T) {
+		if _, err := d.StartBERT(ctx, startReq("op3", 1, "eth1", "eth2")); err != nil {
+			t.Fatalf("StartBERT() unexpected error: %v", err)
+		}
+		awaitOperStatus(t, "eth1", oc.Interface_OperStatus_TESTING)
+		awaitOperStatus(t, "eth1", oc.Interface_OperStatus_UP)
+		awaitOperStatus(t, "eth2", oc.Interface_OperStatus_UP)
+
+		result, err := d.GetBERTResult(ctx, &diagpb.GetBERTResultRequest{
+			BertOperationId: "op3",
+			PerPortRequests: []*diagpb.GetBERTResultRequest_PerPortRequest{{Interface: intfPath("eth1")}, {Interface: intfPath("eth2")}},
+		})
+		if err != nil {
+			t.Fatalf("GetBERTResult() unexpected error: %v", err)
+		}
+		eth1, eth2 := result.GetPerPortResponses()[0], result.GetPerPortResponses()[1]
+		if eth1.GetStatus() != diagpb.BertStatus_BERT_STATUS_OK || !eth1.GetPeerLockEstablished() {
+			t.Errorf("GetBERTResult() for eth1 got %v, want OK with peer lock", eth1)
+		}
+		if eth1.GetTotalErrors() == 0 {
+			t.Errorf("GetBERTResult() for eth1 got no errors, want errors from profile")
+		}
+		if len(eth1.GetErrorCountPerMinute()) != 1 || uint64(eth1.GetErrorCountPerMinute()[0]) != eth1.GetTotalErrors() {
+			t.Errorf("GetBERTResult() for eth1 got per-minute errors %v, want [%d]", eth1.GetErrorCountPerMinute(), eth1.GetTotalErrors())
+		}
+		if eth2.GetStatus() != diagpb.BertStatus_BERT_STATUS_PEER_LOCK_FAILURE || eth2.GetPeerLockEstablished() {
+			t.Errorf("GetBERTResult() for eth2 got %v, want peer lock failure", eth2)
+		}
+
+		all, err := d.GetBERTResult(ctx, &diagpb.GetBERTResultRequest{ResultFromAllPorts: true})
+		if err != nil {
+			t.Fatalf("GetBERTResult() unexpected error: %v", err)
+		}
+		var gotOps []string
+		for _, r := range all.GetPerPortResponses() {
+			gotOps = append(gotOps, r.GetBertOperationId())
+		}
+		if diff := cmp.Diff([]string{"op1", "op3", "op3"}, gotOps); diff != "" {
+			t.Errorf("GetBERTResult() for all ports unexpected operations (-want +got):\n%s", diff)
+		}
+	})
+}
+
 // setupTestInterfaces creates test interfaces for qualification testing
 func setupTestInterfaces(t *testing.T, c *ygnmi.Client) {
 	ctx := context.Background()
@@ -2768,26 +3890,157 @@ func testPacketInjectorEndpoint(t *testing.T, linkQualServer *linkQualification,
 	t.Logf("PacketInjector correctly returned UNIMPLEMENTED: %s", createResp.Status[qualID].Message)
 }
 
-func testNTPTimingConfiguration(t *testing.T, linkQualServer *linkQualification, ctx context.Context) {
-	// Test NTP timing configuration returns UNIMPLEMENTED
-	qualID := "ntp-timing-test"
-	now := time.Now()
-	startTime := timestamppb.New(now.Add(1 * time.Second))
-	endTime := timestamppb.New(now.Add(4 * time.Second))
+// fakeEndpoint is a link qualification endpoint reporting fixed statistics.
+type fakeEndpoint struct {
+	sent, received, errored uint64
+	stopped                 atomic.Bool
+}
 
-	createReq := &plqpb.CreateRequest{
-		Interfaces: []*plqpb.QualificationConfiguration{
-			{
-				Id:            qualID,
-				InterfaceName: "eth4",
-				EndpointType: &plqpb.QualificationConfiguration_PacketGenerator{
-					PacketGenerator: &plqpb.PacketGeneratorConfiguration{
-						PacketRate: 5000,
-						PacketSize: 1000,
-					},
-				},
-				Timing: &plqpb.QualificationConfiguration_Ntp{
-					Ntp: &plqpb.NTPSyncedTiming{
+func (e *fakeEndpoint) Stats() (uint64, uint64, uint64) {
+	return e.sent, e.received, e.errored
+}
+
+func (e *fakeEndpoint) Stop() error {
+	e.stopped.Store(true)
+	return nil
+}
+
+// fakeLinkTester starts the same endpoint for all the qualifications.
+type fakeLinkTester struct {
+	endpoint *fakeEndpoint
+	count    uint64
+	size     int
+}
+
+func (lt *fakeLinkTester) StartGenerator(string, uint64, int) (linkqual.Endpoint, error) {
+	return lt.endpoint, nil
+}
+
+func (lt *fakeLinkTester) StartInjector(_ string, count uint64, size int, _ time.Duration) (linkqual.Endpoint, error) {
+	lt.count, lt.size = count, size
+	return lt.endpoint, nil
+}
+
+func (lt *fakeLinkTester) StartReflector(string) (linkqual.Endpoint, error) {
+	return lt.endpoint, nil
+}
+
+func testPacketInjectorDataplane(t *testing.T, linkQualServer *linkQualification, ctx context.Context) {
+	tester := &fakeLinkTester{endpoint: &fakeEndpoint{sent: 5000, received: 4990, errored: 2}}
+	linkQualServer.tester = tester
+	defer func() { linkQualServer.tester = nil }()
+
+	capResp, err := linkQualServer.Capabilities(ctx, &plqpb.CapabilitiesRequest{})
+	if err != nil {
+		t.Fatalf("Capabilities failed: %v", err)
+	}
+	if got, want := capResp.GetGenerator().GetPacketInjector().GetMaxInjectedPackets(), linkQualServer.config.GetLinkQualification().GetMaxInjectedPackets(); got != want {
+		t.Errorf("PacketInjector max injected packets got %d, want %d", got, want)
+	}
+	if got, want := capResp.GetGenerator().GetPacketGenerator().GetMaxPps(), linkQualServer.config.GetLinkQualification().GetMaxDataplanePps(); got != want {
+		t.Errorf("PacketGenerator max pps got %d, want %d", got, want)
+	}
+
+	injector := func(id string, count uint32) *plqpb.QualificationConfiguration {
+		return &plqpb.QualificationConfiguration{
+			Id:            id,
+			InterfaceName: "eth3",
+			EndpointType: &plqpb.QualificationConfiguration_PacketInjector{
+				PacketInjector: &plqpb.PacketInjectorConfiguration{
+					PacketCount: count,
+					PacketSize:  1200,
+					LoopbackMode: &plqpb.PacketInjectorConfiguration_PmdLoopback{
+						PmdLoopback: &plqpb.PmdLoopbackConfiguration{},
+					},
+				},
+			},
+			Timing: &plqpb.QualificationConfiguration_Rpc{
+				Rpc: &plqpb.RPCSyncedTiming{
+					SetupDuration:    durationpb.New(1 * time.Second),
+					Duration:         durationpb.New(2 * time.Second),
+					TeardownDuration: durationpb.New(1 * time.Second),
+				},
+			},
+		}
+	}
+
+	tooMany := injector("packet-injector-too-many", linkQualServer.config.GetLinkQualification().GetMaxInjectedPackets()+1)
+	// The test lasts 2s, so twice the maximum rate plus one is too fast.
+	tooFast := injector("packet-injector-too-fast", uint32(2*linkQualServer.config.GetLinkQualification().GetMaxDataplanePps()+1))
+	tooFast.InterfaceName = "eth4"
+	createResp, err := linkQualServer.Create(ctx, &plqpb.CreateRequest{Interfaces: []*plqpb.QualificationConfiguration{tooMany, tooFast}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if got := createResp.Status[tooMany.Id].Code; got != int32(codes.InvalidArgument) {
+		t.Errorf("Create with too many packets got code %d, want %d", got, codes.InvalidArgument)
+	}
+	if got := createResp.Status[tooFast.Id].Code; got != int32(codes.InvalidArgument) {
+		t.Errorf("Create with too high a rate got code %d, want %d", got, codes.InvalidArgument)
+	}
+
+	qualID := "packet-injector-dataplane-test"
+	createResp, err = linkQualServer.Create(ctx, &plqpb.CreateRequest{Interfaces: []*plqpb.QualificationConfiguration{injector(qualID, 5000)}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if createResp.Status[qualID].Code != int32(codes.OK) {
+		t.Fatalf("Expected OK status, got %d: %s", createResp.Status[qualID].Code, createResp.Status[qualID].Message)
+	}
+
+	var finalResult *plqpb.QualificationResult
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		getResp, err := linkQualServer.Get(ctx, &plqpb.GetRequest{Ids: []string{qualID}})
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		result := getResp.Results[qualID]
+		if result.State == plqpb.QualificationState_QUALIFICATION_STATE_ERROR {
+			t.Fatalf("Qualification failed with error state")
+		}
+		if result.State == plqpb.QualificationState_QUALIFICATION_STATE_COMPLETED {
+			finalResult = result
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	if finalResult == nil {
+		t.Fatalf("Qualification did not complete")
+	}
+
+	if tester.count != 5000 || tester.size != 1200 {
+		t.Errorf("StartInjector got count %d and size %d, want 5000 and 1200", tester.count, tester.size)
+	}
+	if !tester.endpoint.stopped.Load() {
+		t.Errorf("Endpoint was not stopped")
+	}
+	if finalResult.PacketsSent != 5000 || finalResult.PacketsReceived != 4990 || finalResult.PacketsDropped != 10 || finalResult.PacketsError != 2 {
+		t.Errorf("Got sent %d, received %d, dropped %d, errored %d, want 5000, 4990, 10, 2",
+			finalResult.PacketsSent, finalResult.PacketsReceived, finalResult.PacketsDropped, finalResult.PacketsError)
+	}
+}
+
+func testNTPTimingConfiguration(t *testing.T, linkQualServer *linkQualification, ctx context.Context) {
+	// Test NTP timing configuration returns UNIMPLEMENTED
+	qualID := "ntp-timing-test"
+	now := time.Now()
+	startTime := timestamppb.New(now.Add(1 * time.Second))
+	endTime := timestamppb.New(now.Add(4 * time.Second))
+
+	createReq := &plqpb.CreateRequest{
+		Interfaces: []*plqpb.QualificationConfiguration{
+			{
+				Id:            qualID,
+				InterfaceName: "eth4",
+				EndpointType: &plqpb.QualificationConfiguration_PacketGenerator{
+					PacketGenerator: &plqpb.PacketGeneratorConfiguration{
+						PacketRate: 5000,
+						PacketSize: 1000,
+					},
+				},
+				Timing: &plqpb.QualificationConfiguration_Ntp{
+					Ntp: &plqpb.NTPSyncedTiming{
 						StartTime: startTime,
 						EndTime:   endTime,
 					},
@@ -3306,6 +4559,22 @@ func TestFile_Stat(t *testing.T) {
 			wantErr:       "",
 			expectedCount: 1,
 		},
+		{
+			name: "stat /home directory",
+			request: &fpb.StatRequest{
+				Path: "/home",
+			},
+			wantErr:       "",
+			expectedCount: 1,
+		},
+		{
+			name: "stat file",
+			request: &fpb.StatRequest{
+				Path: "/tmp/test2.txt",
+			},
+			wantErr:       "",
+			expectedCount: 1,
+		},
 		{
 			name: "stat empty directory",
 			request: &fpb.StatRequest{
@@ -3351,7 +4620,7 @@ func TestFile_Stat(t *testing.T) {
 				if stat.Path == "" {
 					t.Fatalf("Empty path in stat result")
 				}
-				if stat.Size == 0 && len(f.files[stat.Path].content) > 0 {
+				if file, ok := f.files[stat.Path]; ok && stat.Size != uint64(len(file.content)) {
 					t.Fatalf("Size mismatch for %s", stat.Path)
 				}
 				if stat.Umask != defaultUmask {
@@ -3617,6 +4886,375 @@ func TestFile_TransferToRemote(t *testing.T) {
 	}
 }
 
+// transferServer is an SSH server that accepts SCP and SFTP transfers of the
+// files it records.
+type transferServer struct {
+	addr string
+
+	mu    sync.Mutex
+	files map[string][]byte
+	modes map[string]string
+}
+
+func (ts *transferServer) file(p string) []byte {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.files[p]
+}
+
+func startTransferServer(t *testing.T, user, password string) *transferServer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() != user || string(pass) != password {
+				return nil, fmt.Errorf("invalid credentials for %s", c.User())
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+	ts := &transferServer{addr: lis.Addr().String(), files: map[string][]byte{}, modes: map[string]string{}}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go ts.serve(conn, config)
+		}
+	}()
+	return ts
+}
+
+func (ts *transferServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			nc.Reject(ssh.UnknownChannelType, "unsupported channel")
+			continue
+		}
+		ch, chReqs, err := nc.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer ch.Close()
+			for req := range chReqs {
+				var payload struct{ Value string }
+				if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+					req.Reply(false, nil)
+					continue
+				}
+				switch {
+				case req.Type == "exec" && strings.HasPrefix(payload.Value, "scp -t "):
+					req.Reply(true, nil)
+					target := strings.Trim(strings.TrimPrefix(payload.Value, "scp -t "), "'")
+					ts.scpSink(ch, target)
+				case req.Type == "exec" && strings.HasPrefix(payload.Value, "scp -f "):
+					req.Reply(true, nil)
+					target := strings.Trim(strings.TrimPrefix(payload.Value, "scp -f "), "'")
+					ts.scpSource(ch, target)
+				case req.Type == "subsystem" && payload.Value == "sftp":
+					req.Reply(true, nil)
+					ts.sftpServe(ch)
+				default:
+					req.Reply(false, nil)
+					continue
+				}
+				ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+				return
+			}
+		}()
+	}
+}
+
+func (ts *transferServer) scpSink(ch ssh.Channel, target string) {
+	r := bufio.NewReader(ch)
+	ch.Write([]byte{0})
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return
+	}
+	var mode string
+	var size int
+	var name string
+	if _, err := fmt.Sscanf(header, "C%s %d %s", &mode, &size, &name); err != nil {
+		fmt.Fprintf(ch, "\x01invalid header %q\n", header)
+		return
+	}
+	ch.Write([]byte{0})
+	content := make([]byte, size+1)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return
+	}
+	ts.mu.Lock()
+	ts.files[target] = content[:size]
+	ts.modes[target] = mode
+	ts.mu.Unlock()
+	ch.Write([]byte{0})
+}
+
+func (ts *transferServer) scpSource(ch ssh.Channel, target string) {
+	r := bufio.NewReader(ch)
+	if _, err := r.ReadByte(); err != nil {
+		return
+	}
+	content := ts.file(target)
+	if content == nil {
+		fmt.Fprintf(ch, "\x01scp: %s: No such file or directory\n", target)
+		return
+	}
+	fmt.Fprintf(ch, "C0644 %d %s\n", len(content), filepath.Base(target))
+	if _, err := r.ReadByte(); err != nil {
+		return
+	}
+	ch.Write(content)
+	ch.Write([]byte{0})
+	r.ReadByte()
+}
+
+func (ts *transferServer) sftpServe(ch ssh.Channel) {
+	sc := &sftpClient{w: ch, r: ch}
+	var target string
+	var content []byte
+	var reading bool
+	status := func(id []byte, code uint32) error {
+		return sc.send(sftpStatus, sftpString(binary.BigEndian.AppendUint32(id, code), "status"))
+	}
+	for {
+		typ, payload, err := sc.recv()
+		if err != nil {
+			return
+		}
+		if typ == sftpInit {
+			sc.send(sftpVersion, []byte{0, 0, 0, 3})
+			continue
+		}
+		id, data := payload[:4:4], payload[4:]
+		switch typ {
+		case sftpOpen:
+			var rest []byte
+			target, rest, _ = sftpReadString(data)
+			if reading = binary.BigEndian.Uint32(rest)&sftpReadFlag != 0; reading {
+				if content = ts.file(target); content == nil {
+					status(id, 2) // No such file.
+					continue
+				}
+			}
+			sc.send(sftpHandle, sftpString(id, "handle"))
+		case sftpRead:
+			_, rest, _ := sftpReadString(data)
+			off := binary.BigEndian.Uint64(rest)
+			if off >= uint64(len(content)) {
+				status(id, sftpStatusEOF)
+				continue
+			}
+			n := min(uint64(binary.BigEndian.Uint32(rest[8:])), uint64(len(content))-off)
+			sc.send(sftpData, sftpString(id, string(content[off:off+n])))
+		case sftpWrite:
+			_, rest, _ := sftpReadString(data)
+			off := binary.BigEndian.Uint64(rest)
+			chunk, _, _ := sftpReadString(rest[8:])
+			if end := int(off) + len(chunk); end > len(content) {
+				content = append(content, make([]byte, end-len(content))...)
+			}
+			copy(content[off:], chunk)
+			status(id, sftpStatusOK)
+		case sftpClose:
+			if !reading {
+				ts.mu.Lock()
+				ts.files[target] = content
+				ts.mu.Unlock()
+			}
+			status(id, sftpStatusOK)
+			return
+		}
+	}
+}
+
+func TestFile_TransferToRemoteUpload(t *testing.T) {
+	content := bytes.Repeat([]byte("lemming"), 10000)
+	f, err := newFileFromConfig(&configpb.FileConfig{RemoteTransfer: true, TransferTimeoutMs: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.write("/tmp/image.bin", content, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	var httpGot []byte
+	var httpUser string
+	httpSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/upload/image.bin" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		httpUser, _, _ = r.BasicAuth()
+		httpGot, _ = io.ReadAll(r.Body)
+	}))
+	defer httpSrv.Close()
+	sshSrv := startTransferServer(t, "admin", "secret")
+	creds := &pb.Credentials{Username: "admin", Password: &pb.Credentials_Cleartext{Cleartext: "secret"}}
+
+	tests := []struct {
+		desc     string
+		rd       *cpb.RemoteDownload
+		wantCode codes.Code
+		got      func() []byte
+	}{{
+		desc: "http",
+		rd: &cpb.RemoteDownload{
+			Path:        httpSrv.URL + "/upload/image.bin",
+			Protocol:    cpb.RemoteDownload_HTTP,
+			Credentials: creds,
+		},
+		got: func() []byte { return httpGot },
+	}, {
+		desc: "http-error",
+		rd: &cpb.RemoteDownload{
+			Path:     httpSrv.URL + "/missing",
+			Protocol: cpb.RemoteDownload_HTTP,
+		},
+		wantCode: codes.Unavailable,
+	}, {
+		desc: "scp",
+		rd: &cpb.RemoteDownload{
+			Path:        "scp://" + sshSrv.addr + "/scp/image.bin",
+			Protocol:    cpb.RemoteDownload_SCP,
+			Credentials: creds,
+		},
+		got: func() []byte { return sshSrv.file("/scp/image.bin") },
+	}, {
+		desc: "sftp",
+		rd: &cpb.RemoteDownload{
+			Path:        "sftp://admin@" + sshSrv.addr + "/sftp/image.bin",
+			Protocol:    cpb.RemoteDownload_SFTP,
+			Credentials: &pb.Credentials{Password: &pb.Credentials_Cleartext{Cleartext: "secret"}},
+		},
+		got: func() []byte { return sshSrv.file("/sftp/image.bin") },
+	}, {
+		desc: "sftp-wrong-password",
+		rd: &cpb.RemoteDownload{
+			Path:        "sftp://" + sshSrv.addr + "/sftp/other.bin",
+			Protocol:    cpb.RemoteDownload_SFTP,
+			Credentials: &pb.Credentials{Username: "admin", Password: &pb.Credentials_Cleartext{Cleartext: "wrong"}},
+		},
+		wantCode: codes.Unavailable,
+	}, {
+		desc: "scp-no-user",
+		rd: &cpb.RemoteDownload{
+			Path:     sshSrv.addr + ":/scp/image.bin",
+			Protocol: cpb.RemoteDownload_SCP,
+		},
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc: "hashed-credentials",
+		rd: &cpb.RemoteDownload{
+			Path:     sshSrv.addr + ":/scp/image.bin",
+			Protocol: cpb.RemoteDownload_SCP,
+			Credentials: &pb.Credentials{
+				Username: "admin",
+				Password: &pb.Credentials_Hashed{Hashed: &pb.HashType{Method: pb.HashType_SHA256, Hash: []byte("hash")}},
+			},
+		},
+		wantCode: codes.InvalidArgument,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			resp, err := f.TransferToRemote(context.Background(), &fpb.TransferToRemoteRequest{
+				LocalPath:      "/tmp/image.bin",
+				RemoteDownload: tt.rd,
+			})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("TransferToRemote() got code %v, want %v: %v", got, tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if want := md5.Sum(content); !bytes.Equal(resp.GetHash().GetHash(), want[:]) {
+				t.Errorf("TransferToRemote() got hash %x, want %x", resp.GetHash().GetHash(), want)
+			}
+			if got := tt.got(); !bytes.Equal(got, content) {
+				t.Errorf("TransferToRemote() uploaded %d bytes, want %d", len(got), len(content))
+			}
+		})
+	}
+	if httpUser != "admin" {
+		t.Errorf("HTTP upload got user %q, want %q", httpUser, "admin")
+	}
+	sshSrv.mu.Lock()
+	defer sshSrv.mu.Unlock()
+	if got, want := sshSrv.modes["/scp/image.bin"], "0640"; got != want {
+		t.Errorf("SCP upload got mode %q, want %q", got, want)
+	}
+}
+
+func TestFile_RootDir(t *testing.T) {
+	root := t.TempDir()
+	cfg := &configpb.FileConfig{RootDir: root}
+	f, err := newFileFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.write("/images/a.bin", []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.write("/images/old/b.bin", []byte("bb"), 0o400); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.write("/c.txt", []byte("ccc"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Remove(context.Background(), &fpb.RemoveRequest{RemoteFile: "/c.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := goos.Stat(filepath.Join(root, "c.txt")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("removed file still on disk: %v", err)
+	}
+
+	// A new file service loads the files left in the root directory.
+	reloaded, err := newFileFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := reloaded.Stat(context.Background(), &fpb.StatRequest{Path: "/images"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, st := range resp.GetStats() {
+		got = append(got, fmt.Sprintf("%s %o %d", st.GetPath(), st.GetPermissions(), st.GetSize()))
+	}
+	want := []string{"/images/a.bin 644 1", "/images/old 755 0"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Stat(/images) after reload diff (-want +got):\n%s", diff)
+	}
+	if b := reloaded.files["/images/old/b.bin"]; b == nil || string(b.content) != "bb" {
+		t.Errorf("reloaded /images/old/b.bin = %v, want content %q", b, "bb")
+	}
+	if _, ok := reloaded.files["/c.txt"]; ok {
+		t.Errorf("removed file /c.txt was reloaded")
+	}
+}
+
 func TestFile_ComputeHash(t *testing.T) {
 	f := newFile()
 	testData := []byte("hello world")
@@ -3842,3 +5480,255 @@ func TestFile_HelperMethods(t *testing.T) {
 		t.Fatalf("Expected 0 files after reset, got %d", len(files))
 	}
 }
+
+func TestContainerz(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	gnmiServer, err := gnmi.New(grpcServer, "local", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ygnmi.NewClient(gnmiServer.LocalClient(), ygnmi.WithTarget("local"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	files := newFile()
+	czpb.RegisterContainerzServer(grpcServer, newContainerz(c, files))
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("cannot dial containerz server: %v", err)
+	}
+	defer conn.Close()
+	client := czpb.NewContainerzClient(conn)
+	ctx := context.Background()
+
+	image := bytes.Repeat([]byte("layer"), 30000)
+	t.Run("deploy", func(t *testing.T) {
+		stream, err := client.Deploy(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := stream.Send(&czpb.DeployRequest{Request: &czpb.DeployRequest_ImageTransfer{
+			ImageTransfer: &czpb.ImageTransfer{Name: "agent", Tag: "v1", ImageSize: uint64(len(image))},
+		}}); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunkSize := int(resp.GetImageTransferReady().GetChunkSize())
+		if chunkSize == 0 {
+			t.Fatalf("Deploy got %v, want image transfer ready", resp)
+		}
+		for i := 0; i < len(image); i += chunkSize {
+			if err := stream.Send(&czpb.DeployRequest{Request: &czpb.DeployRequest_Content{Content: image[i:min(i+chunkSize, len(image))]}}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := stream.Recv(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := stream.Send(&czpb.DeployRequest{Request: &czpb.DeployRequest_ImageTransferEnd{ImageTransferEnd: &czpb.ImageTransferEnd{}}}); err != nil {
+			t.Fatal(err)
+		}
+		resp, err = stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &czpb.ImageTransferSuccess{Name: "agent", Tag: "v1", ImageSize: uint64(len(image))}
+		if diff := cmp.Diff(want, resp.GetImageTransferSuccess(), protocmp.Transform()); diff != "" {
+			t.Errorf("Deploy got diff (-want +got):\n%s", diff)
+		}
+		if info, ok := files.GetFileInfo("/containerz/images/agent/v1"); !ok || !bytes.Equal(info.content, image) {
+			t.Errorf("image not stored in the file system")
+		}
+	})
+
+	t.Run("deploy-invalid-name", func(t *testing.T) {
+		for _, transfer := range []*czpb.ImageTransfer{
+			{Name: "../../etc/passwd"},
+			{Name: "/etc/passwd"},
+			{Name: "agent", Tag: "../v1"},
+		} {
+			stream, err := client.Deploy(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := stream.Send(&czpb.DeployRequest{Request: &czpb.DeployRequest_ImageTransfer{ImageTransfer: transfer}}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Deploy(%v) got %v, want InvalidArgument", transfer, err)
+			}
+		}
+	})
+
+	t.Run("volumes", func(t *testing.T) {
+		if _, err := client.CreateVolume(ctx, &czpb.CreateVolumeRequest{Name: "data", Labels: map[string]string{"app": "agent"}}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.CreateVolume(ctx, &czpb.CreateVolumeRequest{Name: "data"}); status.Code(err) != codes.AlreadyExists {
+			t.Errorf("CreateVolume of existing volume got %v, want AlreadyExists", err)
+		}
+		stream, err := client.ListVolume(ctx, &czpb.ListVolumeRequest{Filter: []*czpb.ListVolumeRequest_Filter{{Key: "label", Value: []string{"app=agent"}}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.GetName() != "data" || resp.GetDriver() != "local" {
+			t.Errorf("ListVolume got %v, want local volume data", resp)
+		}
+	})
+
+	var pid uint64
+	t.Run("start", func(t *testing.T) {
+		resp, err := client.StartContainer(ctx, &czpb.StartContainerRequest{
+			ImageName:    "agent",
+			Tag:          "v1",
+			Cmd:          "/agent --serve",
+			InstanceName: "agent-1",
+			Ports:        []*czpb.StartContainerRequest_Port{{Internal: 8080, External: 9090}},
+			Volumes:      []*czpb.Volume{{Name: "data", MountPoint: "/data"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.GetStartOk().GetInstanceName(); got != "agent-1" {
+			t.Fatalf("StartContainer got %v, want agent-1 started", resp)
+		}
+		procs, err := ygnmi.GetAll(ctx, c, ocpath.Root().System().ProcessAny().State())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range procs {
+			if p.GetName() == "agent-1" {
+				pid = p.GetPid()
+			}
+		}
+		if pid == 0 {
+			t.Errorf("container process not found")
+		}
+
+		if _, err := client.StartContainer(ctx, &czpb.StartContainerRequest{ImageName: "agent", Tag: "v1", InstanceName: "agent-1"}); status.Code(err) != codes.AlreadyExists {
+			t.Errorf("StartContainer of existing instance got %v, want AlreadyExists", err)
+		}
+		resp, err = client.StartContainer(ctx, &czpb.StartContainerRequest{ImageName: "agent", Tag: "v1", Ports: []*czpb.StartContainerRequest_Port{{Internal: 9090}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.GetStartError().GetErrorCode(); got != czpb.StartError_PORT_USED {
+			t.Errorf("StartContainer on used port got %v, want PORT_USED", resp)
+		}
+		resp, err = client.StartContainer(ctx, &czpb.StartContainerRequest{ImageName: "agent", Tag: "v2"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.GetStartError().GetErrorCode(); got != czpb.StartError_NOT_FOUND {
+			t.Errorf("StartContainer of missing image got %v, want NOT_FOUND", resp)
+		}
+		if _, err := client.RemoveVolume(ctx, &czpb.RemoveVolumeRequest{Name: "data"}); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("RemoveVolume of used volume got %v, want FailedPrecondition", err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		stream, err := client.ListContainer(ctx, &czpb.ListContainerRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.GetName() != "agent-1" || resp.GetImageName() != "agent" || resp.GetStatus() != czpb.ListContainerResponse_RUNNING {
+			t.Errorf("ListContainer got %v, want running agent-1", resp)
+		}
+		if _, err := stream.Recv(); err != io.EOF {
+			t.Errorf("ListContainer got more containers, err: %v", err)
+		}
+	})
+
+	t.Run("log-and-stop", func(t *testing.T) {
+		logCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		stream, err := client.Log(logCtx, &czpb.LogRequest{InstanceName: "agent-1", Follow: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lines []string
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines = append(lines, resp.GetMsg())
+			if strings.Contains(resp.GetMsg(), "running for") {
+				break
+			}
+		}
+		if !strings.Contains(strings.Join(lines, "\n"), `Running command "/agent --serve"`) {
+			t.Errorf("Log got %q, want start of the command", lines)
+		}
+
+		resp, err := client.StopContainer(ctx, &czpb.StopContainerRequest{InstanceName: "agent-1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.GetCode() != czpb.StopContainerResponse_SUCCESS {
+			t.Errorf("StopContainer got %v, want SUCCESS", resp)
+		}
+		var last string
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			last = resp.GetMsg()
+		}
+		if !strings.HasSuffix(last, "Container agent-1 stopped") {
+			t.Errorf("Log got last line %q, want container stopped", last)
+		}
+		if _, err := ygnmi.Get(ctx, c, ocpath.Root().System().Process(pid).State()); err == nil {
+			t.Errorf("container process still present after stop")
+		}
+		if _, err := client.StopContainer(ctx, &czpb.StopContainerRequest{InstanceName: "agent-1"}); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("StopContainer of stopped container got %v, want FailedPrecondition", err)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		resp, err := client.RemoveContainer(ctx, &czpb.RemoveContainerRequest{Name: "agent", Tag: "v1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.GetCode() != czpb.RemoveContainerResponse_SUCCESS {
+			t.Errorf("RemoveContainer got %v, want SUCCESS", resp)
+		}
+		if _, ok := files.GetFileInfo("/containerz/images/agent/v1"); ok {
+			t.Errorf("image still stored after RemoveContainer")
+		}
+		resp, err = client.RemoveContainer(ctx, &czpb.RemoveContainerRequest{Name: "agent", Tag: "v1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.GetCode() != czpb.RemoveContainerResponse_NOT_FOUND {
+			t.Errorf("RemoveContainer of removed image got %v, want NOT_FOUND", resp)
+		}
+		if _, err := client.RemoveVolume(ctx, &czpb.RemoveVolumeRequest{Name: "data"}); err != nil {
+			t.Errorf("RemoveVolume got unexpected error: %v", err)
+		}
+	})
+}
@@ -25,6 +25,7 @@ import (
 
 	log "github.com/golang/glog"
 	plqpb "github.com/openconfig/gnoi/packet_link_qualification"
+	"github.com/openconfig/lemming/dataplane/protocol/linkqual"
 	"github.com/openconfig/lemming/gnmi/fakedevice"
 	"github.com/openconfig/lemming/gnmi/oc/ocpath"
 	configpb "github.com/openconfig/lemming/proto/config"
@@ -41,6 +42,8 @@ type linkQualification struct {
 
 	c      *ygnmi.Client
 	config *configpb.Config
+	// tester sends real test packets through the dataplane, if set.
+	tester LinkTester
 
 	// Protect the qualification state tracking and historical results
 	mu sync.RWMutex
@@ -93,20 +96,43 @@ func newLinkQualification(c *ygnmi.Client, config *configpb.Config) *linkQualifi
 func (lq *linkQualification) Capabilities(ctx context.Context, req *plqpb.CapabilitiesRequest) (*plqpb.CapabilitiesResponse, error) {
 	log.Infof("Received LinkQualification Capabilities request")
 
+	maxPps := lq.config.GetLinkQualification().GetMaxPps()
+	var injector *plqpb.PacketInjectorCapabilities
+	if lq.tester != nil {
+		if dpPps := lq.config.GetLinkQualification().GetMaxDataplanePps(); dpPps != 0 && (maxPps == 0 || dpPps < maxPps) {
+			maxPps = dpPps
+		}
+		injector = &plqpb.PacketInjectorCapabilities{
+			MinMtu:              lq.config.GetLinkQualification().GetMinMtu(),
+			MaxMtu:              lq.config.GetLinkQualification().GetMaxMtu(),
+			MinInjectedPackets:  1,
+			MaxInjectedPackets:  lq.config.GetLinkQualification().GetMaxInjectedPackets(),
+			MinSetupDuration:    durationpb.New(time.Duration(lq.config.GetLinkQualification().GetMinSetupDurationMs()) * time.Millisecond),
+			MinTeardownDuration: durationpb.New(time.Duration(lq.config.GetLinkQualification().GetMinTeardownDurationMs()) * time.Millisecond),
+			MinSampleInterval:   durationpb.New(time.Duration(lq.config.GetLinkQualification().GetMinSampleIntervalMs()) * time.Millisecond),
+			LoopbackModes: []plqpb.PacketInjectorLoopbackMode{
+				plqpb.PacketInjectorLoopbackMode_PACKET_INJECTOR_LOOPBACK_MODE_PMD,
+				plqpb.PacketInjectorLoopbackMode_PACKET_INJECTOR_LOOPBACK_MODE_ASIC,
+			},
+		}
+	}
+
 	return &plqpb.CapabilitiesResponse{
 		Time:      timestamppb.Now(),
 		NtpSynced: true,
 		Generator: &plqpb.GeneratorCapabilities{
 			PacketGenerator: &plqpb.PacketGeneratorCapabilities{
 				MaxBps:              lq.config.GetLinkQualification().GetMaxBps(),
-				MaxPps:              lq.config.GetLinkQualification().GetMaxPps(),
+				MaxPps:              maxPps,
 				MinMtu:              lq.config.GetLinkQualification().GetMinMtu(),
 				MaxMtu:              lq.config.GetLinkQualification().GetMaxMtu(),
 				MinSetupDuration:    durationpb.New(time.Duration(lq.config.GetLinkQualification().GetMinSetupDurationMs()) * time.Millisecond),
 				MinTeardownDuration: durationpb.New(time.Duration(lq.config.GetLinkQualification().GetMinTeardownDurationMs()) * time.Millisecond),
 				MinSampleInterval:   durationpb.New(time.Duration(lq.config.GetLinkQualification().GetMinSampleIntervalMs()) * time.Millisecond),
 			},
-			// PacketInjector intentionally omitted - unimplemented in simulation
+			// PacketInjector requires real packets, it is only advertised
+			// when the dataplane is enabled.
+			PacketInjector: injector,
 		},
 		Reflector: &plqpb.ReflectorCapabilities{
 			AsicLoopback: &plqpb.AsicLoopbackCapabilities{
@@ -443,8 +469,27 @@ func (lq *linkQualification) validateQualificationConfig(config *plqpb.Qualifica
 			return status.Errorf(codes.InvalidArgument, "packet rate must be greater than 0")
 		}
 	case *plqpb.QualificationConfiguration_PacketInjector:
-		// PacketInjector is not implemented in simulation
-		return status.Errorf(codes.Unimplemented, "PacketInjector endpoint type is not implemented in simulation")
+		// PacketInjector needs real packets looped back by the dataplane
+		if lq.tester == nil {
+			return status.Errorf(codes.Unimplemented, "PacketInjector endpoint type is not implemented in simulation")
+		}
+		pi := et.PacketInjector
+		if pi.GetLoopbackMode() == nil {
+			return status.Errorf(codes.InvalidArgument, "loopback mode is required")
+		}
+		if pi.GetPacketCount() == 0 {
+			return status.Errorf(codes.InvalidArgument, "packet count must be greater than 0")
+		}
+		if limit := lq.config.GetLinkQualification().GetMaxInjectedPackets(); limit != 0 && pi.GetPacketCount() > limit {
+			return status.Errorf(codes.InvalidArgument, "packet count %d exceeds maximum %d", pi.GetPacketCount(), limit)
+		}
+		// Injected packets are spread over the test duration, so their rate
+		// is bounded like the generators' one.
+		if limit := lq.config.GetLinkQualification().GetMaxDataplanePps(); limit != 0 {
+			if rate := float64(pi.GetPacketCount()) / config.GetRpc().GetDuration().AsDuration().Seconds(); rate > float64(limit) {
+				return status.Errorf(codes.InvalidArgument, "injecting %d packets in %v exceeds maximum rate of %d pps", pi.GetPacketCount(), config.GetRpc().GetDuration().AsDuration(), limit)
+			}
+		}
 	case *plqpb.QualificationConfiguration_AsicLoopback:
 		// ASIC loopback is valid with any non-nil configuration
 	case *plqpb.QualificationConfiguration_PmdLoopback:
@@ -458,7 +503,7 @@ func (lq *linkQualification) validateQualificationConfig(config *plqpb.Qualifica
 
 // createQualificationState creates a qualification state from config
 func (lq *linkQualification) createQualificationState(config *plqpb.QualificationConfiguration) *QualificationState {
-	isGenerator := config.GetPacketGenerator() != nil
+	isGenerator := config.GetPacketGenerator() != nil || config.GetPacketInjector() != nil
 	isReflector := config.GetAsicLoopback() != nil || config.GetPmdLoopback() != nil
 
 	state := &QualificationState{
@@ -479,6 +524,47 @@ func (lq *linkQualification) createQualificationState(config *plqpb.Qualificatio
 	return state
 }
 
+// endpoint returns the function starting the dataplane endpoint of the
+// qualification, or nil if the qualification is simulated.
+func (lq *linkQualification) endpoint(config *plqpb.QualificationConfiguration) func() (linkqual.Endpoint, error) {
+	if lq.tester == nil {
+		return nil
+	}
+	lqConfig := lq.config.GetLinkQualification()
+	size := int(lqConfig.GetDefaultPacketSize())
+	intf := config.GetInterfaceName()
+
+	switch et := config.GetEndpointType().(type) {
+	case *plqpb.QualificationConfiguration_PacketGenerator:
+		rate := et.PacketGenerator.GetPacketRate()
+		if limit := lqConfig.GetMaxDataplanePps(); limit != 0 && rate > limit {
+			log.Warningf("Link qualification %s: packet rate %d pps exceeds dataplane maximum, sending at %d pps", config.GetId(), rate, limit)
+			rate = limit
+		}
+		if et.PacketGenerator.GetPacketSize() > 0 {
+			size = int(et.PacketGenerator.GetPacketSize())
+		}
+		return func() (linkqual.Endpoint, error) {
+			return lq.tester.StartGenerator(intf, rate, size)
+		}
+	case *plqpb.QualificationConfiguration_PacketInjector:
+		if et.PacketInjector.GetPacketSize() > 0 {
+			size = int(et.PacketInjector.GetPacketSize())
+		}
+		count := uint64(et.PacketInjector.GetPacketCount())
+		duration := config.GetRpc().GetDuration().AsDuration()
+		return func() (linkqual.Endpoint, error) {
+			return lq.tester.StartInjector(intf, count, size, duration)
+		}
+	case *plqpb.QualificationConfiguration_AsicLoopback, *plqpb.QualificationConfiguration_PmdLoopback:
+		return func() (linkqual.Endpoint, error) {
+			return lq.tester.StartReflector(intf)
+		}
+	default:
+		return nil
+	}
+}
+
 // executeQualification runs a single qualification by calling fakedevice simulation
 func (lq *linkQualification) executeQualification(ctx context.Context, qual *QualificationState) {
 	// Create cancellable context for the simulation
@@ -525,7 +611,7 @@ func (lq *linkQualification) executeQualification(ctx context.Context, qual *Qua
 
 	// Run the simulation with the callback.
 	log.Infof("Starting RunPacketLinkQualification for %s", qual.ID)
-	if err := fakedevice.RunPacketLinkQualification(qualCtx, lq.c, qual.Config, updateCallback, lq.config); err != nil {
+	if err := fakedevice.RunPacketLinkQualification(qualCtx, lq.c, qual.Config, updateCallback, lq.config, lq.endpoint(qual.Config)); err != nil {
 		log.Errorf("Link qualification simulation failed for %s: %v", qual.ID, err)
 		// Mark qualification as failed
 		qual.mu.Lock()
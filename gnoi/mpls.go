@@ -0,0 +1,156 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoi
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	log "github.com/golang/glog"
+	mpb "github.com/openconfig/gnoi/mpls"
+	"github.com/openconfig/lemming/gnmi/fakedevice"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mpls struct {
+	mpb.UnimplementedMPLSServer
+
+	c *ygnmi.Client
+	// labels clears and reprograms label entries in the dataplane, if set.
+	labels LabelDataplane
+}
+
+func newMPLS(c *ygnmi.Client) *mpls {
+	return &mpls{c: c}
+}
+
+// lsp is a static LSP and the incoming labels of its ingress, transit and
+// egress entries.
+type lsp struct {
+	ni     string
+	name   string
+	labels []uint32
+}
+
+// staticLSPs returns the configured static LSPs, ordered by network instance
+// and name. If name is non-empty, only the LSPs with that name are returned.
+func (m *mpls) staticLSPs(ctx context.Context, name string) ([]*lsp, error) {
+	v, err := ygnmi.Lookup(ctx, m.c, ocpath.Root().NetworkInstanceMap().Config())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read network instances: %v", err)
+	}
+	nis, _ := v.Val()
+	var lsps []*lsp
+	for niName, ni := range nis {
+		for lspName, static := range ni.GetMpls().GetLsps().StaticLsp {
+			if name != "" && lspName != name {
+				continue
+			}
+			l := &lsp{ni: niName, name: lspName}
+			if u, ok := static.GetIngress().GetIncomingLabel().(oc.UnionUint32); ok {
+				l.labels = append(l.labels, uint32(u))
+			}
+			if u, ok := static.GetTransit().GetIncomingLabel().(oc.UnionUint32); ok {
+				l.labels = append(l.labels, uint32(u))
+			}
+			if u, ok := static.GetEgress().GetIncomingLabel().(oc.UnionUint32); ok {
+				l.labels = append(l.labels, uint32(u))
+			}
+			slices.Sort(l.labels)
+			l.labels = slices.Compact(l.labels)
+			lsps = append(lsps, l)
+		}
+	}
+	sort.Slice(lsps, func(i, j int) bool {
+		if lsps[i].ni != lsps[j].ni {
+			return lsps[i].ni < lsps[j].ni
+		}
+		return lsps[i].name < lsps[j].name
+	})
+	return lsps, nil
+}
+
+// ClearLSP tears down the label entries of the named static LSP and programs
+// them again. The RESET mode re-signals every static LSP of the device.
+func (m *mpls) ClearLSP(ctx context.Context, req *mpb.ClearLSPRequest) (*mpb.ClearLSPResponse, error) {
+	name := req.GetName()
+	switch req.GetMode() {
+	case mpb.ClearLSPRequest_AUTOBW_AGGRESSIVE, mpb.ClearLSPRequest_AUTOBW_NONAGGRESSIVE:
+		return nil, status.Errorf(codes.Unimplemented, "auto-bandwidth is not supported on static LSPs")
+	case mpb.ClearLSPRequest_RESET:
+		name = ""
+	default:
+		if name == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "LSP name must be specified")
+		}
+	}
+	lsps, err := m.staticLSPs(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" && len(lsps) == 0 {
+		return nil, status.Errorf(codes.NotFound, "LSP %q not found", name)
+	}
+	resignalTime := time.Now().UnixNano()
+	for _, l := range lsps {
+		log.Infof("Re-signaling LSP %q in network instance %q with labels %v", l.name, l.ni, l.labels)
+		if m.labels != nil {
+			for _, label := range l.labels {
+				if err := m.labels.ReprogramLabel(ctx, l.ni, label); err != nil && status.Code(err) != codes.NotFound {
+					return nil, status.Errorf(codes.Internal, "failed to reprogram label %d of LSP %q: %v", label, l.name, err)
+				}
+			}
+		}
+		if err := fakedevice.ResignalLabelEntries(ctx, m.c, l.ni, l.labels, resignalTime); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to re-signal LSP %q: %v", l.name, err)
+		}
+	}
+	return &mpb.ClearLSPResponse{}, nil
+}
+
+// ClearLSPCounters zeroes the counters of the label entries of the named
+// static LSP.
+func (m *mpls) ClearLSPCounters(ctx context.Context, req *mpb.ClearLSPCountersRequest) (*mpb.ClearLSPCountersResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "LSP name must be specified")
+	}
+	lsps, err := m.staticLSPs(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if len(lsps) == 0 {
+		return nil, status.Errorf(codes.NotFound, "LSP %q not found", req.GetName())
+	}
+	clearTime := time.Now().UnixNano()
+	for _, l := range lsps {
+		if m.labels != nil {
+			for _, label := range l.labels {
+				if err := m.labels.ClearLabelCounters(ctx, l.ni, label); err != nil && status.Code(err) != codes.NotFound {
+					return nil, status.Errorf(codes.Internal, "failed to clear counters of label %d of LSP %q: %v", label, l.name, err)
+				}
+			}
+		}
+		if err := fakedevice.ClearLabelEntryCounters(ctx, m.c, l.ni, l.labels, clearTime); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to clear counters of LSP %q: %v", l.name, err)
+		}
+	}
+	return &mpb.ClearLSPCountersResponse{}, nil
+}
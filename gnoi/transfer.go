@@ -0,0 +1,611 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cpb "github.com/openconfig/gnoi/common"
+)
+
+const (
+	// defaultSSHPort is the port of SCP and SFTP servers when the remote
+	// path does not have one.
+	defaultSSHPort = "22"
+	// sftpChunkSize is the maximum amount of data sent in an SFTP write.
+	sftpChunkSize = 32 * 1024
+)
+
+// remoteTarget is a remote file that files are transferred to.
+type remoteTarget struct {
+	protocol cpb.RemoteDownload_Protocol
+	// url is the address of the file for HTTP(S).
+	url *url.URL
+	// addr is the host:port of the SSH server and path the file on it, for
+	// SCP and SFTP.
+	addr string
+	path string
+
+	user     string
+	password string
+	// localAddr is the address the connection is sourced from, if any.
+	localAddr net.Addr
+}
+
+// parseRemoteDownload returns the remote file described by rd. The path is
+// either a URL, or for SCP and SFTP of the form [user@]host:path.
+func parseRemoteDownload(rd *cpb.RemoteDownload) (*remoteTarget, error) {
+	t := &remoteTarget{protocol: rd.GetProtocol()}
+	if creds := rd.GetCredentials(); creds != nil {
+		if creds.GetHashed() != nil {
+			return nil, status.Error(codes.InvalidArgument, "hashed passwords cannot be used to authenticate a transfer")
+		}
+		t.user = creds.GetUsername()
+		t.password = creds.GetCleartext()
+	}
+	if src := rd.GetSourceAddress(); src != "" {
+		ip := net.ParseIP(src)
+		if ip == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid source address %q", src)
+		}
+		t.localAddr = &net.TCPAddr{IP: ip}
+	}
+
+	switch rd.GetProtocol() {
+	case cpb.RemoteDownload_HTTP, cpb.RemoteDownload_HTTPS:
+		scheme := "http"
+		if rd.GetProtocol() == cpb.RemoteDownload_HTTPS {
+			scheme = "https"
+		}
+		raw := rd.GetPath()
+		if !strings.Contains(raw, "://") {
+			raw = scheme + "://" + raw
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid remote path %q: %v", rd.GetPath(), err)
+		}
+		if u.Scheme != scheme || u.Host == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "remote path %q is not an %s URL", rd.GetPath(), scheme)
+		}
+		if t.user == "" && u.User != nil {
+			t.user = u.User.Username()
+			t.password, _ = u.User.Password()
+		}
+		u.User = nil
+		t.url = u
+	case cpb.RemoteDownload_SCP, cpb.RemoteDownload_SFTP:
+		host, user, filePath, err := splitSSHPath(rd.GetPath())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid remote path %q: %v", rd.GetPath(), err)
+		}
+		if t.user == "" {
+			t.user = user
+		}
+		if t.user == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "a username is required to transfer to %q", rd.GetPath())
+		}
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, defaultSSHPort)
+		}
+		t.addr = host
+		t.path = filePath
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported protocol: %v", rd.GetProtocol())
+	}
+	return t, nil
+}
+
+// splitSSHPath returns the host, the user and the file path of a remote path
+// of the form scheme://[user@]host[:port]/path or [user@]host:path.
+func splitSSHPath(p string) (string, string, string, error) {
+	if strings.Contains(p, "://") {
+		u, err := url.Parse(p)
+		if err != nil {
+			return "", "", "", err
+		}
+		if u.Host == "" || u.Path == "" {
+			return "", "", "", fmt.Errorf("missing host or path")
+		}
+		return u.Host, u.User.Username(), u.Path, nil
+	}
+	var user string
+	if i := strings.LastIndex(p, "@"); i >= 0 {
+		user, p = p[:i], p[i+1:]
+	}
+	host, filePath, ok := strings.Cut(p, ":")
+	if !ok || host == "" || filePath == "" {
+		return "", "", "", fmt.Errorf("want [user@]host:path")
+	}
+	return host, user, filePath, nil
+}
+
+// upload writes content with the permissions to the remote file.
+func (t *remoteTarget) upload(ctx context.Context, content []byte, permissions uint32) error {
+	switch t.protocol {
+	case cpb.RemoteDownload_HTTP, cpb.RemoteDownload_HTTPS:
+		return t.httpUpload(ctx, content)
+	case cpb.RemoteDownload_SCP:
+		return t.withSSH(ctx, func(c *ssh.Client) error { return scpUpload(c, t.path, content, permissions) })
+	case cpb.RemoteDownload_SFTP:
+		return t.withSSH(ctx, func(c *ssh.Client) error { return sftpUpload(c, t.path, content) })
+	default:
+		return fmt.Errorf("unsupported protocol: %v", t.protocol)
+	}
+}
+
+// download reads the content of the remote file, which must not be larger
+// than maxFileSize.
+func (t *remoteTarget) download(ctx context.Context) ([]byte, error) {
+	switch t.protocol {
+	case cpb.RemoteDownload_HTTP, cpb.RemoteDownload_HTTPS:
+		return t.httpDownload(ctx)
+	case cpb.RemoteDownload_SCP, cpb.RemoteDownload_SFTP:
+		var content []byte
+		err := t.withSSH(ctx, func(c *ssh.Client) error {
+			var err error
+			if t.protocol == cpb.RemoteDownload_SCP {
+				content, err = scpDownload(c, t.path)
+			} else {
+				content, err = sftpDownload(c, t.path)
+			}
+			return err
+		})
+		return content, err
+	default:
+		return nil, fmt.Errorf("unsupported protocol: %v", t.protocol)
+	}
+}
+
+// httpUpload sends content in the body of a PUT request to the URL.
+func (t *remoteTarget) httpUpload(ctx context.Context, content []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, t.url.String(), bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	if t.user != "" {
+		req.SetBasicAuth(t.user, t.password)
+	}
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: (&net.Dialer{LocalAddr: t.localAddr}).DialContext,
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("PUT %s: %s", t.url.Redacted(), resp.Status)
+	}
+	return nil
+}
+
+// httpDownload reads the body of a GET request to the URL.
+func (t *remoteTarget) httpDownload(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if t.user != "" {
+		req.SetBasicAuth(t.user, t.password)
+	}
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: (&net.Dialer{LocalAddr: t.localAddr}).DialContext,
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GET %s: %s", t.url.Redacted(), resp.Status)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxFileSize {
+		return nil, fmt.Errorf("GET %s: file exceeds maximum size of %d bytes", t.url.Redacted(), maxFileSize)
+	}
+	return content, nil
+}
+
+// withSSH calls fn with an SSH client connected to the server. The server's
+// host key is not verified, as the device has no known hosts.
+func (t *remoteTarget) withSSH(ctx context.Context, fn func(*ssh.Client) error) error {
+	conn, err := (&net.Dialer{LocalAddr: t.localAddr}).DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return err
+	}
+	// Abort the transfer if the context is done.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, t.addr, &ssh.ClientConfig{
+		User:            t.user,
+		Auth:            []ssh.AuthMethod{ssh.Password(t.password)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec // The device has no known hosts.
+	})
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+	if err := fn(client); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// scpUpload copies content to filePath using the sink side of the SCP
+// protocol run by the server.
+func scpUpload(c *ssh.Client, filePath string, content []byte, permissions uint32) error {
+	session, err := c.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := session.Start("scp -t " + shellQuote(filePath)); err != nil {
+		return err
+	}
+	r := bufio.NewReader(stdout)
+	if err := scpAck(r); err != nil {
+		return err
+	}
+	if permissions == 0 {
+		permissions = 0o644
+	}
+	if _, err := fmt.Fprintf(stdin, "C%04o %d %s\n", permissions&0o7777, len(content), path.Base(filePath)); err != nil {
+		return err
+	}
+	if err := scpAck(r); err != nil {
+		return err
+	}
+	if _, err := stdin.Write(content); err != nil {
+		return err
+	}
+	if _, err := stdin.Write([]byte{0}); err != nil {
+		return err
+	}
+	if err := scpAck(r); err != nil {
+		return err
+	}
+	stdin.Close()
+	return session.Wait()
+}
+
+// scpDownload copies filePath from the source side of the SCP protocol run
+// by the server.
+func scpDownload(c *ssh.Client, filePath string) ([]byte, error) {
+	session, err := c.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := session.Start("scp -f " + shellQuote(filePath)); err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(stdout)
+	if _, err := stdin.Write([]byte{0}); err != nil {
+		return nil, err
+	}
+	// The server either sends the file header, or an error.
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(header, "C") {
+		return nil, fmt.Errorf("scp: %s", strings.TrimSpace(strings.TrimLeft(header, "\x01\x02")))
+	}
+	var mode uint32
+	var size int64
+	var name string
+	if _, err := fmt.Sscanf(header, "C%o %d %s", &mode, &size, &name); err != nil {
+		return nil, fmt.Errorf("scp: invalid header %q: %v", header, err)
+	}
+	if size > maxFileSize {
+		return nil, fmt.Errorf("scp: file exceeds maximum size of %d bytes", maxFileSize)
+	}
+	if _, err := stdin.Write([]byte{0}); err != nil {
+		return nil, err
+	}
+	content := make([]byte, size)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+	if err := scpAck(r); err != nil {
+		return nil, err
+	}
+	if _, err := stdin.Write([]byte{0}); err != nil {
+		return nil, err
+	}
+	stdin.Close()
+	return content, session.Wait()
+}
+
+// scpAck reads the response of the SCP server to the last message.
+func scpAck(r *bufio.Reader) error {
+	code, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if code == 0 {
+		return nil
+	}
+	msg, _ := r.ReadString('\n')
+	return fmt.Errorf("scp: %s", strings.TrimSpace(msg))
+}
+
+// shellQuote quotes s as a single argument of a POSIX shell command.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// SFTP version 3 packet types and flags, from draft-ietf-secsh-filexfer-02.
+const (
+	sftpInit    = 1
+	sftpVersion = 2
+	sftpOpen    = 3
+	sftpClose   = 4
+	sftpRead    = 5
+	sftpWrite   = 6
+	sftpStatus  = 101
+	sftpHandle  = 102
+	sftpData    = 103
+
+	sftpReadFlag   = 0x01
+	sftpWriteFlag  = 0x02
+	sftpCreateFlag = 0x08
+	sftpTruncFlag  = 0x10
+
+	sftpStatusOK  = 0
+	sftpStatusEOF = 1
+)
+
+// sftpClient is a minimal SFTP client that can only read and write whole
+// files.
+type sftpClient struct {
+	w     io.Writer
+	r     io.Reader
+	reqID uint32
+}
+
+// sftpSession starts the SFTP subsystem of the server, and calls fn with a
+// client of it.
+func sftpSession(c *ssh.Client, fn func(*sftpClient) error) error {
+	session, err := c.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	w, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return err
+	}
+	sc := &sftpClient{w: w, r: r}
+	if err := sc.send(sftpInit, binary.BigEndian.AppendUint32(nil, 3)); err != nil {
+		return err
+	}
+	if typ, _, err := sc.recv(); err != nil {
+		return err
+	} else if typ != sftpVersion {
+		return fmt.Errorf("sftp: unexpected packet type %d in response to init", typ)
+	}
+	return fn(sc)
+}
+
+// open opens filePath with the flags and returns its handle.
+func (sc *sftpClient) open(filePath string, flags uint32) (string, error) {
+	open := sftpString(nil, filePath)
+	open = binary.BigEndian.AppendUint32(open, flags)
+	open = binary.BigEndian.AppendUint32(open, 0) // No attributes.
+	typ, data, err := sc.request(sftpOpen, open)
+	if err != nil {
+		return "", err
+	}
+	if typ != sftpHandle {
+		return "", sftpStatusError(typ, data)
+	}
+	handle, _, err := sftpReadString(data)
+	return handle, err
+}
+
+// sftpUpload writes content to filePath over the SFTP subsystem of the
+// server.
+func sftpUpload(c *ssh.Client, filePath string, content []byte) error {
+	return sftpSession(c, func(sc *sftpClient) error {
+		handle, err := sc.open(filePath, sftpWriteFlag|sftpCreateFlag|sftpTruncFlag)
+		if err != nil {
+			return err
+		}
+		for off := 0; off < len(content); off += sftpChunkSize {
+			chunk := content[off:min(off+sftpChunkSize, len(content))]
+			write := sftpString(nil, handle)
+			write = binary.BigEndian.AppendUint64(write, uint64(off))
+			write = sftpString(write, string(chunk))
+			if err := sc.requestStatus(sftpWrite, write); err != nil {
+				return err
+			}
+		}
+		return sc.requestStatus(sftpClose, sftpString(nil, handle))
+	})
+}
+
+// sftpDownload reads filePath over the SFTP subsystem of the server.
+func sftpDownload(c *ssh.Client, filePath string) ([]byte, error) {
+	var content []byte
+	err := sftpSession(c, func(sc *sftpClient) error {
+		handle, err := sc.open(filePath, sftpReadFlag)
+		if err != nil {
+			return err
+		}
+		for {
+			read := sftpString(nil, handle)
+			read = binary.BigEndian.AppendUint64(read, uint64(len(content)))
+			read = binary.BigEndian.AppendUint32(read, sftpChunkSize)
+			typ, data, err := sc.request(sftpRead, read)
+			if err != nil {
+				return err
+			}
+			if typ == sftpStatus && len(data) >= 4 && binary.BigEndian.Uint32(data) == sftpStatusEOF {
+				break
+			}
+			if typ != sftpData {
+				return sftpStatusError(typ, data)
+			}
+			chunk, _, err := sftpReadString(data)
+			if err != nil {
+				return err
+			}
+			if len(content)+len(chunk) > maxFileSize {
+				return fmt.Errorf("sftp: file exceeds maximum size of %d bytes", maxFileSize)
+			}
+			content = append(content, chunk...)
+		}
+		return sc.requestStatus(sftpClose, sftpString(nil, handle))
+	})
+	return content, err
+}
+
+// send writes a packet of the type with the payload.
+func (sc *sftpClient) send(typ byte, payload []byte) error {
+	pkt := binary.BigEndian.AppendUint32(nil, uint32(len(payload)+1))
+	pkt = append(pkt, typ)
+	pkt = append(pkt, payload...)
+	_, err := sc.w.Write(pkt)
+	return err
+}
+
+// recv reads a packet and returns its type and payload.
+func (sc *sftpClient) recv() (byte, []byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(sc.r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:4])
+	if n == 0 || n > 1<<20 {
+		return 0, nil, fmt.Errorf("sftp: invalid packet length %d", n)
+	}
+	payload := make([]byte, n-1)
+	if _, err := io.ReadFull(sc.r, payload); err != nil {
+		return 0, nil, err
+	}
+	return hdr[4], payload, nil
+}
+
+// request sends a request with a new ID and returns the type and payload,
+// after the ID, of its response.
+func (sc *sftpClient) request(typ byte, payload []byte) (byte, []byte, error) {
+	sc.reqID++
+	if err := sc.send(typ, append(binary.BigEndian.AppendUint32(nil, sc.reqID), payload...)); err != nil {
+		return 0, nil, err
+	}
+	respTyp, resp, err := sc.recv()
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(resp) < 4 || binary.BigEndian.Uint32(resp) != sc.reqID {
+		return 0, nil, errors.New("sftp: response does not match request")
+	}
+	return respTyp, resp[4:], nil
+}
+
+// requestStatus sends a request whose response is a status, and returns an
+// error unless the status is OK.
+func (sc *sftpClient) requestStatus(typ byte, payload []byte) error {
+	respTyp, resp, err := sc.request(typ, payload)
+	if err != nil {
+		return err
+	}
+	return sftpStatusError(respTyp, resp)
+}
+
+// sftpStatusError returns the error of a response that should be an OK
+// status.
+func sftpStatusError(typ byte, data []byte) error {
+	if typ != sftpStatus {
+		return fmt.Errorf("sftp: unexpected packet type %d", typ)
+	}
+	if len(data) < 4 {
+		return errors.New("sftp: short status")
+	}
+	code := binary.BigEndian.Uint32(data)
+	if code == sftpStatusOK {
+		return nil
+	}
+	msg, _, _ := sftpReadString(data[4:])
+	return fmt.Errorf("sftp: status %d: %s", code, msg)
+}
+
+// sftpString appends the SFTP encoding of s to b.
+func sftpString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// sftpReadString decodes a string at the start of b, and returns it with the
+// rest of b.
+func sftpReadString(b []byte) (string, []byte, error) {
+	if len(b) < 4 {
+		return "", nil, errors.New("sftp: short string")
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < n {
+		return "", nil, errors.New("sftp: short string")
+	}
+	return string(b[4 : 4+n]), b[4+n:], nil
+}
@@ -0,0 +1,544 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package acctz is a gNSI acctz server, which records the gRPC calls made to
+// the device and streams the accounting records to its subscribers.
+package acctz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/openconfig/lemming/gnmi/gnmiclient"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+	"github.com/openconfig/lemming/gnmi/reconciler"
+	"github.com/openconfig/lemming/gnsi/credentialz"
+
+	acctzpb "github.com/openconfig/gnsi/acctz"
+)
+
+const (
+	// DefaultHistorySize is the default number of records retained for the
+	// subscribers.
+	DefaultHistorySize = 1024
+	// maxPayloadSize is the largest request recorded in full. Larger requests
+	// are only summarized.
+	maxPayloadSize = 4096
+)
+
+// serviceTypes maps the protobuf package of the RPCs to their service type.
+var serviceTypes = map[string]acctzpb.GrpcService_GrpcServiceType{
+	"gnmi":  acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNMI,
+	"gnoi":  acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNOI,
+	"gnsi":  acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNSI,
+	"gribi": acctzpb.GrpcService_GRPC_SERVICE_TYPE_GRIBI,
+	"p4":    acctzpb.GrpcService_GRPC_SERVICE_TYPE_P4RT,
+}
+
+// authnTypes maps the credentialz authentication methods to their type.
+var authnTypes = map[credentialz.Method]acctzpb.AuthnDetail_AuthnType{
+	credentialz.MethodNone:     acctzpb.AuthnDetail_AUTHN_TYPE_NONE,
+	credentialz.MethodPassword: acctzpb.AuthnDetail_AUTHN_TYPE_PASSWORD,
+	credentialz.MethodCert:     acctzpb.AuthnDetail_AUTHN_TYPE_TLSCERT,
+}
+
+// secretFields are the names of the request fields carrying secrets, such as
+// the passwords of gNSI credentialz and the private keys of gNSI certz and
+// credentialz, which are cleared from the recorded requests.
+var secretFields = map[protoreflect.Name]bool{
+	"plaintext":       true,
+	"crypto_hash":     true,
+	"private_key":     true,
+	"raw_private_key": true,
+}
+
+// ocServiceTypes maps the service types to their telemetry value. The
+// records of unspecified services are not counted, as UNSPECIFIED does not
+// identify the type of a source record.
+var ocServiceTypes = map[acctzpb.GrpcService_GrpcServiceType]oc.E_GnsiAcctz_GrpcService{
+	acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNMI:  oc.GnsiAcctz_GrpcService_GNMI,
+	acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNOI:  oc.GnsiAcctz_GrpcService_GNOI,
+	acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNSI:  oc.GnsiAcctz_GrpcService_GNSI,
+	acctzpb.GrpcService_GRPC_SERVICE_TYPE_GRIBI: oc.GnsiAcctz_GrpcService_GRIBI,
+	acctzpb.GrpcService_GRPC_SERVICE_TYPE_P4RT:  oc.GnsiAcctz_GrpcService_P4RT,
+}
+
+// Server implements the acctz gRPC servers and the interceptors recording
+// the gRPC calls.
+type Server struct {
+	acctzpb.UnimplementedAcctzServer
+
+	mu sync.Mutex
+	// history contains the retained records, ordered by strictly increasing
+	// timestamps.
+	history []*acctzpb.RecordResponse
+	size    int
+	// lastEvicted is the timestamp of the last record evicted from the
+	// history, zero if none was.
+	lastEvicted time.Time
+	// added is closed when a record is added to the history.
+	added chan struct{}
+
+	countersMu       sync.Mutex
+	recordRequests   uint64
+	recordResponses  uint64
+	historyTruncated uint64
+	// records counts the records of each service type.
+	records map[acctzpb.GrpcService_GrpcServiceType]uint64
+	// dirty is set when the state published by the reconciler is stale.
+	dirty bool
+}
+
+// New returns an acctz server retaining the given number of records.
+func New(historySize int) *Server {
+	return &Server{
+		size:    historySize,
+		added:   make(chan struct{}),
+		records: map[acctzpb.GrpcService_GrpcServiceType]uint64{},
+	}
+}
+
+// add appends a record to the history, evicting the oldest record if the
+// history is full, and wakes up the subscribers.
+func (s *Server) add(rec *acctzpb.RecordResponse) {
+	s.mu.Lock()
+	now := time.Now()
+	if n := len(s.history); n > 0 {
+		// Subscribers resume after the last timestamp they received, so
+		// timestamps must not repeat.
+		if last := s.history[n-1].GetTimestamp().AsTime(); !now.After(last) {
+			now = last.Add(time.Nanosecond)
+		}
+	}
+	rec.Timestamp = timestamppb.New(now)
+	if len(s.history) >= s.size {
+		s.lastEvicted = s.history[0].GetTimestamp().AsTime()
+		s.history = append(s.history[:0:0], s.history[1:]...)
+	}
+	s.history = append(s.history, rec)
+	close(s.added)
+	s.added = make(chan struct{})
+	s.mu.Unlock()
+
+	s.countersMu.Lock()
+	s.records[rec.GetGrpcService().GetServiceType()]++
+	s.dirty = true
+	s.countersMu.Unlock()
+}
+
+// since returns the records with a timestamp after t, whether records after t
+// were evicted, and a channel closed when a record is added.
+func (s *Server) since(t time.Time) ([]*acctzpb.RecordResponse, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := sort.Search(len(s.history), func(i int) bool {
+		return s.history[i].GetTimestamp().AsTime().After(t)
+	})
+	truncated := !s.lastEvicted.IsZero() && s.lastEvicted.After(t)
+	return s.history[i:], truncated, s.added
+}
+
+// subscribe sends the records after t, then the new records until ctx is
+// done.
+func (s *Server) subscribe(ctx context.Context, t time.Time, send func(*acctzpb.RecordResponse) error) error {
+	recs, truncated, added := s.since(t)
+	if truncated {
+		s.countersMu.Lock()
+		s.historyTruncated++
+		s.dirty = true
+		s.countersMu.Unlock()
+	}
+	for {
+		for _, rec := range recs {
+			if truncated {
+				rec = proto.Clone(rec).(*acctzpb.RecordResponse)
+				rec.HistoryIstruncated = true
+				truncated = false
+			}
+			if err := send(rec); err != nil {
+				return err
+			}
+			t = rec.GetTimestamp().AsTime()
+			s.countersMu.Lock()
+			s.recordResponses++
+			s.dirty = true
+			s.countersMu.Unlock()
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-added:
+		}
+		recs, _, added = s.since(t)
+	}
+}
+
+func (s *Server) countRequest() {
+	s.countersMu.Lock()
+	s.recordRequests++
+	s.dirty = true
+	s.countersMu.Unlock()
+}
+
+// RecordSubscribe implements the acctz RecordSubscribe RPC. Each request
+// restarts the stream of records after its timestamp.
+func (s *Server) RecordSubscribe(rs acctzpb.Acctz_RecordSubscribeServer) error {
+	reqs := make(chan *acctzpb.RecordRequest)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			req, err := rs.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case reqs <- req:
+			case <-rs.Context().Done():
+				return
+			}
+		}
+	}()
+
+	subErr := make(chan error, 1)
+	// sub is the subscription of the last request, stopped by the next
+	// request.
+	var sub *subscription
+	stop := func() {
+		if sub != nil {
+			sub.cancel()
+			<-sub.done
+		}
+	}
+	defer stop()
+	for {
+		select {
+		case req := <-reqs:
+			stop()
+			s.countRequest()
+			sub = &subscription{done: make(chan struct{})}
+			var ctx context.Context
+			ctx, sub.cancel = context.WithCancel(rs.Context())
+			go func(done chan struct{}) {
+				defer close(done)
+				if err := s.subscribe(ctx, req.GetTimestamp().AsTime(), rs.Send); err != nil {
+					select {
+					case subErr <- err:
+					default:
+					}
+				}
+			}(sub.done)
+		case err := <-recvErr:
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+			// The client sends no more requests, the records are streamed
+			// until it cancels the call.
+			if sub == nil {
+				return nil
+			}
+			select {
+			case <-rs.Context().Done():
+				return nil
+			case err := <-subErr:
+				return err
+			}
+		case err := <-subErr:
+			return err
+		}
+	}
+}
+
+// subscription is a goroutine streaming records.
+type subscription struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// streamServer implements the server-streaming acctz RecordSubscribe RPC.
+type streamServer struct {
+	acctzpb.UnimplementedAcctzStreamServer
+	s *Server
+}
+
+// StreamServer returns the server-streaming variant of the acctz server.
+func (s *Server) StreamServer() acctzpb.AcctzStreamServer {
+	return &streamServer{s: s}
+}
+
+// RecordSubscribe implements the acctz stream RecordSubscribe RPC.
+func (ss *streamServer) RecordSubscribe(req *acctzpb.RecordRequest, rs acctzpb.AcctzStream_RecordSubscribeServer) error {
+	ss.s.countRequest()
+	return ss.s.subscribe(rs.Context(), req.GetTimestamp().AsTime(), rs.Send)
+}
+
+// serviceType returns the service type of an RPC method, e.g. GNMI for
+// "/gnmi.gNMI/Get".
+func serviceType(method string) acctzpb.GrpcService_GrpcServiceType {
+	pkg, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), ".")
+	return serviceTypes[pkg]
+}
+
+// splitAddr returns the host and port of an address, the host only if the
+// address has no port.
+func splitAddr(addr net.Addr) (string, uint32) {
+	if addr == nil {
+		return "", 0
+	}
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String(), 0
+	}
+	p, _ := strconv.ParseUint(port, 10, 16)
+	return host, uint32(p)
+}
+
+// payload returns the payload of a record for a request, with its secrets
+// redacted.
+func payload(req any) *acctzpb.GrpcService {
+	m, ok := req.(proto.Message)
+	if !ok {
+		return &acctzpb.GrpcService{}
+	}
+	if size := proto.Size(m); size > maxPayloadSize {
+		return &acctzpb.GrpcService{
+			Payload:            &acctzpb.GrpcService_StringVal{StringVal: fmt.Sprintf("%s of %d bytes", m.ProtoReflect().Descriptor().FullName(), size)},
+			PayloadIstruncated: true,
+		}
+	}
+	m = proto.Clone(m)
+	redact(m.ProtoReflect())
+	a, err := anypb.New(m)
+	if err != nil {
+		return &acctzpb.GrpcService{Payload: &acctzpb.GrpcService_StringVal{StringVal: string(m.ProtoReflect().Descriptor().FullName())}}
+	}
+	return &acctzpb.GrpcService{Payload: &acctzpb.GrpcService_ProtoVal{ProtoVal: a}}
+}
+
+// redact clears the secret fields of a message and of its nested messages.
+func redact(m protoreflect.Message) {
+	var secrets []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case secretFields[fd.Name()]:
+			secrets = append(secrets, fd)
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					redact(mv.Message())
+					return true
+				})
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				for i := 0; i < v.List().Len(); i++ {
+					redact(v.List().Get(i).Message())
+				}
+			}
+		case fd.Message() != nil:
+			redact(v.Message())
+		}
+		return true
+	})
+	for _, fd := range secrets {
+		m.Clear(fd)
+	}
+}
+
+// newRecord returns the accounting record of a completed call, whose user is
+// the principal authenticated by the credentialz interceptors.
+func newRecord(ctx context.Context, method string, sessionStatus acctzpb.SessionInfo_SessionStatus, req any, authn *credentialz.Authentication, callErr error) *acctzpb.RecordResponse {
+	session := &acctzpb.SessionInfo{
+		Status: sessionStatus,
+		User:   &acctzpb.UserDetail{Identity: authn.Principal},
+		Authn:  &acctzpb.AuthnDetail{Type: authnTypes[authn.Method]},
+	}
+	switch {
+	case authn.Err != nil:
+		session.Authn.Status = acctzpb.AuthnDetail_AUTHN_STATUS_FAIL
+		session.Authn.Cause = status.Convert(authn.Err).Message()
+	case authn.Method != credentialz.MethodNone:
+		session.Authn.Status = acctzpb.AuthnDetail_AUTHN_STATUS_SUCCESS
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		session.RemoteAddress, session.RemotePort = splitAddr(p.Addr)
+		session.LocalAddress, session.LocalPort = splitAddr(p.LocalAddr)
+		if p.Addr != nil && p.Addr.Network() == "tcp" {
+			session.IpProto = 6
+		}
+	}
+
+	svc := payload(req)
+	svc.ServiceType = serviceType(method)
+	svc.RpcName = method
+	switch {
+	case authn.Err != nil:
+		// The call was rejected before being authorized.
+	case status.Code(callErr) == codes.PermissionDenied:
+		svc.Authz = &acctzpb.AuthzDetail{Status: acctzpb.AuthzDetail_AUTHZ_STATUS_DENY, Detail: status.Convert(callErr).Message()}
+	default:
+		svc.Authz = &acctzpb.AuthzDetail{Status: acctzpb.AuthzDetail_AUTHZ_STATUS_PERMIT}
+	}
+	return &acctzpb.RecordResponse{
+		SessionInfo:    session,
+		ServiceRequest: &acctzpb.RecordResponse_GrpcService{GrpcService: svc},
+	}
+}
+
+// Unary is a gRPC unary interceptor recording the calls once they complete.
+func (s *Server) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, authn := credentialz.WithAuthentication(ctx)
+	resp, err := handler(ctx, req)
+	s.add(newRecord(ctx, info.FullMethod, acctzpb.SessionInfo_SESSION_STATUS_ONCE, req, authn, err))
+	return resp, err
+}
+
+// Stream is a gRPC stream interceptor recording the calls once they
+// complete, with the first request of the stream as payload.
+func (s *Server) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, authn := credentialz.WithAuthentication(ss.Context())
+	rs := &recordingStream{ServerStream: ss, ctx: ctx}
+	err := handler(srv, rs)
+	s.add(newRecord(ctx, info.FullMethod, acctzpb.SessionInfo_SESSION_STATUS_OPERATION, rs.firstRequest(), authn, err))
+	return err
+}
+
+// recordingStream is a server stream keeping a copy of the first request it
+// receives, whose context records the authentication of the call.
+type recordingStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	mu    sync.Mutex
+	first proto.Message
+}
+
+func (rs *recordingStream) Context() context.Context {
+	return rs.ctx
+}
+
+func (rs *recordingStream) RecvMsg(m any) error {
+	if err := rs.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if pm, ok := m.(proto.Message); ok && rs.first == nil {
+		rs.first = proto.Clone(pm)
+	}
+	return nil
+}
+
+func (rs *recordingStream) firstRequest() any {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.first == nil {
+		return nil
+	}
+	return rs.first
+}
+
+// publishState publishes the acctz counters, if they changed since they were
+// last published.
+func (s *Server) publishState(ctx context.Context, c *ygnmi.Client) error {
+	s.countersMu.Lock()
+	if !s.dirty {
+		s.countersMu.Unlock()
+		return nil
+	}
+	s.dirty = false
+	counters := &oc.System_GrpcServer_Acctz_Counters{
+		RecordRequests:     ygot.Uint64(s.recordRequests),
+		RecordResponses:    ygot.Uint64(s.recordResponses),
+		HistoryIstruncated: ygot.Uint64(s.historyTruncated),
+	}
+	records := map[oc.E_GnsiAcctz_GrpcService]uint64{}
+	for t, n := range s.records {
+		if ocType, ok := ocServiceTypes[t]; ok {
+			records[ocType] = n
+		}
+	}
+	s.countersMu.Unlock()
+
+	batch := &ygnmi.SetBatch{}
+	gnmiclient.BatchReplace(batch, ocpath.Root().System().GrpcServer("gnsi").Acctz().Counters().State(), counters)
+	for t, n := range records {
+		gnmiclient.BatchReplace(batch, ocpath.Root().System().Aaa().Accounting().Acctz().SourceRecord(oc.GnsiAcctz_ServiceRequest_GRPC_SERVICE, t).State(), &oc.System_Aaa_Accounting_Acctz_SourceRecord{
+			Service:  oc.GnsiAcctz_ServiceRequest_GRPC_SERVICE,
+			Type:     t,
+			Counters: &oc.System_Aaa_Accounting_Acctz_SourceRecord_Counters{Records: ygot.Uint64(n)},
+		})
+	}
+	if _, err := batch.Set(ctx, c); err != nil {
+		s.countersMu.Lock()
+		s.dirty = true
+		s.countersMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Reconciler returns a reconciler publishing the acctz counters under
+// /system.
+func (s *Server) Reconciler() *reconciler.BuiltReconciler {
+	var cancel context.CancelFunc
+	return reconciler.NewBuilder("gnsi-acctz").
+		WithStart(func(ctx context.Context, c *ygnmi.Client) error {
+			ctx, cancel = context.WithCancel(ctx)
+			s.countersMu.Lock()
+			s.dirty = true
+			s.countersMu.Unlock()
+			go func() {
+				tick := time.NewTicker(time.Second)
+				defer tick.Stop()
+				for {
+					if err := s.publishState(ctx, c); err != nil && ctx.Err() == nil {
+						log.Warningf("unable to update acctz state: %v", err)
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case <-tick.C:
+					}
+				}
+			}()
+			return nil
+		}).
+		WithStop(func(context.Context) error {
+			if cancel != nil {
+				cancel()
+			}
+			return nil
+		}).Build()
+}
@@ -0,0 +1,450 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acctz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/openconfig/lemming/gnmi"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+	"github.com/openconfig/lemming/gnsi/credentialz"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	acctzpb "github.com/openconfig/gnsi/acctz"
+	cpb "github.com/openconfig/gnsi/credentialz"
+)
+
+func TestInterceptor(t *testing.T) {
+	setReq := &gpb.SetRequest{Prefix: &gpb.Path{Target: "dut"}}
+	largeReq := &gpb.SetRequest{Prefix: &gpb.Path{Target: strings.Repeat("x", maxPayloadSize)}}
+	p := &peer.Peer{
+		Addr:      &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 40000},
+		LocalAddr: &net.TCPAddr{IP: net.ParseIP("192.0.2.2"), Port: 9339},
+	}
+	ctx := peer.NewContext(context.Background(), p)
+	session := func(status acctzpb.SessionInfo_SessionStatus, user string, authn *acctzpb.AuthnDetail) *acctzpb.SessionInfo {
+		return &acctzpb.SessionInfo{
+			LocalAddress:  "192.0.2.2",
+			LocalPort:     9339,
+			RemoteAddress: "192.0.2.1",
+			RemotePort:    40000,
+			IpProto:       6,
+			Status:        status,
+			User:          &acctzpb.UserDetail{Identity: user},
+			Authn:         authn,
+		}
+	}
+	noAuthn := &acctzpb.AuthnDetail{Type: acctzpb.AuthnDetail_AUTHN_TYPE_NONE}
+	permit := &acctzpb.AuthzDetail{Status: acctzpb.AuthzDetail_AUTHZ_STATUS_PERMIT}
+
+	tests := []struct {
+		desc    string
+		method  string
+		req     proto.Message
+		authn   credentialz.Authentication
+		callErr error
+		want    *acctzpb.RecordResponse
+	}{{
+		desc:   "anonymous",
+		method: "/gnmi.gNMI/Set",
+		req:    setReq,
+		want: &acctzpb.RecordResponse{
+			SessionInfo: session(acctzpb.SessionInfo_SESSION_STATUS_ONCE, "", noAuthn),
+			ServiceRequest: &acctzpb.RecordResponse_GrpcService{GrpcService: &acctzpb.GrpcService{
+				ServiceType: acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNMI,
+				RpcName:     "/gnmi.gNMI/Set",
+				Payload:     &acctzpb.GrpcService_ProtoVal{ProtoVal: mustAny(t, setReq)},
+				Authz:       permit,
+			}},
+		},
+	}, {
+		desc:   "authenticated",
+		method: "/gnoi.system.System/Time",
+		authn:  credentialz.Authentication{Principal: "alice", Method: credentialz.MethodPassword},
+		want: &acctzpb.RecordResponse{
+			SessionInfo: session(acctzpb.SessionInfo_SESSION_STATUS_ONCE, "alice", &acctzpb.AuthnDetail{
+				Type:   acctzpb.AuthnDetail_AUTHN_TYPE_PASSWORD,
+				Status: acctzpb.AuthnDetail_AUTHN_STATUS_SUCCESS,
+			}),
+			ServiceRequest: &acctzpb.RecordResponse_GrpcService{GrpcService: &acctzpb.GrpcService{
+				ServiceType: acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNOI,
+				RpcName:     "/gnoi.system.System/Time",
+				Authz:       permit,
+			}},
+		},
+	}, {
+		desc:    "authentication failure",
+		method:  "/gribi.gRIBI/Get",
+		authn:   credentialz.Authentication{Method: credentialz.MethodPassword, Err: status.Error(codes.Unauthenticated, "invalid username or password")},
+		callErr: status.Error(codes.Unauthenticated, "invalid username or password"),
+		want: &acctzpb.RecordResponse{
+			SessionInfo: session(acctzpb.SessionInfo_SESSION_STATUS_ONCE, "", &acctzpb.AuthnDetail{
+				Type:   acctzpb.AuthnDetail_AUTHN_TYPE_PASSWORD,
+				Status: acctzpb.AuthnDetail_AUTHN_STATUS_FAIL,
+				Cause:  "invalid username or password",
+			}),
+			ServiceRequest: &acctzpb.RecordResponse_GrpcService{GrpcService: &acctzpb.GrpcService{
+				ServiceType: acctzpb.GrpcService_GRPC_SERVICE_TYPE_GRIBI,
+				RpcName:     "/gribi.gRIBI/Get",
+			}},
+		},
+	}, {
+		desc:   "certificate",
+		method: "/gnmi.gNMI/Get",
+		authn:  credentialz.Authentication{Principal: "alice", Method: credentialz.MethodCert},
+		want: &acctzpb.RecordResponse{
+			SessionInfo: session(acctzpb.SessionInfo_SESSION_STATUS_ONCE, "alice", &acctzpb.AuthnDetail{
+				Type:   acctzpb.AuthnDetail_AUTHN_TYPE_TLSCERT,
+				Status: acctzpb.AuthnDetail_AUTHN_STATUS_SUCCESS,
+			}),
+			ServiceRequest: &acctzpb.RecordResponse_GrpcService{GrpcService: &acctzpb.GrpcService{
+				ServiceType: acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNMI,
+				RpcName:     "/gnmi.gNMI/Get",
+				Authz:       permit,
+			}},
+		},
+	}, {
+		desc:    "anonymous rejected",
+		method:  "/gnmi.gNMI/Get",
+		authn:   credentialz.Authentication{Err: status.Error(codes.Unauthenticated, "no credentials specified")},
+		callErr: status.Error(codes.Unauthenticated, "no credentials specified"),
+		want: &acctzpb.RecordResponse{
+			SessionInfo: session(acctzpb.SessionInfo_SESSION_STATUS_ONCE, "", &acctzpb.AuthnDetail{
+				Type:   acctzpb.AuthnDetail_AUTHN_TYPE_NONE,
+				Status: acctzpb.AuthnDetail_AUTHN_STATUS_FAIL,
+				Cause:  "no credentials specified",
+			}),
+			ServiceRequest: &acctzpb.RecordResponse_GrpcService{GrpcService: &acctzpb.GrpcService{
+				ServiceType: acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNMI,
+				RpcName:     "/gnmi.gNMI/Get",
+			}},
+		},
+	}, {
+		desc:    "denied",
+		method:  "/gnsi.authz.v1.Authz/Get",
+		authn:   credentialz.Authentication{Principal: "bob", Method: credentialz.MethodPassword},
+		callErr: status.Error(codes.PermissionDenied, "denied by policy"),
+		want: &acctzpb.RecordResponse{
+			SessionInfo: session(acctzpb.SessionInfo_SESSION_STATUS_ONCE, "bob", &acctzpb.AuthnDetail{
+				Type:   acctzpb.AuthnDetail_AUTHN_TYPE_PASSWORD,
+				Status: acctzpb.AuthnDetail_AUTHN_STATUS_SUCCESS,
+			}),
+			ServiceRequest: &acctzpb.RecordResponse_GrpcService{GrpcService: &acctzpb.GrpcService{
+				ServiceType: acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNSI,
+				RpcName:     "/gnsi.authz.v1.Authz/Get",
+				Authz:       &acctzpb.AuthzDetail{Status: acctzpb.AuthzDetail_AUTHZ_STATUS_DENY, Detail: "denied by policy"},
+			}},
+		},
+	}, {
+		desc:   "truncated payload",
+		method: "/p4.v1.P4Runtime/Write",
+		req:    largeReq,
+		want: &acctzpb.RecordResponse{
+			SessionInfo: session(acctzpb.SessionInfo_SESSION_STATUS_ONCE, "", noAuthn),
+			ServiceRequest: &acctzpb.RecordResponse_GrpcService{GrpcService: &acctzpb.GrpcService{
+				ServiceType:        acctzpb.GrpcService_GRPC_SERVICE_TYPE_P4RT,
+				RpcName:            "/p4.v1.P4Runtime/Write",
+				Payload:            &acctzpb.GrpcService_StringVal{StringVal: fmt.Sprintf("gnmi.SetRequest of %d bytes", proto.Size(largeReq))},
+				PayloadIstruncated: true,
+				Authz:              permit,
+			}},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s := New(DefaultHistorySize)
+			// The handler authenticates the call like the credentialz
+			// interceptor.
+			handler := func(ctx context.Context, _ any) (any, error) {
+				if a, ok := credentialz.AuthenticationFromContext(ctx); ok {
+					*a = tt.authn
+				}
+				return nil, tt.callErr
+			}
+			if _, err := s.Unary(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler); err != tt.callErr {
+				t.Fatalf("Unary() got err %v, want %v", err, tt.callErr)
+			}
+			recs, _, _ := s.since(time.Time{})
+			if len(recs) != 1 {
+				t.Fatalf("Unary() recorded %d records, want 1", len(recs))
+			}
+			if diff := cmp.Diff(tt.want, recs[0], protocmp.Transform(), protocmp.IgnoreFields(&acctzpb.RecordResponse{}, "timestamp")); diff != "" {
+				t.Errorf("Unary() record diff (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRecordSubscribe(t *testing.T) {
+	s, conn, closeFn := start(t, 3)
+	client, streamClient := acctzpb.NewAcctzClient(conn), acctzpb.NewAcctzStreamClient(conn)
+	defer closeFn()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	addRecords := func(methods ...string) {
+		for _, m := range methods {
+			s.add(&acctzpb.RecordResponse{ServiceRequest: &acctzpb.RecordResponse_GrpcService{GrpcService: &acctzpb.GrpcService{RpcName: m}}})
+		}
+	}
+	type record struct {
+		rpc       string
+		truncated bool
+	}
+	recv := func(t *testing.T, rs interface {
+		Recv() (*acctzpb.RecordResponse, error)
+	}, n int,
+	) ([]record, *timestamppb.Timestamp) {
+		t.Helper()
+		var got []record
+		var last *timestamppb.Timestamp
+		for i := 0; i < n; i++ {
+			resp, err := rs.Recv()
+			if err != nil {
+				t.Fatalf("RecordSubscribe() unexpected err: %v", err)
+			}
+			got = append(got, record{rpc: resp.GetGrpcService().GetRpcName(), truncated: resp.GetHistoryIstruncated()})
+			last = resp.GetTimestamp()
+		}
+		return got, last
+	}
+
+	addRecords("/a", "/b")
+	rs, err := client.RecordSubscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rs.Send(&acctzpb.RecordRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := recv(t, rs, 2)
+	if want := []record{{rpc: "/a"}, {rpc: "/b"}}; !cmp.Equal(got, want, cmp.AllowUnexported(record{})) {
+		t.Errorf("RecordSubscribe() history got %v, want %v", got, want)
+	}
+	addRecords("/c")
+	got, last := recv(t, rs, 1)
+	if want := []record{{rpc: "/c"}}; !cmp.Equal(got, want, cmp.AllowUnexported(record{})) {
+		t.Errorf("RecordSubscribe() new records got %v, want %v", got, want)
+	}
+
+	// The history retains 3 records, so /a is evicted.
+	addRecords("/d")
+	recv(t, rs, 1)
+	if err := rs.Send(&acctzpb.RecordRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = recv(t, rs, 3)
+	if want := []record{{rpc: "/b", truncated: true}, {rpc: "/c"}, {rpc: "/d"}}; !cmp.Equal(got, want, cmp.AllowUnexported(record{})) {
+		t.Errorf("RecordSubscribe() restarted history got %v, want %v", got, want)
+	}
+
+	srs, err := streamClient.RecordSubscribe(ctx, &acctzpb.RecordRequest{Timestamp: last})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ = recv(t, srs, 1)
+	if want := []record{{rpc: "/d"}}; !cmp.Equal(got, want, cmp.AllowUnexported(record{})) {
+		t.Errorf("stream RecordSubscribe() after %v got %v, want %v", last.AsTime(), got, want)
+	}
+
+	t.Run("counters", func(t *testing.T) {
+		gnmiServer, err := gnmi.New(grpc.NewServer(), "local", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := ygnmi.NewClient(gnmiServer.LocalClient(), ygnmi.WithTarget("local"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.publishState(ctx, c); err != nil {
+			t.Fatalf("publishState() unexpected err: %v", err)
+		}
+		// The state is applied to the cache asynchronously.
+		val, err := ygnmi.Watch(ctx, c, ocpath.Root().System().GrpcServer("gnsi").Acctz().Counters().State(), func(v *ygnmi.Value[*oc.System_GrpcServer_Acctz_Counters]) error {
+			if _, ok := v.Val(); ok {
+				return nil
+			}
+			return ygnmi.Continue
+		}).Await()
+		if err != nil {
+			t.Fatalf("failed to get acctz counters: %v", err)
+		}
+		counters, _ := val.Val()
+		if got, want := [3]uint64{counters.GetRecordRequests(), counters.GetRecordResponses(), counters.GetHistoryIstruncated()}, [3]uint64{3, 8, 1}; got != want {
+			t.Errorf("acctz requests, responses and truncations got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	s, conn, closeFn := start(t, DefaultHistorySize)
+	client := acctzpb.NewAcctzClient(conn)
+	defer closeFn()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rotatePassword(t, conn, "alice", "s3cret")
+	subCtx, subCancel := context.WithCancel(metadata.AppendToOutgoingContext(ctx, "username", "alice", "password", "s3cret"))
+	rs, err := client.RecordSubscribe(subCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &acctzpb.RecordRequest{Timestamp: timestamppb.Now()}
+	if err := rs.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	s.add(&acctzpb.RecordResponse{})
+	if _, err := rs.Recv(); err != nil {
+		t.Fatalf("RecordSubscribe() unexpected err: %v", err)
+	}
+	// The call is recorded once it completes.
+	subCancel()
+
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		// The records are of the rotation, the added record and the call.
+		if recs, _, _ := s.since(time.Time{}); len(recs) > 2 {
+			svc := recs[2].GetGrpcService()
+			if got, want := []any{svc.GetRpcName(), svc.GetServiceType(), recs[2].GetSessionInfo().GetStatus(), recs[2].GetSessionInfo().GetUser().GetIdentity(), recs[2].GetSessionInfo().GetAuthn().GetType()}, []any{"/gnsi.acctz.v1.Acctz/RecordSubscribe", acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNSI, acctzpb.SessionInfo_SESSION_STATUS_OPERATION, "alice", acctzpb.AuthnDetail_AUTHN_TYPE_PASSWORD}; !cmp.Equal(got, want) {
+				t.Errorf("stream record got %v, want %v", got, want)
+			}
+			if diff := cmp.Diff(mustAny(t, req), svc.GetProtoVal(), protocmp.Transform()); diff != "" {
+				t.Errorf("stream record payload diff (-want, +got):\n%s", diff)
+			}
+			return
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatal("stream call not recorded")
+		}
+	}
+}
+
+func TestRedactedPayload(t *testing.T) {
+	s, conn, closeFn := start(t, DefaultHistorySize)
+	defer closeFn()
+
+	rotatePassword(t, conn, "alice", "s3cret")
+
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if recs, _, _ := s.since(time.Time{}); len(recs) > 0 {
+			b, err := proto.Marshal(recs[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(b, []byte("s3cret")) {
+				t.Errorf("record contains the plaintext password: %v", recs[0])
+			}
+			got := &cpb.RotateAccountCredentialsRequest{}
+			if err := recs[0].GetGrpcService().GetProtoVal().UnmarshalTo(got); err != nil {
+				t.Fatal(err)
+			}
+			want := &cpb.RotateAccountCredentialsRequest{
+				Request: &cpb.RotateAccountCredentialsRequest_Password{Password: &cpb.PasswordRequest{Accounts: []*cpb.PasswordRequest_Account{{
+					Account:  "alice",
+					Password: &cpb.PasswordRequest_Password{},
+					Version:  "1",
+				}}}},
+			}
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("record payload diff (-want, +got):\n%s", diff)
+			}
+			return
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatal("rotation not recorded")
+		}
+	}
+}
+
+// rotatePassword sets the password of an account with gNSI credentialz.
+func rotatePassword(t testing.TB, conn *grpc.ClientConn, account, password string) {
+	t.Helper()
+	rot, err := cpb.NewCredentialzClient(conn).RotateAccountCredentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs := []*cpb.RotateAccountCredentialsRequest{{
+		Request: &cpb.RotateAccountCredentialsRequest_Password{Password: &cpb.PasswordRequest{Accounts: []*cpb.PasswordRequest_Account{{
+			Account:  account,
+			Password: &cpb.PasswordRequest_Password{Value: &cpb.PasswordRequest_Password_Plaintext{Plaintext: password}},
+			Version:  "1",
+		}}}},
+	}, {
+		Request: &cpb.RotateAccountCredentialsRequest_Finalize{Finalize: &cpb.FinalizeRequest{}},
+	}}
+	for _, req := range reqs {
+		if err := rot.Send(req); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rot.Recv(); err != nil && !errors.Is(err, io.EOF) {
+			t.Fatalf("RotateAccountCredentials() unexpected err: %v", err)
+		}
+	}
+}
+
+func mustAny(t testing.TB, m proto.Message) *anypb.Any {
+	t.Helper()
+	a, err := anypb.New(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func start(t testing.TB, historySize int) (*Server, *grpc.ClientConn, func()) {
+	t.Helper()
+	acctzServer := New(historySize)
+	credzServer := credentialz.New()
+	credzServer.SetAllowAnonymous(true)
+
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(acctzServer.Unary, credzServer.Unary), grpc.ChainStreamInterceptor(acctzServer.Stream, credzServer.Stream))
+	acctzpb.RegisterAcctzServer(s, acctzServer)
+	acctzpb.RegisterAcctzStreamServer(s, acctzServer.StreamServer())
+	cpb.RegisterCredentialzServer(s, credzServer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:")
+	if err != nil {
+		t.Fatalf("failed to start listener: %v", err)
+	}
+
+	go s.Serve(l)
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed dial server: %v", err)
+	}
+	return acctzServer, conn, func() { s.Stop() }
+}
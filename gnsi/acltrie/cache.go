@@ -0,0 +1,191 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acltrie
+
+import (
+	"sort"
+	"strings"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	pathzpb "github.com/openconfig/gnsi/pathz"
+)
+
+const (
+	// maxViews is the number of compiled views kept by a trie, beyond which
+	// they are all discarded.
+	maxViews = 1024
+	// maxDecisions is the number of decisions kept by a trie, beyond which
+	// they are all discarded.
+	maxDecisions = 1 << 16
+)
+
+// principalMode is a user and the mode of its access.
+type principalMode struct {
+	user string
+	mode pathzpb.Mode
+}
+
+// decisionKey identifies the decision for an access to a path.
+type decisionKey struct {
+	principalMode
+	path string
+}
+
+// nodeAction is the action of a user at a node, and whether the action is
+// from a rule for the user rather than for one of its groups.
+type nodeAction struct {
+	action pathzpb.Action
+	isUser bool
+}
+
+// view is the trie compiled for a user and mode: it only contains the nodes
+// with an action for the user, resolved once for all its groups, and their
+// ancestors.
+type view struct {
+	actions map[*trieNode]nodeAction
+	// relevant contains the nodes with an action and their ancestors, the
+	// other subtrees cannot match.
+	relevant map[*trieNode]bool
+}
+
+// compile returns the view of the trie for a user and mode.
+func (t *Trie) compile(user string, mode pathzpb.Mode) *view {
+	v := &view{
+		actions:  map[*trieNode]nodeAction{},
+		relevant: map[*trieNode]bool{},
+	}
+	if t.root == nil {
+		return v
+	}
+	t.walk(func(node *trieNode, _ *gpb.Path) (bool, error) {
+		if !node.hasPolicy {
+			return true, nil
+		}
+		act, isUser := node.getAction(user, mode, t.memberships)
+		if act == pathzpb.Action_ACTION_UNSPECIFIED {
+			return true, nil
+		}
+		v.actions[node] = nodeAction{action: act, isUser: isUser}
+		for n := node; n != nil && !v.relevant[n]; n = n.parent {
+			v.relevant[n] = true
+		}
+		return true, nil
+	})
+	return v
+}
+
+// view returns the compiled view of the trie for a user and mode, compiling
+// it on first use.
+func (t *Trie) view(user string, mode pathzpb.Mode) *view {
+	key := principalMode{user: user, mode: mode}
+	t.cacheMu.RLock()
+	v, ok := t.views[key]
+	t.cacheMu.RUnlock()
+	if ok {
+		return v
+	}
+	v = t.compile(user, mode)
+	t.cacheMu.Lock()
+	defer t.cacheMu.Unlock()
+	if t.views == nil || len(t.views) >= maxViews {
+		t.views = map[principalMode]*view{}
+	}
+	t.views[key] = v
+	return v
+}
+
+// decide caches the decision for an access to a path.
+func (t *Trie) decide(key decisionKey, act pathzpb.Action) {
+	t.cacheMu.Lock()
+	defer t.cacheMu.Unlock()
+	if t.decisions == nil || len(t.decisions) >= maxDecisions {
+		t.decisions = map[decisionKey]pathzpb.Action{}
+	}
+	t.decisions[key] = act
+}
+
+// resetCache discards the compiled views and decisions, which are stale once
+// the rules of the trie change.
+func (t *Trie) resetCache() {
+	t.cacheMu.Lock()
+	defer t.cacheMu.Unlock()
+	t.views = nil
+	t.decisions = nil
+}
+
+// pathKey returns a string identifying a path for the decisions cache. Paths
+// with the same key are equal.
+func pathKey(path *gpb.Path) string {
+	var b strings.Builder
+	if origin := path.GetOrigin(); origin != "openconfig" {
+		b.WriteString(escapeKey(origin))
+	}
+	for _, elem := range path.GetElem() {
+		b.WriteByte('/')
+		b.WriteString(escapeKey(elem.GetName()))
+		if len(elem.GetKey()) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(elem.GetKey()))
+		for k := range elem.GetKey() {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b.WriteByte('[')
+			b.WriteString(escapeKey(k))
+			b.WriteByte('=')
+			b.WriteString(escapeKey(elem.GetKey()[k]))
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
+
+var keyEscaper = strings.NewReplacer(`\`, `\\`, `/`, `\/`, `[`, `\[`, `]`, `\]`, `=`, `\=`)
+
+func escapeKey(s string) string {
+	if !strings.ContainsAny(s, `\/[]=`) {
+		return s
+	}
+	return keyEscaper.Replace(s)
+}
+
+// matchingKeys returns the keys of the children of a node matching elem: the
+// rules for elem have the same name and a subset of its keys, with the same
+// values, the other keys being wildcards.
+func matchingKeys(elem *gpb.PathElem) ([]string, error) {
+	var definite []string
+	for k, v := range elem.GetKey() {
+		if v != "*" {
+			definite = append(definite, k)
+		}
+	}
+	keys := make([]string, 0, 1<<len(definite))
+	for subset := 0; subset < 1<<len(definite); subset++ {
+		kv := make(map[string]string, len(definite))
+		for i, k := range definite {
+			if subset&(1<<i) != 0 {
+				kv[k] = elem.GetKey()[k]
+			}
+		}
+		key, _, err := elemToString(elem.GetName(), kv)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -20,8 +20,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
-
-	"github.com/openconfig/ygot/util"
+	"sync"
 
 	gpb "github.com/openconfig/gnmi/proto/gnmi"
 	pathzpb "github.com/openconfig/gnsi/pathz"
@@ -32,6 +31,15 @@ type Trie struct {
 	root *trieNode
 	// memberships is a map of group name to a set of users.
 	memberships map[string]map[string]bool
+
+	// cacheMu protects the evaluation caches, which are discarded when a
+	// rule is inserted. Rotating a policy builds a new trie, with empty
+	// caches.
+	cacheMu sync.RWMutex
+	// views is keyed by the user and mode of the compiled views.
+	views map[principalMode]*view
+	// decisions is keyed by the user, mode, and path of the probed accesses.
+	decisions map[decisionKey]pathzpb.Action
 }
 
 type trieNode struct {
@@ -171,33 +179,68 @@ func (t *Trie) Insert(r *pathzpb.AuthorizationRule) error {
 		return fmt.Errorf("error inserting policy at %v: %v", r.Path, err)
 	}
 	node.hasPolicy = true
+	t.resetCache()
 
 	return nil
 }
 
-// Probe returns the action for the given path, user, and mode; if there is no match, deny is returned
+// Probe returns the action for the given path, user, and mode; if there is no match, deny is returned.
+// Decisions are memoized, and evaluated against the trie compiled for the user and mode.
 func (t *Trie) Probe(path *gpb.Path, user string, mode pathzpb.Mode) pathzpb.Action {
-	matchingPolicies := []*trieNode{}
-	longestPolicyLen := 0
+	key := decisionKey{principalMode: principalMode{user: user, mode: mode}, path: pathKey(path)}
+	t.cacheMu.RLock()
+	act, ok := t.decisions[key]
+	t.cacheMu.RUnlock()
+	if ok {
+		return act
+	}
+	act = t.probe(path, t.view(user, mode))
+	t.decide(key, act)
+	return act
+}
 
-	// Walk the matching rules, keeping only the longest ones that contain either the user or a group that the user belongs too.
-	t.walk(func(node *trieNode, walkPath *gpb.Path) (bool, error) {
-		if res := util.ComparePaths(path, walkPath); res != util.Equal && res != util.Subset {
-			return false, nil
-		}
-		if !node.hasPolicy {
-			return true, nil
+// probe returns the action for the given path in the view of a user and mode.
+func (t *Trie) probe(path *gpb.Path, v *view) pathzpb.Action {
+	if len(v.actions) == 0 {
+		return pathzpb.Action_ACTION_DENY
+	}
+	// Rules apply to paths without an origin.
+	if origin := path.GetOrigin(); origin != "" && origin != "openconfig" {
+		return pathzpb.Action_ACTION_DENY
+	}
+
+	// Descend the relevant nodes matching the path, keeping only the deepest ones that contain either the user or a group that the user belongs too.
+	var matchingPolicies []*trieNode
+	if _, ok := v.actions[t.root]; ok {
+		matchingPolicies = append(matchingPolicies, t.root)
+	}
+	nodes := []*trieNode{t.root}
+	for _, elem := range path.GetElem() {
+		keys, err := matchingKeys(elem)
+		if err != nil {
+			break
 		}
-		if act, _ := node.getAction(user, mode, t.memberships); act != pathzpb.Action_ACTION_UNSPECIFIED {
-			if len(walkPath.Elem) > longestPolicyLen {
-				matchingPolicies = nil
-				longestPolicyLen = len(walkPath.Elem)
+		var next, matching []*trieNode
+		for _, node := range nodes {
+			for _, key := range keys {
+				child, ok := node.children[key]
+				if !ok || !v.relevant[child] {
+					continue
+				}
+				next = append(next, child)
+				if _, ok := v.actions[child]; ok {
+					matching = append(matching, child)
+				}
 			}
-			matchingPolicies = append(matchingPolicies, node)
 		}
-
-		return true, nil
-	})
+		if len(matching) > 0 {
+			matchingPolicies = matching
+		}
+		if len(next) == 0 {
+			break
+		}
+		nodes = next
+	}
 
 	if len(matchingPolicies) == 0 {
 		return pathzpb.Action_ACTION_DENY
@@ -216,7 +259,7 @@ func (t *Trie) Probe(path *gpb.Path, user string, mode pathzpb.Mode) pathzpb.Act
 	var finalAction pathzpb.Action
 	// Prefer user over groups and DENY over permit.
 	for _, n := range mostSpecificPolicies {
-		act, isUser := n.getAction(user, mode, t.memberships)
+		act, isUser := v.actions[n].action, v.actions[n].isUser
 		switch {
 		case isUser && act == pathzpb.Action_ACTION_DENY: // Prefer user and deny, so return immediately.
 			return act
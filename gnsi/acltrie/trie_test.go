@@ -451,3 +451,111 @@ func TestProbe(t *testing.T) {
 		})
 	}
 }
+
+func TestProbeCache(t *testing.T) {
+	trie, err := FromPolicy(&pathzpb.AuthorizationPolicy{
+		Groups: []*pathzpb.Group{{Name: "admin", Users: []*pathzpb.User{{Name: "bob"}}}},
+		Rules: []*pathzpb.AuthorizationRule{{
+			Path:      mustPath("/foo[a=*]"),
+			Principal: &pathzpb.AuthorizationRule_Group{Group: "admin"},
+			Mode:      pathzpb.Mode_MODE_READ,
+			Action:    pathzpb.Action_ACTION_PERMIT,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("FromPolicy() unexpected err: %v", err)
+	}
+	probes := []struct {
+		path *gpb.Path
+		user string
+		want pathzpb.Action
+	}{
+		{path: mustPath("/foo[a=1]/bar"), user: "bob", want: pathzpb.Action_ACTION_PERMIT},
+		{path: mustPath("/foo[a=1]/bar"), user: "alice", want: pathzpb.Action_ACTION_DENY},
+		{path: mustPath("/foo[a=1=]/bar"), user: "bob", want: pathzpb.Action_ACTION_PERMIT},
+		{path: &gpb.Path{Origin: "openconfig", Elem: mustPath("/foo[a=1]/bar").Elem}, user: "bob", want: pathzpb.Action_ACTION_PERMIT},
+		{path: &gpb.Path{Origin: "cli", Elem: mustPath("/foo[a=1]/bar").Elem}, user: "bob", want: pathzpb.Action_ACTION_DENY},
+	}
+	for range 2 {
+		for _, p := range probes {
+			if got := trie.Probe(p.path, p.user, pathzpb.Mode_MODE_READ); got != p.want {
+				t.Errorf("Probe(%v, %q) got %v, want %v", p.path, p.user, got, p.want)
+			}
+		}
+	}
+	if err := trie.Insert(&pathzpb.AuthorizationRule{
+		Path:      mustPath("/foo[a=1]"),
+		Principal: &pathzpb.AuthorizationRule_User{User: "bob"},
+		Mode:      pathzpb.Mode_MODE_READ,
+		Action:    pathzpb.Action_ACTION_DENY,
+	}); err != nil {
+		t.Fatalf("Insert() unexpected err: %v", err)
+	}
+	if got := trie.Probe(mustPath("/foo[a=1]/bar"), "bob", pathzpb.Mode_MODE_READ); got != pathzpb.Action_ACTION_DENY {
+		t.Errorf("Probe() after Insert() got %v, want the cached decision discarded", got)
+	}
+}
+
+// benchmarkTrie returns a trie with rules rules per user and for a group,
+// with wildcard keys, and paths probed by the users.
+func benchmarkTrie(b *testing.B, rules int) (*Trie, []*gpb.Path) {
+	b.Helper()
+	const users = 100
+	group := &pathzpb.Group{Name: "readers"}
+	for u := range users {
+		group.Users = append(group.Users, &pathzpb.User{Name: fmt.Sprintf("user%d", u)})
+	}
+	policy := &pathzpb.AuthorizationPolicy{Groups: []*pathzpb.Group{group}}
+	for i := range rules {
+		policy.Rules = append(policy.Rules, &pathzpb.AuthorizationRule{
+			Path:      mustPath(fmt.Sprintf("/interfaces/interface[name=eth%d]/state", i)),
+			Principal: &pathzpb.AuthorizationRule_User{User: fmt.Sprintf("user%d", i%users)},
+			Mode:      pathzpb.Mode_MODE_READ,
+			Action:    pathzpb.Action_ACTION_PERMIT,
+		}, &pathzpb.AuthorizationRule{
+			Path:      mustPath(fmt.Sprintf("/network-instances/network-instance[name=*]/protocols/protocol[identifier=BGP][name=bgp%d]/bgp/neighbors/neighbor[neighbor-address=*]", i)),
+			Principal: &pathzpb.AuthorizationRule_Group{Group: "readers"},
+			Mode:      pathzpb.Mode_MODE_READ,
+			Action:    pathzpb.Action_ACTION_PERMIT,
+		})
+	}
+	policy.Rules = append(policy.Rules, &pathzpb.AuthorizationRule{
+		Path:      mustPath("/interfaces/interface[name=*]/state/counters"),
+		Principal: &pathzpb.AuthorizationRule_Group{Group: "readers"},
+		Mode:      pathzpb.Mode_MODE_READ,
+		Action:    pathzpb.Action_ACTION_DENY,
+	})
+	trie, err := FromPolicy(policy)
+	if err != nil {
+		b.Fatalf("FromPolicy() unexpected err: %v", err)
+	}
+	var paths []*gpb.Path
+	for i := range 1000 {
+		paths = append(paths,
+			mustPath(fmt.Sprintf("/interfaces/interface[name=eth%d]/state/counters/in-pkts", i%rules)),
+			mustPath(fmt.Sprintf("/network-instances/network-instance[name=DEFAULT]/protocols/protocol[identifier=BGP][name=bgp%d]/bgp/neighbors/neighbor[neighbor-address=192.0.2.%d]/state/session-state", i%rules, i%256)),
+		)
+	}
+	return trie, paths
+}
+
+func BenchmarkProbe(b *testing.B) {
+	for _, rules := range []int{1000, 10000} {
+		trie, paths := benchmarkTrie(b, rules)
+		b.Run(fmt.Sprintf("rules=%d/memoized", rules), func(b *testing.B) {
+			for i := range b.N {
+				trie.Probe(paths[i%len(paths)], "user1", pathzpb.Mode_MODE_READ)
+			}
+		})
+		b.Run(fmt.Sprintf("rules=%d/compiled", rules), func(b *testing.B) {
+			for i := range b.N {
+				trie.probe(paths[i%len(paths)], trie.view("user1", pathzpb.Mode_MODE_READ))
+			}
+		})
+		b.Run(fmt.Sprintf("rules=%d/uncached", rules), func(b *testing.B) {
+			for i := range b.N {
+				trie.probe(paths[i%len(paths)], trie.compile("user1", pathzpb.Mode_MODE_READ))
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package authz is a gNSI authz server, which enforces the gRPC-level
+// authorization policy on the device's gRPC servers.
+package authz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/lemming/gnmi/gnmiclient"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+	"github.com/openconfig/lemming/gnmi/reconciler"
+
+	authzpb "github.com/openconfig/gnsi/authz"
+)
+
+// usernameKey is the metadata key carrying the principal of a call.
+const usernameKey = "username"
+
+type policyData struct {
+	policy    *policy
+	rawPolicy string
+	version   string
+	createdOn uint64
+}
+
+// rpcCounters counts the calls of an RPC accepted and rejected by the policy.
+type rpcCounters struct {
+	accepts    uint64
+	rejects    uint64
+	lastAccept uint64
+	lastReject uint64
+}
+
+// Server implements the authz gRPC server and the interceptors enforcing its
+// policy.
+type Server struct {
+	authzpb.UnimplementedAuthzServer
+	rotationInProgress atomic.Bool
+
+	// mu protects the policy in use, which is either the finalized policy or
+	// the one uploaded by a rotation in progress.
+	mu     sync.RWMutex
+	active *policyData
+
+	// finalized is the last finalized policy.
+	finalized *policyData
+	// onFinalize is called once a rotation is finalized.
+	onFinalize func()
+
+	countersMu sync.Mutex
+	// counters is keyed by the full name of the RPC.
+	counters map[string]*rpcCounters
+	// dirty is set when the state published by the reconciler is stale.
+	dirty bool
+}
+
+// New returns an authz server without a policy, which permits every RPC.
+func New() *Server {
+	return &Server{
+		counters: map[string]*rpcCounters{},
+	}
+}
+
+func (s *Server) policyInUse() *policyData {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active
+}
+
+func (s *Server) setPolicy(p *policyData) {
+	s.mu.Lock()
+	s.active = p
+	s.mu.Unlock()
+	s.countersMu.Lock()
+	s.dirty = true
+	s.countersMu.Unlock()
+}
+
+// Rotate implements the authz Rotate RPC. The uploaded policy is enforced
+// immediately, so that it can be tested before finalizing it, and the
+// previous policy is restored if the stream ends before the rotation is
+// finalized.
+func (s *Server) Rotate(rs authzpb.Authz_RotateServer) error {
+	if !s.rotationInProgress.CompareAndSwap(false, true) {
+		return status.Error(codes.Unavailable, "another rotation is already in progress")
+	}
+	defer s.rotationInProgress.Store(false)
+
+	prev := s.policyInUse()
+	uploaded, finalized := false, false
+	defer func() {
+		if uploaded && !finalized {
+			log.Info("authz rotation not finalized, rolling back the policy")
+			s.setPolicy(prev)
+		}
+	}()
+
+	for {
+		req, err := rs.Recv()
+		if errors.Is(err, io.EOF) {
+			return status.Error(codes.Aborted, "stream closed before the rotation was finalized")
+		}
+		if err != nil {
+			return err
+		}
+		if req.GetAuthzProfileId() != "" {
+			return status.Errorf(codes.Unimplemented, "authz profiles are not supported")
+		}
+		switch r := req.RotateRequest.(type) {
+		case *authzpb.RotateAuthzRequest_UploadRequest:
+			if uploaded {
+				return status.Error(codes.FailedPrecondition, "only a single upload request can be sent per Rotate RPC")
+			}
+			upload := r.UploadRequest
+			if prev != nil && prev.version == upload.GetVersion() && !req.GetForceOverwrite() {
+				return status.Errorf(codes.AlreadyExists, "policy version %q is already in use", upload.GetVersion())
+			}
+			if err := s.setUploadedPolicy(upload); err != nil {
+				return err
+			}
+			uploaded = true
+			if err := rs.Send(&authzpb.RotateAuthzResponse{
+				RotateResponse: &authzpb.RotateAuthzResponse_UploadResponse{UploadResponse: &authzpb.UploadResponse{}},
+			}); err != nil {
+				return err
+			}
+		case *authzpb.RotateAuthzRequest_FinalizeRotation:
+			if !uploaded {
+				return status.Error(codes.FailedPrecondition, "finalize rotation called before upload request")
+			}
+			finalized = true
+			s.finalize()
+			return nil
+		default:
+			return status.Errorf(codes.InvalidArgument, "unknown request type %T", r)
+		}
+	}
+}
+
+// setUploadedPolicy parses the uploaded policy and puts it in use.
+func (s *Server) setUploadedPolicy(upload *authzpb.UploadRequest) error {
+	p, err := parsePolicy(upload.GetPolicy())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid policy: %v", err)
+	}
+	s.setPolicy(&policyData{
+		policy:    p,
+		rawPolicy: upload.GetPolicy(),
+		version:   upload.GetVersion(),
+		createdOn: upload.GetCreatedOn(),
+	})
+	return nil
+}
+
+// Get implements the authz Get RPC.
+func (s *Server) Get(context.Context, *authzpb.GetRequest) (*authzpb.GetResponse, error) {
+	p := s.policyInUse()
+	if p == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no policy has been set")
+	}
+	return &authzpb.GetResponse{
+		Version:   p.version,
+		CreatedOn: p.createdOn,
+		Policy:    p.rawPolicy,
+	}, nil
+}
+
+// Probe implements the authz Probe RPC.
+func (s *Server) Probe(_ context.Context, req *authzpb.ProbeRequest) (*authzpb.ProbeResponse, error) {
+	if req.GetUser() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user not specified")
+	}
+	if req.GetRpc() == "" {
+		return nil, status.Error(codes.InvalidArgument, "rpc not specified")
+	}
+	p := s.policyInUse()
+	if p == nil {
+		return &authzpb.ProbeResponse{Action: authzpb.ProbeResponse_ACTION_PERMIT}, nil
+	}
+	action := authzpb.ProbeResponse_ACTION_DENY
+	if p.policy.evaluate(req.GetUser(), req.GetRpc(), nil) {
+		action = authzpb.ProbeResponse_ACTION_PERMIT
+	}
+	return &authzpb.ProbeResponse{
+		Action:  action,
+		Version: p.version,
+	}, nil
+}
+
+// authorize evaluates the policy in use for the RPC method called with ctx,
+// and counts the outcome.
+func (s *Server) authorize(ctx context.Context, method string) error {
+	p := s.policyInUse()
+	if p == nil {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var user string
+	if users := md.Get(usernameKey); len(users) > 0 {
+		user = users[0]
+	}
+	allowed := p.policy.evaluate(user, method, md)
+	s.count(method, allowed)
+	if !allowed {
+		return status.Errorf(codes.PermissionDenied, "unauthorized RPC request rejected")
+	}
+	return nil
+}
+
+func (s *Server) count(method string, allowed bool) {
+	now := uint64(time.Now().UnixNano())
+	s.countersMu.Lock()
+	defer s.countersMu.Unlock()
+	c, ok := s.counters[method]
+	if !ok {
+		c = &rpcCounters{}
+		s.counters[method] = c
+	}
+	if allowed {
+		c.accepts++
+		c.lastAccept = now
+	} else {
+		c.rejects++
+		c.lastReject = now
+	}
+	s.dirty = true
+}
+
+// Unary is a gRPC unary interceptor rejecting the calls denied by the policy.
+func (s *Server) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := s.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream is a gRPC stream interceptor rejecting the calls denied by the
+// policy.
+func (s *Server) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// grpcServerName returns the name of the gRPC server an RPC method is
+// counted under, which is the protocol of its service, e.g. "gnmi" for
+// "/gnmi.gNMI/Get".
+func grpcServerName(method string) string {
+	pkg, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), ".")
+	if pkg == "p4" {
+		return "p4rt"
+	}
+	return pkg
+}
+
+// publishState publishes the version of the policy and the RPC counters, if
+// they changed since they were last published.
+func (s *Server) publishState(ctx context.Context, c *ygnmi.Client) error {
+	s.countersMu.Lock()
+	if !s.dirty {
+		s.countersMu.Unlock()
+		return nil
+	}
+	s.dirty = false
+	rpcs := map[string]*oc.System_GrpcServer_AuthzPolicyCounters_Rpc{}
+	for method, cnt := range s.counters {
+		rpcs[method] = &oc.System_GrpcServer_AuthzPolicyCounters_Rpc{
+			Name:             ygot.String(method),
+			AccessAccepts:    ygot.Uint64(cnt.accepts),
+			AccessRejects:    ygot.Uint64(cnt.rejects),
+			LastAccessAccept: ygot.Uint64(cnt.lastAccept),
+			LastAccessReject: ygot.Uint64(cnt.lastReject),
+		}
+	}
+	s.countersMu.Unlock()
+
+	batch := &ygnmi.SetBatch{}
+	authPath := ocpath.Root().System().Aaa().Authorization()
+	if p := s.policyInUse(); p != nil {
+		gnmiclient.BatchReplace(batch, authPath.GrpcAuthzPolicyVersion().State(), p.version)
+		gnmiclient.BatchReplace(batch, authPath.GrpcAuthzPolicyCreatedOn().State(), p.createdOn)
+	} else {
+		gnmiclient.BatchDelete(batch, authPath.GrpcAuthzPolicyVersion().State())
+		gnmiclient.BatchDelete(batch, authPath.GrpcAuthzPolicyCreatedOn().State())
+	}
+	for method, rpc := range rpcs {
+		gnmiclient.BatchReplace(batch, ocpath.Root().System().GrpcServer(grpcServerName(method)).AuthzPolicyCounters().Rpc(method).State(), rpc)
+	}
+	if _, err := batch.Set(ctx, c); err != nil {
+		s.countersMu.Lock()
+		s.dirty = true
+		s.countersMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Reconciler returns a reconciler publishing the policy version and the RPC
+// counters under /system.
+func (s *Server) Reconciler() *reconciler.BuiltReconciler {
+	var cancel context.CancelFunc
+	return reconciler.NewBuilder("gnsi-authz").
+		WithStart(func(ctx context.Context, c *ygnmi.Client) error {
+			ctx, cancel = context.WithCancel(ctx)
+			s.countersMu.Lock()
+			s.dirty = true
+			s.countersMu.Unlock()
+			go func() {
+				tick := time.NewTicker(time.Second)
+				defer tick.Stop()
+				for {
+					if err := s.publishState(ctx, c); err != nil && ctx.Err() == nil {
+						log.Warningf("unable to update authz state: %v", err)
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case <-tick.C:
+					}
+				}
+			}()
+			return nil
+		}).
+		WithStop(func(context.Context) error {
+			if cancel != nil {
+				cancel()
+			}
+			return nil
+		}).Build()
+}
@@ -0,0 +1,469 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/openconfig/lemming/gnmi"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+
+	authzpb "github.com/openconfig/gnsi/authz"
+)
+
+const testPolicy = `{
+  "name": "test policy",
+  "deny_rules": [{
+    "name": "deny-bob-rotate",
+    "source": {"principals": ["bob"]},
+    "request": {"paths": ["/gnsi.authz.v1.Authz/Rotate"]}
+  }],
+  "allow_rules": [{
+    "name": "admins",
+    "source": {"principals": ["alice", "bob"]},
+    "request": {"paths": ["/gnsi.authz.v1.Authz/*"]}
+  }, {
+    "name": "probe",
+    "source": {"principals": ["*"]},
+    "request": {
+      "paths": ["/gnsi.authz.v1.Authz/Probe"],
+      "headers": [{"key": "role", "values": ["oper*"]}]
+    }
+  }]
+}`
+
+func upload(version, policy string, force bool) *authzpb.RotateAuthzRequest {
+	return &authzpb.RotateAuthzRequest{
+		RotateRequest: &authzpb.RotateAuthzRequest_UploadRequest{
+			UploadRequest: &authzpb.UploadRequest{
+				Version:   version,
+				CreatedOn: 100,
+				Policy:    policy,
+			},
+		},
+		ForceOverwrite: force,
+	}
+}
+
+var finalize = &authzpb.RotateAuthzRequest{RotateRequest: &authzpb.RotateAuthzRequest_FinalizeRotation{}}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		desc    string
+		policy  string
+		wantErr string
+	}{{
+		desc:   "valid",
+		policy: testPolicy,
+	}, {
+		desc:    "not json",
+		policy:  "policy",
+		wantErr: "failed to unmarshal policy",
+	}, {
+		desc:    "unknown field",
+		policy:  `{"name": "p", "allow_rules": [{"name": "r"}], "audit_logging_options": {}}`,
+		wantErr: "unknown field",
+	}, {
+		desc:    "no name",
+		policy:  `{"allow_rules": [{"name": "r"}]}`,
+		wantErr: `"name" is not present`,
+	}, {
+		desc:    "no allow rules",
+		policy:  `{"name": "p", "deny_rules": [{"name": "r"}]}`,
+		wantErr: `"allow_rules" is not present`,
+	}, {
+		desc:    "unnamed rule",
+		policy:  `{"name": "p", "allow_rules": [{}]}`,
+		wantErr: `"name" is not present`,
+	}, {
+		desc:    "wildcard in the middle",
+		policy:  `{"name": "p", "allow_rules": [{"name": "r", "request": {"paths": ["/gnmi.gNMI*Get"]}}]}`,
+		wantErr: "only supported as a prefix or suffix",
+	}, {
+		desc:    "reserved header",
+		policy:  `{"name": "p", "allow_rules": [{"name": "r", "request": {"headers": [{"key": "grpc-timeout", "values": ["1"]}]}}]}`,
+		wantErr: "unsupported header",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := parsePolicy(tt.policy)
+			if d := errdiff.Check(err, tt.wantErr); d != "" {
+				t.Errorf("parsePolicy() unexpected err: %s", d)
+			}
+		})
+	}
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		desc        string
+		active      string
+		reqs        []*authzpb.RotateAuthzRequest
+		wantErrs    []string
+		wantVersion string
+	}{{
+		desc:     "invalid policy",
+		reqs:     []*authzpb.RotateAuthzRequest{upload("1", "{}", false)},
+		wantErrs: []string{"invalid policy"},
+	}, {
+		desc:     "finalize before upload",
+		reqs:     []*authzpb.RotateAuthzRequest{finalize},
+		wantErrs: []string{"finalize rotation called before upload request"},
+	}, {
+		desc:        "multiple uploads",
+		reqs:        []*authzpb.RotateAuthzRequest{upload("1", testPolicy, false), upload("2", testPolicy, false)},
+		wantErrs:    []string{"", "single upload request"},
+		wantVersion: "",
+	}, {
+		desc:        "version in use",
+		active:      "1",
+		reqs:        []*authzpb.RotateAuthzRequest{upload("1", testPolicy, false)},
+		wantErrs:    []string{"already in use"},
+		wantVersion: "1",
+	}, {
+		desc:        "force overwrite",
+		active:      "1",
+		reqs:        []*authzpb.RotateAuthzRequest{upload("1", testPolicy, true), finalize},
+		wantErrs:    []string{"", "EOF"},
+		wantVersion: "1",
+	}, {
+		desc:     "authz profile",
+		reqs:     []*authzpb.RotateAuthzRequest{{AuthzProfileId: "profile", RotateRequest: upload("1", testPolicy, false).RotateRequest}},
+		wantErrs: []string{"profiles are not supported"},
+	}, {
+		desc:        "success",
+		active:      "1",
+		reqs:        []*authzpb.RotateAuthzRequest{upload("2", testPolicy, false), finalize},
+		wantErrs:    []string{"", "EOF"},
+		wantVersion: "2",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s, client, closeFn := start(t)
+			defer closeFn()
+			if tt.active != "" {
+				p, err := parsePolicy(testPolicy)
+				if err != nil {
+					t.Fatal(err)
+				}
+				s.setPolicy(&policyData{policy: p, rawPolicy: testPolicy, version: tt.active})
+			}
+			rot, err := client.Rotate(metadata.AppendToOutgoingContext(context.Background(), usernameKey, "alice"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, req := range tt.reqs {
+				if err := rot.Send(req); err != nil {
+					t.Fatal(err)
+				}
+				_, err := rot.Recv()
+				if d := errdiff.Check(err, tt.wantErrs[i]); d != "" {
+					t.Errorf("Rotate() unexpected err: %s", d)
+				}
+			}
+			// Wait for the rollback of unfinalized rotations.
+			time.Sleep(10 * time.Millisecond)
+			var gotVersion string
+			if p := s.policyInUse(); p != nil {
+				gotVersion = p.version
+			}
+			if gotVersion != tt.wantVersion {
+				t.Errorf("Rotate() got policy version %q, want %q", gotVersion, tt.wantVersion)
+			}
+		})
+	}
+	t.Run("rollback on stream close", func(t *testing.T) {
+		s, client, closeFn := start(t)
+		defer closeFn()
+		ctx, cancel := context.WithCancel(context.Background())
+		rot, err := client.Rotate(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rot.Send(upload("1", testPolicy, false)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rot.Recv(); err != nil {
+			t.Fatalf("Rotate() unexpected err: %v", err)
+		}
+		if s.policyInUse() == nil {
+			t.Fatalf("Rotate() uploaded policy not in use before finalize")
+		}
+		cancel()
+		time.Sleep(100 * time.Millisecond)
+		if p := s.policyInUse(); p != nil {
+			t.Errorf("Rotate() got policy version %q after stream close, want rollback", p.version)
+		}
+	})
+	t.Run("concurrent rotation", func(t *testing.T) {
+		_, client, closeFn := start(t)
+		defer closeFn()
+		if _, err := client.Rotate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		c, err := client.Rotate(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.Recv()
+		if d := errdiff.Check(err, "another rotation is already in progress"); d != "" {
+			t.Errorf("Rotate() unexpected err: %s", d)
+		}
+	})
+}
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		desc    string
+		policy  bool
+		req     *authzpb.ProbeRequest
+		want    *authzpb.ProbeResponse
+		wantErr string
+	}{{
+		desc:    "no user",
+		req:     &authzpb.ProbeRequest{Rpc: "/gnsi.authz.v1.Authz/Get"},
+		wantErr: "user not specified",
+	}, {
+		desc:    "no rpc",
+		req:     &authzpb.ProbeRequest{User: "alice"},
+		wantErr: "rpc not specified",
+	}, {
+		desc: "no policy",
+		req:  &authzpb.ProbeRequest{User: "alice", Rpc: "/gnmi.gNMI/Set"},
+		want: &authzpb.ProbeResponse{Action: authzpb.ProbeResponse_ACTION_PERMIT},
+	}, {
+		desc:   "allowed",
+		policy: true,
+		req:    &authzpb.ProbeRequest{User: "alice", Rpc: "/gnsi.authz.v1.Authz/Rotate"},
+		want:   &authzpb.ProbeResponse{Action: authzpb.ProbeResponse_ACTION_PERMIT, Version: "1"},
+	}, {
+		desc:   "denied by deny rule",
+		policy: true,
+		req:    &authzpb.ProbeRequest{User: "bob", Rpc: "/gnsi.authz.v1.Authz/Rotate"},
+		want:   &authzpb.ProbeResponse{Action: authzpb.ProbeResponse_ACTION_DENY, Version: "1"},
+	}, {
+		desc:   "denied by default",
+		policy: true,
+		req:    &authzpb.ProbeRequest{User: "alice", Rpc: "/gnmi.gNMI/Set"},
+		want:   &authzpb.ProbeResponse{Action: authzpb.ProbeResponse_ACTION_DENY, Version: "1"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s := New()
+			if tt.policy {
+				p, err := parsePolicy(testPolicy)
+				if err != nil {
+					t.Fatal(err)
+				}
+				s.setPolicy(&policyData{policy: p, rawPolicy: testPolicy, version: "1"})
+			}
+			got, err := s.Probe(context.Background(), tt.req)
+			if d := errdiff.Check(err, tt.wantErr); d != "" {
+				t.Fatalf("Probe() unexpected err: %s", d)
+			}
+			if d := cmp.Diff(tt.want, got, protocmp.Transform()); d != "" {
+				t.Errorf("Probe() unexpected diff (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	s, client, closeFn := start(t)
+	defer closeFn()
+	ctx := context.Background()
+	if _, err := client.Get(ctx, &authzpb.GetRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Get() without policy got err %v, want FailedPrecondition", err)
+	}
+	p, err := parsePolicy(testPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.setPolicy(&policyData{policy: p, rawPolicy: testPolicy, version: "1", createdOn: 100})
+	got, err := client.Get(metadata.AppendToOutgoingContext(ctx, usernameKey, "alice"), &authzpb.GetRequest{})
+	if err != nil {
+		t.Fatalf("Get() unexpected err: %v", err)
+	}
+	want := &authzpb.GetResponse{Version: "1", CreatedOn: 100, Policy: testPolicy}
+	if d := cmp.Diff(want, got, protocmp.Transform()); d != "" {
+		t.Errorf("Get() unexpected diff (-want +got):\n%s", d)
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	s, client, closeFn := start(t)
+	defer closeFn()
+	ctx := context.Background()
+	withUser := func(user string, kv ...string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, append([]string{usernameKey, user}, kv...)...)
+	}
+
+	// Every RPC is permitted until a policy is rotated in.
+	rot, err := client.Rotate(withUser("carol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rot.Send(upload("1", testPolicy, false)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rot.Recv(); err != nil {
+		t.Fatalf("Rotate() unexpected err: %v", err)
+	}
+	if err := rot.Send(finalize); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rot.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("Rotate() got err %v, want EOF after finalize", err)
+	}
+
+	probe := &authzpb.ProbeRequest{User: "alice", Rpc: "/gnmi.gNMI/Get"}
+	tests := []struct {
+		desc     string
+		ctx      context.Context
+		call     func(context.Context) error
+		wantCode codes.Code
+	}{{
+		desc: "allowed unary",
+		ctx:  withUser("alice"),
+		call: func(ctx context.Context) error {
+			_, err := client.Get(ctx, &authzpb.GetRequest{})
+			return err
+		},
+	}, {
+		desc: "unknown principal",
+		ctx:  withUser("carol"),
+		call: func(ctx context.Context) error {
+			_, err := client.Get(ctx, &authzpb.GetRequest{})
+			return err
+		},
+		wantCode: codes.PermissionDenied,
+	}, {
+		desc: "no principal",
+		ctx:  ctx,
+		call: func(ctx context.Context) error {
+			_, err := client.Get(ctx, &authzpb.GetRequest{})
+			return err
+		},
+		wantCode: codes.PermissionDenied,
+	}, {
+		desc: "allowed by header",
+		ctx:  withUser("carol", "role", "operator"),
+		call: func(ctx context.Context) error {
+			_, err := client.Probe(ctx, probe)
+			return err
+		},
+	}, {
+		desc: "header mismatch",
+		ctx:  withUser("carol", "role", "guest"),
+		call: func(ctx context.Context) error {
+			_, err := client.Probe(ctx, probe)
+			return err
+		},
+		wantCode: codes.PermissionDenied,
+	}, {
+		desc: "denied stream",
+		ctx:  withUser("bob"),
+		call: func(ctx context.Context) error {
+			rot, err := client.Rotate(ctx)
+			if err != nil {
+				return err
+			}
+			_, err = rot.Recv()
+			return err
+		},
+		wantCode: codes.PermissionDenied,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := status.Code(tt.call(tt.ctx)); got != tt.wantCode {
+				t.Errorf("call got code %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("counters", func(t *testing.T) {
+		gnmiServer, err := gnmi.New(grpc.NewServer(), "local", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := ygnmi.NewClient(gnmiServer.LocalClient(), ygnmi.WithTarget("local"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.publishState(ctx, c); err != nil {
+			t.Fatalf("publishState() unexpected err: %v", err)
+		}
+		// The state is applied to the cache asynchronously.
+		awaitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		if _, err := ygnmi.Await(awaitCtx, c, ocpath.Root().System().Aaa().Authorization().GrpcAuthzPolicyVersion().State(), "1"); err != nil {
+			t.Fatalf("failed to await policy version %q: %v", "1", err)
+		}
+		wantCounters := map[string][2]uint64{
+			"/gnsi.authz.v1.Authz/Get":    {1, 2},
+			"/gnsi.authz.v1.Authz/Probe":  {1, 1},
+			"/gnsi.authz.v1.Authz/Rotate": {0, 1},
+		}
+		for method, want := range wantCounters {
+			rpc, err := ygnmi.Get(ctx, c, ocpath.Root().System().GrpcServer("gnsi").AuthzPolicyCounters().Rpc(method).State())
+			if err != nil {
+				t.Fatalf("failed to get %s counters: %v", method, err)
+			}
+			if got := [2]uint64{rpc.GetAccessAccepts(), rpc.GetAccessRejects()}; got != want {
+				t.Errorf("%s accepts and rejects got %v, want %v", method, got, want)
+			}
+			if want[1] > 0 && rpc.GetLastAccessReject() == 0 {
+				t.Errorf("%s last access reject not set", method)
+			}
+		}
+	})
+}
+
+func start(t testing.TB) (*Server, authzpb.AuthzClient, func()) {
+	t.Helper()
+	authzServer := New()
+
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(authzServer.Unary), grpc.ChainStreamInterceptor(authzServer.Stream))
+	authzpb.RegisterAuthzServer(s, authzServer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:")
+	if err != nil {
+		t.Fatalf("failed to start listener: %v", err)
+	}
+
+	go s.Serve(l)
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed dial server: %v", err)
+	}
+	return authzServer, authzpb.NewAuthzClient(conn), func() { s.Stop() }
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	authzpb "github.com/openconfig/gnsi/authz"
+)
+
+// OnFinalize sets a function called once a rotation is finalized, while no
+// other rotation can start. It must be set before the server is serving.
+func (s *Server) OnFinalize(f func()) {
+	s.onFinalize = f
+}
+
+// finalize records the policy in use as finalized.
+func (s *Server) finalize() {
+	s.mu.Lock()
+	s.finalized = s.active
+	s.mu.Unlock()
+	if s.onFinalize != nil {
+		s.onFinalize()
+	}
+}
+
+// MarshalState returns the finalized policy, stored as the upload request
+// restoring it, or nil if no policy was finalized.
+func (s *Server) MarshalState() ([]byte, error) {
+	s.mu.RLock()
+	p := s.finalized
+	s.mu.RUnlock()
+	if p == nil {
+		return nil, nil
+	}
+	return proto.Marshal(&authzpb.UploadRequest{
+		Version:   p.version,
+		CreatedOn: p.createdOn,
+		Policy:    p.rawPolicy,
+	})
+}
+
+// UnmarshalState restores and finalizes a policy returned by MarshalState.
+func (s *Server) UnmarshalState(b []byte) error {
+	if b == nil {
+		return nil
+	}
+	upload := &authzpb.UploadRequest{}
+	if err := proto.Unmarshal(b, upload); err != nil {
+		return err
+	}
+	if err := s.setUploadedPolicy(upload); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.finalized = s.active
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// policy is a gRPC authorization policy, in the JSON format defined by
+// https://github.com/grpc/proposal/blob/master/A43-grpc-authorization-api.md.
+type policy struct {
+	Name       string  `json:"name"`
+	DenyRules  []*rule `json:"deny_rules"`
+	AllowRules []*rule `json:"allow_rules"`
+}
+
+type rule struct {
+	Name    string   `json:"name"`
+	Source  *source  `json:"source"`
+	Request *request `json:"request"`
+}
+
+type source struct {
+	Principals []string `json:"principals"`
+}
+
+type request struct {
+	Paths   []string  `json:"paths"`
+	Headers []*header `json:"headers"`
+}
+
+type header struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
+
+// parsePolicy unmarshals and validates a gRPC authorization policy.
+func parsePolicy(s string) (*policy, error) {
+	dec := json.NewDecoder(bytes.NewBufferString(s))
+	dec.DisallowUnknownFields()
+	p := &policy{}
+	if err := dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal policy: %v", err)
+	}
+	if p.Name == "" {
+		return nil, fmt.Errorf(`"name" is not present`)
+	}
+	if len(p.AllowRules) == 0 {
+		return nil, fmt.Errorf(`"allow_rules" is not present`)
+	}
+	for i, r := range p.DenyRules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf(`"deny_rules" %d: %v`, i, err)
+		}
+	}
+	for i, r := range p.AllowRules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf(`"allow_rules" %d: %v`, i, err)
+		}
+	}
+	return p, nil
+}
+
+func (r *rule) validate() error {
+	if r == nil {
+		return fmt.Errorf("rule is empty")
+	}
+	if r.Name == "" {
+		return fmt.Errorf(`"name" is not present`)
+	}
+	for _, pattern := range r.Source.principals() {
+		if err := validatePattern(pattern); err != nil {
+			return fmt.Errorf("principal %q: %v", pattern, err)
+		}
+	}
+	if r.Request == nil {
+		return nil
+	}
+	for _, pattern := range r.Request.Paths {
+		if err := validatePattern(pattern); err != nil {
+			return fmt.Errorf("path %q: %v", pattern, err)
+		}
+	}
+	for _, h := range r.Request.Headers {
+		key := strings.ToLower(h.Key)
+		switch {
+		case key == "":
+			return fmt.Errorf(`header "key" is not present`)
+		case strings.HasPrefix(key, ":"), strings.HasPrefix(key, "grpc-"), key == "host":
+			return fmt.Errorf("unsupported header %q", h.Key)
+		case len(h.Values) == 0:
+			return fmt.Errorf(`header %q "values" is not present`, h.Key)
+		}
+		for _, pattern := range h.Values {
+			if err := validatePattern(pattern); err != nil {
+				return fmt.Errorf("header %q value %q: %v", h.Key, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *source) principals() []string {
+	if s == nil {
+		return nil
+	}
+	return s.Principals
+}
+
+// validatePattern checks that a wildcard is either the whole pattern, its
+// prefix or its suffix.
+func validatePattern(pattern string) error {
+	if pattern == "*" {
+		return nil
+	}
+	body := strings.TrimSuffix(pattern, "*")
+	if strings.HasPrefix(pattern, "*") {
+		body = pattern[1:]
+	}
+	if strings.Contains(body, "*") {
+		return fmt.Errorf("wildcards are only supported as a prefix or suffix")
+	}
+	return nil
+}
+
+// matchPattern reports whether s matches the exact, prefix, suffix or
+// wildcard pattern.
+func matchPattern(pattern, s string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasPrefix(pattern, "*"):
+		return strings.HasSuffix(s, pattern[1:])
+	case strings.HasSuffix(pattern, "*"):
+		return strings.HasPrefix(s, pattern[:len(pattern)-1])
+	default:
+		return pattern == s
+	}
+}
+
+func matchAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the RPC method called by the principal with the
+// metadata md is matched by the rule. Empty sources and requests match
+// every call.
+func (r *rule) matches(principal, method string, md metadata.MD) bool {
+	if principals := r.Source.principals(); len(principals) > 0 && !matchAny(principals, principal) {
+		return false
+	}
+	if r.Request == nil {
+		return true
+	}
+	if len(r.Request.Paths) > 0 && !matchAny(r.Request.Paths, method) {
+		return false
+	}
+	for _, h := range r.Request.Headers {
+		found := false
+		for _, v := range md.Get(h.Key) {
+			if matchAny(h.Values, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluate returns whether the policy permits the principal to call the RPC
+// method with the metadata md. Deny rules take precedence over allow rules,
+// and calls matched by no rule are denied.
+func (p *policy) evaluate(principal, method string, md metadata.MD) bool {
+	for _, r := range p.DenyRules {
+		if r.matches(principal, method, md) {
+			return false
+		}
+	}
+	for _, r := range p.AllowRules {
+		if r.matches(principal, method, md) {
+			return true
+		}
+	}
+	return false
+}
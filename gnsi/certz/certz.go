@@ -0,0 +1,671 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package certz is a gNSI certz server, which manages the SSL profiles used
+// by the TLS credentials of the device's gRPC servers.
+package certz
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/openconfig/lemming/gnmi/gnmiclient"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+	"github.com/openconfig/lemming/gnmi/reconciler"
+
+	certzpb "github.com/openconfig/gnsi/certz"
+)
+
+// DefaultProfile is the SSL profile used by the gRPC servers which are not
+// configured with a certificate-id. It always exists.
+const DefaultProfile = "system_default_profile"
+
+// meta is the versioning information of an entity, reported as-is in
+// telemetry.
+type meta struct {
+	version   string
+	createdOn uint64
+}
+
+// profile is an SSL profile. Profiles are never modified once stored, a
+// rotation replaces them.
+type profile struct {
+	cert            *tls.Certificate
+	certMeta        meta
+	trustBundle     []*x509.Certificate
+	trustBundleMeta meta
+	crls            []*x509.RevocationList
+	crlMeta         meta
+	authPolicy      *anypb.Any
+	authPolicyMeta  meta
+}
+
+// connCounters counts the connections accepted and rejected by a gRPC server.
+type connCounters struct {
+	accepts    uint64
+	rejects    uint64
+	lastAccept uint64
+	lastReject uint64
+}
+
+// Server implements the certz gRPC server and the TLS credentials serving
+// its SSL profiles.
+type Server struct {
+	certzpb.UnimplementedCertzServer
+	// rpcInProgress is set while an RPC modifying the profiles is running,
+	// only one is allowed at a time.
+	rpcInProgress atomic.Bool
+
+	// mu protects the profiles, which are either finalized or uploaded by a
+	// rotation in progress, and the bindings of the gRPC servers to them.
+	mu       sync.RWMutex
+	profiles map[string]*profile
+	// bindings is keyed by the name of a gRPC server, and contains the
+	// certificate-id it is configured with.
+	bindings map[string]string
+	// requireClientCert is set if the clients must present a certificate
+	// verified by the trust bundle of the profile.
+	requireClientCert bool
+	// finalized contains the profiles as of the last finalized RPC.
+	finalized map[string]*profile
+	// onFinalize is called once an RPC modifying the profiles is finalized.
+	onFinalize func()
+
+	countersMu sync.Mutex
+	// servers is keyed by the name of the gRPC servers using the credentials
+	// of the server.
+	servers map[string]*connCounters
+	// dirty is set when the state published by the reconciler is stale.
+	dirty bool
+}
+
+// New returns a certz server whose default profile serves cert, which may be
+// nil if the device does not use TLS.
+func New(cert *tls.Certificate) *Server {
+	p := &profile{cert: cert}
+	return &Server{
+		profiles:  map[string]*profile{DefaultProfile: p},
+		finalized: map[string]*profile{DefaultProfile: p},
+		bindings:  map[string]string{},
+		servers:   map[string]*connCounters{},
+	}
+}
+
+// RequireClientCerts requires the clients to present a certificate verified
+// by the trust bundle of the profile in use. The trust bundle, if not empty,
+// is the initial trust bundle of the default profile. Connections to servers
+// using a profile without a trust bundle are rejected.
+func (s *Server) RequireClientCerts(trustBundle []*x509.Certificate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requireClientCert = true
+	if len(trustBundle) > 0 {
+		p := *s.profiles[DefaultProfile]
+		p.trustBundle = trustBundle
+		s.profiles[DefaultProfile] = &p
+		s.finalized[DefaultProfile] = &p
+	}
+}
+
+func (s *Server) getProfile(id string) *profile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.profiles[id]
+}
+
+func (s *Server) setProfile(id string, p *profile) {
+	s.mu.Lock()
+	s.profiles[id] = p
+	s.mu.Unlock()
+	s.markDirty()
+}
+
+func (s *Server) markDirty() {
+	s.countersMu.Lock()
+	s.dirty = true
+	s.countersMu.Unlock()
+}
+
+// profileOf returns the ID of the profile used by the gRPC server, which is
+// the default profile unless it is configured with an existing profile.
+func (s *Server) profileOf(server string) (string, *profile) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if id, ok := s.bindings[server]; ok {
+		if p, ok := s.profiles[id]; ok {
+			return id, p
+		}
+	}
+	return DefaultProfile, s.profiles[DefaultProfile]
+}
+
+// Rotate implements the certz Rotate RPC. The uploaded entities are used
+// immediately, so that they can be tested before finalizing the rotation,
+// and the previous profile is restored if the stream ends before the
+// rotation is finalized.
+func (s *Server) Rotate(rs certzpb.Certz_RotateServer) error {
+	if !s.rpcInProgress.CompareAndSwap(false, true) {
+		return status.Error(codes.Unavailable, "another certz RPC is already in progress")
+	}
+	defer s.rpcInProgress.Store(false)
+
+	var (
+		id     string
+		prev   *profile
+		csrKey crypto.Signer
+	)
+	uploaded, finalized := false, false
+	defer func() {
+		if uploaded && !finalized {
+			log.Infof("certz rotation of profile %q not finalized, rolling back", id)
+			s.setProfile(id, prev)
+		}
+	}()
+
+	for {
+		req, err := rs.Recv()
+		if errors.Is(err, io.EOF) {
+			return status.Error(codes.Aborted, "stream closed before the rotation was finalized")
+		}
+		if err != nil {
+			return err
+		}
+		switch reqID := req.GetSslProfileId(); {
+		case id == "":
+			id = reqID
+			if id == "" {
+				id = DefaultProfile
+			}
+			if prev = s.getProfile(id); prev == nil {
+				return status.Errorf(codes.NotFound, "SSL profile %q does not exist", id)
+			}
+		case reqID != "" && reqID != id:
+			return status.Errorf(codes.InvalidArgument, "SSL profile changed from %q to %q during the rotation", id, reqID)
+		}
+
+		switch r := req.RotateRequest.(type) {
+		case *certzpb.RotateCertificateRequest_GenerateCsr:
+			if uploaded {
+				return status.Error(codes.FailedPrecondition, "a CSR can only be generated before the upload request")
+			}
+			key, csr, err := generateCSR(r.GenerateCsr.GetParams())
+			if err != nil {
+				return status.Errorf(codes.InvalidArgument, "cannot generate CSR: %v", err)
+			}
+			csrKey = key
+			if err := rs.Send(&certzpb.RotateCertificateResponse{
+				RotateResponse: &certzpb.RotateCertificateResponse_GeneratedCsr{
+					GeneratedCsr: &certzpb.GenerateCSRResponse{
+						CertificateSigningRequest: &certzpb.CertificateSigningRequest{
+							Type:                      certzpb.CertificateType_CERTIFICATE_TYPE_X509,
+							Encoding:                  certzpb.CertificateEncoding_CERTIFICATE_ENCODING_PEM,
+							CertificateSigningRequest: csr,
+						},
+					},
+				},
+			}); err != nil {
+				return err
+			}
+		case *certzpb.RotateCertificateRequest_Certificates:
+			next, err := s.upload(s.getProfile(id), r.Certificates.GetEntities(), req.GetForceOverwrite(), csrKey)
+			if err != nil {
+				return err
+			}
+			s.setProfile(id, next)
+			uploaded = true
+			if err := rs.Send(&certzpb.RotateCertificateResponse{
+				RotateResponse: &certzpb.RotateCertificateResponse_Certificates{Certificates: &certzpb.UploadResponse{}},
+			}); err != nil {
+				return err
+			}
+		case *certzpb.RotateCertificateRequest_FinalizeRotation:
+			if !uploaded {
+				return status.Error(codes.FailedPrecondition, "finalize rotation called before upload request")
+			}
+			finalized = true
+			s.finalize()
+			return nil
+		default:
+			return status.Errorf(codes.InvalidArgument, "unknown request type %T", r)
+		}
+	}
+}
+
+// checkVersion checks that the version of an uploaded entity can replace the
+// version in use.
+func checkVersion(e *certzpb.Entity, cur meta, force bool) error {
+	if e.GetVersion() == "" {
+		return status.Error(codes.InvalidArgument, "entity version not specified")
+	}
+	if e.GetVersion() == cur.version && !force {
+		return status.Errorf(codes.AlreadyExists, "version %q is already in use", e.GetVersion())
+	}
+	return nil
+}
+
+// upload returns the profile p with the uploaded entities applied.
+func (s *Server) upload(p *profile, entities []*certzpb.Entity, force bool, csrKey crypto.Signer) (*profile, error) {
+	if len(entities) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "upload request contains no entity")
+	}
+	next := *p
+	seen := map[certzpb.ExistingEntity_EntityType]bool{}
+	for _, e := range entities {
+		m := meta{version: e.GetVersion(), createdOn: e.GetCreatedOn()}
+		var typ certzpb.ExistingEntity_EntityType
+		switch ent := e.GetEntity().(type) {
+		case *certzpb.Entity_CertificateChain:
+			typ = certzpb.ExistingEntity_ENTITY_TYPE_CERTIFICATE_CHAIN
+			if err := checkVersion(e, p.certMeta, force); err != nil {
+				return nil, err
+			}
+			cert, err := keyPair(ent.CertificateChain, csrKey)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid certificate chain: %v", err)
+			}
+			next.cert, next.certMeta = cert, m
+		case *certzpb.Entity_TrustBundle, *certzpb.Entity_TrustBundlePcks7, *certzpb.Entity_TrustBundlePkcs7:
+			typ = certzpb.ExistingEntity_ENTITY_TYPE_TRUST_BUNDLE
+			if err := checkVersion(e, p.trustBundleMeta, force); err != nil {
+				return nil, err
+			}
+			var certs []*x509.Certificate
+			var err error
+			switch ent := ent.(type) {
+			case *certzpb.Entity_TrustBundle:
+				certs, err = parseChain(ent.TrustBundle)
+			case *certzpb.Entity_TrustBundlePcks7:
+				certs, err = parsePKCS7(ent.TrustBundlePcks7.GetPkcs7Block())
+			case *certzpb.Entity_TrustBundlePkcs7:
+				certs, err = parsePKCS7(ent.TrustBundlePkcs7.GetPkcs7Block())
+			}
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid trust bundle: %v", err)
+			}
+			next.trustBundle, next.trustBundleMeta = certs, m
+		case *certzpb.Entity_CertificateRevocationListBundle:
+			typ = certzpb.ExistingEntity_ENTITY_TYPE_CERTIFICATE_REVOCATION_LIST_BUNDLE
+			if err := checkVersion(e, p.crlMeta, force); err != nil {
+				return nil, err
+			}
+			crls, err := parseCRLs(ent.CertificateRevocationListBundle)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid CRL bundle: %v", err)
+			}
+			next.crls, next.crlMeta = crls, m
+		case *certzpb.Entity_AuthenticationPolicy:
+			typ = certzpb.ExistingEntity_ENTITY_TYPE_AUTHENTICATION_POLICY
+			if err := checkVersion(e, p.authPolicyMeta, force); err != nil {
+				return nil, err
+			}
+			if ent.AuthenticationPolicy.GetSerialized() == nil {
+				return nil, status.Error(codes.InvalidArgument, "authentication policy not specified")
+			}
+			next.authPolicy, next.authPolicyMeta = ent.AuthenticationPolicy.GetSerialized(), m
+		case *certzpb.Entity_ExistingEntity:
+			typ = ent.ExistingEntity.GetEntityType()
+			srcID := ent.ExistingEntity.GetSslProfileId()
+			if srcID == "" {
+				return nil, status.Error(codes.InvalidArgument, "existing entity SSL profile not specified")
+			}
+			src := s.getProfile(srcID)
+			if src == nil {
+				return nil, status.Errorf(codes.NotFound, "SSL profile %q does not exist", srcID)
+			}
+			switch typ {
+			case certzpb.ExistingEntity_ENTITY_TYPE_CERTIFICATE_CHAIN:
+				next.cert, next.certMeta = src.cert, src.certMeta
+			case certzpb.ExistingEntity_ENTITY_TYPE_TRUST_BUNDLE:
+				next.trustBundle, next.trustBundleMeta = src.trustBundle, src.trustBundleMeta
+			case certzpb.ExistingEntity_ENTITY_TYPE_CERTIFICATE_REVOCATION_LIST_BUNDLE:
+				next.crls, next.crlMeta = src.crls, src.crlMeta
+			case certzpb.ExistingEntity_ENTITY_TYPE_AUTHENTICATION_POLICY:
+				next.authPolicy, next.authPolicyMeta = src.authPolicy, src.authPolicyMeta
+			default:
+				return nil, status.Errorf(codes.InvalidArgument, "unsupported existing entity type %v", typ)
+			}
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown entity type %T", ent)
+		}
+		if seen[typ] {
+			return nil, status.Errorf(codes.InvalidArgument, "entity type %v uploaded more than once", typ)
+		}
+		seen[typ] = true
+	}
+	return &next, nil
+}
+
+// CanGenerateCSR implements the certz CanGenerateCSR RPC.
+func (s *Server) CanGenerateCSR(_ context.Context, req *certzpb.CanGenerateCSRRequest) (*certzpb.CanGenerateCSRResponse, error) {
+	if req.GetParams() == nil {
+		return nil, status.Error(codes.InvalidArgument, "CSR params not specified")
+	}
+	_, _, err := csrTemplate(req.GetParams())
+	return &certzpb.CanGenerateCSRResponse{CanGenerate: err == nil}, nil
+}
+
+// AddProfile implements the certz AddProfile RPC.
+func (s *Server) AddProfile(_ context.Context, req *certzpb.AddProfileRequest) (*certzpb.AddProfileResponse, error) {
+	if !s.rpcInProgress.CompareAndSwap(false, true) {
+		return nil, status.Error(codes.Unavailable, "another certz RPC is already in progress")
+	}
+	defer s.rpcInProgress.Store(false)
+
+	id := req.GetSslProfileId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "SSL profile not specified")
+	}
+	s.mu.Lock()
+	if _, ok := s.profiles[id]; ok {
+		s.mu.Unlock()
+		return nil, status.Errorf(codes.AlreadyExists, "SSL profile %q already exists", id)
+	}
+	s.profiles[id] = &profile{}
+	s.mu.Unlock()
+	s.finalize()
+	return &certzpb.AddProfileResponse{}, nil
+}
+
+// DeleteProfile implements the certz DeleteProfile RPC.
+func (s *Server) DeleteProfile(_ context.Context, req *certzpb.DeleteProfileRequest) (*certzpb.DeleteProfileResponse, error) {
+	if !s.rpcInProgress.CompareAndSwap(false, true) {
+		return nil, status.Error(codes.Unavailable, "another certz RPC is already in progress")
+	}
+	defer s.rpcInProgress.Store(false)
+
+	id := req.GetSslProfileId()
+	switch id {
+	case "":
+		return nil, status.Error(codes.InvalidArgument, "SSL profile not specified")
+	case DefaultProfile:
+		return nil, status.Errorf(codes.InvalidArgument, "SSL profile %q cannot be deleted", id)
+	}
+	if err := s.deleteProfile(id); err != nil {
+		return nil, err
+	}
+	s.finalize()
+	return &certzpb.DeleteProfileResponse{}, nil
+}
+
+func (s *Server) deleteProfile(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.profiles[id]; !ok {
+		return status.Errorf(codes.NotFound, "SSL profile %q does not exist", id)
+	}
+	for server, bound := range s.bindings {
+		if bound == id {
+			return status.Errorf(codes.FailedPrecondition, "SSL profile %q is used by gRPC server %q", id, server)
+		}
+	}
+	delete(s.profiles, id)
+	return nil
+}
+
+// GetProfileList implements the certz GetProfileList RPC.
+func (s *Server) GetProfileList(context.Context, *certzpb.GetProfileListRequest) (*certzpb.GetProfileListResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	resp := &certzpb.GetProfileListResponse{}
+	for id := range s.profiles {
+		resp.SslProfileIds = append(resp.SslProfileIds, id)
+	}
+	sort.Strings(resp.SslProfileIds)
+	return resp, nil
+}
+
+// tlsConfig returns the TLS config of a handshake of the gRPC server, built
+// from the profile it uses at the time of the handshake.
+func (s *Server) tlsConfig(server string) (*tls.Config, error) {
+	id, p := s.profileOf(server)
+	if p.cert == nil {
+		return nil, fmt.Errorf("SSL profile %q has no certificate", id)
+	}
+	s.mu.RLock()
+	require := s.requireClientCert
+	s.mu.RUnlock()
+	if require && len(p.trustBundle) == 0 {
+		return nil, fmt.Errorf("SSL profile %q has no trust bundle to verify the client certificates", id)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{*p.cert},
+		NextProtos:   []string{"h2"},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if len(p.trustBundle) > 0 {
+		pool := x509.NewCertPool()
+		for _, c := range p.trustBundle {
+			pool.AddCert(c)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+		if require {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	if crls := p.crls; len(crls) > 0 {
+		cfg.VerifyPeerCertificate = func(_ [][]byte, chains [][]*x509.Certificate) error {
+			return checkRevoked(crls, chains)
+		}
+	}
+	return cfg, nil
+}
+
+// checkRevoked returns an error if a certificate of the verified chains is
+// revoked by one of the CRLs.
+func checkRevoked(crls []*x509.RevocationList, chains [][]*x509.Certificate) error {
+	for _, chain := range chains {
+		for _, cert := range chain {
+			for _, crl := range crls {
+				if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) {
+					continue
+				}
+				for _, r := range crl.RevokedCertificateEntries {
+					if r.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+						return fmt.Errorf("certificate %q is revoked", cert.Subject)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Credentials returns TLS credentials for a listener shared by the named
+// gRPC servers. Each handshake uses the profile the first server is bound to
+// at that time, so that rotations apply to new connections without
+// restarting the servers. The connections are counted for every server.
+func (s *Server) Credentials(servers ...string) credentials.TransportCredentials {
+	s.countersMu.Lock()
+	for _, name := range servers {
+		s.servers[name] = &connCounters{}
+	}
+	s.dirty = true
+	s.countersMu.Unlock()
+	return &countingCreds{
+		TransportCredentials: credentials.NewTLS(&tls.Config{
+			GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+				return s.tlsConfig(servers[0])
+			},
+		}),
+		s:       s,
+		servers: servers,
+	}
+}
+
+// countingCreds counts the handshakes of the connections to gRPC servers.
+type countingCreds struct {
+	credentials.TransportCredentials
+	s       *Server
+	servers []string
+}
+
+func (c *countingCreds) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	sc, info, err := c.TransportCredentials.ServerHandshake(conn)
+	c.s.count(c.servers, err == nil)
+	return sc, info, err
+}
+
+func (c *countingCreds) Clone() credentials.TransportCredentials {
+	return &countingCreds{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		s:                    c.s,
+		servers:              c.servers,
+	}
+}
+
+func (s *Server) count(servers []string, accepted bool) {
+	now := uint64(time.Now().UnixNano())
+	s.countersMu.Lock()
+	defer s.countersMu.Unlock()
+	for _, name := range servers {
+		c := s.servers[name]
+		if accepted {
+			c.accepts++
+			c.lastAccept = now
+		} else {
+			c.rejects++
+			c.lastReject = now
+		}
+	}
+	s.dirty = true
+}
+
+// setBindings sets the profiles the gRPC servers are configured with.
+func (s *Server) setBindings(servers map[string]*oc.System_GrpcServer) {
+	bindings := map[string]string{}
+	for name, srv := range servers {
+		if id := srv.GetCertificateId(); id != "" {
+			bindings[name] = id
+		}
+	}
+	s.mu.Lock()
+	s.bindings = bindings
+	s.mu.Unlock()
+	s.markDirty()
+}
+
+// batchMeta sets the version leaves of an entity, or deletes them if the
+// entity was never uploaded.
+func batchMeta(batch *ygnmi.SetBatch, m meta, version ygnmi.SingletonQuery[string], createdOn ygnmi.SingletonQuery[uint64]) {
+	if m.version == "" {
+		gnmiclient.BatchDelete(batch, version)
+		gnmiclient.BatchDelete(batch, createdOn)
+		return
+	}
+	gnmiclient.BatchReplace(batch, version, m.version)
+	gnmiclient.BatchReplace(batch, createdOn, m.createdOn)
+}
+
+// publishState publishes the profile used by each gRPC server, the versions
+// of its entities and the connection counters, if they changed since they
+// were last published.
+func (s *Server) publishState(ctx context.Context, c *ygnmi.Client) error {
+	s.countersMu.Lock()
+	if !s.dirty {
+		s.countersMu.Unlock()
+		return nil
+	}
+	s.dirty = false
+	counters := map[string]*oc.System_GrpcServer_Counters{}
+	for name, cnt := range s.servers {
+		counters[name] = &oc.System_GrpcServer_Counters{
+			ConnectionAccepts:    ygot.Uint64(cnt.accepts),
+			ConnectionRejects:    ygot.Uint64(cnt.rejects),
+			LastConnectionAccept: ygot.Uint64(cnt.lastAccept),
+			LastConnectionReject: ygot.Uint64(cnt.lastReject),
+		}
+	}
+	s.countersMu.Unlock()
+
+	batch := &ygnmi.SetBatch{}
+	for name, cnt := range counters {
+		id, p := s.profileOf(name)
+		srvPath := ocpath.Root().System().GrpcServer(name)
+		gnmiclient.BatchReplace(batch, srvPath.SslProfileId().State(), id)
+		gnmiclient.BatchReplace(batch, srvPath.Counters().State(), cnt)
+		batchMeta(batch, p.certMeta, srvPath.CertificateVersion().State(), srvPath.CertificateCreatedOn().State())
+		batchMeta(batch, p.trustBundleMeta, srvPath.CaTrustBundleVersion().State(), srvPath.CaTrustBundleCreatedOn().State())
+		batchMeta(batch, p.crlMeta, srvPath.CertificateRevocationListBundleVersion().State(), srvPath.CertificateRevocationListBundleCreatedOn().State())
+		batchMeta(batch, p.authPolicyMeta, srvPath.AuthenticationPolicyVersion().State(), srvPath.AuthenticationPolicyCreatedOn().State())
+	}
+	if _, err := batch.Set(ctx, c); err != nil {
+		s.markDirty()
+		return err
+	}
+	return nil
+}
+
+// Reconciler returns a reconciler binding the gRPC servers to the profiles
+// configured as their certificate-id, and publishing the certz state of the
+// servers using the credentials of the server.
+func (s *Server) Reconciler() *reconciler.BuiltReconciler {
+	var cancel context.CancelFunc
+	return reconciler.NewBuilder("gnsi-certz").
+		WithStart(func(ctx context.Context, c *ygnmi.Client) error {
+			ctx, cancel = context.WithCancel(ctx)
+			s.markDirty()
+			w := ygnmi.Watch(ctx, c, ocpath.Root().System().GrpcServerMap().Config(), func(v *ygnmi.Value[map[string]*oc.System_GrpcServer]) error {
+				servers, _ := v.Val()
+				s.setBindings(servers)
+				return ygnmi.Continue
+			})
+			go func() {
+				if _, err := w.Await(); err != nil && ctx.Err() == nil {
+					log.Errorf("certz gRPC server watch error: %v", err)
+				}
+			}()
+			go func() {
+				tick := time.NewTicker(time.Second)
+				defer tick.Stop()
+				for {
+					if err := s.publishState(ctx, c); err != nil && ctx.Err() == nil {
+						log.Warningf("unable to update certz state: %v", err)
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case <-tick.C:
+					}
+				}
+			}()
+			return nil
+		}).
+		WithStop(func(context.Context) error {
+			if cancel != nil {
+				cancel()
+			}
+			return nil
+		}).Build()
+}
@@ -0,0 +1,751 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certz
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/lemming/gnmi"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+
+	certzpb "github.com/openconfig/gnsi/certz"
+)
+
+// testCA is a CA issuing certificates for the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newKey(t testing.TB) *ecdsa.PrivateKey {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func newCA(t testing.TB, name string) *testCA {
+	t.Helper()
+	key := newKey(t)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+// issue returns a certificate for the server "lemming" and clients.
+func (ca *testCA) issue(t testing.TB, serial int64, pub crypto.PublicKey) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "lemming"},
+		DNSNames:     []string{"lemming"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, pub, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func (ca *testCA) crl(t testing.TB, serials ...int64) []byte {
+	t.Helper()
+	tmpl := &x509.RevocationList{Number: big.NewInt(1), ThisUpdate: time.Now().Add(-time.Hour), NextUpdate: time.Now().Add(time.Hour)}
+	for _, s := range serials {
+		tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries, x509.RevocationListEntry{SerialNumber: big.NewInt(s), RevocationTime: time.Now()})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, tmpl, ca.cert, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
+
+func pemCert(c *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})
+}
+
+func pemKey(t testing.TB, k crypto.Signer) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func x509Cert(c *x509.Certificate) *certzpb.Certificate {
+	return &certzpb.Certificate{
+		Type:            certzpb.CertificateType_CERTIFICATE_TYPE_X509,
+		Encoding:        certzpb.CertificateEncoding_CERTIFICATE_ENCODING_PEM,
+		CertificateType: &certzpb.Certificate_RawCertificate{RawCertificate: pemCert(c)},
+	}
+}
+
+func certEntity(t testing.TB, version string, c *x509.Certificate, k crypto.Signer) *certzpb.Entity {
+	cert := x509Cert(c)
+	if k != nil {
+		cert.PrivateKeyType = &certzpb.Certificate_RawPrivateKey{RawPrivateKey: pemKey(t, k)}
+	} else {
+		cert.PrivateKeyType = &certzpb.Certificate_KeySource_{KeySource: certzpb.Certificate_KEY_SOURCE_GENERATED}
+	}
+	return &certzpb.Entity{
+		Version:   version,
+		CreatedOn: 100,
+		Entity:    &certzpb.Entity_CertificateChain{CertificateChain: &certzpb.CertificateChain{Certificate: cert}},
+	}
+}
+
+func trustEntity(version string, c *x509.Certificate) *certzpb.Entity {
+	return &certzpb.Entity{
+		Version:   version,
+		CreatedOn: 200,
+		Entity:    &certzpb.Entity_TrustBundle{TrustBundle: &certzpb.CertificateChain{Certificate: x509Cert(c)}},
+	}
+}
+
+func crlEntity(version string, crl []byte) *certzpb.Entity {
+	return &certzpb.Entity{
+		Version:   version,
+		CreatedOn: 300,
+		Entity: &certzpb.Entity_CertificateRevocationListBundle{
+			CertificateRevocationListBundle: &certzpb.CertificateRevocationListBundle{
+				CertificateRevocationLists: []*certzpb.CertificateRevocationList{{
+					Type:                      certzpb.CertificateType_CERTIFICATE_TYPE_X509,
+					Encoding:                  certzpb.CertificateEncoding_CERTIFICATE_ENCODING_PEM,
+					CertificateRevocationList: crl,
+					Id:                        "crl",
+				}},
+			},
+		},
+	}
+}
+
+func upload(profile string, force bool, entities ...*certzpb.Entity) *certzpb.RotateCertificateRequest {
+	return &certzpb.RotateCertificateRequest{
+		ForceOverwrite: force,
+		SslProfileId:   profile,
+		RotateRequest: &certzpb.RotateCertificateRequest_Certificates{
+			Certificates: &certzpb.UploadRequest{Entities: entities},
+		},
+	}
+}
+
+var finalize = &certzpb.RotateCertificateRequest{RotateRequest: &certzpb.RotateCertificateRequest_FinalizeRotation{}}
+
+func TestRotate(t *testing.T) {
+	ca := newCA(t, "ca")
+	key := newKey(t)
+	cert := ca.issue(t, 2, key.Public())
+
+	tests := []struct {
+		desc         string
+		reqs         []*certzpb.RotateCertificateRequest
+		wantErrs     []string
+		wantVersions meta
+	}{{
+		desc:         "finalize before upload",
+		reqs:         []*certzpb.RotateCertificateRequest{finalize},
+		wantErrs:     []string{"finalize rotation called before upload request"},
+		wantVersions: meta{version: "1"},
+	}, {
+		desc:         "unknown profile",
+		reqs:         []*certzpb.RotateCertificateRequest{upload("unknown", false, certEntity(t, "2", cert, key))},
+		wantErrs:     []string{"does not exist"},
+		wantVersions: meta{version: "1"},
+	}, {
+		desc:         "key mismatch",
+		reqs:         []*certzpb.RotateCertificateRequest{upload("", false, certEntity(t, "2", cert, newKey(t)))},
+		wantErrs:     []string{"private key does not match the certificate"},
+		wantVersions: meta{version: "1"},
+	}, {
+		desc:         "no generated key",
+		reqs:         []*certzpb.RotateCertificateRequest{upload("", false, certEntity(t, "2", cert, nil))},
+		wantErrs:     []string{"no CSR was generated"},
+		wantVersions: meta{version: "1"},
+	}, {
+		desc:         "missing version",
+		reqs:         []*certzpb.RotateCertificateRequest{upload("", false, trustEntity("", ca.cert))},
+		wantErrs:     []string{"version not specified"},
+		wantVersions: meta{version: "1"},
+	}, {
+		desc:         "duplicate entity",
+		reqs:         []*certzpb.RotateCertificateRequest{upload("", false, trustEntity("2", ca.cert), trustEntity("3", ca.cert))},
+		wantErrs:     []string{"uploaded more than once"},
+		wantVersions: meta{version: "1"},
+	}, {
+		desc:         "version in use",
+		reqs:         []*certzpb.RotateCertificateRequest{upload("", false, certEntity(t, "1", cert, key))},
+		wantErrs:     []string{"already in use"},
+		wantVersions: meta{version: "1"},
+	}, {
+		desc:         "force overwrite",
+		reqs:         []*certzpb.RotateCertificateRequest{upload("", true, certEntity(t, "1", cert, key)), finalize},
+		wantErrs:     []string{"", "EOF"},
+		wantVersions: meta{version: "1", createdOn: 100},
+	}, {
+		desc: "profile changed",
+		reqs: []*certzpb.RotateCertificateRequest{
+			upload("", false, certEntity(t, "2", cert, key)),
+			upload("other", false, trustEntity("2", ca.cert)),
+		},
+		wantErrs:     []string{"", "SSL profile changed"},
+		wantVersions: meta{version: "1"},
+	}, {
+		desc:         "success",
+		reqs:         []*certzpb.RotateCertificateRequest{upload("", false, certEntity(t, "2", cert, key), trustEntity("2", ca.cert)), finalize},
+		wantErrs:     []string{"", "EOF"},
+		wantVersions: meta{version: "2", createdOn: 100},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s, client, closeFn := start(t, nil)
+			defer closeFn()
+			s.setProfile(DefaultProfile, &profile{certMeta: meta{version: "1"}})
+			rot, err := client.Rotate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, req := range tt.reqs {
+				if err := rot.Send(req); err != nil {
+					t.Fatal(err)
+				}
+				_, err := rot.Recv()
+				if d := errdiff.Check(err, tt.wantErrs[i]); d != "" {
+					t.Errorf("Rotate() unexpected err: %s", d)
+				}
+			}
+			// Wait for the rollback of unfinalized rotations.
+			time.Sleep(10 * time.Millisecond)
+			if got := s.getProfile(DefaultProfile).certMeta; got != tt.wantVersions {
+				t.Errorf("Rotate() got certificate version %+v, want %+v", got, tt.wantVersions)
+			}
+		})
+	}
+	t.Run("rollback on stream close", func(t *testing.T) {
+		s, client, closeFn := start(t, nil)
+		defer closeFn()
+		ctx, cancel := context.WithCancel(context.Background())
+		rot, err := client.Rotate(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rot.Send(upload("", false, certEntity(t, "1", cert, key))); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rot.Recv(); err != nil {
+			t.Fatalf("Rotate() unexpected err: %v", err)
+		}
+		if s.getProfile(DefaultProfile).cert == nil {
+			t.Fatalf("Rotate() uploaded certificate not in use before finalize")
+		}
+		cancel()
+		time.Sleep(100 * time.Millisecond)
+		if p := s.getProfile(DefaultProfile); p.cert != nil {
+			t.Errorf("Rotate() got certificate version %q after stream close, want rollback", p.certMeta.version)
+		}
+	})
+	t.Run("existing entity", func(t *testing.T) {
+		s, client, closeFn := start(t, nil)
+		defer closeFn()
+		s.setProfile(DefaultProfile, &profile{trustBundle: []*x509.Certificate{ca.cert}, trustBundleMeta: meta{version: "tb"}})
+		if _, err := client.AddProfile(context.Background(), &certzpb.AddProfileRequest{SslProfileId: "other"}); err != nil {
+			t.Fatal(err)
+		}
+		rot, err := client.Rotate(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		existing := &certzpb.Entity{Entity: &certzpb.Entity_ExistingEntity{ExistingEntity: &certzpb.ExistingEntity{
+			SslProfileId: DefaultProfile,
+			EntityType:   certzpb.ExistingEntity_ENTITY_TYPE_TRUST_BUNDLE,
+		}}}
+		for _, req := range []*certzpb.RotateCertificateRequest{upload("other", false, existing), finalize} {
+			if err := rot.Send(req); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := rot.Recv(); err != nil {
+			t.Fatalf("Rotate() unexpected err: %v", err)
+		}
+		if got := s.getProfile("other").trustBundleMeta.version; got != "tb" {
+			t.Errorf("Rotate() got trust bundle version %q, want %q", got, "tb")
+		}
+	})
+}
+
+func TestGenerateCSR(t *testing.T) {
+	ca := newCA(t, "ca")
+	s, client, closeFn := start(t, nil)
+	defer closeFn()
+
+	rot, err := client.Rotate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rot.Send(&certzpb.RotateCertificateRequest{
+		RotateRequest: &certzpb.RotateCertificateRequest_GenerateCsr{
+			GenerateCsr: &certzpb.GenerateCSRRequest{
+				Params: &certzpb.CSRParams{
+					CsrSuite:   certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_ECDSA_PRIME256V1_SIGNATURE_ALGORITHM_SHA_2_256,
+					CommonName: "lemming",
+					San:        &certzpb.V3ExtensionSAN{Dns: []string{"lemming"}, Ips: []string{"192.0.2.1"}},
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rot.Recv()
+	if err != nil {
+		t.Fatalf("Rotate() unexpected err: %v", err)
+	}
+	block, _ := pem.Decode(resp.GetGeneratedCsr().GetCertificateSigningRequest().GetCertificateSigningRequest())
+	if block == nil {
+		t.Fatalf("Rotate() got invalid CSR: %v", resp)
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		t.Fatalf("CSR has invalid signature: %v", err)
+	}
+	if diff := cmp.Diff([]string{"lemming"}, csr.DNSNames); diff != "" {
+		t.Errorf("CSR DNS names diff (-want +got):\n%s", diff)
+	}
+	if got := csr.IPAddresses; len(got) != 1 || !got[0].Equal(net.ParseIP("192.0.2.1")) {
+		t.Errorf("CSR got IP addresses %v, want [192.0.2.1]", got)
+	}
+
+	cert := ca.issue(t, 2, csr.PublicKey)
+	for _, req := range []*certzpb.RotateCertificateRequest{upload("", false, certEntity(t, "1", cert, nil)), finalize} {
+		if err := rot.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := rot.Recv(); err != nil {
+		t.Fatalf("Rotate() upload unexpected err: %v", err)
+	}
+	if p := s.getProfile(DefaultProfile); p.cert == nil || !p.cert.Leaf.Equal(cert) {
+		t.Errorf("Rotate() signed certificate not in use")
+	}
+}
+
+func TestCanGenerateCSR(t *testing.T) {
+	tests := []struct {
+		desc   string
+		params *certzpb.CSRParams
+		want   bool
+	}{{
+		desc:   "supported",
+		params: &certzpb.CSRParams{CsrSuite: certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_EDDSA_ED25519, CommonName: "lemming"},
+		want:   true,
+	}, {
+		desc:   "unspecified suite",
+		params: &certzpb.CSRParams{CommonName: "lemming"},
+	}, {
+		desc:   "invalid IP",
+		params: &certzpb.CSRParams{CsrSuite: certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_EDDSA_ED25519, CommonName: "lemming", IpAddress: "invalid"},
+	}}
+	s := New(nil)
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			resp, err := s.CanGenerateCSR(context.Background(), &certzpb.CanGenerateCSRRequest{Params: tt.params})
+			if err != nil {
+				t.Fatalf("CanGenerateCSR() unexpected err: %v", err)
+			}
+			if got := resp.GetCanGenerate(); got != tt.want {
+				t.Errorf("CanGenerateCSR() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	s, client, closeFn := start(t, nil)
+	defer closeFn()
+	ctx := context.Background()
+
+	if _, err := client.AddProfile(ctx, &certzpb.AddProfileRequest{SslProfileId: "p4rt"}); err != nil {
+		t.Fatalf("AddProfile() unexpected err: %v", err)
+	}
+	if _, err := client.AddProfile(ctx, &certzpb.AddProfileRequest{SslProfileId: "p4rt"}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("AddProfile() of existing profile got err %v, want AlreadyExists", err)
+	}
+	resp, err := client.GetProfileList(ctx, &certzpb.GetProfileListRequest{})
+	if err != nil {
+		t.Fatalf("GetProfileList() unexpected err: %v", err)
+	}
+	if diff := cmp.Diff([]string{"p4rt", DefaultProfile}, resp.GetSslProfileIds()); diff != "" {
+		t.Errorf("GetProfileList() diff (-want +got):\n%s", diff)
+	}
+
+	s.setBindings(map[string]*oc.System_GrpcServer{"p4rt": {CertificateId: ygot.String("p4rt")}})
+	tests := []struct {
+		desc     string
+		id       string
+		wantCode codes.Code
+	}{{
+		desc:     "default profile",
+		id:       DefaultProfile,
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "unknown profile",
+		id:       "unknown",
+		wantCode: codes.NotFound,
+	}, {
+		desc:     "profile in use",
+		id:       "p4rt",
+		wantCode: codes.FailedPrecondition,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := client.DeleteProfile(ctx, &certzpb.DeleteProfileRequest{SslProfileId: tt.id})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("DeleteProfile() got code %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+
+	s.setBindings(nil)
+	if _, err := client.DeleteProfile(ctx, &certzpb.DeleteProfileRequest{SslProfileId: "p4rt"}); err != nil {
+		t.Fatalf("DeleteProfile() unexpected err: %v", err)
+	}
+	if p := s.getProfile("p4rt"); p != nil {
+		t.Errorf("DeleteProfile() profile not deleted")
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	oldCA, rotatedCA := newCA(t, "old"), newCA(t, "rotated")
+	oldKey, rotatedKey, clientKey := newKey(t), newKey(t), newKey(t)
+	oldCert := oldCA.issue(t, 2, oldKey.Public())
+
+	s, _, closeFn := start(t, &tls.Certificate{Certificate: [][]byte{oldCert.Raw}, PrivateKey: oldKey, Leaf: oldCert})
+	defer closeFn()
+	ctx := context.Background()
+
+	dial := func(ca *testCA, clientCert *tls.Certificate) error {
+		return dialTLS(t, s.tlsAddr, ca, clientCert)
+	}
+	if err := dial(oldCA, nil); err != nil {
+		t.Fatalf("call with the initial certificate failed: %v", err)
+	}
+
+	rotatedCert := rotatedCA.issue(t, 3, rotatedKey.Public())
+	clientCert := rotatedCA.issue(t, 4, clientKey.Public())
+	rot, err := s.client.Rotate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rot.Send(upload("", false,
+		certEntity(t, "2", rotatedCert, rotatedKey),
+		trustEntity("2", rotatedCA.cert),
+		crlEntity("2", rotatedCA.crl(t, 4)),
+	)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rot.Recv(); err != nil {
+		t.Fatalf("Rotate() unexpected err: %v", err)
+	}
+
+	if err := dial(oldCA, nil); status.Code(err) != codes.Unavailable {
+		t.Errorf("call trusting the rotated out CA got err %v, want Unavailable", err)
+	}
+	if err := dial(rotatedCA, nil); err != nil {
+		t.Errorf("call with the rotated certificate failed: %v", err)
+	}
+	revoked := &tls.Certificate{Certificate: [][]byte{clientCert.Raw}, PrivateKey: clientKey}
+	if err := dial(rotatedCA, revoked); status.Code(err) != codes.Unavailable {
+		t.Errorf("call with a revoked client certificate got err %v, want Unavailable", err)
+	}
+
+	if err := rot.Send(finalize); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rot.Recv(); err == nil {
+		t.Fatal("Rotate() finalize got no stream close")
+	}
+
+	gnmiServer, err := gnmi.New(grpc.NewServer(), "local", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ygnmi.NewClient(gnmiServer.LocalClient(), ygnmi.WithTarget("local"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.publishState(ctx, c); err != nil {
+		t.Fatalf("publishState() unexpected err: %v", err)
+	}
+	// The state is applied to the cache asynchronously.
+	watchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	val, err := ygnmi.Watch(watchCtx, c, ocpath.Root().System().GrpcServer("gnmi").State(), func(v *ygnmi.Value[*oc.System_GrpcServer]) error {
+		if srv, ok := v.Val(); ok && srv.GetCounters() != nil {
+			return nil
+		}
+		return ygnmi.Continue
+	}).Await()
+	if err != nil {
+		t.Fatalf("failed to get gRPC server state: %v", err)
+	}
+	srv, _ := val.Val()
+	if got, want := []any{srv.GetSslProfileId(), srv.GetCertificateVersion(), srv.GetCaTrustBundleVersion(), srv.GetCertificateRevocationListBundleVersion()}, []any{DefaultProfile, "2", "2", "2"}; !cmp.Equal(got, want) {
+		t.Errorf("gRPC server profile and versions got %v, want %v", got, want)
+	}
+	if got, want := [2]uint64{srv.GetCounters().GetConnectionAccepts(), srv.GetCounters().GetConnectionRejects()}, [2]uint64{2, 2}; got != want {
+		t.Errorf("gRPC server connection accepts and rejects got %v, want %v", got, want)
+	}
+}
+
+// dialTLS makes a certz call over TLS, trusting the CA and presenting the
+// client certificate if any.
+func dialTLS(t testing.TB, addr string, ca *testCA, clientCert *tls.Certificate) error {
+	t.Helper()
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	cfg := &tls.Config{RootCAs: roots, ServerName: "lemming"}
+	if clientCert != nil {
+		cfg.Certificates = []tls.Certificate{*clientCert}
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = certzpb.NewCertzClient(conn).GetProfileList(context.Background(), &certzpb.GetProfileListRequest{})
+	return err
+}
+
+func TestRequireClientCerts(t *testing.T) {
+	ca, otherCA := newCA(t, "ca"), newCA(t, "other")
+	serverKey, clientKey := newKey(t), newKey(t)
+	serverCert := ca.issue(t, 2, serverKey.Public())
+
+	s, _, closeFn := start(t, &tls.Certificate{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey, Leaf: serverCert})
+	defer closeFn()
+	s.RequireClientCerts([]*x509.Certificate{ca.cert})
+
+	tests := []struct {
+		desc       string
+		clientCert *tls.Certificate
+		wantCode   codes.Code
+	}{{
+		desc:     "no client certificate",
+		wantCode: codes.Unavailable,
+	}, {
+		desc:       "trusted client certificate",
+		clientCert: &tls.Certificate{Certificate: [][]byte{ca.issue(t, 3, clientKey.Public()).Raw}, PrivateKey: clientKey},
+	}, {
+		desc:       "untrusted client certificate",
+		clientCert: &tls.Certificate{Certificate: [][]byte{otherCA.issue(t, 4, clientKey.Public()).Raw}, PrivateKey: clientKey},
+		wantCode:   codes.Unavailable,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := status.Code(dialTLS(t, s.tlsAddr, ca, tt.clientCert)); got != tt.wantCode {
+				t.Errorf("call got code %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestParsePKCS7(t *testing.T) {
+	ca1, ca2 := newCA(t, "ca1"), newCA(t, "ca2")
+	raw := func(tag int, compound bool, b []byte) asn1.RawValue {
+		return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: tag, IsCompound: compound, Bytes: b}
+	}
+	sd, err := asn1.Marshal(signedData{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+		ContentInfo:      asn1.RawValue{FullBytes: mustMarshal(t, contentInfo{ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}})},
+		Certificates:     raw(0, true, append(append([]byte{}, ca1.cert.Raw...), ca2.cert.Raw...)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ci := mustMarshal(t, contentInfo{ContentType: oidSignedData, Content: raw(0, true, sd)})
+	certs, err := parsePKCS7(string(pem.EncodeToMemory(&pem.Block{Type: "PKCS7", Bytes: ci})))
+	if err != nil {
+		t.Fatalf("parsePKCS7() unexpected err: %v", err)
+	}
+	if len(certs) != 2 || !certs[0].Equal(ca1.cert) || !certs[1].Equal(ca2.cert) {
+		t.Errorf("parsePKCS7() got %d certificates, want the 2 CA certificates", len(certs))
+	}
+	if _, err := parsePKCS7("invalid"); err == nil {
+		t.Errorf("parsePKCS7() of invalid data got no error")
+	}
+}
+
+func mustMarshal(t testing.TB, v any) []byte {
+	t.Helper()
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// testServer is a certz server, served without TLS and over TLS using its
+// own credentials for the "gnmi" gRPC server.
+type testServer struct {
+	*Server
+	client  certzpb.CertzClient
+	tlsAddr string
+}
+
+func start(t testing.TB, cert *tls.Certificate) (*testServer, certzpb.CertzClient, func()) {
+	t.Helper()
+	certzServer := New(cert)
+
+	s := grpc.NewServer()
+	certzpb.RegisterCertzServer(s, certzServer)
+	tlsS := grpc.NewServer(grpc.Creds(certzServer.Credentials("gnmi")))
+	certzpb.RegisterCertzServer(tlsS, certzServer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:")
+	if err != nil {
+		t.Fatalf("failed to start listener: %v", err)
+	}
+	tlsL, err := net.Listen("tcp", "127.0.0.1:")
+	if err != nil {
+		t.Fatalf("failed to start listener: %v", err)
+	}
+
+	go s.Serve(l)
+	go tlsS.Serve(tlsL)
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed dial server: %v", err)
+	}
+	client := certzpb.NewCertzClient(conn)
+	return &testServer{Server: certzServer, client: client, tlsAddr: tlsL.Addr().String()}, client, func() {
+		s.Stop()
+		tlsS.Stop()
+	}
+}
+
+func TestMarshalState(t *testing.T) {
+	ca := newCA(t, "ca")
+	key := newKey(t)
+	cert := ca.issue(t, 2, key.Public())
+
+	s, client, closeFn := start(t, nil)
+	defer closeFn()
+	ctx := context.Background()
+	if _, err := client.AddProfile(ctx, &certzpb.AddProfileRequest{SslProfileId: "p4rt"}); err != nil {
+		t.Fatalf("AddProfile() unexpected err: %v", err)
+	}
+	for _, req := range []*certzpb.RotateCertificateRequest{
+		upload(DefaultProfile, false, certEntity(t, "cert", cert, key), trustEntity("tb", ca.cert), crlEntity("crl", ca.crl(t, 3))),
+		upload("p4rt", false, trustEntity("p4rt-tb", ca.cert)),
+	} {
+		rot, err := client.Rotate(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, req := range []*certzpb.RotateCertificateRequest{req, finalize} {
+			if err := rot.Send(req); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := rot.Recv(); err != nil {
+			t.Fatalf("Rotate() unexpected err: %v", err)
+		}
+		if _, err := rot.Recv(); !errors.Is(err, io.EOF) {
+			t.Fatalf("Rotate() finalize got err %v, want EOF", err)
+		}
+	}
+
+	b, err := s.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState() unexpected err: %v", err)
+	}
+	restored := New(nil)
+	if err := restored.UnmarshalState(b); err != nil {
+		t.Fatalf("UnmarshalState() unexpected err: %v", err)
+	}
+	for _, id := range []string{DefaultProfile, "p4rt"} {
+		want, got := s.getProfile(id), restored.getProfile(id)
+		if got == nil {
+			t.Fatalf("UnmarshalState() profile %q not restored", id)
+		}
+		if gotMeta, wantMeta := [3]meta{got.certMeta, got.trustBundleMeta, got.crlMeta}, [3]meta{want.certMeta, want.trustBundleMeta, want.crlMeta}; gotMeta != wantMeta {
+			t.Errorf("UnmarshalState() profile %q got versions %+v, want %+v", id, gotMeta, wantMeta)
+		}
+		if len(got.trustBundle) != len(want.trustBundle) || len(got.crls) != len(want.crls) {
+			t.Errorf("UnmarshalState() profile %q got %d trusted certificates and %d CRLs, want %d and %d", id, len(got.trustBundle), len(got.crls), len(want.trustBundle), len(want.crls))
+		}
+	}
+	if got := restored.getProfile(DefaultProfile).cert; got == nil || !bytes.Equal(got.Certificate[0], cert.Raw) {
+		t.Errorf("UnmarshalState() default profile certificate not restored")
+	}
+	if got, err := restored.MarshalState(); err != nil || !bytes.Equal(got, b) {
+		t.Errorf("MarshalState() of restored server got (%d bytes, %v), want the restored state", len(got), err)
+	}
+}
@@ -0,0 +1,337 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certz
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/url"
+
+	certzpb "github.com/openconfig/gnsi/certz"
+)
+
+// csrSuite is the key type and signature algorithm of a CSR suite.
+type csrSuite struct {
+	newKey func() (crypto.Signer, error)
+	sigAlg x509.SignatureAlgorithm
+}
+
+func rsaKey(bits int) func() (crypto.Signer, error) {
+	return func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, bits) }
+}
+
+func ecdsaKey(c elliptic.Curve) func() (crypto.Signer, error) {
+	return func() (crypto.Signer, error) { return ecdsa.GenerateKey(c, rand.Reader) }
+}
+
+func ed25519Key() (crypto.Signer, error) {
+	_, k, err := ed25519.GenerateKey(rand.Reader)
+	return k, err
+}
+
+// csrSuites are the CSR suites the device can generate CSRs for.
+var csrSuites = map[certzpb.CSRSuite]csrSuite{
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_RSA_2048_SIGNATURE_ALGORITHM_SHA_2_256:         {rsaKey(2048), x509.SHA256WithRSA},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_RSA_2048_SIGNATURE_ALGORITHM_SHA_2_384:         {rsaKey(2048), x509.SHA384WithRSA},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_RSA_2048_SIGNATURE_ALGORITHM_SHA_2_512:         {rsaKey(2048), x509.SHA512WithRSA},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_RSA_3072_SIGNATURE_ALGORITHM_SHA_2_256:         {rsaKey(3072), x509.SHA256WithRSA},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_RSA_3072_SIGNATURE_ALGORITHM_SHA_2_384:         {rsaKey(3072), x509.SHA384WithRSA},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_RSA_3072_SIGNATURE_ALGORITHM_SHA_2_512:         {rsaKey(3072), x509.SHA512WithRSA},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_RSA_4096_SIGNATURE_ALGORITHM_SHA_2_256:         {rsaKey(4096), x509.SHA256WithRSA},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_RSA_4096_SIGNATURE_ALGORITHM_SHA_2_384:         {rsaKey(4096), x509.SHA384WithRSA},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_RSA_4096_SIGNATURE_ALGORITHM_SHA_2_512:         {rsaKey(4096), x509.SHA512WithRSA},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_ECDSA_PRIME256V1_SIGNATURE_ALGORITHM_SHA_2_256: {ecdsaKey(elliptic.P256()), x509.ECDSAWithSHA256},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_ECDSA_PRIME256V1_SIGNATURE_ALGORITHM_SHA_2_384: {ecdsaKey(elliptic.P256()), x509.ECDSAWithSHA384},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_ECDSA_PRIME256V1_SIGNATURE_ALGORITHM_SHA_2_512: {ecdsaKey(elliptic.P256()), x509.ECDSAWithSHA512},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_ECDSA_SECP384R1_SIGNATURE_ALGORITHM_SHA_2_256:  {ecdsaKey(elliptic.P384()), x509.ECDSAWithSHA256},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_ECDSA_SECP384R1_SIGNATURE_ALGORITHM_SHA_2_384:  {ecdsaKey(elliptic.P384()), x509.ECDSAWithSHA384},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_ECDSA_SECP384R1_SIGNATURE_ALGORITHM_SHA_2_512:  {ecdsaKey(elliptic.P384()), x509.ECDSAWithSHA512},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_ECDSA_SECP521R1_SIGNATURE_ALGORITHM_SHA_2_256:  {ecdsaKey(elliptic.P521()), x509.ECDSAWithSHA256},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_ECDSA_SECP521R1_SIGNATURE_ALGORITHM_SHA_2_384:  {ecdsaKey(elliptic.P521()), x509.ECDSAWithSHA384},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_ECDSA_SECP521R1_SIGNATURE_ALGORITHM_SHA_2_512:  {ecdsaKey(elliptic.P521()), x509.ECDSAWithSHA512},
+	certzpb.CSRSuite_CSRSUITE_X509_KEY_TYPE_EDDSA_ED25519:                                  {ed25519Key, x509.PureEd25519},
+}
+
+// csrTemplate validates the CSR params and returns the CSR they describe.
+func csrTemplate(params *certzpb.CSRParams) (*x509.CertificateRequest, csrSuite, error) {
+	suite, ok := csrSuites[params.GetCsrSuite()]
+	if !ok {
+		return nil, csrSuite{}, fmt.Errorf("unsupported CSR suite %v", params.GetCsrSuite())
+	}
+	if params.GetCommonName() == "" {
+		return nil, csrSuite{}, fmt.Errorf("common name not specified")
+	}
+	tmpl := &x509.CertificateRequest{
+		Subject:            pkix.Name{CommonName: params.GetCommonName()},
+		SignatureAlgorithm: suite.sigAlg,
+		DNSNames:           params.GetSan().GetDns(),
+		EmailAddresses:     params.GetSan().GetEmails(),
+	}
+	if v := params.GetCountry(); v != "" {
+		tmpl.Subject.Country = []string{v}
+	}
+	if v := params.GetState(); v != "" {
+		tmpl.Subject.Province = []string{v}
+	}
+	if v := params.GetCity(); v != "" {
+		tmpl.Subject.Locality = []string{v}
+	}
+	if v := params.GetOrganization(); v != "" {
+		tmpl.Subject.Organization = []string{v}
+	}
+	if v := params.GetOrganizationalUnit(); v != "" {
+		tmpl.Subject.OrganizationalUnit = []string{v}
+	}
+	if v := params.GetEmailId(); v != "" {
+		tmpl.EmailAddresses = append(tmpl.EmailAddresses, v)
+	}
+	ips := params.GetSan().GetIps()
+	if v := params.GetIpAddress(); v != "" {
+		ips = append(ips, v)
+	}
+	for _, s := range ips {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, csrSuite{}, fmt.Errorf("invalid IP address %q", s)
+		}
+		tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+	}
+	for _, s := range params.GetSan().GetUris() {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, csrSuite{}, fmt.Errorf("invalid URI %q: %v", s, err)
+		}
+		tmpl.URIs = append(tmpl.URIs, u)
+	}
+	return tmpl, suite, nil
+}
+
+// generateCSR generates a key and a PEM encoded CSR for it.
+func generateCSR(params *certzpb.CSRParams) (crypto.Signer, []byte, error) {
+	tmpl, suite, err := csrTemplate(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := suite.newKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, tmpl, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create CSR: %v", err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+// decode returns the DER blocks of b, which is either a DER block or a
+// sequence of PEM blocks depending on the encoding.
+func decode(b []byte, enc certzpb.CertificateEncoding) ([][]byte, error) {
+	switch enc {
+	case certzpb.CertificateEncoding_CERTIFICATE_ENCODING_DER:
+		return [][]byte{b}, nil
+	case certzpb.CertificateEncoding_CERTIFICATE_ENCODING_PEM, certzpb.CertificateEncoding_CERTIFICATE_ENCODING_CRT:
+		var blocks [][]byte
+		for {
+			var block *pem.Block
+			block, b = pem.Decode(b)
+			if block == nil {
+				break
+			}
+			blocks = append(blocks, block.Bytes)
+		}
+		if len(blocks) == 0 || len(bytes.TrimSpace(b)) != 0 {
+			return nil, fmt.Errorf("invalid PEM data")
+		}
+		return blocks, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %v", enc)
+	}
+}
+
+// parseCertificate parses the certificate of c.
+func parseCertificate(c *certzpb.Certificate) (*x509.Certificate, error) {
+	if c.GetType() != certzpb.CertificateType_CERTIFICATE_TYPE_X509 {
+		return nil, fmt.Errorf("unsupported certificate type %v", c.GetType())
+	}
+	if _, ok := c.GetCertificateType().(*certzpb.Certificate_CertSource_); ok {
+		return nil, fmt.Errorf("certificate sources are not supported")
+	}
+	raw := c.GetRawCertificate()
+	if raw == nil {
+		raw = c.GetCertificate() //nolint:staticcheck // Fall back to the deprecated field.
+	}
+	blocks, err := decode(raw, c.GetEncoding())
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) != 1 {
+		return nil, fmt.Errorf("expected a single certificate, got %d", len(blocks))
+	}
+	return x509.ParseCertificate(blocks[0])
+}
+
+// parseChain returns the certificates of a chain, starting from its leaf.
+func parseChain(chain *certzpb.CertificateChain) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for ; chain != nil; chain = chain.GetParent() {
+		cert, err := parseCertificate(chain.GetCertificate())
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("certificate chain is empty")
+	}
+	return certs, nil
+}
+
+// parsePrivateKey parses a PKCS #8, PKCS #1 or SEC 1 private key.
+func parsePrivateKey(b []byte, enc certzpb.CertificateEncoding) (crypto.Signer, error) {
+	blocks, err := decode(b, enc)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) != 1 {
+		return nil, fmt.Errorf("expected a single private key, got %d", len(blocks))
+	}
+	if k, err := x509.ParsePKCS8PrivateKey(blocks[0]); err == nil {
+		if s, ok := k.(crypto.Signer); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("unsupported private key type %T", k)
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(blocks[0]); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParseECPrivateKey(blocks[0]); err == nil {
+		return k, nil
+	}
+	return nil, fmt.Errorf("failed to parse private key")
+}
+
+// keyPair returns the certificate of the chain with its private key, which is
+// either part of the leaf certificate or the key of the CSR generated by the
+// device.
+func keyPair(chain *certzpb.CertificateChain, csrKey crypto.Signer) (*tls.Certificate, error) {
+	certs, err := parseChain(chain)
+	if err != nil {
+		return nil, err
+	}
+	leaf := chain.GetCertificate()
+	var key crypto.Signer
+	switch k := leaf.GetPrivateKeyType().(type) {
+	case *certzpb.Certificate_RawPrivateKey:
+		if key, err = parsePrivateKey(k.RawPrivateKey, leaf.GetEncoding()); err != nil {
+			return nil, err
+		}
+	case *certzpb.Certificate_KeySource_:
+		if k.KeySource != certzpb.Certificate_KEY_SOURCE_GENERATED {
+			return nil, fmt.Errorf("unsupported key source %v", k.KeySource)
+		}
+		if csrKey == nil {
+			return nil, fmt.Errorf("no CSR was generated in this rotation")
+		}
+		key = csrKey
+	default:
+		if raw := leaf.GetPrivateKey(); raw != nil { //nolint:staticcheck // Fall back to the deprecated field.
+			if key, err = parsePrivateKey(raw, leaf.GetEncoding()); err != nil {
+				return nil, err
+			}
+		} else if csrKey != nil {
+			key = csrKey
+		} else {
+			return nil, fmt.Errorf("private key not specified")
+		}
+	}
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(certs[0].PublicKey) {
+		return nil, fmt.Errorf("private key does not match the certificate")
+	}
+	tc := &tls.Certificate{PrivateKey: key, Leaf: certs[0]}
+	for _, c := range certs {
+		tc.Certificate = append(tc.Certificate, c.Raw)
+	}
+	return tc, nil
+}
+
+var oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+// signedData is the prefix of a PKCS #7 SignedData up to its certificates.
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+// parsePKCS7 returns the certificates of a PEM encoded PKCS #7 SignedData.
+func parsePKCS7(s string) ([]*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM data")
+	}
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(block.Bytes, &ci); err != nil {
+		return nil, fmt.Errorf("invalid PKCS #7 content info: %v", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("unsupported PKCS #7 content type %v", ci.ContentType)
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("invalid PKCS #7 signed data: %v", err)
+	}
+	return x509.ParseCertificates(sd.Certificates.Bytes)
+}
+
+// parseCRLs parses a CRL bundle.
+func parseCRLs(bundle *certzpb.CertificateRevocationListBundle) ([]*x509.RevocationList, error) {
+	var crls []*x509.RevocationList
+	for _, c := range bundle.GetCertificateRevocationLists() {
+		if c.GetType() != certzpb.CertificateType_CERTIFICATE_TYPE_X509 {
+			return nil, fmt.Errorf("CRL %q: unsupported type %v", c.GetId(), c.GetType())
+		}
+		blocks, err := decode(c.GetCertificateRevocationList(), c.GetEncoding())
+		if err != nil {
+			return nil, fmt.Errorf("CRL %q: %v", c.GetId(), err)
+		}
+		for _, b := range blocks {
+			crl, err := x509.ParseRevocationList(b)
+			if err != nil {
+				return nil, fmt.Errorf("CRL %q: %v", c.GetId(), err)
+			}
+			crls = append(crls, crl)
+		}
+	}
+	return crls, nil
+}
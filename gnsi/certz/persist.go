@@ -0,0 +1,168 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certz
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+
+	certzpb "github.com/openconfig/gnsi/certz"
+)
+
+// OnFinalize sets a function called once a rotation is finalized or a
+// profile is added or deleted, while no other certz RPC can start. It must be
+// set before the server is serving.
+func (s *Server) OnFinalize(f func()) {
+	s.onFinalize = f
+}
+
+// finalize records the profiles in use as finalized.
+func (s *Server) finalize() {
+	s.mu.Lock()
+	s.finalized = maps.Clone(s.profiles)
+	s.mu.Unlock()
+	if s.onFinalize != nil {
+		s.onFinalize()
+	}
+}
+
+// chain returns the certificate chain of DER certificates, with the private
+// key of the leaf if it is not nil.
+func chain(ders [][]byte, key []byte) *certzpb.CertificateChain {
+	var c *certzpb.CertificateChain
+	for i := len(ders) - 1; i >= 0; i-- {
+		c = &certzpb.CertificateChain{
+			Certificate: &certzpb.Certificate{
+				Type:            certzpb.CertificateType_CERTIFICATE_TYPE_X509,
+				Encoding:        certzpb.CertificateEncoding_CERTIFICATE_ENCODING_PEM,
+				CertificateType: &certzpb.Certificate_RawCertificate{RawCertificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ders[i]})},
+			},
+			Parent: c,
+		}
+	}
+	if c != nil && key != nil {
+		c.Certificate.PrivateKeyType = &certzpb.Certificate_RawPrivateKey{RawPrivateKey: key}
+	}
+	return c
+}
+
+// entities returns the entities uploading the versioned artifacts of p.
+func entities(p *profile) ([]*certzpb.Entity, error) {
+	var ents []*certzpb.Entity
+	add := func(m meta, e *certzpb.Entity) {
+		if m.version == "" {
+			return
+		}
+		e.Version, e.CreatedOn = m.version, m.createdOn
+		ents = append(ents, e)
+	}
+	if p.cert != nil && p.certMeta.version != "" {
+		der, err := x509.MarshalPKCS8PrivateKey(p.cert.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot marshal private key: %v", err)
+		}
+		key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		add(p.certMeta, &certzpb.Entity{Entity: &certzpb.Entity_CertificateChain{CertificateChain: chain(p.cert.Certificate, key)}})
+	}
+	if len(p.trustBundle) > 0 {
+		var ders [][]byte
+		for _, c := range p.trustBundle {
+			ders = append(ders, c.Raw)
+		}
+		add(p.trustBundleMeta, &certzpb.Entity{Entity: &certzpb.Entity_TrustBundle{TrustBundle: chain(ders, nil)}})
+	}
+	if len(p.crls) > 0 {
+		bundle := &certzpb.CertificateRevocationListBundle{}
+		for i, crl := range p.crls {
+			bundle.CertificateRevocationLists = append(bundle.CertificateRevocationLists, &certzpb.CertificateRevocationList{
+				Type:                      certzpb.CertificateType_CERTIFICATE_TYPE_X509,
+				Encoding:                  certzpb.CertificateEncoding_CERTIFICATE_ENCODING_DER,
+				CertificateRevocationList: crl.Raw,
+				Id:                        fmt.Sprint(i),
+			})
+		}
+		add(p.crlMeta, &certzpb.Entity{Entity: &certzpb.Entity_CertificateRevocationListBundle{CertificateRevocationListBundle: bundle}})
+	}
+	if p.authPolicy != nil {
+		add(p.authPolicyMeta, &certzpb.Entity{Entity: &certzpb.Entity_AuthenticationPolicy{AuthenticationPolicy: &certzpb.AuthenticationPolicy{
+			Policy: &certzpb.AuthenticationPolicy_Serialized{Serialized: p.authPolicy},
+		}}})
+	}
+	return ents, nil
+}
+
+// MarshalState returns the finalized profiles, stored as the rotation
+// requests restoring their versioned artifacts. The artifacts of the initial
+// default profile are not versioned, and are not stored.
+func (s *Server) MarshalState() ([]byte, error) {
+	s.mu.RLock()
+	profiles := s.finalized
+	s.mu.RUnlock()
+	var b bytes.Buffer
+	for _, id := range slices.Sorted(maps.Keys(profiles)) {
+		ents, err := entities(profiles[id])
+		if err != nil {
+			return nil, fmt.Errorf("SSL profile %q: %v", id, err)
+		}
+		req := &certzpb.RotateCertificateRequest{
+			SslProfileId:  id,
+			RotateRequest: &certzpb.RotateCertificateRequest_Certificates{Certificates: &certzpb.UploadRequest{Entities: ents}},
+		}
+		if _, err := protodelim.MarshalTo(&b, req); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalState restores and finalizes the profiles returned by
+// MarshalState.
+func (s *Server) UnmarshalState(b []byte) error {
+	r := bufio.NewReader(bytes.NewReader(b))
+	for {
+		req := &certzpb.RotateCertificateRequest{}
+		err := protodelim.UnmarshalFrom(r, req)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		id := req.GetSslProfileId()
+		p := s.getProfile(id)
+		if p == nil {
+			p = &profile{}
+		}
+		if ents := req.GetCertificates().GetEntities(); len(ents) > 0 {
+			if p, err = s.upload(p, ents, true, nil); err != nil {
+				return fmt.Errorf("SSL profile %q: %v", id, err)
+			}
+		}
+		s.setProfile(id, p)
+	}
+	s.mu.Lock()
+	s.finalized = maps.Clone(s.profiles)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialz
+
+import (
+	"context"
+	"crypto/x509"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// certIdentity returns the identity of a client certificate, which is its
+// SPIFFE ID if it has one, else its first DNS SAN, else its common name.
+func certIdentity(cert *x509.Certificate) string {
+	for _, u := range cert.URIs {
+		if u.Scheme == "spiffe" {
+			return u.String()
+		}
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return cert.Subject.CommonName
+}
+
+// SetCertPrincipals sets the mapping of the identities of client
+// certificates to the principals they authenticate. The identity of a
+// certificate is its SPIFFE ID, else its first DNS SAN, else its common name.
+// If principals is nil, the identities are the principals, otherwise
+// certificates with an unmapped identity are rejected.
+func (s *Server) SetCertPrincipals(principals map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.certPrincipals = principals
+}
+
+// certPrincipal returns the principal authenticated by the verified client
+// certificate of a call, or false if the call has none.
+func (s *Server) certPrincipal(ctx context.Context) (string, bool, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false, nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", false, nil
+	}
+	id := certIdentity(info.State.VerifiedChains[0][0])
+	s.mu.RLock()
+	principals := s.certPrincipals
+	s.mu.RUnlock()
+	if principals == nil {
+		if id == "" {
+			return "", false, status.Error(codes.Unauthenticated, "client certificate has no identity")
+		}
+		return id, true, nil
+	}
+	principal, ok := principals[id]
+	if !ok {
+		return "", false, status.Errorf(codes.Unauthenticated, "client certificate identity %q is not mapped to a principal", id)
+	}
+	return principal, true, nil
+}
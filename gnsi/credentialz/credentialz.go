@@ -0,0 +1,567 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentialz is a gNSI credentialz server, which manages the
+// account credentials and SSH host parameters of the device, and
+// authenticates the users of its gRPC servers.
+package credentialz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"github.com/openconfig/ygot/ygot"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/lemming/gnmi/gnmiclient"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+	"github.com/openconfig/lemming/gnmi/reconciler"
+
+	cpb "github.com/openconfig/gnsi/credentialz"
+)
+
+const (
+	// usernameKey is the metadata key carrying the user of a call. It is only
+	// passed on to the services once the user is authenticated.
+	usernameKey = "username"
+	// passwordKey is the metadata key carrying the password of the user. It
+	// is never passed on to the services.
+	passwordKey = "password"
+)
+
+// meta is the versioning information of a credential, reported as-is in
+// telemetry.
+type meta struct {
+	version   string
+	createdOn uint64
+}
+
+// account holds the credentials of an account. Accounts are never modified
+// once stored, a rotation replaces them.
+type account struct {
+	// passwordHash is the crypt(3) hash of the password.
+	passwordHash   string
+	passwordMeta   meta
+	keys           []*cpb.AccountCredentials_AuthorizedKey
+	keysMeta       meta
+	principals     []*cpb.UserPolicy_SshAuthorizedPrincipal
+	principalsMeta meta
+}
+
+// Server implements the credentialz gRPC server and the interceptors
+// authenticating the users of the gRPC servers.
+type Server struct {
+	cpb.UnimplementedCredentialzServer
+	accountRotationInProgress atomic.Bool
+	hostRotationInProgress    atomic.Bool
+
+	// mu protects the credentials in use, which are either finalized or
+	// uploaded by a rotation in progress.
+	mu       sync.RWMutex
+	accounts map[string]*account
+	host     *hostParams
+	// certPrincipals maps the identities of client certificates to
+	// principals, see SetCertPrincipals.
+	certPrincipals map[string]string
+	// allowAnonymous lets through the calls presenting no credential, see
+	// SetAllowAnonymous.
+	allowAnonymous bool
+	// finalizedAccounts and finalizedHost are the credentials as of the last
+	// finalized rotations.
+	finalizedAccounts map[string]*account
+	finalizedHost     *hostParams
+	// onFinalize is called once a rotation is finalized.
+	onFinalize func()
+
+	stateMu sync.Mutex
+	// dirty is set when the state published by the reconciler is stale.
+	dirty bool
+	// published contains the accounts whose state is published.
+	published map[string]bool
+}
+
+// New returns a credentialz server without accounts, which rejects every call
+// until accounts are rotated in or anonymous calls are allowed.
+func New() *Server {
+	return &Server{
+		accounts:          map[string]*account{},
+		host:              &hostParams{},
+		finalizedAccounts: map[string]*account{},
+		finalizedHost:     &hostParams{},
+		published:         map[string]bool{},
+	}
+}
+
+func (s *Server) markDirty() {
+	s.stateMu.Lock()
+	s.dirty = true
+	s.stateMu.Unlock()
+}
+
+func (s *Server) getAccounts() map[string]*account {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.accounts
+}
+
+func (s *Server) setAccounts(accounts map[string]*account) {
+	s.mu.Lock()
+	s.accounts = accounts
+	s.mu.Unlock()
+	s.markDirty()
+}
+
+// updateAccounts applies f to copies of the accounts of the request, and
+// stores them if f succeeds.
+func (s *Server) updateAccounts(names []string, f func(map[string]*account) error) error {
+	cur := s.getAccounts()
+	next := make(map[string]*account, len(cur))
+	for name, acc := range cur {
+		next[name] = acc
+	}
+	for _, name := range names {
+		if name == "" {
+			return status.Error(codes.InvalidArgument, "account not specified")
+		}
+		acc := &account{}
+		if a, ok := cur[name]; ok {
+			*acc = *a
+		}
+		next[name] = acc
+	}
+	if err := f(next); err != nil {
+		return err
+	}
+	s.setAccounts(next)
+	return nil
+}
+
+// RotateAccountCredentials implements the credentialz
+// RotateAccountCredentials RPC. The credentials are used immediately, and the
+// previous credentials are restored if the stream ends before the rotation is
+// finalized.
+func (s *Server) RotateAccountCredentials(rs cpb.Credentialz_RotateAccountCredentialsServer) error {
+	if !s.accountRotationInProgress.CompareAndSwap(false, true) {
+		return status.Error(codes.Unavailable, "another account rotation is already in progress")
+	}
+	defer s.accountRotationInProgress.Store(false)
+
+	prev := s.getAccounts()
+	changed, finalized := false, false
+	defer func() {
+		if changed && !finalized {
+			log.Info("credentialz account rotation not finalized, rolling back the credentials")
+			s.setAccounts(prev)
+		}
+	}()
+
+	for {
+		req, err := rs.Recv()
+		if errors.Is(err, io.EOF) {
+			return status.Error(codes.Aborted, "stream closed before the rotation was finalized")
+		}
+		if err != nil {
+			return err
+		}
+		if _, ok := req.Request.(*cpb.RotateAccountCredentialsRequest_Finalize); ok {
+			if !changed {
+				return status.Error(codes.FailedPrecondition, "finalize called before any credential was rotated")
+			}
+			finalized = true
+			s.finalizeAccounts()
+			return nil
+		}
+		resp, err := s.rotateAccounts(req)
+		if err != nil {
+			return err
+		}
+		changed = true
+		if err := rs.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// rotateAccounts applies an account credentials rotation request to the
+// credentials in use.
+func (s *Server) rotateAccounts(req *cpb.RotateAccountCredentialsRequest) (*cpb.RotateAccountCredentialsResponse, error) {
+	resp := &cpb.RotateAccountCredentialsResponse{}
+	switch r := req.Request.(type) {
+	case *cpb.RotateAccountCredentialsRequest_Credential:
+		if err := s.rotateKeys(r.Credential.GetCredentials()); err != nil {
+			return nil, err
+		}
+		resp.Response = &cpb.RotateAccountCredentialsResponse_Credential{Credential: &cpb.AuthorizedKeysResponse{}}
+	case *cpb.RotateAccountCredentialsRequest_User:
+		if err := s.rotatePrincipals(r.User.GetPolicies()); err != nil {
+			return nil, err
+		}
+		resp.Response = &cpb.RotateAccountCredentialsResponse_User{User: &cpb.AuthorizedUsersResponse{}}
+	case *cpb.RotateAccountCredentialsRequest_Password:
+		if err := s.rotatePasswords(r.Password.GetAccounts()); err != nil {
+			return nil, err
+		}
+		resp.Response = &cpb.RotateAccountCredentialsResponse_Password{Password: &cpb.PasswordResponse{}}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown request type %T", r)
+	}
+	return resp, nil
+}
+
+func (s *Server) rotateKeys(creds []*cpb.AccountCredentials) error {
+	var names []string
+	for _, c := range creds {
+		names = append(names, c.GetAccount())
+	}
+	return s.updateAccounts(names, func(accounts map[string]*account) error {
+		for _, c := range creds {
+			for _, k := range c.GetAuthorizedKeys() {
+				if _, _, _, _, err := ssh.ParseAuthorizedKey(k.GetAuthorizedKey()); err != nil {
+					return status.Errorf(codes.InvalidArgument, "account %q: invalid authorized key: %v", c.GetAccount(), err)
+				}
+			}
+			acc := accounts[c.GetAccount()]
+			acc.keys = c.GetAuthorizedKeys()
+			acc.keysMeta = meta{version: c.GetVersion(), createdOn: c.GetCreatedOn()}
+		}
+		return nil
+	})
+}
+
+func (s *Server) rotatePrincipals(policies []*cpb.UserPolicy) error {
+	var names []string
+	for _, p := range policies {
+		names = append(names, p.GetAccount())
+	}
+	return s.updateAccounts(names, func(accounts map[string]*account) error {
+		for _, p := range policies {
+			principals := p.GetAuthorizedPrincipals().GetAuthorizedPrincipals()
+			for _, pr := range principals {
+				if pr.GetAuthorizedUser() == "" {
+					return status.Errorf(codes.InvalidArgument, "account %q: authorized user not specified", p.GetAccount())
+				}
+			}
+			acc := accounts[p.GetAccount()]
+			acc.principals = principals
+			acc.principalsMeta = meta{version: p.GetVersion(), createdOn: p.GetCreatedOn()}
+		}
+		return nil
+	})
+}
+
+func (s *Server) rotatePasswords(pws []*cpb.PasswordRequest_Account) error {
+	var names []string
+	for _, pw := range pws {
+		names = append(names, pw.GetAccount())
+	}
+	return s.updateAccounts(names, func(accounts map[string]*account) error {
+		for _, pw := range pws {
+			var hash string
+			switch v := pw.GetPassword().GetValue().(type) {
+			case *cpb.PasswordRequest_Password_Plaintext:
+				if v.Plaintext == "" {
+					return status.Errorf(codes.InvalidArgument, "account %q: empty password", pw.GetAccount())
+				}
+				var err error
+				if hash, err = hashPassword(v.Plaintext); err != nil {
+					return status.Errorf(codes.Internal, "account %q: cannot hash password: %v", pw.GetAccount(), err)
+				}
+			case *cpb.PasswordRequest_Password_CryptoHash:
+				prefix := ""
+				switch v.CryptoHash.GetHashType() {
+				case cpb.PasswordRequest_CryptoHash_HASH_TYPE_CRYPT_MD5:
+					prefix = md5Prefix
+				case cpb.PasswordRequest_CryptoHash_HASH_TYPE_CRYPT_SHA_2_512:
+					prefix = sha512Prefix
+				default:
+					return status.Errorf(codes.InvalidArgument, "account %q: unsupported hash type %v", pw.GetAccount(), v.CryptoHash.GetHashType())
+				}
+				hash = v.CryptoHash.GetHashValue()
+				if err := validateHash(hash, prefix); err != nil {
+					return status.Errorf(codes.InvalidArgument, "account %q: %v", pw.GetAccount(), err)
+				}
+			default:
+				return status.Errorf(codes.InvalidArgument, "account %q: password not specified", pw.GetAccount())
+			}
+			acc := accounts[pw.GetAccount()]
+			acc.passwordHash = hash
+			acc.passwordMeta = meta{version: pw.GetVersion(), createdOn: pw.GetCreatedOn()}
+		}
+		return nil
+	})
+}
+
+// SetAllowAnonymous sets whether the calls presenting neither a username and
+// password nor a client certificate are let through, with no principal.
+func (s *Server) SetAllowAnonymous(allow bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.allowAnonymous = allow
+}
+
+// Method is the credential authenticating the user of a call.
+type Method int
+
+const (
+	// MethodNone is used by the calls presenting no credential.
+	MethodNone Method = iota
+	// MethodPassword is used by the calls presenting a username and
+	// password.
+	MethodPassword
+	// MethodCert is used by the calls presenting a verified client
+	// certificate.
+	MethodCert
+)
+
+// Authentication is the outcome of the authentication of a call.
+type Authentication struct {
+	// Principal is the authenticated user, empty if the call is anonymous or
+	// is rejected.
+	Principal string
+	Method    Method
+	// Err is the reason the call is rejected, nil if it is authenticated.
+	Err error
+}
+
+type authenticationKey struct{}
+
+// WithAuthentication returns a context in which the interceptors record the
+// authentication of a call in the returned Authentication. It lets the
+// interceptors preceding them, such as the acctz interceptors, learn the
+// principal of the call once it returns.
+func WithAuthentication(ctx context.Context) (context.Context, *Authentication) {
+	a := &Authentication{}
+	return context.WithValue(ctx, authenticationKey{}, a), a
+}
+
+// AuthenticationFromContext returns the Authentication recorded by the
+// interceptors in a context returned by WithAuthentication, if any.
+func AuthenticationFromContext(ctx context.Context) (*Authentication, bool) {
+	a, ok := ctx.Value(authenticationKey{}).(*Authentication)
+	return a, ok
+}
+
+// authenticate authenticates the user of a call, and returns the context of
+// the call with the password removed from its metadata and the principal set
+// as its username. The authentication is recorded if the context was returned
+// by WithAuthentication.
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		md = metadata.MD{}
+	}
+	principal, method, err := s.principal(ctx, md)
+	if a, ok := AuthenticationFromContext(ctx); ok {
+		a.Method, a.Err = method, err
+		if err == nil {
+			a.Principal = principal
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if method == MethodNone {
+		return ctx, nil
+	}
+	md = md.Copy()
+	delete(md, passwordKey)
+	md.Set(usernameKey, principal)
+	return metadata.NewIncomingContext(ctx, md), nil
+}
+
+// principal returns the principal authenticated by the credential of a call,
+// and the method of the credential. Calls with a verified client certificate
+// are authenticated as the principal of the certificate, and others by the
+// username and password metadata. Calls presenting no credential are
+// rejected unless anonymous calls are allowed.
+func (s *Server) principal(ctx context.Context, md metadata.MD) (string, Method, error) {
+	principal, hasCert, err := s.certPrincipal(ctx)
+	if err != nil {
+		return "", MethodCert, err
+	}
+	users, passwords := md.Get(usernameKey), md.Get(passwordKey)
+	switch {
+	case hasCert:
+		if len(users) > 1 || len(users) == 1 && users[0] != principal {
+			return "", MethodCert, status.Errorf(codes.Unauthenticated, "username does not match the client certificate principal %q", principal)
+		}
+		if len(passwords) > 0 {
+			if err := s.checkAccountPassword(principal, passwords); err != nil {
+				return "", MethodCert, err
+			}
+		}
+		return principal, MethodCert, nil
+	case len(users) == 0 && len(passwords) == 0:
+		s.mu.RLock()
+		allow := s.allowAnonymous
+		s.mu.RUnlock()
+		if !allow {
+			return "", MethodNone, status.Error(codes.Unauthenticated, "no credentials specified")
+		}
+		return "", MethodNone, nil
+	case len(users) != 1 || len(passwords) != 1:
+		return "", MethodPassword, status.Error(codes.Unauthenticated, "a single username and password must be specified")
+	}
+	if err := s.checkAccountPassword(users[0], passwords); err != nil {
+		return "", MethodPassword, err
+	}
+	return users[0], MethodPassword, nil
+}
+
+// checkAccountPassword checks that a single password is specified, and that
+// it is the password of the account.
+func (s *Server) checkAccountPassword(user string, passwords []string) error {
+	if len(passwords) != 1 {
+		return status.Error(codes.Unauthenticated, "a single password must be specified")
+	}
+	acc, ok := s.getAccounts()[user]
+	if !ok || acc.passwordHash == "" || !checkPassword(acc.passwordHash, passwords[0]) {
+		return status.Error(codes.Unauthenticated, "invalid username or password")
+	}
+	return nil
+}
+
+// Unary is a gRPC unary interceptor rejecting the calls whose user cannot be
+// authenticated.
+func (s *Server) Unary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// Stream is a gRPC stream interceptor rejecting the calls whose user cannot
+// be authenticated.
+func (s *Server) Stream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticatedStream is a server stream whose context carries the
+// authenticated metadata.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// batchMeta sets the version leaves of a credential, or deletes them if the
+// credential was never rotated.
+func batchMeta(batch *ygnmi.SetBatch, m meta, version ygnmi.SingletonQuery[string], createdOn ygnmi.SingletonQuery[uint64]) {
+	if m.version == "" {
+		gnmiclient.BatchDelete(batch, version)
+		gnmiclient.BatchDelete(batch, createdOn)
+		return
+	}
+	gnmiclient.BatchReplace(batch, version, m.version)
+	gnmiclient.BatchReplace(batch, createdOn, m.createdOn)
+}
+
+// publishState publishes the versions of the credentials of the accounts and
+// of the SSH host parameters, if they changed since they were last published.
+func (s *Server) publishState(ctx context.Context, c *ygnmi.Client) error {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	s.dirty = false
+
+	accounts := s.getAccounts()
+	batch := &ygnmi.SetBatch{}
+	usersPath := ocpath.Root().System().Aaa().Authentication()
+	for name, acc := range accounts {
+		user := &oc.System_Aaa_Authentication_User{Username: ygot.String(name)}
+		if m := acc.passwordMeta; m.version != "" {
+			user.PasswordVersion, user.PasswordCreatedOn = ygot.String(m.version), ygot.Uint64(m.createdOn)
+		}
+		if m := acc.keysMeta; m.version != "" {
+			user.AuthorizedKeysListVersion, user.AuthorizedKeysListCreatedOn = ygot.String(m.version), ygot.Uint64(m.createdOn)
+		}
+		if m := acc.principalsMeta; m.version != "" {
+			user.AuthorizedPrincipalsListVersion, user.AuthorizedPrincipalsListCreatedOn = ygot.String(m.version), ygot.Uint64(m.createdOn)
+		}
+		gnmiclient.BatchReplace(batch, usersPath.User(name).State(), user)
+	}
+	for name := range s.published {
+		if _, ok := accounts[name]; !ok {
+			gnmiclient.BatchDelete(batch, usersPath.User(name).State())
+		}
+	}
+	host := s.getHost()
+	sshPath := ocpath.Root().System().SshServer()
+	batchMeta(batch, host.keysMeta, sshPath.ActiveHostKeyVersion().State(), sshPath.ActiveHostKeyCreatedOn().State())
+	batchMeta(batch, host.certMeta, sshPath.ActiveHostCertificateVersion().State(), sshPath.ActiveHostCertificateCreatedOn().State())
+	batchMeta(batch, host.caKeysMeta, sshPath.ActiveTrustedUserCaKeysVersion().State(), sshPath.ActiveTrustedUserCaKeysCreatedOn().State())
+
+	if _, err := batch.Set(ctx, c); err != nil {
+		s.dirty = true
+		return err
+	}
+	s.published = map[string]bool{}
+	for name := range accounts {
+		s.published[name] = true
+	}
+	return nil
+}
+
+// Reconciler returns a reconciler publishing the versions of the
+// credentials under /system.
+func (s *Server) Reconciler() *reconciler.BuiltReconciler {
+	var cancel context.CancelFunc
+	return reconciler.NewBuilder("gnsi-credentialz").
+		WithStart(func(ctx context.Context, c *ygnmi.Client) error {
+			ctx, cancel = context.WithCancel(ctx)
+			s.markDirty()
+			go func() {
+				tick := time.NewTicker(time.Second)
+				defer tick.Stop()
+				for {
+					if err := s.publishState(ctx, c); err != nil && ctx.Err() == nil {
+						log.Warningf("unable to update credentialz state: %v", err)
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case <-tick.C:
+					}
+				}
+			}()
+			return nil
+		}).
+		WithStop(func(context.Context) error {
+			if cancel != nil {
+				cancel()
+			}
+			return nil
+		}).Build()
+}
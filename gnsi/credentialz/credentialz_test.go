@@ -0,0 +1,771 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialz
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/openconfig/lemming/gnmi"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+
+	cpb "github.com/openconfig/gnsi/credentialz"
+)
+
+const (
+	// The hashes of "Hello world!" and "password", generated by openssl passwd.
+	sha512Hash       = "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"
+	sha512RoundsHash = "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."
+	md5Hash          = "$1$saltstri$YMyguxXMBpd2TEZ.vS/3q1"
+	md5ShortHash     = "$1$ab$oKsM6dtDD2L1bKowOBX.7."
+)
+
+func TestCheckPassword(t *testing.T) {
+	generated, err := hashPassword("s3cret")
+	if err != nil {
+		t.Fatalf("hashPassword() unexpected err: %v", err)
+	}
+	tests := []struct {
+		desc     string
+		hash     string
+		password string
+		want     bool
+	}{
+		{desc: "sha512", hash: sha512Hash, password: "Hello world!", want: true},
+		{desc: "sha512 with rounds", hash: sha512RoundsHash, password: "Hello world!", want: true},
+		{desc: "md5", hash: md5Hash, password: "Hello world!", want: true},
+		{desc: "md5 short salt", hash: md5ShortHash, password: "password", want: true},
+		{desc: "generated", hash: generated, password: "s3cret", want: true},
+		{desc: "wrong sha512 password", hash: sha512Hash, password: "Hello world"},
+		{desc: "wrong md5 password", hash: md5Hash, password: "hello world!"},
+		{desc: "unsupported hash", hash: "$2a$10$abcdefghijklmnopqrstuv", password: "Hello world!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := checkPassword(tt.hash, tt.password); got != tt.want {
+				t.Errorf("checkPassword(%q, %q) got %v, want %v", tt.hash, tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateHash(t *testing.T) {
+	tests := []struct {
+		desc    string
+		hash    string
+		prefix  string
+		wantErr bool
+	}{
+		{desc: "sha512", hash: sha512Hash, prefix: sha512Prefix},
+		{desc: "sha512 with rounds", hash: sha512RoundsHash, prefix: sha512Prefix},
+		{desc: "md5", hash: md5Hash, prefix: md5Prefix},
+		{desc: "wrong prefix", hash: md5Hash, prefix: sha512Prefix, wantErr: true},
+		{desc: "missing checksum", hash: "$6$saltstring", prefix: sha512Prefix, wantErr: true},
+		{desc: "truncated checksum", hash: sha512Hash[:len(sha512Hash)-1], prefix: sha512Prefix, wantErr: true},
+		{desc: "invalid rounds", hash: "$6$rounds=x$salt$" + sha512Hash[len(sha512Hash)-86:], prefix: sha512Prefix, wantErr: true},
+		{desc: "long md5 salt", hash: "$1$saltstring$YMyguxXMBpd2TEZ.vS/3q1", prefix: md5Prefix, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if err := validateHash(tt.hash, tt.prefix); (err != nil) != tt.wantErr {
+				t.Errorf("validateHash(%q, %q) got err %v, want error %v", tt.hash, tt.prefix, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+var accountFinalize = &cpb.RotateAccountCredentialsRequest{Request: &cpb.RotateAccountCredentialsRequest_Finalize{Finalize: &cpb.FinalizeRequest{}}}
+
+func plaintext(account, password, version string) *cpb.RotateAccountCredentialsRequest {
+	return &cpb.RotateAccountCredentialsRequest{
+		Request: &cpb.RotateAccountCredentialsRequest_Password{Password: &cpb.PasswordRequest{Accounts: []*cpb.PasswordRequest_Account{{
+			Account:  account,
+			Password: &cpb.PasswordRequest_Password{Value: &cpb.PasswordRequest_Password_Plaintext{Plaintext: password}},
+			Version:  version,
+		}}}},
+	}
+}
+
+func cryptoHash(account string, hashType cpb.PasswordRequest_CryptoHash_HashType, hash string) *cpb.RotateAccountCredentialsRequest {
+	return &cpb.RotateAccountCredentialsRequest{
+		Request: &cpb.RotateAccountCredentialsRequest_Password{Password: &cpb.PasswordRequest{Accounts: []*cpb.PasswordRequest_Account{{
+			Account: account,
+			Password: &cpb.PasswordRequest_Password{Value: &cpb.PasswordRequest_Password_CryptoHash{CryptoHash: &cpb.PasswordRequest_CryptoHash{
+				HashType:  hashType,
+				HashValue: hash,
+			}}},
+			Version: "1",
+		}}}},
+	}
+}
+
+func authorizedKeys(t testing.TB, account string, keys ...[]byte) *cpb.RotateAccountCredentialsRequest {
+	t.Helper()
+	creds := &cpb.AccountCredentials{Account: account, Version: "1", CreatedOn: 100}
+	for _, k := range keys {
+		creds.AuthorizedKeys = append(creds.AuthorizedKeys, &cpb.AccountCredentials_AuthorizedKey{AuthorizedKey: k})
+	}
+	return &cpb.RotateAccountCredentialsRequest{
+		Request: &cpb.RotateAccountCredentialsRequest_Credential{Credential: &cpb.AuthorizedKeysRequest{Credentials: []*cpb.AccountCredentials{creds}}},
+	}
+}
+
+func principals(account string, users ...string) *cpb.RotateAccountCredentialsRequest {
+	p := &cpb.UserPolicy{Account: account, Version: "1", CreatedOn: 100, AuthorizedPrincipals: &cpb.UserPolicy_SshAuthorizedPrincipals{}}
+	for _, u := range users {
+		p.AuthorizedPrincipals.AuthorizedPrincipals = append(p.AuthorizedPrincipals.AuthorizedPrincipals, &cpb.UserPolicy_SshAuthorizedPrincipal{AuthorizedUser: u})
+	}
+	return &cpb.RotateAccountCredentialsRequest{
+		Request: &cpb.RotateAccountCredentialsRequest_User{User: &cpb.AuthorizedUsersRequest{Policies: []*cpb.UserPolicy{p}}},
+	}
+}
+
+// newSigner returns an SSH ED25519 key and its PEM encoded private key.
+func newSigner(t testing.TB) (ssh.Signer, []byte) {
+	t.Helper()
+	_, k, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(k, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, pem.EncodeToMemory(block)
+}
+
+func TestRotateAccountCredentials(t *testing.T) {
+	key, _ := newSigner(t)
+	authorizedKey := ssh.MarshalAuthorizedKey(key.PublicKey())
+	tests := []struct {
+		desc     string
+		reqs     []*cpb.RotateAccountCredentialsRequest
+		wantCode codes.Code
+		// wantPassword is the password of alice after the rotation.
+		wantPassword string
+	}{{
+		desc:         "plaintext password",
+		reqs:         []*cpb.RotateAccountCredentialsRequest{plaintext("alice", "s3cret", "1")},
+		wantPassword: "s3cret",
+	}, {
+		desc:         "sha512 hash",
+		reqs:         []*cpb.RotateAccountCredentialsRequest{cryptoHash("alice", cpb.PasswordRequest_CryptoHash_HASH_TYPE_CRYPT_SHA_2_512, sha512Hash)},
+		wantPassword: "Hello world!",
+	}, {
+		desc:         "md5 hash",
+		reqs:         []*cpb.RotateAccountCredentialsRequest{cryptoHash("alice", cpb.PasswordRequest_CryptoHash_HASH_TYPE_CRYPT_MD5, md5Hash)},
+		wantPassword: "Hello world!",
+	}, {
+		desc:         "keys and principals",
+		reqs:         []*cpb.RotateAccountCredentialsRequest{authorizedKeys(t, "alice", authorizedKey), principals("alice", "alice@corp")},
+		wantPassword: "initial",
+	}, {
+		desc:         "hash of the wrong type",
+		reqs:         []*cpb.RotateAccountCredentialsRequest{cryptoHash("alice", cpb.PasswordRequest_CryptoHash_HASH_TYPE_CRYPT_MD5, sha512Hash)},
+		wantCode:     codes.InvalidArgument,
+		wantPassword: "initial",
+	}, {
+		desc:         "unspecified hash type",
+		reqs:         []*cpb.RotateAccountCredentialsRequest{cryptoHash("alice", cpb.PasswordRequest_CryptoHash_HASH_TYPE_UNSPECIFIED, sha512Hash)},
+		wantCode:     codes.InvalidArgument,
+		wantPassword: "initial",
+	}, {
+		desc:         "empty password",
+		reqs:         []*cpb.RotateAccountCredentialsRequest{plaintext("alice", "", "1")},
+		wantCode:     codes.InvalidArgument,
+		wantPassword: "initial",
+	}, {
+		desc:         "no account",
+		reqs:         []*cpb.RotateAccountCredentialsRequest{plaintext("", "s3cret", "1")},
+		wantCode:     codes.InvalidArgument,
+		wantPassword: "initial",
+	}, {
+		desc:         "invalid authorized key",
+		reqs:         []*cpb.RotateAccountCredentialsRequest{authorizedKeys(t, "alice", []byte("ssh-ed25519 invalid"))},
+		wantCode:     codes.InvalidArgument,
+		wantPassword: "initial",
+	}, {
+		desc:         "empty principal",
+		reqs:         []*cpb.RotateAccountCredentialsRequest{principals("alice", "")},
+		wantCode:     codes.InvalidArgument,
+		wantPassword: "initial",
+	}, {
+		desc:         "finalize without rotation",
+		wantCode:     codes.FailedPrecondition,
+		wantPassword: "initial",
+	}, {
+		desc:         "rollback of earlier requests",
+		reqs:         []*cpb.RotateAccountCredentialsRequest{plaintext("alice", "s3cret", "1"), plaintext("", "s3cret", "1")},
+		wantCode:     codes.InvalidArgument,
+		wantPassword: "initial",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s, client, closeFn := start(t)
+			defer closeFn()
+			rotate(t, client, plaintext("alice", "initial", "0"))
+
+			rot, err := client.RotateAccountCredentials(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, req := range append(tt.reqs, accountFinalize) {
+				if err := rot.Send(req); err != nil {
+					t.Fatal(err)
+				}
+				if _, err = rot.Recv(); err != nil {
+					break
+				}
+			}
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("RotateAccountCredentials() got code %v, want %v: %v", got, tt.wantCode, err)
+			}
+			if acc := s.getAccounts()["alice"]; !checkPassword(acc.passwordHash, tt.wantPassword) {
+				t.Errorf("password of alice is not %q after the rotation", tt.wantPassword)
+			}
+		})
+	}
+
+	t.Run("rollback on stream close", func(t *testing.T) {
+		s, client, closeFn := start(t)
+		defer closeFn()
+		rot, err := client.RotateAccountCredentials(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rot.Send(plaintext("alice", "s3cret", "1")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rot.Recv(); err != nil {
+			t.Fatalf("RotateAccountCredentials() unexpected err: %v", err)
+		}
+		if _, ok := s.getAccounts()["alice"]; !ok {
+			t.Fatal("rotated account not used before finalize")
+		}
+		concurrent, err := client.RotateAccountCredentials(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := concurrent.Recv(); status.Code(err) != codes.Unavailable {
+			t.Errorf("concurrent RotateAccountCredentials() got err %v, want Unavailable", err)
+		}
+		rot.CloseSend()
+		if _, err := rot.Recv(); status.Code(err) != codes.Aborted {
+			t.Fatalf("RotateAccountCredentials() got err %v, want Aborted", err)
+		}
+		if _, ok := s.getAccounts()["alice"]; ok {
+			t.Error("account not rolled back after the stream closed")
+		}
+	})
+}
+
+func TestRotateHostParameters(t *testing.T) {
+	caKey, _ := newSigner(t)
+	hostKey, hostPEM := newSigner(t)
+	otherKey, _ := newSigner(t)
+	cert := &ssh.Certificate{Key: hostKey.PublicKey(), CertType: ssh.HostCert, ValidPrincipals: []string{"lemming"}}
+	if err := cert.SignCert(rand.Reader, caKey); err != nil {
+		t.Fatal(err)
+	}
+	otherCert := &ssh.Certificate{Key: otherKey.PublicKey(), CertType: ssh.HostCert}
+	if err := otherCert.SignCert(rand.Reader, caKey); err != nil {
+		t.Fatal(err)
+	}
+	serverKeys := func(certificate []byte) *cpb.RotateHostParametersRequest {
+		return &cpb.RotateHostParametersRequest{Request: &cpb.RotateHostParametersRequest_ServerKeys{ServerKeys: &cpb.ServerKeysRequest{
+			AuthArtifacts: []*cpb.ServerKeysRequest_AuthenticationArtifacts{{PrivateKey: hostPEM, Certificate: certificate}},
+			Version:       "2",
+			CreatedOn:     200,
+		}}}
+	}
+	generate := func(gens ...cpb.KeyGen) *cpb.RotateHostParametersRequest {
+		return &cpb.RotateHostParametersRequest{Request: &cpb.RotateHostParametersRequest_GenerateKeys{GenerateKeys: &cpb.GenerateKeysRequest{
+			KeyParams: gens,
+			Version:   "3",
+		}}}
+	}
+	wantHostKey := bytes.TrimSpace(ssh.MarshalAuthorizedKey(hostKey.PublicKey()))
+
+	tests := []struct {
+		desc     string
+		req      *cpb.RotateHostParametersRequest
+		wantCode codes.Code
+		// wantKeyTypes are the types of the host keys after the rotation.
+		wantKeyTypes []cpb.KeyType
+		wantHostKey  []byte
+		wantMeta     [3]meta
+	}{{
+		desc: "CA keys",
+		req: &cpb.RotateHostParametersRequest{Request: &cpb.RotateHostParametersRequest_SshCaPublicKey{SshCaPublicKey: &cpb.CaPublicKeyRequest{
+			SshCaPublicKeys: []*cpb.PublicKey{{PublicKey: ssh.MarshalAuthorizedKey(caKey.PublicKey()), KeyType: cpb.KeyType_KEY_TYPE_ED25519}},
+			Version:         "1",
+			CreatedOn:       100,
+		}}},
+		wantMeta: [3]meta{{}, {}, {version: "1", createdOn: 100}},
+	}, {
+		desc:         "server keys",
+		req:          serverKeys(nil),
+		wantKeyTypes: []cpb.KeyType{cpb.KeyType_KEY_TYPE_ED25519},
+		wantHostKey:  wantHostKey,
+		wantMeta:     [3]meta{{version: "2", createdOn: 200}},
+	}, {
+		desc:         "server keys with certificate",
+		req:          serverKeys(ssh.MarshalAuthorizedKey(cert)),
+		wantKeyTypes: []cpb.KeyType{cpb.KeyType_KEY_TYPE_ED25519},
+		wantHostKey:  wantHostKey,
+		wantMeta:     [3]meta{{version: "2", createdOn: 200}, {version: "2", createdOn: 200}},
+	}, {
+		desc:     "certificate of another key",
+		req:      serverKeys(ssh.MarshalAuthorizedKey(otherCert)),
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:     "certificate is a public key",
+		req:      serverKeys(ssh.MarshalAuthorizedKey(hostKey.PublicKey())),
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc:         "generate keys",
+		req:          generate(cpb.KeyGen_KEY_GEN_SSH_KEY_TYPE_EDDSA_ED25519, cpb.KeyGen_KEY_GEN_SSH_KEY_TYPE_ECDSA_P_256),
+		wantKeyTypes: []cpb.KeyType{cpb.KeyType_KEY_TYPE_ED25519, cpb.KeyType_KEY_TYPE_ECDSA_P_256},
+		wantMeta:     [3]meta{{version: "3"}},
+	}, {
+		desc:     "generate unsupported keys",
+		req:      generate(cpb.KeyGen_KEY_GEN_SSH_KEY_UNSPECIFIED),
+		wantCode: codes.InvalidArgument,
+	}, {
+		desc: "principal check",
+		req: &cpb.RotateHostParametersRequest{Request: &cpb.RotateHostParametersRequest_AuthorizedPrincipalCheck{AuthorizedPrincipalCheck: &cpb.AuthorizedPrincipalCheckRequest{
+			Tool: cpb.AuthorizedPrincipalCheckRequest_TOOL_HIBA_DEFAULT,
+		}}},
+	}, {
+		desc:     "unspecified principal check",
+		req:      &cpb.RotateHostParametersRequest{Request: &cpb.RotateHostParametersRequest_AuthorizedPrincipalCheck{AuthorizedPrincipalCheck: &cpb.AuthorizedPrincipalCheckRequest{}}},
+		wantCode: codes.InvalidArgument,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s, client, closeFn := start(t)
+			defer closeFn()
+			ctx := context.Background()
+
+			rot, err := client.RotateHostParameters(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := rot.Send(tt.req); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := rot.Recv()
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("RotateHostParameters() got code %v, want %v: %v", got, tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if gen := resp.GetGenerateKeys(); gen != nil && len(gen.GetPublicKeys()) != len(tt.wantKeyTypes) {
+				t.Errorf("RotateHostParameters() generated %d keys, want %d", len(gen.GetPublicKeys()), len(tt.wantKeyTypes))
+			}
+			if err := rot.Send(&cpb.RotateHostParametersRequest{Request: &cpb.RotateHostParametersRequest_Finalize{Finalize: &cpb.FinalizeRequest{}}}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := rot.Recv(); !errors.Is(err, io.EOF) {
+				t.Fatalf("RotateHostParameters() finalize got err %v, want EOF", err)
+			}
+
+			keys, err := client.GetPublicKeys(ctx, &cpb.GetPublicKeysRequest{})
+			if err != nil {
+				t.Fatalf("GetPublicKeys() unexpected err: %v", err)
+			}
+			var gotTypes []cpb.KeyType
+			for _, k := range keys.GetPublicKeys() {
+				gotTypes = append(gotTypes, k.GetKeyType())
+			}
+			if diff := cmp.Diff(tt.wantKeyTypes, gotTypes); diff != "" {
+				t.Errorf("GetPublicKeys() key types diff (-want, +got):\n%s", diff)
+			}
+			if tt.wantHostKey != nil && !bytes.Equal(keys.GetPublicKeys()[0].GetPublicKey(), tt.wantHostKey) {
+				t.Errorf("GetPublicKeys() got key %s, want %s", keys.GetPublicKeys()[0].GetPublicKey(), tt.wantHostKey)
+			}
+			host := s.getHost()
+			if got := [3]meta{host.keysMeta, host.certMeta, host.caKeysMeta}; got != tt.wantMeta {
+				t.Errorf("host keys, certificate and CA keys versions got %v, want %v", got, tt.wantMeta)
+			}
+		})
+	}
+}
+
+func TestCanGenerateKey(t *testing.T) {
+	_, client, closeFn := start(t)
+	defer closeFn()
+	for gen, want := range map[cpb.KeyGen]bool{
+		cpb.KeyGen_KEY_GEN_SSH_KEY_TYPE_EDDSA_ED25519: true,
+		cpb.KeyGen_KEY_GEN_SSH_KEY_TYPE_RSA_4096:      true,
+		cpb.KeyGen_KEY_GEN_SSH_KEY_UNSPECIFIED:        false,
+	} {
+		resp, err := client.CanGenerateKey(context.Background(), &cpb.CanGenerateKeyRequest{KeyParams: gen})
+		if err != nil {
+			t.Fatalf("CanGenerateKey(%v) unexpected err: %v", gen, err)
+		}
+		if resp.GetCanGenerate() != want {
+			t.Errorf("CanGenerateKey(%v) got %v, want %v", gen, resp.GetCanGenerate(), want)
+		}
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	s, client, closeFn := start(t)
+	defer closeFn()
+	ctx := context.Background()
+	rotate(t, client, plaintext("alice", "s3cret", "1"), cryptoHash("bob", cpb.PasswordRequest_CryptoHash_HASH_TYPE_CRYPT_MD5, md5Hash))
+
+	withMetadata := func(kv ...string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, kv...)
+	}
+	tests := []struct {
+		desc     string
+		ctx      context.Context
+		wantCode codes.Code
+	}{
+		{desc: "anonymous", ctx: ctx},
+		{desc: "plaintext password", ctx: withMetadata(usernameKey, "alice", passwordKey, "s3cret")},
+		{desc: "hashed password", ctx: withMetadata(usernameKey, "bob", passwordKey, "Hello world!")},
+		{desc: "wrong password", ctx: withMetadata(usernameKey, "alice", passwordKey, "Hello world!"), wantCode: codes.Unauthenticated},
+		{desc: "unknown user", ctx: withMetadata(usernameKey, "carol", passwordKey, "s3cret"), wantCode: codes.Unauthenticated},
+		{desc: "no password", ctx: withMetadata(usernameKey, "alice"), wantCode: codes.Unauthenticated},
+		{desc: "no username", ctx: withMetadata(passwordKey, "s3cret"), wantCode: codes.Unauthenticated},
+		{desc: "two usernames", ctx: withMetadata(usernameKey, "alice", usernameKey, "bob", passwordKey, "s3cret"), wantCode: codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := client.CanGenerateKey(tt.ctx, &cpb.CanGenerateKeyRequest{})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("unary call got code %v, want %v", got, tt.wantCode)
+			}
+			rot, err := client.RotateHostParameters(tt.ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rot.CloseSend()
+			_, err = rot.Recv()
+			if got, want := status.Code(err), tt.wantCode; want == codes.OK && got != codes.Aborted || want != codes.OK && got != want {
+				t.Errorf("stream call got err %v, want code %v", err, want)
+			}
+		})
+	}
+
+	t.Run("anonymous not allowed", func(t *testing.T) {
+		s.SetAllowAnonymous(false)
+		defer s.SetAllowAnonymous(true)
+		_, err := client.CanGenerateKey(ctx, &cpb.CanGenerateKeyRequest{})
+		if got, want := status.Code(err), codes.Unauthenticated; got != want {
+			t.Errorf("unary call got code %v, want %v", got, want)
+		}
+	})
+
+	t.Run("authentication recorded", func(t *testing.T) {
+		for _, tt := range []struct {
+			desc    string
+			md      metadata.MD
+			want    Authentication
+			wantErr bool
+		}{
+			{desc: "anonymous", md: metadata.MD{}, want: Authentication{}},
+			{desc: "password", md: metadata.Pairs(usernameKey, "alice", passwordKey, "s3cret"), want: Authentication{Principal: "alice", Method: MethodPassword}},
+			{desc: "wrong password", md: metadata.Pairs(usernameKey, "alice", passwordKey, "wrong"), want: Authentication{Method: MethodPassword}, wantErr: true},
+		} {
+			authnCtx, got := WithAuthentication(metadata.NewIncomingContext(ctx, tt.md))
+			_, err := s.authenticate(authnCtx)
+			if (err != nil) != tt.wantErr || (got.Err != nil) != tt.wantErr {
+				t.Errorf("%s: authenticate() got err %v, recorded err %v, want err %v", tt.desc, err, got.Err, tt.wantErr)
+			}
+			if got.Principal != tt.want.Principal || got.Method != tt.want.Method {
+				t.Errorf("%s: authenticate() recorded %q with method %v, want %q with method %v", tt.desc, got.Principal, got.Method, tt.want.Principal, tt.want.Method)
+			}
+		}
+	})
+
+	t.Run("password removed", func(t *testing.T) {
+		in := metadata.NewIncomingContext(ctx, metadata.Pairs(usernameKey, "alice", passwordKey, "s3cret", "role", "admin"))
+		got, err := s.authenticate(in)
+		if err != nil {
+			t.Fatalf("authenticate() unexpected err: %v", err)
+		}
+		md, _ := metadata.FromIncomingContext(got)
+		if want := metadata.Pairs(usernameKey, "alice", "role", "admin"); !cmp.Equal(md, want) {
+			t.Errorf("authenticate() got metadata %v, want %v", md, want)
+		}
+	})
+}
+
+func TestCertPrincipal(t *testing.T) {
+	spiffeID, err := url.Parse("spiffe://example.org/ns/ops/sa/alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spiffeCert := &x509.Certificate{URIs: []*url.URL{spiffeID}, DNSNames: []string{"alice.example.org"}, Subject: pkix.Name{CommonName: "alice"}}
+	dnsCert := &x509.Certificate{DNSNames: []string{"bob.example.org"}, Subject: pkix.Name{CommonName: "bob"}}
+	withCert := func(cert *x509.Certificate, kv ...string) context.Context {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+		return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}})
+	}
+	tests := []struct {
+		desc       string
+		principals map[string]string
+		ctx        context.Context
+		want       string
+		wantCode   codes.Code
+	}{{
+		desc: "SPIFFE ID",
+		ctx:  withCert(spiffeCert),
+		want: "spiffe://example.org/ns/ops/sa/alice",
+	}, {
+		desc: "DNS SAN",
+		ctx:  withCert(dnsCert),
+		want: "bob.example.org",
+	}, {
+		desc:       "mapped SPIFFE ID",
+		principals: map[string]string{"spiffe://example.org/ns/ops/sa/alice": "alice"},
+		ctx:        withCert(spiffeCert),
+		want:       "alice",
+	}, {
+		desc:       "unmapped identity",
+		principals: map[string]string{"spiffe://example.org/ns/ops/sa/alice": "alice"},
+		ctx:        withCert(dnsCert),
+		wantCode:   codes.Unauthenticated,
+	}, {
+		desc:       "matching username",
+		principals: map[string]string{"spiffe://example.org/ns/ops/sa/alice": "alice"},
+		ctx:        withCert(spiffeCert, usernameKey, "alice"),
+		want:       "alice",
+	}, {
+		desc:       "impersonation",
+		principals: map[string]string{"spiffe://example.org/ns/ops/sa/alice": "alice"},
+		ctx:        withCert(spiffeCert, usernameKey, "admin"),
+		wantCode:   codes.Unauthenticated,
+	}, {
+		desc:       "wrong password",
+		principals: map[string]string{"spiffe://example.org/ns/ops/sa/alice": "alice"},
+		ctx:        withCert(spiffeCert, passwordKey, "Hello world!"),
+		wantCode:   codes.Unauthenticated,
+	}, {
+		desc:       "password",
+		principals: map[string]string{"spiffe://example.org/ns/ops/sa/alice": "alice"},
+		ctx:        withCert(spiffeCert, passwordKey, "s3cret"),
+		want:       "alice",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s, client, closeFn := start(t)
+			defer closeFn()
+			rotate(t, client, plaintext("alice", "s3cret", "1"))
+			s.SetCertPrincipals(tt.principals)
+			ctx, err := s.authenticate(tt.ctx)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("authenticate() got err %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			md, _ := metadata.FromIncomingContext(ctx)
+			if got := md.Get(usernameKey); !cmp.Equal(got, []string{tt.want}) {
+				t.Errorf("authenticate() got username %v, want %q", got, tt.want)
+			}
+			if got := md.Get(passwordKey); len(got) != 0 {
+				t.Errorf("authenticate() got password %v, want none", got)
+			}
+		})
+	}
+}
+
+func TestPublishState(t *testing.T) {
+	s, client, closeFn := start(t)
+	defer closeFn()
+	ctx := context.Background()
+	key, _ := newSigner(t)
+	rotate(t, client, plaintext("alice", "s3cret", "1"), authorizedKeys(t, "alice", ssh.MarshalAuthorizedKey(key.PublicKey())))
+
+	gnmiServer, err := gnmi.New(grpc.NewServer(), "local", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ygnmi.NewClient(gnmiServer.LocalClient(), ygnmi.WithTarget("local"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.publishState(ctx, c); err != nil {
+		t.Fatalf("publishState() unexpected err: %v", err)
+	}
+	// The state is applied to the cache asynchronously.
+	watchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	val, err := ygnmi.Watch(watchCtx, c, ocpath.Root().System().Aaa().Authentication().User("alice").State(), func(v *ygnmi.Value[*oc.System_Aaa_Authentication_User]) error {
+		if _, ok := v.Val(); ok {
+			return nil
+		}
+		return ygnmi.Continue
+	}).Await()
+	if err != nil {
+		t.Fatalf("failed to get user state: %v", err)
+	}
+	user, _ := val.Val()
+	if got, want := []any{user.GetPasswordVersion(), user.GetAuthorizedKeysListVersion(), user.GetAuthorizedKeysListCreatedOn(), user.AuthorizedPrincipalsListVersion}, []any{"1", "1", uint64(100), (*string)(nil)}; !cmp.Equal(got, want) {
+		t.Errorf("user versions got %v, want %v", got, want)
+	}
+}
+
+// rotate rotates and finalizes the account credentials.
+func rotate(t testing.TB, client cpb.CredentialzClient, reqs ...*cpb.RotateAccountCredentialsRequest) {
+	t.Helper()
+	rot, err := client.RotateAccountCredentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range reqs {
+		if err := rot.Send(req); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rot.Recv(); err != nil {
+			t.Fatalf("RotateAccountCredentials() unexpected err: %v", err)
+		}
+	}
+	if err := rot.Send(accountFinalize); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rot.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("RotateAccountCredentials() finalize got err %v, want EOF", err)
+	}
+}
+
+func start(t testing.TB) (*Server, cpb.CredentialzClient, func()) {
+	t.Helper()
+	credzServer := New()
+	credzServer.SetAllowAnonymous(true)
+
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(credzServer.Unary), grpc.ChainStreamInterceptor(credzServer.Stream))
+	cpb.RegisterCredentialzServer(s, credzServer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:")
+	if err != nil {
+		t.Fatalf("failed to start listener: %v", err)
+	}
+
+	go s.Serve(l)
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed dial server: %v", err)
+	}
+	return credzServer, cpb.NewCredentialzClient(conn), func() { s.Stop() }
+}
+
+func TestMarshalState(t *testing.T) {
+	s, client, closeFn := start(t)
+	defer closeFn()
+	ctx := context.Background()
+	_, key := newSigner(t)
+	rotate(t, client, plaintext("alice", "s3cret", "1"), cryptoHash("bob", cpb.PasswordRequest_CryptoHash_HASH_TYPE_CRYPT_MD5, md5Hash), principals("alice", "alice@corp"))
+
+	rot, err := client.RotateHostParameters(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range []*cpb.RotateHostParametersRequest{
+		{Request: &cpb.RotateHostParametersRequest_ServerKeys{ServerKeys: &cpb.ServerKeysRequest{
+			AuthArtifacts: []*cpb.ServerKeysRequest_AuthenticationArtifacts{{PrivateKey: key}},
+			Version:       "2",
+			CreatedOn:     200,
+		}}},
+		{Request: &cpb.RotateHostParametersRequest_GenerateKeys{GenerateKeys: &cpb.GenerateKeysRequest{
+			KeyParams: []cpb.KeyGen{cpb.KeyGen_KEY_GEN_SSH_KEY_TYPE_ECDSA_P_256},
+			Version:   "3",
+		}}},
+	} {
+		if err := rot.Send(req); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rot.Recv(); err != nil {
+			t.Fatalf("RotateHostParameters() unexpected err: %v", err)
+		}
+	}
+	if err := rot.Send(&cpb.RotateHostParametersRequest{Request: &cpb.RotateHostParametersRequest_Finalize{Finalize: &cpb.FinalizeRequest{}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rot.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("RotateHostParameters() finalize got err %v, want EOF", err)
+	}
+
+	b, err := s.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState() unexpected err: %v", err)
+	}
+	restored := New()
+	if err := restored.UnmarshalState(b); err != nil {
+		t.Fatalf("UnmarshalState() unexpected err: %v", err)
+	}
+	for user, password := range map[string]string{"alice": "s3cret", "bob": "Hello world!"} {
+		if err := restored.checkAccountPassword(user, []string{password}); err != nil {
+			t.Errorf("UnmarshalState() password of %s not restored: %v", user, err)
+		}
+	}
+	if got, want := restored.getAccounts()["alice"], s.getAccounts()["alice"]; got.passwordMeta != want.passwordMeta || got.principalsMeta != want.principalsMeta || len(got.principals) != len(want.principals) {
+		t.Errorf("UnmarshalState() alice got %+v, want %+v", got, want)
+	}
+	host, wantHost := restored.getHost(), s.getHost()
+	if got, want := [3]meta{host.keysMeta, host.certMeta, host.caKeysMeta}, [3]meta{wantHost.keysMeta, wantHost.certMeta, wantHost.caKeysMeta}; got != want {
+		t.Errorf("UnmarshalState() host versions got %v, want %v", got, want)
+	}
+	keys, err := s.GetPublicKeys(ctx, &cpb.GetPublicKeysRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	restoredKeys, err := restored.GetPublicKeys(ctx, &cpb.GetPublicKeysRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(keys.GetPublicKeys(), restoredKeys.GetPublicKeys(), protocmp.Transform()); diff != "" {
+		t.Errorf("UnmarshalState() host keys diff (-want, +got):\n%s", diff)
+	}
+}
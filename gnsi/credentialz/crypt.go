@@ -0,0 +1,277 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialz
+
+import (
+	"crypto/md5" //nolint:gosec // MD5 crypt is required by credentialz.
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cryptAlphabet is the base64 alphabet of crypt(3).
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const (
+	md5Prefix    = "$1$"
+	sha512Prefix = "$6$"
+
+	sha512DefaultRounds = 5000
+	sha512MinRounds     = 1000
+	sha512MaxRounds     = 999999999
+)
+
+// b64From24Bit appends the n crypt(3) base64 characters of the 3 bytes.
+func b64From24Bit(out []byte, b2, b1, b0 byte, n int) []byte {
+	w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+	for ; n > 0; n-- {
+		out = append(out, cryptAlphabet[w&0x3f])
+		w >>= 6
+	}
+	return out
+}
+
+// md5Crypt returns the MD5-crypt hash of the password with the salt.
+func md5Crypt(password, salt string) string {
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+	pw := []byte(password)
+
+	alt := md5.New() //nolint:gosec // MD5 crypt is required by credentialz.
+	alt.Write(pw)
+	alt.Write([]byte(salt))
+	alt.Write(pw)
+	final := alt.Sum(nil)
+
+	h := md5.New() //nolint:gosec // MD5 crypt is required by credentialz.
+	h.Write(pw)
+	h.Write([]byte(md5Prefix))
+	h.Write([]byte(salt))
+	for n := len(pw); n > 0; n -= 16 {
+		h.Write(final[:min(n, 16)])
+	}
+	for n := len(pw); n > 0; n >>= 1 {
+		if n&1 == 1 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(pw[:1])
+		}
+	}
+	final = h.Sum(nil)
+
+	for i := 0; i < 1000; i++ {
+		h := md5.New() //nolint:gosec // MD5 crypt is required by credentialz.
+		if i&1 == 1 {
+			h.Write(pw)
+		} else {
+			h.Write(final)
+		}
+		if i%3 != 0 {
+			h.Write([]byte(salt))
+		}
+		if i%7 != 0 {
+			h.Write(pw)
+		}
+		if i&1 == 1 {
+			h.Write(final)
+		} else {
+			h.Write(pw)
+		}
+		final = h.Sum(nil)
+	}
+
+	out := []byte(md5Prefix + salt + "$")
+	for i := 0; i < 4; i++ {
+		out = b64From24Bit(out, final[i], final[i+6], final[i+12], 4)
+	}
+	out = b64From24Bit(out, final[4], final[10], final[5], 4)
+	out = b64From24Bit(out, 0, 0, final[11], 2)
+	return string(out)
+}
+
+// repeatDigest returns n bytes of the digest repeated.
+func repeatDigest(d []byte, n int) []byte {
+	out := make([]byte, 0, n)
+	for len(out)+len(d) <= n {
+		out = append(out, d...)
+	}
+	return append(out, d[:n-len(out)]...)
+}
+
+// sha512Crypt returns the SHA512-crypt hash of the password with the salt,
+// using the default number of rounds when rounds is 0.
+func sha512Crypt(password, salt string, rounds int) string {
+	if len(salt) > 16 {
+		salt = salt[:16]
+	}
+	customRounds := rounds != 0
+	if !customRounds {
+		rounds = sha512DefaultRounds
+	}
+	rounds = max(sha512MinRounds, min(rounds, sha512MaxRounds))
+	pw, s := []byte(password), []byte(salt)
+
+	alt := sha512.New()
+	alt.Write(pw)
+	alt.Write(s)
+	alt.Write(pw)
+	b := alt.Sum(nil)
+
+	h := sha512.New()
+	h.Write(pw)
+	h.Write(s)
+	h.Write(repeatDigest(b, len(pw)))
+	for n := len(pw); n > 0; n >>= 1 {
+		if n&1 == 1 {
+			h.Write(b)
+		} else {
+			h.Write(pw)
+		}
+	}
+	a := h.Sum(nil)
+
+	dp := sha512.New()
+	for range pw {
+		dp.Write(pw)
+	}
+	p := repeatDigest(dp.Sum(nil), len(pw))
+
+	ds := sha512.New()
+	for i := 0; i < 16+int(a[0]); i++ {
+		ds.Write(s)
+	}
+	sBytes := repeatDigest(ds.Sum(nil), len(s))
+
+	for i := 0; i < rounds; i++ {
+		c := sha512.New()
+		if i&1 == 1 {
+			c.Write(p)
+		} else {
+			c.Write(a)
+		}
+		if i%3 != 0 {
+			c.Write(sBytes)
+		}
+		if i%7 != 0 {
+			c.Write(p)
+		}
+		if i&1 == 1 {
+			c.Write(a)
+		} else {
+			c.Write(p)
+		}
+		a = c.Sum(nil)
+	}
+
+	out := []byte(sha512Prefix)
+	if customRounds {
+		out = append(out, fmt.Sprintf("rounds=%d$", rounds)...)
+	}
+	out = append(out, salt...)
+	out = append(out, '$')
+	for i := 0; i < 21; i++ {
+		x, y, z := a[i], a[i+21], a[i+42]
+		switch i % 3 {
+		case 1:
+			x, y, z = y, z, x
+		case 2:
+			x, y, z = z, x, y
+		}
+		out = b64From24Bit(out, x, y, z, 4)
+	}
+	out = b64From24Bit(out, 0, 0, a[63], 2)
+	return string(out)
+}
+
+// hashPassword returns the SHA512-crypt hash of the password with a random
+// salt.
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	for i, b := range salt {
+		salt[i] = cryptAlphabet[int(b)%len(cryptAlphabet)]
+	}
+	return sha512Crypt(password, string(salt), 0), nil
+}
+
+// parseSHA512 returns the salt, rounds and checksum of a SHA512-crypt hash.
+func parseSHA512(hash string) (string, int, string, error) {
+	rest := strings.TrimPrefix(hash, sha512Prefix)
+	rounds := 0
+	if r, ok := strings.CutPrefix(rest, "rounds="); ok {
+		n, tail, ok := strings.Cut(r, "$")
+		if !ok {
+			return "", 0, "", fmt.Errorf("invalid rounds")
+		}
+		var err error
+		if rounds, err = strconv.Atoi(n); err != nil {
+			return "", 0, "", fmt.Errorf("invalid rounds: %v", err)
+		}
+		rest = tail
+	}
+	salt, sum, ok := strings.Cut(rest, "$")
+	if !ok {
+		return "", 0, "", fmt.Errorf("missing checksum")
+	}
+	return salt, rounds, sum, nil
+}
+
+// validateHash checks that the hash is a crypt(3) hash of the given prefix.
+func validateHash(hash, prefix string) error {
+	if !strings.HasPrefix(hash, prefix) {
+		return fmt.Errorf("hash does not start with %q", prefix)
+	}
+	switch prefix {
+	case md5Prefix:
+		salt, sum, ok := strings.Cut(strings.TrimPrefix(hash, md5Prefix), "$")
+		if !ok || len(salt) > 8 || len(sum) != 22 {
+			return fmt.Errorf("invalid MD5-crypt hash")
+		}
+	case sha512Prefix:
+		salt, _, sum, err := parseSHA512(hash)
+		if err != nil {
+			return fmt.Errorf("invalid SHA512-crypt hash: %v", err)
+		}
+		if len(salt) > 16 || len(sum) != 86 {
+			return fmt.Errorf("invalid SHA512-crypt hash")
+		}
+	}
+	return nil
+}
+
+// checkPassword reports whether the password matches the crypt(3) hash.
+func checkPassword(hash, password string) bool {
+	var got string
+	switch {
+	case strings.HasPrefix(hash, md5Prefix):
+		salt, _, _ := strings.Cut(strings.TrimPrefix(hash, md5Prefix), "$")
+		got = md5Crypt(password, salt)
+	case strings.HasPrefix(hash, sha512Prefix):
+		salt, rounds, _, err := parseSHA512(hash)
+		if err != nil {
+			return false
+		}
+		got = sha512Crypt(password, salt, rounds)
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(hash)) == 1
+}
@@ -0,0 +1,296 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialz
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"io"
+
+	log "github.com/golang/glog"
+	"golang.org/x/crypto/ssh"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cpb "github.com/openconfig/gnsi/credentialz"
+)
+
+// hostParams are the SSH host parameters of the device. They are never
+// modified once stored, a rotation replaces them.
+type hostParams struct {
+	caKeys     []*cpb.PublicKey
+	caKeysMeta meta
+	keys       []ssh.Signer
+	keysMeta   meta
+	// keyArtifacts are the artifacts the keys and certs were uploaded as, or
+	// would be if they were generated.
+	keyArtifacts []*cpb.ServerKeysRequest_AuthenticationArtifacts
+	certs        []*ssh.Certificate
+	certMeta     meta
+	// allowedAuth is empty if all authentication types are allowed.
+	allowedAuth    []cpb.AuthenticationType
+	principalCheck cpb.AuthorizedPrincipalCheckRequest_Tool
+}
+
+// keyGens are the host keys the device can generate.
+var keyGens = map[cpb.KeyGen]func() (crypto.Signer, error){
+	cpb.KeyGen_KEY_GEN_SSH_KEY_TYPE_RSA_2048: func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 2048) },
+	cpb.KeyGen_KEY_GEN_SSH_KEY_TYPE_RSA_4096: func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 4096) },
+	cpb.KeyGen_KEY_GEN_SSH_KEY_TYPE_ECDSA_P_256: func() (crypto.Signer, error) {
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	},
+	cpb.KeyGen_KEY_GEN_SSH_KEY_TYPE_ECDSA_P_521: func() (crypto.Signer, error) {
+		return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	},
+	cpb.KeyGen_KEY_GEN_SSH_KEY_TYPE_EDDSA_ED25519: func() (crypto.Signer, error) {
+		_, k, err := ed25519.GenerateKey(rand.Reader)
+		return k, err
+	},
+}
+
+// keyType returns the credentialz type of an SSH public key.
+func keyType(pub ssh.PublicKey) cpb.KeyType {
+	switch pub.Type() {
+	case ssh.KeyAlgoED25519:
+		return cpb.KeyType_KEY_TYPE_ED25519
+	case ssh.KeyAlgoECDSA256:
+		return cpb.KeyType_KEY_TYPE_ECDSA_P_256
+	case ssh.KeyAlgoECDSA521:
+		return cpb.KeyType_KEY_TYPE_ECDSA_P_521
+	case ssh.KeyAlgoRSA:
+		cpk, ok := pub.(ssh.CryptoPublicKey)
+		if !ok {
+			break
+		}
+		if k, ok := cpk.CryptoPublicKey().(*rsa.PublicKey); ok {
+			switch k.N.BitLen() {
+			case 2048:
+				return cpb.KeyType_KEY_TYPE_RSA_2048
+			case 4096:
+				return cpb.KeyType_KEY_TYPE_RSA_4096
+			}
+		}
+	}
+	return cpb.KeyType_KEY_TYPE_UNSPECIFIED
+}
+
+func publicKey(pub ssh.PublicKey) *cpb.PublicKey {
+	return &cpb.PublicKey{
+		PublicKey: bytes.TrimSpace(ssh.MarshalAuthorizedKey(pub)),
+		KeyType:   keyType(pub),
+	}
+}
+
+func (s *Server) getHost() *hostParams {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.host
+}
+
+func (s *Server) setHost(h *hostParams) {
+	s.mu.Lock()
+	s.host = h
+	s.mu.Unlock()
+	s.markDirty()
+}
+
+// RotateHostParameters implements the credentialz RotateHostParameters RPC.
+// The parameters are used immediately, and the previous parameters are
+// restored if the stream ends before the rotation is finalized.
+func (s *Server) RotateHostParameters(rs cpb.Credentialz_RotateHostParametersServer) error {
+	if !s.hostRotationInProgress.CompareAndSwap(false, true) {
+		return status.Error(codes.Unavailable, "another host parameters rotation is already in progress")
+	}
+	defer s.hostRotationInProgress.Store(false)
+
+	prev := s.getHost()
+	changed, finalized := false, false
+	defer func() {
+		if changed && !finalized {
+			log.Info("credentialz host parameters rotation not finalized, rolling back the parameters")
+			s.setHost(prev)
+		}
+	}()
+
+	for {
+		req, err := rs.Recv()
+		if errors.Is(err, io.EOF) {
+			return status.Error(codes.Aborted, "stream closed before the rotation was finalized")
+		}
+		if err != nil {
+			return err
+		}
+		if _, ok := req.Request.(*cpb.RotateHostParametersRequest_Finalize); ok {
+			if !changed {
+				return status.Error(codes.FailedPrecondition, "finalize called before any parameter was rotated")
+			}
+			finalized = true
+			s.finalizeHost()
+			return nil
+		}
+		resp, err := s.rotateHost(req)
+		if err != nil {
+			return err
+		}
+		changed = true
+		if err := rs.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// rotateHost applies a host parameters rotation request to the parameters in
+// use.
+func (s *Server) rotateHost(req *cpb.RotateHostParametersRequest) (*cpb.RotateHostParametersResponse, error) {
+	next := *s.getHost()
+	resp := &cpb.RotateHostParametersResponse{}
+	switch r := req.Request.(type) {
+	case *cpb.RotateHostParametersRequest_SshCaPublicKey:
+		for _, k := range r.SshCaPublicKey.GetSshCaPublicKeys() {
+			if _, _, _, _, err := ssh.ParseAuthorizedKey(k.GetPublicKey()); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid CA public key: %v", err)
+			}
+		}
+		next.caKeys = r.SshCaPublicKey.GetSshCaPublicKeys()
+		next.caKeysMeta = meta{version: r.SshCaPublicKey.GetVersion(), createdOn: r.SshCaPublicKey.GetCreatedOn()}
+		resp.Response = &cpb.RotateHostParametersResponse_SshCaPublicKey{SshCaPublicKey: &cpb.CaPublicKeyResponse{}}
+	case *cpb.RotateHostParametersRequest_ServerKeys:
+		keys, certs, err := parseServerKeys(r.ServerKeys.GetAuthArtifacts())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid server keys: %v", err)
+		}
+		m := meta{version: r.ServerKeys.GetVersion(), createdOn: r.ServerKeys.GetCreatedOn()}
+		next.keys, next.keysMeta = keys, m
+		next.keyArtifacts = r.ServerKeys.GetAuthArtifacts()
+		next.certs, next.certMeta = certs, meta{}
+		if len(certs) > 0 {
+			next.certMeta = m
+		}
+		resp.Response = &cpb.RotateHostParametersResponse_ServerKeys{ServerKeys: &cpb.ServerKeysResponse{}}
+	case *cpb.RotateHostParametersRequest_GenerateKeys:
+		keys, artifacts, err := generateKeys(r.GenerateKeys.GetKeyParams())
+		if err != nil {
+			return nil, err
+		}
+		next.keys, next.keyArtifacts = keys, artifacts
+		next.keysMeta = meta{version: r.GenerateKeys.GetVersion(), createdOn: r.GenerateKeys.GetCreatedOn()}
+		next.certs, next.certMeta = nil, meta{}
+		gen := &cpb.GenerateKeysResponse{}
+		for _, k := range keys {
+			gen.PublicKeys = append(gen.PublicKeys, publicKey(k.PublicKey()))
+		}
+		resp.Response = &cpb.RotateHostParametersResponse_GenerateKeys{GenerateKeys: gen}
+	case *cpb.RotateHostParametersRequest_AuthenticationAllowed:
+		next.allowedAuth = r.AuthenticationAllowed.GetAuthenticationTypes()
+		resp.Response = &cpb.RotateHostParametersResponse_AuthenticationAllowed{AuthenticationAllowed: &cpb.AllowedAuthenticationResponse{}}
+	case *cpb.RotateHostParametersRequest_AuthorizedPrincipalCheck:
+		if r.AuthorizedPrincipalCheck.GetTool() == cpb.AuthorizedPrincipalCheckRequest_TOOL_UNSPECIFIED {
+			return nil, status.Error(codes.InvalidArgument, "authorized principal check tool not specified")
+		}
+		next.principalCheck = r.AuthorizedPrincipalCheck.GetTool()
+		resp.Response = &cpb.RotateHostParametersResponse_AuthorizedPrincipalCheck{AuthorizedPrincipalCheck: &cpb.AuthorizedPrincipalCheckResponse{}}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown request type %T", r)
+	}
+	s.setHost(&next)
+	return resp, nil
+}
+
+// parseServerKeys returns the host keys and certificates of the artifacts.
+func parseServerKeys(artifacts []*cpb.ServerKeysRequest_AuthenticationArtifacts) ([]ssh.Signer, []*ssh.Certificate, error) {
+	if len(artifacts) == 0 {
+		return nil, nil, errors.New("no authentication artifact")
+	}
+	var keys []ssh.Signer
+	var certs []*ssh.Certificate
+	for _, a := range artifacts {
+		key, err := ssh.ParsePrivateKey(a.GetPrivateKey())
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		if len(a.GetCertificate()) == 0 {
+			continue
+		}
+		pub, _, _, _, err := ssh.ParseAuthorizedKey(a.GetCertificate())
+		if err != nil {
+			return nil, nil, err
+		}
+		cert, ok := pub.(*ssh.Certificate)
+		if !ok {
+			return nil, nil, errors.New("certificate is not an SSH certificate")
+		}
+		if !bytes.Equal(cert.Key.Marshal(), key.PublicKey().Marshal()) {
+			return nil, nil, errors.New("certificate does not match the private key")
+		}
+		certs = append(certs, cert)
+	}
+	return keys, certs, nil
+}
+
+// generateKeys generates a host key of each type, and returns them with the
+// artifacts uploading them.
+func generateKeys(gens []cpb.KeyGen) ([]ssh.Signer, []*cpb.ServerKeysRequest_AuthenticationArtifacts, error) {
+	if len(gens) == 0 {
+		return nil, nil, status.Error(codes.InvalidArgument, "no key type specified")
+	}
+	var keys []ssh.Signer
+	var artifacts []*cpb.ServerKeysRequest_AuthenticationArtifacts
+	for _, g := range gens {
+		gen, ok := keyGens[g]
+		if !ok {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "unsupported key type %v", g)
+		}
+		k, err := gen()
+		if err != nil {
+			return nil, nil, status.Errorf(codes.Internal, "cannot generate %v key: %v", g, err)
+		}
+		signer, err := ssh.NewSignerFromKey(k)
+		if err != nil {
+			return nil, nil, status.Errorf(codes.Internal, "cannot generate %v key: %v", g, err)
+		}
+		block, err := ssh.MarshalPrivateKey(k, "")
+		if err != nil {
+			return nil, nil, status.Errorf(codes.Internal, "cannot marshal %v key: %v", g, err)
+		}
+		keys = append(keys, signer)
+		artifacts = append(artifacts, &cpb.ServerKeysRequest_AuthenticationArtifacts{PrivateKey: pem.EncodeToMemory(block)})
+	}
+	return keys, artifacts, nil
+}
+
+// CanGenerateKey implements the credentialz CanGenerateKey RPC.
+func (s *Server) CanGenerateKey(_ context.Context, req *cpb.CanGenerateKeyRequest) (*cpb.CanGenerateKeyResponse, error) {
+	_, ok := keyGens[req.GetKeyParams()]
+	return &cpb.CanGenerateKeyResponse{CanGenerate: ok}, nil
+}
+
+// GetPublicKeys implements the credentialz GetPublicKeys RPC, which returns
+// the public host keys.
+func (s *Server) GetPublicKeys(context.Context, *cpb.GetPublicKeysRequest) (*cpb.GetPublicKeysResponse, error) {
+	resp := &cpb.GetPublicKeysResponse{}
+	for _, k := range s.getHost().keys {
+		resp.PublicKeys = append(resp.PublicKeys, publicKey(k.PublicKey()))
+	}
+	return resp, nil
+}
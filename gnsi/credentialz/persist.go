@@ -0,0 +1,204 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentialz
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+
+	cpb "github.com/openconfig/gnsi/credentialz"
+)
+
+// OnFinalize sets a function called once a rotation is finalized, while no
+// other rotation of the same credentials can start. It must be set before the
+// server is serving.
+func (s *Server) OnFinalize(f func()) {
+	s.onFinalize = f
+}
+
+func (s *Server) finalizeAccounts() {
+	s.mu.Lock()
+	s.finalizedAccounts = s.accounts
+	s.mu.Unlock()
+	if s.onFinalize != nil {
+		s.onFinalize()
+	}
+}
+
+func (s *Server) finalizeHost() {
+	s.mu.Lock()
+	s.finalizedHost = s.host
+	s.mu.Unlock()
+	if s.onFinalize != nil {
+		s.onFinalize()
+	}
+}
+
+// accountRequests returns the rotation requests restoring the accounts.
+func accountRequests(accounts map[string]*account) []*cpb.RotateAccountCredentialsRequest {
+	pws := &cpb.PasswordRequest{}
+	keys := &cpb.AuthorizedKeysRequest{}
+	users := &cpb.AuthorizedUsersRequest{}
+	for _, name := range slices.Sorted(maps.Keys(accounts)) {
+		acc := accounts[name]
+		if acc.passwordHash != "" {
+			hashType := cpb.PasswordRequest_CryptoHash_HASH_TYPE_CRYPT_SHA_2_512
+			if strings.HasPrefix(acc.passwordHash, md5Prefix) {
+				hashType = cpb.PasswordRequest_CryptoHash_HASH_TYPE_CRYPT_MD5
+			}
+			pws.Accounts = append(pws.Accounts, &cpb.PasswordRequest_Account{
+				Account: name,
+				Password: &cpb.PasswordRequest_Password{Value: &cpb.PasswordRequest_Password_CryptoHash{CryptoHash: &cpb.PasswordRequest_CryptoHash{
+					HashType:  hashType,
+					HashValue: acc.passwordHash,
+				}}},
+				Version:   acc.passwordMeta.version,
+				CreatedOn: acc.passwordMeta.createdOn,
+			})
+		}
+		if acc.keys != nil || acc.keysMeta != (meta{}) {
+			keys.Credentials = append(keys.Credentials, &cpb.AccountCredentials{
+				Account:        name,
+				AuthorizedKeys: acc.keys,
+				Version:        acc.keysMeta.version,
+				CreatedOn:      acc.keysMeta.createdOn,
+			})
+		}
+		if acc.principals != nil || acc.principalsMeta != (meta{}) {
+			users.Policies = append(users.Policies, &cpb.UserPolicy{
+				Account:              name,
+				AuthorizedPrincipals: &cpb.UserPolicy_SshAuthorizedPrincipals{AuthorizedPrincipals: acc.principals},
+				Version:              acc.principalsMeta.version,
+				CreatedOn:            acc.principalsMeta.createdOn,
+			})
+		}
+	}
+	var reqs []*cpb.RotateAccountCredentialsRequest
+	if len(pws.Accounts) > 0 {
+		reqs = append(reqs, &cpb.RotateAccountCredentialsRequest{Request: &cpb.RotateAccountCredentialsRequest_Password{Password: pws}})
+	}
+	if len(keys.Credentials) > 0 {
+		reqs = append(reqs, &cpb.RotateAccountCredentialsRequest{Request: &cpb.RotateAccountCredentialsRequest_Credential{Credential: keys}})
+	}
+	if len(users.Policies) > 0 {
+		reqs = append(reqs, &cpb.RotateAccountCredentialsRequest{Request: &cpb.RotateAccountCredentialsRequest_User{User: users}})
+	}
+	return reqs
+}
+
+// hostRequests returns the rotation requests restoring the host parameters.
+func hostRequests(h *hostParams) []*cpb.RotateHostParametersRequest {
+	var reqs []*cpb.RotateHostParametersRequest
+	if len(h.caKeys) > 0 || h.caKeysMeta != (meta{}) {
+		reqs = append(reqs, &cpb.RotateHostParametersRequest{Request: &cpb.RotateHostParametersRequest_SshCaPublicKey{SshCaPublicKey: &cpb.CaPublicKeyRequest{
+			SshCaPublicKeys: h.caKeys,
+			Version:         h.caKeysMeta.version,
+			CreatedOn:       h.caKeysMeta.createdOn,
+		}}})
+	}
+	if len(h.keyArtifacts) > 0 {
+		reqs = append(reqs, &cpb.RotateHostParametersRequest{Request: &cpb.RotateHostParametersRequest_ServerKeys{ServerKeys: &cpb.ServerKeysRequest{
+			AuthArtifacts: h.keyArtifacts,
+			Version:       h.keysMeta.version,
+			CreatedOn:     h.keysMeta.createdOn,
+		}}})
+	}
+	if len(h.allowedAuth) > 0 {
+		reqs = append(reqs, &cpb.RotateHostParametersRequest{Request: &cpb.RotateHostParametersRequest_AuthenticationAllowed{AuthenticationAllowed: &cpb.AllowedAuthenticationRequest{
+			AuthenticationTypes: h.allowedAuth,
+		}}})
+	}
+	if h.principalCheck != cpb.AuthorizedPrincipalCheckRequest_TOOL_UNSPECIFIED {
+		reqs = append(reqs, &cpb.RotateHostParametersRequest{Request: &cpb.RotateHostParametersRequest_AuthorizedPrincipalCheck{AuthorizedPrincipalCheck: &cpb.AuthorizedPrincipalCheckRequest{
+			Tool: h.principalCheck,
+		}}})
+	}
+	return reqs
+}
+
+// accountsEnd ends the account requests of the marshaled state, which are
+// followed by the host parameters requests.
+var accountsEnd = &cpb.RotateAccountCredentialsRequest{Request: &cpb.RotateAccountCredentialsRequest_Finalize{Finalize: &cpb.FinalizeRequest{}}}
+
+// MarshalState returns the finalized credentials, stored as the account
+// credentials rotation requests restoring them up to a finalize request,
+// followed by the host parameters rotation requests restoring them.
+func (s *Server) MarshalState() ([]byte, error) {
+	s.mu.RLock()
+	accounts, host := s.finalizedAccounts, s.finalizedHost
+	s.mu.RUnlock()
+	var msgs []proto.Message
+	for _, req := range accountRequests(accounts) {
+		msgs = append(msgs, req)
+	}
+	msgs = append(msgs, accountsEnd)
+	for _, req := range hostRequests(host) {
+		msgs = append(msgs, req)
+	}
+	var b bytes.Buffer
+	for _, m := range msgs {
+		if _, err := protodelim.MarshalTo(&b, m); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalState restores and finalizes the credentials returned by
+// MarshalState.
+func (s *Server) UnmarshalState(b []byte) error {
+	r := bufio.NewReader(bytes.NewReader(b))
+	for {
+		req := &cpb.RotateAccountCredentialsRequest{}
+		err := protodelim.UnmarshalFrom(r, req)
+		if errors.Is(err, io.EOF) {
+			return errors.New("missing end of the account credentials")
+		}
+		if err != nil {
+			return err
+		}
+		if req.GetFinalize() != nil {
+			break
+		}
+		if _, err := s.rotateAccounts(req); err != nil {
+			return err
+		}
+	}
+	for {
+		req := &cpb.RotateHostParametersRequest{}
+		err := protodelim.UnmarshalFrom(r, req)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := s.rotateHost(req); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	s.finalizedAccounts, s.finalizedHost = s.accounts, s.host
+	s.mu.Unlock()
+	return nil
+}
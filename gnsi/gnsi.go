@@ -15,82 +15,181 @@
 package gnsi
 
 import (
-	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
 
+	log "github.com/golang/glog"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
+	acctzpb "github.com/openconfig/gnsi/acctz"
 	authzpb "github.com/openconfig/gnsi/authz"
 	certzpb "github.com/openconfig/gnsi/certz"
 	credentialzpb "github.com/openconfig/gnsi/credentialz"
 	pathzpb "github.com/openconfig/gnsi/pathz"
 
+	"github.com/openconfig/lemming/gnsi/acctz"
+	"github.com/openconfig/lemming/gnsi/authz"
+	"github.com/openconfig/lemming/gnsi/certz"
+	"github.com/openconfig/lemming/gnsi/credentialz"
 	"github.com/openconfig/lemming/gnsi/pathz"
 )
 
-type authz struct {
-	authzpb.UnimplementedAuthzServer
-}
-
-func (a *authz) Rotate(authzpb.Authz_RotateServer) error {
-	return status.Errorf(codes.Unimplemented, "Fake UnImplemented")
-}
-
-func (a *authz) Get(context.Context, *authzpb.GetRequest) (*authzpb.GetResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "Fake UnImplemented")
+// Server is a fake gNSI implementation.
+type Server struct {
+	s     *grpc.Server
+	authz *authz.Server
+	certz *certz.Server
+	pathz *pathz.Server
+	credz *credentialz.Server
+	acctz *acctz.Server
+	// stateDir is the directory persisting the finalized artifacts, if set.
+	stateDir string
 }
 
-type cert struct {
-	certzpb.UnimplementedCertzServer
+func (s *Server) GetPathZ() *pathz.Server {
+	return s.pathz
 }
 
-func (c *cert) CanGenerateCSR(context.Context, *certzpb.CanGenerateCSRRequest) (*certzpb.CanGenerateCSRResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "Fake UnImplemented")
+// GetAuthz returns the authz server enforcing the gRPC-level authorization
+// policy.
+func (s *Server) GetAuthz() *authz.Server {
+	return s.authz
 }
 
-func (c *cert) Rotate(certzpb.Certz_RotateServer) error {
-	return status.Errorf(codes.Unimplemented, "Fake UnImplemented")
+// GetCertz returns the certz server managing the SSL profiles.
+func (s *Server) GetCertz() *certz.Server {
+	return s.certz
 }
 
-type credentialz struct {
-	credentialzpb.UnimplementedCredentialzServer
+// GetCredentialz returns the credentialz server authenticating the users.
+func (s *Server) GetCredentialz() *credentialz.Server {
+	return s.credz
 }
 
-func (c *credentialz) MutateAccountCredentials(credentialzpb.Credentialz_RotateAccountCredentialsServer) error {
-	return status.Errorf(codes.Unimplemented, "Fake UnImplemented")
+// GetAcctz returns the acctz server recording the gRPC calls.
+func (s *Server) GetAcctz() *acctz.Server {
+	return s.acctz
 }
 
-func (c *credentialz) MutateHostCredentials(credentialzpb.Credentialz_RotateHostParametersServer) error {
-	return status.Errorf(codes.Unimplemented, "Fake UnImplemented")
-}
+// Option is an option of the gNSI server.
+type Option func(*Server)
 
-// Server is a fake gNSI implementation.
-type Server struct {
-	s     *grpc.Server
-	authz *authz
-	cert  *cert
-	pathz *pathz.Server
-	credz *credentialz
+// WithStateDir persists the finalized artifacts of the authz, certz,
+// credentialz and pathz servers, with their versions and creation times, in
+// dir. The artifacts stored in dir are restored by New, so that they survive
+// restarts of the device.
+func WithStateDir(dir string) Option {
+	return func(s *Server) {
+		s.stateDir = dir
+	}
 }
 
-func (s *Server) GetPathZ() *pathz.Server {
-	return s.pathz
+// persistentServer is a gNSI server whose finalized artifacts can be
+// persisted.
+type persistentServer interface {
+	// MarshalState returns the finalized artifacts, or nil if there are none.
+	MarshalState() ([]byte, error)
+	// UnmarshalState restores and finalizes the artifacts returned by
+	// MarshalState.
+	UnmarshalState([]byte) error
+	// OnFinalize sets a function called once artifacts are finalized.
+	OnFinalize(func())
 }
 
-// New returns a new fake gNMI server.
-func New(s *grpc.Server) *Server {
+// New returns a new fake gNSI server. The acctz, authz and credentialz
+// servers' interceptors must be installed on the gRPC servers they apply to,
+// the certz server's credentials on the listeners its profiles apply to, and
+// the pathz server on the gNMI server.
+func New(s *grpc.Server, authzServer *authz.Server, certzServer *certz.Server, credzServer *credentialz.Server, acctzServer *acctz.Server, pathzServer *pathz.Server, opts ...Option) (*Server, error) {
 	srv := &Server{
 		s:     s,
-		authz: &authz{},
-		cert:  &cert{},
-		pathz: &pathz.Server{},
-		credz: &credentialz{},
+		authz: authzServer,
+		certz: certzServer,
+		pathz: pathzServer,
+		credz: credzServer,
+		acctz: acctzServer,
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+	if srv.stateDir != "" {
+		if err := os.MkdirAll(srv.stateDir, 0o700); err != nil {
+			return nil, fmt.Errorf("cannot create gNSI state directory: %v", err)
+		}
+		for name, ps := range map[string]persistentServer{
+			"authz":       srv.authz,
+			"certz":       srv.certz,
+			"credentialz": srv.credz,
+			"pathz":       srv.pathz,
+		} {
+			if err := srv.restore(name, ps); err != nil {
+				return nil, err
+			}
+			ps.OnFinalize(func() {
+				if err := srv.persist(name, ps); err != nil {
+					log.Errorf("cannot persist gNSI %s artifacts: %v", name, err)
+				}
+			})
+		}
 	}
+	acctzpb.RegisterAcctzServer(s, srv.acctz)
+	acctzpb.RegisterAcctzStreamServer(s, srv.acctz.StreamServer())
 	authzpb.RegisterAuthzServer(s, srv.authz)
-	certzpb.RegisterCertzServer(s, srv.cert)
+	certzpb.RegisterCertzServer(s, srv.certz)
 	credentialzpb.RegisterCredentialzServer(s, srv.credz)
 	pathzpb.RegisterPathzServer(s, srv.pathz)
 
-	return srv
+	return srv, nil
+}
+
+// statePath returns the path of the file storing the artifacts of a server.
+func (s *Server) statePath(name string) string {
+	return filepath.Join(s.stateDir, name+".pb")
+}
+
+// restore restores the artifacts stored for a server, if any.
+func (s *Server) restore(name string, ps persistentServer) error {
+	b, err := os.ReadFile(s.statePath(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read gNSI %s artifacts: %v", name, err)
+	}
+	if err := ps.UnmarshalState(b); err != nil {
+		return fmt.Errorf("cannot restore gNSI %s artifacts: %v", name, err)
+	}
+	log.Infof("restored gNSI %s artifacts from %s", name, s.statePath(name))
+	return nil
+}
+
+// persist stores the finalized artifacts of a server, replacing the stored
+// artifacts atomically.
+func (s *Server) persist(name string, ps persistentServer) error {
+	b, err := ps.MarshalState()
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		if err := os.Remove(s.statePath(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	f, err := os.CreateTemp(s.stateDir, name+".pb.*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.statePath(name))
 }
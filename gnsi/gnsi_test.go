@@ -0,0 +1,163 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnsi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/openconfig/lemming/gnsi/acctz"
+	"github.com/openconfig/lemming/gnsi/authz"
+	"github.com/openconfig/lemming/gnsi/certz"
+	"github.com/openconfig/lemming/gnsi/credentialz"
+	"github.com/openconfig/lemming/gnsi/pathz"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	authzpb "github.com/openconfig/gnsi/authz"
+	pathzpb "github.com/openconfig/gnsi/pathz"
+)
+
+const testPolicy = `{
+  "name": "test policy",
+  "allow_rules": [{
+    "name": "admins",
+    "source": {"principals": ["alice"]},
+    "request": {"paths": ["/gnsi.authz.v1.Authz/*"]}
+  }]
+}`
+
+func TestStateDir(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	s, conn, stop := start(t, dir)
+	authzRot, err := authzpb.NewAuthzClient(conn).Rotate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range []*authzpb.RotateAuthzRequest{{
+		RotateRequest: &authzpb.RotateAuthzRequest_UploadRequest{UploadRequest: &authzpb.UploadRequest{Version: "1", CreatedOn: 100, Policy: testPolicy}},
+	}, {
+		RotateRequest: &authzpb.RotateAuthzRequest_FinalizeRotation{},
+	}} {
+		if err := authzRot.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := authzRot.Recv(); err != nil {
+		t.Fatalf("authz Rotate() unexpected err: %v", err)
+	}
+	if _, err := authzRot.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("authz Rotate() finalize got err %v, want EOF", err)
+	}
+	pathzRot, err := pathzpb.NewPathzClient(conn).Rotate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range []*pathzpb.RotateRequest{{
+		RotateRequest: &pathzpb.RotateRequest_UploadRequest{UploadRequest: &pathzpb.UploadRequest{
+			Version:   "2",
+			CreatedOn: 200,
+			Policy: &pathzpb.AuthorizationPolicy{Rules: []*pathzpb.AuthorizationRule{{
+				Path:      &gpb.Path{Elem: []*gpb.PathElem{{Name: "test"}}},
+				Principal: &pathzpb.AuthorizationRule_User{User: "alice"},
+				Mode:      pathzpb.Mode_MODE_READ,
+				Action:    pathzpb.Action_ACTION_PERMIT,
+			}}},
+		}},
+	}, {
+		RotateRequest: &pathzpb.RotateRequest_FinalizeRotation{},
+	}} {
+		if err := pathzRot.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 2 {
+		if _, err := pathzRot.Recv(); err != nil {
+			t.Fatalf("pathz Rotate() unexpected err: %v", err)
+		}
+	}
+	stop()
+	for _, name := range []string{"authz", "pathz"} {
+		if _, err := os.Stat(s.statePath(name)); err != nil {
+			t.Errorf("%s artifacts not persisted: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "credentialz.pb")); err == nil {
+		t.Errorf("credentialz artifacts persisted without a finalized rotation")
+	}
+
+	_, conn, stop = start(t, dir)
+	defer stop()
+	authzResp, err := authzpb.NewAuthzClient(conn).Get(ctx, &authzpb.GetRequest{})
+	if err != nil {
+		t.Fatalf("authz Get() unexpected err: %v", err)
+	}
+	if got := [2]any{authzResp.GetVersion(), authzResp.GetCreatedOn()}; got != [2]any{"1", uint64(100)} {
+		t.Errorf("authz Get() after restart got version and creation time %v, want [1 100]", got)
+	}
+	pathzResp, err := pathzpb.NewPathzClient(conn).Get(ctx, &pathzpb.GetRequest{PolicyInstance: pathzpb.PolicyInstance_POLICY_INSTANCE_ACTIVE})
+	if err != nil {
+		t.Fatalf("pathz Get() unexpected err: %v", err)
+	}
+	if got := [2]any{pathzResp.GetVersion(), pathzResp.GetCreatedOn()}; got != [2]any{"2", uint64(200)} {
+		t.Errorf("pathz Get() after restart got version and creation time %v, want [2 200]", got)
+	}
+}
+
+func TestStateDirInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "authz.pb"), []byte("invalid"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(grpc.NewServer(), authz.New(), certz.New(nil), credentialz.New(), acctz.New(acctz.DefaultHistorySize), pathz.New(), WithStateDir(dir)); err == nil {
+		t.Errorf("New() with invalid stored artifacts got nil err, want err")
+	}
+}
+
+// start starts a gNSI server persisting its artifacts in dir, and returns a
+// connection to it.
+func start(t testing.TB, dir string) (*Server, *grpc.ClientConn, func()) {
+	t.Helper()
+	s := grpc.NewServer()
+	srv, err := New(s, authz.New(), certz.New(nil), credentialz.New(), acctz.New(acctz.DefaultHistorySize), pathz.New(), WithStateDir(dir))
+	if err != nil {
+		t.Fatalf("New() unexpected err: %v", err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:")
+	if err != nil {
+		t.Fatalf("failed to start listener: %v", err)
+	}
+
+	go s.Serve(l)
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed dial server: %v", err)
+	}
+	return srv, conn, func() {
+		conn.Close()
+		s.Stop()
+	}
+}
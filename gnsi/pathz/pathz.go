@@ -17,13 +17,24 @@ package pathz
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"github.com/openconfig/ygot/ygot"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/openconfig/lemming/gnmi/gnmiclient"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+	"github.com/openconfig/lemming/gnmi/reconciler"
 	"github.com/openconfig/lemming/gnsi/acltrie"
 
 	gpb "github.com/openconfig/gnmi/proto/gnmi"
@@ -37,6 +48,21 @@ type policyData struct {
 	createdOn uint64
 }
 
+// accessCounters counts the accesses to a path accepted and rejected by the
+// policy.
+type accessCounters struct {
+	accepts    uint64
+	rejects    uint64
+	lastAccept uint64
+	lastReject uint64
+}
+
+// pathCounters counts the reads and writes of a path.
+type pathCounters struct {
+	reads  accessCounters
+	writes accessCounters
+}
+
 // Server implements the pathz gRPC server.
 type Server struct {
 	pathzpb.UnimplementedPathzServer
@@ -45,26 +71,52 @@ type Server struct {
 	sandbox            *policyData
 	activeMu           sync.RWMutex
 	active             *policyData
+	// onFinalize is called once a rotation is finalized.
+	onFinalize func()
+
+	countersMu sync.Mutex
+	// counters is keyed by the schema path of the accessed paths.
+	counters map[string]*pathCounters
+	// dirty is set when the state published by the reconciler is stale.
+	dirty bool
+}
+
+// New returns a pathz server without a policy.
+func New() *Server {
+	return &Server{counters: map[string]*pathCounters{}}
+}
+
+func (s *Server) markDirty() {
+	s.countersMu.Lock()
+	s.dirty = true
+	s.countersMu.Unlock()
 }
 
-// Rotate implements the pathz Rotate RPC.
+// Rotate implements the pathz Rotate RPC. The uploaded policy is the sandbox
+// policy until the rotation is finalized, and is discarded if the stream ends
+// before.
 func (s *Server) Rotate(rs pathzpb.Pathz_RotateServer) error {
-	if s.rotationInProgress.Load() {
+	if !s.rotationInProgress.CompareAndSwap(false, true) {
 		return status.Error(codes.Unavailable, "another rotation is already in progress")
 	}
-	s.rotationInProgress.Store(true)
 	defer s.rotationInProgress.Store(false)
 
-	ctx := rs.Context()
-	receivedUploadReq := false
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
+	receivedUploadReq, finalized := false, false
+	defer func() {
+		if receivedUploadReq && !finalized {
+			log.Info("pathz rotation not finalized, discarding the sandbox policy")
+			s.sandboxMu.Lock()
+			s.sandbox = nil
+			s.sandboxMu.Unlock()
+			s.markDirty()
 		}
+	}()
 
+	for {
 		resp, err := rs.Recv()
+		if errors.Is(err, io.EOF) {
+			return status.Error(codes.Aborted, "stream closed before the rotation was finalized")
+		}
 		if err != nil {
 			return err
 		}
@@ -73,20 +125,19 @@ func (s *Server) Rotate(rs pathzpb.Pathz_RotateServer) error {
 			if receivedUploadReq {
 				return status.Error(codes.FailedPrecondition, "only a single upload request can be sent per Rotate RPC")
 			}
-			receivedUploadReq = true
-
-			t, err := acltrie.FromPolicy(req.UploadRequest.GetPolicy())
+			upload := req.UploadRequest
+			if err := s.checkVersion(upload, resp.GetForceOverwrite()); err != nil {
+				return err
+			}
+			p, err := newPolicyData(upload)
 			if err != nil {
-				return status.Errorf(codes.InvalidArgument, "invalid policy: %v", err)
+				return err
 			}
+			receivedUploadReq = true
 			s.sandboxMu.Lock()
-			s.sandbox = &policyData{
-				trie:      t,
-				version:   req.UploadRequest.GetVersion(),
-				rawPolicy: req.UploadRequest.GetPolicy(),
-				createdOn: req.UploadRequest.GetCreatedOn(),
-			}
+			s.sandbox = p
 			s.sandboxMu.Unlock()
+			s.markDirty()
 			if err := rs.Send(&pathzpb.RotateResponse{}); err != nil {
 				return err
 			}
@@ -100,13 +151,50 @@ func (s *Server) Rotate(rs pathzpb.Pathz_RotateServer) error {
 			s.sandbox = nil
 			s.sandboxMu.Unlock()
 			s.activeMu.Unlock()
-			if err := rs.Send(&pathzpb.RotateResponse{}); err != nil {
-				return err
+			finalized = true
+			s.markDirty()
+			if s.onFinalize != nil {
+				s.onFinalize()
 			}
+			return rs.Send(&pathzpb.RotateResponse{})
+		default:
+			return status.Errorf(codes.InvalidArgument, "unknown request type %T", req)
 		}
 	}
 }
 
+// newPolicyData returns the policy of an upload request.
+func newPolicyData(upload *pathzpb.UploadRequest) (*policyData, error) {
+	t, err := acltrie.FromPolicy(upload.GetPolicy())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid policy: %v", err)
+	}
+	return &policyData{
+		trie:      t,
+		version:   upload.GetVersion(),
+		rawPolicy: upload.GetPolicy(),
+		createdOn: upload.GetCreatedOn(),
+	}, nil
+}
+
+// checkVersion checks that an uploaded policy does not conflict with the
+// active policy, unless it is forced to overwrite it: its version must differ
+// and it must not have been created before.
+func (s *Server) checkVersion(upload *pathzpb.UploadRequest, force bool) error {
+	s.activeMu.RLock()
+	defer s.activeMu.RUnlock()
+	if s.active == nil || force {
+		return nil
+	}
+	if upload.GetVersion() == s.active.version {
+		return status.Errorf(codes.AlreadyExists, "policy version %q is already in use", upload.GetVersion())
+	}
+	if upload.GetCreatedOn() < s.active.createdOn {
+		return status.Errorf(codes.FailedPrecondition, "policy created on %d is older than the active policy created on %d", upload.GetCreatedOn(), s.active.createdOn)
+	}
+	return nil
+}
+
 func (s *Server) getPolicyWithRLock(i pathzpb.PolicyInstance) (*policyData, *sync.RWMutex, error) {
 	switch i {
 	case pathzpb.PolicyInstance_POLICY_INSTANCE_SANDBOX:
@@ -120,7 +208,8 @@ func (s *Server) getPolicyWithRLock(i pathzpb.PolicyInstance) (*policyData, *syn
 	}
 }
 
-// CheckPermit implements the gNMI path auth interface, by using Probe.
+// CheckPermit implements the gNMI path auth interface, by using Probe. The
+// decisions are counted under the schema path of the path.
 func (s *Server) CheckPermit(path *gpb.Path, user string, write bool) bool {
 	s.activeMu.RLock()
 	defer s.activeMu.RUnlock()
@@ -132,8 +221,50 @@ func (s *Server) CheckPermit(path *gpb.Path, user string, write bool) bool {
 	if write {
 		mode = pathzpb.Mode_MODE_WRITE
 	}
-	act := s.active.trie.Probe(path, user, mode)
-	return act == pathzpb.Action_ACTION_PERMIT
+	permit := s.active.trie.Probe(path, user, mode) == pathzpb.Action_ACTION_PERMIT
+	s.count(path, write, permit)
+	return permit
+}
+
+// schemaPath returns the schema path of a path, which is the path without
+// its keys and origin.
+func schemaPath(path *gpb.Path) string {
+	var b strings.Builder
+	for _, e := range path.GetElem() {
+		b.WriteString("/")
+		b.WriteString(e.GetName())
+	}
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+func (s *Server) count(path *gpb.Path, write, permit bool) {
+	s.countersMu.Lock()
+	defer s.countersMu.Unlock()
+	if s.counters == nil {
+		s.counters = map[string]*pathCounters{}
+	}
+	name := schemaPath(path)
+	cnt, ok := s.counters[name]
+	if !ok {
+		cnt = &pathCounters{}
+		s.counters[name] = cnt
+	}
+	access := &cnt.reads
+	if write {
+		access = &cnt.writes
+	}
+	now := uint64(time.Now().UnixNano())
+	if permit {
+		access.accepts++
+		access.lastAccept = now
+	} else {
+		access.rejects++
+		access.lastReject = now
+	}
+	s.dirty = true
 }
 
 // IsInitialized implements the gNMI path auth interface, by checking the active policy exists.
@@ -170,7 +301,7 @@ func (s *Server) Probe(_ context.Context, req *pathzpb.ProbeRequest) (*pathzpb.P
 	}, nil
 }
 
-// Probe implements the pathz Get RPC.
+// Get implements the pathz Get RPC.
 func (s *Server) Get(_ context.Context, req *pathzpb.GetRequest) (*pathzpb.GetResponse, error) {
 	policy, mu, err := s.getPolicyWithRLock(req.GetPolicyInstance())
 	if err != nil {
@@ -187,3 +318,102 @@ func (s *Server) Get(_ context.Context, req *pathzpb.GetRequest) (*pathzpb.GetRe
 		Version:   policy.version,
 	}, nil
 }
+
+func policyState(instance oc.E_Policy_Instance, p *policyData) *oc.System_GnmiPathzPolicies_Policy {
+	policy := &oc.System_GnmiPathzPolicies_Policy{Instance: instance}
+	if p != nil {
+		policy.Version = ygot.String(p.version)
+		policy.CreatedOn = ygot.Uint64(p.createdOn)
+	}
+	return policy
+}
+
+// publishState publishes the versions of the policies and the path counters,
+// if they changed since they were last published.
+func (s *Server) publishState(ctx context.Context, c *ygnmi.Client) error {
+	s.countersMu.Lock()
+	if !s.dirty {
+		s.countersMu.Unlock()
+		return nil
+	}
+	s.dirty = false
+	paths := map[string]*oc.System_GrpcServer_GnmiPathzPolicyCounters_Path{}
+	for name, cnt := range s.counters {
+		paths[name] = &oc.System_GrpcServer_GnmiPathzPolicyCounters_Path{
+			Name: ygot.String(name),
+			Reads: &oc.System_GrpcServer_GnmiPathzPolicyCounters_Path_Reads{
+				AccessAccepts:    ygot.Uint64(cnt.reads.accepts),
+				AccessRejects:    ygot.Uint64(cnt.reads.rejects),
+				LastAccessAccept: ygot.Uint64(cnt.reads.lastAccept),
+				LastAccessReject: ygot.Uint64(cnt.reads.lastReject),
+			},
+			Writes: &oc.System_GrpcServer_GnmiPathzPolicyCounters_Path_Writes{
+				AccessAccepts:    ygot.Uint64(cnt.writes.accepts),
+				AccessRejects:    ygot.Uint64(cnt.writes.rejects),
+				LastAccessAccept: ygot.Uint64(cnt.writes.lastAccept),
+				LastAccessReject: ygot.Uint64(cnt.writes.lastReject),
+			},
+		}
+	}
+	s.countersMu.Unlock()
+
+	s.activeMu.RLock()
+	active := s.active
+	s.activeMu.RUnlock()
+	s.sandboxMu.RLock()
+	sandbox := s.sandbox
+	s.sandboxMu.RUnlock()
+
+	batch := &ygnmi.SetBatch{}
+	policies := ocpath.Root().System().GnmiPathzPolicies()
+	gnmiclient.BatchReplace(batch, policies.Policy(oc.Policy_Instance_ACTIVE).State(), policyState(oc.Policy_Instance_ACTIVE, active))
+	gnmiclient.BatchReplace(batch, policies.Policy(oc.Policy_Instance_SANDBOX).State(), policyState(oc.Policy_Instance_SANDBOX, sandbox))
+	srv := ocpath.Root().System().GrpcServer("gnmi")
+	if active != nil {
+		gnmiclient.BatchReplace(batch, srv.GnmiPathzPolicyVersion().State(), active.version)
+		gnmiclient.BatchReplace(batch, srv.GnmiPathzPolicyCreatedOn().State(), active.createdOn)
+	} else {
+		gnmiclient.BatchDelete(batch, srv.GnmiPathzPolicyVersion().State())
+		gnmiclient.BatchDelete(batch, srv.GnmiPathzPolicyCreatedOn().State())
+	}
+	for name, path := range paths {
+		gnmiclient.BatchReplace(batch, srv.GnmiPathzPolicyCounters().Path(name).State(), path)
+	}
+	if _, err := batch.Set(ctx, c); err != nil {
+		s.markDirty()
+		return err
+	}
+	return nil
+}
+
+// Reconciler returns a reconciler publishing the policy versions and the path
+// counters under /system.
+func (s *Server) Reconciler() *reconciler.BuiltReconciler {
+	var cancel context.CancelFunc
+	return reconciler.NewBuilder("gnsi-pathz").
+		WithStart(func(ctx context.Context, c *ygnmi.Client) error {
+			ctx, cancel = context.WithCancel(ctx)
+			s.markDirty()
+			go func() {
+				tick := time.NewTicker(time.Second)
+				defer tick.Stop()
+				for {
+					if err := s.publishState(ctx, c); err != nil && ctx.Err() == nil {
+						log.Warningf("unable to update pathz state: %v", err)
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case <-tick.C:
+					}
+				}
+			}()
+			return nil
+		}).
+		WithStop(func(context.Context) error {
+			if cancel != nil {
+				cancel()
+			}
+			return nil
+		}).Build()
+}
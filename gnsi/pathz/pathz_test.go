@@ -22,10 +22,17 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/openconfig/gnmi/errdiff"
+	"github.com/openconfig/ygnmi/ygnmi"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/testing/protocmp"
 
+	"github.com/openconfig/lemming/gnmi"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+
 	gpb "github.com/openconfig/gnmi/proto/gnmi"
 	pathzpb "github.com/openconfig/gnsi/pathz"
 )
@@ -125,6 +132,183 @@ func TestRotate(t *testing.T) {
 	})
 }
 
+// upload returns a request uploading a policy permitting me to read /test.
+func upload(version string, createdOn uint64, force bool) *pathzpb.RotateRequest {
+	return &pathzpb.RotateRequest{
+		ForceOverwrite: force,
+		RotateRequest: &pathzpb.RotateRequest_UploadRequest{
+			UploadRequest: &pathzpb.UploadRequest{
+				Version:   version,
+				CreatedOn: createdOn,
+				Policy: &pathzpb.AuthorizationPolicy{
+					Rules: []*pathzpb.AuthorizationRule{{
+						Path:      &gpb.Path{Elem: []*gpb.PathElem{{Name: "test"}}},
+						Principal: &pathzpb.AuthorizationRule_User{User: "me"},
+						Mode:      pathzpb.Mode_MODE_READ,
+						Action:    pathzpb.Action_ACTION_PERMIT,
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestRotateVersion(t *testing.T) {
+	tests := []struct {
+		desc    string
+		req     *pathzpb.RotateRequest
+		wantErr string
+	}{{
+		desc: "new version",
+		req:  upload("2", 200, false),
+	}, {
+		desc:    "same version",
+		req:     upload("1", 200, false),
+		wantErr: "already in use",
+	}, {
+		desc: "same version forced",
+		req:  upload("1", 200, true),
+	}, {
+		desc:    "older policy",
+		req:     upload("2", 50, false),
+		wantErr: "older than the active policy",
+	}, {
+		desc: "older policy forced",
+		req:  upload("2", 50, true),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			client, closeFn := start(t)
+			defer closeFn()
+			rc, err := client.Rotate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			mustSendAndRecv(t, rc, upload("1", 100, false))
+			mustFinalize(t, rc)
+
+			rc, err = client.Rotate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := rc.Send(tt.req); err != nil {
+				t.Fatal(err)
+			}
+			_, err = rc.Recv()
+			if d := errdiff.Check(err, tt.wantErr); d != "" {
+				t.Errorf("Rotate() unexpected err: %s", d)
+			}
+		})
+	}
+}
+
+func TestRotateRollback(t *testing.T) {
+	client, closeFn := start(t)
+	defer closeFn()
+	ctx := context.Background()
+	rc, err := client.Rotate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSendAndRecv(t, rc, upload("1", 100, false))
+	mustFinalize(t, rc)
+
+	rc, err = client.Rotate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSendAndRecv(t, rc, upload("2", 200, false))
+	if err := rc.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rc.Recv(); status.Code(err) != codes.Aborted {
+		t.Fatalf("Rotate() got err %v, want Aborted", err)
+	}
+
+	if _, err := client.Get(ctx, &pathzpb.GetRequest{PolicyInstance: pathzpb.PolicyInstance_POLICY_INSTANCE_SANDBOX}); err == nil {
+		t.Errorf("Get(SANDBOX) got no err, want the sandbox policy discarded")
+	}
+	got, err := client.Get(ctx, &pathzpb.GetRequest{PolicyInstance: pathzpb.PolicyInstance_POLICY_INSTANCE_ACTIVE})
+	if err != nil {
+		t.Fatalf("Get(ACTIVE) unexpected err: %v", err)
+	}
+	if got.GetVersion() != "1" {
+		t.Errorf("Get(ACTIVE) got version %q, want 1", got.GetVersion())
+	}
+}
+
+func TestCheckPermitCounters(t *testing.T) {
+	s := New()
+	client, closeFn := startServer(t, s)
+	defer closeFn()
+	rc, err := client.Rotate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSendAndRecv(t, rc, upload("1", 100, false))
+	mustFinalize(t, rc)
+
+	path := &gpb.Path{Elem: []*gpb.PathElem{{Name: "test", Key: map[string]string{"name": "a"}}}}
+	for _, c := range []struct {
+		user  string
+		write bool
+		want  bool
+	}{
+		{user: "me", want: true},
+		{user: "me", want: true},
+		{user: "you", want: false},
+		{user: "me", write: true, want: false},
+	} {
+		if got := s.CheckPermit(path, c.user, c.write); got != c.want {
+			t.Errorf("CheckPermit(%v, %q, %v) got %v, want %v", path, c.user, c.write, got, c.want)
+		}
+	}
+	cnt := s.counters["/test"]
+	if cnt == nil {
+		t.Fatalf("no counters for /test, got %v", s.counters)
+	}
+	if got, want := []uint64{cnt.reads.accepts, cnt.reads.rejects, cnt.writes.accepts, cnt.writes.rejects}, []uint64{2, 1, 0, 1}; !cmp.Equal(got, want) {
+		t.Errorf("counters got %v, want %v", got, want)
+	}
+
+	gnmiServer, err := gnmi.New(grpc.NewServer(), "local", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ygnmi.NewClient(gnmiServer.LocalClient(), ygnmi.WithTarget("local"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := s.publishState(ctx, c); err != nil {
+		t.Fatalf("publishState() unexpected err: %v", err)
+	}
+	// The state is applied to the cache asynchronously.
+	watchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	srv := ocpath.Root().System().GrpcServer("gnmi")
+	val, err := ygnmi.Watch(watchCtx, c, srv.GnmiPathzPolicyCounters().Path("/test").State(), func(v *ygnmi.Value[*oc.System_GrpcServer_GnmiPathzPolicyCounters_Path]) error {
+		if _, ok := v.Val(); ok {
+			return nil
+		}
+		return ygnmi.Continue
+	}).Await()
+	if err != nil {
+		t.Fatalf("failed to get counters state: %v", err)
+	}
+	got, _ := val.Val()
+	if got, want := []uint64{got.GetReads().GetAccessAccepts(), got.GetReads().GetAccessRejects(), got.GetWrites().GetAccessAccepts(), got.GetWrites().GetAccessRejects()}, []uint64{2, 1, 0, 1}; !cmp.Equal(got, want) {
+		t.Errorf("counters state got %v, want %v", got, want)
+	}
+	version, err := ygnmi.Get(ctx, c, srv.GnmiPathzPolicyVersion().State())
+	if err != nil {
+		t.Fatalf("failed to get policy version: %v", err)
+	}
+	if version != "1" {
+		t.Errorf("policy version got %q, want 1", version)
+	}
+}
+
 func TestProbe(t *testing.T) {
 	tests := []struct {
 		desc                string
@@ -387,8 +571,11 @@ func mustFinalize(t testing.TB, rc pathzpb.Pathz_RotateClient) {
 
 func start(t testing.TB) (pathzpb.PathzClient, func()) {
 	t.Helper()
-	pathzServer := &Server{}
+	return startServer(t, New())
+}
 
+func startServer(t testing.TB, pathzServer *Server) (pathzpb.PathzClient, func()) {
+	t.Helper()
 	s := grpc.NewServer()
 	pathzpb.RegisterPathzServer(s, pathzServer)
 
@@ -0,0 +1,63 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathz
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	pathzpb "github.com/openconfig/gnsi/pathz"
+)
+
+// OnFinalize sets a function called once a rotation is finalized, while no
+// other rotation can start. It must be set before the server is serving.
+func (s *Server) OnFinalize(f func()) {
+	s.onFinalize = f
+}
+
+// MarshalState returns the active policy, stored as the upload request
+// restoring it, or nil if there is none.
+func (s *Server) MarshalState() ([]byte, error) {
+	s.activeMu.RLock()
+	p := s.active
+	s.activeMu.RUnlock()
+	if p == nil {
+		return nil, nil
+	}
+	return proto.Marshal(&pathzpb.UploadRequest{
+		Version:   p.version,
+		CreatedOn: p.createdOn,
+		Policy:    p.rawPolicy,
+	})
+}
+
+// UnmarshalState restores the active policy returned by MarshalState.
+func (s *Server) UnmarshalState(b []byte) error {
+	if b == nil {
+		return nil
+	}
+	upload := &pathzpb.UploadRequest{}
+	if err := proto.Unmarshal(b, upload); err != nil {
+		return err
+	}
+	p, err := newPolicyData(upload)
+	if err != nil {
+		return err
+	}
+	s.activeMu.Lock()
+	s.active = p
+	s.activeMu.Unlock()
+	s.markDirty()
+	return nil
+}
@@ -0,0 +1,277 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gribi
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/golang/glog"
+
+	gribipb "github.com/openconfig/gribi/v1/proto/service"
+)
+
+// routeKey identifies a route or an MPLS label entry programmed by gRIBI.
+type routeKey struct {
+	ni     string
+	prefix string
+	// label is the incoming label of an MPLS label entry, whose prefix is
+	// empty.
+	label uint32
+}
+
+// fibOutcome is the outcome of programming a route in the FIB.
+type fibOutcome struct {
+	// seq is the sequence number at which the programming started.
+	seq uint64
+	err error
+}
+
+// fibAck is a FIB acknowledgement of an operation waiting for the outcome of
+// programming its route.
+type fibAck struct {
+	id uint64
+	// seq is the sequence number at which the operation was received.
+	seq    uint64
+	stream *modifyStream
+}
+
+// fibTracker ties the FIB acknowledgements of gRIBI operations to the
+// outcome of programming their routes in the FIB.
+//
+// gribigo acknowledges an operation as FIB_PROGRAMMED as soon as it is
+// installed in the RIB, while its route is programmed asynchronously by the
+// resolved entry hook. The acknowledgements of route operations are held in
+// a pending queue until a programming of the route started after the
+// operation was received completes.
+type fibTracker struct {
+	mu sync.Mutex
+	// seq orders the receipt of operations and the start of programmings.
+	seq      uint64
+	outcomes map[routeKey]*fibOutcome
+	pending  map[routeKey][]*fibAck
+}
+
+func newFIBTracker() *fibTracker {
+	return &fibTracker{
+		outcomes: map[routeKey]*fibOutcome{},
+		pending:  map[routeKey][]*fibAck{},
+	}
+}
+
+// next returns the next sequence number.
+func (t *fibTracker) next() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seq++
+	return t.seq
+}
+
+// complete records the outcome of programming the route with key k, which
+// started at sequence number seq, and acknowledges the operations received
+// before.
+func (t *fibTracker) complete(k routeKey, seq uint64, err error) {
+	t.mu.Lock()
+	if o, ok := t.outcomes[k]; !ok || o.seq < seq {
+		t.outcomes[k] = &fibOutcome{seq: seq, err: err}
+	}
+	var acks, remaining []*fibAck
+	for _, ack := range t.pending[k] {
+		if ack.seq > seq {
+			remaining = append(remaining, ack)
+			continue
+		}
+		acks = append(acks, ack)
+	}
+	if len(remaining) == 0 {
+		delete(t.pending, k)
+	} else {
+		t.pending[k] = remaining
+	}
+	t.mu.Unlock()
+
+	for _, ack := range acks {
+		if err := ack.stream.send(&gribipb.ModifyResponse{Result: []*gribipb.AFTResult{fibResult(ack.id, err)}}); err != nil {
+			log.Warningf("cannot send FIB acknowledgement of operation %d: %v", ack.id, err)
+		}
+	}
+}
+
+// ack returns the FIB acknowledgement of operation id on the route with key
+// k, received at sequence number seq, if its outcome is known. Otherwise,
+// the acknowledgement is queued to be sent on stream once it is.
+func (t *fibTracker) ack(stream *modifyStream, k routeKey, id, seq uint64) (*gribipb.AFTResult, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if o, ok := t.outcomes[k]; ok && o.seq > seq {
+		return fibResult(id, o.err), true
+	}
+	t.pending[k] = append(t.pending[k], &fibAck{id: id, seq: seq, stream: stream})
+	return nil, false
+}
+
+// drop discards the pending acknowledgements of a stream.
+func (t *fibTracker) drop(stream *modifyStream) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, acks := range t.pending {
+		var remaining []*fibAck
+		for _, ack := range acks {
+			if ack.stream != stream {
+				remaining = append(remaining, ack)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(t.pending, k)
+			continue
+		}
+		t.pending[k] = remaining
+	}
+}
+
+func fibResult(id uint64, err error) *gribipb.AFTResult {
+	if err != nil {
+		return &gribipb.AFTResult{
+			Id:     id,
+			Status: gribipb.AFTResult_FIB_FAILED,
+			ErrorDetails: &gribipb.AFTErrorDetails{
+				ErrorMessage: err.Error(),
+			},
+		}
+	}
+	return &gribipb.AFTResult{
+		Id:     id,
+		Status: gribipb.AFTResult_FIB_PROGRAMMED,
+	}
+}
+
+// modifyStream wraps a Modify stream to replace the FIB acknowledgements of
+// route operations sent by gribigo with the outcome of their programming, and
+// to reject the operations on removed network instances.
+type modifyStream struct {
+	gribipb.GRIBI_ModifyServer
+	fib *fibTracker
+	nis *networkInstances
+
+	sendMu sync.Mutex
+
+	mu sync.Mutex
+	// ops contains the routes of the operations received on the stream
+	// and the sequence number at which they were received, by ID.
+	ops map[uint64]receivedOp
+}
+
+type receivedOp struct {
+	route routeKey
+	seq   uint64
+}
+
+func newModifyStream(ms gribipb.GRIBI_ModifyServer, fib *fibTracker, nis *networkInstances) *modifyStream {
+	return &modifyStream{
+		GRIBI_ModifyServer: ms,
+		fib:                fib,
+		nis:                nis,
+		ops:                map[uint64]receivedOp{},
+	}
+}
+
+// Recv records the routes of the received operations, and rejects the
+// operations on removed network instances as gribigo does for unknown ones.
+func (s *modifyStream) Recv() (*gribipb.ModifyRequest, error) {
+	req, err := s.GRIBI_ModifyServer.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if req.GetOperation() == nil {
+		return req, nil
+	}
+	ops := []*gribipb.AFTOperation{}
+	for _, op := range req.GetOperation() {
+		if s.nis.isRemoved(op.GetNetworkInstance()) {
+			if err := s.send(&gribipb.ModifyResponse{
+				Result: []*gribipb.AFTResult{{
+					Id:     op.GetId(),
+					Status: gribipb.AFTResult_FAILED,
+					ErrorDetails: &gribipb.AFTErrorDetails{
+						ErrorMessage: fmt.Sprintf("unknown network instance %q specified", op.GetNetworkInstance()),
+					},
+				}},
+			}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		ops = append(ops, op)
+		k := routeKey{ni: op.GetNetworkInstance()}
+		switch e := op.GetEntry().(type) {
+		case *gribipb.AFTOperation_Ipv4:
+			k.prefix = e.Ipv4.GetPrefix()
+		case *gribipb.AFTOperation_Ipv6:
+			k.prefix = e.Ipv6.GetPrefix()
+		case *gribipb.AFTOperation_Mpls:
+			k.label = uint32(e.Mpls.GetLabelUint64())
+		default:
+			continue
+		}
+		seq := s.fib.next()
+		s.mu.Lock()
+		s.ops[op.GetId()] = receivedOp{route: k, seq: seq}
+		s.mu.Unlock()
+	}
+	req.Operation = ops
+	return req, nil
+}
+
+// Send replaces the FIB acknowledgements of route operations by their
+// outcome, if it is known, and otherwise defers them.
+func (s *modifyStream) Send(res *gribipb.ModifyResponse) error {
+	// Deferred acknowledgements must not be sent before the response
+	// deferring them.
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if len(res.GetResult()) == 0 {
+		return s.GRIBI_ModifyServer.Send(res)
+	}
+	var results []*gribipb.AFTResult
+	for _, r := range res.GetResult() {
+		if r.GetStatus() != gribipb.AFTResult_FIB_PROGRAMMED {
+			results = append(results, r)
+			continue
+		}
+		s.mu.Lock()
+		op, ok := s.ops[r.GetId()]
+		delete(s.ops, r.GetId())
+		s.mu.Unlock()
+		if !ok {
+			results = append(results, r)
+			continue
+		}
+		if ack, ok := s.fib.ack(s, op.route, r.GetId(), op.seq); ok {
+			results = append(results, ack)
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	res.Result = results
+	return s.GRIBI_ModifyServer.Send(res)
+}
+
+// send sends a deferred FIB acknowledgement.
+func (s *modifyStream) send(res *gribipb.ModifyResponse) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.GRIBI_ModifyServer.Send(res)
+}
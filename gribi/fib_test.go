@@ -0,0 +1,148 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gribi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	aftpb "github.com/openconfig/gribi/v1/proto/gribi_aft"
+	gribipb "github.com/openconfig/gribi/v1/proto/service"
+)
+
+type fakeModifyServer struct {
+	gribipb.GRIBI_ModifyServer
+	reqs []*gribipb.ModifyRequest
+	sent []*gribipb.AFTResult
+}
+
+func (f *fakeModifyServer) Recv() (*gribipb.ModifyRequest, error) {
+	req := f.reqs[0]
+	f.reqs = f.reqs[1:]
+	return req, nil
+}
+
+func (f *fakeModifyServer) Send(res *gribipb.ModifyResponse) error {
+	f.sent = append(f.sent, res.GetResult()...)
+	return nil
+}
+
+func ipv4Op(id uint64, prefix string) *gribipb.AFTOperation {
+	return &gribipb.AFTOperation{
+		Id:              id,
+		NetworkInstance: "DEFAULT",
+		Op:              gribipb.AFTOperation_ADD,
+		Entry: &gribipb.AFTOperation_Ipv4{
+			Ipv4: &aftpb.Afts_Ipv4EntryKey{Prefix: prefix},
+		},
+	}
+}
+
+func programmed(id uint64) []*gribipb.AFTResult {
+	return []*gribipb.AFTResult{{
+		Id:     id,
+		Status: gribipb.AFTResult_RIB_PROGRAMMED,
+	}, {
+		Id:     id,
+		Status: gribipb.AFTResult_FIB_PROGRAMMED,
+	}}
+}
+
+func TestFIBAck(t *testing.T) {
+	fib := newFIBTracker()
+	ms := &fakeModifyServer{
+		reqs: []*gribipb.ModifyRequest{{
+			Operation: []*gribipb.AFTOperation{
+				ipv4Op(1, "1.0.0.0/8"),
+				ipv4Op(2, "2.0.0.0/8"),
+				ipv4Op(3, "3.0.0.0/8"),
+				{
+					Id:              4,
+					NetworkInstance: "DEFAULT",
+					Op:              gribipb.AFTOperation_ADD,
+					Entry: &gribipb.AFTOperation_NextHop{
+						NextHop: &aftpb.Afts_NextHopKey{Index: 1},
+					},
+				},
+			},
+		}},
+	}
+	// The programming of 3.0.0.0/8 starts before its operation is received.
+	stale := fib.next()
+
+	s := newModifyStream(ms, fib, newNetworkInstances(nil))
+	if _, err := s.Recv(); err != nil {
+		t.Fatalf("Recv() got unexpected error: %v", err)
+	}
+	key := func(prefix string) routeKey { return routeKey{ni: "DEFAULT", prefix: prefix} }
+
+	// 1.0.0.0/8 is programmed before gribigo acknowledges it.
+	fib.complete(key("1.0.0.0/8"), fib.next(), nil)
+	seq2, seq3 := fib.next(), fib.next()
+	fib.complete(key("3.0.0.0/8"), stale, nil)
+	for id := uint64(1); id <= 4; id++ {
+		if err := s.Send(&gribipb.ModifyResponse{Result: programmed(id)}); err != nil {
+			t.Fatalf("Send() got unexpected error: %v", err)
+		}
+	}
+	want := []*gribipb.AFTResult{
+		programmed(1)[0], programmed(1)[1],
+		programmed(2)[0],
+		programmed(3)[0],
+		programmed(4)[0], programmed(4)[1],
+	}
+	if diff := cmp.Diff(want, ms.sent, protocmp.Transform()); diff != "" {
+		t.Fatalf("sent results before programming diff (-want, +got):\n%s", diff)
+	}
+
+	// 2.0.0.0/8 fails to be programmed after gribigo acknowledges it.
+	fib.complete(key("2.0.0.0/8"), seq2, errors.New("no more space"))
+	fib.complete(key("3.0.0.0/8"), seq3, nil)
+	want = append(want, &gribipb.AFTResult{
+		Id:           2,
+		Status:       gribipb.AFTResult_FIB_FAILED,
+		ErrorDetails: &gribipb.AFTErrorDetails{ErrorMessage: "no more space"},
+	}, programmed(3)[1])
+	if diff := cmp.Diff(want, ms.sent, protocmp.Transform()); diff != "" {
+		t.Errorf("sent results after programming diff (-want, +got):\n%s", diff)
+	}
+	if len(fib.pending) != 0 {
+		t.Errorf("got pending acknowledgements %v, want none", fib.pending)
+	}
+}
+
+func TestFIBAckDrop(t *testing.T) {
+	fib := newFIBTracker()
+	ms := &fakeModifyServer{
+		reqs: []*gribipb.ModifyRequest{{
+			Operation: []*gribipb.AFTOperation{ipv4Op(1, "1.0.0.0/8")},
+		}},
+	}
+	s := newModifyStream(ms, fib, newNetworkInstances(nil))
+	if _, err := s.Recv(); err != nil {
+		t.Fatalf("Recv() got unexpected error: %v", err)
+	}
+	if err := s.Send(&gribipb.ModifyResponse{Result: programmed(1)}); err != nil {
+		t.Fatalf("Send() got unexpected error: %v", err)
+	}
+	fib.drop(s)
+	fib.complete(routeKey{ni: "DEFAULT", prefix: "1.0.0.0/8"}, fib.next(), nil)
+	if diff := cmp.Diff(programmed(1)[:1], ms.sent, protocmp.Transform()); diff != "" {
+		t.Errorf("sent results diff (-want, +got):\n%s", diff)
+	}
+}
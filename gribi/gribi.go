@@ -16,6 +16,7 @@ package gribi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
 	"net/netip"
@@ -48,6 +49,25 @@ import (
 type Server struct {
 	*server.Server
 	s *grpc.Server
+
+	// The parameters used to recreate the gRIBI server on Reset.
+	gClient    gpb.GNMIClient
+	target     string
+	root       *oc.Root
+	sysribAddr string
+	opts       []server.ServerOpt
+	// state is the state of the current gRIBI server.
+	state *serverState
+}
+
+// serverState is the state kept by lemming for a gRIBI server.
+type serverState struct {
+	// fib tracks the FIB acknowledgements of the server.
+	fib *fibTracker
+	// nis tracks the network instances of the server.
+	nis *networkInstances
+	// stop releases the resources of the server.
+	stop func()
 }
 
 // New returns a new fake gRIBI server.
@@ -59,32 +79,68 @@ type Server struct {
 //   - opts, if specified, will be used to control the underlying gRIBI server's
 //     behaviours.
 func New(s *grpc.Server, gClient gpb.GNMIClient, target string, root *oc.Root, sysribAddr string, opts ...server.ServerOpt) (*Server, error) {
-	gs, err := createGRIBIServer(gClient, target, root, sysribAddr, opts...)
+	gs, state, err := createGRIBIServer(gClient, target, root, sysribAddr, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create gRIBI server, %v", err)
 	}
 
 	srv := &Server{
-		Server: gs,
-		s:      s,
+		Server:     gs,
+		s:          s,
+		gClient:    gClient,
+		target:     target,
+		root:       root,
+		sysribAddr: sysribAddr,
+		opts:       opts,
+		state:      state,
 	}
 	gribipb.RegisterGRIBIServer(s, srv)
 
 	return srv, nil
 }
 
+// Modify implements the gRIBI Modify RPC, acknowledging route operations as
+// FIB_PROGRAMMED or FIB_FAILED once they are programmed in the dataplane.
+func (s *Server) Modify(ms gribipb.GRIBI_ModifyServer) error {
+	stream := newModifyStream(ms, s.state.fib, s.state.nis)
+	defer s.state.fib.drop(stream)
+	return s.Server.Modify(stream)
+}
+
+// Reset flushes all the entries from the RIB, and replaces the gRIBI server
+// with a new one, discarding all client sessions and election state.
+//
+// Reset must not be called while the gRIBI service is serving requests.
+func (s *Server) Reset(ctx context.Context) error {
+	if _, err := s.Server.Flush(ctx, &gribipb.FlushRequest{
+		NetworkInstance: &gribipb.FlushRequest_All{All: &gribipb.Empty{}},
+		Election:        &gribipb.FlushRequest_Override{Override: &gribipb.Empty{}},
+	}); err != nil {
+		return fmt.Errorf("cannot flush gRIBI server, %v", err)
+	}
+	s.state.stop()
+
+	gs, state, err := createGRIBIServer(s.gClient, s.target, s.root, s.sysribAddr, s.opts...)
+	if err != nil {
+		return fmt.Errorf("cannot create gRIBI server, %v", err)
+	}
+	s.Server = gs
+	s.state = state
+	return nil
+}
+
 // createGRIBIServer creates and returns a gRIBI server that is ready be
-// registered by a gRPC server.
+// registered by a gRPC server, along with the state lemming keeps for it.
 //
 // - root, if specified, will be used to populate connected routes into the RIB
 // manager. Note this is intended to be used for unit/standalone device testing.
 //
 // The ServerOpt slice provided is handed to the gRIBI fake server to control its
 // behaviour.
-func createGRIBIServer(gClient gpb.GNMIClient, target string, root *oc.Root, sysribAddr string, opts ...server.ServerOpt) (*server.Server, error) {
+func createGRIBIServer(gClient gpb.GNMIClient, target string, root *oc.Root, sysribAddr string, opts ...server.ServerOpt) (*server.Server, *serverState, error) {
 	gzebraConn, err := grpc.Dial(sysribAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
-		return nil, fmt.Errorf("cannot dial to sysrib, %v", err)
+		return nil, nil, fmt.Errorf("cannot dial to sysrib, %v", err)
 	}
 	gzebraClient := sysribpb.NewSysribClient(gzebraConn)
 
@@ -97,24 +153,69 @@ func createGRIBIServer(gClient gpb.GNMIClient, target string, root *oc.Root, sys
 
 	yclient, err := ygnmi.NewClient(gClient, ygnmi.WithTarget(target), ygnmi.WithRequestLogLevel(2))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	fib := newFIBTracker()
+	nis := newNetworkInstances(append([]string{server.DefaultNetworkInstanceName}, networkInstances...))
 
 	ribHookfn := func(o constants.OpType, _ int64, ni string, data ygot.ValidatedGoStruct) {
 		// write gNMI notifications
 		if err := updateAft(yclient, o, ni, data, o); err != nil {
 			log.Errorf("invalid notifications, %v", err)
 		}
+	}
+
+	// labelAddfn sets the MPLS label entries in sysrib.
+	labelAddfn := func(ribs map[string]*aft.RIB, optype constants.OpType, netinst string, key any) {
+		label, ok := mplsLabel(key)
+		if !ok {
+			log.Errorf("Key is not an MPLS label: (%T, %v)", key, key)
+			return
+		}
+		// The FIB acknowledgements of the label entry's operations
+		// received so far are resolved by the outcome of this programming.
+		rk, seq := routeKey{ni: netinst, label: label}, fib.next()
+		labelReq := &sysribpb.SetLabelRequest{
+			NetworkInstance: netinst,
+			Label:           label,
+		}
+		switch optype {
+		case constants.Add, constants.Replace:
+			var err error
+			if labelReq, err = createSetLabelRequest(netinst, label, ribs); err != nil {
+				log.Errorf("Cannot create SetLabelRequest: %v", err)
+				fib.complete(rk, seq, err)
+				return
+			}
+		case constants.Delete:
+			labelReq.Delete = true
+		default:
+			return
+		}
+		if !nis.setRoute(rk, labelReq.Delete) {
+			fib.complete(rk, seq, fmt.Errorf("network instance %q is removed", netinst))
+			return
+		}
 
-		// TODO(wenbli): Check if this is needed with @robshakir.
-		// server.WithFIBProgrammedCheck()
-		//   -> gives us a function that checks whether an ID is a tristate (ok, failed, pending)
-		//   -> plumb this through to the rib - and have a fib pending queue for responding.
-		//
-		// here we just write to something that the server has access to.
+		resp, err := gzebraClient.SetLabel(context.Background(), labelReq)
+		if err != nil {
+			log.Errorf("Error sending label to sysrib: %v", err)
+			fib.complete(rk, seq, err)
+			return
+		}
+		log.Infof("Sent label %v with response %v", labelReq, resp)
+		if resp.GetStatus() != sysribpb.SetRouteResponse_STATUS_SUCCESS {
+			fib.complete(rk, seq, fmt.Errorf("label not programmed: %s", resp.GetMessage()))
+			return
+		}
+		fib.complete(rk, seq, nil)
 	}
 
 	ribAddfn := func(ribs map[string]*aft.RIB, optype constants.OpType, netinst string, aft constants.AFT, key any, _ ...rib.ResolvedDetails) {
+		if aft == constants.MPLS {
+			labelAddfn(ribs, optype, netinst, key)
+			return
+		}
 		prefix, ok := key.(string)
 		if !ok {
 			log.Errorf("Key is not a string type: (%T, %v)", key, key)
@@ -124,17 +225,19 @@ func createGRIBIServer(gClient gpb.GNMIClient, target string, root *oc.Root, sys
 		default:
 			log.Errorf("Incompatible type of route receive, type: %s, key: %v", aft, key)
 		}
+		// The FIB acknowledgements of the route's operations received so
+		// far are resolved by the outcome of this programming.
+		rk, seq := routeKey{ni: netinst, prefix: prefix}, fib.next()
 		nhSum := []*afthelper.NextHopSummary{}
 		switch optype {
 		case constants.Add, constants.Replace:
-			nhs, err := afthelper.NextHopAddrsForPrefix(ribs, netinst, prefix)
+			nhs, err := nextHopsForPrefix(ribs, netinst, prefix)
 			if err != nil {
 				log.Errorf("cannot add netinst:prefix %s:%s to the RIB, %v", netinst, prefix, err)
+				fib.complete(rk, seq, fmt.Errorf("cannot resolve next hops: %v", err))
 				return
 			}
-			for _, nh := range nhs {
-				nhSum = append(nhSum, nh)
-			}
+			nhSum = nhs
 		case constants.Delete:
 		default:
 			return
@@ -143,18 +246,29 @@ func createGRIBIServer(gClient gpb.GNMIClient, target string, root *oc.Root, sys
 		routeReq, err := createSetRouteRequest(netinst, prefix, nhSum, ribs)
 		if err != nil {
 			log.Errorf("Cannot create SetRouteRequest: %v", err)
+			fib.complete(rk, seq, err)
 			return
 		}
 		if optype == constants.Delete {
 			routeReq.Delete = true
 		}
+		if !nis.setRoute(rk, routeReq.Delete) {
+			fib.complete(rk, seq, fmt.Errorf("network instance %q is removed", netinst))
+			return
+		}
 
 		resp, err := gzebraClient.SetRoute(context.Background(), routeReq)
 		if err != nil {
 			log.Errorf("Error sending route to sysrib: %v", err)
+			fib.complete(rk, seq, err)
 			return
 		}
 		log.Infof("Sent route %v with response %v", routeReq, resp)
+		if resp.GetStatus() != sysribpb.SetRouteResponse_STATUS_SUCCESS {
+			fib.complete(rk, seq, fmt.Errorf("route not programmed: %s", resp.GetMessage()))
+			return
+		}
+		fib.complete(rk, seq, nil)
 	}
 
 	s, err := server.New(append([]server.ServerOpt{
@@ -163,27 +277,75 @@ func createGRIBIServer(gClient gpb.GNMIClient, target string, root *oc.Root, sys
 		server.WithVRFs(networkInstances),
 	}, opts...)...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	s.UnimplementedGRIBIServer = &gribipb.UnimplementedGRIBIServer{}
 
-	nis := map[string]bool{}
-
-	w := ygnmi.WatchAll(context.Background(), yclient, ocpath.Root().NetworkInstanceAny().Name().Config(), func(v *ygnmi.Value[string]) error {
-		val, present := v.Val()
-		if !present { // TODO: support deleting NI.
+	ctx, cancel := context.WithCancel(context.Background())
+	w := ygnmi.WatchAll(ctx, yclient, ocpath.Root().NetworkInstanceAny().Name().Config(), func(v *ygnmi.Value[string]) error {
+		name := v.Path.GetElem()[1].GetKey()["name"]
+		if _, present := v.Val(); present {
+			if nis.add(name) {
+				log.Infof("adding network instance %q", name)
+				if err := s.AddNetworkInstance(name); err != nil {
+					log.Errorf("cannot add network instance %q: %v", name, err)
+				}
+			}
+			return ygnmi.Continue
+		}
+		if name == server.DefaultNetworkInstanceName {
 			return ygnmi.Continue
 		}
-		if _, ok := nis[val]; !ok {
-			log.Infof("adding network instance %q", val)
-			s.AddNetworkInstance(val)
+		if err := removeNetworkInstance(ctx, s, nis, gzebraClient, name); err != nil {
+			log.Errorf("cannot remove network instance %q: %v", name, err)
 		}
 		return ygnmi.Continue
 	})
 	go func() {
 		w.Await()
 	}()
-	return s, nil
+	stop := func() {
+		cancel()
+		if err := gzebraConn.Close(); err != nil {
+			log.Warningf("cannot close connection to sysrib, %v", err)
+		}
+	}
+	return s, &serverState{fib: fib, nis: nis, stop: stop}, nil
+}
+
+// removeNetworkInstance flushes the gRIBI RIB of a removed network instance
+// and withdraws its routes and label entries from sysrib.
+func removeNetworkInstance(ctx context.Context, s *server.Server, nis *networkInstances, gzebraClient sysribpb.SysribClient, ni string) error {
+	ok, routes := nis.remove(ni)
+	if !ok {
+		return nil
+	}
+	log.Infof("removing network instance %q", ni)
+	if _, err := s.Flush(ctx, &gribipb.FlushRequest{
+		NetworkInstance: &gribipb.FlushRequest_Name{Name: ni},
+		Election:        &gribipb.FlushRequest_Override{Override: &gribipb.Empty{}},
+	}); err != nil {
+		return fmt.Errorf("cannot flush gRIBI RIB: %v", err)
+	}
+	var errs []error
+	for _, k := range routes {
+		if k.prefix == "" {
+			if _, err := gzebraClient.SetLabel(ctx, &sysribpb.SetLabelRequest{Delete: true, NetworkInstance: ni, Label: k.label}); err != nil {
+				errs = append(errs, fmt.Errorf("cannot withdraw label %d from sysrib: %v", k.label, err))
+			}
+			continue
+		}
+		routeReq, err := createSetRouteRequest(ni, k.prefix, nil, nil)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		routeReq.Delete = true
+		if _, err := gzebraClient.SetRoute(ctx, routeReq); err != nil {
+			errs = append(errs, fmt.Errorf("cannot withdraw route %s from sysrib: %v", k.prefix, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 type udpEncap interface {
@@ -216,18 +378,199 @@ func createSetRouteRequest(netinst, prefix string, nexthops []*afthelper.NextHop
 		return nil, fmt.Errorf("gribigo/sysrib: %v", err)
 	}
 
+	zNexthops, err := createNexthops(nexthops, ribs)
+	if err != nil {
+		return nil, err
+	}
+
+	family := sysribpb.Prefix_FAMILY_IPV4
+	if pfx.Addr().Is6() {
+		family = sysribpb.Prefix_FAMILY_IPV6
+	}
+
+	req := &sysribpb.SetRouteRequest{
+		AdminDistance: 5,
+		ProtocolName:  "gRIBI",
+		Safi:          sysribpb.SetRouteRequest_SAFI_UNICAST,
+		Prefix: &sysribpb.Prefix{
+			Family:     family,
+			Address:    pfx.Addr().String(),
+			MaskLength: uint32(pfx.Bits()),
+		},
+		Nexthops:        zNexthops,
+		NetworkInstance: netinst,
+	}
+	if len(nexthops) == 0 {
+		return req, nil
+	}
+	// The dataplane switches over to the backup NHG of the prefix's NHG and
+	// reports which of them is active.
+	nhNI, nhgID, err := groupForPrefix(ribs, netinst, prefix)
+	if err != nil {
+		return nil, err
+	}
+	req.NextHopGroupId, req.NextHopGroupNetworkInstance = nhgID, nhNI
+	if backupID := ribs[nhNI].GetAfts().GetNextHopGroup(nhgID).GetBackupNextHopGroup(); backupID != 0 {
+		backups, err := nextHopsForGroup(ribs, nhNI, backupID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup NHG: %v", err)
+		}
+		if req.BackupNexthops, err = createNexthops(backups, ribs); err != nil {
+			return nil, fmt.Errorf("invalid backup NHG: %v", err)
+		}
+	}
+	return req, nil
+}
+
+// mplsLabel returns the value of an MPLS label of the gRIBI RIB, which is
+// either a number or one of the reserved labels.
+func mplsLabel(l any) (uint32, bool) {
+	switch val := l.(type) {
+	case uint64:
+		return uint32(val), true
+	case aft.UnionUint32:
+		return uint32(val), true
+	case aft.E_MplsTypes_MplsLabel_Enum: // https://www.iana.org/assignments/mpls-label-values/mpls-label-values.xhtml
+		switch val {
+		case aft.MplsTypes_MplsLabel_Enum_IPV4_EXPLICIT_NULL:
+			return 0, true
+		case aft.MplsTypes_MplsLabel_Enum_ROUTER_ALERT:
+			return 1, true
+		case aft.MplsTypes_MplsLabel_Enum_IPV6_EXPLICIT_NULL:
+			return 2, true
+		case aft.MplsTypes_MplsLabel_Enum_IMPLICIT_NULL:
+			return 3, true
+		case aft.MplsTypes_MplsLabel_Enum_ENTROPY_LABEL_INDICATOR:
+			return 7, true
+		}
+	}
+	return 0, false
+}
+
+// nextHopsForPrefix returns the next hops of the NHG of a prefix of the
+// gRIBI RIB. Unlike afthelper.NextHopAddrsForPrefix, it allows next hops with
+// no IP address, which decapsulate or encapsulate packets.
+func nextHopsForPrefix(ribs map[string]*aft.RIB, netinst, prefix string) ([]*afthelper.NextHopSummary, error) {
+	nhNI, nhgID, err := groupForPrefix(ribs, netinst, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return nextHopsForGroup(ribs, nhNI, nhgID)
+}
+
+// groupForPrefix returns the network instance and ID of the NHG of a prefix
+// of the gRIBI RIB.
+func groupForPrefix(ribs map[string]*aft.RIB, netinst, prefix string) (string, uint64, error) {
+	pfx, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid prefix: %v", err)
+	}
+	afts := ribs[netinst].GetAfts()
+	var nhNI string
+	var nhgID uint64
+	if pfx.Addr().Is4() {
+		v4 := afts.GetIpv4Entry(prefix)
+		if v4 == nil {
+			return "", 0, fmt.Errorf("cannot find IPv4 prefix %s in network instance %q", prefix, netinst)
+		}
+		nhNI, nhgID = v4.GetNextHopGroupNetworkInstance(), v4.GetNextHopGroup()
+	} else {
+		v6 := afts.GetIpv6Entry(prefix)
+		if v6 == nil {
+			return "", 0, fmt.Errorf("cannot find IPv6 prefix %s in network instance %q", prefix, netinst)
+		}
+		nhNI, nhgID = v6.GetNextHopGroupNetworkInstance(), v6.GetNextHopGroup()
+	}
+	if nhNI == "" {
+		nhNI = netinst
+	}
+	return nhNI, nhgID, nil
+}
+
+// nextHopsForGroup returns the next hops of an NHG of the gRIBI RIB, ordered
+// by index.
+func nextHopsForGroup(ribs map[string]*aft.RIB, nhNI string, nhgID uint64) ([]*afthelper.NextHopSummary, error) {
+	nhg := ribs[nhNI].GetAfts().GetNextHopGroup(nhgID)
+	if nhg == nil {
+		return nil, fmt.Errorf("got unknown NHG %d in NI %s", nhgID, nhNI)
+	}
+	var nexthops []*afthelper.NextHopSummary
+	for _, idx := range slices.Sorted(maps.Keys(nhg.NextHop)) {
+		if ribs[nhNI].GetAfts().GetNextHop(idx) == nil {
+			return nil, fmt.Errorf("invalid next-hop %d", idx)
+		}
+		nexthops = append(nexthops, &afthelper.NextHopSummary{
+			Address:         ribs[nhNI].GetAfts().GetNextHop(idx).GetIpAddress(),
+			Weight:          nhg.GetNextHop(idx).GetWeight(),
+			NetworkInstance: nhNI,
+			Index:           idx,
+		})
+	}
+	return nexthops, nil
+}
+
+// ipHeaderTypes maps the gRIBI IP encapsulation header types to the header
+// types of sysrib.
+var ipHeaderTypes = map[aft.E_AftTypes_EncapsulationHeaderType]routingpb.HeaderType{
+	aft.AftTypes_EncapsulationHeaderType_IPV4: routingpb.HeaderType_HEADER_TYPE_IP4,
+	aft.AftTypes_EncapsulationHeaderType_IPV6: routingpb.HeaderType_HEADER_TYPE_IP6,
+}
+
+// createNexthops converts the next hops of a gRIBI entry to sysrib nexthops
+// carrying their encap headers.
+//
+// A next hop with no IP address forwards to the destination of its outermost
+// encap header, and a next hop that decapsulates packets looks them up in its
+// network instance instead.
+func createNexthops(nexthops []*afthelper.NextHopSummary, ribs map[string]*aft.RIB) ([]*sysribpb.Nexthop, error) {
 	var zNexthops []*sysribpb.Nexthop
 	for _, nhs := range nexthops {
+		aftNH := ribs[nhs.NetworkInstance].GetAfts().GetNextHop(nhs.Index)
+		if decap := aftNH.GetDecapsulateHeader(); decap != aft.AftTypes_EncapsulationHeaderType_UNSET {
+			t, ok := ipHeaderTypes[decap]
+			if !ok {
+				return nil, fmt.Errorf("unsupported decapsulate header: %v", decap)
+			}
+			if len(aftNH.EncapHeader) > 0 || len(aftNH.PushedMplsLabelStack) > 0 || aftNH.IpInIp != nil {
+				return nil, fmt.Errorf("next-hop %d cannot both decapsulate and encapsulate packets", nhs.Index)
+			}
+			lookupNI := aftNH.GetNetworkInstance()
+			if lookupNI == "" {
+				lookupNI = nhs.NetworkInstance
+			}
+			zNexthops = append(zNexthops, &sysribpb.Nexthop{
+				Weight:            nhs.Weight,
+				DecapsulateHeader: t,
+				NetworkInstance:   lookupNI,
+			})
+			continue
+		}
 		nh := &sysribpb.Nexthop{
-			Type:    sysribpb.Nexthop_TYPE_IPV4,
-			Address: nhs.Address,
-			Weight:  nhs.Weight,
-			Encap:   &routingpb.Headers{},
+			Type:               sysribpb.Nexthop_TYPE_IPV4,
+			Address:            nhs.Address,
+			Weight:             nhs.Weight,
+			Encap:              &routingpb.Headers{},
+			NetworkInstance:    aftNH.GetNetworkInstance(),
+			AftIndex:           nhs.Index,
+			AftNetworkInstance: nhs.NetworkInstance,
+		}
+		// The first label of the pushed stack is the bottom of the stack,
+		// while the first label of a header is its top.
+		if pushed := aftNH.GetPushedMplsLabelStack(); len(pushed) > 0 {
+			rh := &routingpb.Header{
+				Type: routingpb.HeaderType_HEADER_TYPE_MPLS,
+			}
+			for _, l := range slices.Backward(pushed) {
+				if v, ok := mplsLabel(l); ok {
+					rh.Labels = append(rh.Labels, v)
+				}
+			}
+			nh.Encap.Headers = append(nh.Encap.Headers, rh)
 		}
-		encaps := slices.Collect(maps.Keys(ribs[nhs.NetworkInstance].GetAfts().GetNextHop(nhs.Index).EncapHeader))
+		encaps := slices.Collect(maps.Keys(aftNH.EncapHeader))
 		slices.Sort(encaps)
 		for _, i := range encaps {
-			eh := ribs[nhs.NetworkInstance].GetAfts().GetNextHop(nhs.Index).GetEncapHeader(i)
+			eh := aftNH.GetEncapHeader(i)
 			switch eh.Type {
 			case aft.AftTypes_EncapsulationHeaderType_UDPV4:
 				appendUDPHeader(nh, routingpb.HeaderType_HEADER_TYPE_UDP4, eh.GetUdpV4())
@@ -238,48 +581,80 @@ func createSetRouteRequest(netinst, prefix string, nexthops []*afthelper.NextHop
 					Type: routingpb.HeaderType_HEADER_TYPE_MPLS,
 				}
 				for _, l := range eh.GetMpls().GetMplsLabelStack() {
-					switch val := l.(type) {
-					case aft.UnionUint32:
-						rh.Labels = append(rh.Labels, uint32(val))
-					case aft.E_MplsTypes_MplsLabel_Enum: // https://www.iana.org/assignments/mpls-label-values/mpls-label-values.xhtml
-						switch val {
-						case aft.MplsTypes_MplsLabel_Enum_IPV4_EXPLICIT_NULL:
-							rh.Labels = append(rh.Labels, 0)
-						case aft.MplsTypes_MplsLabel_Enum_ROUTER_ALERT:
-							rh.Labels = append(rh.Labels, 1)
-						case aft.MplsTypes_MplsLabel_Enum_IPV6_EXPLICIT_NULL:
-							rh.Labels = append(rh.Labels, 2)
-						case aft.MplsTypes_MplsLabel_Enum_IMPLICIT_NULL:
-							rh.Labels = append(rh.Labels, 3)
-						case aft.MplsTypes_MplsLabel_Enum_ENTROPY_LABEL_INDICATOR:
-							rh.Labels = append(rh.Labels, 7)
-						}
+					if v, ok := mplsLabel(l); ok {
+						rh.Labels = append(rh.Labels, v)
 					}
 				}
 				nh.Encap.Headers = append(nh.Encap.Headers, rh)
+			case aft.AftTypes_EncapsulationHeaderType_IPV4:
+				nh.Encap.Headers = append(nh.Encap.Headers, &routingpb.Header{
+					Type:  routingpb.HeaderType_HEADER_TYPE_IP4,
+					SrcIp: eh.GetIpv4().GetSrcIp(),
+					DstIp: eh.GetIpv4().GetDstIp(),
+				})
+			case aft.AftTypes_EncapsulationHeaderType_IPV6:
+				nh.Encap.Headers = append(nh.Encap.Headers, &routingpb.Header{
+					Type:  routingpb.HeaderType_HEADER_TYPE_IP6,
+					SrcIp: eh.GetIpv6().GetSrcIp(),
+					DstIp: eh.GetIpv6().GetDstIp(),
+				})
 			default:
 				return nil, fmt.Errorf("unsupported encap type: %v", eh.Type)
 			}
 		}
+		// The legacy IP-in-IP encapsulation is outside of the encap headers.
+		if ipip := aftNH.GetIpInIp(); ipip != nil {
+			t := routingpb.HeaderType_HEADER_TYPE_IP4
+			if addr, err := netip.ParseAddr(ipip.GetDstIp()); err == nil && addr.Is6() {
+				t = routingpb.HeaderType_HEADER_TYPE_IP6
+			}
+			nh.Encap.Headers = append(nh.Encap.Headers, &routingpb.Header{
+				Type:  t,
+				SrcIp: ipip.GetSrcIp(),
+				DstIp: ipip.GetDstIp(),
+			})
+		}
+		if nh.Address == "" && len(nh.Encap.Headers) > 0 {
+			nh.Address = nh.Encap.Headers[len(nh.Encap.Headers)-1].GetDstIp()
+		}
+		if nh.Address == "" {
+			return nil, fmt.Errorf("invalid next-hop %d", nhs.Index)
+		}
 		zNexthops = append(zNexthops, nh)
 	}
+	return zNexthops, nil
+}
 
-	family := sysribpb.Prefix_FAMILY_IPV4
-	if pfx.Addr().Is6() {
-		family = sysribpb.Prefix_FAMILY_IPV6
+// createSetLabelRequest converts an MPLS label entry of the gRIBI RIB to a
+// sysrib SetLabelRequest.
+func createSetLabelRequest(netinst string, label uint32, ribs map[string]*aft.RIB) (*sysribpb.SetLabelRequest, error) {
+	le := ribs[netinst].GetAfts().GetLabelEntry(aft.UnionUint32(label))
+	if le == nil {
+		return nil, fmt.Errorf("cannot find label %d in network instance %q", label, netinst)
 	}
-
-	return &sysribpb.SetRouteRequest{
-		AdminDistance: 5,
-		ProtocolName:  "gRIBI",
-		Safi:          sysribpb.SetRouteRequest_SAFI_UNICAST,
-		Prefix: &sysribpb.Prefix{
-			Family:     family,
-			Address:    pfx.Addr().String(),
-			MaskLength: uint32(pfx.Bits()),
-		},
-		Nexthops:        zNexthops,
+	nhNI := netinst
+	if ni := le.GetNextHopGroupNetworkInstance(); ni != "" {
+		nhNI = ni
+	}
+	nexthops, err := nextHopsForGroup(ribs, nhNI, le.GetNextHopGroup())
+	if err != nil {
+		return nil, err
+	}
+	zNexthops, err := createNexthops(nexthops, ribs)
+	if err != nil {
+		return nil, err
+	}
+	// The incoming label is always popped: a label entry with no popped
+	// stack swaps it for the pushed labels of its next hops.
+	pop := uint32(len(le.GetPoppedMplsLabelStack()))
+	if pop == 0 {
+		pop = 1
+	}
+	return &sysribpb.SetLabelRequest{
 		NetworkInstance: netinst,
+		Label:           label,
+		PopLabels:       pop,
+		Nexthops:        zNexthops,
 	}, nil
 }
 
@@ -0,0 +1,302 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gribi
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+	"github.com/openconfig/gribigo/aft"
+	"github.com/openconfig/ygot/ygot"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	routingpb "github.com/openconfig/lemming/proto/routing"
+	sysribpb "github.com/openconfig/lemming/proto/sysrib"
+)
+
+func TestCreateSetLabelRequest(t *testing.T) {
+	newRIBs := func(popped []aft.Afts_LabelEntry_PoppedMplsLabelStack_Union, pushed []aft.Afts_NextHop_PushedMplsLabelStack_Union) map[string]*aft.RIB {
+		r := &aft.RIB{}
+		afts := r.GetOrCreateAfts()
+		le := afts.GetOrCreateLabelEntry(aft.UnionUint32(100))
+		le.NextHopGroup = ygot.Uint64(1)
+		le.PoppedMplsLabelStack = popped
+		afts.GetOrCreateNextHopGroup(1).GetOrCreateNextHop(1).Weight = ygot.Uint64(1)
+		nh := afts.GetOrCreateNextHop(1)
+		nh.IpAddress = ygot.String("192.168.1.42")
+		nh.PushedMplsLabelStack = pushed
+		return map[string]*aft.RIB{"DEFAULT": r}
+	}
+	tests := []struct {
+		desc    string
+		inLabel uint32
+		inRIBs  map[string]*aft.RIB
+		want    *sysribpb.SetLabelRequest
+		wantErr string
+	}{{
+		desc:    "swap",
+		inLabel: 100,
+		inRIBs:  newRIBs(nil, []aft.Afts_NextHop_PushedMplsLabelStack_Union{aft.UnionUint32(200)}),
+		want: &sysribpb.SetLabelRequest{
+			NetworkInstance: "DEFAULT",
+			Label:           100,
+			PopLabels:       1,
+			Nexthops: []*sysribpb.Nexthop{{
+				Type:               sysribpb.Nexthop_TYPE_IPV4,
+				Address:            "192.168.1.42",
+				Weight:             1,
+				AftIndex:           1,
+				AftNetworkInstance: "DEFAULT",
+				Encap: &routingpb.Headers{Headers: []*routingpb.Header{{
+					Type:   routingpb.HeaderType_HEADER_TYPE_MPLS,
+					Labels: []uint32{200},
+				}}},
+			}},
+		},
+	}, {
+		desc:    "pop",
+		inLabel: 100,
+		inRIBs:  newRIBs([]aft.Afts_LabelEntry_PoppedMplsLabelStack_Union{aft.UnionUint32(100), aft.MplsTypes_MplsLabel_Enum_IPV4_EXPLICIT_NULL}, nil),
+		want: &sysribpb.SetLabelRequest{
+			NetworkInstance: "DEFAULT",
+			Label:           100,
+			PopLabels:       2,
+			Nexthops: []*sysribpb.Nexthop{{
+				Type:               sysribpb.Nexthop_TYPE_IPV4,
+				Address:            "192.168.1.42",
+				Weight:             1,
+				AftIndex:           1,
+				AftNetworkInstance: "DEFAULT",
+				Encap:              &routingpb.Headers{},
+			}},
+		},
+	}, {
+		desc:    "push stack",
+		inLabel: 100,
+		inRIBs:  newRIBs(nil, []aft.Afts_NextHop_PushedMplsLabelStack_Union{aft.UnionUint32(300), aft.UnionUint32(200)}),
+		want: &sysribpb.SetLabelRequest{
+			NetworkInstance: "DEFAULT",
+			Label:           100,
+			PopLabels:       1,
+			Nexthops: []*sysribpb.Nexthop{{
+				Type:               sysribpb.Nexthop_TYPE_IPV4,
+				Address:            "192.168.1.42",
+				Weight:             1,
+				AftIndex:           1,
+				AftNetworkInstance: "DEFAULT",
+				Encap: &routingpb.Headers{Headers: []*routingpb.Header{{
+					Type:   routingpb.HeaderType_HEADER_TYPE_MPLS,
+					Labels: []uint32{200, 300},
+				}}},
+			}},
+		},
+	}, {
+		desc:    "unknown label",
+		inLabel: 101,
+		inRIBs:  newRIBs(nil, nil),
+		wantErr: "cannot find label",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := createSetLabelRequest("DEFAULT", tt.inLabel, tt.inRIBs)
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Fatalf("createSetLabelRequest() unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("createSetLabelRequest() (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateSetRouteRequest(t *testing.T) {
+	newRIBs := func(nh, backup *aft.Afts_NextHop) map[string]*aft.RIB {
+		r := &aft.RIB{}
+		afts := r.GetOrCreateAfts()
+		afts.GetOrCreateIpv4Entry("10.0.0.0/8").NextHopGroup = ygot.Uint64(1)
+		afts.GetOrCreateNextHopGroup(1).GetOrCreateNextHop(1).Weight = ygot.Uint64(1)
+		nh.Index = ygot.Uint64(1)
+		afts.NextHop = map[uint64]*aft.Afts_NextHop{1: nh}
+		if backup != nil {
+			afts.GetNextHopGroup(1).BackupNextHopGroup = ygot.Uint64(2)
+			afts.GetOrCreateNextHopGroup(2).GetOrCreateNextHop(2).Weight = ygot.Uint64(1)
+			backup.Index = ygot.Uint64(2)
+			afts.NextHop[2] = backup
+		}
+		return map[string]*aft.RIB{"DEFAULT": r}
+	}
+	ipv4Encap := func(src, dst string) map[uint8]*aft.Afts_NextHop_EncapHeader {
+		return map[uint8]*aft.Afts_NextHop_EncapHeader{1: {
+			Index: ygot.Uint8(1),
+			Type:  aft.AftTypes_EncapsulationHeaderType_IPV4,
+			Ipv4:  &aft.Afts_NextHop_EncapHeader_Ipv4{SrcIp: ygot.String(src), DstIp: ygot.String(dst)},
+		}}
+	}
+	prefix := &sysribpb.Prefix{
+		Family:     sysribpb.Prefix_FAMILY_IPV4,
+		Address:    "10.0.0.0",
+		MaskLength: 8,
+	}
+	tests := []struct {
+		desc        string
+		inNH        *aft.Afts_NextHop
+		inBackupNH  *aft.Afts_NextHop
+		want        []*sysribpb.Nexthop
+		wantBackups []*sysribpb.Nexthop
+		wantErr     string
+	}{{
+		desc: "ipv4 encap",
+		inNH: &aft.Afts_NextHop{
+			EncapHeader:     ipv4Encap("192.0.2.1", "203.0.113.1"),
+			NetworkInstance: ygot.String("DEFAULT"),
+		},
+		want: []*sysribpb.Nexthop{{
+			Type:               sysribpb.Nexthop_TYPE_IPV4,
+			Address:            "203.0.113.1",
+			Weight:             1,
+			AftIndex:           1,
+			AftNetworkInstance: "DEFAULT",
+			NetworkInstance:    "DEFAULT",
+			Encap: &routingpb.Headers{Headers: []*routingpb.Header{{
+				Type:  routingpb.HeaderType_HEADER_TYPE_IP4,
+				SrcIp: "192.0.2.1",
+				DstIp: "203.0.113.1",
+			}}},
+		}},
+	}, {
+		desc: "ipv6 encap with next hop address",
+		inNH: &aft.Afts_NextHop{
+			IpAddress: ygot.String("192.0.2.2"),
+			EncapHeader: map[uint8]*aft.Afts_NextHop_EncapHeader{1: {
+				Index: ygot.Uint8(1),
+				Type:  aft.AftTypes_EncapsulationHeaderType_IPV6,
+				Ipv6:  &aft.Afts_NextHop_EncapHeader_Ipv6{SrcIp: ygot.String("2001:db8::1"), DstIp: ygot.String("2001:db8::2")},
+			}},
+		},
+		want: []*sysribpb.Nexthop{{
+			Type:               sysribpb.Nexthop_TYPE_IPV4,
+			Address:            "192.0.2.2",
+			Weight:             1,
+			AftIndex:           1,
+			AftNetworkInstance: "DEFAULT",
+			Encap: &routingpb.Headers{Headers: []*routingpb.Header{{
+				Type:  routingpb.HeaderType_HEADER_TYPE_IP6,
+				SrcIp: "2001:db8::1",
+				DstIp: "2001:db8::2",
+			}}},
+		}},
+	}, {
+		desc: "legacy ip-in-ip",
+		inNH: &aft.Afts_NextHop{
+			IpInIp: &aft.Afts_NextHop_IpInIp{SrcIp: ygot.String("192.0.2.1"), DstIp: ygot.String("203.0.113.1")},
+		},
+		want: []*sysribpb.Nexthop{{
+			Type:               sysribpb.Nexthop_TYPE_IPV4,
+			Address:            "203.0.113.1",
+			Weight:             1,
+			AftIndex:           1,
+			AftNetworkInstance: "DEFAULT",
+			Encap: &routingpb.Headers{Headers: []*routingpb.Header{{
+				Type:  routingpb.HeaderType_HEADER_TYPE_IP4,
+				SrcIp: "192.0.2.1",
+				DstIp: "203.0.113.1",
+			}}},
+		}},
+	}, {
+		desc: "decap and lookup",
+		inNH: &aft.Afts_NextHop{
+			DecapsulateHeader: aft.AftTypes_EncapsulationHeaderType_IPV4,
+			NetworkInstance:   ygot.String("VRF-1"),
+		},
+		want: []*sysribpb.Nexthop{{
+			Weight:            1,
+			DecapsulateHeader: routingpb.HeaderType_HEADER_TYPE_IP4,
+			NetworkInstance:   "VRF-1",
+		}},
+	}, {
+		desc: "decap and encap",
+		inNH: &aft.Afts_NextHop{
+			DecapsulateHeader: aft.AftTypes_EncapsulationHeaderType_IPV4,
+			EncapHeader:       ipv4Encap("192.0.2.1", "203.0.113.1"),
+		},
+		wantErr: "cannot both decapsulate and encapsulate",
+	}, {
+		desc: "unsupported decap",
+		inNH: &aft.Afts_NextHop{
+			DecapsulateHeader: aft.AftTypes_EncapsulationHeaderType_GRE,
+		},
+		wantErr: "unsupported decapsulate header",
+	}, {
+		desc:    "no address",
+		inNH:    &aft.Afts_NextHop{},
+		wantErr: "invalid next-hop 1",
+	}, {
+		desc:       "backup next-hop group",
+		inNH:       &aft.Afts_NextHop{IpAddress: ygot.String("192.0.2.2")},
+		inBackupNH: &aft.Afts_NextHop{IpAddress: ygot.String("192.0.2.6")},
+		want: []*sysribpb.Nexthop{{
+			Type:               sysribpb.Nexthop_TYPE_IPV4,
+			Address:            "192.0.2.2",
+			Weight:             1,
+			AftIndex:           1,
+			AftNetworkInstance: "DEFAULT",
+			Encap:              &routingpb.Headers{},
+		}},
+		wantBackups: []*sysribpb.Nexthop{{
+			Type:               sysribpb.Nexthop_TYPE_IPV4,
+			Address:            "192.0.2.6",
+			Weight:             1,
+			AftIndex:           2,
+			AftNetworkInstance: "DEFAULT",
+			Encap:              &routingpb.Headers{},
+		}},
+	}, {
+		desc:       "invalid backup next hop",
+		inNH:       &aft.Afts_NextHop{IpAddress: ygot.String("192.0.2.2")},
+		inBackupNH: &aft.Afts_NextHop{},
+		wantErr:    "invalid backup NHG",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ribs := newRIBs(tt.inNH, tt.inBackupNH)
+			nhs, err := nextHopsForPrefix(ribs, "DEFAULT", "10.0.0.0/8")
+			if err != nil {
+				t.Fatalf("nextHopsForPrefix() unexpected error: %v", err)
+			}
+			got, err := createSetRouteRequest("DEFAULT", "10.0.0.0/8", nhs, ribs)
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Fatalf("createSetRouteRequest() unexpected error: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			want := &sysribpb.SetRouteRequest{
+				AdminDistance:               5,
+				ProtocolName:                "gRIBI",
+				Safi:                        sysribpb.SetRouteRequest_SAFI_UNICAST,
+				Prefix:                      prefix,
+				Nexthops:                    tt.want,
+				BackupNexthops:              tt.wantBackups,
+				NetworkInstance:             "DEFAULT",
+				NextHopGroupId:              1,
+				NextHopGroupNetworkInstance: "DEFAULT",
+			}
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("createSetRouteRequest() (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
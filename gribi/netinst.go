@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gribi
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// networkInstances tracks the network instances of a gRIBI server and the
+// routes and label entries it set in sysrib for each of them.
+//
+// gribigo cannot remove a network instance from its RIB, so a removed network
+// instance is flushed and its operations rejected until it is added back.
+type networkInstances struct {
+	mu      sync.Mutex
+	known   map[string]bool
+	removed map[string]bool
+	// routes contains the routes set in sysrib, by network instance.
+	routes map[string]map[routeKey]bool
+}
+
+func newNetworkInstances(names []string) *networkInstances {
+	n := &networkInstances{
+		known:   map[string]bool{},
+		removed: map[string]bool{},
+		routes:  map[string]map[routeKey]bool{},
+	}
+	for _, name := range names {
+		n.known[name] = true
+	}
+	return n
+}
+
+// add records that the network instance is configured, returning whether it
+// is new to the gRIBI server.
+func (n *networkInstances) add(ni string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.removed, ni)
+	if n.known[ni] {
+		return false
+	}
+	n.known[ni] = true
+	return true
+}
+
+// remove records that the network instance is removed, returning whether
+// it was known, and the routes that were set in sysrib for it.
+func (n *networkInstances) remove(ni string) (bool, []routeKey) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.known[ni] || n.removed[ni] {
+		return false, nil
+	}
+	n.removed[ni] = true
+	var routes []routeKey
+	for k := range n.routes[ni] {
+		routes = append(routes, k)
+	}
+	slices.SortFunc(routes, func(a, b routeKey) int {
+		return cmp.Or(cmp.Compare(a.prefix, b.prefix), cmp.Compare(a.label, b.label))
+	})
+	delete(n.routes, ni)
+	return true, routes
+}
+
+// isRemoved returns whether the network instance is removed.
+func (n *networkInstances) isRemoved(ni string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.removed[ni]
+}
+
+// setRoute records that a route was set in, or deleted from, sysrib. It
+// returns false if the network instance is removed, in which case the route
+// must not be set.
+func (n *networkInstances) setRoute(k routeKey, isDelete bool) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.removed[k.ni] {
+		return false
+	}
+	switch {
+	case isDelete:
+		delete(n.routes[k.ni], k)
+	case n.routes[k.ni] == nil:
+		n.routes[k.ni] = map[routeKey]bool{k: true}
+	default:
+		n.routes[k.ni][k] = true
+	}
+	return true
+}
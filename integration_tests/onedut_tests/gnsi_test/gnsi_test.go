@@ -16,6 +16,7 @@ package gnsi_test
 
 import (
 	"context"
+	"io"
 	"testing"
 
 	"github.com/openconfig/gnmi/errdiff"
@@ -30,6 +31,7 @@ import (
 	"github.com/openconfig/lemming/internal/binding"
 
 	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	credzpb "github.com/openconfig/gnsi/credentialz"
 	pathzpb "github.com/openconfig/gnsi/pathz"
 )
 
@@ -183,7 +185,7 @@ func TestPathz(t *testing.T) {
 		},
 	}}
 
-	ctx := metadata.AppendToOutgoingContext(context.Background(), "username", "testuser")
+	ctx := metadata.NewOutgoingContext(context.Background(), testUserMetadata)
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
@@ -216,8 +218,13 @@ func mustPath(t testing.TB, s string) *gpb.Path {
 	return p
 }
 
+// testUserMetadata authenticates the calls as the test user, whose password
+// is rotated in by reset.
+var testUserMetadata = metadata.Pairs("username", "testuser", "password", "testpassword")
+
 func reset(t testing.TB, dut *ondatra.DUTDevice) {
 	t.Helper()
+	installPassword(t, dut.RawAPIs().GNSI(t).Credentialz(), "testuser", "testpassword")
 	installPolicy(t, dut.RawAPIs().GNSI(t).Pathz(), &pathzpb.AuthorizationPolicy{
 		Rules: []*pathzpb.AuthorizationRule{{
 			Path:      mustPath(t, "/"),
@@ -232,12 +239,42 @@ func reset(t testing.TB, dut *ondatra.DUTDevice) {
 		}},
 	})
 
-	gnmi.Update(t, dut.GNMIOpts().WithMetadata(metadata.Pairs("username", "testuser")), ocpath.Root().System().Config(), &oc.System{
+	gnmi.Update(t, dut.GNMIOpts().WithMetadata(testUserMetadata), ocpath.Root().System().Config(), &oc.System{
 		DomainName: ygot.String("lemming.example.com"),
 		Hostname:   ygot.String("lemming"),
 	})
 }
 
+func installPassword(t testing.TB, credzClient credzpb.CredentialzClient, account, password string) {
+	t.Helper()
+
+	rc, err := credzClient.RotateAccountCredentials(context.Background())
+	if err != nil {
+		t.Fatalf("failed to start rotation: %v", err)
+	}
+	if err := rc.Send(&credzpb.RotateAccountCredentialsRequest{
+		Request: &credzpb.RotateAccountCredentialsRequest_Password{Password: &credzpb.PasswordRequest{
+			Accounts: []*credzpb.PasswordRequest_Account{{
+				Account:  account,
+				Password: &credzpb.PasswordRequest_Password{Value: &credzpb.PasswordRequest_Password_Plaintext{Plaintext: password}},
+			}},
+		}},
+	}); err != nil {
+		t.Fatalf("failed to send password req: %v", err)
+	}
+	if _, err := rc.Recv(); err != nil {
+		t.Fatalf("failed to recv password resp: %v", err)
+	}
+	if err := rc.Send(&credzpb.RotateAccountCredentialsRequest{
+		Request: &credzpb.RotateAccountCredentialsRequest_Finalize{Finalize: &credzpb.FinalizeRequest{}},
+	}); err != nil {
+		t.Fatalf("failed to send finalize req: %v", err)
+	}
+	if _, err := rc.Recv(); err != io.EOF {
+		t.Fatalf("failed to finalize rotation: %v", err)
+	}
+}
+
 func installPolicy(t testing.TB, pathzClient pathzpb.PathzClient, req *pathzpb.AuthorizationPolicy) {
 	t.Helper()
 
@@ -245,7 +282,9 @@ func installPolicy(t testing.TB, pathzClient pathzpb.PathzClient, req *pathzpb.A
 	if err != nil {
 		t.Fatalf("failed to start rotation: %v", err)
 	}
+	// The policies are not versioned, so they overwrite the previous policy.
 	if err := rc.Send(&pathzpb.RotateRequest{
+		ForceOverwrite: true,
 		RotateRequest: &pathzpb.RotateRequest_UploadRequest{UploadRequest: &pathzpb.UploadRequest{
 			Policy: req,
 		}},
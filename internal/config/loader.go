@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
 
 	log "github.com/golang/glog"
 
@@ -111,6 +112,10 @@ func mergeWithDefaults(userConfig *configpb.Config) *configpb.Config {
 		Interfaces:        defaultInterfaces(),
 		LinkQualification: defaultLinkQualification(),
 		FaultConfig:       defaultFaultConfig(),
+		Diag:              defaultDiag(),
+		Reboot:            defaultReboot(),
+		File:              defaultFile(),
+		Clock:             defaultClock(),
 	}
 
 	if userConfig == nil {
@@ -149,6 +154,24 @@ func mergeWithDefaults(userConfig *configpb.Config) *configpb.Config {
 		config.FaultConfig = userConfig.FaultConfig
 	}
 
+	// Diag is merged per field, so that a config setting only error
+	// profiles keeps the default BERT duration limits.
+	if userConfig.Diag != nil {
+		proto.Merge(config.Diag, userConfig.Diag)
+	}
+
+	if userConfig.Reboot != nil {
+		config.Reboot = userConfig.Reboot
+	}
+
+	if userConfig.File != nil {
+		config.File = userConfig.File
+	}
+
+	if userConfig.Clock != nil {
+		config.Clock = userConfig.Clock
+	}
+
 	return config
 }
 
@@ -246,6 +269,8 @@ func defaultLinkQualification() *configpb.LinkQualificationConfig {
 		DefaultPacketSize:     8184,
 		DefaultTestDurationMs: 5000, // 5 seconds
 		MaxHistoricalResults:  10,
+		MaxDataplanePps:       10000,
+		MaxInjectedPackets:    1000000,
 	}
 }
 
@@ -256,6 +281,40 @@ func defaultFaultConfig() *configpb.FaultServiceConfiguration {
 	}
 }
 
+// defaultDiag returns default diagnostics configuration
+func defaultDiag() *configpb.DiagConfig {
+	return &configpb.DiagConfig{
+		MinBertDurationSecs: 1,
+		MaxBertDurationSecs: 86400, // 1 day
+		DefaultErrorProfile: &configpb.BertErrorProfile{},
+	}
+}
+
+// defaultFile returns default file service configuration
+func defaultFile() *configpb.FileConfig {
+	return &configpb.FileConfig{
+		RemoteTransfer:    false,
+		TransferTimeoutMs: 30000,
+	}
+}
+
+// defaultClock returns default clock configuration
+func defaultClock() *configpb.ClockConfig {
+	return &configpb.ClockConfig{
+		OffsetMs:            0,
+		DriftPpm:            0,
+		NtpStratum:          2,
+		NtpPollIntervalSecs: 64,
+	}
+}
+
+// defaultReboot returns default reboot configuration
+func defaultReboot() *configpb.RebootConfig {
+	return &configpb.RebootConfig{
+		ResetState: false,
+	}
+}
+
 // parseFromEmbedded parses configuration from an embedded file
 func parseFromEmbedded(path string) (*configpb.Config, error) {
 	data, err := configs.FS.ReadFile(path)
@@ -344,6 +403,24 @@ func validate(config *configpb.Config) error {
 		}
 	}
 
+	if config.Diag != nil {
+		if err := validateDiag(config.Diag); err != nil {
+			return fmt.Errorf("diag validation failed: %v", err)
+		}
+	}
+
+	if config.File != nil {
+		if err := validateFile(config.File); err != nil {
+			return fmt.Errorf("file validation failed: %v", err)
+		}
+	}
+
+	if config.Clock != nil {
+		if err := validateClock(config.Clock); err != nil {
+			return fmt.Errorf("clock validation failed: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -630,6 +707,9 @@ func validateCapabilityFields(lq *configpb.LinkQualificationConfig) error {
 			return fmt.Errorf("max_pps %d exceeds reasonable maximum 10B PPS", lq.MaxPps)
 		}
 	}
+	if lq.MaxDataplanePps > 0 && lq.MaxPps > 0 && lq.MaxDataplanePps > lq.MaxPps {
+		return fmt.Errorf("max_dataplane_pps %d exceeds max_pps %d", lq.MaxDataplanePps, lq.MaxPps)
+	}
 	if lq.MaxHistoricalResults <= 1 || lq.MaxHistoricalResults > 20 {
 		return fmt.Errorf("max_historical_results must be between 2 and 20, got %d", lq.MaxHistoricalResults)
 	}
@@ -637,6 +717,61 @@ func validateCapabilityFields(lq *configpb.LinkQualificationConfig) error {
 	return nil
 }
 
+// validateDiag validates diagnostics configuration
+func validateDiag(diag *configpb.DiagConfig) error {
+	if diag.MinBertDurationSecs == 0 {
+		return fmt.Errorf("min_bert_duration_secs must be positive, got %d", diag.MinBertDurationSecs)
+	}
+	if diag.MaxBertDurationSecs < diag.MinBertDurationSecs {
+		return fmt.Errorf("max_bert_duration_secs %d is less than min_bert_duration_secs %d", diag.MaxBertDurationSecs, diag.MinBertDurationSecs)
+	}
+
+	if diag.DefaultErrorProfile.GetErrorsPerMinute() < 0 {
+		return fmt.Errorf("default_error_profile: errors_per_minute must be non-negative, got %f", diag.DefaultErrorProfile.GetErrorsPerMinute())
+	}
+	seen := make(map[string]bool)
+	for i, profile := range diag.ErrorProfile {
+		if profile.Interface == "" {
+			return fmt.Errorf("error_profile[%d]: interface is required", i)
+		}
+		if seen[profile.Interface] {
+			return fmt.Errorf("duplicate error profile for interface %q", profile.Interface)
+		}
+		seen[profile.Interface] = true
+		if profile.ErrorsPerMinute < 0 {
+			return fmt.Errorf("error_profile[%d]: errors_per_minute must be non-negative, got %f", i, profile.ErrorsPerMinute)
+		}
+	}
+	return nil
+}
+
+// validateFile validates file service configuration
+func validateFile(file *configpb.FileConfig) error {
+	if file.RootDir != "" && !strings.HasPrefix(file.RootDir, "/") {
+		return fmt.Errorf("root_dir must be an absolute path, got %q", file.RootDir)
+	}
+	if file.RemoteTransfer && file.TransferTimeoutMs == 0 {
+		return fmt.Errorf("transfer_timeout_ms must be positive when remote_transfer is enabled")
+	}
+	return nil
+}
+
+// validateClock validates clock configuration
+func validateClock(clock *configpb.ClockConfig) error {
+	// A drift of -1e6 ppm would stop the device clock.
+	if clock.DriftPpm <= -1e6 || clock.DriftPpm >= 1e6 {
+		return fmt.Errorf("drift_ppm must be between -1000000 and 1000000, got %f", clock.DriftPpm)
+	}
+	// Stratum 16 means unsynchronized, and is not a server stratum.
+	if clock.NtpStratum < 1 || clock.NtpStratum > 15 {
+		return fmt.Errorf("ntp_stratum must be between 1 and 15, got %d", clock.NtpStratum)
+	}
+	if clock.NtpPollIntervalSecs == 0 {
+		return fmt.Errorf("ntp_poll_interval_secs must be positive")
+	}
+	return nil
+}
+
 // validateFaultConfig validates fault service configuration
 func validateFaultConfig(faultConfig *configpb.FaultServiceConfiguration) error {
 	if faultConfig == nil {
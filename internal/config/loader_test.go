@@ -489,6 +489,27 @@ func TestMergeWithDefaults(t *testing.T) {
 	}
 }
 
+func TestMergeDiagWithDefaults(t *testing.T) {
+	userConfig := &configpb.Config{
+		Diag: &configpb.DiagConfig{
+			ErrorProfile: []*configpb.BertErrorProfile{{Interface: "eth1", ErrorsPerMinute: 10}},
+		},
+	}
+	result := mergeWithDefaults(userConfig)
+	want := &configpb.DiagConfig{
+		MinBertDurationSecs: 1,
+		MaxBertDurationSecs: 86400,
+		DefaultErrorProfile: &configpb.BertErrorProfile{},
+		ErrorProfile:        []*configpb.BertErrorProfile{{Interface: "eth1", ErrorsPerMinute: 10}},
+	}
+	if diff := cmp.Diff(want, result.GetDiag(), protocmp.Transform()); diff != "" {
+		t.Errorf("Diag mismatch (-want +got):\n%s", diff)
+	}
+	if err := validateDiag(result.GetDiag()); err != nil {
+		t.Errorf("validateDiag() of merged config got error: %v", err)
+	}
+}
+
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -1389,6 +1410,22 @@ func TestValidateLinkQualification(t *testing.T) {
 			wantError: true,
 			errorMsg:  "exceeds reasonable maximum 10B PPS",
 		},
+		{
+			name: "max_dataplane_pps_exceeds_max_pps",
+			config: &configpb.LinkQualificationConfig{
+				MinSetupDurationMs:    1000,
+				MinTeardownDurationMs: 1000,
+				MinSampleIntervalMs:   10000,
+				DefaultPacketRate:     138888,
+				DefaultPacketSize:     8184,
+				DefaultTestDurationMs: 5000,
+				MaxPps:                1000,
+				MaxDataplanePps:       2000, // exceeds max_pps
+				MaxHistoricalResults:  10,
+			},
+			wantError: true,
+			errorMsg:  "max_dataplane_pps 2000 exceeds max_pps 1000",
+		},
 		{
 			name: "invalid_max_historical_results_low",
 			config: &configpb.LinkQualificationConfig{
@@ -1445,6 +1482,220 @@ func TestValidateLinkQualification(t *testing.T) {
 	}
 }
 
+func TestValidateDiag(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *configpb.DiagConfig
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "valid_diag_config",
+			config: &configpb.DiagConfig{
+				MinBertDurationSecs: 1,
+				MaxBertDurationSecs: 3600,
+				DefaultErrorProfile: &configpb.BertErrorProfile{ErrorsPerMinute: 1},
+				ErrorProfile: []*configpb.BertErrorProfile{
+					{Interface: "eth0", ErrorsPerMinute: 100},
+					{Interface: "eth1", PeerLockFailure: true},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "zero_min_bert_duration",
+			config: &configpb.DiagConfig{
+				MaxBertDurationSecs: 3600,
+			},
+			wantError: true,
+			errorMsg:  "min_bert_duration_secs must be positive",
+		},
+		{
+			name: "max_less_than_min_bert_duration",
+			config: &configpb.DiagConfig{
+				MinBertDurationSecs: 60,
+				MaxBertDurationSecs: 30,
+			},
+			wantError: true,
+			errorMsg:  "is less than min_bert_duration_secs",
+		},
+		{
+			name: "negative_default_error_rate",
+			config: &configpb.DiagConfig{
+				MinBertDurationSecs: 1,
+				MaxBertDurationSecs: 3600,
+				DefaultErrorProfile: &configpb.BertErrorProfile{ErrorsPerMinute: -1},
+			},
+			wantError: true,
+			errorMsg:  "errors_per_minute must be non-negative",
+		},
+		{
+			name: "profile_without_interface",
+			config: &configpb.DiagConfig{
+				MinBertDurationSecs: 1,
+				MaxBertDurationSecs: 3600,
+				ErrorProfile:        []*configpb.BertErrorProfile{{ErrorsPerMinute: 1}},
+			},
+			wantError: true,
+			errorMsg:  "interface is required",
+		},
+		{
+			name: "duplicate_profile",
+			config: &configpb.DiagConfig{
+				MinBertDurationSecs: 1,
+				MaxBertDurationSecs: 3600,
+				ErrorProfile: []*configpb.BertErrorProfile{
+					{Interface: "eth0"},
+					{Interface: "eth0"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "duplicate error profile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDiag(tt.config)
+
+			if tt.wantError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.wantError && err != nil && tt.errorMsg != "" {
+				if !containsSubstring(err.Error(), tt.errorMsg) {
+					t.Errorf("Expected error to contain %q, got %q", tt.errorMsg, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *configpb.FileConfig
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "valid_file_config",
+			config: &configpb.FileConfig{
+				RemoteTransfer:    true,
+				RootDir:           "/var/lib/lemming/files",
+				TransferTimeoutMs: 1000,
+			},
+			wantError: false,
+		},
+		{
+			name:      "in_memory_simulated",
+			config:    &configpb.FileConfig{},
+			wantError: false,
+		},
+		{
+			name: "relative_root_dir",
+			config: &configpb.FileConfig{
+				RootDir: "files",
+			},
+			wantError: true,
+			errorMsg:  "root_dir must be an absolute path",
+		},
+		{
+			name: "remote_transfer_without_timeout",
+			config: &configpb.FileConfig{
+				RemoteTransfer: true,
+			},
+			wantError: true,
+			errorMsg:  "transfer_timeout_ms must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFile(tt.config)
+
+			if tt.wantError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.wantError && err != nil && tt.errorMsg != "" {
+				if !containsSubstring(err.Error(), tt.errorMsg) {
+					t.Errorf("Expected error to contain %q, got %q", tt.errorMsg, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateClock(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *configpb.ClockConfig
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "valid_skewed_clock",
+			config: &configpb.ClockConfig{
+				OffsetMs:            -90000,
+				DriftPpm:            50,
+				NtpStratum:          3,
+				NtpPollIntervalSecs: 16,
+			},
+			wantError: false,
+		},
+		{
+			name: "clock_stopped",
+			config: &configpb.ClockConfig{
+				DriftPpm:            -1e6,
+				NtpStratum:          2,
+				NtpPollIntervalSecs: 64,
+			},
+			wantError: true,
+			errorMsg:  "drift_ppm must be between",
+		},
+		{
+			name: "unsynchronized_stratum",
+			config: &configpb.ClockConfig{
+				NtpStratum:          16,
+				NtpPollIntervalSecs: 64,
+			},
+			wantError: true,
+			errorMsg:  "ntp_stratum must be between 1 and 15",
+		},
+		{
+			name: "zero_poll_interval",
+			config: &configpb.ClockConfig{
+				NtpStratum: 2,
+			},
+			wantError: true,
+			errorMsg:  "ntp_poll_interval_secs must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateClock(tt.config)
+
+			if tt.wantError && err == nil {
+				t.Error("Expected error but got none")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.wantError && err != nil && tt.errorMsg != "" {
+				if !containsSubstring(err.Error(), tt.errorMsg) {
+					t.Errorf("Expected error to contain %q, got %q", tt.errorMsg, err.Error())
+				}
+			}
+		})
+	}
+}
+
 func TestIsValidRPCMethod(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -17,10 +17,17 @@ package lemming
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"net"
+	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"google.golang.org/grpc"
@@ -40,6 +47,11 @@ import (
 	"github.com/openconfig/lemming/gnmi/reconciler"
 	fgnoi "github.com/openconfig/lemming/gnoi"
 	fgnsi "github.com/openconfig/lemming/gnsi"
+	"github.com/openconfig/lemming/gnsi/acctz"
+	"github.com/openconfig/lemming/gnsi/authz"
+	"github.com/openconfig/lemming/gnsi/certz"
+	"github.com/openconfig/lemming/gnsi/credentialz"
+	"github.com/openconfig/lemming/gnsi/pathz"
 	fgribi "github.com/openconfig/lemming/gribi"
 	"github.com/openconfig/lemming/internal/config"
 	fp4rt "github.com/openconfig/lemming/p4rt"
@@ -50,12 +62,68 @@ import (
 	log "github.com/golang/glog"
 )
 
+// dataplaneRouteTimeout is how long the routes set by gRIBI wait for the
+// dataplane to program them before they are acknowledged as FIB_FAILED.
+const dataplaneRouteTimeout = 10 * time.Second
+
 type gRPCService struct {
 	s       *grpc.Server
 	lis     net.Listener
 	stopped chan struct{}
 }
 
+// trackingListener is a net.Listener that keeps track of the connections it
+// accepted, so that they can be closed without stopping the gRPC server.
+type trackingListener struct {
+	net.Listener
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+func newTrackingListener(l net.Listener) *trackingListener {
+	return &trackingListener{
+		Listener: l,
+		conns:    map[net.Conn]struct{}{},
+	}
+}
+
+func (l *trackingListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	tc := &trackedConn{Conn: c, l: l}
+	l.mu.Lock()
+	l.conns[tc] = struct{}{}
+	l.mu.Unlock()
+	return tc, nil
+}
+
+// closeAll closes the listener and all the connections it accepted.
+func (l *trackingListener) closeAll() {
+	l.Listener.Close()
+	l.mu.Lock()
+	conns := l.conns
+	l.conns = map[net.Conn]struct{}{}
+	l.mu.Unlock()
+	for c := range conns {
+		c.Close()
+	}
+}
+
+type trackedConn struct {
+	net.Conn
+	l *trackingListener
+}
+
+func (c *trackedConn) Close() error {
+	c.l.mu.Lock()
+	delete(c.l.conns, c)
+	c.l.mu.Unlock()
+	return c.Conn.Close()
+}
+
 // Device is the reference device implementation.
 type Device struct {
 	gnmignoignsiService *gRPCService
@@ -70,6 +138,14 @@ type Device struct {
 	gnsiServer   *fgnsi.Server
 	p4rtServer   *fp4rt.Server
 	dplaneServer *dataplane.Dataplane
+	sysribServer *sysrib.Server
+
+	// rebootMu serializes reboots and protects the services while they are
+	// restarted.
+	rebootMu sync.Mutex
+	// rebooting is set while the services are down for a reboot, so that the
+	// errors returned by their Serve calls are not recorded.
+	rebooting atomic.Bool
 
 	// Configuration
 	config *configpb.Config
@@ -100,12 +176,27 @@ type opt struct {
 	dataplaneOpts  []dplaneopts.Option
 	gribiOpts      []gribis.ServerOpt
 	configFile     string
+
+	// tlsCert is the initial certificate of the TLS credentials managed by
+	// gNSI certz.
+	tlsCert *tls.Certificate
+	// clientCAs is the initial trust bundle verifying the required client
+	// certificates, if not nil.
+	clientCAs []*x509.Certificate
+	// certPrincipals maps the identities of client certificates to
+	// principals.
+	certPrincipals map[string]string
+	// gnsiStateDir is the directory persisting the gNSI artifacts, if set.
+	gnsiStateDir string
+	// anonymousClients lets through the calls presenting no credential.
+	anonymousClients bool
 }
 
 // resolveOpts applies all the options and returns a struct containing the result.
 func resolveOpts(opts []Option) *opt {
 	o := &opt{
-		sysribAddr: "/tmp/sysrib.api",
+		sysribAddr:       "/tmp/sysrib.api",
+		anonymousClients: true,
 	}
 	for _, opt := range opts {
 		opt(o)
@@ -154,20 +245,64 @@ func WithBGPPort(port uint16) Option {
 
 // WithTLSCredsFromFile loads the credentials from the specified cert and key file
 // and returns them such that they can be used for the gNMI and gRIBI servers.
+// The certificate is the initial certificate of the default gNSI certz
+// profile, and is replaced by certz rotations.
 func WithTLSCredsFromFile(certFile, keyFile string) (Option, error) {
-	t, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
 	if err != nil {
 		return nil, err
 	}
 	return func(o *opt) {
-		o.tlsCredentials = t
+		o.tlsCert = &cert
+		o.tlsCredentials = nil
 	}, nil
 }
 
+// WithClientCertAuth requires the clients of the TLS credentials managed by
+// gNSI certz to present a certificate issued by a CA of caFile, a PEM bundle
+// which is the initial trust bundle of the default certz profile. The clients
+// are authenticated as the principal their certificate's SPIFFE ID, DNS SAN or
+// common name maps to in principals, or as that identity if principals is nil.
+func WithClientCertAuth(caFile string, principals map[string]string) (Option, error) {
+	b, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	var cas []*x509.Certificate
+	for block, rest := pem.Decode(b); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		ca, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		cas = append(cas, ca)
+	}
+	if len(cas) == 0 {
+		return nil, fmt.Errorf("no certificate in %s", caFile)
+	}
+	return func(o *opt) {
+		o.clientCAs = cas
+		o.certPrincipals = principals
+	}, nil
+}
+
+// WithAnonymousClients sets whether the calls presenting neither a username
+// and password nor a client certificate are let through, which they are by
+// default. Otherwise gNSI credentialz rejects them as unauthenticated.
+func WithAnonymousClients(allow bool) Option {
+	return func(o *opt) {
+		o.anonymousClients = allow
+	}
+}
+
 // WithTransportCreds returns a wrapper of TransportCredentials into a DevOpt.
+// The credentials are not managed by gNSI certz.
 func WithTransportCreds(c credentials.TransportCredentials) Option {
 	return func(o *opt) {
 		o.tlsCredentials = c
+		o.tlsCert = nil
 	}
 }
 
@@ -213,6 +348,15 @@ func WithFaultAddr(addr string) Option {
 	}
 }
 
+// WithGNSIStateDir persists the finalized gNSI authz, certz, credentialz and
+// pathz artifacts in dir, and restores the artifacts stored in dir, so that
+// they survive restarts of the device.
+func WithGNSIStateDir(dir string) Option {
+	return func(o *opt) {
+		o.gnsiStateDir = dir
+	}
+}
+
 // WithConfigFile specifies a configuration file path for lemming device settings.
 // The file has to be in protobuf text (.textproto/.pb.txt) format.
 func WithConfigFile(configFile string) Option {
@@ -265,11 +409,38 @@ func New(targetName, zapiURL string, opts ...Option) (*Device, error) {
 	streamInt := []grpc.StreamServerInterceptor{fgnmi.NewSubscribeTargetUpdateInterceptor(targetName)}
 	unaryInt := []grpc.UnaryServerInterceptor{}
 
-	creds := resolvedOpts.tlsCredentials
+	certzServer := certz.New(resolvedOpts.tlsCert)
+	if resolvedOpts.clientCAs != nil {
+		certzServer.RequireClientCerts(resolvedOpts.clientCAs)
+	}
+	creds, gribiCreds := resolvedOpts.tlsCredentials, resolvedOpts.tlsCredentials
+	if resolvedOpts.tlsCert != nil {
+		creds = certzServer.Credentials("gnmi", "gnoi", "gnsi")
+		gribiCreds = certzServer.Credentials("gribi")
+	}
 	if creds != nil {
 		grpcOpts = append(grpcOpts, grpc.Creds(creds))
 	}
 
+	// The acctz interceptors record every call, including the ones rejected by
+	// the credentialz and authz interceptors, which come next so that calls
+	// from unauthenticated or denied users are rejected before any other
+	// processing. The calls are recorded with the principal authenticated by
+	// credentialz. Authentication must precede authz, which trusts the
+	// username metadata.
+	acctzServer := acctz.New(acctz.DefaultHistorySize)
+	streamInt = append(streamInt, acctzServer.Stream)
+	unaryInt = append(unaryInt, acctzServer.Unary)
+	credzServer := credentialz.New()
+	credzServer.SetCertPrincipals(resolvedOpts.certPrincipals)
+	credzServer.SetAllowAnonymous(resolvedOpts.anonymousClients)
+	pathzServer := pathz.New()
+	streamInt = append(streamInt, credzServer.Stream)
+	unaryInt = append(unaryInt, credzServer.Unary)
+	authzServer := authz.New()
+	streamInt = append(streamInt, authzServer.Stream)
+	unaryInt = append(unaryInt, authzServer.Unary)
+
 	var faultService *gRPCService
 
 	// Initialize fault interceptor with static config
@@ -297,18 +468,32 @@ func New(targetName, zapiURL string, opts ...Option) (*Device, error) {
 
 	s := grpc.NewServer(grpcOpts...)
 
+	clock := fakedevice.NewClock(lemmingConfig.GetClock())
 	recs = append(recs,
 		fakedevice.NewSystemBaseTask(),
 		fakedevice.NewBootTimeTask(lemmingConfig),
-		fakedevice.NewCurrentTimeTask(),
+		fakedevice.NewCurrentTimeTask(clock),
+		fakedevice.NewNTPTask(lemmingConfig, clock),
 		fakedevice.NewChassisComponentsTask(lemmingConfig),
 		fakedevice.NewProcessMonitoringTask(lemmingConfig),
 		fakedevice.NewInterfaceInitializationTask(lemmingConfig),
 		bgp.NewGoBGPTask(targetName, zapiURL, resolvedOpts.bgpPort),
+		authzServer.Reconciler(),
+		certzServer.Reconciler(),
+		credzServer.Reconciler(),
+		acctzServer.Reconciler(),
+		pathzServer.Reconciler(),
 	)
 
 	log.Info("starting gNSI")
-	gnsiServer := fgnsi.New(s)
+	var gnsiOpts []fgnsi.Option
+	if resolvedOpts.gnsiStateDir != "" {
+		gnsiOpts = append(gnsiOpts, fgnsi.WithStateDir(resolvedOpts.gnsiStateDir))
+	}
+	gnsiServer, err := fgnsi.New(s, authzServer, certzServer, credzServer, acctzServer, pathzServer, gnsiOpts...)
+	if err != nil {
+		return nil, err
+	}
 
 	gnmiServer, err := fgnmi.New(s, targetName, gnsiServer.GetPathZ(), recs...)
 	if err != nil {
@@ -318,7 +503,12 @@ func New(targetName, zapiURL string, opts ...Option) (*Device, error) {
 	cacheClient := gnmiServer.LocalClient()
 
 	log.Infof("starting sysrib")
-	sysribServer, err := sysrib.New(root)
+	var sysribOpts []sysrib.Option
+	if resolvedOpts.dataplane {
+		// Acknowledge routes once the dataplane has programmed them.
+		sysribOpts = append(sysribOpts, sysrib.WithDataplaneStatus(dataplaneRouteTimeout))
+	}
+	sysribServer, err := sysrib.New(root, sysribOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -327,15 +517,24 @@ func New(targetName, zapiURL string, opts ...Option) (*Device, error) {
 	}
 
 	log.Info("starting gRIBI")
-	// TODO(wenbli): Use gRIBIs once we change lemming's KNE config to use different ports.
-	// gRIBIs := grpc.NewServer()
-	gribiServer, err := fgribi.New(s, cacheClient, targetName, root, fmt.Sprintf("unix:%s", resolvedOpts.sysribAddr), resolvedOpts.gribiOpts...)
+	gribiOpts := []grpc.ServerOption{
+		grpc.ChainStreamInterceptor(acctzServer.Stream, credzServer.Stream, authzServer.Stream, faultInt.Stream),
+		grpc.ChainUnaryInterceptor(acctzServer.Unary, credzServer.Unary, authzServer.Unary, faultInt.Unary),
+	}
+	if gribiCreds != nil {
+		gribiOpts = append(gribiOpts, grpc.Creds(gribiCreds))
+	}
+	gRIBIs := grpc.NewServer(gribiOpts...)
+	gribiServer, err := fgribi.New(gRIBIs, cacheClient, targetName, root, fmt.Sprintf("unix:%s", resolvedOpts.sysribAddr), resolvedOpts.gribiOpts...)
 	if err != nil {
 		return nil, err
 	}
 
 	log.Info("starting P4RT (there is nothing here yet)")
-	P4RTs := grpc.NewServer()
+	P4RTs := grpc.NewServer(
+		grpc.ChainStreamInterceptor(acctzServer.Stream, credzServer.Stream, authzServer.Stream),
+		grpc.ChainUnaryInterceptor(acctzServer.Unary, credzServer.Unary, authzServer.Unary),
+	)
 
 	log.Info("Create listeners")
 	lgnmi, err := net.Listen("tcp", resolvedOpts.gnmiAddr)
@@ -353,26 +552,32 @@ func New(targetName, zapiURL string, opts ...Option) (*Device, error) {
 		return nil, fmt.Errorf("cannot create gRPC server for P4RT, %v", err)
 	}
 
-	gnoiServer, err := fgnoi.New(s, cacheClient, targetName, lemmingConfig)
+	// The device is filled in below, once the gNOI server that reboots it has
+	// been created.
+	d := &Device{}
+	gnoiOpts := []fgnoi.Option{fgnoi.WithRIB(sysribServer), fgnoi.WithRebooter(d), fgnoi.WithClock(clock)}
+	if dplane != nil {
+		gnoiOpts = append(gnoiOpts, fgnoi.WithProber(dplane), fgnoi.WithLinkTester(dplane), fgnoi.WithLabelDataplane(dplane))
+	}
+	gnoiServer, err := fgnoi.New(s, cacheClient, targetName, lemmingConfig, gnoiOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	d := &Device{
+	*d = Device{
 		gnmignoignsiService: &gRPCService{
 			s:       s,
-			lis:     lgnmi,
+			lis:     newTrackingListener(lgnmi),
 			stopped: make(chan struct{}),
 		},
 		gribiService: &gRPCService{
-			// TODO(wenbli): Change s to gRIBIs once we change lemming's KNE config to use different ports.
-			s:       s,
-			lis:     lgribi,
+			s:       gRIBIs,
+			lis:     newTrackingListener(lgribi),
 			stopped: make(chan struct{}),
 		},
 		p4rtService: &gRPCService{
 			s:       P4RTs,
-			lis:     lp4rt,
+			lis:     newTrackingListener(lp4rt),
 			stopped: make(chan struct{}),
 		},
 		faultService: faultService,
@@ -382,9 +587,11 @@ func New(targetName, zapiURL string, opts ...Option) (*Device, error) {
 		gnsiServer:   gnsiServer,
 		p4rtServer:   fp4rt.New(P4RTs),
 		dplaneServer: dplane,
+		sysribServer: sysribServer,
 		config:       lemmingConfig,
 	}
 	reflection.Register(s)
+	reflection.Register(gRIBIs)
 	d.startServer()
 
 	if err := gnmiServer.StartReconcilers(context.Background()); err != nil {
@@ -436,6 +643,8 @@ func (d *Device) P4RTListener() net.Listener {
 // If error is not nil, it will contain why the server failed.
 func (d *Device) Stop() error {
 	klog.Info("Stopping server")
+	d.rebootMu.Lock()
+	defer d.rebootMu.Unlock()
 	select {
 	case <-d.stopped:
 		klog.Infof("Server already stopped: %v", d.errs)
@@ -486,19 +695,7 @@ func (d *Device) startServer() {
 		if svc == nil {
 			continue
 		}
-		// Capture loop variables by value instead of reference.
-		svcName, svc := svcName, svc
-		go func() {
-			if err := svc.s.Serve(svc.lis); err != nil {
-				d.errsMu.Lock()
-				d.errs = append(d.errs, err)
-				d.errsMu.Unlock()
-			}
-			d.errsMu.Lock()
-			klog.Infof("%s server stopped: %v", svcName, d.errs)
-			d.errsMu.Unlock()
-			close(svc.stopped)
-		}()
+		d.serve(svcName, svc)
 	}
 
 	d.stop = func() {
@@ -514,3 +711,115 @@ func (d *Device) startServer() {
 		}
 	}
 }
+
+// serve starts serving the service on its listener in the background, and
+// closes svc.stopped once it stops.
+func (d *Device) serve(svcName string, svc *gRPCService) {
+	lis, stopped := svc.lis, svc.stopped
+	go func() {
+		if err := svc.s.Serve(lis); err != nil && !d.rebooting.Load() {
+			d.errsMu.Lock()
+			d.errs = append(d.errs, err)
+			d.errsMu.Unlock()
+		}
+		d.errsMu.Lock()
+		klog.Infof("%s server stopped: %v", svcName, d.errs)
+		d.errsMu.Unlock()
+		close(stopped)
+	}()
+}
+
+// Reboot resets the protocol and dataplane state of the device: the gRPC
+// services are taken down, the reconcilers stopped, the gRIBI and system RIBs
+// flushed and the dataplane reset. After the configured reboot duration, the
+// services are brought back up on the same addresses and the reconcilers
+// re-apply the configuration. The fault service is kept running.
+//
+// The services and reconcilers are brought back up even if the reset fails,
+// and reboot errors are recorded so that Stop reports them.
+func (d *Device) Reboot(ctx context.Context) (err error) {
+	d.rebootMu.Lock()
+	defer d.rebootMu.Unlock()
+	select {
+	case <-d.stopped:
+		return fmt.Errorf("device is stopped")
+	default:
+	}
+	klog.Info("Rebooting device")
+
+	services := map[string]*gRPCService{
+		"gNMI/gNOI/gNSI": d.gnmignoignsiService,
+		"gRIBI":          d.gribiService,
+		"P4RT":           d.p4rtService,
+	}
+	d.rebooting.Store(true)
+	for _, svc := range services {
+		svc.lis.(*trackingListener).closeAll()
+	}
+	for _, svc := range services {
+		<-svc.stopped
+	}
+	d.rebooting.Store(false)
+
+	defer func() {
+		if serveErr := d.restartServices(services); serveErr != nil {
+			err = errors.Join(err, serveErr)
+		}
+		if err != nil {
+			d.errsMu.Lock()
+			d.errs = append(d.errs, fmt.Errorf("reboot failed: %v", err))
+			d.errsMu.Unlock()
+			return
+		}
+		klog.Info("Device rebooted")
+	}()
+
+	if err := d.gnmiServer.StopReconcilers(ctx); err != nil {
+		return fmt.Errorf("failed to stop reconcilers: %v", err)
+	}
+	resetErr := d.resetState(ctx)
+	if err := d.gnmiServer.StartReconcilers(context.Background()); err != nil {
+		return errors.Join(resetErr, fmt.Errorf("failed to start reconcilers: %v", err))
+	}
+	return resetErr
+}
+
+// resetState flushes the gRIBI and system RIBs, resets the dataplane and waits
+// for the configured reboot duration.
+func (d *Device) resetState(ctx context.Context) error {
+	if err := d.gribiServer.Reset(ctx); err != nil {
+		return fmt.Errorf("failed to reset gRIBI: %v", err)
+	}
+	if err := d.sysribServer.FlushProtocolRoutes(ctx); err != nil {
+		return fmt.Errorf("failed to flush sysrib: %v", err)
+	}
+	if d.dplaneServer != nil {
+		if err := d.dplaneServer.Reset(ctx); err != nil {
+			return fmt.Errorf("failed to reset dataplane: %v", err)
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Duration(d.config.GetTiming().GetRebootDurationMs()) * time.Millisecond):
+	}
+	return nil
+}
+
+// restartServices serves the services on new listeners on their previous
+// addresses.
+func (d *Device) restartServices(services map[string]*gRPCService) error {
+	var errs []error
+	for svcName, svc := range services {
+		l, err := net.Listen("tcp", svc.lis.Addr().String())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot recreate listener for %s: %v", svcName, err))
+			continue
+		}
+		svc.lis = newTrackingListener(l)
+		svc.stopped = make(chan struct{})
+		d.serve(svcName, svc)
+	}
+	return errors.Join(errs...)
+}
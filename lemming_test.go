@@ -16,13 +16,47 @@ package lemming
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/openconfig/gnmi/errdiff"
+	"github.com/openconfig/gribigo/chk"
+	"github.com/openconfig/gribigo/constants"
+	"github.com/openconfig/gribigo/fluent"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"github.com/openconfig/ygot/ygot"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/openconfig/lemming/gnmi/fakedevice"
+	"github.com/openconfig/lemming/gnmi/gnmiclient"
+	"github.com/openconfig/lemming/gnmi/oc"
+	"github.com/openconfig/lemming/gnmi/oc/ocpath"
+	"github.com/openconfig/lemming/sysrib"
+
+	routingpb "github.com/openconfig/lemming/proto/routing"
+
+	gribipb "github.com/openconfig/gribi/v1/proto/service"
+
 	// gNMI
 	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
 
@@ -40,6 +74,9 @@ import (
 	spb "github.com/openconfig/gnoi/system"
 	wrpb "github.com/openconfig/gnoi/wavelength_router"
 	// gNSI
+	acctzpb "github.com/openconfig/gnsi/acctz"
+	authzpb "github.com/openconfig/gnsi/authz"
+	credzpb "github.com/openconfig/gnsi/credentialz"
 	// authzpb "github.com/openconfig/gnsi/authz/authz_go_proto"
 	// certpb "github.com/openconfig/gnsi/cert/cert_go_proto"
 	// consolepb "github.com/openconfig/gnsi/console/console_go_proto"
@@ -102,6 +139,546 @@ func TestStop(t *testing.T) {
 	})
 }
 
+func TestReboot(t *testing.T) {
+	f := startLemming(t)
+	const rebootDuration = 500 * time.Millisecond
+	f.Config().GetTiming().RebootDurationMs = rebootDuration.Milliseconds()
+	addr := f.GNMIAddr()
+	ctx := context.Background()
+
+	// A connected route, configured on the interface, that the gRIBI entry
+	// resolves over.
+	yc, err := ygnmi.NewClient(f.GNMI().LocalClient(), ygnmi.WithTarget("fakedevice"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	intf := &oc.Interface{Name: ygot.String("eth0"), Enabled: ygot.Bool(true), Ifindex: ygot.Uint32(1)}
+	intf.GetOrCreateSubinterface(0).GetOrCreateIpv4().GetOrCreateAddress("192.0.2.1").PrefixLength = ygot.Uint8(30)
+	if _, err := gnmiclient.Replace(ctx, yc, ocpath.Root().Interface("eth0").State(), intf); err != nil {
+		t.Fatalf("cannot configure interface: %v", err)
+	}
+	connected := sysrib.RouteKey{Prefix: "192.0.2.0/30", NIName: fakedevice.DefaultNetworkInstance}
+	gribiRoute := sysrib.RouteKey{Prefix: "198.51.100.0/24", NIName: fakedevice.DefaultNetworkInstance}
+	awaitRoutes := func(t *testing.T, want map[sysrib.RouteKey]bool) {
+		t.Helper()
+		for start := time.Now(); ; time.Sleep(50 * time.Millisecond) {
+			routes := f.sysribServer.ResolvedRoutes()
+			ok := true
+			for key, present := range want {
+				if _, got := routes[key]; got != present {
+					ok = false
+				}
+			}
+			if ok {
+				return
+			}
+			if time.Since(start) > 10*time.Second {
+				t.Fatalf("resolved routes got %v, want presence %v", routes, want)
+			}
+		}
+	}
+	awaitRoutes(t, map[sysrib.RouteKey]bool{connected: true})
+
+	gribiConn, err := grpc.NewClient(f.GRIBIAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to Dial gRIBI: %v", err)
+	}
+	defer gribiConn.Close()
+	gribic := fluent.NewClient()
+	gribic.Connection().WithStub(gribipb.NewGRIBIClient(gribiConn)).
+		WithRedundancyMode(fluent.ElectedPrimaryClient).
+		WithPersistence().
+		WithInitialElectionID(1, 0)
+	gribic.Start(ctx, t)
+	gribic.StartSending(ctx, t)
+	gribic.Modify().AddEntry(t,
+		fluent.NextHopEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithIndex(1).WithIPAddress("192.0.2.2"),
+		fluent.NextHopGroupEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithID(1).AddNextHop(1, 1),
+		fluent.IPv4Entry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithPrefix(gribiRoute.Prefix).WithNextHopGroup(1),
+	)
+	awaitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := gribic.Await(awaitCtx, t); err != nil {
+		t.Fatalf("gRIBI Await failed: %v", err)
+	}
+	awaitRoutes(t, map[sysrib.RouteKey]bool{connected: true, gribiRoute: true})
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to Dial fake: %v", err)
+	}
+	defer conn.Close()
+	c := spb.NewSystemClient(conn)
+	if _, err := c.Time(ctx, &spb.TimeRequest{}); err != nil {
+		t.Fatalf("Time failed before reboot: %v", err)
+	}
+
+	rebootErr := make(chan error, 1)
+	start := time.Now()
+	go func() { rebootErr <- f.Reboot(ctx) }()
+	// The services are down for the reboot duration.
+	time.Sleep(rebootDuration / 2)
+	timeCtx, cancel := context.WithTimeout(ctx, rebootDuration/10)
+	defer cancel()
+	if _, err := c.Time(timeCtx, &spb.TimeRequest{}); err == nil {
+		t.Errorf("Time during reboot succeeded, want error")
+	}
+	if err := <-rebootErr; err != nil {
+		t.Fatalf("Reboot failed: %v", err)
+	}
+	if got := time.Since(start); got < rebootDuration {
+		t.Errorf("Reboot took %v, want at least %v", got, rebootDuration)
+	}
+	gribic.Stop(t)
+
+	if got := f.GNMIAddr(); got != addr {
+		t.Errorf("GNMIAddr after reboot got %q, want %q", got, addr)
+	}
+	// The connection failed during the reboot, so wait for it to reconnect.
+	timeCtx, cancel = context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if _, err := c.Time(timeCtx, &spb.TimeRequest{}, grpc.WaitForReady(true)); err != nil {
+		t.Fatalf("Time failed after reboot: %v", err)
+	}
+	// The gRIBI entries are flushed, the connected route is kept.
+	awaitRoutes(t, map[sysrib.RouteKey]bool{connected: true, gribiRoute: false})
+	getResp, err := gribipb.NewGRIBIClient(gribiConn).Get(ctx, &gribipb.GetRequest{
+		NetworkInstance: &gribipb.GetRequest_All{All: &gribipb.Empty{}},
+		Aft:             gribipb.AFTType_ALL,
+	}, grpc.WaitForReady(true))
+	if err != nil {
+		t.Fatalf("gRIBI Get failed after reboot: %v", err)
+	}
+	if resp, err := getResp.Recv(); err != io.EOF {
+		t.Errorf("gRIBI Get after reboot got %v, %v, want no entries", resp, err)
+	}
+	if err := f.Stop(); err != nil {
+		t.Fatalf("did not get nil error on stop, got: %v", err)
+	}
+}
+
+func TestGRIBINetworkInstanceRemoval(t *testing.T) {
+	f := startLemming(t)
+	defer f.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	const vrf = "vrf1"
+
+	// A connected route, configured on the interface, that the gRIBI entry
+	// resolves over.
+	local, err := ygnmi.NewClient(f.GNMI().LocalClient(), ygnmi.WithTarget("fakedevice"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	intf := &oc.Interface{Name: ygot.String("eth0"), Enabled: ygot.Bool(true), Ifindex: ygot.Uint32(1)}
+	intf.GetOrCreateSubinterface(0).GetOrCreateIpv4().GetOrCreateAddress("192.0.2.1").PrefixLength = ygot.Uint8(30)
+	if _, err := gnmiclient.Replace(ctx, local, ocpath.Root().Interface("eth0").State(), intf); err != nil {
+		t.Fatalf("cannot configure interface: %v", err)
+	}
+	conn, err := grpc.NewClient(f.GNMIAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to Dial fake: %v", err)
+	}
+	defer conn.Close()
+	yc, err := ygnmi.NewClient(gnmipb.NewGNMIClient(conn), ygnmi.WithTarget("fakedevice"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	if _, err := ygnmi.Replace(ctx, yc, ocpath.Root().NetworkInstance(vrf).Config(), &oc.NetworkInstance{
+		Name: ygot.String(vrf),
+		Type: oc.NetworkInstanceTypes_NETWORK_INSTANCE_TYPE_L3VRF,
+	}); err != nil {
+		t.Fatalf("cannot configure network instance: %v", err)
+	}
+
+	gribiConn, err := grpc.NewClient(f.GRIBIAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to Dial gRIBI: %v", err)
+	}
+	defer gribiConn.Close()
+	gribic := fluent.NewClient()
+	gribic.Connection().WithStub(gribipb.NewGRIBIClient(gribiConn)).
+		WithRedundancyMode(fluent.ElectedPrimaryClient).
+		WithPersistence().
+		WithInitialElectionID(1, 0)
+	gribic.Start(ctx, t)
+	defer gribic.Stop(t)
+	gribic.StartSending(ctx, t)
+	addRoute := func(t *testing.T, id uint64, prefix string) {
+		t.Helper()
+		gribic.Modify().AddEntry(t,
+			fluent.NextHopEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithIndex(id).WithIPAddress("192.0.2.2"),
+			fluent.NextHopGroupEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithID(id).AddNextHop(id, 1),
+			fluent.IPv4Entry().WithNetworkInstance(vrf).WithPrefix(prefix).WithNextHopGroup(id).WithNextHopGroupNetworkInstance(fakedevice.DefaultNetworkInstance),
+		)
+		if err := gribic.Await(ctx, t); err != nil {
+			t.Fatalf("gRIBI Await failed: %v", err)
+		}
+	}
+	route := sysrib.RouteKey{Prefix: "198.51.100.0/24", NIName: vrf}
+	awaitRoute := func(t *testing.T, want bool) {
+		t.Helper()
+		for start := time.Now(); ; time.Sleep(50 * time.Millisecond) {
+			if _, got := f.sysribServer.ResolvedRoutes()[route]; got == want {
+				return
+			}
+			if time.Since(start) > 10*time.Second {
+				t.Fatalf("route %v resolved got %v, want %v", route, !want, want)
+			}
+		}
+	}
+
+	// The network instance is added to gRIBI asynchronously.
+	for id := uint64(1); ; id++ {
+		addRoute(t, id, route.Prefix)
+		if _, ok := f.sysribServer.ResolvedRoutes()[route]; ok || id == 10 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	awaitRoute(t, true)
+
+	if _, err := ygnmi.Delete(ctx, yc, ocpath.Root().NetworkInstance(vrf).Config()); err != nil {
+		t.Fatalf("cannot delete network instance: %v", err)
+	}
+	awaitRoute(t, false)
+	getResp, err := gribipb.NewGRIBIClient(gribiConn).Get(ctx, &gribipb.GetRequest{
+		NetworkInstance: &gribipb.GetRequest_Name{Name: vrf},
+		Aft:             gribipb.AFTType_ALL,
+	})
+	if err != nil {
+		t.Fatalf("gRIBI Get failed: %v", err)
+	}
+	if resp, err := getResp.Recv(); err != io.EOF {
+		t.Errorf("gRIBI Get after network instance removal got %v, %v, want no entries", resp, err)
+	}
+
+	// Operations on the removed network instance are rejected.
+	addRoute(t, 100, "203.0.113.0/24")
+	chk.HasResult(t, gribic.Results(t),
+		fluent.OperationResult().
+			WithIPv4Operation("203.0.113.0/24").
+			WithProgrammingResult(fluent.ProgrammingFailed).
+			WithOperationType(constants.Add).
+			AsResult(),
+		chk.IgnoreOperationID(),
+	)
+	if _, ok := f.sysribServer.ResolvedRoutes()[sysrib.RouteKey{Prefix: "203.0.113.0/24", NIName: vrf}]; ok {
+		t.Errorf("route in removed network instance got resolved")
+	}
+}
+
+func TestGRIBILabelEntry(t *testing.T) {
+	f := startLemming(t)
+	defer f.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// A connected route, configured on the interface, that the label entry
+	// resolves over.
+	local, err := ygnmi.NewClient(f.GNMI().LocalClient(), ygnmi.WithTarget("fakedevice"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	intf := &oc.Interface{Name: ygot.String("eth0"), Enabled: ygot.Bool(true), Ifindex: ygot.Uint32(1)}
+	intf.GetOrCreateSubinterface(0).GetOrCreateIpv4().GetOrCreateAddress("192.0.2.1").PrefixLength = ygot.Uint8(30)
+	if _, err := gnmiclient.Replace(ctx, local, ocpath.Root().Interface("eth0").State(), intf); err != nil {
+		t.Fatalf("cannot configure interface: %v", err)
+	}
+	connected := sysrib.RouteKey{Prefix: "192.0.2.0/30", NIName: fakedevice.DefaultNetworkInstance}
+	for start := time.Now(); ; time.Sleep(50 * time.Millisecond) {
+		if _, ok := f.sysribServer.ResolvedRoutes()[connected]; ok {
+			break
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatalf("connected route %v not resolved", connected)
+		}
+	}
+
+	gribiConn, err := grpc.NewClient(f.GRIBIAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to Dial gRIBI: %v", err)
+	}
+	defer gribiConn.Close()
+	gribic := fluent.NewClient()
+	gribic.Connection().WithStub(gribipb.NewGRIBIClient(gribiConn)).
+		WithRedundancyMode(fluent.ElectedPrimaryClient).
+		WithPersistence().
+		WithInitialElectionID(1, 0).
+		WithFIBACK()
+	gribic.Start(ctx, t)
+	defer gribic.Stop(t)
+	gribic.StartSending(ctx, t)
+
+	entries := []fluent.GRIBIEntry{
+		fluent.NextHopEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithIndex(1).WithIPAddress("192.0.2.2").WithPushedLabelStack(200),
+		fluent.NextHopGroupEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithID(1).AddNextHop(1, 1),
+		fluent.LabelEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithLabel(100).WithNextHopGroup(1),
+	}
+	gribic.Modify().AddEntry(t, entries...)
+	if err := gribic.Await(ctx, t); err != nil {
+		t.Fatalf("gRIBI Await failed: %v", err)
+	}
+	chk.HasResult(t, gribic.Results(t),
+		fluent.OperationResult().
+			WithMPLSOperation(100).
+			WithProgrammingResult(fluent.InstalledInFIB).
+			WithOperationType(constants.Add).
+			AsResult(),
+		chk.IgnoreOperationID(),
+	)
+	label := sysrib.LabelKey{Label: 100, NIName: fakedevice.DefaultNetworkInstance}
+	programmed, ok := f.sysribServer.ProgrammedLabels()[label]
+	if !ok {
+		t.Fatalf("label %v not programmed", label)
+	}
+	if programmed.PopLabels != 1 || len(programmed.Nexthops) != 1 || programmed.Nexthops[0].Address != "192.0.2.2" {
+		t.Errorf("label %v got programmed as %+v, want swapped to next hop 192.0.2.2", label, programmed)
+	}
+	if got, err := ygnmi.Get(ctx, local, ocpath.Root().NetworkInstance(fakedevice.DefaultNetworkInstance).Afts().LabelEntry(oc.UnionUint32(100)).State()); err != nil || got.GetNextHopGroup() != 1 {
+		t.Errorf("label entry got %v, %v, want next hop group 1", got, err)
+	}
+
+	gribic.Modify().DeleteEntry(t, entries[2])
+	if err := gribic.Await(ctx, t); err != nil {
+		t.Fatalf("gRIBI Await failed: %v", err)
+	}
+	if _, ok := f.sysribServer.ProgrammedLabels()[label]; ok {
+		t.Errorf("label %v is still programmed after deletion", label)
+	}
+}
+
+func TestGRIBIEncapDecap(t *testing.T) {
+	f := startLemming(t)
+	defer f.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	const vrf = "vrf1"
+
+	// A connected route, configured on the interface, that the tunnel
+	// destination resolves over.
+	local, err := ygnmi.NewClient(f.GNMI().LocalClient(), ygnmi.WithTarget("fakedevice"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	intf := &oc.Interface{Name: ygot.String("eth0"), Enabled: ygot.Bool(true), Ifindex: ygot.Uint32(1)}
+	intf.GetOrCreateSubinterface(0).GetOrCreateIpv4().GetOrCreateAddress("192.0.2.1").PrefixLength = ygot.Uint8(30)
+	if _, err := gnmiclient.Replace(ctx, local, ocpath.Root().Interface("eth0").State(), intf); err != nil {
+		t.Fatalf("cannot configure interface: %v", err)
+	}
+	conn, err := grpc.NewClient(f.GNMIAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to Dial fake: %v", err)
+	}
+	defer conn.Close()
+	yc, err := ygnmi.NewClient(gnmipb.NewGNMIClient(conn), ygnmi.WithTarget("fakedevice"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	if _, err := ygnmi.Replace(ctx, yc, ocpath.Root().NetworkInstance(vrf).Config(), &oc.NetworkInstance{
+		Name: ygot.String(vrf),
+		Type: oc.NetworkInstanceTypes_NETWORK_INSTANCE_TYPE_L3VRF,
+	}); err != nil {
+		t.Fatalf("cannot configure network instance: %v", err)
+	}
+	connected := sysrib.RouteKey{Prefix: "192.0.2.0/30", NIName: fakedevice.DefaultNetworkInstance}
+	for start := time.Now(); ; time.Sleep(50 * time.Millisecond) {
+		if _, ok := f.sysribServer.ResolvedRoutes()[connected]; ok {
+			break
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatalf("connected route %v not resolved", connected)
+		}
+	}
+
+	gribiConn, err := grpc.NewClient(f.GRIBIAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to Dial gRIBI: %v", err)
+	}
+	defer gribiConn.Close()
+	gribic := fluent.NewClient()
+	gribic.Connection().WithStub(gribipb.NewGRIBIClient(gribiConn)).
+		WithRedundancyMode(fluent.ElectedPrimaryClient).
+		WithPersistence().
+		WithInitialElectionID(1, 0).
+		WithFIBACK()
+	gribic.Start(ctx, t)
+	defer gribic.Stop(t)
+	gribic.StartSending(ctx, t)
+
+	const encapPrefix, decapPrefix = "198.51.100.0/24", "203.0.113.0/24"
+	gribic.Modify().AddEntry(t,
+		fluent.NextHopEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithIndex(1).WithIPinIP("192.0.2.1", "192.0.2.2"),
+		fluent.NextHopGroupEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithID(1).AddNextHop(1, 1),
+		fluent.IPv4Entry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithPrefix(encapPrefix).WithNextHopGroup(1),
+		fluent.NextHopEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithIndex(2).WithDecapsulateHeader(fluent.IPinIP).WithNextHopNetworkInstance(vrf),
+		fluent.NextHopGroupEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithID(2).AddNextHop(2, 1),
+		fluent.IPv4Entry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithPrefix(decapPrefix).WithNextHopGroup(2),
+	)
+	if err := gribic.Await(ctx, t); err != nil {
+		t.Fatalf("gRIBI Await failed: %v", err)
+	}
+	for _, prefix := range []string{encapPrefix, decapPrefix} {
+		chk.HasResult(t, gribic.Results(t),
+			fluent.OperationResult().
+				WithIPv4Operation(prefix).
+				WithProgrammingResult(fluent.InstalledInFIB).
+				WithOperationType(constants.Add).
+				AsResult(),
+			chk.IgnoreOperationID(),
+		)
+	}
+
+	programmed := f.sysribServer.ProgrammedRoutes()
+	encap, ok := programmed[sysrib.RouteKey{Prefix: encapPrefix, NIName: fakedevice.DefaultNetworkInstance}]
+	if !ok {
+		t.Fatalf("route %s not programmed", encapPrefix)
+	}
+	if len(encap.Nexthops) != 1 || encap.Nexthops[0].Address != "192.0.2.2" || len(encap.Nexthops[0].Headers) != 1 {
+		t.Errorf("route %s got programmed as %+v, want encapsulated to 192.0.2.2", encapPrefix, encap)
+	}
+	decap, ok := programmed[sysrib.RouteKey{Prefix: decapPrefix, NIName: fakedevice.DefaultNetworkInstance}]
+	if !ok {
+		t.Fatalf("route %s not programmed", decapPrefix)
+	}
+	if len(decap.Nexthops) != 1 || decap.Nexthops[0].Decap != routingpb.HeaderType_HEADER_TYPE_IP4 || decap.Nexthops[0].NetworkInstance != vrf {
+		t.Errorf("route %s got programmed as %+v, want decapsulated into %s", decapPrefix, decap, vrf)
+	}
+}
+
+func TestGRIBIBackupNextHopGroup(t *testing.T) {
+	f := startLemming(t)
+	defer f.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// A connected route, configured on the interface, that both the primary
+	// and backup next hops resolve over.
+	local, err := ygnmi.NewClient(f.GNMI().LocalClient(), ygnmi.WithTarget("fakedevice"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	intf := &oc.Interface{Name: ygot.String("eth0"), Enabled: ygot.Bool(true), Ifindex: ygot.Uint32(1)}
+	intf.GetOrCreateSubinterface(0).GetOrCreateIpv4().GetOrCreateAddress("192.0.2.1").PrefixLength = ygot.Uint8(29)
+	if _, err := gnmiclient.Replace(ctx, local, ocpath.Root().Interface("eth0").State(), intf); err != nil {
+		t.Fatalf("cannot configure interface: %v", err)
+	}
+	connected := sysrib.RouteKey{Prefix: "192.0.2.0/29", NIName: fakedevice.DefaultNetworkInstance}
+	for start := time.Now(); ; time.Sleep(50 * time.Millisecond) {
+		if _, ok := f.sysribServer.ResolvedRoutes()[connected]; ok {
+			break
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatalf("connected route %v not resolved", connected)
+		}
+	}
+
+	gribiConn, err := grpc.NewClient(f.GRIBIAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to Dial gRIBI: %v", err)
+	}
+	defer gribiConn.Close()
+	gribic := fluent.NewClient()
+	gribic.Connection().WithStub(gribipb.NewGRIBIClient(gribiConn)).
+		WithRedundancyMode(fluent.ElectedPrimaryClient).
+		WithPersistence().
+		WithInitialElectionID(1, 0).
+		WithFIBACK()
+	gribic.Start(ctx, t)
+	defer gribic.Stop(t)
+	gribic.StartSending(ctx, t)
+
+	const prefix = "198.51.100.0/24"
+	gribic.Modify().AddEntry(t,
+		fluent.NextHopEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithIndex(1).WithIPAddress("192.0.2.2"),
+		fluent.NextHopEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithIndex(2).WithIPAddress("192.0.2.3"),
+		fluent.NextHopGroupEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithID(2).AddNextHop(2, 1),
+		fluent.NextHopGroupEntry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithID(1).AddNextHop(1, 1).WithBackupNHG(2),
+		fluent.IPv4Entry().WithNetworkInstance(fakedevice.DefaultNetworkInstance).WithPrefix(prefix).WithNextHopGroup(1),
+	)
+	if err := gribic.Await(ctx, t); err != nil {
+		t.Fatalf("gRIBI Await failed: %v", err)
+	}
+	chk.HasResult(t, gribic.Results(t),
+		fluent.OperationResult().
+			WithIPv4Operation(prefix).
+			WithProgrammingResult(fluent.InstalledInFIB).
+			WithOperationType(constants.Add).
+			AsResult(),
+		chk.IgnoreOperationID(),
+	)
+
+	route := sysrib.RouteKey{Prefix: prefix, NIName: fakedevice.DefaultNetworkInstance}
+	programmed, ok := f.sysribServer.ProgrammedRoutes()[route]
+	if !ok {
+		t.Fatalf("route %v not programmed", route)
+	}
+	if len(programmed.BackupNexthops) != 1 || programmed.BackupNexthops[0].Address != "192.0.2.3" {
+		t.Errorf("route %v got programmed as %+v, want backup next hop 192.0.2.3", route, programmed)
+	}
+}
+
+func TestClock(t *testing.T) {
+	const skew = -time.Hour
+	configFile := filepath.Join(t.TempDir(), "clock.textproto")
+	cfg := fmt.Sprintf("clock {\n  offset_ms: %d\n  ntp_stratum: 3\n  ntp_poll_interval_secs: 16\n}\n", skew.Milliseconds())
+	if err := os.WriteFile(configFile, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f := startLemming(t, WithConfigFile(configFile))
+	defer f.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	conn, err := grpc.NewClient(f.GNMIAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to Dial fake: %v", err)
+	}
+	defer conn.Close()
+	yc, err := ygnmi.NewClient(gnmipb.NewGNMIClient(conn), ygnmi.WithTarget("fakedevice"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	// skewed returns whether the device time got is the host time skewed by
+	// skew, within margin.
+	skewed := func(got time.Time, margin time.Duration) bool {
+		d := got.Sub(time.Now().Add(skew))
+		return d > -margin && d < margin
+	}
+
+	resp, err := spb.NewSystemClient(conn).Time(ctx, &spb.TimeRequest{})
+	if err != nil {
+		t.Fatalf("gnoi.System.Time failed: %v", err)
+	}
+	if got := time.Unix(0, int64(resp.GetTime())); !skewed(got, time.Second) {
+		t.Errorf("gnoi.System.Time got %v, want host time skewed by %v", got, skew)
+	}
+	// The current time is published every second, in seconds.
+	datetime, err := ygnmi.Get(ctx, yc, ocpath.Root().System().CurrentDatetime().State())
+	if err != nil {
+		t.Fatalf("cannot get current datetime: %v", err)
+	}
+	if got, err := time.Parse(time.RFC3339, datetime); err != nil || !skewed(got, 3*time.Second) {
+		t.Errorf("current datetime got %q, want host time skewed by %v", datetime, skew)
+	}
+
+	// The generated schema misses the key-id config of the NTP servers, so
+	// servers cannot be set until it is regenerated, and only the NTP state
+	// itself is reconciled here.
+	ntpPath := ocpath.Root().System().Ntp()
+	if _, err := ygnmi.Replace(ctx, yc, ntpPath.Config(), &oc.System_Ntp{Enabled: ygot.Bool(true)}); err != nil {
+		t.Fatalf("cannot configure NTP: %v", err)
+	}
+	if _, err := ygnmi.Await(ctx, yc, ntpPath.Enabled().State(), true); err != nil {
+		t.Fatalf("NTP state not published: %v", err)
+	}
+	if _, err := ygnmi.Replace(ctx, yc, ntpPath.Enabled().Config(), false); err != nil {
+		t.Fatalf("cannot disable NTP: %v", err)
+	}
+	if _, err := ygnmi.Await(ctx, yc, ntpPath.Enabled().State(), false); err != nil {
+		t.Fatalf("NTP state not updated: %v", err)
+	}
+}
+
 func TestFakeGNOI(t *testing.T) {
 	f := startLemming(t)
 	defer f.stop()
@@ -194,6 +771,287 @@ func TestFakeGNOI(t *testing.T) {
 	}
 }
 
+func TestAuthz(t *testing.T) {
+	f := startLemming(t)
+	defer f.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	conn, err := grpc.NewClient(f.GNMIAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to Dial fake: %v", err)
+	}
+	defer conn.Close()
+	asUser := func(user string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "username", user, "password", user+"-password")
+	}
+
+	credRot, err := credzpb.NewCredentialzClient(conn).RotateAccountCredentials(ctx)
+	if err != nil {
+		t.Fatalf("gnsi.Credentialz.RotateAccountCredentials failed: %v", err)
+	}
+	var accounts []*credzpb.PasswordRequest_Account
+	for _, user := range []string{"admin", "guest"} {
+		accounts = append(accounts, &credzpb.PasswordRequest_Account{
+			Account:  user,
+			Password: &credzpb.PasswordRequest_Password{Value: &credzpb.PasswordRequest_Password_Plaintext{Plaintext: user + "-password"}},
+			Version:  "v1",
+		})
+	}
+	if err := credRot.Send(&credzpb.RotateAccountCredentialsRequest{
+		Request: &credzpb.RotateAccountCredentialsRequest_Password{Password: &credzpb.PasswordRequest{Accounts: accounts}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := credRot.Recv(); err != nil {
+		t.Fatalf("gnsi.Credentialz.RotateAccountCredentials password failed: %v", err)
+	}
+	if err := credRot.Send(&credzpb.RotateAccountCredentialsRequest{
+		Request: &credzpb.RotateAccountCredentialsRequest_Finalize{Finalize: &credzpb.FinalizeRequest{}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := credRot.Recv(); err != io.EOF {
+		t.Fatalf("gnsi.Credentialz.RotateAccountCredentials finalize got err %v, want EOF", err)
+	}
+
+	rot, err := authzpb.NewAuthzClient(conn).Rotate(ctx)
+	if err != nil {
+		t.Fatalf("gnsi.Authz.Rotate failed: %v", err)
+	}
+	if err := rot.Send(&authzpb.RotateAuthzRequest{
+		RotateRequest: &authzpb.RotateAuthzRequest_UploadRequest{
+			UploadRequest: &authzpb.UploadRequest{
+				Version: "v1",
+				Policy:  `{"name": "admins", "allow_rules": [{"name": "admin", "source": {"principals": ["admin"]}}]}`,
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rot.Recv(); err != nil {
+		t.Fatalf("gnsi.Authz.Rotate upload failed: %v", err)
+	}
+	if err := rot.Send(&authzpb.RotateAuthzRequest{RotateRequest: &authzpb.RotateAuthzRequest_FinalizeRotation{}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rot.Recv(); err != io.EOF {
+		t.Fatalf("gnsi.Authz.Rotate finalize got err %v, want EOF", err)
+	}
+
+	sc := spb.NewSystemClient(conn)
+	if _, err := sc.Time(asUser("admin"), &spb.TimeRequest{}); err != nil {
+		t.Errorf("gnoi.System.Time as admin failed: %v", err)
+	}
+	if _, err := sc.Time(asUser("guest"), &spb.TimeRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("gnoi.System.Time as guest got err %v, want PermissionDenied", err)
+	}
+	impostor := metadata.AppendToOutgoingContext(ctx, "username", "admin", "password", "guest-password")
+	if _, err := sc.Time(impostor, &spb.TimeRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("gnoi.System.Time with a wrong password got err %v, want Unauthenticated", err)
+	}
+
+	yc, err := ygnmi.NewClient(gnmipb.NewGNMIClient(conn), ygnmi.WithTarget("fakedevice"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	counters := ocpath.Root().System().GrpcServer("gnoi").AuthzPolicyCounters().Rpc("/gnoi.system.System/Time").State()
+	_, err = ygnmi.Watch(asUser("admin"), yc, counters, func(v *ygnmi.Value[*oc.System_GrpcServer_AuthzPolicyCounters_Rpc]) error {
+		if rpc, ok := v.Val(); ok && rpc.GetAccessAccepts() == 1 && rpc.GetAccessRejects() == 1 {
+			return nil
+		}
+		return ygnmi.Continue
+	}).Await()
+	if err != nil {
+		t.Errorf("gnoi.System.Time authz counters not published: %v", err)
+	}
+	version, err := ygnmi.Get(asUser("admin"), yc, ocpath.Root().System().Aaa().Authorization().GrpcAuthzPolicyVersion().State())
+	if err != nil || version != "v1" {
+		t.Errorf("authz policy version got %q, %v, want %q", version, err, "v1")
+	}
+}
+
+// issueCert issues a certificate from the template, signed by parent and
+// parentKey or self-signed if parent is nil, and returns it with its key.
+func issueCert(t *testing.T, tmpl, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.NotBefore, tmpl.NotAfter = time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestClientCertAuth(t *testing.T) {
+	ca, caKey := issueCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "ca"}, IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}, nil, nil)
+	serverCert, serverKey := issueCert(t, &x509.Certificate{DNSNames: []string{"lemming"}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}, ca, caKey)
+	clientCert := func(id string) *tls.Certificate {
+		u, err := url.Parse(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, key := issueCert(t, &x509.Certificate{URIs: []*url.URL{u}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}, ca, caKey)
+		return &tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key}
+	}
+
+	dir := t.TempDir()
+	keyDER, err := x509.MarshalPKCS8PrivateKey(serverKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]*pem.Block{
+		"ca.pem":   {Type: "CERTIFICATE", Bytes: ca.Raw},
+		"cert.pem": {Type: "CERTIFICATE", Bytes: serverCert.Raw},
+		"key.pem":  {Type: "PRIVATE KEY", Bytes: keyDER},
+	}
+	for name, block := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tlsOpt, err := WithTLSCredsFromFile(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientOpt, err := WithClientCertAuth(filepath.Join(dir, "ca.pem"), map[string]string{
+		"spiffe://lemming.test/admin": "admin",
+		"spiffe://lemming.test/guest": "guest",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := startLemming(t, tlsOpt, clientOpt)
+	defer f.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	dial := func(cert *tls.Certificate) *grpc.ClientConn {
+		cfg := &tls.Config{RootCAs: roots, ServerName: "lemming"}
+		if cert != nil {
+			cfg.Certificates = []tls.Certificate{*cert}
+		}
+		conn, err := grpc.NewClient(f.GNMIAddr(), grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+		if err != nil {
+			t.Fatalf("failed to Dial fake: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	admin := dial(clientCert("spiffe://lemming.test/admin"))
+
+	rot, err := authzpb.NewAuthzClient(admin).Rotate(ctx)
+	if err != nil {
+		t.Fatalf("gnsi.Authz.Rotate failed: %v", err)
+	}
+	if err := rot.Send(&authzpb.RotateAuthzRequest{
+		RotateRequest: &authzpb.RotateAuthzRequest_UploadRequest{
+			UploadRequest: &authzpb.UploadRequest{
+				Version: "v1",
+				Policy:  `{"name": "admins", "allow_rules": [{"name": "admin", "source": {"principals": ["admin"]}}]}`,
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rot.Recv(); err != nil {
+		t.Fatalf("gnsi.Authz.Rotate upload failed: %v", err)
+	}
+	if err := rot.Send(&authzpb.RotateAuthzRequest{RotateRequest: &authzpb.RotateAuthzRequest_FinalizeRotation{}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rot.Recv(); err != io.EOF {
+		t.Fatalf("gnsi.Authz.Rotate finalize got err %v, want EOF", err)
+	}
+
+	tests := []struct {
+		desc     string
+		conn     *grpc.ClientConn
+		ctx      context.Context
+		wantCode codes.Code
+	}{{
+		desc: "admin certificate",
+		conn: admin,
+		ctx:  ctx,
+	}, {
+		desc:     "guest certificate",
+		conn:     dial(clientCert("spiffe://lemming.test/guest")),
+		ctx:      ctx,
+		wantCode: codes.PermissionDenied,
+	}, {
+		desc:     "guest certificate claiming admin",
+		conn:     dial(clientCert("spiffe://lemming.test/guest")),
+		ctx:      metadata.AppendToOutgoingContext(ctx, "username", "admin"),
+		wantCode: codes.Unauthenticated,
+	}, {
+		desc:     "unmapped certificate",
+		conn:     dial(clientCert("spiffe://lemming.test/other")),
+		ctx:      ctx,
+		wantCode: codes.Unauthenticated,
+	}, {
+		desc:     "no certificate",
+		conn:     dial(nil),
+		ctx:      ctx,
+		wantCode: codes.Unavailable,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := spb.NewSystemClient(tt.conn).Time(tt.ctx, &spb.TimeRequest{})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("gnoi.System.Time got err %v, want code %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAcctz(t *testing.T) {
+	f := startLemming(t)
+	defer f.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	conn, err := grpc.NewClient(f.GNMIAddr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to Dial fake: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := spb.NewSystemClient(conn).Time(ctx, &spb.TimeRequest{}); err != nil {
+		t.Fatalf("gnoi.System.Time failed: %v", err)
+	}
+	rs, err := acctzpb.NewAcctzClient(conn).RecordSubscribe(ctx)
+	if err != nil {
+		t.Fatalf("gnsi.Acctz.RecordSubscribe failed: %v", err)
+	}
+	if err := rs.Send(&acctzpb.RecordRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		resp, err := rs.Recv()
+		if err != nil {
+			t.Fatalf("gnsi.Acctz.RecordSubscribe got err %v before the gnoi.System.Time record", err)
+		}
+		if svc := resp.GetGrpcService(); svc.GetRpcName() == "/gnoi.system.System/Time" {
+			if svc.GetServiceType() != acctzpb.GrpcService_GRPC_SERVICE_TYPE_GNOI || resp.GetSessionInfo().GetRemoteAddress() == "" {
+				t.Errorf("gnoi.System.Time record got %v, want a GNOI record with the remote address", resp)
+			}
+			return
+		}
+	}
+}
+
 /*
 func TestGNSI(t *testing.T) {
 	desc := "gnsi.Authz.Rotate"
@@ -31,6 +31,10 @@ type Config struct {
 	Interfaces        *InterfaceConfig           `protobuf:"bytes,6,opt,name=interfaces,proto3" json:"interfaces,omitempty"`
 	LinkQualification *LinkQualificationConfig   `protobuf:"bytes,7,opt,name=link_qualification,json=linkQualification,proto3" json:"link_qualification,omitempty"`
 	FaultConfig       *FaultServiceConfiguration `protobuf:"bytes,8,opt,name=fault_config,json=faultConfig,proto3" json:"fault_config,omitempty"`
+	Diag              *DiagConfig                `protobuf:"bytes,9,opt,name=diag,proto3" json:"diag,omitempty"`
+	Reboot            *RebootConfig              `protobuf:"bytes,10,opt,name=reboot,proto3" json:"reboot,omitempty"`
+	File              *FileConfig                `protobuf:"bytes,11,opt,name=file,proto3" json:"file,omitempty"`
+	Clock             *ClockConfig               `protobuf:"bytes,12,opt,name=clock,proto3" json:"clock,omitempty"`
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
@@ -121,6 +125,34 @@ func (x *Config) GetFaultConfig() *FaultServiceConfiguration {
 	return nil
 }
 
+func (x *Config) GetDiag() *DiagConfig {
+	if x != nil {
+		return x.Diag
+	}
+	return nil
+}
+
+func (x *Config) GetReboot() *RebootConfig {
+	if x != nil {
+		return x.Reboot
+	}
+	return nil
+}
+
+func (x *Config) GetFile() *FileConfig {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *Config) GetClock() *ClockConfig {
+	if x != nil {
+		return x.Clock
+	}
+	return nil
+}
+
 type ProcessesConfig struct {
 	state                protoimpl.MessageState `protogen:"open.v1"`
 	Process              []*ProcessConfig       `protobuf:"bytes,1,rep,name=process,proto3" json:"process,omitempty"`
@@ -477,6 +509,178 @@ func (x *TimingConfig) GetProcessRestartDelayMs() int64 {
 	return 0
 }
 
+type RebootConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResetState    bool                   `protobuf:"varint,1,opt,name=reset_state,json=resetState,proto3" json:"reset_state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RebootConfig) Reset() {
+	*x = RebootConfig{}
+	mi := &file_proto_config_lemming_config_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RebootConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RebootConfig) ProtoMessage() {}
+
+func (x *RebootConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_config_lemming_config_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RebootConfig.ProtoReflect.Descriptor instead.
+func (*RebootConfig) Descriptor() ([]byte, []int) {
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RebootConfig) GetResetState() bool {
+	if x != nil {
+		return x.ResetState
+	}
+	return false
+}
+
+type FileConfig struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	RemoteTransfer    bool                   `protobuf:"varint,1,opt,name=remote_transfer,json=remoteTransfer,proto3" json:"remote_transfer,omitempty"`
+	RootDir           string                 `protobuf:"bytes,2,opt,name=root_dir,json=rootDir,proto3" json:"root_dir,omitempty"`
+	TransferTimeoutMs uint32                 `protobuf:"varint,3,opt,name=transfer_timeout_ms,json=transferTimeoutMs,proto3" json:"transfer_timeout_ms,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *FileConfig) Reset() {
+	*x = FileConfig{}
+	mi := &file_proto_config_lemming_config_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileConfig) ProtoMessage() {}
+
+func (x *FileConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_config_lemming_config_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileConfig.ProtoReflect.Descriptor instead.
+func (*FileConfig) Descriptor() ([]byte, []int) {
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FileConfig) GetRemoteTransfer() bool {
+	if x != nil {
+		return x.RemoteTransfer
+	}
+	return false
+}
+
+func (x *FileConfig) GetRootDir() string {
+	if x != nil {
+		return x.RootDir
+	}
+	return ""
+}
+
+func (x *FileConfig) GetTransferTimeoutMs() uint32 {
+	if x != nil {
+		return x.TransferTimeoutMs
+	}
+	return 0
+}
+
+type ClockConfig struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	OffsetMs            int64                  `protobuf:"varint,1,opt,name=offset_ms,json=offsetMs,proto3" json:"offset_ms,omitempty"`
+	DriftPpm            float64                `protobuf:"fixed64,2,opt,name=drift_ppm,json=driftPpm,proto3" json:"drift_ppm,omitempty"`
+	NtpStratum          uint32                 `protobuf:"varint,3,opt,name=ntp_stratum,json=ntpStratum,proto3" json:"ntp_stratum,omitempty"`
+	NtpPollIntervalSecs uint32                 `protobuf:"varint,4,opt,name=ntp_poll_interval_secs,json=ntpPollIntervalSecs,proto3" json:"ntp_poll_interval_secs,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ClockConfig) Reset() {
+	*x = ClockConfig{}
+	mi := &file_proto_config_lemming_config_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClockConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClockConfig) ProtoMessage() {}
+
+func (x *ClockConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_config_lemming_config_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClockConfig.ProtoReflect.Descriptor instead.
+func (*ClockConfig) Descriptor() ([]byte, []int) {
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ClockConfig) GetOffsetMs() int64 {
+	if x != nil {
+		return x.OffsetMs
+	}
+	return 0
+}
+
+func (x *ClockConfig) GetDriftPpm() float64 {
+	if x != nil {
+		return x.DriftPpm
+	}
+	return 0
+}
+
+func (x *ClockConfig) GetNtpStratum() uint32 {
+	if x != nil {
+		return x.NtpStratum
+	}
+	return 0
+}
+
+func (x *ClockConfig) GetNtpPollIntervalSecs() uint32 {
+	if x != nil {
+		return x.NtpPollIntervalSecs
+	}
+	return 0
+}
+
 type InterfaceConfig struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Interface     []*InterfaceSpec       `protobuf:"bytes,1,rep,name=interface,proto3" json:"interface,omitempty"`
@@ -486,7 +690,7 @@ type InterfaceConfig struct {
 
 func (x *InterfaceConfig) Reset() {
 	*x = InterfaceConfig{}
-	mi := &file_proto_config_lemming_config_proto_msgTypes[6]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -498,7 +702,7 @@ func (x *InterfaceConfig) String() string {
 func (*InterfaceConfig) ProtoMessage() {}
 
 func (x *InterfaceConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_config_lemming_config_proto_msgTypes[6]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -511,7 +715,7 @@ func (x *InterfaceConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InterfaceConfig.ProtoReflect.Descriptor instead.
 func (*InterfaceConfig) Descriptor() ([]byte, []int) {
-	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{6}
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *InterfaceConfig) GetInterface() []*InterfaceSpec {
@@ -532,7 +736,7 @@ type InterfaceSpec struct {
 
 func (x *InterfaceSpec) Reset() {
 	*x = InterfaceSpec{}
-	mi := &file_proto_config_lemming_config_proto_msgTypes[7]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -544,7 +748,7 @@ func (x *InterfaceSpec) String() string {
 func (*InterfaceSpec) ProtoMessage() {}
 
 func (x *InterfaceSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_config_lemming_config_proto_msgTypes[7]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -557,7 +761,7 @@ func (x *InterfaceSpec) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InterfaceSpec.ProtoReflect.Descriptor instead.
 func (*InterfaceSpec) Descriptor() ([]byte, []int) {
-	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{7}
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *InterfaceSpec) GetName() string {
@@ -594,13 +798,15 @@ type LinkQualificationConfig struct {
 	DefaultPacketRate     uint64                 `protobuf:"varint,8,opt,name=default_packet_rate,json=defaultPacketRate,proto3" json:"default_packet_rate,omitempty"`
 	DefaultPacketSize     uint32                 `protobuf:"varint,9,opt,name=default_packet_size,json=defaultPacketSize,proto3" json:"default_packet_size,omitempty"`
 	DefaultTestDurationMs int64                  `protobuf:"varint,10,opt,name=default_test_duration_ms,json=defaultTestDurationMs,proto3" json:"default_test_duration_ms,omitempty"`
+	MaxDataplanePps       uint64                 `protobuf:"varint,12,opt,name=max_dataplane_pps,json=maxDataplanePps,proto3" json:"max_dataplane_pps,omitempty"`
+	MaxInjectedPackets    uint32                 `protobuf:"varint,13,opt,name=max_injected_packets,json=maxInjectedPackets,proto3" json:"max_injected_packets,omitempty"`
 	unknownFields         protoimpl.UnknownFields
 	sizeCache             protoimpl.SizeCache
 }
 
 func (x *LinkQualificationConfig) Reset() {
 	*x = LinkQualificationConfig{}
-	mi := &file_proto_config_lemming_config_proto_msgTypes[8]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -612,7 +818,7 @@ func (x *LinkQualificationConfig) String() string {
 func (*LinkQualificationConfig) ProtoMessage() {}
 
 func (x *LinkQualificationConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_config_lemming_config_proto_msgTypes[8]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -625,7 +831,7 @@ func (x *LinkQualificationConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LinkQualificationConfig.ProtoReflect.Descriptor instead.
 func (*LinkQualificationConfig) Descriptor() ([]byte, []int) {
-	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{8}
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *LinkQualificationConfig) GetMaxBps() uint64 {
@@ -705,6 +911,20 @@ func (x *LinkQualificationConfig) GetDefaultTestDurationMs() int64 {
 	return 0
 }
 
+func (x *LinkQualificationConfig) GetMaxDataplanePps() uint64 {
+	if x != nil {
+		return x.MaxDataplanePps
+	}
+	return 0
+}
+
+func (x *LinkQualificationConfig) GetMaxInjectedPackets() uint32 {
+	if x != nil {
+		return x.MaxInjectedPackets
+	}
+	return 0
+}
+
 type NetworkSimConfig struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	BaseLatencyMs   int64                  `protobuf:"varint,1,opt,name=base_latency_ms,json=baseLatencyMs,proto3" json:"base_latency_ms,omitempty"`
@@ -718,7 +938,7 @@ type NetworkSimConfig struct {
 
 func (x *NetworkSimConfig) Reset() {
 	*x = NetworkSimConfig{}
-	mi := &file_proto_config_lemming_config_proto_msgTypes[9]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -730,7 +950,7 @@ func (x *NetworkSimConfig) String() string {
 func (*NetworkSimConfig) ProtoMessage() {}
 
 func (x *NetworkSimConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_config_lemming_config_proto_msgTypes[9]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -743,7 +963,7 @@ func (x *NetworkSimConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NetworkSimConfig.ProtoReflect.Descriptor instead.
 func (*NetworkSimConfig) Descriptor() ([]byte, []int) {
-	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{9}
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *NetworkSimConfig) GetBaseLatencyMs() int64 {
@@ -781,6 +1001,142 @@ func (x *NetworkSimConfig) GetPacketErrorRate() float32 {
 	return 0
 }
 
+type DiagConfig struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	MinBertDurationSecs uint32                 `protobuf:"varint,1,opt,name=min_bert_duration_secs,json=minBertDurationSecs,proto3" json:"min_bert_duration_secs,omitempty"`
+	MaxBertDurationSecs uint32                 `protobuf:"varint,2,opt,name=max_bert_duration_secs,json=maxBertDurationSecs,proto3" json:"max_bert_duration_secs,omitempty"`
+	DefaultErrorProfile *BertErrorProfile      `protobuf:"bytes,3,opt,name=default_error_profile,json=defaultErrorProfile,proto3" json:"default_error_profile,omitempty"`
+	ErrorProfile        []*BertErrorProfile    `protobuf:"bytes,4,rep,name=error_profile,json=errorProfile,proto3" json:"error_profile,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *DiagConfig) Reset() {
+	*x = DiagConfig{}
+	mi := &file_proto_config_lemming_config_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiagConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiagConfig) ProtoMessage() {}
+
+func (x *DiagConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_config_lemming_config_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiagConfig.ProtoReflect.Descriptor instead.
+func (*DiagConfig) Descriptor() ([]byte, []int) {
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DiagConfig) GetMinBertDurationSecs() uint32 {
+	if x != nil {
+		return x.MinBertDurationSecs
+	}
+	return 0
+}
+
+func (x *DiagConfig) GetMaxBertDurationSecs() uint32 {
+	if x != nil {
+		return x.MaxBertDurationSecs
+	}
+	return 0
+}
+
+func (x *DiagConfig) GetDefaultErrorProfile() *BertErrorProfile {
+	if x != nil {
+		return x.DefaultErrorProfile
+	}
+	return nil
+}
+
+func (x *DiagConfig) GetErrorProfile() []*BertErrorProfile {
+	if x != nil {
+		return x.ErrorProfile
+	}
+	return nil
+}
+
+type BertErrorProfile struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Interface             string                 `protobuf:"bytes,1,opt,name=interface,proto3" json:"interface,omitempty"`
+	ErrorsPerMinute       float64                `protobuf:"fixed64,2,opt,name=errors_per_minute,json=errorsPerMinute,proto3" json:"errors_per_minute,omitempty"`
+	PeerLockFailure       bool                   `protobuf:"varint,3,opt,name=peer_lock_failure,json=peerLockFailure,proto3" json:"peer_lock_failure,omitempty"`
+	PeerLockLostAfterSecs uint32                 `protobuf:"varint,4,opt,name=peer_lock_lost_after_secs,json=peerLockLostAfterSecs,proto3" json:"peer_lock_lost_after_secs,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *BertErrorProfile) Reset() {
+	*x = BertErrorProfile{}
+	mi := &file_proto_config_lemming_config_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BertErrorProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BertErrorProfile) ProtoMessage() {}
+
+func (x *BertErrorProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_config_lemming_config_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BertErrorProfile.ProtoReflect.Descriptor instead.
+func (*BertErrorProfile) Descriptor() ([]byte, []int) {
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *BertErrorProfile) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *BertErrorProfile) GetErrorsPerMinute() float64 {
+	if x != nil {
+		return x.ErrorsPerMinute
+	}
+	return 0
+}
+
+func (x *BertErrorProfile) GetPeerLockFailure() bool {
+	if x != nil {
+		return x.PeerLockFailure
+	}
+	return false
+}
+
+func (x *BertErrorProfile) GetPeerLockLostAfterSecs() uint32 {
+	if x != nil {
+		return x.PeerLockLostAfterSecs
+	}
+	return 0
+}
+
 type VendorConfig struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -792,7 +1148,7 @@ type VendorConfig struct {
 
 func (x *VendorConfig) Reset() {
 	*x = VendorConfig{}
-	mi := &file_proto_config_lemming_config_proto_msgTypes[10]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -804,7 +1160,7 @@ func (x *VendorConfig) String() string {
 func (*VendorConfig) ProtoMessage() {}
 
 func (x *VendorConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_config_lemming_config_proto_msgTypes[10]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -817,7 +1173,7 @@ func (x *VendorConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VendorConfig.ProtoReflect.Descriptor instead.
 func (*VendorConfig) Descriptor() ([]byte, []int) {
-	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{10}
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *VendorConfig) GetName() string {
@@ -851,7 +1207,7 @@ type GNOIFaults struct {
 
 func (x *GNOIFaults) Reset() {
 	*x = GNOIFaults{}
-	mi := &file_proto_config_lemming_config_proto_msgTypes[11]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -863,7 +1219,7 @@ func (x *GNOIFaults) String() string {
 func (*GNOIFaults) ProtoMessage() {}
 
 func (x *GNOIFaults) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_config_lemming_config_proto_msgTypes[11]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -876,7 +1232,7 @@ func (x *GNOIFaults) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GNOIFaults.ProtoReflect.Descriptor instead.
 func (*GNOIFaults) Descriptor() ([]byte, []int) {
-	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{11}
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *GNOIFaults) GetRpcMethod() string {
@@ -902,7 +1258,7 @@ type FaultServiceConfiguration struct {
 
 func (x *FaultServiceConfiguration) Reset() {
 	*x = FaultServiceConfiguration{}
-	mi := &file_proto_config_lemming_config_proto_msgTypes[12]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -914,7 +1270,7 @@ func (x *FaultServiceConfiguration) String() string {
 func (*FaultServiceConfiguration) ProtoMessage() {}
 
 func (x *FaultServiceConfiguration) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_config_lemming_config_proto_msgTypes[12]
+	mi := &file_proto_config_lemming_config_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -927,7 +1283,7 @@ func (x *FaultServiceConfiguration) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FaultServiceConfiguration.ProtoReflect.Descriptor instead.
 func (*FaultServiceConfiguration) Descriptor() ([]byte, []int) {
-	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{12}
+	return file_proto_config_lemming_config_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *FaultServiceConfiguration) GetGnoiFaults() []*GNOIFaults {
@@ -944,7 +1300,7 @@ var file_proto_config_lemming_config_proto_rawDesc = []byte{
 	0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e,
 	0x66, 0x69, 0x67, 0x1a, 0x17, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x66, 0x61, 0x75, 0x6c, 0x74,
-	0x2f, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xac, 0x04, 0x0a,
+	0x2f, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xf5, 0x05, 0x0a,
 	0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x3f, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x6f,
 	0x6e, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6c, 0x65,
 	0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x43, 0x6f, 0x6d,
@@ -979,147 +1335,216 @@ var file_proto_config_lemming_config_proto_rawDesc = []byte{
 	0x01, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f,
 	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x46, 0x61, 0x75, 0x6c, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
 	0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b,
-	0x66, 0x61, 0x75, 0x6c, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x81, 0x01, 0x0a, 0x0f,
-	0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12,
-	0x37, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x1d, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x2e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52,
-	0x07, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x12, 0x35, 0x0a, 0x17, 0x64, 0x65, 0x66, 0x61,
-	0x75, 0x6c, 0x74, 0x5f, 0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x6f, 0x6e, 0x5f, 0x6b,
-	0x69, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x64, 0x65, 0x66, 0x61, 0x75,
-	0x6c, 0x74, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x4f, 0x6e, 0x4b, 0x69, 0x6c, 0x6c, 0x22,
-	0xd6, 0x02, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e,
-	0x66, 0x69, 0x67, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f,
-	0x72, 0x31, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x73,
-	0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x31, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x29,
-	0x0a, 0x10, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x32, 0x5f, 0x6e, 0x61,
-	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76,
-	0x69, 0x73, 0x6f, 0x72, 0x32, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x68, 0x61,
-	0x73, 0x73, 0x69, 0x73, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0b, 0x63, 0x68, 0x61, 0x73, 0x73, 0x69, 0x73, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0f,
-	0x6c, 0x69, 0x6e, 0x65, 0x63, 0x61, 0x72, 0x64, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x6c, 0x69, 0x6e, 0x65, 0x63, 0x61, 0x72, 0x64, 0x50,
-	0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x23, 0x0a, 0x0d, 0x66, 0x61, 0x62, 0x72, 0x69, 0x63, 0x5f,
-	0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x66, 0x61,
-	0x62, 0x72, 0x69, 0x63, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x3f, 0x0a, 0x08, 0x6c, 0x69,
-	0x6e, 0x65, 0x63, 0x61, 0x72, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x6c,
-	0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x43, 0x6f,
-	0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x52, 0x08, 0x6c, 0x69, 0x6e, 0x65, 0x63, 0x61, 0x72, 0x64, 0x12, 0x3b, 0x0a, 0x06, 0x66,
-	0x61, 0x62, 0x72, 0x69, 0x63, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x6c, 0x65,
-	0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x43, 0x6f, 0x6d,
-	0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x52, 0x06, 0x66, 0x61, 0x62, 0x72, 0x69, 0x63, 0x22, 0x60, 0x0a, 0x13, 0x43, 0x6f, 0x6d, 0x70,
-	0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12,
-	0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
-	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x69,
-	0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x72,
-	0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x74, 0x65, 0x70, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x74, 0x65, 0x70, 0x22, 0x80, 0x02, 0x0a, 0x0d, 0x50,
-	0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04,
-	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
-	0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x70,
-	0x69, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x63, 0x70, 0x75, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x5f,
-	0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x63, 0x70, 0x75, 0x55,
-	0x73, 0x61, 0x67, 0x65, 0x55, 0x73, 0x65, 0x72, 0x12, 0x28, 0x0a, 0x10, 0x63, 0x70, 0x75, 0x5f,
-	0x75, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x04, 0x52, 0x0e, 0x63, 0x70, 0x75, 0x55, 0x73, 0x61, 0x67, 0x65, 0x53, 0x79, 0x73, 0x74,
-	0x65, 0x6d, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x70, 0x75, 0x5f, 0x75, 0x74, 0x69, 0x6c, 0x69, 0x7a,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x63, 0x70, 0x75,
-	0x55, 0x74, 0x69, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x6d,
-	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2d,
-	0x0a, 0x12, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x75, 0x74, 0x69, 0x6c, 0x69, 0x7a, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11, 0x6d, 0x65, 0x6d, 0x6f,
-	0x72, 0x79, 0x55, 0x74, 0x69, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xab, 0x01,
-	0x0a, 0x0c, 0x54, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x34,
-	0x0a, 0x16, 0x73, 0x77, 0x69, 0x74, 0x63, 0x68, 0x6f, 0x76, 0x65, 0x72, 0x5f, 0x64, 0x75, 0x72,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x14,
-	0x73, 0x77, 0x69, 0x74, 0x63, 0x68, 0x6f, 0x76, 0x65, 0x72, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x72, 0x65, 0x62, 0x6f, 0x6f, 0x74, 0x5f, 0x64,
-	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
-	0x52, 0x10, 0x72, 0x65, 0x62, 0x6f, 0x6f, 0x74, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x4d, 0x73, 0x12, 0x37, 0x0a, 0x18, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x72, 0x65,
-	0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x64, 0x65, 0x6c, 0x61, 0x79, 0x5f, 0x6d, 0x73, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x15, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73,
-	0x74, 0x61, 0x72, 0x74, 0x44, 0x65, 0x6c, 0x61, 0x79, 0x4d, 0x73, 0x22, 0x4e, 0x0a, 0x0f, 0x49,
-	0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x3b,
-	0x0a, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x1d, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x2e, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x53, 0x70, 0x65, 0x63,
-	0x52, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x22, 0x60, 0x0a, 0x0d, 0x49,
-	0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12, 0x12, 0x0a, 0x04,
-	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
-	0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69,
-	0x6f, 0x6e, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x66, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x69, 0x66, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x22, 0xed, 0x03,
-	0x0a, 0x17, 0x4c, 0x69, 0x6e, 0x6b, 0x51, 0x75, 0x61, 0x6c, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78,
-	0x5f, 0x62, 0x70, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x42,
-	0x70, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x70, 0x73, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x50, 0x70, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x6d,
-	0x69, 0x6e, 0x5f, 0x6d, 0x74, 0x75, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6d, 0x69,
-	0x6e, 0x4d, 0x74, 0x75, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x6d, 0x74, 0x75, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x4d, 0x74, 0x75, 0x12, 0x34, 0x0a,
-	0x16, 0x6d, 0x61, 0x78, 0x5f, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x63, 0x61, 0x6c, 0x5f,
-	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x6d,
-	0x61, 0x78, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x63, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x75,
-	0x6c, 0x74, 0x73, 0x12, 0x31, 0x0a, 0x15, 0x6d, 0x69, 0x6e, 0x5f, 0x73, 0x65, 0x74, 0x75, 0x70,
-	0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x12, 0x6d, 0x69, 0x6e, 0x53, 0x65, 0x74, 0x75, 0x70, 0x44, 0x75, 0x72, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x37, 0x0a, 0x18, 0x6d, 0x69, 0x6e, 0x5f, 0x74, 0x65,
-	0x61, 0x72, 0x64, 0x6f, 0x77, 0x6e, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
-	0x6d, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x15, 0x6d, 0x69, 0x6e, 0x54, 0x65, 0x61,
-	0x72, 0x64, 0x6f, 0x77, 0x6e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12,
-	0x33, 0x0a, 0x16, 0x6d, 0x69, 0x6e, 0x5f, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x69, 0x6e,
-	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x13, 0x6d, 0x69, 0x6e, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76,
-	0x61, 0x6c, 0x4d, 0x73, 0x12, 0x2e, 0x0a, 0x13, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f,
-	0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x11, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74,
-	0x52, 0x61, 0x74, 0x65, 0x12, 0x2e, 0x0a, 0x13, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f,
-	0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x11, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74,
-	0x53, 0x69, 0x7a, 0x65, 0x12, 0x37, 0x0a, 0x18, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f,
-	0x74, 0x65, 0x73, 0x74, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73,
-	0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x15, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x54,
-	0x65, 0x73, 0x74, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x22, 0xdd, 0x01,
-	0x0a, 0x10, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x53, 0x69, 0x6d, 0x43, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x12, 0x26, 0x0a, 0x0f, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x6c, 0x61, 0x74, 0x65, 0x6e,
-	0x63, 0x79, 0x5f, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x62, 0x61, 0x73,
-	0x65, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4d, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x6c, 0x61,
-	0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x5f, 0x6d, 0x73, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4a, 0x69,
-	0x74, 0x74, 0x65, 0x72, 0x4d, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74,
-	0x5f, 0x6c, 0x6f, 0x73, 0x73, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02,
-	0x52, 0x0e, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x4c, 0x6f, 0x73, 0x73, 0x52, 0x61, 0x74, 0x65,
-	0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x74, 0x74, 0x6c, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x54, 0x74,
-	0x6c, 0x12, 0x2a, 0x0a, 0x11, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x65, 0x72, 0x72, 0x6f,
-	0x72, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0f, 0x70, 0x61,
-	0x63, 0x6b, 0x65, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x61, 0x74, 0x65, 0x22, 0x57, 0x0a,
-	0x0c, 0x56, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x12, 0x0a,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x6f, 0x73, 0x5f, 0x76, 0x65,
-	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x73, 0x56,
-	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x60, 0x0a, 0x0a, 0x47, 0x4e, 0x4f, 0x49, 0x46, 0x61,
-	0x75, 0x6c, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x70, 0x63, 0x5f, 0x6d, 0x65, 0x74, 0x68,
-	0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x70, 0x63, 0x4d, 0x65, 0x74,
-	0x68, 0x6f, 0x64, 0x12, 0x33, 0x0a, 0x06, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x66, 0x61,
-	0x75, 0x6c, 0x74, 0x2e, 0x46, 0x61, 0x75, 0x6c, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x52, 0x06, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x22, 0x58, 0x0a, 0x19, 0x46, 0x61, 0x75, 0x6c,
-	0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x0b, 0x67, 0x6e, 0x6f, 0x69, 0x5f, 0x66, 0x61,
-	0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6c, 0x65, 0x6d,
-	0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x47, 0x4e, 0x4f, 0x49,
-	0x46, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x52, 0x0a, 0x67, 0x6e, 0x6f, 0x69, 0x46, 0x61, 0x75, 0x6c,
-	0x74, 0x73, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
-	0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2f, 0x6c, 0x65, 0x6d, 0x6d,
-	0x69, 0x6e, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x66, 0x61, 0x75, 0x6c, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x2e, 0x0a, 0x04, 0x64,
+	0x69, 0x61, 0x67, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6c, 0x65, 0x6d, 0x6d,
+	0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x44, 0x69, 0x61, 0x67, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x04, 0x64, 0x69, 0x61, 0x67, 0x12, 0x34, 0x0a, 0x06, 0x72,
+	0x65, 0x62, 0x6f, 0x6f, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6c, 0x65,
+	0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x52, 0x65, 0x62,
+	0x6f, 0x6f, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x72, 0x65, 0x62, 0x6f, 0x6f,
+	0x74, 0x12, 0x2e, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x46, 0x69, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x04, 0x66, 0x69, 0x6c,
+	0x65, 0x12, 0x31, 0x0a, 0x05, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x05, 0x63,
+	0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x81, 0x01, 0x0a, 0x0f, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73,
+	0x65, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x37, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x63,
+	0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x6c, 0x65, 0x6d, 0x6d,
+	0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x50, 0x72, 0x6f, 0x63, 0x65,
+	0x73, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73,
+	0x73, 0x12, 0x35, 0x0a, 0x17, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x72, 0x65, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x5f, 0x6f, 0x6e, 0x5f, 0x6b, 0x69, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x14, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x4f, 0x6e, 0x4b, 0x69, 0x6c, 0x6c, 0x22, 0xd6, 0x02, 0x0a, 0x0f, 0x43, 0x6f, 0x6d,
+	0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x29, 0x0a, 0x10,
+	0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x31, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73,
+	0x6f, 0x72, 0x31, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x75, 0x70, 0x65, 0x72,
+	0x76, 0x69, 0x73, 0x6f, 0x72, 0x32, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0f, 0x73, 0x75, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x32, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x68, 0x61, 0x73, 0x73, 0x69, 0x73, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x73, 0x73, 0x69,
+	0x73, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x6c, 0x69, 0x6e, 0x65, 0x63, 0x61, 0x72,
+	0x64, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x6c, 0x69, 0x6e, 0x65, 0x63, 0x61, 0x72, 0x64, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x23,
+	0x0a, 0x0d, 0x66, 0x61, 0x62, 0x72, 0x69, 0x63, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x66, 0x61, 0x62, 0x72, 0x69, 0x63, 0x50, 0x72, 0x65,
+	0x66, 0x69, 0x78, 0x12, 0x3f, 0x0a, 0x08, 0x6c, 0x69, 0x6e, 0x65, 0x63, 0x61, 0x72, 0x64, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x08, 0x6c, 0x69, 0x6e, 0x65,
+	0x63, 0x61, 0x72, 0x64, 0x12, 0x3b, 0x0a, 0x06, 0x66, 0x61, 0x62, 0x72, 0x69, 0x63, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x54,
+	0x79, 0x70, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x66, 0x61, 0x62, 0x72, 0x69,
+	0x63, 0x22, 0x60, 0x0a, 0x13, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x54, 0x79,
+	0x70, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1f,
+	0x0a, 0x0b, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x74, 0x65, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73,
+	0x74, 0x65, 0x70, 0x22, 0x80, 0x02, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x70, 0x69, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x63,
+	0x70, 0x75, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0c, 0x63, 0x70, 0x75, 0x55, 0x73, 0x61, 0x67, 0x65, 0x55, 0x73, 0x65,
+	0x72, 0x12, 0x28, 0x0a, 0x10, 0x63, 0x70, 0x75, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x73,
+	0x79, 0x73, 0x74, 0x65, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x63, 0x70, 0x75,
+	0x55, 0x73, 0x61, 0x67, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x12, 0x27, 0x0a, 0x0f, 0x63,
+	0x70, 0x75, 0x5f, 0x75, 0x74, 0x69, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x63, 0x70, 0x75, 0x55, 0x74, 0x69, 0x6c, 0x69, 0x7a, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x75,
+	0x73, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f,
+	0x72, 0x79, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2d, 0x0a, 0x12, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x5f, 0x75, 0x74, 0x69, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x11, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x55, 0x74, 0x69, 0x6c, 0x69,
+	0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xab, 0x01, 0x0a, 0x0c, 0x54, 0x69, 0x6d, 0x69, 0x6e,
+	0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x34, 0x0a, 0x16, 0x73, 0x77, 0x69, 0x74, 0x63,
+	0x68, 0x6f, 0x76, 0x65, 0x72, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x14, 0x73, 0x77, 0x69, 0x74, 0x63, 0x68, 0x6f,
+	0x76, 0x65, 0x72, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x2c, 0x0a,
+	0x12, 0x72, 0x65, 0x62, 0x6f, 0x6f, 0x74, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10, 0x72, 0x65, 0x62, 0x6f, 0x6f,
+	0x74, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x37, 0x0a, 0x18, 0x70,
+	0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x64,
+	0x65, 0x6c, 0x61, 0x79, 0x5f, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x15, 0x70,
+	0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x44, 0x65, 0x6c,
+	0x61, 0x79, 0x4d, 0x73, 0x22, 0x2f, 0x0a, 0x0c, 0x52, 0x65, 0x62, 0x6f, 0x6f, 0x74, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x65, 0x74, 0x5f, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x65, 0x74,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x22, 0x80, 0x01, 0x0a, 0x0a, 0x46, 0x69, 0x6c, 0x65, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x12, 0x19, 0x0a,
+	0x08, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x72, 0x6f, 0x6f, 0x74, 0x44, 0x69, 0x72, 0x12, 0x2e, 0x0a, 0x13, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x54,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x22, 0x9d, 0x01, 0x0a, 0x0b, 0x43, 0x6c, 0x6f,
+	0x63, 0x6b, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x1b, 0x0a, 0x09, 0x6f, 0x66, 0x66, 0x73,
+	0x65, 0x74, 0x5f, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6f, 0x66, 0x66,
+	0x73, 0x65, 0x74, 0x4d, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x72, 0x69, 0x66, 0x74, 0x5f, 0x70,
+	0x70, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x72, 0x69, 0x66, 0x74, 0x50,
+	0x70, 0x6d, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x74, 0x70, 0x5f, 0x73, 0x74, 0x72, 0x61, 0x74, 0x75,
+	0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6e, 0x74, 0x70, 0x53, 0x74, 0x72, 0x61,
+	0x74, 0x75, 0x6d, 0x12, 0x33, 0x0a, 0x16, 0x6e, 0x74, 0x70, 0x5f, 0x70, 0x6f, 0x6c, 0x6c, 0x5f,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x63, 0x73, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x13, 0x6e, 0x74, 0x70, 0x50, 0x6f, 0x6c, 0x6c, 0x49, 0x6e, 0x74, 0x65,
+	0x72, 0x76, 0x61, 0x6c, 0x53, 0x65, 0x63, 0x73, 0x22, 0x4e, 0x0a, 0x0f, 0x49, 0x6e, 0x74, 0x65,
+	0x72, 0x66, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x3b, 0x0a, 0x09, 0x69,
+	0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d,
+	0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e,
+	0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x53, 0x70, 0x65, 0x63, 0x52, 0x09, 0x69,
+	0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x22, 0x60, 0x0a, 0x0d, 0x49, 0x6e, 0x74, 0x65,
+	0x72, 0x66, 0x61, 0x63, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a,
+	0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x19, 0x0a, 0x08, 0x69, 0x66, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x07, 0x69, 0x66, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x22, 0xcb, 0x04, 0x0a, 0x17, 0x4c,
+	0x69, 0x6e, 0x6b, 0x51, 0x75, 0x61, 0x6c, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x62, 0x70,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x42, 0x70, 0x73, 0x12,
+	0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x70, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x06, 0x6d, 0x61, 0x78, 0x50, 0x70, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x69, 0x6e, 0x5f,
+	0x6d, 0x74, 0x75, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x4d, 0x74,
+	0x75, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x6d, 0x74, 0x75, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x4d, 0x74, 0x75, 0x12, 0x34, 0x0a, 0x16, 0x6d, 0x61,
+	0x78, 0x5f, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x63, 0x61, 0x6c, 0x5f, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x6d, 0x61, 0x78, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x63, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73,
+	0x12, 0x31, 0x0a, 0x15, 0x6d, 0x69, 0x6e, 0x5f, 0x73, 0x65, 0x74, 0x75, 0x70, 0x5f, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x12, 0x6d, 0x69, 0x6e, 0x53, 0x65, 0x74, 0x75, 0x70, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x4d, 0x73, 0x12, 0x37, 0x0a, 0x18, 0x6d, 0x69, 0x6e, 0x5f, 0x74, 0x65, 0x61, 0x72, 0x64,
+	0x6f, 0x77, 0x6e, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x15, 0x6d, 0x69, 0x6e, 0x54, 0x65, 0x61, 0x72, 0x64, 0x6f,
+	0x77, 0x6e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x33, 0x0a, 0x16,
+	0x6d, 0x69, 0x6e, 0x5f, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x76, 0x61, 0x6c, 0x5f, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x6d, 0x69,
+	0x6e, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x4d,
+	0x73, 0x12, 0x2e, 0x0a, 0x13, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x70, 0x61, 0x63,
+	0x6b, 0x65, 0x74, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11,
+	0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x61, 0x74,
+	0x65, 0x12, 0x2e, 0x0a, 0x13, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x70, 0x61, 0x63,
+	0x6b, 0x65, 0x74, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11,
+	0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x53, 0x69, 0x7a,
+	0x65, 0x12, 0x37, 0x0a, 0x18, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x74, 0x65, 0x73,
+	0x74, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x15, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x54, 0x65, 0x73, 0x74,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x6d, 0x61,
+	0x78, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x5f, 0x70, 0x70, 0x73, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x6d, 0x61, 0x78, 0x44, 0x61, 0x74, 0x61, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x50, 0x70, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x6d, 0x61, 0x78, 0x5f, 0x69, 0x6e,
+	0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x12, 0x6d, 0x61, 0x78, 0x49, 0x6e, 0x6a, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x22, 0xdd, 0x01, 0x0a, 0x10, 0x4e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x53, 0x69, 0x6d, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x26, 0x0a,
+	0x0f, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6d, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x62, 0x61, 0x73, 0x65, 0x4c, 0x61, 0x74, 0x65,
+	0x6e, 0x63, 0x79, 0x4d, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79,
+	0x5f, 0x6a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0f, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x4d,
+	0x73, 0x12, 0x28, 0x0a, 0x10, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x73, 0x73,
+	0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0e, 0x70, 0x61, 0x63,
+	0x6b, 0x65, 0x74, 0x4c, 0x6f, 0x73, 0x73, 0x52, 0x61, 0x74, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x64,
+	0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x74, 0x74, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0a, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x54, 0x74, 0x6c, 0x12, 0x2a, 0x0a, 0x11,
+	0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x72, 0x61, 0x74,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0f, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x52, 0x61, 0x74, 0x65, 0x22, 0x93, 0x02, 0x0a, 0x0a, 0x44, 0x69, 0x61,
+	0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x33, 0x0a, 0x16, 0x6d, 0x69, 0x6e, 0x5f, 0x62,
+	0x65, 0x72, 0x74, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x65, 0x63,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x13, 0x6d, 0x69, 0x6e, 0x42, 0x65, 0x72, 0x74,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x63, 0x73, 0x12, 0x33, 0x0a, 0x16,
+	0x6d, 0x61, 0x78, 0x5f, 0x62, 0x65, 0x72, 0x74, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x73, 0x65, 0x63, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x13, 0x6d, 0x61,
+	0x78, 0x42, 0x65, 0x72, 0x74, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x63,
+	0x73, 0x12, 0x54, 0x0a, 0x15, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x20, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x42, 0x65, 0x72, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x50, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x52, 0x13, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x45, 0x0a, 0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20,
+	0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e,
+	0x42, 0x65, 0x72, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x22, 0xc2,
+	0x01, 0x0a, 0x10, 0x42, 0x65, 0x72, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63,
+	0x65, 0x12, 0x2a, 0x0a, 0x11, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f,
+	0x6d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x73, 0x50, 0x65, 0x72, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x12, 0x2a, 0x0a,
+	0x11, 0x70, 0x65, 0x65, 0x72, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x66, 0x61, 0x69, 0x6c, 0x75,
+	0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x70, 0x65, 0x65, 0x72, 0x4c, 0x6f,
+	0x63, 0x6b, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x12, 0x38, 0x0a, 0x19, 0x70, 0x65, 0x65,
+	0x72, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6c, 0x6f, 0x73, 0x74, 0x5f, 0x61, 0x66, 0x74, 0x65,
+	0x72, 0x5f, 0x73, 0x65, 0x63, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x15, 0x70, 0x65,
+	0x65, 0x72, 0x4c, 0x6f, 0x63, 0x6b, 0x4c, 0x6f, 0x73, 0x74, 0x41, 0x66, 0x74, 0x65, 0x72, 0x53,
+	0x65, 0x63, 0x73, 0x22, 0x57, 0x0a, 0x0c, 0x56, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x1d, 0x0a,
+	0x0a, 0x6f, 0x73, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x6f, 0x73, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x60, 0x0a, 0x0a,
+	0x47, 0x4e, 0x4f, 0x49, 0x46, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x70,
+	0x63, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x72, 0x70, 0x63, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x33, 0x0a, 0x06, 0x66, 0x61, 0x75,
+	0x6c, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6c, 0x65, 0x6d, 0x6d,
+	0x69, 0x6e, 0x67, 0x2e, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x2e, 0x46, 0x61, 0x75, 0x6c, 0x74, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x06, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x22, 0x58,
+	0x0a, 0x19, 0x46, 0x61, 0x75, 0x6c, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x0b, 0x67,
+	0x6e, 0x6f, 0x69, 0x5f, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x47, 0x4e, 0x4f, 0x49, 0x46, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x52, 0x0a, 0x67, 0x6e,
+	0x6f, 0x69, 0x46, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2f, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1134,7 +1559,7 @@ func file_proto_config_lemming_config_proto_rawDescGZIP() []byte {
 	return file_proto_config_lemming_config_proto_rawDescData
 }
 
-var file_proto_config_lemming_config_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_proto_config_lemming_config_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_proto_config_lemming_config_proto_goTypes = []any{
 	(*Config)(nil),                    // 0: lemming.config.Config
 	(*ProcessesConfig)(nil),           // 1: lemming.config.ProcessesConfig
@@ -1142,35 +1567,46 @@ var file_proto_config_lemming_config_proto_goTypes = []any{
 	(*ComponentTypeConfig)(nil),       // 3: lemming.config.ComponentTypeConfig
 	(*ProcessConfig)(nil),             // 4: lemming.config.ProcessConfig
 	(*TimingConfig)(nil),              // 5: lemming.config.TimingConfig
-	(*InterfaceConfig)(nil),           // 6: lemming.config.InterfaceConfig
-	(*InterfaceSpec)(nil),             // 7: lemming.config.InterfaceSpec
-	(*LinkQualificationConfig)(nil),   // 8: lemming.config.LinkQualificationConfig
-	(*NetworkSimConfig)(nil),          // 9: lemming.config.NetworkSimConfig
-	(*VendorConfig)(nil),              // 10: lemming.config.VendorConfig
-	(*GNOIFaults)(nil),                // 11: lemming.config.GNOIFaults
-	(*FaultServiceConfiguration)(nil), // 12: lemming.config.FaultServiceConfiguration
-	(*fault.FaultMessage)(nil),        // 13: lemming.fault.FaultMessage
+	(*RebootConfig)(nil),              // 6: lemming.config.RebootConfig
+	(*FileConfig)(nil),                // 7: lemming.config.FileConfig
+	(*ClockConfig)(nil),               // 8: lemming.config.ClockConfig
+	(*InterfaceConfig)(nil),           // 9: lemming.config.InterfaceConfig
+	(*InterfaceSpec)(nil),             // 10: lemming.config.InterfaceSpec
+	(*LinkQualificationConfig)(nil),   // 11: lemming.config.LinkQualificationConfig
+	(*NetworkSimConfig)(nil),          // 12: lemming.config.NetworkSimConfig
+	(*DiagConfig)(nil),                // 13: lemming.config.DiagConfig
+	(*BertErrorProfile)(nil),          // 14: lemming.config.BertErrorProfile
+	(*VendorConfig)(nil),              // 15: lemming.config.VendorConfig
+	(*GNOIFaults)(nil),                // 16: lemming.config.GNOIFaults
+	(*FaultServiceConfiguration)(nil), // 17: lemming.config.FaultServiceConfiguration
+	(*fault.FaultMessage)(nil),        // 18: lemming.fault.FaultMessage
 }
 var file_proto_config_lemming_config_proto_depIdxs = []int32{
 	2,  // 0: lemming.config.Config.components:type_name -> lemming.config.ComponentConfig
 	1,  // 1: lemming.config.Config.processes:type_name -> lemming.config.ProcessesConfig
 	5,  // 2: lemming.config.Config.timing:type_name -> lemming.config.TimingConfig
-	9,  // 3: lemming.config.Config.network_simulation:type_name -> lemming.config.NetworkSimConfig
-	10, // 4: lemming.config.Config.vendor:type_name -> lemming.config.VendorConfig
-	6,  // 5: lemming.config.Config.interfaces:type_name -> lemming.config.InterfaceConfig
-	8,  // 6: lemming.config.Config.link_qualification:type_name -> lemming.config.LinkQualificationConfig
-	12, // 7: lemming.config.Config.fault_config:type_name -> lemming.config.FaultServiceConfiguration
-	4,  // 8: lemming.config.ProcessesConfig.process:type_name -> lemming.config.ProcessConfig
-	3,  // 9: lemming.config.ComponentConfig.linecard:type_name -> lemming.config.ComponentTypeConfig
-	3,  // 10: lemming.config.ComponentConfig.fabric:type_name -> lemming.config.ComponentTypeConfig
-	7,  // 11: lemming.config.InterfaceConfig.interface:type_name -> lemming.config.InterfaceSpec
-	13, // 12: lemming.config.GNOIFaults.faults:type_name -> lemming.fault.FaultMessage
-	11, // 13: lemming.config.FaultServiceConfiguration.gnoi_faults:type_name -> lemming.config.GNOIFaults
-	14, // [14:14] is the sub-list for method output_type
-	14, // [14:14] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	12, // 3: lemming.config.Config.network_simulation:type_name -> lemming.config.NetworkSimConfig
+	15, // 4: lemming.config.Config.vendor:type_name -> lemming.config.VendorConfig
+	9,  // 5: lemming.config.Config.interfaces:type_name -> lemming.config.InterfaceConfig
+	11, // 6: lemming.config.Config.link_qualification:type_name -> lemming.config.LinkQualificationConfig
+	17, // 7: lemming.config.Config.fault_config:type_name -> lemming.config.FaultServiceConfiguration
+	13, // 8: lemming.config.Config.diag:type_name -> lemming.config.DiagConfig
+	6,  // 9: lemming.config.Config.reboot:type_name -> lemming.config.RebootConfig
+	7,  // 10: lemming.config.Config.file:type_name -> lemming.config.FileConfig
+	8,  // 11: lemming.config.Config.clock:type_name -> lemming.config.ClockConfig
+	4,  // 12: lemming.config.ProcessesConfig.process:type_name -> lemming.config.ProcessConfig
+	3,  // 13: lemming.config.ComponentConfig.linecard:type_name -> lemming.config.ComponentTypeConfig
+	3,  // 14: lemming.config.ComponentConfig.fabric:type_name -> lemming.config.ComponentTypeConfig
+	10, // 15: lemming.config.InterfaceConfig.interface:type_name -> lemming.config.InterfaceSpec
+	14, // 16: lemming.config.DiagConfig.default_error_profile:type_name -> lemming.config.BertErrorProfile
+	14, // 17: lemming.config.DiagConfig.error_profile:type_name -> lemming.config.BertErrorProfile
+	18, // 18: lemming.config.GNOIFaults.faults:type_name -> lemming.fault.FaultMessage
+	16, // 19: lemming.config.FaultServiceConfiguration.gnoi_faults:type_name -> lemming.config.GNOIFaults
+	20, // [20:20] is the sub-list for method output_type
+	20, // [20:20] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_proto_config_lemming_config_proto_init() }
@@ -1184,7 +1620,7 @@ func file_proto_config_lemming_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_config_lemming_config_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   18,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
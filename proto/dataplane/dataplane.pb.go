@@ -250,9 +250,11 @@ type NextHop struct {
 	//
 	//	*NextHop_Gue
 	//	*NextHop_Headers
-	Encap         isNextHop_Encap `protobuf_oneof:"encap"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Encap              isNextHop_Encap `protobuf_oneof:"encap"`
+	AftIndex           uint64          `protobuf:"varint,6,opt,name=aft_index,json=aftIndex,proto3" json:"aft_index,omitempty"`
+	AftNetworkInstance string          `protobuf:"bytes,7,opt,name=aft_network_instance,json=aftNetworkInstance,proto3" json:"aft_network_instance,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *NextHop) Reset() {
@@ -324,6 +326,20 @@ func (x *NextHop) GetHeaders() *routing.Headers {
 	return nil
 }
 
+func (x *NextHop) GetAftIndex() uint64 {
+	if x != nil {
+		return x.AftIndex
+	}
+	return 0
+}
+
+func (x *NextHop) GetAftNetworkInstance() string {
+	if x != nil {
+		return x.AftNetworkInstance
+	}
+	return ""
+}
+
 type isNextHop_Encap interface {
 	isNextHop_Encap()
 }
@@ -341,11 +357,15 @@ func (*NextHop_Gue) isNextHop_Encap() {}
 func (*NextHop_Headers) isNextHop_Encap() {}
 
 type NextHopList struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Hops          []*NextHop             `protobuf:"bytes,1,rep,name=hops,proto3" json:"hops,omitempty"`
-	Weights       []uint64               `protobuf:"varint,2,rep,packed,name=weights,proto3" json:"weights,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Hops                 []*NextHop             `protobuf:"bytes,1,rep,name=hops,proto3" json:"hops,omitempty"`
+	Weights              []uint64               `protobuf:"varint,2,rep,packed,name=weights,proto3" json:"weights,omitempty"`
+	BackupHops           []*NextHop             `protobuf:"bytes,3,rep,name=backup_hops,json=backupHops,proto3" json:"backup_hops,omitempty"`
+	BackupWeights        []uint64               `protobuf:"varint,4,rep,packed,name=backup_weights,json=backupWeights,proto3" json:"backup_weights,omitempty"`
+	GroupId              uint64                 `protobuf:"varint,5,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	GroupNetworkInstance string                 `protobuf:"bytes,6,opt,name=group_network_instance,json=groupNetworkInstance,proto3" json:"group_network_instance,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *NextHopList) Reset() {
@@ -392,6 +412,34 @@ func (x *NextHopList) GetWeights() []uint64 {
 	return nil
 }
 
+func (x *NextHopList) GetBackupHops() []*NextHop {
+	if x != nil {
+		return x.BackupHops
+	}
+	return nil
+}
+
+func (x *NextHopList) GetBackupWeights() []uint64 {
+	if x != nil {
+		return x.BackupWeights
+	}
+	return nil
+}
+
+func (x *NextHopList) GetGroupId() uint64 {
+	if x != nil {
+		return x.GroupId
+	}
+	return 0
+}
+
+func (x *NextHopList) GetGroupNetworkInstance() string {
+	if x != nil {
+		return x.GroupNetworkInstance
+	}
+	return ""
+}
+
 type RoutePrefix struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	Cidr            string                 `protobuf:"bytes,1,opt,name=cidr,proto3" json:"cidr,omitempty"`
@@ -444,6 +492,58 @@ func (x *RoutePrefix) GetNetworkInstance() string {
 	return ""
 }
 
+type DecapLookup struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Header          routing.HeaderType     `protobuf:"varint,1,opt,name=header,proto3,enum=routing.HeaderType" json:"header,omitempty"`
+	NetworkInstance string                 `protobuf:"bytes,2,opt,name=network_instance,json=networkInstance,proto3" json:"network_instance,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DecapLookup) Reset() {
+	*x = DecapLookup{}
+	mi := &file_proto_dataplane_dataplane_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecapLookup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecapLookup) ProtoMessage() {}
+
+func (x *DecapLookup) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dataplane_dataplane_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecapLookup.ProtoReflect.Descriptor instead.
+func (*DecapLookup) Descriptor() ([]byte, []int) {
+	return file_proto_dataplane_dataplane_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DecapLookup) GetHeader() routing.HeaderType {
+	if x != nil {
+		return x.Header
+	}
+	return routing.HeaderType(0)
+}
+
+func (x *DecapLookup) GetNetworkInstance() string {
+	if x != nil {
+		return x.NetworkInstance
+	}
+	return ""
+}
+
 type Route struct {
 	state  protoimpl.MessageState `protogen:"open.v1"`
 	Prefix *RoutePrefix           `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
@@ -452,6 +552,7 @@ type Route struct {
 	//
 	//	*Route_NextHops
 	//	*Route_Interface
+	//	*Route_DecapLookup
 	Hop           isRoute_Hop `protobuf_oneof:"hop"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -459,7 +560,7 @@ type Route struct {
 
 func (x *Route) Reset() {
 	*x = Route{}
-	mi := &file_proto_dataplane_dataplane_proto_msgTypes[5]
+	mi := &file_proto_dataplane_dataplane_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -471,7 +572,7 @@ func (x *Route) String() string {
 func (*Route) ProtoMessage() {}
 
 func (x *Route) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_dataplane_dataplane_proto_msgTypes[5]
+	mi := &file_proto_dataplane_dataplane_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -484,7 +585,7 @@ func (x *Route) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Route.ProtoReflect.Descriptor instead.
 func (*Route) Descriptor() ([]byte, []int) {
-	return file_proto_dataplane_dataplane_proto_rawDescGZIP(), []int{5}
+	return file_proto_dataplane_dataplane_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Route) GetPrefix() *RoutePrefix {
@@ -526,6 +627,15 @@ func (x *Route) GetInterface() *OCInterface {
 	return nil
 }
 
+func (x *Route) GetDecapLookup() *DecapLookup {
+	if x != nil {
+		if x, ok := x.Hop.(*Route_DecapLookup); ok {
+			return x.DecapLookup
+		}
+	}
+	return nil
+}
+
 type isRoute_Hop interface {
 	isRoute_Hop()
 }
@@ -538,10 +648,84 @@ type Route_Interface struct {
 	Interface *OCInterface `protobuf:"bytes,4,opt,name=interface,proto3,oneof"`
 }
 
+type Route_DecapLookup struct {
+	DecapLookup *DecapLookup `protobuf:"bytes,5,opt,name=decap_lookup,json=decapLookup,proto3,oneof"`
+}
+
 func (*Route_NextHops) isRoute_Hop() {}
 
 func (*Route_Interface) isRoute_Hop() {}
 
+func (*Route_DecapLookup) isRoute_Hop() {}
+
+type LabelRoute struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Label           uint32                 `protobuf:"varint,1,opt,name=label,proto3" json:"label,omitempty"`
+	NetworkInstance string                 `protobuf:"bytes,2,opt,name=network_instance,json=networkInstance,proto3" json:"network_instance,omitempty"`
+	PopLabels       uint32                 `protobuf:"varint,3,opt,name=pop_labels,json=popLabels,proto3" json:"pop_labels,omitempty"`
+	NextHops        *NextHopList           `protobuf:"bytes,4,opt,name=next_hops,json=nextHops,proto3" json:"next_hops,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *LabelRoute) Reset() {
+	*x = LabelRoute{}
+	mi := &file_proto_dataplane_dataplane_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LabelRoute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LabelRoute) ProtoMessage() {}
+
+func (x *LabelRoute) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_dataplane_dataplane_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LabelRoute.ProtoReflect.Descriptor instead.
+func (*LabelRoute) Descriptor() ([]byte, []int) {
+	return file_proto_dataplane_dataplane_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LabelRoute) GetLabel() uint32 {
+	if x != nil {
+		return x.Label
+	}
+	return 0
+}
+
+func (x *LabelRoute) GetNetworkInstance() string {
+	if x != nil {
+		return x.NetworkInstance
+	}
+	return ""
+}
+
+func (x *LabelRoute) GetPopLabels() uint32 {
+	if x != nil {
+		return x.PopLabels
+	}
+	return 0
+}
+
+func (x *LabelRoute) GetNextHops() *NextHopList {
+	if x != nil {
+		return x.NextHops
+	}
+	return nil
+}
+
 var File_proto_dataplane_dataplane_proto protoreflect.FileDescriptor
 
 var file_proto_dataplane_dataplane_proto_rawDesc = []byte{
@@ -561,7 +745,7 @@ var file_proto_dataplane_dataplane_proto_rawDesc = []byte{
 	0x52, 0x05, 0x64, 0x73, 0x74, 0x49, 0x70, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x73, 0x74, 0x5f, 0x70,
 	0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x64, 0x73, 0x74, 0x50, 0x6f,
 	0x72, 0x74, 0x12, 0x13, 0x0a, 0x05, 0x69, 0x73, 0x5f, 0x76, 0x36, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x04, 0x69, 0x73, 0x56, 0x36, 0x22, 0xca, 0x01, 0x0a, 0x07, 0x4e, 0x65, 0x78, 0x74,
+	0x08, 0x52, 0x04, 0x69, 0x73, 0x56, 0x36, 0x22, 0x99, 0x02, 0x0a, 0x07, 0x4e, 0x65, 0x78, 0x74,
 	0x48, 0x6f, 0x70, 0x12, 0x3c, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67,
 	0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x4f, 0x43, 0x49, 0x6e, 0x74,
@@ -573,52 +757,90 @@ var file_proto_dataplane_dataplane_proto_rawDesc = []byte{
 	0x6e, 0x65, 0x2e, 0x47, 0x55, 0x45, 0x48, 0x00, 0x52, 0x03, 0x67, 0x75, 0x65, 0x12, 0x2c, 0x0a,
 	0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10,
 	0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73,
-	0x48, 0x00, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x65,
-	0x6e, 0x63, 0x61, 0x70, 0x22, 0x57, 0x0a, 0x0b, 0x4e, 0x65, 0x78, 0x74, 0x48, 0x6f, 0x70, 0x4c,
+	0x48, 0x00, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x61,
+	0x66, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08,
+	0x61, 0x66, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x30, 0x0a, 0x14, 0x61, 0x66, 0x74, 0x5f,
+	0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x61, 0x66, 0x74, 0x4e, 0x65, 0x74, 0x77, 0x6f,
+	0x72, 0x6b, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x6e,
+	0x63, 0x61, 0x70, 0x22, 0x8c, 0x02, 0x0a, 0x0b, 0x4e, 0x65, 0x78, 0x74, 0x48, 0x6f, 0x70, 0x4c,
 	0x69, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x68, 0x6f, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
 	0x0b, 0x32, 0x1a, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x64, 0x61, 0x74, 0x61,
 	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x48, 0x6f, 0x70, 0x52, 0x04, 0x68,
 	0x6f, 0x70, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x18, 0x02,
-	0x20, 0x03, 0x28, 0x04, 0x52, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x22, 0x4c, 0x0a,
-	0x0b, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x12, 0x0a, 0x04,
-	0x63, 0x69, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x69, 0x64, 0x72,
-	0x12, 0x29, 0x0a, 0x10, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e, 0x73, 0x74,
-	0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6e, 0x65, 0x74, 0x77,
-	0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0xfe, 0x01, 0x0a, 0x05,
-	0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x36, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e,
-	0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x50,
-	0x72, 0x65, 0x66, 0x69, 0x78, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x37, 0x0a,
-	0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e,
-	0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e,
-	0x65, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06,
-	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3d, 0x0a, 0x09, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x68,
-	0x6f, 0x70, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x65, 0x6d, 0x6d,
-	0x69, 0x6e, 0x67, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x4e, 0x65,
-	0x78, 0x74, 0x48, 0x6f, 0x70, 0x4c, 0x69, 0x73, 0x74, 0x48, 0x00, 0x52, 0x08, 0x6e, 0x65, 0x78,
-	0x74, 0x48, 0x6f, 0x70, 0x73, 0x12, 0x3e, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61,
-	0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69,
-	0x6e, 0x67, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x4f, 0x43, 0x49,
-	0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x48, 0x00, 0x52, 0x09, 0x69, 0x6e, 0x74, 0x65,
-	0x72, 0x66, 0x61, 0x63, 0x65, 0x42, 0x05, 0x0a, 0x03, 0x68, 0x6f, 0x70, 0x2a, 0x7c, 0x0a, 0x0c,
-	0x50, 0x6f, 0x72, 0x74, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x19,
-	0x50, 0x4f, 0x52, 0x54, 0x5f, 0x4c, 0x4f, 0x43, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e,
-	0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x50,
-	0x4f, 0x52, 0x54, 0x5f, 0x4c, 0x4f, 0x43, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x49, 0x4e, 0x54,
-	0x45, 0x52, 0x4e, 0x41, 0x4c, 0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x4f, 0x52, 0x54, 0x5f,
-	0x4c, 0x4f, 0x43, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x45, 0x58, 0x54, 0x45, 0x52, 0x4e, 0x41,
-	0x4c, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x4c, 0x4f, 0x43, 0x41,
-	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x50, 0x55, 0x10, 0x03, 0x2a, 0x60, 0x0a, 0x0c, 0x50, 0x61,
-	0x63, 0x6b, 0x65, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x19, 0x50, 0x41,
-	0x43, 0x4b, 0x45, 0x54, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50,
-	0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x50, 0x41, 0x43,
-	0x4b, 0x45, 0x54, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x44, 0x52, 0x4f, 0x50, 0x10,
-	0x01, 0x12, 0x19, 0x0a, 0x15, 0x50, 0x41, 0x43, 0x4b, 0x45, 0x54, 0x5f, 0x41, 0x43, 0x54, 0x49,
-	0x4f, 0x4e, 0x5f, 0x46, 0x4f, 0x52, 0x57, 0x41, 0x52, 0x44, 0x10, 0x02, 0x42, 0x2f, 0x5a, 0x2d,
-	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2f, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2f, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x2f, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x20, 0x03, 0x28, 0x04, 0x52, 0x07, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x12, 0x3b, 0x0a,
+	0x0b, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x5f, 0x68, 0x6f, 0x70, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x64, 0x61, 0x74,
+	0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x48, 0x6f, 0x70, 0x52, 0x0a,
+	0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x48, 0x6f, 0x70, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x62, 0x61,
+	0x63, 0x6b, 0x75, 0x70, 0x5f, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x04, 0x52, 0x0d, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x73, 0x12, 0x19, 0x0a, 0x08, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x07, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x64, 0x12, 0x34, 0x0a, 0x16,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x22, 0x4c, 0x0a, 0x0b, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x50, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x69, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x63, 0x69, 0x64, 0x72, 0x12, 0x29, 0x0a, 0x10, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
+	0x22, 0x65, 0x0a, 0x0b, 0x44, 0x65, 0x63, 0x61, 0x70, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x12,
+	0x2b, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x13, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x29, 0x0a, 0x10,
+	0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49,
+	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0xc3, 0x02, 0x0a, 0x05, 0x52, 0x6f, 0x75, 0x74,
+	0x65, 0x12, 0x36, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x64, 0x61, 0x74, 0x61,
+	0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x50, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x37, 0x0a, 0x06, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x6c, 0x65, 0x6d, 0x6d,
+	0x69, 0x6e, 0x67, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x50, 0x61,
+	0x63, 0x6b, 0x65, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x3d, 0x0a, 0x09, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x68, 0x6f, 0x70, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e,
+	0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x48, 0x6f,
+	0x70, 0x4c, 0x69, 0x73, 0x74, 0x48, 0x00, 0x52, 0x08, 0x6e, 0x65, 0x78, 0x74, 0x48, 0x6f, 0x70,
+	0x73, 0x12, 0x3e, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x64,
+	0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x4f, 0x43, 0x49, 0x6e, 0x74, 0x65, 0x72,
+	0x66, 0x61, 0x63, 0x65, 0x48, 0x00, 0x52, 0x09, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63,
+	0x65, 0x12, 0x43, 0x0a, 0x0c, 0x64, 0x65, 0x63, 0x61, 0x70, 0x5f, 0x6c, 0x6f, 0x6f, 0x6b, 0x75,
+	0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e,
+	0x67, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x2e, 0x44, 0x65, 0x63, 0x61,
+	0x70, 0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x48, 0x00, 0x52, 0x0b, 0x64, 0x65, 0x63, 0x61, 0x70,
+	0x4c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x42, 0x05, 0x0a, 0x03, 0x68, 0x6f, 0x70, 0x22, 0xa9, 0x01,
+	0x0a, 0x0a, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x61, 0x62,
+	0x65, 0x6c, 0x12, 0x29, 0x0a, 0x10, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x70, 0x6f, 0x70, 0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x09, 0x70, 0x6f, 0x70, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x3b, 0x0a, 0x09,
+	0x6e, 0x65, 0x78, 0x74, 0x5f, 0x68, 0x6f, 0x70, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1e, 0x2e, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c,
+	0x61, 0x6e, 0x65, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x48, 0x6f, 0x70, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x08, 0x6e, 0x65, 0x78, 0x74, 0x48, 0x6f, 0x70, 0x73, 0x2a, 0x7c, 0x0a, 0x0c, 0x50, 0x6f, 0x72,
+	0x74, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x19, 0x50, 0x4f, 0x52,
+	0x54, 0x5f, 0x4c, 0x4f, 0x43, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45,
+	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x4f, 0x52, 0x54,
+	0x5f, 0x4c, 0x4f, 0x43, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x4e,
+	0x41, 0x4c, 0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x4c, 0x4f, 0x43,
+	0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x45, 0x58, 0x54, 0x45, 0x52, 0x4e, 0x41, 0x4c, 0x10, 0x02,
+	0x12, 0x15, 0x0a, 0x11, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x4c, 0x4f, 0x43, 0x41, 0x54, 0x49, 0x4f,
+	0x4e, 0x5f, 0x43, 0x50, 0x55, 0x10, 0x03, 0x2a, 0x60, 0x0a, 0x0c, 0x50, 0x61, 0x63, 0x6b, 0x65,
+	0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x19, 0x50, 0x41, 0x43, 0x4b, 0x45,
+	0x54, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x50, 0x41, 0x43, 0x4b, 0x45, 0x54,
+	0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x44, 0x52, 0x4f, 0x50, 0x10, 0x01, 0x12, 0x19,
+	0x0a, 0x15, 0x50, 0x41, 0x43, 0x4b, 0x45, 0x54, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f,
+	0x46, 0x4f, 0x52, 0x57, 0x41, 0x52, 0x44, 0x10, 0x02, 0x42, 0x2f, 0x5a, 0x2d, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2f, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x64, 0x61, 0x74, 0x61, 0x70, 0x6c, 0x61, 0x6e, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
@@ -634,7 +856,7 @@ func file_proto_dataplane_dataplane_proto_rawDescGZIP() []byte {
 }
 
 var file_proto_dataplane_dataplane_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_proto_dataplane_dataplane_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_dataplane_dataplane_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_proto_dataplane_dataplane_proto_goTypes = []any{
 	(PortLocation)(0),       // 0: lemming.dataplane.PortLocation
 	(PacketAction)(0),       // 1: lemming.dataplane.PacketAction
@@ -643,23 +865,30 @@ var file_proto_dataplane_dataplane_proto_goTypes = []any{
 	(*NextHop)(nil),         // 4: lemming.dataplane.NextHop
 	(*NextHopList)(nil),     // 5: lemming.dataplane.NextHopList
 	(*RoutePrefix)(nil),     // 6: lemming.dataplane.RoutePrefix
-	(*Route)(nil),           // 7: lemming.dataplane.Route
-	(*routing.Headers)(nil), // 8: routing.Headers
+	(*DecapLookup)(nil),     // 7: lemming.dataplane.DecapLookup
+	(*Route)(nil),           // 8: lemming.dataplane.Route
+	(*LabelRoute)(nil),      // 9: lemming.dataplane.LabelRoute
+	(*routing.Headers)(nil), // 10: routing.Headers
+	(routing.HeaderType)(0), // 11: routing.HeaderType
 }
 var file_proto_dataplane_dataplane_proto_depIdxs = []int32{
-	2, // 0: lemming.dataplane.NextHop.interface:type_name -> lemming.dataplane.OCInterface
-	3, // 1: lemming.dataplane.NextHop.gue:type_name -> lemming.dataplane.GUE
-	8, // 2: lemming.dataplane.NextHop.headers:type_name -> routing.Headers
-	4, // 3: lemming.dataplane.NextHopList.hops:type_name -> lemming.dataplane.NextHop
-	6, // 4: lemming.dataplane.Route.prefix:type_name -> lemming.dataplane.RoutePrefix
-	1, // 5: lemming.dataplane.Route.action:type_name -> lemming.dataplane.PacketAction
-	5, // 6: lemming.dataplane.Route.next_hops:type_name -> lemming.dataplane.NextHopList
-	2, // 7: lemming.dataplane.Route.interface:type_name -> lemming.dataplane.OCInterface
-	8, // [8:8] is the sub-list for method output_type
-	8, // [8:8] is the sub-list for method input_type
-	8, // [8:8] is the sub-list for extension type_name
-	8, // [8:8] is the sub-list for extension extendee
-	0, // [0:8] is the sub-list for field type_name
+	2,  // 0: lemming.dataplane.NextHop.interface:type_name -> lemming.dataplane.OCInterface
+	3,  // 1: lemming.dataplane.NextHop.gue:type_name -> lemming.dataplane.GUE
+	10, // 2: lemming.dataplane.NextHop.headers:type_name -> routing.Headers
+	4,  // 3: lemming.dataplane.NextHopList.hops:type_name -> lemming.dataplane.NextHop
+	4,  // 4: lemming.dataplane.NextHopList.backup_hops:type_name -> lemming.dataplane.NextHop
+	11, // 5: lemming.dataplane.DecapLookup.header:type_name -> routing.HeaderType
+	6,  // 6: lemming.dataplane.Route.prefix:type_name -> lemming.dataplane.RoutePrefix
+	1,  // 7: lemming.dataplane.Route.action:type_name -> lemming.dataplane.PacketAction
+	5,  // 8: lemming.dataplane.Route.next_hops:type_name -> lemming.dataplane.NextHopList
+	2,  // 9: lemming.dataplane.Route.interface:type_name -> lemming.dataplane.OCInterface
+	7,  // 10: lemming.dataplane.Route.decap_lookup:type_name -> lemming.dataplane.DecapLookup
+	5,  // 11: lemming.dataplane.LabelRoute.next_hops:type_name -> lemming.dataplane.NextHopList
+	12, // [12:12] is the sub-list for method output_type
+	12, // [12:12] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_proto_dataplane_dataplane_proto_init() }
@@ -671,9 +900,10 @@ func file_proto_dataplane_dataplane_proto_init() {
 		(*NextHop_Gue)(nil),
 		(*NextHop_Headers)(nil),
 	}
-	file_proto_dataplane_dataplane_proto_msgTypes[5].OneofWrappers = []any{
+	file_proto_dataplane_dataplane_proto_msgTypes[6].OneofWrappers = []any{
 		(*Route_NextHops)(nil),
 		(*Route_Interface)(nil),
+		(*Route_DecapLookup)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -681,7 +911,7 @@ func file_proto_dataplane_dataplane_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_dataplane_dataplane_proto_rawDesc,
 			NumEnums:      2,
-			NumMessages:   6,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
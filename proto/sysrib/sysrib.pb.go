@@ -219,19 +219,21 @@ func (SetRouteResponse_Status) EnumDescriptor() ([]byte, []int) {
 }
 
 type SetRouteRequest struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Delete          bool                   `protobuf:"varint,1,opt,name=delete,proto3" json:"delete,omitempty"`
-	VrfId           uint32                 `protobuf:"varint,2,opt,name=vrf_id,json=vrfId,proto3" json:"vrf_id,omitempty"`
-	AdminDistance   uint32                 `protobuf:"varint,3,opt,name=admin_distance,json=adminDistance,proto3" json:"admin_distance,omitempty"`
-	ProtocolName    string                 `protobuf:"bytes,4,opt,name=protocol_name,json=protocolName,proto3" json:"protocol_name,omitempty"`
-	Safi            SetRouteRequest_Safi   `protobuf:"varint,5,opt,name=safi,proto3,enum=sysrib.SetRouteRequest_Safi" json:"safi,omitempty"`
-	Prefix          *Prefix                `protobuf:"bytes,6,opt,name=prefix,proto3" json:"prefix,omitempty"`
-	Metric          uint32                 `protobuf:"varint,7,opt,name=metric,proto3" json:"metric,omitempty"`
-	Nexthops        []*Nexthop             `protobuf:"bytes,8,rep,name=nexthops,proto3" json:"nexthops,omitempty"`
-	BackupNexthops  []*Nexthop             `protobuf:"bytes,9,rep,name=backup_nexthops,json=backupNexthops,proto3" json:"backup_nexthops,omitempty"`
-	NetworkInstance string                 `protobuf:"bytes,10,opt,name=network_instance,json=networkInstance,proto3" json:"network_instance,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	state                       protoimpl.MessageState `protogen:"open.v1"`
+	Delete                      bool                   `protobuf:"varint,1,opt,name=delete,proto3" json:"delete,omitempty"`
+	VrfId                       uint32                 `protobuf:"varint,2,opt,name=vrf_id,json=vrfId,proto3" json:"vrf_id,omitempty"`
+	AdminDistance               uint32                 `protobuf:"varint,3,opt,name=admin_distance,json=adminDistance,proto3" json:"admin_distance,omitempty"`
+	ProtocolName                string                 `protobuf:"bytes,4,opt,name=protocol_name,json=protocolName,proto3" json:"protocol_name,omitempty"`
+	Safi                        SetRouteRequest_Safi   `protobuf:"varint,5,opt,name=safi,proto3,enum=sysrib.SetRouteRequest_Safi" json:"safi,omitempty"`
+	Prefix                      *Prefix                `protobuf:"bytes,6,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Metric                      uint32                 `protobuf:"varint,7,opt,name=metric,proto3" json:"metric,omitempty"`
+	Nexthops                    []*Nexthop             `protobuf:"bytes,8,rep,name=nexthops,proto3" json:"nexthops,omitempty"`
+	BackupNexthops              []*Nexthop             `protobuf:"bytes,9,rep,name=backup_nexthops,json=backupNexthops,proto3" json:"backup_nexthops,omitempty"`
+	NetworkInstance             string                 `protobuf:"bytes,10,opt,name=network_instance,json=networkInstance,proto3" json:"network_instance,omitempty"`
+	NextHopGroupId              uint64                 `protobuf:"varint,11,opt,name=next_hop_group_id,json=nextHopGroupId,proto3" json:"next_hop_group_id,omitempty"`
+	NextHopGroupNetworkInstance string                 `protobuf:"bytes,12,opt,name=next_hop_group_network_instance,json=nextHopGroupNetworkInstance,proto3" json:"next_hop_group_network_instance,omitempty"`
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
 }
 
 func (x *SetRouteRequest) Reset() {
@@ -334,6 +336,20 @@ func (x *SetRouteRequest) GetNetworkInstance() string {
 	return ""
 }
 
+func (x *SetRouteRequest) GetNextHopGroupId() uint64 {
+	if x != nil {
+		return x.NextHopGroupId
+	}
+	return 0
+}
+
+func (x *SetRouteRequest) GetNextHopGroupNetworkInstance() string {
+	if x != nil {
+		return x.NextHopGroupNetworkInstance
+	}
+	return ""
+}
+
 type Prefix struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Family        Prefix_Family          `protobuf:"varint,1,opt,name=family,proto3,enum=sysrib.Prefix_Family" json:"family,omitempty"`
@@ -395,14 +411,18 @@ func (x *Prefix) GetMaskLength() uint32 {
 }
 
 type Nexthop struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	VrfId         uint32                 `protobuf:"varint,1,opt,name=vrf_id,json=vrfId,proto3" json:"vrf_id,omitempty"`
-	Type          Nexthop_Type           `protobuf:"varint,2,opt,name=type,proto3,enum=sysrib.Nexthop_Type" json:"type,omitempty"`
-	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
-	Weight        uint64                 `protobuf:"varint,4,opt,name=weight,proto3" json:"weight,omitempty"`
-	Encap         *routing.Headers       `protobuf:"bytes,5,opt,name=encap,proto3" json:"encap,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	VrfId              uint32                 `protobuf:"varint,1,opt,name=vrf_id,json=vrfId,proto3" json:"vrf_id,omitempty"`
+	Type               Nexthop_Type           `protobuf:"varint,2,opt,name=type,proto3,enum=sysrib.Nexthop_Type" json:"type,omitempty"`
+	Address            string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Weight             uint64                 `protobuf:"varint,4,opt,name=weight,proto3" json:"weight,omitempty"`
+	Encap              *routing.Headers       `protobuf:"bytes,5,opt,name=encap,proto3" json:"encap,omitempty"`
+	DecapsulateHeader  routing.HeaderType     `protobuf:"varint,6,opt,name=decapsulate_header,json=decapsulateHeader,proto3,enum=routing.HeaderType" json:"decapsulate_header,omitempty"`
+	NetworkInstance    string                 `protobuf:"bytes,7,opt,name=network_instance,json=networkInstance,proto3" json:"network_instance,omitempty"`
+	AftIndex           uint64                 `protobuf:"varint,8,opt,name=aft_index,json=aftIndex,proto3" json:"aft_index,omitempty"`
+	AftNetworkInstance string                 `protobuf:"bytes,9,opt,name=aft_network_instance,json=aftNetworkInstance,proto3" json:"aft_network_instance,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *Nexthop) Reset() {
@@ -470,9 +490,38 @@ func (x *Nexthop) GetEncap() *routing.Headers {
 	return nil
 }
 
+func (x *Nexthop) GetDecapsulateHeader() routing.HeaderType {
+	if x != nil {
+		return x.DecapsulateHeader
+	}
+	return routing.HeaderType(0)
+}
+
+func (x *Nexthop) GetNetworkInstance() string {
+	if x != nil {
+		return x.NetworkInstance
+	}
+	return ""
+}
+
+func (x *Nexthop) GetAftIndex() uint64 {
+	if x != nil {
+		return x.AftIndex
+	}
+	return 0
+}
+
+func (x *Nexthop) GetAftNetworkInstance() string {
+	if x != nil {
+		return x.AftNetworkInstance
+	}
+	return ""
+}
+
 type SetRouteResponse struct {
 	state         protoimpl.MessageState  `protogen:"open.v1"`
 	Status        SetRouteResponse_Status `protobuf:"varint,1,opt,name=status,proto3,enum=sysrib.SetRouteResponse_Status" json:"status,omitempty"`
+	Message       string                  `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -514,6 +563,141 @@ func (x *SetRouteResponse) GetStatus() SetRouteResponse_Status {
 	return SetRouteResponse_STATUS_UNSPECIFIED
 }
 
+func (x *SetRouteResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type SetLabelRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Delete          bool                   `protobuf:"varint,1,opt,name=delete,proto3" json:"delete,omitempty"`
+	NetworkInstance string                 `protobuf:"bytes,2,opt,name=network_instance,json=networkInstance,proto3" json:"network_instance,omitempty"`
+	Label           uint32                 `protobuf:"varint,3,opt,name=label,proto3" json:"label,omitempty"`
+	PopLabels       uint32                 `protobuf:"varint,4,opt,name=pop_labels,json=popLabels,proto3" json:"pop_labels,omitempty"`
+	Nexthops        []*Nexthop             `protobuf:"bytes,5,rep,name=nexthops,proto3" json:"nexthops,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SetLabelRequest) Reset() {
+	*x = SetLabelRequest{}
+	mi := &file_proto_sysrib_sysrib_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLabelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLabelRequest) ProtoMessage() {}
+
+func (x *SetLabelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sysrib_sysrib_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLabelRequest.ProtoReflect.Descriptor instead.
+func (*SetLabelRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sysrib_sysrib_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SetLabelRequest) GetDelete() bool {
+	if x != nil {
+		return x.Delete
+	}
+	return false
+}
+
+func (x *SetLabelRequest) GetNetworkInstance() string {
+	if x != nil {
+		return x.NetworkInstance
+	}
+	return ""
+}
+
+func (x *SetLabelRequest) GetLabel() uint32 {
+	if x != nil {
+		return x.Label
+	}
+	return 0
+}
+
+func (x *SetLabelRequest) GetPopLabels() uint32 {
+	if x != nil {
+		return x.PopLabels
+	}
+	return 0
+}
+
+func (x *SetLabelRequest) GetNexthops() []*Nexthop {
+	if x != nil {
+		return x.Nexthops
+	}
+	return nil
+}
+
+type SetLabelResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Status        SetRouteResponse_Status `protobuf:"varint,1,opt,name=status,proto3,enum=sysrib.SetRouteResponse_Status" json:"status,omitempty"`
+	Message       string                  `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLabelResponse) Reset() {
+	*x = SetLabelResponse{}
+	mi := &file_proto_sysrib_sysrib_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLabelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLabelResponse) ProtoMessage() {}
+
+func (x *SetLabelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sysrib_sysrib_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLabelResponse.ProtoReflect.Descriptor instead.
+func (*SetLabelResponse) Descriptor() ([]byte, []int) {
+	return file_proto_sysrib_sysrib_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SetLabelResponse) GetStatus() SetRouteResponse_Status {
+	if x != nil {
+		return x.Status
+	}
+	return SetRouteResponse_STATUS_UNSPECIFIED
+}
+
+func (x *SetLabelResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 var File_proto_sysrib_sysrib_proto protoreflect.FileDescriptor
 
 var file_proto_sysrib_sysrib_proto_rawDesc = []byte{
@@ -521,7 +705,7 @@ var file_proto_sysrib_sysrib_proto_rawDesc = []byte{
 	0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x73, 0x79, 0x73,
 	0x72, 0x69, 0x62, 0x1a, 0x1b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x72, 0x6f, 0x75, 0x74, 0x69,
 	0x6e, 0x67, 0x2f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x22, 0xc0, 0x03, 0x0a, 0x0f, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x22, 0xb1, 0x04, 0x0a, 0x0f, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x71,
 	0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x01,
 	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x15, 0x0a, 0x06,
 	0x76, 0x72, 0x66, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x76, 0x72,
@@ -546,53 +730,95 @@ var file_proto_sysrib_sysrib_proto_rawDesc = []byte{
 	0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x6e, 0x65, 0x74, 0x77,
 	0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x0a, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x0f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x73, 0x74, 0x61,
-	0x6e, 0x63, 0x65, 0x22, 0x2e, 0x0a, 0x04, 0x53, 0x61, 0x66, 0x69, 0x12, 0x14, 0x0a, 0x10, 0x53,
-	0x41, 0x46, 0x49, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
-	0x00, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x41, 0x46, 0x49, 0x5f, 0x55, 0x4e, 0x49, 0x43, 0x41, 0x53,
-	0x54, 0x10, 0x01, 0x22, 0xb6, 0x01, 0x0a, 0x06, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x2d,
-	0x0a, 0x06, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15,
-	0x2e, 0x73, 0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x2e, 0x46,
-	0x61, 0x6d, 0x69, 0x6c, 0x79, 0x52, 0x06, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x79, 0x12, 0x18, 0x0a,
-	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
-	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x73, 0x6b, 0x5f,
-	0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6d, 0x61,
-	0x73, 0x6b, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x22, 0x42, 0x0a, 0x06, 0x46, 0x61, 0x6d, 0x69,
-	0x6c, 0x79, 0x12, 0x16, 0x0a, 0x12, 0x46, 0x41, 0x4d, 0x49, 0x4c, 0x59, 0x5f, 0x55, 0x4e, 0x53,
-	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x46, 0x41,
-	0x4d, 0x49, 0x4c, 0x59, 0x5f, 0x49, 0x50, 0x56, 0x34, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x46,
-	0x41, 0x4d, 0x49, 0x4c, 0x59, 0x5f, 0x49, 0x50, 0x56, 0x36, 0x10, 0x02, 0x22, 0xe0, 0x01, 0x0a,
-	0x07, 0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x12, 0x15, 0x0a, 0x06, 0x76, 0x72, 0x66, 0x5f,
-	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x76, 0x72, 0x66, 0x49, 0x64, 0x12,
-	0x28, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e,
-	0x73, 0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x2e, 0x54,
-	0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64,
-	0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72,
-	0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x26, 0x0a, 0x05, 0x65,
-	0x6e, 0x63, 0x61, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x72, 0x6f, 0x75,
-	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x52, 0x05, 0x65, 0x6e,
-	0x63, 0x61, 0x70, 0x22, 0x3a, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x10, 0x54,
-	0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
-	0x00, 0x12, 0x0d, 0x0a, 0x09, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x50, 0x56, 0x34, 0x10, 0x01,
-	0x12, 0x0d, 0x0a, 0x09, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x50, 0x56, 0x36, 0x10, 0x02, 0x22,
-	0x92, 0x01, 0x0a, 0x10, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x73, 0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x53, 0x65,
-	0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x45, 0x0a,
-	0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x12, 0x53, 0x54, 0x41, 0x54, 0x55,
-	0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12,
-	0x12, 0x0a, 0x0e, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x53, 0x55, 0x43, 0x43, 0x45, 0x53,
-	0x53, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x46, 0x41,
-	0x49, 0x4c, 0x10, 0x02, 0x32, 0x47, 0x0a, 0x06, 0x53, 0x79, 0x73, 0x72, 0x69, 0x62, 0x12, 0x3d,
-	0x0a, 0x08, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x73, 0x79, 0x73,
-	0x72, 0x69, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x73, 0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x53, 0x65, 0x74,
-	0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2c, 0x5a,
-	0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x70, 0x65, 0x6e,
-	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2f, 0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2f, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x79, 0x73, 0x72, 0x69, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x6e, 0x63, 0x65, 0x12, 0x29, 0x0a, 0x11, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x68, 0x6f, 0x70, 0x5f,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e,
+	0x6e, 0x65, 0x78, 0x74, 0x48, 0x6f, 0x70, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x64, 0x12, 0x44,
+	0x0a, 0x1f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x68, 0x6f, 0x70, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x5f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x1b, 0x6e, 0x65, 0x78, 0x74, 0x48, 0x6f, 0x70,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x22, 0x2e, 0x0a, 0x04, 0x53, 0x61, 0x66, 0x69, 0x12, 0x14, 0x0a, 0x10,
+	0x53, 0x41, 0x46, 0x49, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44,
+	0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x41, 0x46, 0x49, 0x5f, 0x55, 0x4e, 0x49, 0x43, 0x41,
+	0x53, 0x54, 0x10, 0x01, 0x22, 0xb6, 0x01, 0x0a, 0x06, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12,
+	0x2d, 0x0a, 0x06, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x15, 0x2e, 0x73, 0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x2e,
+	0x46, 0x61, 0x6d, 0x69, 0x6c, 0x79, 0x52, 0x06, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x79, 0x12, 0x18,
+	0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x73, 0x6b,
+	0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6d,
+	0x61, 0x73, 0x6b, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x22, 0x42, 0x0a, 0x06, 0x46, 0x61, 0x6d,
+	0x69, 0x6c, 0x79, 0x12, 0x16, 0x0a, 0x12, 0x46, 0x41, 0x4d, 0x49, 0x4c, 0x59, 0x5f, 0x55, 0x4e,
+	0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x46,
+	0x41, 0x4d, 0x49, 0x4c, 0x59, 0x5f, 0x49, 0x50, 0x56, 0x34, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b,
+	0x46, 0x41, 0x4d, 0x49, 0x4c, 0x59, 0x5f, 0x49, 0x50, 0x56, 0x36, 0x10, 0x02, 0x22, 0x9e, 0x03,
+	0x0a, 0x07, 0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x12, 0x15, 0x0a, 0x06, 0x76, 0x72, 0x66,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x76, 0x72, 0x66, 0x49, 0x64,
+	0x12, 0x28, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14,
+	0x2e, 0x73, 0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x2e,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x26, 0x0a, 0x05,
+	0x65, 0x6e, 0x63, 0x61, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x72, 0x6f,
+	0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x52, 0x05, 0x65,
+	0x6e, 0x63, 0x61, 0x70, 0x12, 0x42, 0x0a, 0x12, 0x64, 0x65, 0x63, 0x61, 0x70, 0x73, 0x75, 0x6c,
+	0x61, 0x74, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x13, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x54, 0x79, 0x70, 0x65, 0x52, 0x11, 0x64, 0x65, 0x63, 0x61, 0x70, 0x73, 0x75, 0x6c, 0x61,
+	0x74, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x29, 0x0a, 0x10, 0x6e, 0x65, 0x74, 0x77,
+	0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x61, 0x66, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x61, 0x66, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x12, 0x30, 0x0a, 0x14, 0x61, 0x66, 0x74, 0x5f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f,
+	0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12,
+	0x61, 0x66, 0x74, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x22, 0x3a, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x10, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
+	0x12, 0x0d, 0x0a, 0x09, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x50, 0x56, 0x34, 0x10, 0x01, 0x12,
+	0x0d, 0x0a, 0x09, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x50, 0x56, 0x36, 0x10, 0x02, 0x22, 0xac,
+	0x01, 0x0a, 0x10, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x73, 0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x53, 0x65, 0x74,
+	0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x45, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x12, 0x16, 0x0a, 0x12, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45,
+	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x54, 0x41, 0x54,
+	0x55, 0x53, 0x5f, 0x53, 0x55, 0x43, 0x43, 0x45, 0x53, 0x53, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b,
+	0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x10, 0x02, 0x22, 0xb6, 0x01,
+	0x0a, 0x0f, 0x53, 0x65, 0x74, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x6e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x6f,
+	0x70, 0x5f, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09,
+	0x70, 0x6f, 0x70, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x2b, 0x0a, 0x08, 0x6e, 0x65, 0x78,
+	0x74, 0x68, 0x6f, 0x70, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x73, 0x79,
+	0x73, 0x72, 0x69, 0x62, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x68, 0x6f, 0x70, 0x52, 0x08, 0x6e, 0x65,
+	0x78, 0x74, 0x68, 0x6f, 0x70, 0x73, 0x22, 0x65, 0x0a, 0x10, 0x53, 0x65, 0x74, 0x4c, 0x61, 0x62,
+	0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x73, 0x79, 0x73,
+	0x72, 0x69, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x32, 0x86, 0x01,
+	0x0a, 0x06, 0x53, 0x79, 0x73, 0x72, 0x69, 0x62, 0x12, 0x3d, 0x0a, 0x08, 0x53, 0x65, 0x74, 0x52,
+	0x6f, 0x75, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x73, 0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x53, 0x65,
+	0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e,
+	0x73, 0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x08, 0x53, 0x65, 0x74, 0x4c, 0x61,
+	0x62, 0x65, 0x6c, 0x12, 0x17, 0x2e, 0x73, 0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x53, 0x65, 0x74,
+	0x4c, 0x61, 0x62, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x73,
+	0x79, 0x73, 0x72, 0x69, 0x62, 0x2e, 0x53, 0x65, 0x74, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2c, 0x5a, 0x2a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2f,
+	0x6c, 0x65, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x79,
+	0x73, 0x72, 0x69, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -608,7 +834,7 @@ func file_proto_sysrib_sysrib_proto_rawDescGZIP() []byte {
 }
 
 var file_proto_sysrib_sysrib_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
-var file_proto_sysrib_sysrib_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_sysrib_sysrib_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_proto_sysrib_sysrib_proto_goTypes = []any{
 	(SetRouteRequest_Safi)(0),    // 0: sysrib.SetRouteRequest.Safi
 	(Prefix_Family)(0),           // 1: sysrib.Prefix.Family
@@ -618,24 +844,32 @@ var file_proto_sysrib_sysrib_proto_goTypes = []any{
 	(*Prefix)(nil),               // 5: sysrib.Prefix
 	(*Nexthop)(nil),              // 6: sysrib.Nexthop
 	(*SetRouteResponse)(nil),     // 7: sysrib.SetRouteResponse
-	(*routing.Headers)(nil),      // 8: routing.Headers
+	(*SetLabelRequest)(nil),      // 8: sysrib.SetLabelRequest
+	(*SetLabelResponse)(nil),     // 9: sysrib.SetLabelResponse
+	(*routing.Headers)(nil),      // 10: routing.Headers
+	(routing.HeaderType)(0),      // 11: routing.HeaderType
 }
 var file_proto_sysrib_sysrib_proto_depIdxs = []int32{
-	0, // 0: sysrib.SetRouteRequest.safi:type_name -> sysrib.SetRouteRequest.Safi
-	5, // 1: sysrib.SetRouteRequest.prefix:type_name -> sysrib.Prefix
-	6, // 2: sysrib.SetRouteRequest.nexthops:type_name -> sysrib.Nexthop
-	6, // 3: sysrib.SetRouteRequest.backup_nexthops:type_name -> sysrib.Nexthop
-	1, // 4: sysrib.Prefix.family:type_name -> sysrib.Prefix.Family
-	2, // 5: sysrib.Nexthop.type:type_name -> sysrib.Nexthop.Type
-	8, // 6: sysrib.Nexthop.encap:type_name -> routing.Headers
-	3, // 7: sysrib.SetRouteResponse.status:type_name -> sysrib.SetRouteResponse.Status
-	4, // 8: sysrib.Sysrib.SetRoute:input_type -> sysrib.SetRouteRequest
-	7, // 9: sysrib.Sysrib.SetRoute:output_type -> sysrib.SetRouteResponse
-	9, // [9:10] is the sub-list for method output_type
-	8, // [8:9] is the sub-list for method input_type
-	8, // [8:8] is the sub-list for extension type_name
-	8, // [8:8] is the sub-list for extension extendee
-	0, // [0:8] is the sub-list for field type_name
+	0,  // 0: sysrib.SetRouteRequest.safi:type_name -> sysrib.SetRouteRequest.Safi
+	5,  // 1: sysrib.SetRouteRequest.prefix:type_name -> sysrib.Prefix
+	6,  // 2: sysrib.SetRouteRequest.nexthops:type_name -> sysrib.Nexthop
+	6,  // 3: sysrib.SetRouteRequest.backup_nexthops:type_name -> sysrib.Nexthop
+	1,  // 4: sysrib.Prefix.family:type_name -> sysrib.Prefix.Family
+	2,  // 5: sysrib.Nexthop.type:type_name -> sysrib.Nexthop.Type
+	10, // 6: sysrib.Nexthop.encap:type_name -> routing.Headers
+	11, // 7: sysrib.Nexthop.decapsulate_header:type_name -> routing.HeaderType
+	3,  // 8: sysrib.SetRouteResponse.status:type_name -> sysrib.SetRouteResponse.Status
+	6,  // 9: sysrib.SetLabelRequest.nexthops:type_name -> sysrib.Nexthop
+	3,  // 10: sysrib.SetLabelResponse.status:type_name -> sysrib.SetRouteResponse.Status
+	4,  // 11: sysrib.Sysrib.SetRoute:input_type -> sysrib.SetRouteRequest
+	8,  // 12: sysrib.Sysrib.SetLabel:input_type -> sysrib.SetLabelRequest
+	7,  // 13: sysrib.Sysrib.SetRoute:output_type -> sysrib.SetRouteResponse
+	9,  // 14: sysrib.Sysrib.SetLabel:output_type -> sysrib.SetLabelResponse
+	13, // [13:15] is the sub-list for method output_type
+	11, // [11:13] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_proto_sysrib_sysrib_proto_init() }
@@ -649,7 +883,7 @@ func file_proto_sysrib_sysrib_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_sysrib_sysrib_proto_rawDesc,
 			NumEnums:      4,
-			NumMessages:   4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -677,6 +911,7 @@ const _ = grpc.SupportPackageIsVersion6
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type SysribClient interface {
 	SetRoute(ctx context.Context, in *SetRouteRequest, opts ...grpc.CallOption) (*SetRouteResponse, error)
+	SetLabel(ctx context.Context, in *SetLabelRequest, opts ...grpc.CallOption) (*SetLabelResponse, error)
 }
 
 type sysribClient struct {
@@ -696,9 +931,19 @@ func (c *sysribClient) SetRoute(ctx context.Context, in *SetRouteRequest, opts .
 	return out, nil
 }
 
+func (c *sysribClient) SetLabel(ctx context.Context, in *SetLabelRequest, opts ...grpc.CallOption) (*SetLabelResponse, error) {
+	out := new(SetLabelResponse)
+	err := c.cc.Invoke(ctx, "/sysrib.Sysrib/SetLabel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SysribServer is the server API for Sysrib service.
 type SysribServer interface {
 	SetRoute(context.Context, *SetRouteRequest) (*SetRouteResponse, error)
+	SetLabel(context.Context, *SetLabelRequest) (*SetLabelResponse, error)
 }
 
 // UnimplementedSysribServer can be embedded to have forward compatible implementations.
@@ -708,6 +953,9 @@ type UnimplementedSysribServer struct {
 func (*UnimplementedSysribServer) SetRoute(context.Context, *SetRouteRequest) (*SetRouteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetRoute not implemented")
 }
+func (*UnimplementedSysribServer) SetLabel(context.Context, *SetLabelRequest) (*SetLabelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLabel not implemented")
+}
 
 func RegisterSysribServer(s *grpc.Server, srv SysribServer) {
 	s.RegisterService(&_Sysrib_serviceDesc, srv)
@@ -731,6 +979,24 @@ func _Sysrib_SetRoute_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Sysrib_SetLabel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLabelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SysribServer).SetLabel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sysrib.Sysrib/SetLabel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SysribServer).SetLabel(ctx, req.(*SetLabelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Sysrib_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "sysrib.Sysrib",
 	HandlerType: (*SysribServer)(nil),
@@ -739,6 +1005,10 @@ var _Sysrib_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SetRoute",
 			Handler:    _Sysrib_SetRoute_Handler,
 		},
+		{
+			MethodName: "SetLabel",
+			Handler:    _Sysrib_SetLabel_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/sysrib/sysrib.proto",
@@ -0,0 +1,240 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sysrib
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+
+	"github.com/openconfig/lemming/dataplane/dplanerc"
+	"github.com/openconfig/lemming/gnmi/fakedevice"
+
+	dpb "github.com/openconfig/lemming/proto/dataplane"
+	sysribpb "github.com/openconfig/lemming/proto/sysrib"
+)
+
+// LabelKey is the unique identifier of an MPLS label entry.
+type LabelKey struct {
+	Label  uint32
+	NIName string
+}
+
+// LabelRoute is an MPLS label entry whose nexthops are resolved through the
+// IP routes of the RIB.
+type LabelRoute struct {
+	LabelKey
+
+	// PopLabels is the number of labels popped from the top of the stack,
+	// including the incoming label.
+	PopLabels uint32
+	NextHops  []*ResolvedNexthop
+}
+
+// ResolvedLabel represents an MPLS label entry that is ready to be programmed
+// into the forwarding plane.
+type ResolvedLabel struct {
+	LabelKey
+
+	PopLabels uint32
+	Nexthops  []*ResolvedNexthop
+}
+
+func resolvedLabelToLabelRoute(l *ResolvedLabel) *dpb.LabelRoute {
+	nexthops := &dpb.NextHopList{}
+	for _, nh := range l.Nexthops {
+		nexthops.Hops = append(nexthops.Hops, resolvedNexthopToNextHop(nh))
+		nexthops.Weights = append(nexthops.Weights, nh.Weight)
+	}
+	return &dpb.LabelRoute{
+		Label:           l.Label,
+		NetworkInstance: l.NIName,
+		PopLabels:       l.PopLabels,
+		NextHops:        nexthops,
+	}
+}
+
+// labelStatus waits for the dataplane to report the outcome of programming
+// the label entry, returning an error if programming failed or no outcome was
+// reported within timeout.
+func (d *dplane) labelStatus(ctx context.Context, l *ResolvedLabel, timeout time.Duration) error {
+	lr := resolvedLabelToLabelRoute(l)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var st *spb.Status
+	_, err := ygnmi.Watch(ctx, d.Client, dplanerc.LabelStatusQuery(l.NIName, l.Label), func(v *ygnmi.Value[*spb.Status]) error {
+		val, ok := v.Val()
+		if !ok || len(val.GetDetails()) == 0 {
+			return ygnmi.Continue
+		}
+		programmed := &dpb.LabelRoute{}
+		if err := val.GetDetails()[0].UnmarshalTo(programmed); err != nil || !proto.Equal(programmed, lr) {
+			// The status is for a previous version of the label entry.
+			return ygnmi.Continue
+		}
+		st = val
+		return nil
+	}).Await()
+	if err != nil {
+		return fmt.Errorf("no dataplane status for label %d: %v", l.Label, err)
+	}
+	return status.FromProto(st).Err()
+}
+
+// programLabel programs the label entry in the dataplane, returning an error
+// on failure.
+func (d *dplane) programLabel(ctx context.Context, l *ResolvedLabel) error {
+	log.V(1).Infof("sysrib: programming resolved label: %+v", l)
+	_, err := ygnmi.Replace(ctx, d.Client, dplanerc.LabelQuery(l.NIName, l.Label), resolvedLabelToLabelRoute(l), ygnmi.WithSetFallbackEncoding())
+	return err
+}
+
+// deprogramLabel de-programs the label entry in the dataplane, returning an
+// error on failure.
+func (d *dplane) deprogramLabel(ctx context.Context, l *ResolvedLabel) error {
+	log.V(1).Infof("sysrib: deprogramming label: %+v", l)
+	_, err := ygnmi.Delete(ctx, d.Client, dplanerc.LabelQuery(l.NIName, l.Label))
+	return err
+}
+
+// resolveAndProgramLabels resolves the nexthops of each label entry and
+// programs the ones that changed, deprogramming the ones that became
+// unresolved or were deleted.
+//
+// NOTE: s.rib.mu.RLock() must be called prior to calling this function.
+func (s *Server) resolveAndProgramLabels(ctx context.Context) {
+	s.labelsMu.Lock()
+	defer s.labelsMu.Unlock()
+
+	resolved := map[LabelKey]*ResolvedLabel{}
+	for key, l := range s.labels {
+		rl := &ResolvedLabel{LabelKey: key, PopLabels: l.PopLabels}
+		for _, nh := range l.NextHops {
+			pfx, err := addressToPrefix(nh.Address)
+			if err != nil {
+				log.Errorf("sysrib: %v", err)
+				continue
+			}
+			s.interfacesMu.Lock()
+			nhs, _, err := s.rib.egressNexthops(nh.NetworkInstance, pfx, s.interfaces)
+			s.interfacesMu.Unlock()
+			if err != nil {
+				log.Errorf("sysrib: %v", err)
+				continue
+			}
+			for _, rnh := range nhs {
+				rnh.Headers = slices.Concat(nh.Headers, rnh.Headers)
+				rnh.AFTNextHop = nh.AFTNextHop
+				rl.Nexthops = append(rl.Nexthops, rnh)
+			}
+		}
+		if len(rl.Nexthops) == 0 {
+			continue
+		}
+		resolved[key] = rl
+		if reflect.DeepEqual(s.programmedLabels[key], rl) {
+			continue
+		}
+		if err := s.dataplane.programLabel(ctx, rl); err != nil {
+			log.Warningf("failed to program label %+v: %v", rl, err)
+			continue
+		}
+		s.programmedLabels[key] = rl
+	}
+
+	for key, rl := range s.programmedLabels {
+		if _, ok := resolved[key]; ok {
+			continue
+		}
+		if err := s.dataplane.deprogramLabel(ctx, rl); err != nil {
+			log.Warningf("failed to deprogram label %+v: %v", rl, err)
+			continue
+		}
+		delete(s.programmedLabels, key)
+	}
+}
+
+// ProgrammedLabels returns the shallow copy of the programmed label entries
+// of the RIB manager.
+func (s *Server) ProgrammedLabels() map[LabelKey]*ResolvedLabel {
+	s.labelsMu.Lock()
+	defer s.labelsMu.Unlock()
+	return maps.Clone(s.programmedLabels)
+}
+
+// SetLabel adds or deletes an MPLS label entry, whose nexthops are resolved
+// through the IP routes of the RIB.
+func (s *Server) SetLabel(ctx context.Context, req *sysribpb.SetLabelRequest) (*sysribpb.SetLabelResponse, error) {
+	nexthops, err := requestNexthops(req.GetNexthops())
+	if err != nil {
+		return nil, err
+	}
+	niName := req.GetNetworkInstance()
+	if niName == "" {
+		niName = fakedevice.DefaultNetworkInstance
+	}
+	key := LabelKey{Label: req.GetLabel(), NIName: niName}
+
+	s.labelsMu.Lock()
+	if req.GetDelete() {
+		delete(s.labels, key)
+	} else {
+		s.labels[key] = &LabelRoute{
+			LabelKey:  key,
+			PopLabels: req.GetPopLabels(),
+			NextHops:  nexthops,
+		}
+	}
+	s.labelsMu.Unlock()
+	if err := s.ResolveAndProgramDiff(ctx); err != nil {
+		return nil, status.Error(codes.Aborted, fmt.Sprintf("error while resolving sysrib: %v", err))
+	}
+
+	s.labelsMu.Lock()
+	programmed, ok := s.programmedLabels[key]
+	s.labelsMu.Unlock()
+	switch {
+	case req.GetDelete():
+		return &sysribpb.SetLabelResponse{
+			Status: sysribpb.SetRouteResponse_STATUS_SUCCESS,
+		}, nil
+	case !ok:
+		return &sysribpb.SetLabelResponse{
+			Status:  sysribpb.SetRouteResponse_STATUS_FAIL,
+			Message: fmt.Sprintf("label %d in network instance %q is unresolved", key.Label, niName),
+		}, nil
+	case s.dataplaneStatusTimeout != 0:
+		if err := s.dataplane.labelStatus(ctx, programmed, s.dataplaneStatusTimeout); err != nil {
+			return &sysribpb.SetLabelResponse{
+				Status:  sysribpb.SetRouteResponse_STATUS_FAIL,
+				Message: err.Error(),
+			}, nil
+		}
+	}
+	return &sysribpb.SetLabelResponse{
+		Status: sysribpb.SetRouteResponse_STATUS_SUCCESS,
+	}, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sysrib
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/ygnmi/ygnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/openconfig/lemming/dataplane/dplanerc"
+	"github.com/openconfig/lemming/gnmi"
+
+	dpb "github.com/openconfig/lemming/proto/dataplane"
+	"github.com/openconfig/lemming/proto/routing"
+	pb "github.com/openconfig/lemming/proto/sysrib"
+)
+
+func TestSetLabel(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	gnmiServer, err := gnmi.New(grpcServer, "local", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := gnmiServer.LocalClient()
+	if err := s.Start(context.Background(), client, "local", "", t.TempDir()+"/sysrib.api"); err != nil {
+		t.Fatalf("cannot start sysrib server, %v", err)
+	}
+	defer s.Stop()
+
+	c, err := ygnmi.NewClient(client, ygnmi.WithTarget("local"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	configureInterface(t, &AddIntfAction{
+		name:    "eth0",
+		ifindex: 0,
+		enabled: true,
+		prefix:  "192.168.1.1/24",
+		niName:  "DEFAULT",
+	}, c)
+	// Wait for Sysrib to pick up the connected prefix.
+	for i := 0; i != maxGNMIWaitQuanta; i++ {
+		if routes, err := ygnmi.GetAll(context.Background(), c, programmedRoutesQuery(t)); err == nil && len(routes) == 1 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	swap := &routing.Headers{Headers: []*routing.Header{{
+		Type:   routing.HeaderType_HEADER_TYPE_MPLS,
+		Labels: []uint32{200},
+	}}}
+	tests := []struct {
+		desc        string
+		inLabel     uint32
+		inNexthop   string
+		inDelete    bool
+		wantStatus  pb.SetRouteResponse_Status
+		wantMessage string
+		want        *dpb.LabelRoute
+	}{{
+		desc:       "programmed",
+		inLabel:    100,
+		inNexthop:  "192.168.1.42",
+		wantStatus: pb.SetRouteResponse_STATUS_SUCCESS,
+		want: &dpb.LabelRoute{
+			Label:           100,
+			NetworkInstance: "DEFAULT",
+			PopLabels:       1,
+			NextHops: &dpb.NextHopList{
+				Hops: []*dpb.NextHop{{
+					Interface: &dpb.OCInterface{Interface: "eth0"},
+					NextHopIp: "192.168.1.42",
+					Encap:     &dpb.NextHop_Headers{Headers: swap},
+				}},
+				Weights: []uint64{0},
+			},
+		},
+	}, {
+		desc:        "unresolved",
+		inLabel:     101,
+		inNexthop:   "50.5.5.5",
+		wantStatus:  pb.SetRouteResponse_STATUS_FAIL,
+		wantMessage: "unresolved",
+	}, {
+		desc:       "delete",
+		inLabel:    100,
+		inNexthop:  "192.168.1.42",
+		inDelete:   true,
+		wantStatus: pb.SetRouteResponse_STATUS_SUCCESS,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			resp, err := s.SetLabel(context.Background(), &pb.SetLabelRequest{
+				Label:     tt.inLabel,
+				PopLabels: 1,
+				Nexthops: []*pb.Nexthop{{
+					Type:    pb.Nexthop_TYPE_IPV4,
+					Address: tt.inNexthop,
+					Encap:   swap,
+				}},
+				Delete: tt.inDelete,
+			})
+			if err != nil {
+				t.Fatalf("SetLabel() got unexpected error: %v", err)
+			}
+			if got := resp.GetStatus(); got != tt.wantStatus {
+				t.Errorf("SetLabel() got status %v, want %v", got, tt.wantStatus)
+			}
+			if got := resp.GetMessage(); !strings.Contains(got, tt.wantMessage) || (tt.wantMessage == "") != (got == "") {
+				t.Errorf("SetLabel() got message %q, want it to contain %q", got, tt.wantMessage)
+			}
+			got, err := ygnmi.Lookup(context.Background(), c, dplanerc.LabelQuery("DEFAULT", tt.inLabel))
+			if err != nil {
+				t.Fatalf("cannot look up label %d: %v", tt.inLabel, err)
+			}
+			gotLabel, _ := got.Val()
+			if diff := cmp.Diff(tt.want, gotLabel, protocmp.Transform()); diff != "" {
+				t.Errorf("programmed label (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
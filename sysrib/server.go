@@ -25,6 +25,7 @@ import (
 	"reflect"
 	"strconv"
 	"sync"
+	"time"
 
 	log "github.com/golang/glog"
 	"github.com/google/go-cmp/cmp"
@@ -33,6 +34,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
 
 	"github.com/openconfig/lemming/dataplane/dplanerc"
 	"github.com/openconfig/lemming/gnmi/fakedevice"
@@ -59,6 +63,7 @@ const (
 //
 // API:
 // - SetRoute
+// - SetLabel
 // - setConnectedRoute
 // - setInterface
 type Server struct {
@@ -90,17 +95,69 @@ type Server struct {
 	// have been resolved.
 	resolvedRoutes map[RouteKey]*Route
 
+	labelsMu sync.Mutex
+	// labels contains the MPLS label entries set through SetLabel.
+	labels map[LabelKey]*LabelRoute
+	// programmedLabels contains the resolved label entries programmed in
+	// the dataplane.
+	programmedLabels map[LabelKey]*ResolvedLabel
+
 	dataplane dplane
+	// dataplaneStatusTimeout, if non-zero, is how long SetRoute and SetLabel
+	// wait for the dataplane to report the outcome of programming a route.
+	dataplaneStatusTimeout time.Duration
 
 	zServer *ZServer
 }
 
+// Option is an option of the sysrib server.
+type Option func(*Server)
+
+// WithDataplaneStatus makes SetRoute and SetLabel wait, for at most timeout,
+// for the dataplane to report the outcome of programming the route, instead
+// of only reporting whether the route is resolved.
+func WithDataplaneStatus(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.dataplaneStatusTimeout = timeout
+	}
+}
+
 // dplane represents the dataplane API accessible to sysrib for programming
 // routes.
 type dplane struct {
 	Client *ygnmi.Client
 }
 
+// routeStatus waits for the dataplane to report the outcome of programming
+// the route, returning an error if programming failed or no outcome was
+// reported within timeout.
+func (d *dplane) routeStatus(ctx context.Context, r *ResolvedRoute, timeout time.Duration) error {
+	rr, err := resolvedRouteToRouteRequest(r)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var st *spb.Status
+	_, err = ygnmi.Watch(ctx, d.Client, dplanerc.RouteStatusQuery(rr.GetPrefix().GetNetworkInstance(), r.Prefix), func(v *ygnmi.Value[*spb.Status]) error {
+		val, ok := v.Val()
+		if !ok || len(val.GetDetails()) == 0 {
+			return ygnmi.Continue
+		}
+		programmed := &dpb.Route{}
+		if err := val.GetDetails()[0].UnmarshalTo(programmed); err != nil || !proto.Equal(programmed, rr) {
+			// The status is for a previous version of the route.
+			return ygnmi.Continue
+		}
+		st = val
+		return nil
+	}).Await()
+	if err != nil {
+		return fmt.Errorf("no dataplane status for route %s: %v", r.Prefix, err)
+	}
+	return status.FromProto(st).Err()
+}
+
 // programRoute programs the route in the dataplane, returning an error on failure.
 func (d *dplane) programRoute(ctx context.Context, r *ResolvedRoute) error {
 	log.V(1).Infof("sysrib: programming resolved route: %+v", r)
@@ -126,7 +183,7 @@ func (d *dplane) deprogramRoute(ctx context.Context, r *ResolvedRoute) error {
 // New instantiates server to handle client queries.
 //
 // If dp is nil, then a connection attempt is made.
-func New(cfg *oc.Root) (*Server, error) {
+func New(cfg *oc.Root, opts ...Option) (*Server, error) {
 	rib, err := NewSysRIB(cfg)
 	if err != nil {
 		return nil, err
@@ -138,6 +195,11 @@ func New(cfg *oc.Root) (*Server, error) {
 		bgpGUEPolicies:   map[string]GUEPolicy{},
 		programmedRoutes: map[RouteKey]*ResolvedRoute{},
 		resolvedRoutes:   map[RouteKey]*Route{},
+		labels:           map[LabelKey]*LabelRoute{},
+		programmedLabels: map[LabelKey]*ResolvedLabel{},
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
 	return s, nil
 }
@@ -313,13 +375,29 @@ type RouteKey struct {
 	NIName string
 }
 
+// NextHopGroupKey is the unique identifier of a gRIBI next-hop group.
+type NextHopGroupKey struct {
+	ID     uint64
+	NIName string
+}
+
+// NextHopKey is the unique identifier of a gRIBI next hop.
+type NextHopKey struct {
+	Index  uint64
+	NIName string
+}
+
 // ResolvedRoute represents a route that is ready to be programmed into the forwarding plane.
 type ResolvedRoute struct {
 	RouteKey
 
 	// NOTE: The order of the nexthops should not matter when being programmed into the forwarding plane. As such, the forwarding plane should sort these nexthops before assigning the hash output for ECMP.
 	Nexthops []*ResolvedNexthop
-	// TODO(wenbli): backup nexthops.
+	// BackupNexthops are used by the forwarding plane when the ports of all
+	// of Nexthops are down.
+	BackupNexthops []*ResolvedNexthop
+	// NextHopGroup is the gRIBI next-hop group of the nexthops, if any.
+	NextHopGroup NextHopGroupKey
 }
 
 // ResolvedNexthop contains the information required to forward an IP packet.
@@ -331,6 +409,13 @@ type ResolvedNexthop struct {
 	Port       Interface
 	GUEHeaders GUEHeaders
 	Headers    []*routingpb.Header
+	// Decap is the type of the outer header removed by a nexthop that
+	// looks up packets again in its network instance. Such nexthops have
+	// no address.
+	Decap routingpb.HeaderType
+	// AFTNextHop is the gRIBI next hop, if any, whose AFT counters count
+	// the packets forwarded by the nexthop.
+	AFTNextHop NextHopKey
 }
 
 // HasGUE returns a bool indicating whether the resolved nexthop contains GUE
@@ -385,6 +470,20 @@ func resolvedRouteToRouteRequest(r *ResolvedRoute) (*dpb.Route, error) {
 	// TODO: Include a better signal for this.
 	if len(r.Nexthops) == 1 {
 		for _, nh := range r.Nexthops {
+			if nh.Decap != routingpb.HeaderType_HEADER_TYPE_UNSPECIFIED {
+				return &dpb.Route{
+					Prefix: &dpb.RoutePrefix{
+						NetworkInstance: r.NIName,
+						Cidr:            r.Prefix,
+					},
+					Hop: &dpb.Route_DecapLookup{
+						DecapLookup: &dpb.DecapLookup{
+							Header:          nh.Decap,
+							NetworkInstance: nh.NetworkInstance,
+						},
+					},
+				}, nil
+			}
 			if nh.Address == "" {
 				return &dpb.Route{
 					Prefix: &dpb.RoutePrefix{
@@ -404,16 +503,10 @@ func resolvedRouteToRouteRequest(r *ResolvedRoute) (*dpb.Route, error) {
 
 	nexthops := &dpb.NextHopList{}
 	for _, nh := range r.Nexthops {
-		dnh := &dpb.NextHop{
-			Interface: &dpb.OCInterface{
-				Interface:    nh.Port.Name,
-				Subinterface: nh.Port.Subinterface,
-			},
-			NextHopIp: nh.Address,
-		}
-		if len(nh.Headers) > 0 {
-			dnh.Encap = &dpb.NextHop_Headers{Headers: &routingpb.Headers{Headers: nh.Headers}}
+		if nh.Decap != routingpb.HeaderType_HEADER_TYPE_UNSPECIFIED {
+			return nil, fmt.Errorf("route %s cannot both decapsulate and forward packets", r.Prefix)
 		}
+		dnh := resolvedNexthopToNextHop(nh)
 		if nh.HasGUE() {
 			if !nh.GUEHeaders.IsV6 {
 				dnh.Encap = &dpb.NextHop_Gue{
@@ -441,6 +534,11 @@ func resolvedRouteToRouteRequest(r *ResolvedRoute) (*dpb.Route, error) {
 		nexthops.Hops = append(nexthops.Hops, dnh)
 		nexthops.Weights = append(nexthops.Weights, nh.Weight)
 	}
+	for _, nh := range r.BackupNexthops {
+		nexthops.BackupHops = append(nexthops.BackupHops, resolvedNexthopToNextHop(nh))
+		nexthops.BackupWeights = append(nexthops.BackupWeights, nh.Weight)
+	}
+	nexthops.GroupId, nexthops.GroupNetworkInstance = r.NextHopGroup.ID, r.NextHopGroup.NIName
 
 	return &dpb.Route{
 		Prefix: &dpb.RoutePrefix{
@@ -453,6 +551,24 @@ func resolvedRouteToRouteRequest(r *ResolvedRoute) (*dpb.Route, error) {
 	}, nil
 }
 
+// resolvedNexthopToNextHop returns the dataplane next hop forwarding out of
+// the resolved nexthop's port with its encap headers.
+func resolvedNexthopToNextHop(nh *ResolvedNexthop) *dpb.NextHop {
+	dnh := &dpb.NextHop{
+		Interface: &dpb.OCInterface{
+			Interface:    nh.Port.Name,
+			Subinterface: nh.Port.Subinterface,
+		},
+		NextHopIp:          nh.Address,
+		AftIndex:           nh.AFTNextHop.Index,
+		AftNetworkInstance: nh.AFTNextHop.NIName,
+	}
+	if len(nh.Headers) > 0 {
+		dnh.Encap = &dpb.NextHop_Headers{Headers: &routingpb.Headers{Headers: nh.Headers}}
+	}
+	return dnh
+}
+
 // ResolveAndProgramDiff walks through each prefix in the RIB, resolving it and
 // programs the forwarding plane.
 func (s *Server) ResolveAndProgramDiff(ctx context.Context) error {
@@ -473,6 +589,7 @@ func (s *Server) ResolveAndProgramDiff(ctx context.Context) error {
 			s.resolveAndProgramDiffAux(ctx, niName, ni, it.Address().String(), newResolvedRoutes)
 		}
 	}
+	s.resolveAndProgramLabels(ctx)
 
 	s.resolvedRoutesMu.Lock()
 	defer s.resolvedRoutesMu.Unlock()
@@ -514,6 +631,10 @@ func (s *Server) resolveAndProgramDiffAux(ctx context.Context, niName string, ni
 	}
 	s.interfacesMu.Lock()
 	nhs, route, err := s.rib.egressNexthops(niName, pfx, s.interfaces)
+	var backups []*ResolvedNexthop
+	if err == nil && len(nhs) > 0 {
+		backups = s.rib.egressBackupNexthops(route, s.interfaces)
+	}
 	s.interfacesMu.Unlock()
 	if err != nil {
 		log.Errorf("sysrib: %v", err)
@@ -531,9 +652,11 @@ func (s *Server) resolveAndProgramDiffAux(ctx context.Context, niName string, ni
 			Prefix: cPfx.String(),
 			NIName: niName,
 		},
-		Nexthops: nhs,
+		Nexthops:       nhs,
+		BackupNexthops: backups,
 	}
 	if routeIsResolved {
+		rr.NextHopGroup = route.NextHopGroup
 		newResolvedRoutes[rr.RouteKey] = route
 	}
 
@@ -558,6 +681,23 @@ func (s *Server) resolveAndProgramDiffAux(ctx context.Context, niName string, ni
 	}
 }
 
+// FlushProtocolRoutes removes all routes that were learned from routing
+// protocols (e.g. BGP and gRIBI), keeping only the connected and static
+// routes, and deprograms them from the dataplane.
+func (s *Server) FlushProtocolRoutes(ctx context.Context) error {
+	for niName, routes := range s.rib.protocolRoutes() {
+		for _, route := range routes {
+			if err := s.rib.DeleteRoute(niName, route); err != nil {
+				return fmt.Errorf("error while deleting route from sysrib: %v", err)
+			}
+		}
+	}
+	if err := s.ResolveAndProgramDiff(ctx); err != nil {
+		return fmt.Errorf("error while resolving sysrib: %v", err)
+	}
+	return nil
+}
+
 // ResolvedRoutes returns the shallow copy of the resolved routes of the RIB
 // manager.
 func (s *Server) ResolvedRoutes() map[RouteKey]*Route {
@@ -574,6 +714,17 @@ func (s *Server) ProgrammedRoutes() map[RouteKey]*ResolvedRoute {
 	return maps.Clone(s.programmedRoutes)
 }
 
+// NexthopChain returns the addresses of the next-hops traversed to reach
+// address in the named network instance, ordered from the one nearest to the
+// device. The returned bool indicates whether the address is routable.
+func (s *Server) NexthopChain(niName, address string) ([]string, bool, error) {
+	pfx, err := addressToPrefix(address)
+	if err != nil {
+		return nil, false, err
+	}
+	return s.rib.NexthopChain(niName, pfx)
+}
+
 // SetRoute implements ROUTE_ADD and ROUTE_DELETE
 func (s *Server) SetRoute(ctx context.Context, req *sysribpb.SetRouteRequest) (*sysribpb.SetRouteResponse, error) {
 	pfx, err := prefixString(req.Prefix)
@@ -581,28 +732,31 @@ func (s *Server) SetRoute(ctx context.Context, req *sysribpb.SetRouteRequest) (*
 		return nil, err
 	}
 
-	nexthops := []*ResolvedNexthop{}
-	for _, nh := range req.GetNexthops() {
-		if nh.GetType() != sysribpb.Nexthop_TYPE_IPV4 && nh.GetType() != sysribpb.Nexthop_TYPE_IPV6 {
-			return nil, status.Errorf(codes.Unimplemented, "Unrecognized nexthop type: %s", nh.GetType())
-		}
-		nexthops = append(nexthops, &ResolvedNexthop{
-			NextHopSummary: afthelper.NextHopSummary{
-				Weight:          nh.GetWeight(),
-				Address:         nh.GetAddress(),
-				NetworkInstance: vrfIDToNiName(nh.GetVrfId()),
-			},
-			Headers: nh.GetEncap().GetHeaders(),
-		})
+	nexthops, err := requestNexthops(req.GetNexthops())
+	if err != nil {
+		return nil, err
+	}
+	backups, err := requestNexthops(req.GetBackupNexthops())
+	if err != nil {
+		return nil, err
 	}
 
 	niName := req.GetNetworkInstance()
 	if niName == "" {
 		niName = vrfIDToNiName(req.GetVrfId())
 	}
+	var nhg NextHopGroupKey
+	if req.GetNextHopGroupId() != 0 {
+		nhg = NextHopGroupKey{ID: req.GetNextHopGroupId(), NIName: req.GetNextHopGroupNetworkInstance()}
+		if nhg.NIName == "" {
+			nhg.NIName = niName
+		}
+	}
 	if err := s.setRoute(ctx, niName, &Route{
-		Prefix:   pfx,
-		NextHops: nexthops,
+		Prefix:         pfx,
+		NextHops:       nexthops,
+		BackupNextHops: backups,
+		NextHopGroup:   nhg,
 		RoutePref: RoutePreference{
 			AdminDistance: uint8(req.GetAdminDistance()),
 			Metric:        req.GetMetric(),
@@ -612,17 +766,64 @@ func (s *Server) SetRoute(ctx context.Context, req *sysribpb.SetRouteRequest) (*
 	}
 
 	// There could be operations carried out by ResolveAndProgramDiff() other than the input route, so we look up our particular prefix.
-	status := sysribpb.SetRouteResponse_STATUS_FAIL
 	s.programmedRoutesMu.Lock()
-	if _, ok := s.programmedRoutes[RouteKey{Prefix: pfx, NIName: niName}]; ok {
-		status = sysribpb.SetRouteResponse_STATUS_SUCCESS
-	}
+	programmed, ok := s.programmedRoutes[RouteKey{Prefix: pfx, NIName: niName}]
 	s.programmedRoutesMu.Unlock()
+	switch {
+	case req.Delete:
+		// The prefix stays programmed if it is resolved through
+		// another protocol's route.
+		return &sysribpb.SetRouteResponse{
+			Status: sysribpb.SetRouteResponse_STATUS_SUCCESS,
+		}, nil
+	case !ok:
+		return &sysribpb.SetRouteResponse{
+			Status:  sysribpb.SetRouteResponse_STATUS_FAIL,
+			Message: fmt.Sprintf("route %s in network instance %q is unresolved", pfx, niName),
+		}, nil
+	case s.dataplaneStatusTimeout != 0:
+		if err := s.dataplane.routeStatus(ctx, programmed, s.dataplaneStatusTimeout); err != nil {
+			return &sysribpb.SetRouteResponse{
+				Status:  sysribpb.SetRouteResponse_STATUS_FAIL,
+				Message: err.Error(),
+			}, nil
+		}
+	}
 	return &sysribpb.SetRouteResponse{
-		Status: status,
+		Status: sysribpb.SetRouteResponse_STATUS_SUCCESS,
 	}, nil
 }
 
+// requestNexthops returns the unresolved nexthops of a request.
+func requestNexthops(nhs []*sysribpb.Nexthop) ([]*ResolvedNexthop, error) {
+	nexthops := []*ResolvedNexthop{}
+	for _, nh := range nhs {
+		switch decap := nh.GetDecapsulateHeader(); {
+		case decap != routingpb.HeaderType_HEADER_TYPE_UNSPECIFIED:
+			if decap != routingpb.HeaderType_HEADER_TYPE_IP4 && decap != routingpb.HeaderType_HEADER_TYPE_IP6 {
+				return nil, status.Errorf(codes.Unimplemented, "Unsupported decapsulate header: %s", decap)
+			}
+		case nh.GetType() != sysribpb.Nexthop_TYPE_IPV4 && nh.GetType() != sysribpb.Nexthop_TYPE_IPV6:
+			return nil, status.Errorf(codes.Unimplemented, "Unrecognized nexthop type: %s", nh.GetType())
+		}
+		niName := nh.GetNetworkInstance()
+		if niName == "" {
+			niName = vrfIDToNiName(nh.GetVrfId())
+		}
+		nexthops = append(nexthops, &ResolvedNexthop{
+			NextHopSummary: afthelper.NextHopSummary{
+				Weight:          nh.GetWeight(),
+				Address:         nh.GetAddress(),
+				NetworkInstance: niName,
+			},
+			Headers:    nh.GetEncap().GetHeaders(),
+			Decap:      nh.GetDecapsulateHeader(),
+			AFTNextHop: NextHopKey{Index: nh.GetAftIndex(), NIName: nh.GetAftNetworkInstance()},
+		})
+	}
+	return nexthops, nil
+}
+
 // setRoute adds/deletes a route from the RIB manager.
 func (s *Server) setRoute(ctx context.Context, niName string, route *Route, isDelete bool) error {
 	if err := s.rib.setRoute(niName, route, isDelete); err != nil {
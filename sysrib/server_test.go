@@ -29,8 +29,11 @@ import (
 	"github.com/openconfig/ygnmi/ygnmi"
 	"github.com/openconfig/ygot/ygot"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
 
+	"github.com/openconfig/lemming/dataplane/dplanerc"
 	"github.com/openconfig/lemming/gnmi"
 	"github.com/openconfig/lemming/gnmi/gnmiclient"
 	"github.com/openconfig/lemming/gnmi/oc"
@@ -39,6 +42,8 @@ import (
 	dpb "github.com/openconfig/lemming/proto/dataplane"
 	"github.com/openconfig/lemming/proto/routing"
 	pb "github.com/openconfig/lemming/proto/sysrib"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
 )
 
 const (
@@ -1177,6 +1182,142 @@ func TestServer(t *testing.T) {
 				},
 			},
 		}},
+	}, {
+		desc: "Decap",
+		noV6: true,
+		inSetRouteRequests: []*SetRouteRequestAction{{
+			Desc: "decapsulate and look up in another network instance",
+			RouteReq: &pb.SetRouteRequest{
+				AdminDistance: 5,
+				Metric:        5,
+				Prefix: &pb.Prefix{
+					Family:     pb.Prefix_FAMILY_IPV4,
+					Address:    "10.0.0.0",
+					MaskLength: 8,
+				},
+				Nexthops: []*pb.Nexthop{{
+					DecapsulateHeader: routing.HeaderType_HEADER_TYPE_IP4,
+					NetworkInstance:   "VRF-1",
+				}},
+			},
+		}},
+		wantRoutes: []*dpb.Route{{
+			Prefix: &dpb.RoutePrefix{
+				NetworkInstance: "DEFAULT",
+				Cidr:            "10.0.0.0/8",
+			},
+			Hop: &dpb.Route_DecapLookup{
+				DecapLookup: &dpb.DecapLookup{
+					Header:          routing.HeaderType_HEADER_TYPE_IP4,
+					NetworkInstance: "VRF-1",
+				},
+			},
+		}},
+	}, {
+		desc: "Backup nexthops",
+		noV6: true,
+		inSetRouteRequests: []*SetRouteRequestAction{{
+			Desc: "route with a backup next-hop group",
+			RouteReq: &pb.SetRouteRequest{
+				AdminDistance: 5,
+				Metric:        5,
+				Prefix: &pb.Prefix{
+					Family:     pb.Prefix_FAMILY_IPV4,
+					Address:    "10.0.0.0",
+					MaskLength: 8,
+				},
+				Nexthops: []*pb.Nexthop{{
+					Type:    pb.Nexthop_TYPE_IPV4,
+					Address: "192.168.1.42",
+				}},
+				BackupNexthops: []*pb.Nexthop{{
+					Type:    pb.Nexthop_TYPE_IPV4,
+					Address: "192.168.2.42",
+				}, {
+					Type:    pb.Nexthop_TYPE_IPV4,
+					Address: "172.16.0.1",
+				}},
+				NextHopGroupId: 1,
+			},
+		}},
+		wantRoutes: []*dpb.Route{{
+			Prefix: &dpb.RoutePrefix{
+				NetworkInstance: "DEFAULT",
+				Cidr:            "10.0.0.0/8",
+			},
+			Hop: &dpb.Route_NextHops{
+				NextHops: &dpb.NextHopList{
+					Weights: []uint64{0},
+					Hops: []*dpb.NextHop{{
+						NextHopIp: "192.168.1.42",
+						Interface: &dpb.OCInterface{
+							Interface: "eth0",
+						},
+					}},
+					BackupWeights: []uint64{0},
+					BackupHops: []*dpb.NextHop{{
+						NextHopIp: "192.168.2.42",
+						Interface: &dpb.OCInterface{
+							Interface: "eth1",
+						},
+					}},
+					GroupId:              1,
+					GroupNetworkInstance: "DEFAULT",
+				},
+			},
+		}},
+	}, {
+		desc: "gRIBI next hops",
+		noV6: true,
+		inSetRouteRequests: []*SetRouteRequestAction{{
+			Desc: "route with nexthops counted under their AFT next hops",
+			RouteReq: &pb.SetRouteRequest{
+				AdminDistance: 5,
+				Metric:        5,
+				Prefix: &pb.Prefix{
+					Family:     pb.Prefix_FAMILY_IPV4,
+					Address:    "10.0.0.0",
+					MaskLength: 8,
+				},
+				Nexthops: []*pb.Nexthop{{
+					Type:               pb.Nexthop_TYPE_IPV4,
+					Address:            "192.168.1.42",
+					AftIndex:           1,
+					AftNetworkInstance: "DEFAULT",
+				}, {
+					Type:               pb.Nexthop_TYPE_IPV4,
+					Address:            "192.168.2.42",
+					AftIndex:           2,
+					AftNetworkInstance: "DEFAULT",
+				}},
+			},
+		}},
+		wantRoutes: []*dpb.Route{{
+			Prefix: &dpb.RoutePrefix{
+				NetworkInstance: "DEFAULT",
+				Cidr:            "10.0.0.0/8",
+			},
+			Hop: &dpb.Route_NextHops{
+				NextHops: &dpb.NextHopList{
+					Weights: []uint64{0, 0},
+					Hops: []*dpb.NextHop{{
+						NextHopIp: "192.168.1.42",
+						Interface: &dpb.OCInterface{
+							Interface: "eth0",
+						},
+						AftIndex:           1,
+						AftNetworkInstance: "DEFAULT",
+					}, {
+						NextHopIp: "192.168.2.42",
+						Interface: &dpb.OCInterface{
+							Interface: "eth1",
+						},
+						AftIndex:           2,
+						AftNetworkInstance: "DEFAULT",
+					}},
+				},
+			},
+		}},
 	}}
 
 	grpcServer := grpc.NewServer()
@@ -2392,3 +2533,130 @@ func TestBGPGUEPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestSetRouteDataplaneStatus(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	gnmiServer, err := gnmi.New(grpcServer, "local", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(nil, WithDataplaneStatus(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := gnmiServer.LocalClient()
+	if err := s.Start(context.Background(), client, "local", "", t.TempDir()+"/sysrib.api"); err != nil {
+		t.Fatalf("cannot start sysrib server, %v", err)
+	}
+	defer s.Stop()
+
+	c, err := ygnmi.NewClient(client, ygnmi.WithTarget("local"))
+	if err != nil {
+		t.Fatalf("cannot create ygnmi client: %v", err)
+	}
+	configureInterface(t, &AddIntfAction{
+		name:    "eth0",
+		ifindex: 0,
+		enabled: true,
+		prefix:  "192.168.1.1/24",
+		niName:  "DEFAULT",
+	}, c)
+	// Wait for Sysrib to pick up the connected prefix.
+	for i := 0; i != maxGNMIWaitQuanta; i++ {
+		if routes, err := ygnmi.GetAll(context.Background(), c, programmedRoutesQuery(t)); err == nil && len(routes) == 1 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Fake the route reconciler of the dataplane: it fails to program
+	// 20.0.0.0/8 and never reports an outcome for 30.0.0.0/8.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := ygnmi.WatchAll(ctx, c, programmedRoutesQuery(t), func(v *ygnmi.Value[*dpb.Route]) error {
+		route, ok := v.Val()
+		if !ok {
+			return ygnmi.Continue
+		}
+		detail, err := anypb.New(route)
+		if err != nil {
+			return err
+		}
+		st := &spb.Status{Code: int32(codes.OK), Details: []*anypb.Any{detail}}
+		switch route.GetPrefix().GetCidr() {
+		case "20.0.0.0/8":
+			st.Code, st.Message = int32(codes.Internal), "no more space"
+		case "30.0.0.0/8":
+			return ygnmi.Continue
+		}
+		if _, err := ygnmi.Replace(ctx, c, dplanerc.RouteStatusQuery(route.GetPrefix().GetNetworkInstance(), route.GetPrefix().GetCidr()), st, ygnmi.WithSetFallbackEncoding()); err != nil {
+			return err
+		}
+		return ygnmi.Continue
+	})
+	go w.Await()
+
+	tests := []struct {
+		desc        string
+		inAddress   string
+		inNexthop   string
+		inDelete    bool
+		wantStatus  pb.SetRouteResponse_Status
+		wantMessage string
+	}{{
+		desc:       "programmed",
+		inAddress:  "10.0.0.0",
+		inNexthop:  "192.168.1.42",
+		wantStatus: pb.SetRouteResponse_STATUS_SUCCESS,
+	}, {
+		desc:        "failed",
+		inAddress:   "20.0.0.0",
+		inNexthop:   "192.168.1.42",
+		wantStatus:  pb.SetRouteResponse_STATUS_FAIL,
+		wantMessage: "no more space",
+	}, {
+		desc:        "no dataplane status",
+		inAddress:   "30.0.0.0",
+		inNexthop:   "192.168.1.42",
+		wantStatus:  pb.SetRouteResponse_STATUS_FAIL,
+		wantMessage: "no dataplane status",
+	}, {
+		desc:        "unresolved",
+		inAddress:   "40.0.0.0",
+		inNexthop:   "50.5.5.5",
+		wantStatus:  pb.SetRouteResponse_STATUS_FAIL,
+		wantMessage: "unresolved",
+	}, {
+		desc:       "delete",
+		inAddress:  "10.0.0.0",
+		inNexthop:  "192.168.1.42",
+		inDelete:   true,
+		wantStatus: pb.SetRouteResponse_STATUS_SUCCESS,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			resp, err := s.SetRoute(context.Background(), &pb.SetRouteRequest{
+				AdminDistance: 10,
+				Prefix: &pb.Prefix{
+					Family:     pb.Prefix_FAMILY_IPV4,
+					Address:    tt.inAddress,
+					MaskLength: 8,
+				},
+				Nexthops: []*pb.Nexthop{{
+					Type:    pb.Nexthop_TYPE_IPV4,
+					Address: tt.inNexthop,
+				}},
+				Delete: tt.inDelete,
+			})
+			if err != nil {
+				t.Fatalf("SetRoute() got unexpected error: %v", err)
+			}
+			if got := resp.GetStatus(); got != tt.wantStatus {
+				t.Errorf("SetRoute() got status %v, want %v", got, tt.wantStatus)
+			}
+			if got := resp.GetMessage(); !strings.Contains(got, tt.wantMessage) || (tt.wantMessage == "") != (got == "") {
+				t.Errorf("SetRoute() got message %q, want it to contain %q", got, tt.wantMessage)
+			}
+		})
+	}
+}
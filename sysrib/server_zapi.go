@@ -22,6 +22,8 @@ import (
 	log "github.com/golang/glog"
 	"github.com/openconfig/gribigo/afthelper"
 	"github.com/osrg/gobgp/v3/pkg/zebra"
+
+	routingpb "github.com/openconfig/lemming/proto/routing"
 )
 
 func distributeRoute(s *ZServer, rr *ResolvedRoute, route *Route, isDelete bool) {
@@ -69,6 +71,10 @@ func convertToZAPIRoute(routeKey RouteKey, route *Route, rr *ResolvedRoute) (*ze
 
 	var nexthops []zebra.Nexthop
 	for _, nh := range rr.Nexthops {
+		if nh.Decap != routingpb.HeaderType_HEADER_TYPE_UNSPECIFIED {
+			// Decapsulating routes have no gateway to redistribute.
+			return nil, nil
+		}
 		nexthops = append(nexthops, zebra.Nexthop{
 			VrfID:  vrfID,
 			Gate:   net.ParseIP(nh.Address),
@@ -22,6 +22,7 @@ import (
 	"net"
 	"net/netip"
 	"reflect"
+	"slices"
 	"sort"
 	"sync"
 
@@ -33,6 +34,8 @@ import (
 
 	"github.com/openconfig/lemming/gnmi/fakedevice"
 	"github.com/openconfig/lemming/gnmi/oc"
+
+	routingpb "github.com/openconfig/lemming/proto/routing"
 )
 
 // SysRIB is a RIB data structure that can be used to resolve routing entries to their egress interfaces.
@@ -167,8 +170,13 @@ type Route struct {
 	Connected *Interface `json:"connected"`
 	// NextHops is the set of IP nexthops that the route uses if
 	// it is not a connected route.
-	NextHops  []*ResolvedNexthop `json:"nexthops"`
-	RoutePref RoutePreference
+	NextHops []*ResolvedNexthop `json:"nexthops"`
+	// BackupNextHops is the set of IP nexthops that the forwarding plane
+	// uses when the ports of all of NextHops are down.
+	BackupNextHops []*ResolvedNexthop `json:"backup-nexthops"`
+	// NextHopGroup is the gRIBI next-hop group of the nexthops, if any.
+	NextHopGroup NextHopGroupKey `json:"next-hop-group"`
+	RoutePref    RoutePreference
 }
 
 func (r *Route) String() string {
@@ -313,6 +321,34 @@ func (sr *SysRIB) setRoute(ni string, r *Route, isDelete bool) error {
 	}
 }
 
+// protocolRoutes returns the routes in each network instance that were
+// learned from routing protocols, i.e. that are neither connected nor static.
+func (sr *SysRIB) protocolRoutes() map[string][]*Route {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	routes := map[string][]*Route{}
+	isProtocolRoute := func(r *Route) bool {
+		return r.Connected == nil && r.RoutePref.AdminDistance > AdminDistanceStatic
+	}
+	for niName, ni := range sr.NI {
+		for it := ni.IPV4.Iterate(); it.Next(); {
+			for _, route := range it.Tags() {
+				if isProtocolRoute(route) {
+					routes[niName] = append(routes[niName], route)
+				}
+			}
+		}
+		for it := ni.IPV6.Iterate(); it.Next(); {
+			for _, route := range it.Tags() {
+				if isProtocolRoute(route) {
+					routes[niName] = append(routes[niName], route)
+				}
+			}
+		}
+	}
+	return routes
+}
+
 // SetGUEPolicy sets a GUE Policy in the RIB.
 func (sr *SysRIB) SetGUEPolicy(prefix string, policy GUEPolicy) error {
 	sr.mu.Lock()
@@ -494,6 +530,74 @@ func (sr *SysRIB) egressInterfaceInternal(inputNI string, ip *net.IPNet, visited
 	return egressIfs, nil
 }
 
+// NexthopChain looks up the IP destination address ip in the routes for
+// network instance named inputNI and returns the addresses of the next-hops
+// traversed when recursively resolving it, ordered from the one nearest to
+// the device. Where a route has multiple next-hops, the one with the lowest
+// address is followed. The returned bool indicates whether ip resolves to a
+// connected route; a directly connected destination returns an empty chain.
+func (sr *SysRIB) NexthopChain(inputNI string, ip *net.IPNet) ([]string, bool, error) {
+	return sr.nexthopChainInternal(inputNI, ip, []*Route{})
+}
+
+// nexthopChainInternal is a recursive implementation of NexthopChain that
+// tracks the routes that have been visited along each branch to detect
+// resolution loops.
+func (sr *SysRIB) nexthopChainInternal(inputNI string, ip *net.IPNet, visited []*Route) ([]string, bool, error) {
+	if inputNI == "" {
+		inputNI = sr.defaultNI
+	}
+
+	sr.mu.RLock()
+	found, entries, err := sr.entryForCIDR(inputNI, ip)
+	sr.mu.RUnlock()
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot lookup IP %s", ip)
+	}
+	if !found {
+		return nil, false, nil
+	}
+
+	routes := append([]*Route{}, entries...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].RoutePref.AdminDistance < routes[j].RoutePref.AdminDistance || routes[i].RoutePref.AdminDistance == routes[j].RoutePref.AdminDistance && routes[i].RoutePref.Metric < routes[j].RoutePref.Metric
+	})
+	for _, cr := range routes {
+		if cr.Connected != nil {
+			return nil, true, nil
+		}
+
+		loop := false
+		for _, r := range visited {
+			if reflect.DeepEqual(cr, r) {
+				log.V(1).Infof("route %v has a circular dependency", ip)
+				loop = true
+			}
+		}
+		if loop {
+			continue
+		}
+		v := append(visited, cr)
+
+		nhs := append([]*ResolvedNexthop{}, cr.NextHops...)
+		sort.Slice(nhs, func(i, j int) bool { return nhs[i].Address < nhs[j].Address })
+		for _, nh := range nhs {
+			nhop, err := addressToPrefix(nh.Address)
+			if err != nil {
+				return nil, false, err
+			}
+			chain, ok, err := sr.nexthopChainInternal(nh.NetworkInstance, nhop, v)
+			if err != nil {
+				return nil, false, fmt.Errorf("for nexthop %s, can't resolve: %v", nh.Address, err)
+			}
+			if ok {
+				return append(chain, nh.Address), true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
+
 // egressNexthops returns the resolved nexthops for the input IP prefix. It
 // also returns the top-level route (at this level) that was successfully
 // resolved (if any). This is useful for determining the properties of the
@@ -579,6 +683,15 @@ func (sr *SysRIB) egressNexthopsInternal(inputNI string, ip *net.IPNet, interfac
 
 		v := append(visited, cr)
 		for _, nh := range cr.NextHops {
+			if nh.Decap != routingpb.HeaderType_HEADER_TYPE_UNSPECIFIED {
+				// The dataplane resolves decapsulated packets by
+				// looking them up in the nexthop's network instance.
+				allEgressNhs[cr.RoutePref] = append(allEgressNhs[cr.RoutePref], &ResolvedNexthop{
+					NextHopSummary: nh.NextHopSummary,
+					Decap:          nh.Decap,
+				})
+				continue
+			}
 			log.V(1).Infof("Recursively resolving nexthop: %+v", *nh)
 			nhop, err := addressToPrefix(nh.Address)
 			if err != nil {
@@ -614,6 +727,7 @@ func (sr *SysRIB) egressNexthopsInternal(inputNI string, ip *net.IPNet, interfac
 				}
 				rnh.Headers = append(nh.Headers, rnh.Headers...)
 				rnh.GUEHeaders = encapHeaders
+				rnh.AFTNextHop = nh.AFTNextHop
 				// TODO(wenbli): Implement WCMP: there could be a merger of two nexthops, in which case we add their weights.
 				allEgressNhs[cr.RoutePref] = append(allEgressNhs[cr.RoutePref], rnh)
 			}
@@ -639,6 +753,41 @@ func (sr *SysRIB) egressNexthopsInternal(inputNI string, ip *net.IPNet, interfac
 	return nil, nil, nil
 }
 
+// egressBackupNexthops resolves the backup nexthops of a route selected by
+// egressNexthops. Backup nexthops that cannot be resolved, or that do not
+// resolve to a port, are skipped.
+//
+// NOTE: sr.mu.RLock() must be called prior to calling this function.
+func (sr *SysRIB) egressBackupNexthops(route *Route, interfaces map[Interface]bool) []*ResolvedNexthop {
+	var backups []*ResolvedNexthop
+	for _, nh := range route.BackupNextHops {
+		if nh.Decap != routingpb.HeaderType_HEADER_TYPE_UNSPECIFIED {
+			log.Warningf("Skipping backup nexthop %+v of route %s: decapsulating backup nexthops are not supported", *nh, route.Prefix)
+			continue
+		}
+		nhop, err := addressToPrefix(nh.Address)
+		if err != nil {
+			log.Warningf("Skipping backup nexthop %+v of route %s: %v", *nh, route.Prefix, err)
+			continue
+		}
+		recursiveNHs, _, err := sr.egressNexthopsInternal(nh.NetworkInstance, nhop, interfaces, []*Route{route})
+		if err != nil {
+			log.Warningf("Skipping backup nexthop %+v of route %s: %v", *nh, route.Prefix, err)
+			continue
+		}
+		for _, rnh := range recursiveNHs {
+			if rnh.Decap != routingpb.HeaderType_HEADER_TYPE_UNSPECIFIED || rnh.HasGUE() {
+				log.Warningf("Skipping backup nexthop %+v of route %s: it does not resolve to a port", *nh, route.Prefix)
+				continue
+			}
+			rnh.Headers = slices.Concat(nh.Headers, rnh.Headers)
+			rnh.AFTNextHop = nh.AFTNextHop
+			backups = append(backups, rnh)
+		}
+	}
+	return backups
+}
+
 // niConnected is a description of a set of connected routes within a network instance.
 type niConnected struct {
 	// N is the network instance to which the route belongs.
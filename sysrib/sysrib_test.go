@@ -816,6 +816,92 @@ func TestEgressInterface(t *testing.T) {
 	}
 }
 
+func TestNexthopChain(t *testing.T) {
+	cfg := func() *oc.Root {
+		d := &oc.Root{}
+		d.GetOrCreateInterface("eth0").
+			GetOrCreateSubinterface(0).
+			GetOrCreateIpv4().
+			GetOrCreateAddress("192.0.2.0").
+			PrefixLength = ygot.Uint8(24)
+		d.GetOrCreateNetworkInstance("DEFAULT").
+			Type = oc.NetworkInstanceTypes_NETWORK_INSTANCE_TYPE_DEFAULT_INSTANCE
+		return d
+	}
+	nh := func(addr string) *ResolvedNexthop {
+		return &ResolvedNexthop{NextHopSummary: afthelper.NextHopSummary{Address: addr, NetworkInstance: "DEFAULT"}}
+	}
+	tests := []struct {
+		desc        string
+		inAddRoutes []*Route
+		inIP        net.IPNet
+		wantChain   []string
+		wantFound   bool
+	}{{
+		desc:      "connected",
+		inIP:      mustCIDR("192.0.2.1/32"),
+		wantFound: true,
+	}, {
+		desc: "single recursion",
+		inAddRoutes: []*Route{{
+			Prefix:   "8.8.8.0/24",
+			NextHops: []*ResolvedNexthop{nh("192.0.2.1")},
+		}},
+		inIP:      mustCIDR("8.8.8.8/32"),
+		wantChain: []string{"192.0.2.1"},
+		wantFound: true,
+	}, {
+		desc: "multiple recursions with ecmp",
+		inAddRoutes: []*Route{{
+			Prefix:   "8.8.8.0/24",
+			NextHops: []*ResolvedNexthop{nh("10.0.0.1")},
+		}, {
+			Prefix:   "10.0.0.0/8",
+			NextHops: []*ResolvedNexthop{nh("192.0.2.2"), nh("192.0.2.1")},
+		}},
+		inIP:      mustCIDR("8.8.8.8/32"),
+		wantChain: []string{"192.0.2.1", "10.0.0.1"},
+		wantFound: true,
+	}, {
+		desc:      "no route",
+		inIP:      mustCIDR("8.8.8.8/32"),
+		wantFound: false,
+	}, {
+		desc: "unresolvable next-hop",
+		inAddRoutes: []*Route{{
+			Prefix:   "8.8.8.0/24",
+			NextHops: []*ResolvedNexthop{nh("10.0.0.1")},
+		}},
+		inIP:      mustCIDR("8.8.8.8/32"),
+		wantFound: false,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r, err := NewSysRIB(cfg())
+			if err != nil {
+				t.Fatalf("could not build RIB, %v", err)
+			}
+			for _, rt := range tt.inAddRoutes {
+				if err := r.AddRoute("DEFAULT", rt); err != nil {
+					t.Fatalf("cannot add route %s, err: %v", rt.Prefix, err)
+				}
+			}
+
+			got, found, err := r.NexthopChain("DEFAULT", &tt.inIP)
+			if err != nil {
+				t.Fatalf("NexthopChain() unexpected error: %v", err)
+			}
+			if found != tt.wantFound {
+				t.Errorf("NexthopChain() got found %v, want %v", found, tt.wantFound)
+			}
+			if diff := cmp.Diff(got, tt.wantChain); diff != "" {
+				t.Errorf("did not get expected chain, diff(-got,+want):\n%s", diff)
+			}
+		})
+	}
+}
+
 func baseCfg() *oc.Root {
 	d := &oc.Root{}
 	d.GetOrCreateNetworkInstance(fakedevice.DefaultNetworkInstance).Type = oc.NetworkInstanceTypes_NETWORK_INSTANCE_TYPE_DEFAULT_INSTANCE